@@ -55,6 +55,8 @@ func main() {
 		newRunCommand(),
 		newRunTestCommand(),
 		newRunMonitorCommand(),
+		newListCommand(),
+		newLintTestsCommand(),
 	)
 
 	pflag.CommandLine = pflag.NewFlagSet("empty", pflag.ExitOnError)
@@ -114,6 +116,12 @@ func newRunCommand() *cobra.Command {
 		command with the --file argument. You may also pipe a list of test names, one per line, on
 		standard input by passing "-f -".
 
+		With --upgrade only the PreChkUpgrade and PstChkUpgrade tests of the suite are run: the pre-upgrade
+		checks first, then the cluster is upgraded to --upgrade-to-image, or the command waits for an upgrade
+		started by someone else if it is empty, and then the post-upgrade checks. A post-upgrade check is
+		skipped if the pre-upgrade check with the same Polarion ID did not pass. The checks share data with
+		exutil.NewUpgradeHandoff, stored in the cluster or in --upgrade-handoff-dir.
+
 		`) + testginkgo.SuitesString(opt.Suites, "\n\nAvailable test suites:\n\n"),
 
 		SilenceUsage:  true,
@@ -176,6 +184,84 @@ func newRunTestCommand() *cobra.Command {
 	return cmd
 }
 
+func newListCommand() *cobra.Command {
+	listOpt := &testginkgo.ListOptions{
+		Suites: staticSuites,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list [SUITE]",
+		Short: "List the tests with the metadata in their names",
+		Long: templates.LongDesc(`
+		List tests and their metadata
+
+		This command prints the tests of a suite, or all the tests if no suite is given, with the metadata
+		encoded in their names: author, importance, Polarion IDs and tags like NonPreRelease or Longduration.
+		The list can be filtered by any of these fields, for example:
+
+		    list --importance=Critical --author=minmli --exclude-tag=Longduration -o csv
+
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := initProvider(os.Getenv("TEST_PROVIDER"), true); err != nil {
+				return err
+			}
+			return listOpt.Run(args)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&listOpt.Output, "output", "o", "json", "Output format, json or csv.")
+	flags.StringVar(&listOpt.Regex, "run", listOpt.Regex, "Regular expression of tests to list.")
+	flags.StringSliceVar(&listOpt.Filter.Importances, "importance", nil, "List only the tests with this importance, i.e. Critical. May be repeated.")
+	flags.StringSliceVar(&listOpt.Filter.Authors, "author", nil, "List only the tests of this author. May be repeated.")
+	flags.StringSliceVar(&listOpt.Filter.Sigs, "sig", nil, "List only the tests of this sig, i.e. sig-networking or networking. May be repeated.")
+	flags.StringSliceVar(&listOpt.Filter.PolarionIDs, "id", nil, "List only the tests with this Polarion ID. May be repeated.")
+	flags.StringSliceVar(&listOpt.Filter.Tags, "tag", nil, "List only the tests with this tag or label, i.e. NonPreRelease or Serial. May be repeated.")
+	flags.StringSliceVar(&listOpt.Filter.ExcludeTags, "exclude-tag", nil, "Skip the tests with this tag or label, i.e. Longduration or Disruptive. May be repeated.")
+	return cmd
+}
+
+func newLintTestsCommand() *cobra.Command {
+	lintOpt := &testginkgo.LintOptions{
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "lint-tests",
+		Short: "Validate the test names against the metadata convention",
+		Long: templates.LongDesc(`
+		Validate the test names
+
+		The suites select tests by substrings of their names like [Serial], [Disruptive], NonPreRelease
+		or Longduration, so a typo in a name silently moves the test to another suite. This command
+		checks the names of all the tests in this repository: the tokens are known, each Polarion ID is
+		numeric and preceded by one importance, the author is present, the Polarion IDs are not used
+		in several packages, and tests that look disruptive have the [Disruptive] or [Serial] label.
+
+		The findings are printed as JSON, or as text with --output=text, and the command fails if any
+		of them is an error.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := initProvider(os.Getenv("TEST_PROVIDER"), true); err != nil {
+				return err
+			}
+			return lintOpt.Run(args)
+		},
+	}
+	cmd.Flags().StringVarP(&lintOpt.Output, "output", "o", "json", "Output format, json or text.")
+	cmd.Flags().BoolVar(&lintOpt.Strict, "strict", lintOpt.Strict, "Fail on warnings too.")
+	return cmd
+}
+
 func checkClusterTypeAndSetEnvs() {
 	if exutil.PreSetEnvK8s() == "yes" {
 		_ = os.Setenv(exutil.EnvIsExternalOIDCCluster, "no")
@@ -238,6 +324,10 @@ func bindOptions(opt *testginkgo.Options, flags *pflag.FlagSet) {
 	flags.IntVar(&opt.Count, "count", opt.Count, "Run each test a specified number of times. Defaults to 1 or the suite's preferred value.")
 	flags.DurationVar(&opt.Timeout, "timeout", opt.Timeout, "Set the maximum time a test can run before being aborted. This is read from the suite by default, but will be 10 minutes otherwise.")
 	flags.BoolVar(&opt.IncludeSuccessOutput, "include-success", opt.IncludeSuccessOutput, "Print output from successful tests.")
+	flags.BoolVar(&opt.Upgrade, "upgrade", opt.Upgrade, "Run the pre-upgrade checks, upgrade the cluster and run the post-upgrade checks.")
+	flags.StringVar(&opt.UpgradeImage, "upgrade-to-image", opt.UpgradeImage, "The release image to upgrade the cluster to with --upgrade. If empty, wait for an upgrade started externally.")
+	flags.DurationVar(&opt.UpgradeTimeout, "upgrade-timeout", opt.UpgradeTimeout, "The maximum time to wait for the upgrade with --upgrade. Defaults to 3 hours.")
+	flags.StringVar(&opt.UpgradeHandoffDir, "upgrade-handoff-dir", opt.UpgradeHandoffDir, "Store the data passed from the pre-upgrade to the post-upgrade checks in this directory instead of the cluster.")
 	flags.IntVar(&opt.Parallelism, "max-parallel-tests", opt.Parallelism, "Maximum number of tests running in parallel. 0 defaults to test suite recommended value, which is different in each suite.")
 }
 
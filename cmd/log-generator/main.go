@@ -0,0 +1,34 @@
+// log-generator writes lines with sequence numbers to stdout for the logging tests to measure the loss of the
+// collector, see test/extended/util/loggen
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/openshift/openshift-tests-private/test/extended/util/loggen"
+)
+
+func main() {
+	hostname, _ := os.Hostname()
+	config := loggen.Config{}
+	flag.StringVar(&config.Stream, "stream", hostname, "name of the stream in the lines, the pod name by default")
+	flag.StringVar(&config.Format, "format", loggen.FormatUnstructured, "format of the lines: unstructured, structured or multiline")
+	flag.Float64Var(&config.Rate, "rate", 10, "lines per second, 0 means as fast as possible")
+	flag.IntVar(&config.Size, "size", 64, "length of the payload of every line")
+	flag.Int64Var(&config.Count, "count", 0, "number of lines, 0 means unlimited")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	// nothing else is written to the container log, the tests read the last sequence from its tail
+	if _, err := loggen.Run(ctx, config, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	// keep running so that the pod isn't restarted and doesn't generate the sequences again
+	<-ctx.Done()
+}
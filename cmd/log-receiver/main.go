@@ -0,0 +1,39 @@
+// log-receiver is the in-cluster stand-in of the third-party log stores of the ClusterLogForwarder output tests, see
+// test/extended/util/logreceiver
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/openshift/openshift-tests-private/test/extended/util/logreceiver"
+)
+
+func main() {
+	config := logreceiver.Config{}
+	flag.StringVar(&config.APIAddr, "api-addr", ":8080", "address of the API serving the received records")
+	flag.StringVar(&config.HTTPAddr, "http-addr", ":8090", "address receiving HTTP, Splunk HEC, Elasticsearch bulk, Loki push and OTLP/HTTP")
+	flag.StringVar(&config.HTTPSAddr, "https-addr", "", "address receiving the HTTP based protocols over TLS")
+	flag.StringVar(&config.SyslogTCPAddr, "syslog-tcp-addr", ":5140", "address receiving syslog over TCP")
+	flag.StringVar(&config.SyslogUDPAddr, "syslog-udp-addr", ":5140", "address receiving syslog over UDP")
+	flag.StringVar(&config.SyslogTLSAddr, "syslog-tls-addr", "", "address receiving syslog over TLS")
+	flag.StringVar(&config.TLSDir, "tls-dir", "", "directory of tls.crt, tls.key and the client CA ca.crt")
+	flag.BoolVar(&config.ClientAuth, "client-auth", false, "require the TLS clients to present a certificate signed by ca.crt")
+	flag.StringVar(&config.SplunkToken, "splunk-token", "", "HEC token required by the Splunk endpoints")
+	flag.IntVar(&config.MaxRecords, "max-records", 100000, "number of records kept in memory")
+	flag.Parse()
+
+	server, err := logreceiver.NewServer(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := server.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
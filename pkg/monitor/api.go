@@ -28,12 +28,17 @@ import (
 // Start begins monitoring the cluster referenced by the default kube configuration until
 // context is finished.
 func Start(ctx context.Context) (*Monitor, error) {
-	m := NewMonitor()
 	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
 	clusterConfig, err := cfg.ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("could not load client configuration: %v", err)
 	}
+	return StartWithConfig(ctx, clusterConfig)
+}
+
+// StartWithConfig begins monitoring the cluster of the client configuration until context is finished.
+func StartWithConfig(ctx context.Context, clusterConfig *rest.Config) (*Monitor, error) {
+	m := NewMonitor()
 	client, err := kubernetes.NewForConfig(clusterConfig)
 	if err != nil {
 		return nil, err
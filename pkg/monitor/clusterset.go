@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+)
+
+// RunnerMonitor is the monitor of the test runner, it reports the events during the tests and records the conditions
+// of the runner, i.e. the upgrade of the cluster
+type RunnerMonitor interface {
+	Interface
+	Recorder
+}
+
+// StartFromEnvironment begins monitoring the cluster of the default kube configuration until context is finished, and
+// the hosted cluster of GUEST_KUBECONFIG if it's set
+func StartFromEnvironment(ctx context.Context) (RunnerMonitor, error) {
+	hostedKubeconfig := os.Getenv("GUEST_KUBECONFIG")
+	if hostedKubeconfig == "" {
+		m, err := Start(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	m, err := StartClusterSet(ctx, exutil.NewClusterSetFromKubeconfigs(exutil.KubeConfigPath(), hostedKubeconfig))
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ClusterSetMonitor monitors every cluster of a ClusterSet, so that the disruptions of the management and of the
+// hosted clusters are both reported for a test. The conditions recorded by the runner are those of the management
+// cluster.
+type ClusterSetMonitor struct {
+	clusters []*exutil.Cluster
+	monitors []*Monitor
+	started  time.Time
+}
+
+// StartClusterSet begins monitoring every cluster of the set until context is finished.
+func StartClusterSet(ctx context.Context, clusters *exutil.ClusterSet) (*ClusterSetMonitor, error) {
+	m := &ClusterSetMonitor{started: time.Now().UTC()}
+	for _, cluster := range clusters.Clusters() {
+		// the config is loaded from the kubeconfig instead of the CLI of the cluster, which fails the test on errors
+		config, err := clientcmd.BuildConfigFromFlags("", cluster.Kubeconfig())
+		if err != nil {
+			return nil, fmt.Errorf("could not load the client configuration of %s cluster %s: %v", cluster.Role(), cluster.Name(), err)
+		}
+		monitor, err := StartWithConfig(ctx, config)
+		if err != nil {
+			return nil, fmt.Errorf("could not monitor %s cluster %s: %v", cluster.Role(), cluster.Name(), err)
+		}
+		m.clusters = append(m.clusters, cluster)
+		m.monitors = append(m.monitors, monitor)
+	}
+	return m, nil
+}
+
+// locateCluster returns the locator of the cluster, prepended to the locators of its intervals
+func locateCluster(cluster *exutil.Cluster) string {
+	return fmt.Sprintf("cluster/%s role/%s", cluster.Name(), cluster.Role())
+}
+
+// Events returns the events of every cluster between from and to, the locators start with the cluster.
+func (m *ClusterSetMonitor) Events(from, to time.Time) EventIntervals {
+	return m.intervals(func(monitor *Monitor) EventIntervals { return monitor.Events(from, to) })
+}
+
+// Conditions returns the conditions of every cluster between from and to, the locators start with the cluster.
+func (m *ClusterSetMonitor) Conditions(from, to time.Time) EventIntervals {
+	return m.intervals(func(monitor *Monitor) EventIntervals { return monitor.Conditions(from, to) })
+}
+
+func (m *ClusterSetMonitor) intervals(get func(*Monitor) EventIntervals) EventIntervals {
+	var intervals EventIntervals
+	for i, monitor := range m.monitors {
+		locator := locateCluster(m.clusters[i])
+		for _, interval := range get(monitor) {
+			condition := *interval.Condition
+			condition.Locator = locator + " " + condition.Locator
+			intervals = append(intervals, &EventInterval{Condition: &condition, From: interval.From, To: interval.To})
+		}
+	}
+	sort.Sort(intervals)
+	return intervals
+}
+
+// Record records the conditions on the monitor of the management cluster
+func (m *ClusterSetMonitor) Record(conditions ...Condition) {
+	m.monitors[0].Record(conditions...)
+}
+
+// RecordIntervals records the intervals on the monitor of the management cluster
+func (m *ClusterSetMonitor) RecordIntervals(intervals ...*EventInterval) {
+	m.monitors[0].RecordIntervals(intervals...)
+}
+
+// AddSampler adds the sampler to the monitor of the management cluster
+func (m *ClusterSetMonitor) AddSampler(fn SamplerFunc) {
+	m.monitors[0].AddSampler(fn)
+}
+
+// WriteIntervals writes the events of every cluster since the monitoring started to the test output, so they are
+// reported in the monitor timeline of the test
+func (m *ClusterSetMonitor) WriteIntervals(w io.Writer) error {
+	return WriteIntervals(w, m.Events(m.started, time.Now().UTC())...)
+}
@@ -0,0 +1,23 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+)
+
+func TestClusterSetMonitor_Events(t *testing.T) {
+	clusters := exutil.NewClusterSetFromKubeconfigs("/mgmt", "/guest").Clusters()
+	m := &ClusterSetMonitor{clusters: clusters, monitors: []*Monitor{{}, {}}}
+	m.Record(Condition{Level: Error, Locator: "clusterversion/cluster", Message: "upgrade failed"})
+	m.monitors[1].RecordIntervals(&EventInterval{&Condition{Locator: "node/worker-0", Message: "not ready"}, time.Unix(1, 0), time.Unix(2, 0)})
+
+	got := m.Events(time.Time{}, time.Time{})
+	if len(got) != 2 || got[0].Locator != "cluster/hosted role/hosted node/worker-0" || got[1].Locator != "cluster/management role/management clusterversion/cluster" {
+		t.Errorf("unexpected events: %v", got)
+	}
+	if len(m.monitors[0].Events(time.Time{}, time.Time{})) != 1 {
+		t.Errorf("expected the runner conditions on the management cluster")
+	}
+}
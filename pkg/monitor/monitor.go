@@ -14,9 +14,10 @@ type Monitor struct {
 	interval time.Duration
 	samplers []SamplerFunc
 
-	lock    sync.Mutex
-	events  []*Event
-	samples []*sample
+	lock      sync.Mutex
+	events    []*Event
+	samples   []*sample
+	intervals EventIntervals
 }
 
 // NewMonitor creates a monitor with the default sampling interval.
@@ -75,6 +76,17 @@ func (m *Monitor) Record(conditions ...Condition) {
 	}
 }
 
+// RecordIntervals captures intervals measured elsewhere, i.e. by the tests. They are returned by Events
+// if they overlap the requested period.
+func (m *Monitor) RecordIntervals(intervals ...*EventInterval) {
+	if len(intervals) == 0 {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.intervals = append(m.intervals, intervals...)
+}
+
 func (m *Monitor) sample() {
 	m.lock.Lock()
 	samplers := m.samplers
@@ -98,10 +110,10 @@ func (m *Monitor) sample() {
 	})
 }
 
-func (m *Monitor) snapshot() ([]*sample, []*Event) {
+func (m *Monitor) snapshot() ([]*sample, []*Event, EventIntervals) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	return m.samples, m.events
+	return m.samples, m.events, m.intervals
 }
 
 // Conditions returns all conditions that were sampled in the interval
@@ -111,7 +123,7 @@ func (m *Monitor) snapshot() ([]*sample, []*Event) {
 // returned with from == to. No duplicate conditions are returned
 // unless a sampling interval did not report that value.
 func (m *Monitor) Conditions(from, to time.Time) EventIntervals {
-	samples, _ := m.snapshot()
+	samples, _, _ := m.snapshot()
 	return filterSamples(samples, from, to)
 }
 
@@ -119,12 +131,14 @@ func (m *Monitor) Conditions(from, to time.Time) EventIntervals {
 // any sampled conditions that were encountered during that period.
 // EventIntervals are returned in order of their occurrence.
 func (m *Monitor) Events(from, to time.Time) EventIntervals {
-	samples, events := m.snapshot()
+	samples, events, recorded := m.snapshot()
 	intervals := filterSamples(samples, from, to)
 	events = filterEvents(events, from, to)
+	recorded = filterIntervals(recorded, from, to)
 
-	// merge the two sets of inputs
-	mustSort := len(intervals) > 0
+	// merge the three sets of inputs
+	mustSort := len(intervals) > 0 || len(recorded) > 0
+	intervals = append(intervals, recorded...)
 	for i := range events {
 		if i > 0 && events[i-1].At.After(events[i].At) {
 			fmt.Printf("ERROR: event %d out of order\n  %#v\n  %#v\n", i, events[i-1], events[i])
@@ -217,3 +231,17 @@ func filterEvents(events []*Event, from, to time.Time) []*Event {
 	}
 	return events[first:]
 }
+
+func filterIntervals(intervals EventIntervals, from, to time.Time) EventIntervals {
+	var filtered EventIntervals
+	for _, interval := range intervals {
+		if !from.IsZero() && interval.To.Before(from) {
+			continue
+		}
+		if !to.IsZero() && interval.From.After(to) {
+			continue
+		}
+		filtered = append(filtered, interval)
+	}
+	return filtered
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// intervalOutputPrefix marks the lines of the test output carrying the intervals measured by the test. The tests run
+// in their own process, so the runner parses the intervals back from the output to record them and report them in jUnit.
+const intervalOutputPrefix = "monitor-interval: "
+
+// outputInterval is the wire format of an interval in the test output
+type outputInterval struct {
+	Level   EventLevel `json:"level"`
+	Locator string     `json:"locator"`
+	Message string     `json:"message"`
+	From    time.Time  `json:"from"`
+	To      time.Time  `json:"to"`
+}
+
+// WriteIntervals writes the intervals to the test output, one line each
+func WriteIntervals(w io.Writer, intervals ...*EventInterval) error {
+	for _, interval := range intervals {
+		out, err := json.Marshal(outputInterval{Level: interval.Level, Locator: interval.Locator, Message: interval.Message, From: interval.From, To: interval.To})
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s%s\n", intervalOutputPrefix, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseIntervals returns the intervals written to the test output by WriteIntervals, the malformed lines are ignored
+func ParseIntervals(output []byte) EventIntervals {
+	var intervals EventIntervals
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		index := strings.Index(line, intervalOutputPrefix)
+		if index == -1 {
+			continue
+		}
+		var interval outputInterval
+		if err := json.Unmarshal([]byte(line[index+len(intervalOutputPrefix):]), &interval); err != nil {
+			continue
+		}
+		intervals = append(intervals, &EventInterval{
+			Condition: &Condition{Level: interval.Level, Locator: interval.Locator, Message: interval.Message},
+			From:      interval.From,
+			To:        interval.To,
+		})
+	}
+	return intervals
+}
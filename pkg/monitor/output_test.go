@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+func TestMonitor_OutputIntervals(t *testing.T) {
+	intervals := EventIntervals{
+		{&Condition{Level: Info, Locator: "storageclass/gp3-csi op/provision", Message: "pvc/e2e/mypvc"}, time.Unix(1, 0).UTC(), time.Unix(3, 500).UTC()},
+		{&Condition{Level: Warning, Locator: "storageclass/gp3-csi op/attach", Message: "a\nb"}, time.Unix(4, 0).UTC(), time.Unix(9, 0).UTC()},
+	}
+	out := &bytes.Buffer{}
+	out.WriteString("I1019 10:00:00.000000 some test output\n")
+	if err := WriteIntervals(out, intervals...); err != nil {
+		t.Fatal(err)
+	}
+	out.WriteString("monitor-interval: {malformed\nSTEP: the end\n")
+
+	if got := ParseIntervals(out.Bytes()); !reflect.DeepEqual(intervals, got) {
+		t.Errorf("%s", diff.ObjectReflectDiff(intervals, got))
+	}
+}
+
+func TestMonitor_RecordIntervals(t *testing.T) {
+	m := &Monitor{}
+	m.Record(Condition{Message: "event"})
+	m.RecordIntervals(
+		&EventInterval{&Condition{Message: "early"}, time.Unix(1, 0), time.Unix(2, 0)},
+		&EventInterval{&Condition{Message: "overlapping"}, time.Unix(5, 0), time.Unix(20, 0)},
+	)
+
+	got := m.Events(time.Unix(10, 0), time.Time{})
+	if len(got) != 2 || got[0].Message != "overlapping" || got[1].Message != "event" {
+		t.Errorf("unexpected events: %v", got)
+	}
+}
@@ -17,6 +17,7 @@ type Interface interface {
 
 type Recorder interface {
 	Record(conditions ...Condition)
+	RecordIntervals(intervals ...*EventInterval)
 	AddSampler(fn SamplerFunc)
 }
 
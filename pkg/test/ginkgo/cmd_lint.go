@@ -0,0 +1,55 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// LintOptions is used to validate the names of all the tests against the metadata convention
+type LintOptions struct {
+	// Output is the format of the findings, json or text
+	Output string
+	// Strict makes warnings fail the lint too
+	Strict bool
+
+	Out, ErrOut io.Writer
+}
+
+// Run prints the findings of all the tests and returns an ExitError if any of them is an error
+func (opt *LintOptions) Run(args []string) error {
+	tests, err := testsForSuite()
+	if err != nil {
+		return err
+	}
+	findings := lintTests(tests)
+
+	switch opt.Output {
+	case "", "json":
+		encoder := json.NewEncoder(opt.Out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return err
+		}
+	case "text":
+		for _, finding := range findings {
+			fmt.Fprintln(opt.Out, finding)
+		}
+	default:
+		return fmt.Errorf("unsupported output format %q, use json or text", opt.Output)
+	}
+
+	errors, warnings := 0, 0
+	for _, finding := range findings {
+		if finding.Severity == LintSeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Fprintf(opt.ErrOut, "%d errors, %d warnings in %d tests\n", errors, warnings, len(tests))
+	if errors > 0 || opt.Strict && warnings > 0 {
+		return ExitError{Code: 1}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package ginkgo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ListOptions is used to print the tests of a suite with the metadata parsed from their names
+type ListOptions struct {
+	// Output is the format of the list, json or csv
+	Output string
+	Regex  string
+	Filter TestMetadataFilter
+
+	Suites []*TestSuite
+
+	Out, ErrOut io.Writer
+}
+
+// Run prints the tests of the suite in args, or all the tests if no suite is given, matching the filters
+func (opt *ListOptions) Run(args []string) error {
+	var suite *TestSuite
+	if len(args) > 0 {
+		for _, s := range opt.Suites {
+			if s.Name == args[0] {
+				suite = s
+				break
+			}
+		}
+		if suite == nil {
+			fmt.Fprintf(opt.ErrOut, SuitesString(opt.Suites, "Select a test suite to list:\n\n"))
+			return fmt.Errorf("suite %q does not exist", args[0])
+		}
+	} else {
+		suite = &TestSuite{Name: "all", Matches: func(name string) bool { return true }}
+	}
+	// the suite is copied so the regex filter does not change the suites of the command
+	copied := *suite
+	suite = &copied
+	if len(opt.Regex) > 0 {
+		if err := filterWithRegex(suite, opt.Regex); err != nil {
+			return fmt.Errorf("regular expression for filtering tests is invalid: %v", err)
+		}
+	}
+
+	tests, err := testsForSuite()
+	if err != nil {
+		return err
+	}
+	tests = suite.Filter(tests)
+
+	metadata := []TestMetadata{}
+	for _, m := range sortedMetadata(tests) {
+		if opt.Filter.Matches(m) {
+			metadata = append(metadata, m)
+		}
+	}
+
+	switch opt.Output {
+	case "", "json":
+		encoder := json.NewEncoder(opt.Out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(metadata)
+	case "csv":
+		return writeMetadataCSV(opt.Out, metadata)
+	default:
+		return fmt.Errorf("unsupported output format %q, use json or csv", opt.Output)
+	}
+}
+
+// writeMetadataCSV writes a test per row. List fields are joined with spaces.
+func writeMetadataCSV(out io.Writer, metadata []TestMetadata) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"name", "sig", "author", "importance", "polarion_ids", "tags", "labels", "title"}); err != nil {
+		return err
+	}
+	for _, m := range metadata {
+		record := []string{
+			m.Name,
+			m.Sig,
+			m.Author,
+			m.Importance,
+			strings.Join(m.PolarionIDs, " "),
+			strings.Join(m.Tags, " "),
+			strings.Join(m.Labels, " "),
+			m.Title,
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/openshift/openshift-tests-private/pkg/monitor"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 )
 
 // Options is used to run a suite of tests by invoking each test
@@ -33,6 +34,16 @@ type Options struct {
 	Provider     string
 	SuiteOptions string
 
+	// Upgrade runs the PreChkUpgrade checks, upgrades the cluster to UpgradeImage, or waits for an upgrade if it is empty,
+	// and runs the PstChkUpgrade checks. UpgradeHandoffDir stores the data passed between the checks instead of the cluster.
+	Upgrade           bool
+	UpgradeImage      string
+	UpgradeTimeout    time.Duration
+	UpgradeHandoffDir string
+
+	// RunID identifies the run in the tags of the cloud resources created by the tests, it is generated if empty
+	RunID string
+
 	Suites []*TestSuite
 
 	DryRun        bool
@@ -44,6 +55,12 @@ func (opt *Options) AsEnv() []string {
 	var args []string
 	args = append(args, fmt.Sprintf("TEST_PROVIDER=%s", opt.Provider))
 	args = append(args, fmt.Sprintf("TEST_SUITE_OPTIONS=%s", opt.SuiteOptions))
+	if len(opt.UpgradeHandoffDir) > 0 {
+		args = append(args, fmt.Sprintf("%s=%s", exutil.EnvUpgradeHandoffDir, opt.UpgradeHandoffDir))
+	}
+	if len(opt.RunID) > 0 {
+		args = append(args, fmt.Sprintf("%s=%s", exutil.EnvCloudResourceRunID, opt.RunID))
+	}
 	return args
 }
 
@@ -99,6 +116,9 @@ func (opt *Options) Run(args []string) error {
 	}
 
 	tests = suite.Filter(tests)
+	if opt.Upgrade {
+		tests = upgradeTests(tests)
+	}
 	if len(tests) == 0 {
 		return fmt.Errorf("suite %q does not contain any tests", suite.Name)
 	}
@@ -115,6 +135,13 @@ func (opt *Options) Run(args []string) error {
 		tests = newTests
 	}
 
+	if len(opt.RunID) == 0 {
+		opt.RunID = os.Getenv(exutil.EnvCloudResourceRunID)
+	}
+	if len(opt.RunID) == 0 {
+		opt.RunID = fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102-150405"), exutil.GetRandomString())
+	}
+
 	if opt.PrintCommands {
 		status := newTestStatus(opt.Out, true, len(tests), time.Minute, &monitor.Monitor{}, opt.AsEnv())
 		newParallelTestQueue(tests).Execute(context.Background(), 1, status.OutputCommand)
@@ -171,7 +198,7 @@ func (opt *Options) Run(args []string) error {
 	}()
 	signal.Notify(abortCh, syscall.SIGINT, syscall.SIGTERM)
 
-	m, err := monitor.Start(ctx)
+	m, err := monitor.StartFromEnvironment(ctx)
 	if err != nil {
 		return err
 	}
@@ -181,6 +208,8 @@ func (opt *Options) Run(args []string) error {
 		includeSuccess = true
 	}
 	status := newTestStatus(opt.Out, includeSuccess, len(tests), timeout, m, opt.AsEnv())
+	ledger := exutil.NewCloudResourceLedger()
+	status.ledger = ledger
 
 	smoke, normal := splitTests(tests, func(t *testCase) bool {
 		return strings.Contains(t.name, "[Smoke]")
@@ -189,13 +218,19 @@ func (opt *Options) Run(args []string) error {
 	// run the tests
 	start := time.Now()
 
-	// run our smoke tests first
-	q := newParallelTestQueue(smoke)
-	q.Execute(ctx, parallelism, status.Run)
+	var upgradeResults []*JUnitTestCase
+	if opt.Upgrade {
+		// the checks before and after the upgrade
+		upgradeResults = opt.runUpgrade(ctx, tests, parallelism, status, m)
+	} else {
+		// run our smoke tests first
+		q := newParallelTestQueue(smoke)
+		q.Execute(ctx, parallelism, status.Run)
 
-	// run other tests next
-	q = newParallelTestQueue(normal)
-	q.Execute(ctx, parallelism, status.Run)
+		// run other tests next
+		q = newParallelTestQueue(normal)
+		q.Execute(ctx, parallelism, status.Run)
+	}
 
 	duration := time.Now().Sub(start).Round(time.Second / 10)
 	if duration > time.Minute {
@@ -206,7 +241,7 @@ func (opt *Options) Run(args []string) error {
 
 	// monitor the cluster while the tests are running and report any detected
 	// anomalies
-	var syntheticTestResults []*JUnitTestCase
+	syntheticTestResults := upgradeResults
 	if events := m.Events(time.Time{}, time.Time{}); len(events) > 0 {
 		buf, errBuf := &bytes.Buffer{}, &bytes.Buffer{}
 		fmt.Fprintf(buf, "\nTimeline:\n\n")
@@ -262,8 +297,9 @@ func (opt *Options) Run(args []string) error {
 		}
 	}
 
-	// attempt to retry failures to do flake detection
-	if fail > 0 && fail <= suite.MaximumAllowedFlakes {
+	// attempt to retry failures to do flake detection. The upgrade checks are not retried, the cluster the pre-upgrade
+	// checks ran against does not exist anymore.
+	if !opt.Upgrade && fail > 0 && fail <= suite.MaximumAllowedFlakes {
 		var retries []*testCase
 		for _, test := range failing {
 			retries = append(retries, test.Retry())
@@ -274,6 +310,7 @@ func (opt *Options) Run(args []string) error {
 
 		q := newParallelTestQueue(retries)
 		status := newTestStatus(ioutil.Discard, opt.IncludeSuccessOutput, len(retries), timeout, m, opt.AsEnv())
+		status.ledger = ledger
 		q.Execute(ctx, parallelism, status.Run)
 		var flaky []string
 		var repeatFailures []*testCase
@@ -297,12 +334,29 @@ func (opt *Options) Run(args []string) error {
 		fmt.Fprintf(opt.Out, "Failing tests:\n\n%s\n\n", strings.Join(names, "\n"))
 	}
 
+	// delete the cloud resources the tests left behind, even if the run was interrupted
+	report := ledger.Cleanup(context.Background())
+	if len(report.CleanedUp) > 0 || len(report.Leaked) > 0 || len(report.HandedOff) > 0 {
+		fmt.Fprintf(opt.Out, "%s\n\n", strings.TrimSpace(report.String()))
+	}
+	if len(opt.JUnitDir) > 0 {
+		if err := report.WriteFile(opt.JUnitDir); err != nil {
+			fmt.Fprintf(opt.Out, "error: Unable to write the cloud resource report: %v", err)
+		}
+	}
+
 	if len(opt.JUnitDir) > 0 {
 		if err := writeJUnitReport("junit_e2e", "openshift-tests-private", tests, opt.JUnitDir, duration, opt.ErrOut, syntheticTestResults...); err != nil {
 			fmt.Fprintf(opt.Out, "error: Unable to write e2e JUnit results: %v", err)
 		}
 	}
 
+	for _, result := range upgradeResults {
+		if result.FailureOutput != nil {
+			return fmt.Errorf("%s failed: %s. %d fail, %d pass, %d skip (%s)", result.Name, result.FailureOutput.Message, fail, pass, skip, duration)
+		}
+	}
+
 	if fail > 0 {
 		if len(failing) > 0 || suite.MaximumAllowedFlakes == 0 {
 			return fmt.Errorf("%d fail, %d pass, %d skip (%s)", fail, pass, skip, duration)
@@ -74,6 +74,9 @@ type JUnitTestCase struct {
 	// Duration is the time taken in seconds to run the test
 	Duration float64 `xml:"time,attr"`
 
+	// Properties holds the metadata parsed from the test name, and the linked checks of upgrade tests
+	Properties []*TestSuiteProperty `xml:"properties>property,omitempty"`
+
 	// SkipMessage holds the reason why the test was skipped
 	SkipMessage *SkipMessage `xml:"skipped"`
 
@@ -126,9 +129,10 @@ func writeJUnitReport(filePrefix, name string, tests []*testCase, dir string, du
 			s.NumTests++
 			s.NumSkipped++
 			s.TestCases = append(s.TestCases, &JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.out),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.out),
+				Duration:   test.duration.Seconds(),
+				Properties: test.properties(),
 				SkipMessage: &SkipMessage{
 					Message: lastLinesUntil(string(test.out), 100, "skip ["),
 				},
@@ -137,9 +141,10 @@ func writeJUnitReport(filePrefix, name string, tests []*testCase, dir string, du
 			s.NumTests++
 			s.NumFailed++
 			s.TestCases = append(s.TestCases, &JUnitTestCase{
-				Name:      test.name,
-				SystemOut: string(test.out),
-				Duration:  test.duration.Seconds(),
+				Name:       test.name,
+				SystemOut:  string(test.out),
+				Duration:   test.duration.Seconds(),
+				Properties: test.properties(),
 				FailureOutput: &FailureOutput{
 					Output: lastLinesUntil(string(test.out), 100, "fail ["),
 				},
@@ -147,8 +152,9 @@ func writeJUnitReport(filePrefix, name string, tests []*testCase, dir string, du
 		case test.success:
 			s.NumFailed++
 			s.TestCases = append(s.TestCases, &JUnitTestCase{
-				Name:     test.name,
-				Duration: test.duration.Seconds(),
+				Name:       test.name,
+				Duration:   test.duration.Seconds(),
+				Properties: test.properties(),
 			})
 		}
 	}
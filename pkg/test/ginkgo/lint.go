@@ -0,0 +1,231 @@
+package ginkgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Lint rules reported in the findings
+const (
+	LintRuleMissingAuthor     = "missing-author"
+	LintRuleMissingImportance = "missing-importance"
+	LintRuleMissingPolarionID = "missing-polarion-id"
+	LintRuleMisplacedToken    = "misplaced-token"
+	LintRuleUnknownToken      = "unknown-token"
+	LintRuleDuplicateID       = "duplicate-polarion-id"
+	LintRuleMissingDisruptive = "missing-disruptive-label"
+)
+
+// Severities of the findings, only errors make the lint fail
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+// knownTags are the tags the suites and the CI jobs select tests by. A typo in any of them moves the test to another suite.
+var knownTags = map[string]bool{
+	TagNonPreRelease:     true,
+	TagLongduration:      true,
+	TagNonHyperShiftHOST: true,
+	TagConnectedOnly:     true,
+	TagDisconnectedOnly:  true,
+	TagPreChkUpgrade:     true,
+	TagPstChkUpgrade:     true,
+	TagDeprecated:        true,
+	TagLevel0:            true,
+	"ROSA":               true,
+	"OSD_CCS":            true,
+	"ARO":                true,
+	"HyperShiftMGMT":     true,
+	"MicroShiftOnly":     true,
+	"MicroShiftBoth":     true,
+	"VMonly":             true,
+	"WRS":                true,
+	"Smokerun":           true,
+	"StressTest":         true,
+	"CPaasrunOnly":       true,
+	"CPaasrunBoth":       true,
+	"StagerunOnly":       true,
+	"StagerunBoth":       true,
+	"ProdrunBoth":        true,
+}
+
+// disruptiveTitle matches titles of tests that usually reboot nodes or roll out MachineConfigPools, which cannot run in parallel
+var disruptiveTitle = regexp.MustCompile(`(?i)\breboot|\bmachineconfig(pool)?s?\b|\bmcp\b|\bkubeletconfig|\bcontainerruntimeconfig|\bdrain|\bshutdown|\bpower (off|on)\b`)
+
+// LintFinding is a problem found in a test name
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Test     string `json:"test"`
+	// Location is the file and line of the It node, relative to the test/extended directory
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+// String returns the finding in the file:line: severity: message format of compilers and linters
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s: [%s] %s: %q", f.Location, f.Severity, f.Rule, f.Message, f.Test)
+}
+
+// testLocation returns the location of the It node of the test relative to the test/extended directory, and false if
+// the test is not defined in this repository, i.e. the kubernetes e2e tests
+func testLocation(test *testCase) (string, bool) {
+	if len(test.locations) == 0 {
+		return "", false
+	}
+	location := test.locations[len(test.locations)-1]
+	index := strings.LastIndex(location.FileName, "/test/extended/")
+	if index < 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%d", location.FileName[index+len("/test/extended/"):], location.LineNumber), true
+}
+
+// lintTests validates the names of the tests defined in this repository. The findings are sorted by location.
+func lintTests(tests []*testCase) []LintFinding {
+	findings := []LintFinding{}
+	// packages using each Polarion ID, and the first test using it in each package
+	idPackages := map[string]map[string]string{}
+	seen := map[string]bool{}
+	for _, test := range tests {
+		location, ok := testLocation(test)
+		if !ok || seen[test.name] {
+			continue
+		}
+		seen[test.name] = true
+		for _, finding := range lintTestName(test.name, test.metadata) {
+			finding.Location = location
+			findings = append(findings, finding)
+		}
+		pkg := filepath.Dir(strings.SplitN(location, ":", 2)[0])
+		for _, id := range test.metadata.PolarionIDs {
+			if idPackages[id] == nil {
+				idPackages[id] = map[string]string{}
+			}
+			if _, ok := idPackages[id][pkg]; !ok {
+				idPackages[id][pkg] = location
+			}
+		}
+	}
+
+	for id, packages := range idPackages {
+		if len(packages) < 2 {
+			continue
+		}
+		locations := []string{}
+		for _, location := range packages {
+			locations = append(locations, location)
+		}
+		sort.Strings(locations)
+		for _, location := range locations {
+			findings = append(findings, LintFinding{
+				Rule:     LintRuleDuplicateID,
+				Severity: LintSeverityError,
+				Location: location,
+				Message:  fmt.Sprintf("Polarion ID %s is used in %d packages: %s", id, len(locations), strings.Join(locations, ", ")),
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Location < findings[j].Location })
+	return findings
+}
+
+// lintTestName validates a test name against the metadata grammar:
+//
+//	[<tag>-]...Author:<author>-[<tag>-]...<importance>-<polarion id>-[<importance>-<polarion id>-]...<title>
+//
+// and checks that disruptive looking tests are labeled so they do not run in parallel
+func lintTestName(name string, m TestMetadata) []LintFinding {
+	findings := []LintFinding{}
+	report := func(rule, severity, format string, args ...interface{}) {
+		findings = append(findings, LintFinding{Rule: rule, Severity: severity, Test: name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	tokens, _ := metadataTokens(name)
+	if m.Author == "" {
+		report(LintRuleMissingAuthor, LintSeverityError, "the name has no Author:<author> token")
+	}
+	if len(m.Importances) == 0 {
+		report(LintRuleMissingImportance, LintSeverityError, "the name has no importance, use one of Critical, High, Medium or Low")
+	}
+	if len(m.PolarionIDs) == 0 {
+		report(LintRuleMissingPolarionID, LintSeverityError, "the name has no numeric Polarion ID")
+	}
+
+	for i, token := range tokens {
+		switch {
+		case strings.HasPrefix(token, "Author:"):
+			// any author is valid
+		case importances[token]:
+			if i+1 >= len(tokens) || !polarionID.MatchString(tokens[i+1]) {
+				report(LintRuleMisplacedToken, LintSeverityError, "importance %s must be followed by exactly one Polarion ID", token)
+			}
+		case polarionID.MatchString(token):
+			if i == 0 || !importances[tokens[i-1]] {
+				report(LintRuleMisplacedToken, LintSeverityError, "Polarion ID %s must be preceded by exactly one importance", token)
+			}
+		case !knownTags[token]:
+			if suggestion := closestToken(token); suggestion != "" {
+				report(LintRuleUnknownToken, LintSeverityError, "unknown token %q, did you mean %q?", token, suggestion)
+			} else {
+				report(LintRuleUnknownToken, LintSeverityError, "unknown token %q", token)
+			}
+		}
+	}
+
+	if disruptiveTitle.MatchString(m.Title) && !m.HasLabel("Disruptive") && !m.HasLabel("Serial") {
+		report(LintRuleMissingDisruptive, LintSeverityWarning, "the title %q looks disruptive but the name has no [Disruptive] or [Serial] label", disruptiveTitle.FindString(m.Title))
+	}
+	return findings
+}
+
+// closestToken returns the known token with the smallest edit distance to the given one, ignoring case, i.e. "High"
+// for "Hign" or "high". It returns an empty string if no known token is close enough.
+func closestToken(token string) string {
+	candidates := []string{}
+	for known := range importances {
+		candidates = append(candidates, known)
+	}
+	for known := range knownTags {
+		candidates = append(candidates, known)
+	}
+	sort.Strings(candidates)
+
+	closest, distance := "", 3
+	for _, known := range candidates {
+		if d := editDistance(strings.ToLower(token), strings.ToLower(known)); d < distance {
+			closest, distance = known, d
+		}
+	}
+	// short tokens are only a typo of a known token if they differ in case
+	if distance > 0 && len(token) <= 3 {
+		return ""
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,79 @@
+package ginkgo
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func Test_lintTestName(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+	}{
+		{name: "[sig-node] NODE NonPreRelease-Longduration-Author:minmli-High-52313-Medium-52326-set workload resource usage"},
+		{name: "[sig-node] NODE Author:minmli-Medium-39142-kubeletconfig should not prompt duplicate error message", rules: []string{LintRuleMissingDisruptive}},
+		{name: "[sig-node] NODE Author:minmli-Medium-39142-kubeletconfig should not prompt duplicate error message [Serial]"},
+		{name: "[sig-node] NODE Longduration-Author:jianl-high-68398-CVO reconcile SCC resources",
+			rules: []string{LintRuleMissingImportance, LintRuleUnknownToken, LintRuleMisplacedToken}},
+		{name: "[sig-networking] SDN Author:zzhao-Medium-NonPreRelease-Longduration-69134-SR-IOV VFs can be created [Disruptive]",
+			rules: []string{LintRuleMisplacedToken, LintRuleMisplacedToken}},
+		{name: "[sig-node] NODE Author:vvoronko-High-C00210-run cuda-vectoradd",
+			rules: []string{LintRuleMissingPolarionID, LintRuleMisplacedToken, LintRuleUnknownToken}},
+		{name: "[sig-node] NODE Author:minmli-Critical-High-12345-reboot the node [Disruptive]",
+			rules: []string{LintRuleMisplacedToken}},
+		{name: "[sig-node] NODE High-12345-reboot the node [Disruptive]",
+			rules: []string{LintRuleMissingAuthor, LintRuleMissingImportance, LintRuleMissingPolarionID}},
+		{name: "[sig-node] NODE LongDuration-NonPrerelease-Author:minmli-High-12345-set the cgroup version",
+			rules: []string{LintRuleUnknownToken, LintRuleUnknownToken}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []string{}
+			for _, finding := range lintTestName(tt.name, ParseTestMetadata(tt.name)) {
+				rules = append(rules, finding.Rule)
+			}
+			want := append([]string{}, tt.rules...)
+			sort.Strings(rules)
+			sort.Strings(want)
+			if !reflect.DeepEqual(rules, want) {
+				t.Errorf("lintTestName() rules = %v, want %v", rules, want)
+			}
+		})
+	}
+}
+
+func Test_closestToken(t *testing.T) {
+	for token, want := range map[string]string{"high": "High", "Hign": "High", "LongDuration": "Longduration", "NonPrerelease": "NonPreRelease", "OCP": "", "SR": ""} {
+		if got := closestToken(token); got != want {
+			t.Errorf("closestToken(%q) = %q, want %q", token, got, want)
+		}
+	}
+}
+
+func Test_lintTests(t *testing.T) {
+	newTest := func(name, file string, line int) *testCase {
+		return &testCase{
+			name:      name,
+			metadata:  ParseTestMetadata(name),
+			locations: []types.CodeLocation{{FileName: "/go/src/github.com/openshift/openshift-tests-private/test/extended/" + file, LineNumber: line}},
+		}
+	}
+	tests := []*testCase{
+		newTest("[sig-node] NODE Author:minmli-PreChkUpgrade-High-45436-prepare", "node/node.go", 10),
+		newTest("[sig-node] NODE Author:minmli-PstChkUpgrade-High-45436-check", "node/node.go", 20),
+		newTest("[sig-mco] MCO Author:rioliu-High-45436-another test with the same id", "mco/mco.go", 30),
+		{name: "[sig-storage] upstream test", locations: []types.CodeLocation{{FileName: "/go/pkg/mod/k8s.io/kubernetes/test/e2e/storage/volumes.go"}}},
+	}
+	findings := lintTests(tests)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 duplicate ID findings, got %v", findings)
+	}
+	for i, location := range []string{"mco/mco.go:30", "node/node.go:10"} {
+		if findings[i].Rule != LintRuleDuplicateID || findings[i].Location != location {
+			t.Errorf("unexpected finding %s", findings[i])
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package ginkgo
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Importance levels used in the test names
+const (
+	ImportanceCritical = "Critical"
+	ImportanceHigh     = "High"
+	ImportanceMedium   = "Medium"
+	ImportanceLow      = "Low"
+)
+
+// Well known tags used in the test names
+const (
+	TagNonPreRelease     = "NonPreRelease"
+	TagLongduration      = "Longduration"
+	TagNonHyperShiftHOST = "NonHyperShiftHOST"
+	TagConnectedOnly     = "ConnectedOnly"
+	TagDisconnectedOnly  = "DisconnectedOnly"
+	TagPreChkUpgrade     = "PreChkUpgrade"
+	TagPstChkUpgrade     = "PstChkUpgrade"
+	TagDeprecated        = "DEPRECATED"
+	TagLevel0            = "LEVEL0"
+)
+
+var (
+	importances = map[string]bool{ImportanceCritical: true, ImportanceHigh: true, ImportanceMedium: true, ImportanceLow: true}
+	// metadataToken matches the dash separated tokens of the metadata block, i.e. "ROSA", "OSD_CCS", "Author:minmli" or "12345"
+	metadataToken = regexp.MustCompile(`^[A-Za-z0-9_.:]+$`)
+	polarionID    = regexp.MustCompile(`^\d+$`)
+	bracketLabel  = regexp.MustCompile(`\[([^\[\]]+)\]`)
+)
+
+// TestMetadata is the metadata encoded in a test name. The test title in the It node follows the convention
+//
+//	[<tag>-]...Author:<author>-[<tag>-]...<importance>-<polarion id>-[<importance>-<polarion id>-]...<title>
+//
+// i.e. "NonHyperShiftHOST-Longduration-Author:minmli-High-52313-Medium-52326-set workload resource usage [Serial]".
+// The parser accepts the tokens in any order, like GetCurrentTestPolarionIDNumber does, so misplaced tokens are
+// still reported. Names without "Author:" have no metadata but the sig and the bracket labels.
+type TestMetadata struct {
+	Name string `json:"name"`
+	// Sig is the [sig-*] label of the name, without brackets
+	Sig    string `json:"sig,omitempty"`
+	Author string `json:"author,omitempty"`
+	// Importance is the importance of the first case, a test covering several cases may have several levels
+	Importance  string   `json:"importance,omitempty"`
+	Importances []string `json:"importances,omitempty"`
+	PolarionIDs []string `json:"polarionIDs,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Title       string   `json:"title,omitempty"`
+}
+
+// ParseTestMetadata parses the metadata tokens of a test name. It never fails, the fields not found are left empty.
+func ParseTestMetadata(name string) TestMetadata {
+	m := TestMetadata{Name: name}
+	for _, match := range bracketLabel.FindAllStringSubmatch(name, -1) {
+		label := match[1]
+		if strings.HasPrefix(label, "sig-") && m.Sig == "" {
+			m.Sig = label
+			continue
+		}
+		m.Labels = append(m.Labels, label)
+	}
+
+	tokens, title := metadataTokens(name)
+	for _, token := range tokens {
+		switch {
+		case strings.HasPrefix(token, "Author:"):
+			m.Author = strings.TrimPrefix(token, "Author:")
+		case importances[token]:
+			m.Importances = append(m.Importances, token)
+		case polarionID.MatchString(token):
+			m.PolarionIDs = append(m.PolarionIDs, token)
+		default:
+			m.Tags = append(m.Tags, token)
+		}
+	}
+	m.Title = title
+	if len(m.Importances) > 0 {
+		m.Importance = m.Importances[0]
+	}
+	return m
+}
+
+// metadataTokens splits the metadata block of the name in tokens, the rest of the It text is the title.
+// The block starts at the beginning of the It text containing "Author:" and ends at the first token after a Polarion ID
+// that is not another importance or ID. The order of the tokens is not validated here, see lintTestName.
+func metadataTokens(name string) ([]string, string) {
+	author := strings.Index(name, "Author:")
+	if author < 0 {
+		return nil, ""
+	}
+	// the It text starts after the describe texts
+	start := strings.LastIndexAny(name[:author], " \t]") + 1
+	parts := strings.Split(name[start:], "-")
+
+	tokens := []string{}
+	seenID := false
+	i := 0
+	for ; i < len(parts); i++ {
+		token := parts[i]
+		if !metadataToken.MatchString(token) {
+			break
+		}
+		isID := polarionID.MatchString(token)
+		if seenID && !isID && !importances[token] {
+			break
+		}
+		seenID = seenID || isID
+		tokens = append(tokens, token)
+	}
+	return tokens, strings.TrimSpace(strings.Join(parts[i:], "-"))
+}
+
+// FollowsConvention returns true if the name has an author and at least one Polarion ID
+func (m TestMetadata) FollowsConvention() bool {
+	return m.Author != "" && len(m.PolarionIDs) > 0
+}
+
+// HasTag returns true if the name has the metadata tag, i.e. "Longduration"
+func (m TestMetadata) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HasLabel returns true if the name has the bracket label, i.e. "Serial" or "Disruptive"
+func (m TestMetadata) HasLabel(label string) bool {
+	for _, l := range m.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// PolarionID returns the first Polarion ID of the name, the one GetCurrentTestPolarionIDNumber returns
+func (m TestMetadata) PolarionID() string {
+	if len(m.PolarionIDs) == 0 {
+		return ""
+	}
+	return m.PolarionIDs[0]
+}
+
+// Properties returns the metadata as jUnit properties. Lists are joined with commas.
+func (m TestMetadata) Properties() []*TestSuiteProperty {
+	properties := []*TestSuiteProperty{}
+	add := func(name string, values ...string) {
+		if len(values) == 0 || len(values) == 1 && values[0] == "" {
+			return
+		}
+		properties = append(properties, &TestSuiteProperty{Name: name, Value: strings.Join(values, ",")})
+	}
+	add("sig", m.Sig)
+	add("author", m.Author)
+	add("importance", m.Importance)
+	add("polarion-ids", m.PolarionIDs...)
+	add("tags", m.Tags...)
+	add("labels", m.Labels...)
+	return properties
+}
+
+// TestMetadataFilter selects tests by their metadata. Empty fields match all tests.
+type TestMetadataFilter struct {
+	Importances []string
+	Authors     []string
+	Sigs        []string
+	PolarionIDs []string
+	// Tags must all be present, ExcludeTags must all be absent. Bracket labels like "Serial" are accepted as tags too.
+	Tags        []string
+	ExcludeTags []string
+}
+
+// Matches returns true if the metadata passes all the filters
+func (f TestMetadataFilter) Matches(m TestMetadata) bool {
+	if len(f.Importances) > 0 && !containsAny(f.Importances, m.Importances...) {
+		return false
+	}
+	if len(f.Authors) > 0 && !containsAny(f.Authors, m.Author) {
+		return false
+	}
+	if len(f.Sigs) > 0 && !containsAny(f.Sigs, m.Sig, strings.TrimPrefix(m.Sig, "sig-")) {
+		return false
+	}
+	if len(f.PolarionIDs) > 0 && !containsAny(f.PolarionIDs, m.PolarionIDs...) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !m.HasTag(tag) && !m.HasLabel(tag) {
+			return false
+		}
+	}
+	for _, tag := range f.ExcludeTags {
+		if m.HasTag(tag) || m.HasLabel(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(list []string, values ...string) bool {
+	for _, value := range values {
+		for _, item := range list {
+			if value != "" && item == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortedMetadata returns the metadata of the tests sorted by name
+func sortedMetadata(tests []*testCase) []TestMetadata {
+	metadata := make([]TestMetadata, 0, len(tests))
+	for _, test := range tests {
+		metadata = append(metadata, test.metadata)
+	}
+	sort.Slice(metadata, func(i, j int) bool { return metadata[i].Name < metadata[j].Name })
+	return metadata
+}
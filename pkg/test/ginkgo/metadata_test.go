@@ -0,0 +1,98 @@
+package ginkgo
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ParseTestMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		want TestMetadata
+	}{
+		{
+			name: "[sig-node] NODE initContainer policy Author:minmli-High-41579-Liveness probe failures should terminate the pod immediately",
+			want: TestMetadata{Sig: "sig-node", Author: "minmli", Importance: "High", Importances: []string{"High"}, PolarionIDs: []string{"41579"},
+				Title: "Liveness probe failures should terminate the pod immediately"},
+		},
+		{
+			name: "[sig-node] NODE NonHyperShiftHOST-NonPreRelease-Longduration-Author:minmli-High-52313-Medium-52326-set workload resource usage [Disruptive] [Serial]",
+			want: TestMetadata{Sig: "sig-node", Author: "minmli", Importance: "High", Importances: []string{"High", "Medium"}, PolarionIDs: []string{"52313", "52326"},
+				Tags: []string{"NonHyperShiftHOST", "NonPreRelease", "Longduration"}, Labels: []string{"Disruptive", "Serial"},
+				Title: "set workload resource usage [Disruptive] [Serial]"},
+		},
+		{
+			name: "[sig-networking] SDN ROSA-OSD_CCS-ARO-Author:asahay-NonPreRelease-PstChkUpgrade-LEVEL0-Critical-45436-post-check upgrade",
+			want: TestMetadata{Sig: "sig-networking", Author: "asahay", Importance: "Critical", Importances: []string{"Critical"}, PolarionIDs: []string{"45436"},
+				Tags:  []string{"ROSA", "OSD_CCS", "ARO", "NonPreRelease", "PstChkUpgrade", "LEVEL0"},
+				Title: "post-check upgrade"},
+		},
+		{
+			name: "[sig-cli] oc DEPRECATED-Author:pmali-High-12893-Init containers with restart policy Always",
+			want: TestMetadata{Sig: "sig-cli", Author: "pmali", Importance: "High", Importances: []string{"High"}, PolarionIDs: []string{"12893"},
+				Tags: []string{"DEPRECATED"}, Title: "Init containers with restart policy Always"},
+		},
+		{
+			name: "[sig-networking] SDN sriov Author:zzhao-Medium-NonPreRelease-Longduration-69134-SR-IOV VFs can be created [Disruptive]",
+			want: TestMetadata{Sig: "sig-networking", Author: "zzhao", Importance: "Medium", Importances: []string{"Medium"}, PolarionIDs: []string{"69134"},
+				Tags: []string{"NonPreRelease", "Longduration"}, Labels: []string{"Disruptive"}, Title: "SR-IOV VFs can be created [Disruptive]"},
+		},
+		{
+			name: "[sig-api-machinery] API should serve the discovery document [Serial]",
+			want: TestMetadata{Sig: "sig-api-machinery", Labels: []string{"Serial"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Name = tt.name
+			if got := ParseTestMetadata(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTestMetadata() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_TestMetadataFilter(t *testing.T) {
+	critical := ParseTestMetadata("[sig-node] NODE Author:minmli-Critical-11055-/dev/shm is shared [Serial]")
+	longduration := ParseTestMetadata("[sig-node] NODE Longduration-NonPreRelease-Author:pmali-Medium-11600-kubelet evicts pods")
+	tests := []struct {
+		name   string
+		filter TestMetadataFilter
+		want   []bool
+	}{
+		{name: "empty", filter: TestMetadataFilter{}, want: []bool{true, true}},
+		{name: "importance", filter: TestMetadataFilter{Importances: []string{"Critical"}}, want: []bool{true, false}},
+		{name: "author", filter: TestMetadataFilter{Authors: []string{"pmali", "other"}}, want: []bool{false, true}},
+		{name: "sig without prefix", filter: TestMetadataFilter{Sigs: []string{"node"}}, want: []bool{true, true}},
+		{name: "exclude tag", filter: TestMetadataFilter{ExcludeTags: []string{"Longduration"}}, want: []bool{true, false}},
+		{name: "label as tag", filter: TestMetadataFilter{Tags: []string{"Serial"}}, want: []bool{true, false}},
+		{name: "id", filter: TestMetadataFilter{PolarionIDs: []string{"11600"}}, want: []bool{false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []bool{tt.filter.Matches(critical), tt.filter.Matches(longduration)}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_JUnitTestCaseProperties(t *testing.T) {
+	m := ParseTestMetadata("[sig-node] NODE Longduration-Author:minmli-High-52313-set workload resource usage")
+	out, err := xml.Marshal(&JUnitTestCase{Name: m.Name, Properties: m.Properties()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<properties><property name="sig" value="sig-node"></property>`,
+		`<property name="polarion-ids" value="52313"></property>`,
+		`<property name="tags" value="Longduration"></property></properties>`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %s in %s", want, out)
+		}
+	}
+}
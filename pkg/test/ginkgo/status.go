@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/openshift/openshift-tests-private/pkg/monitor"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 )
 
 type testStatus struct {
@@ -21,6 +22,8 @@ type testStatus struct {
 	timeout time.Duration
 	monitor monitor.Interface
 	env     []string
+	// ledger collects the cloud resources registered by the tests, if set
+	ledger *exutil.CloudResourceLedger
 
 	includeSuccessfulOutput bool
 
@@ -120,6 +123,13 @@ func (s *testStatus) Run(ctx context.Context, test *testCase) {
 	}
 	test.duration = duration
 	test.out = out
+	test.intervals = monitor.ParseIntervals(out)
+	if recorder, ok := s.monitor.(monitor.Recorder); ok {
+		recorder.RecordIntervals(test.intervals...)
+	}
+	if s.ledger != nil {
+		s.ledger.Record(out)
+	}
 	if err == nil {
 		test.success = true
 		return
@@ -143,6 +153,16 @@ func (s *testStatus) Run(ctx context.Context, test *testCase) {
 	test.failed = true
 }
 
+// Skip marks the test as skipped without running it, i.e. a post-upgrade check whose pre-upgrade check failed
+func (s *testStatus) Skip(test *testCase, message string) {
+	test.start = time.Now()
+	test.end = test.start
+	test.skipped = true
+	test.out = []byte(fmt.Sprintf("skip [%s]: %s", test.name, message))
+	s.Fprintf(fmt.Sprintf("started: (%s) %q\n\n", "%d/%d/%d", test.name))
+	fmt.Fprintf(s.out, "%s\n\nskipped: (%s) %s %q\n\n", test.out, test.duration, test.end.UTC().Format("2006-01-02T15:04:05"), test.name)
+}
+
 func summarizeTests(tests []*testCase) (int, int, int, []*testCase) {
 	var pass, fail, skip int
 	var failingTests []*testCase
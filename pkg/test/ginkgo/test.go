@@ -12,16 +12,23 @@ import (
 	"time"
 
 	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/openshift/openshift-tests-private/pkg/monitor"
 )
 
 type testCase struct {
 	name      string
 	spec      types.TestSpec
 	locations []types.CodeLocation
+	metadata  TestMetadata
 
 	// identifies which tests can be run in parallel (ginkgo runs suites linearly)
 	testExclusion string
 
+	// upgradePhase is pre or post for the checks run in upgrade mode, linked are the checks of the other phase
+	upgradePhase string
+	linked       []*testCase
+
 	start    time.Time
 	end      time.Time
 	duration time.Duration
@@ -29,6 +36,8 @@ type testCase struct {
 	success  bool
 	failed   bool
 	skipped  bool
+	// intervals are measured by the test and written to its output
+	intervals monitor.EventIntervals
 
 	previous *testCase
 }
@@ -45,6 +54,7 @@ func newTestCaseFromGinkgoSpec(spec types.TestSpec) (*testCase, error) {
 		name:      name,
 		locations: spec.CodeLocations(),
 		spec:      spec,
+		metadata:  ParseTestMetadata(name),
 	}
 
 	return tc, nil
@@ -55,7 +65,10 @@ func (t *testCase) Retry() *testCase {
 		name:          t.name,
 		spec:          t.spec,
 		locations:     t.locations,
+		metadata:      t.metadata,
 		testExclusion: t.testExclusion,
+		upgradePhase:  t.upgradePhase,
+		linked:        t.linked,
 
 		previous: t,
 	}
@@ -82,6 +95,26 @@ type TestSuite struct {
 	TestTimeout time.Duration
 }
 
+// properties returns the jUnit properties of the test: the metadata of the name, the intervals measured by the test,
+// and the linked checks in upgrade mode
+func (t *testCase) properties() []*TestSuiteProperty {
+	properties := t.metadata.Properties()
+	for _, interval := range t.intervals {
+		properties = append(properties, &TestSuiteProperty{
+			Name:  "interval " + interval.Locator,
+			Value: fmt.Sprintf("%.3fs %s", interval.To.Sub(interval.From).Seconds(), interval.Message),
+		})
+	}
+	if t.upgradePhase == "" {
+		return properties
+	}
+	properties = append(properties, &TestSuiteProperty{Name: "upgrade-phase", Value: t.upgradePhase})
+	for _, linked := range t.linked {
+		properties = append(properties, &TestSuiteProperty{Name: "upgrade-linked-test", Value: linked.name})
+	}
+	return properties
+}
+
 func (s *TestSuite) Filter(tests []*testCase) []*testCase {
 	matches := make([]*testCase, 0, len(tests))
 	for _, test := range tests {
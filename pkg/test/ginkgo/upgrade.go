@@ -0,0 +1,274 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclientset "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/openshift/openshift-tests-private/pkg/monitor"
+)
+
+// Phases of the tests run in upgrade mode
+const (
+	upgradePhasePre  = "pre"
+	upgradePhasePost = "post"
+)
+
+const (
+	defaultUpgradeTimeout = 3 * time.Hour
+	upgradePollInterval   = 30 * time.Second
+	clusterVersionLocator = "clusterversion/version"
+)
+
+// upgradeTests returns the PreChkUpgrade and PstChkUpgrade tests, the only ones run in upgrade mode
+func upgradeTests(tests []*testCase) []*testCase {
+	matches := []*testCase{}
+	for _, test := range tests {
+		if test.metadata.HasTag(TagPreChkUpgrade) || test.metadata.HasTag(TagPstChkUpgrade) {
+			matches = append(matches, test)
+		}
+	}
+	return matches
+}
+
+// pairUpgradeTests splits the tests in pre-upgrade and post-upgrade checks and links the checks of the same case,
+// the ones sharing a Polarion ID
+func pairUpgradeTests(tests []*testCase) (pre, post []*testCase) {
+	for _, test := range tests {
+		switch {
+		case test.metadata.HasTag(TagPreChkUpgrade):
+			test.upgradePhase = upgradePhasePre
+			pre = append(pre, test)
+		case test.metadata.HasTag(TagPstChkUpgrade):
+			test.upgradePhase = upgradePhasePost
+			post = append(post, test)
+		}
+	}
+	for _, p := range pre {
+		for _, q := range post {
+			if containsAny(p.metadata.PolarionIDs, q.metadata.PolarionIDs...) {
+				p.linked = append(p.linked, q)
+				q.linked = append(q.linked, p)
+			}
+		}
+	}
+	return pre, post
+}
+
+// failedPreChecks returns the names of the pre-upgrade checks linked to the post-upgrade check that did not pass
+func failedPreChecks(test *testCase) []string {
+	failed := []string{}
+	for _, pre := range test.linked {
+		if !pre.success {
+			failed = append(failed, pre.name)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// runUpgrade runs the pre-upgrade checks, upgrades the cluster, or waits for an upgrade started by someone else, and runs
+// the post-upgrade checks. The post-upgrade checks whose pre-upgrade check failed are skipped, all of them are skipped
+// if the upgrade failed or timed out. It returns the result of
+// the upgrade as a jUnit test case.
+func (opt *Options) runUpgrade(ctx context.Context, tests []*testCase, parallelism int, status *testStatus, m monitor.Recorder) []*JUnitTestCase {
+	pre, post := pairUpgradeTests(tests)
+	for _, test := range append(append([]*testCase{}, pre...), post...) {
+		if len(test.linked) == 0 {
+			fmt.Fprintf(opt.ErrOut, "warning: the %s-upgrade check %q has no matching check with the same Polarion ID\n", test.upgradePhase, test.name)
+		}
+	}
+
+	// the release is checked before the pre-upgrade checks, they are useless if the cluster cannot be upgraded
+	timeout := opt.UpgradeTimeout
+	if timeout == 0 {
+		timeout = defaultUpgradeTimeout
+	}
+	upgrade := newClusterUpgrade(ctx, m, opt.UpgradeImage, timeout)
+	if upgrade.result.FailureOutput != nil {
+		fmt.Fprintf(opt.Out, "%s\n\n%s\n\n", upgrade.result.Name, upgrade.result.FailureOutput.Output)
+		return []*JUnitTestCase{upgrade.result}
+	}
+
+	fmt.Fprintf(opt.Out, "Running %d pre-upgrade checks\n\n", len(pre))
+	newParallelTestQueue(pre).Execute(ctx, parallelism, status.Run)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	result := upgrade.run(ctx)
+	fmt.Fprintf(opt.Out, "%s\n\n", result.Name)
+	if result.FailureOutput != nil {
+		fmt.Fprintf(opt.Out, "%s\n\n", result.FailureOutput.Output)
+	}
+	if ctx.Err() != nil {
+		return []*JUnitTestCase{result}
+	}
+
+	// the post-upgrade checks would run on a cluster in the middle of the upgrade or on the old release
+	if result.FailureOutput != nil {
+		for _, test := range post {
+			status.Skip(test, fmt.Sprintf("the cluster upgrade did not complete: %s", result.FailureOutput.Message))
+		}
+		return []*JUnitTestCase{result}
+	}
+
+	fmt.Fprintf(opt.Out, "Running %d post-upgrade checks\n\n", len(post))
+	newParallelTestQueue(post).Execute(ctx, parallelism, func(ctx context.Context, test *testCase) {
+		if failed := failedPreChecks(test); len(failed) > 0 {
+			status.Skip(test, fmt.Sprintf("the pre-upgrade check did not pass: %s", strings.Join(failed, ", ")))
+			return
+		}
+		status.Run(ctx, test)
+	})
+	return []*JUnitTestCase{result}
+}
+
+// clusterUpgrade is the upgrade of the cluster between the pre and post upgrade checks
+type clusterUpgrade struct {
+	recorder monitor.Recorder
+	client   configclientset.Interface
+	image    string
+	timeout  time.Duration
+	from     configv1.Release
+	start    time.Time
+	result   *JUnitTestCase
+}
+
+// newClusterUpgrade gets the release the cluster runs. The result of the upgrade is a failure already if the ClusterVersion
+// cannot be read, or if the cluster runs the release image to upgrade to.
+func newClusterUpgrade(ctx context.Context, recorder monitor.Recorder, image string, timeout time.Duration) *clusterUpgrade {
+	u := &clusterUpgrade{
+		recorder: recorder,
+		image:    image,
+		timeout:  timeout,
+		start:    time.Now(),
+		result:   &JUnitTestCase{Name: "[sig-cluster-lifecycle] Cluster upgrade between the pre and post upgrade checks"},
+	}
+	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+	clusterConfig, err := cfg.ClientConfig()
+	if err != nil {
+		u.fail("could not load client configuration: %v", err)
+		return u
+	}
+	client, err := configclientset.NewForConfig(clusterConfig)
+	if err != nil {
+		u.fail("could not create the config client: %v", err)
+		return u
+	}
+	u.client = client
+	cv, err := client.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		u.fail("could not get the ClusterVersion: %v", err)
+		return u
+	}
+	u.from = cv.Status.Desired
+	if image != "" && image == u.from.Image {
+		u.fail("the cluster already runs %s, the post-upgrade checks would run on the same release", versionString(u.from))
+	}
+	return u
+}
+
+// fail records the failure of the upgrade in the monitor and in the result
+func (u *clusterUpgrade) fail(format string, args ...interface{}) *JUnitTestCase {
+	message := fmt.Sprintf(format, args...)
+	u.recorder.Record(monitor.Condition{Level: monitor.Error, Locator: clusterVersionLocator, Message: message})
+	u.result.Duration = time.Since(u.start).Seconds()
+	u.result.FailureOutput = &FailureOutput{Message: message, Output: message}
+	return u.result
+}
+
+// run requests the upgrade to the release image, if any, and waits until the ClusterVersion reports it completed.
+// The milestones are recorded in the monitor so they show in the timeline with the events of the upgrade.
+func (u *clusterUpgrade) run(ctx context.Context) *JUnitTestCase {
+	u.start = time.Now()
+	client, from, image, timeout, recorder := u.client, u.from, u.image, u.timeout, u.recorder
+
+	if image != "" {
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			cv, err := client.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			cv.Spec.DesiredUpdate = &configv1.Update{Image: image}
+			_, err = client.ConfigV1().ClusterVersions().Update(ctx, cv, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil {
+			return u.fail("could not request the upgrade to %s: %v", image, err)
+		}
+		recorder.Record(monitor.Condition{Level: monitor.Info, Locator: clusterVersionLocator, Message: fmt.Sprintf("upgrade requested from %s to %s", from.Image, image)})
+	}
+
+	started := false
+	var last *configv1.ClusterVersion
+	err := wait.PollUntilContextTimeout(ctx, upgradePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		cv, err := client.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+		if err != nil {
+			// the API may be unavailable for a short time while the control plane is upgraded
+			return false, nil
+		}
+		last = cv
+		isStarted, completed := upgradeProgress(cv, from.Image)
+		if isStarted && !started {
+			started = true
+			recorder.Record(monitor.Condition{Level: monitor.Info, Locator: clusterVersionLocator, Message: fmt.Sprintf("upgrade started from %s to %s", versionString(from), versionString(cv.Status.Desired))})
+		}
+		return completed, nil
+	})
+	if err != nil {
+		if !started {
+			return u.fail("the cluster did not start upgrading from %s in %s", versionString(from), timeout)
+		}
+		return u.fail("the upgrade from %s to %s did not complete in %s: %s", versionString(from), versionString(last.Status.Desired), timeout, progressingMessage(last))
+	}
+
+	to := versionString(last.Status.Desired)
+	recorder.Record(monitor.Condition{Level: monitor.Info, Locator: clusterVersionLocator, Message: fmt.Sprintf("upgrade completed from %s to %s", versionString(from), to)})
+	u.result.Name = fmt.Sprintf("[sig-cluster-lifecycle] Cluster upgrade from %s to %s between the pre and post upgrade checks", versionString(from), to)
+	u.result.Duration = time.Since(u.start).Seconds()
+	return u.result
+}
+
+// upgradeProgress returns whether the cluster started upgrading from the release image, and whether the upgrade completed
+func upgradeProgress(cv *configv1.ClusterVersion, from string) (started, completed bool) {
+	desired := cv.Status.Desired
+	if desired.Image == "" || desired.Image == from {
+		return false, false
+	}
+	if len(cv.Status.History) == 0 {
+		return true, false
+	}
+	current := cv.Status.History[0]
+	return true, current.Image == desired.Image && current.State == configv1.CompletedUpdate
+}
+
+// progressingMessage returns the message of the Progressing condition of the ClusterVersion
+func progressingMessage(cv *configv1.ClusterVersion) string {
+	if cv == nil {
+		return ""
+	}
+	for _, condition := range cv.Status.Conditions {
+		if condition.Type == configv1.OperatorProgressing {
+			return condition.Message
+		}
+	}
+	return ""
+}
+
+// versionString returns the version of the release, or its image if the version is not known
+func versionString(release configv1.Release) string {
+	if release.Version != "" {
+		return release.Version
+	}
+	return release.Image
+}
@@ -0,0 +1,80 @@
+package ginkgo
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func newUpgradeTestCase(name string) *testCase {
+	return &testCase{name: name, metadata: ParseTestMetadata(name)}
+}
+
+func Test_pairUpgradeTests(t *testing.T) {
+	preMCO := newUpgradeTestCase("[sig-mco] MCO Author:sregidor-NonPreRelease-PreChkUpgrade-High-45436-prepare the pools")
+	postMCO := newUpgradeTestCase("[sig-mco] MCO Author:sregidor-NonPreRelease-PstChkUpgrade-High-45436-check the pools")
+	preNode := newUpgradeTestCase("[sig-node] NODE Author:minmli-PreChkUpgrade-High-45351-prepare crio config")
+	postOrphan := newUpgradeTestCase("[sig-node] NODE Author:minmli-PstChkUpgrade-High-45999-check without pre")
+	other := newUpgradeTestCase("[sig-node] NODE Author:minmli-High-41579-not an upgrade test")
+
+	tests := upgradeTests([]*testCase{preMCO, postMCO, preNode, postOrphan, other})
+	if len(tests) != 4 {
+		t.Fatalf("expected 4 upgrade tests, got %v", testNames(tests))
+	}
+	pre, post := pairUpgradeTests(tests)
+	if !reflect.DeepEqual(pre, []*testCase{preMCO, preNode}) || !reflect.DeepEqual(post, []*testCase{postMCO, postOrphan}) {
+		t.Fatalf("unexpected split pre=%v post=%v", testNames(pre), testNames(post))
+	}
+	if !reflect.DeepEqual(postMCO.linked, []*testCase{preMCO}) || !reflect.DeepEqual(preMCO.linked, []*testCase{postMCO}) {
+		t.Errorf("the MCO checks are not linked")
+	}
+	if len(preNode.linked) != 0 || len(postOrphan.linked) != 0 {
+		t.Errorf("checks without a pair must not be linked")
+	}
+
+	preMCO.failed = true
+	if failed := failedPreChecks(postMCO); !reflect.DeepEqual(failed, []string{preMCO.name}) {
+		t.Errorf("failedPreChecks() = %v", failed)
+	}
+	if failed := failedPreChecks(postOrphan); len(failed) != 0 {
+		t.Errorf("failedPreChecks() of a check without pre = %v", failed)
+	}
+
+	out, err := xml.Marshal(&JUnitTestCase{Name: postMCO.name, Properties: postMCO.properties()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`name="upgrade-phase" value="post"`, `name="upgrade-linked-test" value="` + preMCO.name + `"`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %s in %s", want, out)
+		}
+	}
+}
+
+func Test_upgradeProgress(t *testing.T) {
+	const from, to = "quay.io/openshift-release-dev/ocp-release:4.16.0", "quay.io/openshift-release-dev/ocp-release:4.17.0"
+	tests := []struct {
+		name      string
+		desired   string
+		history   []configv1.UpdateHistory
+		started   bool
+		completed bool
+	}{
+		{name: "not started", desired: from, history: []configv1.UpdateHistory{{Image: from, State: configv1.CompletedUpdate}}},
+		{name: "partial", desired: to, history: []configv1.UpdateHistory{{Image: to, State: configv1.PartialUpdate}, {Image: from, State: configv1.CompletedUpdate}}, started: true},
+		{name: "history not updated yet", desired: to, history: []configv1.UpdateHistory{{Image: from, State: configv1.CompletedUpdate}}, started: true},
+		{name: "completed", desired: to, history: []configv1.UpdateHistory{{Image: to, State: configv1.CompletedUpdate}, {Image: from, State: configv1.CompletedUpdate}}, started: true, completed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := &configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Image: tt.desired}, History: tt.history}}
+			started, completed := upgradeProgress(cv, from)
+			if started != tt.started || completed != tt.completed {
+				t.Errorf("upgradeProgress() = %v, %v, want %v, %v", started, completed, tt.started, tt.completed)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 	"github.com/openshift/openshift-tests-private/test/extended/util/architecture"
+	clusterinfra "github.com/openshift/openshift-tests-private/test/extended/util/clusterinfra"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
 
@@ -76,6 +77,8 @@ func (clusterAutoscaler *clusterAutoscalerDescription) deleteClusterAutoscaler(o
 
 func (machineAutoscaler *machineAutoscalerDescription) createMachineAutoscaler(oc *exutil.CLI) {
 	e2e.Logf("Creating machineautoscaler ...")
+	// the autoscaler can scale the machineset up to maxReplicas, they are leased before
+	clusterinfra.LeaseMachineSetReplicas(oc, machineAutoscaler.machineSetName, machineAutoscaler.maxReplicas)
 	err := applyResourceFromTemplate(oc, "--ignore-unknown-parameters=true", "-f", machineAutoscaler.template, "-p", "NAME="+machineAutoscaler.name, "NAMESPACE="+machineAPINamespace, "MAXREPLICAS="+strconv.Itoa(machineAutoscaler.maxReplicas), "MINREPLICAS="+strconv.Itoa(machineAutoscaler.minReplicas), "MACHINESETNAME="+machineAutoscaler.machineSetName)
 	o.Expect(err).NotTo(o.HaveOccurred())
 }
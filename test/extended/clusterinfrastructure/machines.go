@@ -1113,7 +1113,7 @@ var _ = g.Describe("[sig-cluster-lifecycle] Cluster_Infrastructure MAPI", func()
 
 		g.By("Create a new machineset")
 		machinesetName := infrastructureName + "-46966"
-		ms := clusterinfra.MachineSetDescription{machinesetName, 0}
+		ms := clusterinfra.MachineSetDescription{Name: machinesetName, Replicas: 0}
 		defer clusterinfra.WaitForMachinesDisapper(oc, machinesetName)
 		defer ms.DeleteMachineSet(oc)
 		ms.CreateMachineSet(oc)
@@ -1209,7 +1209,7 @@ var _ = g.Describe("[sig-cluster-lifecycle] Cluster_Infrastructure MAPI", func()
 
 		g.By("Create a new machineset")
 		machinesetName := infrastructureName + "-30379"
-		ms := clusterinfra.MachineSetDescription{machinesetName, 1}
+		ms := clusterinfra.MachineSetDescription{Name: machinesetName, Replicas: 1}
 		defer clusterinfra.WaitForMachinesDisapper(oc, machinesetName)
 		defer ms.DeleteMachineSet(oc)
 		ms.CreateMachineSet(oc)
@@ -1259,7 +1259,7 @@ var _ = g.Describe("[sig-cluster-lifecycle] Cluster_Infrastructure MAPI", func()
 
 		g.By("Create a new machineset")
 		machinesetName := infrastructureName + "-73762"
-		ms := clusterinfra.MachineSetDescription{machinesetName, 1}
+		ms := clusterinfra.MachineSetDescription{Name: machinesetName, Replicas: 1}
 		defer clusterinfra.WaitForMachinesDisapper(oc, machinesetName)
 		defer ms.DeleteMachineSet(oc)
 		ms.CreateMachineSet(oc)
@@ -177,9 +177,11 @@ var _ = g.Describe("[sig-cluster-lifecycle] Cluster_Infrastructure Upgrade", fun
 		machinesetName := infrastructureName + "-72031"
 		ms := clusterinfra.MachineSetDescription{Name: machinesetName, Replicas: 1}
 		ms.CreateMachineSet(oc)
-		//Add a specicacl tag for the original dhcp so that we can find it in PstChkUpgrade case
-		err = awsClient.CreateTag(currentDhcpOptionsID, infrastructureName, "previousdhcp72031")
+		//Save the original dhcp so that the PstChkUpgrade case can restore it
+		err = exutil.NewUpgradeHandoff(oc).Set("previous-dhcp-options", currentDhcpOptionsID)
 		o.Expect(err).NotTo(o.HaveOccurred())
+		//The PstChkUpgrade case deletes the new dhcpOptions, keep it at the end of this run
+		exutil.HandOffCloudResource(exutil.CloudProviderAWS, exutil.CloudResourceDhcpOptions, newDhcpOptionsID)
 
 		machineNameOfMachineSet := clusterinfra.GetMachineNamesFromMachineSet(oc, machinesetName)[0]
 		nodeName := clusterinfra.GetNodeNameFromMachine(oc, machineNameOfMachineSet)
@@ -211,18 +213,11 @@ var _ = g.Describe("[sig-cluster-lifecycle] Cluster_Infrastructure Upgrade", fun
 		o.Expect(err).NotTo(o.HaveOccurred())
 		newDhcpOptionsID, err := awsClient.GetDhcpOptionsIDOfVpc(vpcID)
 		o.Expect(err).NotTo(o.HaveOccurred())
-		previousDhcpOptionsID, err := awsClient.GetDhcpOptionsIDFromTag(infrastructureName, "previousdhcp72031")
-		e2e.Logf("previousDhcpOptionsID:" + strings.Join(previousDhcpOptionsID, "*"))
+		previousDhcpOptionsID, err := exutil.NewUpgradeHandoff(oc).Get("previous-dhcp-options")
 		o.Expect(err).NotTo(o.HaveOccurred())
-		defer func(dhcpOptionsID []string) {
-			if len(dhcpOptionsID) > 0 {
-				e2e.Logf("previousDhcpOptionsID[0]:" + dhcpOptionsID[0])
-			} else {
-				e2e.Fail("there is no previousDhcpOptionsID")
-			}
-			err := awsClient.DeleteTag(dhcpOptionsID[0], infrastructureName, "previousdhcp72031")
-			o.Expect(err).NotTo(o.HaveOccurred())
-			err = awsClient.AssociateDhcpOptions(vpcID, dhcpOptionsID[0])
+		e2e.Logf("previousDhcpOptionsID:" + previousDhcpOptionsID)
+		defer func(dhcpOptionsID string) {
+			err := awsClient.AssociateDhcpOptions(vpcID, dhcpOptionsID)
 			o.Expect(err).NotTo(o.HaveOccurred())
 			err = awsClient.DeleteDhcpOptions(newDhcpOptionsID)
 			o.Expect(err).NotTo(o.HaveOccurred())
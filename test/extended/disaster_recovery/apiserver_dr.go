@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	compute "github.com/openshift/openshift-tests-private/test/extended/util/compute"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
 
@@ -96,7 +97,7 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 			g.Skip(fmt.Sprintf("Cluster health check failed before running case :: %s ", err))
 		}
 		platform := exutil.CheckPlatform(oc)
-		isAzureStack, _ := isAzureStackCluster(oc)
+		isAzureStack, _ := compute.IsAzureStackCluster(oc)
 		exutil.By("1. Get the leader master node of cluster")
 		nodes, cleanup := GetNodes(oc, "master")
 		if cleanup != nil {
@@ -104,7 +105,7 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 		}
 
 		// we're only interested in the leader
-		node := nodes.leaderMasterNodeName(oc)
+		node := leaderMasterNodeName(oc, nodes)
 		if node != nil {
 			nodeName = node.GetName()
 		} else {
@@ -13,6 +13,7 @@ import (
 	o "github.com/onsi/gomega"
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	compute "github.com/openshift/openshift-tests-private/test/extended/util/compute"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
 
@@ -25,21 +26,8 @@ const (
 )
 
 var (
-	startStates = map[string]bool{
-		exutil.BMPoweredOn: true,
-		"running":          true,
-		"active":           true,
-		"ready":            true,
-	}
-	stopStates = map[string]bool{
-		exutil.BMPoweredOff: true,
-		"stopped":           true,
-		"shutoff":           true,
-		"terminated":        true,
-		"paused":            true,
-		"deallocated":       true,
-		"notready":          true,
-	}
+	startStates = compute.RunningStates
+	stopStates  = compute.StoppedStates
 )
 
 // ClusterSanitycheck do sanity check on cluster.
@@ -239,16 +227,6 @@ func ClusterNodesHealthcheck(oc *exutil.CLI, waitTime int, dirname string) error
 	return errNode
 }
 
-func isAzureStackCluster(oc *exutil.CLI) (bool, string) {
-	cloudName, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("infrastructure", "cluster", "-o=jsonpath={.status.platformStatus.azure.cloudName}").Output()
-	o.Expect(err).NotTo(o.HaveOccurred())
-	if strings.ToLower(cloudName) == "azurestackcloud" {
-		e2e.Logf("This is Azure Stack cluster.")
-		return true, cloudName
-	}
-	return false, ""
-}
-
 // Get a random number of int32 type [m,n], n > m
 func getRandomNum(m int32, n int32) int32 {
 	rand.Seed(time.Now().UnixNano())
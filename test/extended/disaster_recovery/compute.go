@@ -5,75 +5,26 @@ import (
 	"os/exec"
 	"strings"
 
-	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	cvers "github.com/openshift/openshift-tests-private/test/extended/mco"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	compute "github.com/openshift/openshift-tests-private/test/extended/util/compute"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
 
 // ComputeNode interface to handle compute node e.g. start or stop
-type ComputeNode interface {
-	GetName() string
-	GetInstanceID() (string, error)
-	Start() error
-	Stop() error
-	State() (string, error)
-}
-
-type instance struct {
-	nodeName string
-	oc       *exutil.CLI
-}
-
-func (i *instance) GetName() string {
-	return i.nodeName
-}
+type ComputeNode = compute.Node
 
 // ComputeNodes handles ComputeNode interface
-type ComputeNodes []ComputeNode
+type ComputeNodes = compute.Nodes
 
 // GetNodes get master nodes according to platform and creds with the specified label.
 func GetNodes(oc *exutil.CLI, label string) (ComputeNodes, func()) {
-	platform := exutil.CheckPlatform(oc)
-	switch platform {
-	case "aws":
-		e2e.Logf("\n AWS is detected, running the case on AWS\n")
-		return GetAwsNodes(oc, label)
-	case "gcp":
-		e2e.Logf("\n GCP is detected, running the case on gcp\n")
-		return GetGcpNodes(oc, label)
-	case "vsphere":
-		e2e.Logf("\n vsphere is detected, running the case on vsphere\n")
-		return GetVsphereNodes(oc, label)
-	case "openstack":
-		e2e.Logf("\n OSP is detected, running the case on osp\n")
-		return GetOspNodes(oc, label)
-	case "azure":
-		e2e.Logf("\n Azure is detected, running the case on azure\n")
-		return GetAzureNodes(oc, label)
-	case "baremetal":
-		e2e.Logf("\n IPI Baremetal is detected, running the case on baremetal\n")
-		return GetBaremetalNodes(oc, label)
-	case "none":
-		e2e.Logf("\n UPI Baremetal is detected, running the case on baremetal\n")
-		return GetUPIBaremetalNodes(oc, label)
-	case "ibmcloud":
-		e2e.Logf("\n IBM is detected, running the case on IBM\n")
-		return GetIbmNodes(oc, label)
-	case "nutanix":
-		e2e.Logf("\n Nutanix is detected, running the case on nutanix\n")
-		return GetNutanixNodes(oc, label)
-	case "powervs":
-		e2e.Logf("\n IBM Powervs is detected, running the case on PowerVs\n")
-		return GetIBMPowerNodes(oc, label)
-	default:
-		g.Skip("Not support cloud provider for DR cases for now. Test cases should be run on IBM or vsphere or aws or gcp or openstack or azure or baremetal, skip for other platforms!!")
-	}
-	return nil, nil
+	return compute.GetNodes(oc, label)
 }
 
-func (n ComputeNodes) leaderMasterNodeName(oc *exutil.CLI) ComputeNode {
+// leaderMasterNodeName returns the node of the leader of kube-controller-manager
+func leaderMasterNodeName(oc *exutil.CLI, n ComputeNodes) ComputeNode {
 	// get clusterversion
 	e2e.Logf("Checking clusterversion")
 	clusterVersion, _, err := exutil.GetClusterVersion(oc)
@@ -2,13 +2,11 @@ package disasterrecovery
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	g "github.com/onsi/ginkgo/v2"
@@ -17,6 +15,7 @@ import (
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/etcdrecovery"
 )
 
 var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
@@ -59,51 +58,40 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 		g.By("Check kube-apiserver oprator status")
 		checkOperator(oc, "kube-apiserver")
 
-		g.By("Run the backup")
-		masterN, etcdDb := runDRBackup(oc, masterNodeList)
+		executor, err := etcdrecovery.NewDebugExecutor(oc)
+		o.Expect(err).NotTo(o.HaveOccurred())
+		recovery, err := etcdrecovery.New(oc, executor, masterNodeList, masterNodeList[0])
+		o.Expect(err).NotTo(o.HaveOccurred())
+		defer recovery.Cleanup()
 
-		defer func() {
-			_, err := exutil.DebugNodeWithOptionsAndChroot(oc, masterN, []string{"-q"}, "rm", "-rf", "/home/core/assets/backup")
-			o.Expect(err).NotTo(o.HaveOccurred())
-		}()
+		g.By("Run the backup")
+		snapshot, err := recovery.Backup()
+		o.Expect(err).NotTo(o.HaveOccurred())
+		o.Expect(recovery.VerifySnapshot(snapshot)).To(o.Succeed())
 
 		g.By("Corrupt the etcd db file ")
-		_, err := exutil.DebugNodeWithOptionsAndChroot(oc, masterN, []string{"-q"}, "truncate", "-s", "126k", etcdDb)
+		_, err = executor.Exec(snapshot.Node, "truncate -s 126k "+snapshot.Path)
 		o.Expect(err).NotTo(o.HaveOccurred())
+		o.Expect(recovery.VerifySnapshot(snapshot)).NotTo(o.Succeed())
 
 		g.By("Run the restore")
-		output, _ := exutil.DebugNodeWithOptionsAndChroot(oc, masterN, []string{"-q"}, "/usr/local/bin/cluster-restore.sh", "/home/core/assets/backup")
-		o.Expect(output).To(o.ContainSubstring("Backup appears corrupted. Aborting!"))
+		err = recovery.Restore(snapshot)
+		o.Expect(err).To(o.MatchError(o.ContainSubstring("Backup appears corrupted. Aborting!")))
 	})
 
 	// author: skundu@redhat.com
 	g.It("Author:skundu-LEVEL0-Longduration-NonPreRelease-Critical-77921-workflow of quorum restoration. [Disruptive][Slow]", func() {
 
-		var (
-			bastionHost    = ""
-			userForBastion = ""
-		)
 		g.By("check the platform is supported or not")
 		supportedList := []string{"aws", "gcp", "azure", "vsphere", "nutanix", "ibmcloud"}
-		platformListWithoutBastion := []string{"vsphere", "nutanix"}
 		support := in(iaasPlatform, supportedList)
 		if support != true {
 			g.Skip("The platform is not supported now, skip the cases!!")
 		}
-		privateKeyForBastion := os.Getenv("SSH_CLOUD_PRIV_KEY")
-		if privateKeyForBastion == "" {
-			g.Skip("Failed to get the private key, skip the cases!!")
-		}
-		withoutBastion := in(iaasPlatform, platformListWithoutBastion)
-		if !withoutBastion {
-			bastionHost = os.Getenv("QE_BASTION_PUBLIC_ADDRESS")
-			if bastionHost == "" {
-				g.Skip("Failed to get the qe bastion public ip, skip the case !!")
-			}
-			userForBastion = getUserNameAndKeyonBationByPlatform(iaasPlatform)
-			if userForBastion == "" {
-				g.Skip("Failed to get the user for bastion host, hence skipping the case!!")
-			}
+		// the commands run over ssh as the API is down until the quorum is restored
+		executor, err := etcdrecovery.NewSSHExecutor(oc, iaasPlatform)
+		if err != nil {
+			g.Skip(fmt.Sprintf("Failed to get the ssh access to the control plane nodes, skip the case: %v", err))
 		}
 
 		g.By("make sure all the etcd pods are running")
@@ -113,14 +101,6 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 		}
 		defer o.Expect(checkEtcdPodStatus(oc, "openshift-etcd")).To(o.BeTrue())
 
-		g.By("select all the master node")
-		masterNodeList := getNodeListByLabel(oc, "node-role.kubernetes.io/master=")
-		masterNodeInternalIPList := getNodeInternalIPListByLabel(oc, "node-role.kubernetes.io/master=")
-
-		e2e.Logf("bastion host is  : %v", bastionHost)
-		e2e.Logf("platform is  : %v", iaasPlatform)
-		e2e.Logf("user on bastion is  : %v", userForBastion)
-
 		g.By("Make sure all the nodes are normal")
 		out, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("node").Output()
 		checkMessage := []string{
@@ -137,84 +117,34 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 		checkOperator(oc, "etcd")
 		g.By("Check kube-apiserver oprator status")
 		checkOperator(oc, "kube-apiserver")
-		g.By("Make the two non-recovery control plane nodes NOT_READY")
-		//if assert err the cluster will be unavailable
-		for i := 1; i < len(masterNodeInternalIPList); i++ {
-			_, err := runPSCommand(bastionHost, masterNodeInternalIPList[i], "sudo /usr/local/bin/disable-etcd.sh", privateKeyForBastion, userForBastion)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			_, err1 := runPSCommand(bastionHost, masterNodeInternalIPList[i], "sudo rm -rf /var/lib/etcd", privateKeyForBastion, userForBastion)
-			o.Expect(err1).NotTo(o.HaveOccurred())
-			_, err2 := runPSCommand(bastionHost, masterNodeInternalIPList[i], "sudo systemctl stop kubelet.service", privateKeyForBastion, userForBastion)
-			o.Expect(err2).NotTo(o.HaveOccurred())
-		}
 
-		g.By("Run the quorum-restore script on the recovery control plane host")
-		msg, err := runPSCommand(bastionHost, masterNodeInternalIPList[0], "sudo -E /usr/local/bin/quorum-restore.sh", privateKeyForBastion, userForBastion)
+		g.By("select all the master node")
+		masterNodeList := getNodeListByLabel(oc, "node-role.kubernetes.io/master=")
+		masterNodes, cleanup := GetNodes(oc, "master")
+		if cleanup != nil {
+			defer cleanup()
+		}
+		recoveryNode := masterNodes[0].GetName()
+		e2e.Logf("platform is  : %v, recovery node is : %v", iaasPlatform, recoveryNode)
+		recovery, err := etcdrecovery.New(oc, executor, masterNodeList, recoveryNode)
 		o.Expect(err).NotTo(o.HaveOccurred())
-		o.Expect(msg).To(o.ContainSubstring("starting restore-etcd static pod"))
+		recovery.ExpectRevisionBump = true
+		defer recovery.Cleanup()
+		o.Expect(recovery.CreateMarkers(etcdrecovery.PhasePreBackup, 3)).To(o.Succeed())
 
-		g.By("Wait for the api server to come up after restore operation.")
-		errW := wait.Poll(20*time.Second, 900*time.Second, func() (bool, error) {
-			out, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("nodes").Output()
-			if err != nil {
-				e2e.Logf("Fail to get master, error: %s. Trying again", err)
-				return false, nil
-			}
-			if matched, _ := regexp.MatchString(masterNodeList[0], out); matched {
-				e2e.Logf("Api is back online:")
-				return true, nil
-			}
-			return false, nil
-		})
-		exutil.AssertWaitPollNoErr(errW, "the Apiserver has not come up after quorum restore operation")
+		g.By("Power off the two non-recovery control plane nodes")
+		//if assert err the cluster will be unavailable
+		o.Expect(recovery.LoseMembers(masterNodes[1:])).To(o.Succeed())
 
-		g.By("Start the kubelet service on both the non-recovery control plane hosts")
-		for i := 1; i < len(masterNodeList); i++ {
-			_, _ = runPSCommand(bastionHost, masterNodeInternalIPList[i], "sudo systemctl start kubelet.service", privateKeyForBastion, userForBastion)
+		g.By("Run the quorum-restore script on the recovery control plane host")
+		o.Expect(recovery.QuorumRestore()).To(o.Succeed())
 
-		}
-		g.By("Wait for the nodes to be Ready.")
-		for i := 0; i < len(masterNodeList); i++ {
-			err := wait.Poll(20*time.Second, 300*time.Second, func() (bool, error) {
-				out, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("node", masterNodeList[i]).Output()
-				if err != nil {
-					e2e.Logf("Fail to get master, error: %s. Trying again", err)
-					return false, nil
-				}
-				if matched, _ := regexp.MatchString(" Ready", out); matched {
-					e2e.Logf("Node %s is back online:\n%s", masterNodeList[i], out)
-					return true, nil
-				}
-				return false, nil
-			})
-			exutil.AssertWaitPollNoErr(err, "the kubelet start has not brought the node online and Ready")
-		}
-		defer checkOperator(oc, "etcd")
-		defer oc.AsAdmin().WithoutNamespace().Run("patch").Args("etcd", "cluster", "--type=merge", "-p", fmt.Sprintf("{\"spec\": {\"unsupportedConfigOverrides\": null}}")).Execute()
-		g.By("Turn off quorum guard to ensure revision rollouts of static pods")
-		errWait := wait.Poll(10*time.Second, 120*time.Second, func() (bool, error) {
-			errGrd := oc.AsAdmin().WithoutNamespace().Run("patch").Args("etcd", "cluster", "--type=merge", "-p", fmt.Sprintf("{\"spec\": {\"unsupportedConfigOverrides\": {\"useUnsupportedUnsafeNonHANonProductionUnstableEtcd\": true}}}")).Execute()
-			if errGrd != nil {
-				e2e.Logf("server is not ready yet, error: %s. Trying again ...", errGrd)
-				return false, nil
-			} else {
-				e2e.Logf("successfully patched.")
-				return true, nil
-			}
-			return false, nil
-		})
-		exutil.AssertWaitPollNoErr(errWait, "unable to patch the server to turn off the quorum guard.")
-
-		// both etcd and kube-apiserver operators start and end roll out almost simultaneously.
-		var wg sync.WaitGroup
-		wg.Add(1)
-		go func() {
-			defer g.GinkgoRecover()
-			defer wg.Done()
-			waitForOperatorRestart(oc, "etcd")
-		}()
-		waitForOperatorRestart(oc, "kube-apiserver")
-		wg.Wait()
+		g.By("Power on the non-recovery control plane nodes, they join the restored cluster as new members")
+		o.Expect(recovery.RecoverMembers()).To(o.Succeed())
+
+		g.By("Wait for the api server, the nodes, etcd and kube-apiserver to recover")
+		o.Expect(recovery.WaitForRecovery(30 * time.Minute)).To(o.Succeed())
+		o.Expect(recovery.VerifyRecovered(nil)).To(o.Succeed())
 	})
 	// author: geliu@redhat.com
 	g.It("Author:geliu-NonPreRelease-Longduration-Critical-50205-lost master can be replaced by new one with machine config recreation in ocp 4.x [Disruptive][Slow]", func() {
@@ -518,31 +448,16 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 	// author: skundu@redhat.com
 	g.It("Author:skundu-Longduration-NonPreRelease-Critical-77922-workflow of point-in-time restoration. [Disruptive][Slow]", func() {
 
-		var (
-			bastionHost    = ""
-			userForBastion = ""
-		)
 		g.By("check the platform is supported or not")
 		supportedList := []string{"aws", "gcp", "azure", "vsphere", "nutanix", "ibmcloud"}
-		platformListWithoutBastion := []string{"vsphere", "nutanix"}
 		support := in(iaasPlatform, supportedList)
 		if support != true {
 			g.Skip("The platform is not supported now, skip the cases!!")
 		}
-		privateKeyForBastion := os.Getenv("SSH_CLOUD_PRIV_KEY")
-		if privateKeyForBastion == "" {
-			g.Skip("Failed to get the private key, skip the cases!!")
-		}
-		withoutBastion := in(iaasPlatform, platformListWithoutBastion)
-		if !withoutBastion {
-			bastionHost = os.Getenv("QE_BASTION_PUBLIC_ADDRESS")
-			if bastionHost == "" {
-				g.Skip("Failed to get the qe bastion public ip, skip the case !!")
-			}
-			userForBastion = getUserNameAndKeyonBationByPlatform(iaasPlatform)
-			if userForBastion == "" {
-				g.Skip("Failed to get the user for bastion host, hence skipping the case!!")
-			}
+		// the commands run over ssh as the API is down until the backup is restored
+		executor, err := etcdrecovery.NewSSHExecutor(oc, iaasPlatform)
+		if err != nil {
+			g.Skip(fmt.Sprintf("Failed to get the ssh access to the control plane nodes, skip the case: %v", err))
 		}
 
 		g.By("make sure all the etcd pods are running")
@@ -554,11 +469,7 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 
 		g.By("select all the master node")
 		masterNodeList := getNodeListByLabel(oc, "node-role.kubernetes.io/master=")
-		masterNodeInternalIPList := getNodeInternalIPListByLabel(oc, "node-role.kubernetes.io/master=")
-
-		e2e.Logf("bastion host is  : %v", bastionHost)
-		e2e.Logf("platform is  : %v", iaasPlatform)
-		e2e.Logf("user on bastion is  : %v", userForBastion)
+		e2e.Logf("platform is  : %v, recovery node is : %v", iaasPlatform, masterNodeList[0])
 
 		g.By("Make sure all the nodes are normal")
 		out, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("node").Output()
@@ -577,88 +488,25 @@ var _ = g.Describe("[sig-disasterrecovery] DR_Testing", func() {
 		g.By("Check kube-apiserver oprator status")
 		checkOperator(oc, "kube-apiserver")
 
+		recovery, err := etcdrecovery.New(oc, executor, masterNodeList, masterNodeList[0])
+		o.Expect(err).NotTo(o.HaveOccurred())
+		defer recovery.Cleanup()
+		o.Expect(recovery.CreateMarkers(etcdrecovery.PhasePreBackup, 3)).To(o.Succeed())
+
 		g.By("Run the backup on the recovery node.")
-		defer runPSCommand(bastionHost, masterNodeInternalIPList[0], "sudo rm -rf /home/core/assets/backup", privateKeyForBastion, userForBastion)
-		err := wait.Poll(20*time.Second, 300*time.Second, func() (bool, error) {
-			msg, err := runPSCommand(bastionHost, masterNodeInternalIPList[0], "sudo /usr/local/bin/cluster-backup.sh /home/core/assets/backup", privateKeyForBastion, userForBastion)
-			if err != nil {
-				e2e.Logf("backup failed with the err:%v, and try next round", err)
-				return false, nil
-			}
-			if o.Expect(msg).To(o.ContainSubstring("snapshot db and kube resources are successfully saved")) {
-				e2e.Logf("backup successfully saved.")
-			}
-			return true, nil
-		})
-		exutil.AssertWaitPollNoErr(err, fmt.Sprintf("backup is failed with error"))
+		snapshot, err := recovery.Backup()
+		o.Expect(err).NotTo(o.HaveOccurred())
+		o.Expect(recovery.VerifySnapshot(snapshot)).To(o.Succeed())
+		o.Expect(recovery.CreateMarkers(etcdrecovery.PhasePostBackup, 3)).To(o.Succeed())
 
 		g.By("Disable the etcd pods on all the control plane nodes")
-		for i := 0; i < len(masterNodeInternalIPList); i++ {
-			_, err := runPSCommand(bastionHost, masterNodeInternalIPList[i], "sudo -E /usr/local/bin/disable-etcd.sh", privateKeyForBastion, userForBastion)
-			o.Expect(err).NotTo(o.HaveOccurred())
-		}
+		o.Expect(recovery.DisableMembers()).To(o.Succeed())
 
 		g.By("Restore the backup on the recovery control plane host")
-		msg, err := runPSCommand(bastionHost, masterNodeInternalIPList[0], "sudo -E /usr/local/bin/cluster-restore.sh /home/core/assets/backup", privateKeyForBastion, userForBastion)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		o.Expect(msg).To(o.ContainSubstring("starting restore-etcd static pod"))
+		o.Expect(recovery.Restore(snapshot)).To(o.Succeed())
 
-		g.By("Wait for the api server to come up after restore operation.")
-		errW := wait.Poll(20*time.Second, 900*time.Second, func() (bool, error) {
-			out, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("nodes").Output()
-			if err != nil {
-				e2e.Logf("Fail to get master, error: %s. Trying again", err)
-				return false, nil
-			}
-			if matched, _ := regexp.MatchString(masterNodeList[0], out); matched {
-				e2e.Logf("Api is back online:")
-				return true, nil
-			}
-			return false, nil
-		})
-		exutil.AssertWaitPollNoErr(errW, "the Apiserver has not come up after point-in-time restore operation")
-
-		g.By("Wait for the nodes to be Ready.")
-		for i := 0; i < len(masterNodeList); i++ {
-			err := wait.Poll(20*time.Second, 300*time.Second, func() (bool, error) {
-				out, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("node", masterNodeList[i]).Output()
-				if err != nil {
-					e2e.Logf("Fail to get master, error: %s. Trying again", err)
-					return false, nil
-				}
-				if matched, _ := regexp.MatchString(" Ready", out); matched {
-					e2e.Logf("Node %s is back online:\n%s", masterNodeList[i], out)
-					return true, nil
-				}
-				return false, nil
-			})
-			exutil.AssertWaitPollNoErr(err, "the kubelet start has not brought the node online and Ready")
-		}
-		defer checkOperator(oc, "etcd")
-		defer oc.AsAdmin().WithoutNamespace().Run("patch").Args("etcd", "cluster", "--type=merge", "-p", fmt.Sprintf("{\"spec\": {\"unsupportedConfigOverrides\": null}}")).Execute()
-		g.By("Turn off quorum guard to ensure revision rollouts of static pods")
-		errWait := wait.Poll(10*time.Second, 120*time.Second, func() (bool, error) {
-			errGrd := oc.AsAdmin().WithoutNamespace().Run("patch").Args("etcd", "cluster", "--type=merge", "-p", fmt.Sprintf("{\"spec\": {\"unsupportedConfigOverrides\": {\"useUnsupportedUnsafeNonHANonProductionUnstableEtcd\": true}}}")).Execute()
-			if errGrd != nil {
-				e2e.Logf("server is not ready yet, error: %s. Trying again ...", errGrd)
-				return false, nil
-			} else {
-				e2e.Logf("successfully patched.")
-				return true, nil
-			}
-			return false, nil
-		})
-		exutil.AssertWaitPollNoErr(errWait, "unable to patch the server to turn off the quorum guard.")
-
-		// both etcd and kube-apiserver operators start and end roll out almost simultaneously.
-		var wg sync.WaitGroup
-		wg.Add(1)
-		go func() {
-			defer g.GinkgoRecover()
-			defer wg.Done()
-			waitForOperatorRestart(oc, "etcd")
-		}()
-		waitForOperatorRestart(oc, "kube-apiserver")
-		wg.Wait()
+		g.By("Wait for the api server, the nodes, etcd and kube-apiserver to recover")
+		o.Expect(recovery.WaitForRecovery(30 * time.Minute)).To(o.Succeed())
+		o.Expect(recovery.VerifyRecovered(snapshot)).To(o.Succeed())
 	})
 })
@@ -0,0 +1,329 @@
+package hypershift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	g "github.com/onsi/ginkgo/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+)
+
+const (
+	historyKindHostedCluster = "HostedCluster"
+	historyKindNodePool      = "NodePool"
+
+	// historyVersion is recorded as a condition whose status is the version, i.e. "4.17.1 Completed" for HostedCluster
+	historyVersion = "Version"
+	// historyAbsent is the status of a condition which was removed, i.e. UpdatingConfig of a NodePool when the update
+	// completed, it's matched by the False state
+	historyAbsent = "Absent"
+)
+
+// conditionTransition is a change of a condition, or of the version, of a HostedCluster or a NodePool
+type conditionTransition struct {
+	kind      string
+	name      string
+	condition string
+	previous  string // the previous status, empty when the condition is first seen
+	status    string
+	reason    string
+	message   string
+	at        time.Time // the lastTransitionTime of the condition, or when the change was seen
+}
+
+func (t conditionTransition) String() string {
+	previous := t.previous
+	if previous == "" {
+		previous = "<none>"
+	}
+	return fmt.Sprintf("%s %s/%s %s: %s -> %s %s %s", t.at.Format(time.RFC3339), t.kind, t.name, t.condition, previous, t.status, t.reason, t.message)
+}
+
+// conditionState is the status of a condition, used to describe the expected transitions
+type conditionState struct {
+	condition string
+	status    string
+}
+
+func (s conditionState) String() string {
+	return s.condition + "=" + s.status
+}
+
+// matches returns true if the status is the one of the state, a removed condition is False
+func (s conditionState) matches(status string) bool {
+	return status == s.status || (status == historyAbsent && s.status == "False")
+}
+
+// conditionHistory records every condition and version transition of a HostedCluster and of its NodePools, the
+// checks of the current snapshot miss the transient states, e.g. a NodePool which was never Updating
+type conditionHistory struct {
+	namespace   string
+	clusterName string
+	started     time.Time
+	cancel      context.CancelFunc
+
+	lock        sync.Mutex
+	transitions []conditionTransition
+	current     map[string]string
+}
+
+// hypershiftObjectStatus is the part of HostedCluster and NodePool the history records
+type hypershiftObjectStatus struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		ClusterName string `json:"clusterName"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []metav1.Condition `json:"conditions"`
+		// Version is a string for NodePool and the ClusterVersion status for HostedCluster
+		Version json.RawMessage `json:"version"`
+	} `json:"status"`
+}
+
+// startConditionHistory watches the HostedCluster and its NodePools on the management cluster until stop is called
+func (h *hostedCluster) startConditionHistory() (*conditionHistory, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	history := &conditionHistory{namespace: h.namespace, clusterName: h.name, started: time.Now().UTC(), cancel: cancel, current: map[string]string{}}
+	for _, watch := range []struct {
+		kind      string
+		resources string
+	}{
+		{historyKindHostedCluster, "hostedclusters"},
+		{historyKindNodePool, "nodepools"},
+	} {
+		kind := watch.kind
+		err := startWatchOperator(ctx, exutil.KubeConfigPath(), operatorWatchInfo{
+			group:      "hypershift.openshift.io",
+			version:    "v1beta1",
+			resources:  watch.resources,
+			namespace:  h.namespace,
+			name:       h.name,
+			addFunc:    func(obj []byte) { history.observe(kind, obj) },
+			updateFunc: func(_ []byte, obj []byte) { history.observe(kind, obj) },
+		})
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to watch %s in %s: %v", watch.resources, h.namespace, err)
+		}
+	}
+	return history, nil
+}
+
+// stop stops watching, the history is kept
+func (c *conditionHistory) stop() {
+	c.cancel()
+}
+
+// stopAndDumpOnFailure stops watching and logs the history if the test failed, it's meant to be deferred
+func (c *conditionHistory) stopAndDumpOnFailure() {
+	c.stop()
+	if g.CurrentSpecReport().Failed() {
+		e2e.Logf("Condition history of HostedCluster %s/%s and its NodePools:\n%s", c.namespace, c.clusterName, c.dump())
+	}
+}
+
+// observe records the changes of the conditions and of the version of the object
+func (c *conditionHistory) observe(kind string, obj []byte) {
+	c.observeAt(kind, obj, time.Now().UTC())
+}
+
+// observeAt records the changes of the object seen at now, the time of the changes without transition time
+func (c *conditionHistory) observeAt(kind string, obj []byte, now time.Time) {
+	var status hypershiftObjectStatus
+	if err := json.Unmarshal(obj, &status); err != nil {
+		e2e.Logf("failed to decode %s: %v", kind, err)
+		return
+	}
+	name := status.Metadata.Name
+	if (kind == historyKindHostedCluster && name != c.clusterName) || (kind == historyKindNodePool && status.Spec.ClusterName != c.clusterName) {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	present := map[string]bool{historyVersion: true}
+	for _, condition := range status.Status.Conditions {
+		at := condition.LastTransitionTime.Time.UTC()
+		if at.IsZero() {
+			at = now
+		}
+		present[condition.Type] = true
+		c.record(conditionTransition{kind: kind, name: name, condition: condition.Type, status: string(condition.Status), reason: condition.Reason, message: condition.Message, at: at})
+	}
+	prefix := kind + "/" + name + "/"
+	for key := range c.current {
+		if condition, ok := strings.CutPrefix(key, prefix); ok && !present[condition] {
+			c.record(conditionTransition{kind: kind, name: name, condition: condition, status: historyAbsent, at: now})
+		}
+	}
+	if version := parseHistoryVersion(status.Status.Version); version != "" {
+		c.record(conditionTransition{kind: kind, name: name, condition: historyVersion, status: version, at: now})
+	}
+}
+
+// record appends the transition if the status changed, the lock must be held
+func (c *conditionHistory) record(t conditionTransition) {
+	key := t.kind + "/" + t.name + "/" + t.condition
+	previous, ok := c.current[key]
+	if ok && previous == t.status {
+		return
+	}
+	t.previous = previous
+	c.current[key] = t.status
+	c.transitions = append(c.transitions, t)
+}
+
+// parseHistoryVersion returns the version of a NodePool, or the version and the state of the last update of a HostedCluster
+func parseHistoryVersion(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var version string
+	if json.Unmarshal(raw, &version) == nil {
+		return version
+	}
+	var clusterVersion struct {
+		History []struct {
+			State   string `json:"state"`
+			Version string `json:"version"`
+		} `json:"history"`
+	}
+	if json.Unmarshal(raw, &clusterVersion) != nil || len(clusterVersion.History) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(clusterVersion.History[0].Version + " " + clusterVersion.History[0].State)
+}
+
+// getTransitions returns the transitions of the object in time order, all its conditions if condition is empty
+func (c *conditionHistory) getTransitions(kind, name, condition string) []conditionTransition {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var transitions []conditionTransition
+	for _, t := range c.transitions {
+		if t.kind == kind && t.name == name && (condition == "" || t.condition == condition) {
+			transitions = append(transitions, t)
+		}
+	}
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].at.Before(transitions[j].at) })
+	return transitions
+}
+
+// dump returns the transitions of all the objects in time order
+func (c *conditionHistory) dump() string {
+	c.lock.Lock()
+	transitions := append([]conditionTransition{}, c.transitions...)
+	c.lock.Unlock()
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].at.Before(transitions[j].at) })
+	lines := make([]string, 0, len(transitions))
+	for _, t := range transitions {
+		lines = append(lines, t.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkTransitions returns an error unless the object went through the states in order, e.g. NodePool Ready=True,
+// UpdatingVersion=True, UpdatingVersion=False, Ready=True, and the time between the first and the last state is at
+// most within. A state is reached when the condition has the status after a transition, so a condition which never
+// changed, like Ready of a NodePool updated without disruption, reaches the state again right away.
+func (c *conditionHistory) checkTransitions(kind, name string, states []conditionState, within time.Duration) error {
+	if len(states) == 0 {
+		return nil
+	}
+	var first, last time.Time
+	current := map[string]string{}
+	next := 0
+	for _, t := range c.getTransitions(kind, name, "") {
+		current[t.condition] = t.status
+		for next < len(states) && states[next].matches(current[states[next].condition]) {
+			if next == 0 {
+				// the status when the history started was set before, the duration is measured from the start
+				first = t.at
+				if first.Before(c.started) {
+					first = c.started
+				}
+			}
+			last = t.at
+			next++
+		}
+		if next == len(states) {
+			break
+		}
+	}
+	if next < len(states) {
+		return fmt.Errorf("%s %s/%s didn't reach %v after %v", kind, c.namespace, name, states[next], states[:next])
+	}
+	if took := last.Sub(first); took > within {
+		return fmt.Errorf("%s %s/%s went through %v in %v, expected within %v", kind, c.namespace, name, states, took, within)
+	}
+	return nil
+}
+
+// firstReached returns when the condition of the object first had the status, a status held when the history started
+// is reached at the start
+func (c *conditionHistory) firstReached(kind, name string, state conditionState) (time.Time, bool) {
+	for _, t := range c.getTransitions(kind, name, state.condition) {
+		if state.matches(t.status) {
+			if t.at.Before(c.started) {
+				return c.started, true
+			}
+			return t.at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// checkNoLongerThan returns an error if the condition of the object kept the status longer than max since from, e.g.
+// NodePool AllMachinesReady=False after the NodePool was first ready, the status still held is measured until now
+func (c *conditionHistory) checkNoLongerThan(kind, name string, state conditionState, from time.Time, max time.Duration) error {
+	if from.Before(c.started) {
+		from = c.started
+	}
+	transitions := c.getTransitions(kind, name, state.condition)
+	for i, t := range transitions {
+		if t.status != state.status {
+			continue
+		}
+		end := time.Now().UTC()
+		if i+1 < len(transitions) {
+			end = transitions[i+1].at
+		}
+		if !end.After(from) {
+			continue
+		}
+		start := t.at
+		if start.Before(from) {
+			start = from
+		}
+		if held := end.Sub(start); held > max {
+			return fmt.Errorf("%s %s/%s kept %v for %v from %s, expected at most %v", kind, c.namespace, name, state, held, start.Format(time.RFC3339), max)
+		}
+	}
+	return nil
+}
+
+// checkNodePoolUpdated returns an error unless the NodePool went updating, not updating and Ready within, without
+// AllMachinesReady=False longer than maxNotReady once it was first Ready, so the provisioning of a NodePool updated
+// right after its creation isn't counted, updatingCondition is UpdatingVersion or UpdatingConfig
+func (c *conditionHistory) checkNodePoolUpdated(npName, updatingCondition string, within, maxNotReady time.Duration) error {
+	ready := conditionState{"Ready", "True"}
+	states := []conditionState{
+		{updatingCondition, "True"},
+		{updatingCondition, "False"},
+		ready,
+	}
+	if err := c.checkTransitions(historyKindNodePool, npName, states, within); err != nil {
+		return err
+	}
+	firstReady, _ := c.firstReached(historyKindNodePool, npName, ready)
+	return c.checkNoLongerThan(historyKindNodePool, npName, conditionState{"AllMachinesReady", "False"}, firstReady, maxNotReady)
+}
@@ -0,0 +1,229 @@
+package hypershift
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodePoolSnapshot is the status of the NodePool np1 of the HostedCluster hc1 at a minute after the start of the history
+type nodePoolSnapshot struct {
+	minute     int
+	conditions map[string]string
+}
+
+// newTestHistory returns the history which observed the snapshots of the NodePool, the history started at start
+func newTestHistory(t *testing.T, start time.Time, snapshots []nodePoolSnapshot) *conditionHistory {
+	history := &conditionHistory{namespace: "clusters", clusterName: "hc1", started: start, current: map[string]string{}}
+	previous := map[string]string{}
+	transitionTimes := map[string]time.Time{}
+	for _, snapshot := range snapshots {
+		at := start.Add(time.Duration(snapshot.minute) * time.Minute)
+		var np hypershiftObjectStatus
+		np.Metadata.Name = "np1"
+		np.Spec.ClusterName = "hc1"
+		for condition, status := range snapshot.conditions {
+			if previous[condition] != status {
+				transitionTimes[condition] = at
+			}
+			np.Status.Conditions = append(np.Status.Conditions, metav1.Condition{Type: condition, Status: metav1.ConditionStatus(status), LastTransitionTime: metav1.NewTime(transitionTimes[condition])})
+		}
+		previous = snapshot.conditions
+		obj, err := json.Marshal(np)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history.observeAt(historyKindNodePool, obj, at)
+	}
+	return history
+}
+
+func TestConditionHistoryCheckTransitions(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Hour)
+	states := []conditionState{{"Ready", "True"}, {"UpdatingConfig", "True"}, {"UpdatingConfig", "False"}, {"Ready", "True"}}
+	for _, tc := range []struct {
+		name      string
+		snapshots []nodePoolSnapshot
+		within    time.Duration
+		expectErr string
+	}{
+		{
+			name: "updated without disruption",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"Ready": "True"}},
+				{5, map[string]string{"Ready": "True", "UpdatingConfig": "True"}},
+				{20, map[string]string{"Ready": "True", "UpdatingConfig": "False"}},
+			},
+			within: 30 * time.Minute,
+		},
+		{
+			name: "updating condition removed when the update completed",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"Ready": "True"}},
+				{5, map[string]string{"Ready": "True", "UpdatingConfig": "True"}},
+				{20, map[string]string{"Ready": "True"}},
+			},
+			within: 30 * time.Minute,
+		},
+		{
+			name: "not ready during the update",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"Ready": "True"}},
+				{5, map[string]string{"Ready": "False", "UpdatingConfig": "True"}},
+				{20, map[string]string{"Ready": "False", "UpdatingConfig": "False"}},
+				{25, map[string]string{"Ready": "True", "UpdatingConfig": "False"}},
+			},
+			within: 30 * time.Minute,
+		},
+		{
+			name: "never updating",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"Ready": "True"}},
+				{20, map[string]string{"Ready": "True", "UpdatingConfig": "False"}},
+			},
+			within:    30 * time.Minute,
+			expectErr: "didn't reach UpdatingConfig=True",
+		},
+		{
+			name: "still updating",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"Ready": "True"}},
+				{5, map[string]string{"Ready": "True", "UpdatingConfig": "True"}},
+			},
+			within:    30 * time.Minute,
+			expectErr: "didn't reach UpdatingConfig=False",
+		},
+		{
+			name: "updated too slowly",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"Ready": "True"}},
+				{5, map[string]string{"Ready": "True", "UpdatingConfig": "True"}},
+				{50, map[string]string{"Ready": "True", "UpdatingConfig": "False"}},
+			},
+			within:    30 * time.Minute,
+			expectErr: "expected within 30m0s",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newTestHistory(t, start, tc.snapshots).checkTransitions(historyKindNodePool, "np1", states, tc.within)
+			if tc.expectErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.expectErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expectErr)) {
+				t.Errorf("expected error %q, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestConditionHistoryCheckNoLongerThan(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Hour)
+	notReady := conditionState{"AllMachinesReady", "False"}
+	for _, tc := range []struct {
+		name      string
+		snapshots []nodePoolSnapshot
+		max       time.Duration
+		from      int
+		expectErr bool
+	}{
+		{
+			name: "always ready",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"AllMachinesReady": "True"}},
+			},
+			max: 10 * time.Minute,
+		},
+		{
+			name: "not ready for a short time",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"AllMachinesReady": "True"}},
+				{5, map[string]string{"AllMachinesReady": "False"}},
+				{10, map[string]string{"AllMachinesReady": "True"}},
+				{30, map[string]string{"AllMachinesReady": "False"}},
+				{35, map[string]string{"AllMachinesReady": "True"}},
+			},
+			max: 10 * time.Minute,
+		},
+		{
+			name: "not ready for too long",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"AllMachinesReady": "True"}},
+				{5, map[string]string{"AllMachinesReady": "False"}},
+				{30, map[string]string{"AllMachinesReady": "True"}},
+			},
+			max:       10 * time.Minute,
+			expectErr: true,
+		},
+		{
+			name: "still not ready is measured until now",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"AllMachinesReady": "True"}},
+				{30, map[string]string{"AllMachinesReady": "False"}},
+			},
+			max:       10 * time.Minute,
+			expectErr: true,
+		},
+		{
+			name: "provisioning before the window is not counted",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"AllMachinesReady": "False"}},
+				{30, map[string]string{"AllMachinesReady": "True"}},
+				{35, map[string]string{"AllMachinesReady": "False"}},
+				{40, map[string]string{"AllMachinesReady": "True"}},
+			},
+			from: 28,
+			max:  10 * time.Minute,
+		},
+		{
+			name: "not ready across the start of the window",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"AllMachinesReady": "False"}},
+				{30, map[string]string{"AllMachinesReady": "True"}},
+			},
+			from:      15,
+			max:       10 * time.Minute,
+			expectErr: true,
+		},
+		{
+			name: "removed condition ends the status",
+			snapshots: []nodePoolSnapshot{
+				{0, map[string]string{"AllMachinesReady": "False"}},
+				{5, map[string]string{}},
+			},
+			max: 10 * time.Minute,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newTestHistory(t, start, tc.snapshots).checkNoLongerThan(historyKindNodePool, "np1", notReady, start.Add(time.Duration(tc.from)*time.Minute), tc.max)
+			if tc.expectErr != (err != nil) {
+				t.Errorf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestConditionHistoryCheckNodePoolUpdated(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Hour)
+	// the config is patched right after the NodePool is created, the machines are provisioned with the new config
+	history := newTestHistory(t, start, []nodePoolSnapshot{
+		{0, map[string]string{"Ready": "False", "AllMachinesReady": "False"}},
+		{1, map[string]string{"Ready": "False", "AllMachinesReady": "False", "UpdatingConfig": "True"}},
+		{25, map[string]string{"Ready": "True", "AllMachinesReady": "True"}},
+	})
+	if err := history.checkNodePoolUpdated("np1", "UpdatingConfig", 30*time.Minute, 10*time.Minute); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// a ready NodePool whose machines are replaced one at a time
+	history = newTestHistory(t, start, []nodePoolSnapshot{
+		{0, map[string]string{"Ready": "True", "AllMachinesReady": "True"}},
+		{5, map[string]string{"Ready": "True", "AllMachinesReady": "False", "UpdatingConfig": "True"}},
+		{30, map[string]string{"Ready": "True", "AllMachinesReady": "True"}},
+	})
+	if err := history.checkNodePoolUpdated("np1", "UpdatingConfig", 30*time.Minute, 10*time.Minute); err == nil || !strings.Contains(err.Error(), "AllMachinesReady=False") {
+		t.Errorf("expected AllMachinesReady=False to be held too long, got %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+package hypershift
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+)
+
+const (
+	// cpReportMaxRestarts is the number of container restarts of a pod above which the report fails
+	cpReportMaxRestarts = 3
+
+	zoneLabelKey     = "topology.kubernetes.io/zone"
+	hostnameLabelKey = "kubernetes.io/hostname"
+)
+
+// cpHAComponents are the control plane workloads which have several replicas with the HighlyAvailable policy, they
+// must have a priority class so that they aren't preempted by the other workloads of the management cluster
+var cpHAComponents = []string{"kube-apiserver", "etcd", "openshift-apiserver", "openshift-oauth-apiserver", "oauth-openshift"}
+
+// cpWorkloadReport is the health and the placement of a control plane Deployment or StatefulSet
+type cpWorkloadReport struct {
+	kind            string
+	name            string
+	replicas        int32
+	readyReplicas   int32
+	priorityClass   string
+	antiAffinity    string // the topology keys of the required pod anti-affinity
+	pdb             string
+	nodes           []string
+	zones           []string
+	restarts        int32  // the most container restarts of a pod
+	restartedPod    string // the pod with the most restarts
+	cpuRequests     resource.Quantity
+	memoryRequests  resource.Quantity
+	missingRequests []string // containers without cpu or memory requests
+	problems        []string
+}
+
+// cpPlacementReport is the report of all the workloads of the control plane namespace of a HostedCluster
+type cpPlacementReport struct {
+	namespace       string
+	highlyAvailable bool
+	zones           int // the zones of the management cluster nodes
+	workloads       []cpWorkloadReport
+}
+
+// getControlPlaneReport returns the report of the control plane of the hosted cluster on the management cluster, the
+// workloads are checked against the controllerAvailabilityPolicy of the HostedCluster
+func (h *hostedCluster) getControlPlaneReport() (*cpPlacementReport, error) {
+	ctx := context.Background()
+	client := h.oc.AdminKubeClient()
+	report := &cpPlacementReport{namespace: h.getHostedComponentNamespace(), highlyAvailable: h.isCPHighlyAvailable()}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	nodeZones := map[string]string{}
+	zones := map[string]bool{}
+	for _, node := range nodes.Items {
+		if zone := node.Labels[zoneLabelKey]; zone != "" {
+			nodeZones[node.Name] = zone
+			zones[zone] = true
+		}
+	}
+	report.zones = len(zones)
+
+	pods, err := client.CoreV1().Pods(report.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %v", report.namespace, err)
+	}
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets(report.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list poddisruptionbudgets in %s: %v", report.namespace, err)
+	}
+	deployments, err := client.AppsV1().Deployments(report.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in %s: %v", report.namespace, err)
+	}
+	statefulSets, err := client.AppsV1().StatefulSets(report.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets in %s: %v", report.namespace, err)
+	}
+
+	for _, d := range deployments.Items {
+		w := cpWorkloadReport{kind: "Deployment", name: d.Name, replicas: ptrInt32(d.Spec.Replicas), readyReplicas: d.Status.ReadyReplicas}
+		report.add(w, d.Spec.Selector, d.Spec.Template, pods.Items, pdbs.Items, nodeZones)
+	}
+	for _, s := range statefulSets.Items {
+		w := cpWorkloadReport{kind: "StatefulSet", name: s.Name, replicas: ptrInt32(s.Spec.Replicas), readyReplicas: s.Status.ReadyReplicas}
+		report.add(w, s.Spec.Selector, s.Spec.Template, pods.Items, pdbs.Items, nodeZones)
+	}
+	sort.Slice(report.workloads, func(i, j int) bool {
+		return report.workloads[i].kind+"/"+report.workloads[i].name < report.workloads[j].kind+"/"+report.workloads[j].name
+	})
+	return report, nil
+}
+
+func ptrInt32(i *int32) int32 {
+	if i == nil {
+		return 1
+	}
+	return *i
+}
+
+// add fills the workload report from its pods and pod template, checks it and adds it to the report
+func (r *cpPlacementReport) add(w cpWorkloadReport, selector *metav1.LabelSelector, template corev1.PodTemplateSpec, pods []corev1.Pod,
+	pdbs []policyv1.PodDisruptionBudget, nodeZones map[string]string) {
+	w.priorityClass = template.Spec.PriorityClassName
+	w.antiAffinity = requiredAntiAffinityKeys(template.Spec.Affinity)
+	for _, pdb := range pdbs {
+		if s, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector); err == nil && !s.Empty() && s.Matches(labels.Set(template.Labels)) {
+			w.pdb = pdb.Name
+			break
+		}
+	}
+	for _, c := range template.Spec.Containers {
+		cpu, hasCPU := c.Resources.Requests[corev1.ResourceCPU]
+		memory, hasMemory := c.Resources.Requests[corev1.ResourceMemory]
+		if !hasCPU || !hasMemory {
+			w.missingRequests = append(w.missingRequests, c.Name)
+		}
+		w.cpuRequests.Add(cpu)
+		w.memoryRequests.Add(memory)
+	}
+
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		w.problems = append(w.problems, fmt.Sprintf("invalid selector: %v", err))
+	}
+	zones := map[string]bool{}
+	for _, pod := range pods {
+		if podSelector == nil || !podSelector.Matches(labels.Set(pod.Labels)) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		var restarts int32
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			restarts += status.RestartCount
+		}
+		if restarts > w.restarts {
+			w.restarts, w.restartedPod = restarts, pod.Name
+		}
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		w.nodes = append(w.nodes, pod.Spec.NodeName)
+		if zone := nodeZones[pod.Spec.NodeName]; zone != "" && !zones[zone] {
+			zones[zone] = true
+			w.zones = append(w.zones, zone)
+		}
+	}
+	sort.Strings(w.nodes)
+	sort.Strings(w.zones)
+
+	w.problems = append(w.problems, r.check(w)...)
+	r.workloads = append(r.workloads, w)
+}
+
+// requiredAntiAffinityKeys returns the topology keys of the required pod anti-affinity terms
+func requiredAntiAffinityKeys(affinity *corev1.Affinity) string {
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return ""
+	}
+	var keys []string
+	for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		keys = append(keys, term.TopologyKey)
+	}
+	return strings.Join(keys, ",")
+}
+
+// check returns the problems of the workload against the availability policy of the control plane
+func (r *cpPlacementReport) check(w cpWorkloadReport) []string {
+	var problems []string
+	if w.readyReplicas < w.replicas {
+		problems = append(problems, fmt.Sprintf("%d of %d replicas ready", w.readyReplicas, w.replicas))
+	}
+	if w.priorityClass == "" && isCPHAComponent(w.name) {
+		problems = append(problems, "no priority class")
+	}
+	if len(w.missingRequests) > 0 {
+		problems = append(problems, fmt.Sprintf("no cpu or memory requests for %v", w.missingRequests))
+	}
+	if w.restarts > cpReportMaxRestarts {
+		problems = append(problems, fmt.Sprintf("%d restarts of pod %s", w.restarts, w.restartedPod))
+	}
+
+	if !r.highlyAvailable {
+		if w.replicas > 1 {
+			problems = append(problems, fmt.Sprintf("%d replicas with SingleReplica policy", w.replicas))
+		}
+		return problems
+	}
+	if isCPHAComponent(w.name) && w.replicas < 2 {
+		problems = append(problems, fmt.Sprintf("%d replica with HighlyAvailable policy", w.replicas))
+	}
+	if w.replicas < 2 {
+		return problems
+	}
+	if w.pdb == "" {
+		problems = append(problems, "no pod disruption budget")
+	}
+	// the replicas may share nodes and zones unless the anti-affinity spreads them
+	if strings.Contains(w.antiAffinity, hostnameLabelKey) && countDistinct(w.nodes) < len(w.nodes) {
+		problems = append(problems, fmt.Sprintf("replicas share nodes %v", w.nodes))
+	}
+	if strings.Contains(w.antiAffinity, zoneLabelKey) {
+		if expected := min(len(w.nodes), r.zones); len(w.zones) < expected {
+			problems = append(problems, fmt.Sprintf("replicas spread on %d zones, expected %d", len(w.zones), expected))
+		}
+	}
+	return problems
+}
+
+func isCPHAComponent(name string) bool {
+	for _, component := range cpHAComponents {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
+
+func countDistinct(values []string) int {
+	distinct := map[string]bool{}
+	for _, v := range values {
+		distinct[v] = true
+	}
+	return len(distinct)
+}
+
+// failedWorkloads returns the workloads with problems
+func (r *cpPlacementReport) failedWorkloads() []cpWorkloadReport {
+	var failed []cpWorkloadReport
+	for _, w := range r.workloads {
+		if len(w.problems) > 0 {
+			failed = append(failed, w)
+		}
+	}
+	return failed
+}
+
+// passed returns true if no workload has problems
+func (r *cpPlacementReport) passed() bool {
+	return len(r.failedWorkloads()) == 0
+}
+
+// String returns the report as a table followed by the problems
+func (r *cpPlacementReport) String() string {
+	policy := SingleReplica
+	if r.highlyAvailable {
+		policy = HighlyAvailable
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Control plane %s, %s, %d zones\n", r.namespace, policy, r.zones)
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORKLOAD\tREADY\tPRIORITY\tPDB\tANTI-AFFINITY\tNODES\tZONES\tRESTARTS\tCPU\tMEMORY\tRESULT")
+	for _, w := range r.workloads {
+		result := "pass"
+		if len(w.problems) > 0 {
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s/%s\t%d/%d\t%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\t%s\n", w.kind, w.name, w.readyReplicas, w.replicas, orNone(w.priorityClass),
+			orNone(w.pdb), orNone(w.antiAffinity), countDistinct(w.nodes), orNone(strings.Join(w.zones, ",")), w.restarts,
+			w.cpuRequests.String(), w.memoryRequests.String(), result)
+	}
+	tw.Flush()
+	for _, w := range r.failedWorkloads() {
+		fmt.Fprintf(&b, "%s/%s: %s\n", w.kind, w.name, strings.Join(w.problems, "; "))
+	}
+	return b.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// writeArtifact writes the report to ARTIFACT_DIR and returns the path of the file
+func (r *cpPlacementReport) writeArtifact() (string, error) {
+	path := exutil.ArtifactPath("hypershift", "controlplane-report-"+r.namespace+".txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(r.String()), 0644)
+}
+
+// checkControlPlaneReport returns an error with the problems of the control plane of the hosted cluster, the report is
+// logged and written to ARTIFACT_DIR on failure
+func (h *hostedCluster) checkControlPlaneReport() error {
+	report, err := h.getControlPlaneReport()
+	if err != nil {
+		return err
+	}
+	e2e.Logf("%s", report)
+	if report.passed() {
+		return nil
+	}
+	if path, err := report.writeArtifact(); err != nil {
+		e2e.Logf("failed to write the control plane report: %v", err)
+	} else {
+		e2e.Logf("the control plane report is written to %s", path)
+	}
+	failed := report.failedWorkloads()
+	names := make([]string, 0, len(failed))
+	for _, w := range failed {
+		names = append(names, w.kind+"/"+w.name)
+	}
+	return fmt.Errorf("control plane %s has problems in %v", report.namespace, names)
+}
@@ -0,0 +1,122 @@
+package hypershift
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestControlPlaneReportCheck(t *testing.T) {
+	haWorkload := func(modify func(w *cpWorkloadReport)) cpWorkloadReport {
+		w := cpWorkloadReport{kind: "Deployment", name: "kube-apiserver", replicas: 3, readyReplicas: 3, priorityClass: "hypershift-api-critical",
+			antiAffinity: zoneLabelKey + "," + hostnameLabelKey, pdb: "kube-apiserver", nodes: []string{"n1", "n2", "n3"}, zones: []string{"a", "b", "c"}}
+		modify(&w)
+		return w
+	}
+	for _, tc := range []struct {
+		name            string
+		highlyAvailable bool
+		workload        cpWorkloadReport
+		expected        []string
+	}{
+		{
+			name:            "HA component spread on nodes and zones",
+			highlyAvailable: true,
+			workload:        haWorkload(func(w *cpWorkloadReport) {}),
+		},
+		{
+			name:            "HA component without priority class",
+			highlyAvailable: true,
+			workload:        haWorkload(func(w *cpWorkloadReport) { w.priorityClass = "" }),
+			expected:        []string{"no priority class"},
+		},
+		{
+			name:            "other workload without priority class",
+			highlyAvailable: true,
+			workload:        cpWorkloadReport{kind: "Deployment", name: "cluster-version-operator", replicas: 1, readyReplicas: 1},
+		},
+		{
+			name:            "restarts of a pod above the limit",
+			highlyAvailable: true,
+			workload:        haWorkload(func(w *cpWorkloadReport) { w.restarts, w.restartedPod = 4, "kube-apiserver-1" }),
+			expected:        []string{"4 restarts of pod kube-apiserver-1"},
+		},
+		{
+			name:            "replicas share nodes despite the hostname anti-affinity",
+			highlyAvailable: true,
+			workload:        haWorkload(func(w *cpWorkloadReport) { w.nodes = []string{"n1", "n1", "n3"} }),
+			expected:        []string{"replicas share nodes [n1 n1 n3]"},
+		},
+		{
+			name:            "replicas share nodes without anti-affinity",
+			highlyAvailable: true,
+			workload: cpWorkloadReport{kind: "Deployment", name: "ignition-server", replicas: 2, readyReplicas: 2, pdb: "ignition-server",
+				nodes: []string{"n1", "n1"}, zones: []string{"a"}},
+		},
+		{
+			name:            "replicas in less zones than the zone anti-affinity",
+			highlyAvailable: true,
+			workload:        haWorkload(func(w *cpWorkloadReport) { w.zones = []string{"a", "b"} }),
+			expected:        []string{"replicas spread on 2 zones, expected 3"},
+		},
+		{
+			name:            "HA component with a single replica",
+			highlyAvailable: true,
+			workload: haWorkload(func(w *cpWorkloadReport) {
+				w.replicas, w.readyReplicas, w.nodes, w.zones = 1, 1, []string{"n1"}, []string{"a"}
+			}),
+			expected: []string{"1 replica with HighlyAvailable policy"},
+		},
+		{
+			name:            "replicas not ready and no pod disruption budget",
+			highlyAvailable: true,
+			workload:        haWorkload(func(w *cpWorkloadReport) { w.readyReplicas, w.pdb = 2, "" }),
+			expected:        []string{"2 of 3 replicas ready", "no pod disruption budget"},
+		},
+		{
+			name:     "several replicas with the SingleReplica policy",
+			workload: haWorkload(func(w *cpWorkloadReport) {}),
+			expected: []string{"3 replicas with SingleReplica policy"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := &cpPlacementReport{namespace: "clusters-hc1", highlyAvailable: tc.highlyAvailable, zones: 3}
+			if problems := report.check(tc.workload); !reflect.DeepEqual(problems, tc.expected) {
+				t.Errorf("got problems %q, expected %q", problems, tc.expected)
+			}
+		})
+	}
+}
+
+func TestControlPlaneReportAddCountsRestartsPerPod(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "etcd"}}
+	pod := func(name, node string, restarts ...int32) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"app": "etcd"}}, Spec: corev1.PodSpec{NodeName: node}}
+		for _, r := range restarts {
+			p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, corev1.ContainerStatus{RestartCount: r})
+		}
+		return p
+	}
+	report := &cpPlacementReport{namespace: "clusters-hc1", highlyAvailable: true, zones: 3}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "etcd"}},
+		Spec:       corev1.PodSpec{PriorityClassName: "hypershift-etcd"},
+	}
+	// 6 restarts in total but at most 3 in a pod
+	pods := []corev1.Pod{pod("etcd-0", "n1", 2, 1), pod("etcd-1", "n2", 2), pod("etcd-2", "n3", 1)}
+	report.add(cpWorkloadReport{kind: "StatefulSet", name: "etcd", replicas: 3, readyReplicas: 3}, selector, template, pods, nil,
+		map[string]string{"n1": "a", "n2": "b", "n3": "c"})
+
+	w := report.workloads[0]
+	if w.restarts != 3 || w.restartedPod != "etcd-0" {
+		t.Errorf("got %d restarts of pod %s, expected 3 of etcd-0", w.restarts, w.restartedPod)
+	}
+	if !reflect.DeepEqual(w.nodes, []string{"n1", "n2", "n3"}) || !reflect.DeepEqual(w.zones, []string{"a", "b", "c"}) {
+		t.Errorf("got nodes %v and zones %v", w.nodes, w.zones)
+	}
+	if !reflect.DeepEqual(w.problems, []string{"no pod disruption budget"}) {
+		t.Errorf("got problems %q", w.problems)
+	}
+}
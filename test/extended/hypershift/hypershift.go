@@ -1365,6 +1365,11 @@ var _ = g.Describe("[sig-hypershift] Hypershift", func() {
 		cm.create(oc, "", parsedCMFile)
 		doOcpReq(oc, OcpGet, true, "configmap", configmapName, "-n", hostedcluster.namespace)
 
+		g.By("record the condition history of the nodepools")
+		history, err := hostedcluster.startConditionHistory()
+		o.Expect(err).NotTo(o.HaveOccurred())
+		defer history.stopAndDumpOnFailure()
+
 		g.By("create a nodepool")
 		npName := "np-52318"
 		npCount := 1
@@ -1387,6 +1392,7 @@ var _ = g.Describe("[sig-hypershift] Hypershift", func() {
 			return doOcpReq(oc, OcpGet, false, "nodepool", npName, "-n", hostedcluster.namespace, `-ojsonpath={.status.conditions[?(@.type=="UpdatingConfig")].status}`)
 		}, LongTimeout, LongTimeout/10).Should(o.BeEmpty(), "nodepool condition UpdatingConfig should be removed")
 		o.Eventually(hostedcluster.pollCheckHostedClustersNodePoolReady(npName), LongTimeout, LongTimeout/10).Should(o.BeTrue(), fmt.Sprintf("nodepool %s ready error", npName))
+		o.Expect(history.checkNodePoolUpdated(npName, "UpdatingConfig", DoubleLongTimeout, LongTimeout)).NotTo(o.HaveOccurred(), "nodepool config rolling upgrade error")
 
 		g.By("check ssh key in worker nodes")
 		o.Eventually(func() bool {
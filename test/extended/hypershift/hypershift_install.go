@@ -296,6 +296,9 @@ var _ = g.Describe("[sig-hypershift] Hypershift", func() {
 			e2e.Logf(fmt.Sprintf("statefulSetNames: %s: %s", name, value))
 			o.Expect(value).Should(o.ContainSubstring("topology.kubernetes.io/zone"), fmt.Sprintf("statefulset: %s lack of anti-affinity of zone", name))
 		}
+
+		exutil.By("Check the placement and the health of the control plane workloads")
+		o.Eventually(hostedCluster.checkControlPlaneReport, DefaultTimeout, DefaultTimeout/10).ShouldNot(o.HaveOccurred(), "control plane report error")
 	})
 
 	// author: liangli@redhat.com
@@ -1484,7 +1487,7 @@ var _ = g.Describe("[sig-hypershift] Hypershift", func() {
 					continue
 				}
 				if typedCondition.Status == metav1.ConditionTrue {
-					e2e.Logf("Found AWSDefaultSecurityGroupDeleted condition = %s", typedCondition)
+					e2e.Logf("Found AWSDefaultSecurityGroupDeleted condition = %v", typedCondition)
 					targetConditionExpected = true
 					break outerForLoop
 				}
@@ -1794,7 +1797,7 @@ var _ = g.Describe("[sig-hypershift] Hypershift", func() {
 						return false, nil
 					}
 					if len(node.Spec.Taints) > 0 {
-						e2e.Logf("Worker node tainted, keep polling", node.Name)
+						e2e.Logf("Worker node %s tainted, keep polling", node.Name)
 						return false, nil
 					}
 				}
@@ -2337,7 +2340,7 @@ spec:
 						return false, nil
 					}
 					if len(node.Spec.Taints) > 0 {
-						e2e.Logf("Worker node tainted, keep polling", node.Name)
+						e2e.Logf("Worker node %s tainted, keep polling", node.Name)
 						return false, nil
 					}
 					if _, ok := node.Labels[hypershiftClusterLabelKey]; ok {
@@ -28,21 +28,18 @@ var _ = g.Describe("[sig-openshift-logging] LOGGING Logging", func() {
 	g.BeforeEach(func() {
 		exutil.SkipBaselineCaps(oc, "None")
 		loggingBaseDir = exutil.FixturePath("testdata", "logging")
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 		CLO = SubscriptionObjects{
 			OperatorName:       "cluster-logging-operator",
 			Namespace:          cloNS,
 			PackageName:        "cluster-logging",
-			Subscription:       subTemplate,
-			OperatorGroup:      filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces:      true,
 			SkipCaseWhenFailed: true,
 		}
 		LO = SubscriptionObjects{
 			OperatorName:       "loki-operator-controller-manager",
 			Namespace:          loNS,
 			PackageName:        "loki-operator",
-			Subscription:       subTemplate,
-			OperatorGroup:      filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces:      true,
 			SkipCaseWhenFailed: true,
 		}
 
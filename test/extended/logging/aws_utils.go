@@ -14,6 +14,7 @@ import (
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	g "github.com/onsi/ginkgo/v2"
@@ -116,11 +117,17 @@ func newIamClient(cfg aws.Config) *iam.Client {
 // aws iam create-role
 func iamCreateRole(iamClient *iam.Client, trustPolicy string, roleName string) string {
 	e2e.Logf("Create iam role %v", roleName)
+	var tags []iamtypes.Tag
+	for key, value := range exutil.CloudResourceTags() {
+		tags = append(tags, iamtypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
 	result, err := iamClient.CreateRole(context.TODO(), &iam.CreateRoleInput{
 		AssumeRolePolicyDocument: aws.String(trustPolicy),
 		RoleName:                 aws.String(roleName),
+		Tags:                     tags,
 	})
 	o.Expect(err).NotTo(o.HaveOccurred(), "couldn't create role "+roleName)
+	exutil.RegisterCloudResource(exutil.CloudProviderAWS, exutil.CloudResourceIAMRole, roleName, "")
 	roleArn := aws.ToString(result.Role.Arn)
 	return roleArn
 }
@@ -132,18 +139,26 @@ func iamDeleteRole(iamClient *iam.Client, roleName string) {
 	})
 	if err != nil {
 		e2e.Logf("Couldn't delete role %s: %v", roleName, err)
+		return
 	}
+	exutil.ReleaseCloudResource(exutil.CloudProviderAWS, exutil.CloudResourceIAMRole, roleName)
 }
 
 // aws iam create-policy
 func iamCreatePolicy(iamClient *iam.Client, mgmtPolicy string, policyName string) string {
 	e2e.Logf("Create iam policy %v", policyName)
+	var tags []iamtypes.Tag
+	for key, value := range exutil.CloudResourceTags() {
+		tags = append(tags, iamtypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
 	result, err := iamClient.CreatePolicy(context.TODO(), &iam.CreatePolicyInput{
 		PolicyDocument: aws.String(mgmtPolicy),
 		PolicyName:     aws.String(policyName),
+		Tags:           tags,
 	})
 	o.Expect(err).NotTo(o.HaveOccurred(), "Couldn't create policy"+policyName)
 	policyArn := aws.ToString(result.Policy.Arn)
+	exutil.RegisterCloudResource(exutil.CloudProviderAWS, exutil.CloudResourceIAMPolicy, policyArn, "")
 	return policyArn
 }
 
@@ -154,7 +169,9 @@ func iamDeletePolicy(iamClient *iam.Client, policyArn string) {
 	})
 	if err != nil {
 		e2e.Logf("Couldn't delete policy %v: %v", policyArn, err)
+		return
 	}
+	exutil.ReleaseCloudResource(exutil.CloudProviderAWS, exutil.CloudResourceIAMPolicy, policyArn)
 }
 
 // This func creates a IAM role, attaches custom trust policy and managed permission policy
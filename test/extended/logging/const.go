@@ -8,16 +8,8 @@ const (
 	// the namespace where loki-operator is in
 	loNS = "openshift-operators-redhat"
 
-	apiPath         = "/api/logs/v1/"
-	queryPath       = "/loki/api/v1/query"
-	queryRangePath  = "/loki/api/v1/query_range"
-	labelsPath      = "/loki/api/v1/labels"
-	labelValuesPath = "/loki/api/v1/label/%s/values"
-	seriesPath      = "/loki/api/v1/series"
-	tailPath        = "/loki/api/v1/tail"
-	rulesPath       = "/loki/api/v1/rules"
-	minioNS         = "minio-aosqe"
-	minioSecret     = "minio-creds"
+	minioNS     = "minio-aosqe"
+	minioSecret = "minio-creds"
 
 	javaExc = `com.google.devtools.search.cloud.feeder.MakeLog: RuntimeException: Run from this message!
   at com.my.app.Object.do$a1(MakeLog.java:50)
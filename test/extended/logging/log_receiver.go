@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	g "github.com/onsi/ginkgo/v2"
+	o "github.com/onsi/gomega"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/logreceiver"
+)
+
+// logReceiver is the in-cluster stand-in of syslog, Splunk HEC, Elasticsearch, Loki, OTLP and HTTP outputs, it records
+// every event it receives so that the tests can check the payloads, the headers and the TLS of the collector
+type logReceiver struct {
+	name        string //the name of the receiver, it's also used to name the deployment/svc/secret
+	namespace   string //the namespace where the receiver deployed in
+	tls         bool   //serve https and syslog-tls besides the plaintext listeners
+	clientAuth  bool   //only can be set when tls is true
+	secretName  string //the name of the secret for the collector to use, only needed when tls is true
+	loggingNS   string //the namespace where the collector pods deployed in
+	splunkToken string //if it's not empty, the Splunk endpoints require it
+}
+
+// getLogReceiverImage returns the image of the receiver set by env var LOG_RECEIVER_IMAGE. The image built from
+// images/Dockerfile.log-receiver isn't published yet, the case is skipped if the env var is not set.
+func getLogReceiverImage() string {
+	image := os.Getenv("LOG_RECEIVER_IMAGE")
+	if image == "" {
+		g.Skip("Skip for env var LOG_RECEIVER_IMAGE is not set, the log receiver image must be pinned by digest")
+	}
+	return image
+}
+
+func (r logReceiver) createPipelineSecret(oc *exutil.CLI, keysPath string) {
+	secret := resource{"secret", r.secretName, r.loggingNS}
+	cmd := []string{"secret", "generic", secret.name, "-n", secret.namespace, "--from-file=ca-bundle.crt=" + keysPath + "/ca.crt"}
+	if r.clientAuth {
+		cmd = append(cmd, "--from-file=tls.key="+keysPath+"/client.key", "--from-file=tls.crt="+keysPath+"/client.crt")
+	}
+	err := oc.AsAdmin().WithoutNamespace().Run("create").Args(cmd...).Execute()
+	o.Expect(err).NotTo(o.HaveOccurred())
+	secret.WaitForResourceToAppear(oc)
+}
+
+func (r logReceiver) deploy(oc *exutil.CLI) {
+	params := []string{"-p", "NAME=" + r.name, "-p", "NAMESPACE=" + r.namespace, "-p", "IMAGE=" + getLogReceiverImage(), "-p", "CLIENT_AUTH=" + strconv.FormatBool(r.clientAuth), "-p", "SPLUNK_TOKEN=" + r.splunkToken}
+	if r.tls {
+		o.Expect(r.secretName).NotTo(o.BeEmpty())
+		baseDir := exutil.FixturePath("testdata", "logging")
+		keysPath := filepath.Join(baseDir, "temp"+getRandomString())
+		defer exec.Command("rm", "-r", keysPath).Output()
+		err := os.MkdirAll(keysPath, 0755)
+		o.Expect(err).NotTo(o.HaveOccurred())
+
+		cert := certsConf{r.name, r.namespace, ""}
+		cert.generateCerts(oc, keysPath)
+		r.createPipelineSecret(oc, keysPath)
+		err = oc.AsAdmin().WithoutNamespace().Run("create").Args("secret", "generic", r.name, "-n", r.namespace, "--from-file=tls.key="+keysPath+"/server.key", "--from-file=tls.crt="+keysPath+"/server.crt", "--from-file=ca.crt="+keysPath+"/ca.crt").Execute()
+		o.Expect(err).NotTo(o.HaveOccurred())
+		params = append(params, "-p", "TLS_DIR=/etc/log-receiver/tls", "-p", "HTTPS_ADDR=:8443", "-p", "SYSLOG_TLS_ADDR=:6514")
+	}
+
+	file := exutil.FixturePath("testdata", "logging", "external-log-stores", "log-receiver", "log-receiver.yaml")
+	deploy := resource{"deployment", r.name, r.namespace}
+	err := deploy.applyFromTemplate(oc, append([]string{"-f", file}, params...)...)
+	o.Expect(err).NotTo(o.HaveOccurred())
+	WaitForDeploymentPodsToBeReady(oc, r.namespace, r.name)
+}
+
+func (r logReceiver) remove(oc *exutil.CLI) {
+	if r.tls {
+		resource{"secret", r.name, r.namespace}.clear(oc)
+		resource{"secret", r.secretName, r.loggingNS}.clear(oc)
+	}
+	resource{"deployment", r.name, r.namespace}.clear(oc)
+	resource{"svc", r.name, r.namespace}.clear(oc)
+}
+
+func (r logReceiver) host() string {
+	return r.name + "." + r.namespace + ".svc"
+}
+
+// httpURL returns the URL of the HTTP based outputs, i.e. http, splunk, elasticsearch, loki and otlp, the path is appended
+// by the collector for every protocol except http
+func (r logReceiver) httpURL() string {
+	if r.tls {
+		return "https://" + r.host() + ":8443"
+	}
+	return "http://" + r.host() + ":8090"
+}
+
+// syslogURL returns the URL of the syslog output, protocol is tcp or udp, udp is never encrypted
+func (r logReceiver) syslogURL(protocol string) string {
+	if r.tls && protocol != "udp" {
+		return "tls://" + r.host() + ":6514"
+	}
+	return protocol + "://" + r.host() + ":514"
+}
+
+// client returns the client of the records of the receiver, the API is reached through the service proxy of the API server
+func (r logReceiver) client(oc *exutil.CLI) *logreceiver.Client {
+	return logreceiver.NewClient(logReceiverTransport{oc: oc, name: r.name, namespace: r.namespace})
+}
+
+// waitForRecords waits for the receiver to have at least count records selected by the query
+func (r logReceiver) waitForRecords(oc *exutil.CLI, q logreceiver.Query, count int) []logreceiver.Record {
+	records, err := r.client(oc).WaitForRecords(q, count, 3*time.Minute)
+	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("%s/%s didn't receive the records", r.namespace, r.name))
+	return records
+}
+
+type logReceiverTransport struct {
+	oc        *exutil.CLI
+	name      string
+	namespace string
+}
+
+func (t logReceiverTransport) proxyPath(path string) string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/services/http:%s:api/proxy%s", t.namespace, t.name, path)
+}
+
+func (t logReceiverTransport) Get(path string) ([]byte, error) {
+	output, err := t.oc.AsAdmin().WithoutNamespace().Run("get").Args("--raw", t.proxyPath(path)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, output)
+	}
+	return []byte(output), nil
+}
+
+func (t logReceiverTransport) Delete(path string) error {
+	output, err := t.oc.AsAdmin().WithoutNamespace().Run("delete").Args("--raw", t.proxyPath(path)).Output()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, output)
+	}
+	return nil
+}
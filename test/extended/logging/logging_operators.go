@@ -80,21 +80,18 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease vector-loki Up
 			SourceName:      "redhat-operators",
 			SourceNamespace: "openshift-marketplace",
 		}
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 		preCLO := SubscriptionObjects{
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: source,
 		}
 		preLO := SubscriptionObjects{
 			OperatorName:  "loki-operator-controller-manager",
 			Namespace:     loNS,
 			PackageName:   "loki-operator",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: source,
 		}
 		defer preCLO.uninstallOperator(oc)
@@ -234,21 +231,18 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease vector-loki Up
 		// for 6.2, test upgrade from 6.1 to 6.2
 		preSource := CatalogSourceObjects{"stable-6.1", catsrc.name, catsrc.namespace}
 		g.By(fmt.Sprintf("Subscribe operators to %s channel", preSource.Channel))
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 		preCLO := SubscriptionObjects{
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: preSource,
 		}
 		preLO := SubscriptionObjects{
 			OperatorName:  "loki-operator-controller-manager",
 			Namespace:     loNS,
 			PackageName:   "loki-operator",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: preSource,
 		}
 		defer preCLO.uninstallOperator(oc)
@@ -393,20 +387,11 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease vector-loki Up
 
 var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease operator deployments", func() {
 	defer g.GinkgoRecover()
-	var (
-		oc             = exutil.NewCLI("logging-operators", exutil.KubeConfigPath())
-		loggingBaseDir string
-	)
-
-	g.BeforeEach(func() {
-		loggingBaseDir = exutil.FixturePath("testdata", "logging")
-	})
+	var oc = exutil.NewCLI("logging-operators", exutil.KubeConfigPath())
 
 	g.It("Author:anli-CPaasrunOnly-Low-65518-deploy cluster-logging-operator after Datadog-Agent is deployed [Disruptive]", func() {
 		oc.SetupProject()
 		datadogNS := oc.Namespace()
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
-		ogPath := filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml")
 		podLabel := "app.kubernetes.io/name=datadog-operator"
 
 		g.By("Make the datadog operator ready")
@@ -419,9 +404,8 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease operator deplo
 			OperatorName:       "datadog-operator-certified",
 			PackageName:        "datadog-operator-certified",
 			Namespace:          datadogNS,
-			Subscription:       subTemplate,
 			OperatorPodLabel:   podLabel,
-			OperatorGroup:      ogPath,
+			AllNamespaces:      true,
 			CatalogSource:      sourceCert,
 			SkipCaseWhenFailed: true,
 		}
@@ -438,8 +422,7 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease operator deplo
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     "openshift-logging",
 			PackageName:   "cluster-logging",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: sourceQE,
 		}
 		subCLO.uninstallOperator(oc)
@@ -458,20 +441,17 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease multi-mode tes
 
 	g.BeforeEach(func() {
 		loggingBaseDir = exutil.FixturePath("testdata", "logging")
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 		CLO := SubscriptionObjects{
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		LO := SubscriptionObjects{
 			OperatorName:  "loki-operator-controller-manager",
 			Namespace:     loNS,
 			PackageName:   "loki-operator",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		g.By("deploy CLO and LO")
 		CLO.SubscribeOperator(oc)
@@ -858,8 +838,7 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease rapidast scan"
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml"),
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		CLO.SubscribeOperator(oc)
 		proj := oc.Namespace()
@@ -883,8 +862,7 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease rapidast scan"
 			OperatorName:  "loki-operator-controller-manager",
 			Namespace:     loNS,
 			PackageName:   "loki-operator",
-			Subscription:  filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml"),
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		LO.SubscribeOperator(oc)
 		proj := oc.Namespace()
@@ -913,27 +891,23 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease must-gather",
 			g.Skip("The cluster doesn't have a storage class for this test!")
 		}
 		loggingBaseDir = exutil.FixturePath("testdata", "logging")
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 		CLO := SubscriptionObjects{
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		LO := SubscriptionObjects{
 			OperatorName:  "loki-operator-controller-manager",
 			Namespace:     loNS,
 			PackageName:   "loki-operator",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		COO := SubscriptionObjects{
 			OperatorName:  "cluster-observability-operator",
 			Namespace:     "openshift-cluster-observability-operator",
 			PackageName:   "cluster-observability-operator",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: CatalogSourceObjects{
 				SourceName:      "redhat-operators",
 				SourceNamespace: "openshift-marketplace",
@@ -23,20 +23,17 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease", func() {
 	g.Context("LokiStack testing", func() {
 		g.BeforeEach(func() {
 			loggingBaseDir = exutil.FixturePath("testdata", "logging")
-			subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 			CLO := SubscriptionObjects{
 				OperatorName:  "cluster-logging-operator",
 				Namespace:     cloNS,
 				PackageName:   "cluster-logging",
-				Subscription:  subTemplate,
-				OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+				AllNamespaces: true,
 			}
 			LO := SubscriptionObjects{
 				OperatorName:  "loki-operator-controller-manager",
 				Namespace:     loNS,
 				PackageName:   "loki-operator",
-				Subscription:  subTemplate,
-				OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+				AllNamespaces: true,
 			}
 			g.By("deploy CLO and LO")
 			CLO.SubscribeOperator(oc)
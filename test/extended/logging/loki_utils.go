@@ -3,8 +3,8 @@ package logging
 import (
 	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -23,7 +23,9 @@ import (
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/loki"
 	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iam/v1"
 	"google.golang.org/api/iterator"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -280,11 +282,16 @@ func createServiceAccountOnGCP(projectID, name string) (*iam.ServiceAccount, err
 			DisplayName: "Service Account for " + name,
 		},
 	}
+	// service accounts have no labels, the run is kept in the description
+	if runID := exutil.CloudResourceRunID(); runID != "" {
+		request.ServiceAccount.Description = exutil.CloudResourceRunIDTag + "=" + runID
+	}
 	account, err := service.Projects.ServiceAccounts.Create("projects/"+projectID, request).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create serviceaccount: %w", err)
 	}
 	e2e.Logf("Created service account: %v", account)
+	exutil.RegisterCloudResource(exutil.CloudProviderGCP, exutil.CloudResourceServiceAccount, "projects/"+projectID+"/serviceAccounts/"+account.Email, "")
 	return account, nil
 }
 
@@ -448,9 +455,27 @@ func removeServiceAccountFromGCP(name string) error {
 	if err != nil {
 		return fmt.Errorf("can't remove service account: %v", err)
 	}
+	exutil.ReleaseCloudResource(exutil.CloudProviderGCP, exutil.CloudResourceServiceAccount, name)
 	return nil
 }
 
+func init() {
+	// the service accounts left by the tests are deleted at the end of the suite, with the credentials of
+	// GOOGLE_APPLICATION_CREDENTIALS in the environment of the suite runner as in the tests
+	exutil.RegisterCloudResourceCleaner(exutil.CloudProviderGCP, exutil.CloudResourceServiceAccount, func(ctx context.Context, r exutil.CloudResource) error {
+		service, err := iam.NewService(ctx)
+		if err != nil {
+			return fmt.Errorf("iam.NewService: %w", err)
+		}
+		_, err = service.Projects.ServiceAccounts.Delete(r.ID).Context(ctx).Do()
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
 func createSecretForGCSBucketWithSTS(oc *exutil.CLI, namespace, secretName, bucketName string) error {
 	return oc.NotShowInfo().AsAdmin().WithoutNamespace().Run("create").Args("secret", "generic", "-n", namespace, secretName, "--from-literal=bucketname="+bucketName).Execute()
 }
@@ -980,143 +1005,42 @@ func (l lokiStack) createSecretFromGateway(oc *exutil.CLI, name, namespace, toke
 
 }
 
-// TODO: add an option to provide TLS config
+// lokiClient queries Loki with the shared client, the responses are decoded into the logging types
 type lokiClient struct {
-	username        string //Username for HTTP basic auth.
-	password        string //Password for HTTP basic auth
-	address         string //Server address.
-	orgID           string //adds X-Scope-OrgID to API requests for representing tenant ID. Useful for requesting tenant data when bypassing an auth gateway.
-	bearerToken     string //adds the Authorization header to API requests for authentication purposes.
-	bearerTokenFile string //adds the Authorization header to API requests for authentication purposes.
-	retries         int    //How many times to retry each query when getting an error response from Loki.
-	queryTags       string //adds X-Query-Tags header to API requests.
-	quiet           bool   //Suppress query metadata.
+	*loki.Client
 }
 
 // newLokiClient initializes a lokiClient with server address
 func newLokiClient(routeAddress string) *lokiClient {
-	client := &lokiClient{}
-	client.address = routeAddress
-	client.retries = 5
-	client.quiet = true
-	return client
+	return &lokiClient{loki.NewClient(routeAddress)}
 }
 
 // retry sets how many times to retry each query
 func (c *lokiClient) retry(retry int) *lokiClient {
-	nc := *c
-	nc.retries = retry
-	return &nc
+	return &lokiClient{c.WithRetries(retry)}
 }
 
 // withToken sets the token used to do query
 func (c *lokiClient) withToken(bearerToken string) *lokiClient {
-	nc := *c
-	nc.bearerToken = bearerToken
-	return &nc
+	return &lokiClient{c.WithToken(bearerToken)}
 }
 
 func (c *lokiClient) withBasicAuth(username string, password string) *lokiClient {
-	nc := *c
-	nc.username = username
-	nc.password = password
-	return &nc
-}
-
-/*
-func (c *lokiClient) withTokenFile(bearerTokenFile string) *lokiClient {
-	nc := *c
-	nc.bearerTokenFile = bearerTokenFile
-	return &nc
-}
-*/
-
-func (c *lokiClient) getHTTPRequestHeader() (http.Header, error) {
-	h := make(http.Header)
-	if c.username != "" && c.password != "" {
-		h.Set(
-			"Authorization",
-			"Basic "+base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password)),
-		)
-	}
-	h.Set("User-Agent", "loki-logcli")
-
-	if c.orgID != "" {
-		h.Set("X-Scope-OrgID", c.orgID)
-	}
-
-	if c.queryTags != "" {
-		h.Set("X-Query-Tags", c.queryTags)
-	}
-
-	if (c.username != "" || c.password != "") && (len(c.bearerToken) > 0 || len(c.bearerTokenFile) > 0) {
-		return nil, fmt.Errorf("at most one of HTTP basic auth (username/password), bearer-token & bearer-token-file is allowed to be configured")
-	}
-
-	if len(c.bearerToken) > 0 && len(c.bearerTokenFile) > 0 {
-		return nil, fmt.Errorf("at most one of the options bearer-token & bearer-token-file is allowed to be configured")
-	}
-
-	if c.bearerToken != "" {
-		h.Set("Authorization", "Bearer "+c.bearerToken)
-	}
-
-	if c.bearerTokenFile != "" {
-		b, err := os.ReadFile(c.bearerTokenFile)
-		if err != nil {
-			return nil, fmt.Errorf("unable to read authorization credentials file %s: %s", c.bearerTokenFile, err)
-		}
-		bearerToken := strings.TrimSpace(string(b))
-		h.Set("Authorization", "Bearer "+bearerToken)
-	}
-	return h, nil
+	return &lokiClient{c.WithBasicAuth(username, password)}
 }
 
-func (c *lokiClient) doRequest(path, query string, out interface{}) error {
-	h, err := c.getHTTPRequestHeader()
-	if err != nil {
-		return err
-	}
-
-	resp, err := doHTTPRequest(h, c.address, path, query, "GET", c.quiet, c.retries, nil, 200)
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal(resp, out)
-}
-
-func (c *lokiClient) doQuery(path string, query string) (*lokiQueryResponse, error) {
-	var err error
+func (c *lokiClient) doQuery(tenant, path string, params url.Values) (*lokiQueryResponse, error) {
 	var r lokiQueryResponse
-
-	if err = c.doRequest(path, query, &r); err != nil {
+	if err := c.Get(tenant, path, params, &r); err != nil {
 		return nil, err
 	}
-
 	return &r, nil
 }
 
 // query uses the /api/v1/query endpoint to execute an instant query
 // lc.query("application", "sum by(kubernetes_namespace_name)(count_over_time({kubernetes_namespace_name=\"multiple-containers\"}[5m]))", 30, false, time.Now())
 func (c *lokiClient) query(tenant string, queryStr string, limit int, forward bool, time time.Time) (*lokiQueryResponse, error) {
-	direction := func() string {
-		if forward {
-			return "FORWARD"
-		}
-		return "BACKWARD"
-	}
-	qsb := newQueryStringBuilder()
-	qsb.setString("query", queryStr)
-	qsb.setInt("limit", int64(limit))
-	qsb.setInt("time", time.UnixNano())
-	qsb.setString("direction", direction())
-	var logPath string
-	if len(tenant) > 0 {
-		logPath = apiPath + tenant + queryRangePath
-	} else {
-		logPath = queryRangePath
-	}
-	return c.doQuery(logPath, qsb.encode())
+	return c.doQuery(tenant, loki.QueryRangePath, loki.QueryParams(queryStr, limit, forward, time))
 }
 
 // queryRange uses the /api/v1/query_range endpoint to execute a range query
@@ -1127,26 +1051,7 @@ func (c *lokiClient) query(tenant string, queryStr string, limit int, forward bo
 // end: Stop looking for logs at this absolute time (exclusive)
 // forward: true means scan forwards through logs, false means scan backwards through logs
 func (c *lokiClient) queryRange(tenant string, queryStr string, limit int, start, end time.Time, forward bool) (*lokiQueryResponse, error) {
-	direction := func() string {
-		if forward {
-			return "FORWARD"
-		}
-		return "BACKWARD"
-	}
-	params := newQueryStringBuilder()
-	params.setString("query", queryStr)
-	params.setInt32("limit", limit)
-	params.setInt("start", start.UnixNano())
-	params.setInt("end", end.UnixNano())
-	params.setString("direction", direction())
-	var logPath string
-	if len(tenant) > 0 {
-		logPath = apiPath + tenant + queryRangePath
-	} else {
-		logPath = queryRangePath
-	}
-
-	return c.doQuery(logPath, params.encode())
+	return c.doQuery(tenant, loki.QueryRangePath, loki.QueryRangeParams(queryStr, limit, start, end, forward))
 }
 
 func (c *lokiClient) searchLogsInLoki(tenant, query string) (*lokiQueryResponse, error) {
@@ -1175,18 +1080,7 @@ func (c *lokiClient) searchByKey(tenant, key, value string) (*lokiQueryResponse,
 }
 
 func (c *lokiClient) waitForLogsAppearByKey(tenant, key, value string) {
-	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Second, 300*time.Second, true, func(context.Context) (done bool, err error) {
-		logs, err := c.searchByKey(tenant, key, value)
-		if err != nil {
-			e2e.Logf("\ngot err when searching logs: %v, retrying...\n", err)
-			return false, nil
-		}
-		if len(logs.Data.Result) > 0 {
-			e2e.Logf(`find logs by {%s="%s"}`, key, value)
-			return true, nil
-		}
-		return false, nil
-	})
+	err := c.waitForLogsAppearByQuery(tenant, "{"+key+"=\""+value+"\"}")
 	exutil.AssertWaitPollNoErr(err, fmt.Sprintf(`can't find logs by {%s="%s"} in last 5 minutes`, key, value))
 }
 
@@ -1196,18 +1090,7 @@ func (c *lokiClient) searchByNamespace(tenant, projectName string) (*lokiQueryRe
 }
 
 func (c *lokiClient) waitForLogsAppearByProject(tenant, projectName string) {
-	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Second, 300*time.Second, true, func(context.Context) (done bool, err error) {
-		logs, err := c.searchByNamespace(tenant, projectName)
-		if err != nil {
-			e2e.Logf("\ngot err when searching logs: %v, retrying...\n", err)
-			return false, nil
-		}
-		if len(logs.Data.Result) > 0 {
-			e2e.Logf("find logs from %s project", projectName)
-			return true, nil
-		}
-		return false, nil
-	})
+	err := c.waitForLogsAppearByQuery(tenant, "{kubernetes_namespace_name=\""+projectName+"\"}")
 	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("can't find logs from %s project in last 5 minutes", projectName))
 }
 
@@ -1225,130 +1108,18 @@ func extractLogEntities(lokiQueryResult *lokiQueryResponse) []LogEntity {
 	return lokiLogs
 }
 
-// listLabelValues uses the /api/v1/label endpoint to list label values
-func (c *lokiClient) listLabelValues(tenant, name string, start, end time.Time) (*labelResponse, error) {
-	lpath := fmt.Sprintf(labelValuesPath, url.PathEscape(name))
-	var labelResponse labelResponse
-	params := newQueryStringBuilder()
-	params.setInt("start", start.UnixNano())
-	params.setInt("end", end.UnixNano())
-
-	path := ""
-	if len(tenant) > 0 {
-		path = apiPath + tenant + lpath
-	} else {
-		path = lpath
-	}
-
-	if err := c.doRequest(path, params.encode(), &labelResponse); err != nil {
-		return nil, err
-	}
-	return &labelResponse, nil
-}
-
-// listLabelNames uses the /api/v1/label endpoint to list label names
-func (c *lokiClient) listLabelNames(tenant string, start, end time.Time) (*labelResponse, error) {
-	var labelResponse labelResponse
-	params := newQueryStringBuilder()
-	params.setInt("start", start.UnixNano())
-	params.setInt("end", end.UnixNano())
-	path := ""
-	if len(tenant) > 0 {
-		path = apiPath + tenant + labelsPath
-	} else {
-		path = labelsPath
-	}
-
-	if err := c.doRequest(path, params.encode(), &labelResponse); err != nil {
-		return nil, err
-	}
-	return &labelResponse, nil
-}
-
 // listLabels gets the label names or values
 func (c *lokiClient) listLabels(tenant, labelName string) ([]string, error) {
-	var labelResponse *labelResponse
-	var err error
 	start := time.Now().Add(time.Duration(-2) * time.Hour)
 	end := time.Now()
 	if len(labelName) > 0 {
-		labelResponse, err = c.listLabelValues(tenant, labelName, start, end)
-	} else {
-		labelResponse, err = c.listLabelNames(tenant, start, end)
+		return c.LabelValues(tenant, labelName, start, end)
 	}
-	return labelResponse.Data, err
+	return c.Labels(tenant, start, end)
 }
 
 func (c *lokiClient) queryRules(tenant, ns string) ([]byte, error) {
-	path := apiPath + tenant + rulesPath
-
-	params := url.Values{}
-	if ns != "" {
-		params.Add("kubernetes_namespace_name", ns)
-	}
-
-	h, err := c.getHTTPRequestHeader()
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := doHTTPRequest(h, c.address, path, params.Encode(), "GET", c.quiet, c.retries, nil, 200)
-	if err != nil {
-		/*
-			Ignore error "unexpected EOF", adding `h.Add("Accept-Encoding", "identity")` doesn't resolve the error.
-			This seems to be an issue in lokistack when tenant=application, recording rules are not in the response.
-			No error when tenant=infrastructure
-		*/
-		if strings.Contains(err.Error(), "unexpected EOF") && len(resp) > 0 {
-			e2e.Logf("got error %s when reading the response, but ignore it", err.Error())
-			return resp, nil
-		}
-		return nil, err
-	}
-	return resp, nil
-
-}
-
-type queryStringBuilder struct {
-	values url.Values
-}
-
-func newQueryStringBuilder() *queryStringBuilder {
-	return &queryStringBuilder{
-		values: url.Values{},
-	}
-}
-
-func (b *queryStringBuilder) setString(name, value string) {
-	b.values.Set(name, value)
-}
-
-func (b *queryStringBuilder) setInt(name string, value int64) {
-	b.setString(name, strconv.FormatInt(value, 10))
-}
-
-func (b *queryStringBuilder) setInt32(name string, value int) {
-	b.setString(name, strconv.Itoa(value))
-}
-
-/*
-func (b *queryStringBuilder) setStringArray(name string, values []string) {
-	for _, v := range values {
-		b.values.Add(name, v)
-	}
-}
-func (b *queryStringBuilder) setFloat32(name string, value float32) {
-	b.setString(name, strconv.FormatFloat(float64(value), 'f', -1, 32))
-}
-func (b *queryStringBuilder) setFloat(name string, value float64) {
-	b.setString(name, strconv.FormatFloat(value, 'f', -1, 64))
-}
-*/
-
-// encode returns the URL-encoded query string based on key-value
-// parameters added to the builder calling Set functions.
-func (b *queryStringBuilder) encode() string {
-	return b.values.Encode()
+	return c.Rules(tenant, ns)
 }
 
 // compareClusterResources compares the remaning resource with the requested resource provide by user
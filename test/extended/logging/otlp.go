@@ -25,8 +25,7 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease Otlp output te
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml"),
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		exutil.By("deploy CLO")
 		CLO.SubscribeOperator(oc)
@@ -60,8 +59,7 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease Otlp output te
 				OperatorName:  "opentelemetry-operator",
 				Namespace:     "openshift-opentelemetry-operator",
 				PackageName:   "opentelemetry-product",
-				Subscription:  filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml"),
-				OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+				AllNamespaces: true,
 				CatalogSource: CatalogSourceObjects{
 					Channel: "stable",
 				},
@@ -141,8 +139,7 @@ retry_max_duration_secs = 20`,
 			OperatorName:  "loki-operator-controller-manager",
 			Namespace:     loNS,
 			PackageName:   "loki-operator",
-			Subscription:  filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml"),
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		exutil.By("deploy Loki Operator")
 		LO.SubscribeOperator(oc)
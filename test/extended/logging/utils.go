@@ -12,7 +12,6 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -25,6 +24,8 @@ import (
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/loki"
+	"github.com/openshift/openshift-tests-private/test/extended/util/olm"
 	"google.golang.org/api/iterator"
 	"google.golang.org/protobuf/types/known/structpb"
 
@@ -39,12 +40,14 @@ import (
 type SubscriptionObjects struct {
 	OperatorName       string
 	Namespace          string
-	OperatorGroup      string // the file used to create operator group
-	Subscription       string // the file used to create subscription
+	AllNamespaces      bool // the operator group created for the operator targets all the namespaces
 	PackageName        string
 	OperatorPodLabel   string               //The operator pod label which is used to select pod
 	CatalogSource      CatalogSourceObjects `json:",omitempty"`
 	SkipCaseWhenFailed bool                 // if true, the case will be skipped when operator is not ready, otherwise, the case will be marked as failed
+
+	// olmOperator is built once so the objects created by the install are removed by the uninstall
+	olmOperator *olm.Operator
 }
 
 // CatalogSourceObjects defines the source used to subscribe an operator
@@ -128,247 +131,67 @@ func isFipsEnabled(oc *exutil.CLI) bool {
 	return strings.Contains(fips, "FIPS mode is enabled.")
 }
 
-// waitForPackagemanifestAppear waits for the packagemanifest to appear in the cluster
-// chSource: bool value, true means the packagemanifests' source name must match the so.CatalogSource.SourceName, e.g.: oc get packagemanifests xxxx -l catalog=$source-name
-func (so *SubscriptionObjects) waitForPackagemanifestAppear(oc *exutil.CLI, chSource bool) {
-	args := []string{"-n", so.CatalogSource.SourceNamespace, "packagemanifests"}
-	if chSource {
-		args = append(args, "-l", "catalog="+so.CatalogSource.SourceName)
-	} else {
-		args = append(args, so.PackageName)
+// operator returns the OLM operator of the subscription objects, the channel defaults to stable-6.2
+func (so *SubscriptionObjects) operator(oc *exutil.CLI) *olm.Operator {
+	if so.olmOperator != nil {
+		return so.olmOperator
 	}
-	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Second, 180*time.Second, true, func(context.Context) (done bool, err error) {
-		packages, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(args...).Output()
-		if err != nil {
-			msg := fmt.Sprintf("%v", err)
-			if strings.Contains(msg, "No resources found") || strings.Contains(msg, "NotFound") {
-				return false, nil
-			}
-			return false, err
-		}
-		if strings.Contains(packages, so.PackageName) {
-			return true, nil
-		}
-		e2e.Logf("Waiting for packagemanifest/%s to appear", so.PackageName)
-		return false, nil
-	})
-	if err != nil {
-		if so.SkipCaseWhenFailed {
-			g.Skip(fmt.Sprintf("Skip the case for can't find packagemanifest/%s", so.PackageName))
-		} else {
-			e2e.Failf("Packagemanifest %s is not available", so.PackageName)
-		}
-	}
-	//check channel
-	args = append(args, `-ojsonpath={.items[?(@.metadata.name=="`+so.PackageName+`")].status.channels[*].name}`)
-	output, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args(args...).Output()
-	channels := strings.Split(output, " ")
-	if !contain(channels, so.CatalogSource.Channel) {
-		e2e.Logf("Find channels %v from packagemanifest/%s", channels, so.PackageName)
-		if so.SkipCaseWhenFailed {
-			g.Skip(fmt.Sprintf("Skip the case for packagemanifest/%s doesn't have target channel %s", so.PackageName, so.CatalogSource.Channel))
-		} else {
-			e2e.Failf("Packagemanifest %s doesn't have target channel %s", so.PackageName, so.CatalogSource.Channel)
-		}
+	if so.OperatorPodLabel == "" {
+		so.OperatorPodLabel = "name=" + so.OperatorName
 	}
-}
-
-// setCatalogSourceObjects set the default values of channel, source namespace and source name if they're not specified
-func (so *SubscriptionObjects) setCatalogSourceObjects(oc *exutil.CLI) {
-	// set channel
 	if so.CatalogSource.Channel == "" {
 		so.CatalogSource.Channel = "stable-6.2"
 	}
-
-	// set source namespace
 	if so.CatalogSource.SourceNamespace == "" {
-		so.CatalogSource.SourceNamespace = "openshift-marketplace"
+		so.CatalogSource.SourceNamespace = olm.MarketplaceNamespace
+	}
+	if so.CatalogSource.SourceName == "" {
+		so.setCatalogSourceName(oc)
+	}
+	so.olmOperator = &olm.Operator{
+		Name:                    so.OperatorName,
+		Package:                 so.PackageName,
+		Namespace:               so.Namespace,
+		AllNamespaces:           so.AllNamespaces,
+		NamespaceLabels:         map[string]string{"openshift.io/cluster-monitoring": "true"},
+		NamespaceAnnotations:    map[string]string{"openshift.io/node-selector": ""},
+		Channel:                 so.CatalogSource.Channel,
+		CatalogSource:           so.CatalogSource.SourceName,
+		CatalogSourceNamespace:  so.CatalogSource.SourceNamespace,
+		PreferredCatalogSources: []string{"qe-app-registry"},
+		PodLabel:                so.OperatorPodLabel,
+		SkipWhenUnavailable:     so.SkipCaseWhenFailed,
+		// several operators are deployed in the logging namespaces, and the custom resources are cleared by the cases
+		KeepCRDs: true,
+	}
+	return so.olmOperator
+}
+
+// setCatalogSourceName uses redhat-operators in the auto release jobs as we want to test GAed logging, otherwise the
+// source name is left empty for the olm library to prefer qe-app-registry
+func (so *SubscriptionObjects) setCatalogSourceName(oc *exutil.CLI) {
+	output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("catsrc", "-n", so.CatalogSource.SourceNamespace, "-ojsonpath={.items[*].metadata.name}").Output()
+	if err != nil {
+		e2e.Logf("can't list catalog source in project %s: %v", so.CatalogSource.SourceNamespace, err)
 	}
-
-	// set source and check if the packagemanifest exists or not
-	if so.CatalogSource.SourceName != "" {
-		so.waitForPackagemanifestAppear(oc, true)
-	} else {
-		output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("catsrc", "-n", so.CatalogSource.SourceNamespace, "-ojsonpath={.items[*].metadata.name}").Output()
-		if err != nil {
-			e2e.Logf("can't list catalog source in project %s: %v", so.CatalogSource.SourceNamespace, err)
-		}
-		catsrcs := strings.Split(output, " ")
-		if contain(catsrcs, "auto-release-app-registry") {
-			if contain(catsrcs, "redhat-operators") {
-				// do not subscribe source auto-release-app-registry as we want to test GAed logging in auto release jobs
-				so.CatalogSource.SourceName = "redhat-operators"
-				so.waitForPackagemanifestAppear(oc, true)
-			} else {
-				if so.SkipCaseWhenFailed {
-					g.Skip("skip the case because the cluster doesn't have proper catalog source for logging")
-				}
-			}
-		} else if contain(catsrcs, "qe-app-registry") {
-			so.CatalogSource.SourceName = "qe-app-registry"
-			so.waitForPackagemanifestAppear(oc, true)
-		} else {
-			so.waitForPackagemanifestAppear(oc, false)
-			source, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("packagemanifests", so.PackageName, "-o", "jsonpath={.status.catalogSource}").Output()
-			if err != nil {
-				e2e.Logf("error getting catalog source name: %v", err)
-			}
-			so.CatalogSource.SourceName = source
-		}
+	catsrcs := strings.Split(output, " ")
+	if !contain(catsrcs, "auto-release-app-registry") {
+		return
+	}
+	if contain(catsrcs, "redhat-operators") {
+		so.CatalogSource.SourceName = "redhat-operators"
+	} else if so.SkipCaseWhenFailed {
+		g.Skip("skip the case because the cluster doesn't have proper catalog source for logging")
 	}
 }
 
 // SubscribeOperator is used to deploy operators
 func (so *SubscriptionObjects) SubscribeOperator(oc *exutil.CLI) {
-	// check if the namespace exists, if it doesn't exist, create the namespace
-	if so.OperatorPodLabel == "" {
-		so.OperatorPodLabel = "name=" + so.OperatorName
-	}
-	_, err := oc.AdminKubeClient().CoreV1().Namespaces().Get(context.Background(), so.Namespace, metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			e2e.Logf("The project %s is not found, create it now...", so.Namespace)
-			namespaceTemplate := exutil.FixturePath("testdata", "logging", "subscription", "namespace.yaml")
-			namespaceFile, err := processTemplate(oc, "-f", namespaceTemplate, "-p", "NAMESPACE_NAME="+so.Namespace)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			defer os.Remove(namespaceFile)
-			err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 60*time.Second, true, func(context.Context) (done bool, err error) {
-				output, err := oc.AsAdmin().Run("apply").Args("-f", namespaceFile).Output()
-				if err != nil {
-					if strings.Contains(output, "AlreadyExists") {
-						return true, nil
-					}
-					return false, err
-				}
-				return true, nil
-			})
-			exutil.AssertWaitPollNoErr(err, fmt.Sprintf("can't create project %s", so.Namespace))
-		}
-	}
-
-	// check the operator group, if no object found, then create an operator group in the project
-	og, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("-n", so.Namespace, "og").Output()
-	o.Expect(err).NotTo(o.HaveOccurred())
-	msg := fmt.Sprintf("%v", og)
-	if strings.Contains(msg, "No resources found") {
-		// create operator group
-		ogFile, err := processTemplate(oc, "-n", so.Namespace, "-f", so.OperatorGroup, "-p", "OG_NAME="+so.Namespace, "NAMESPACE="+so.Namespace)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer os.Remove(ogFile)
-		err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 60*time.Second, true, func(context.Context) (done bool, err error) {
-			output, err := oc.AsAdmin().Run("apply").Args("-f", ogFile, "-n", so.Namespace).Output()
-			if err != nil {
-				if strings.Contains(output, "AlreadyExists") {
-					return true, nil
-				}
-				return false, err
-			}
-			return true, nil
-		})
-		exutil.AssertWaitPollNoErr(err, fmt.Sprintf("can't create operatorgroup %s in %s project", so.Namespace, so.Namespace))
-	}
-
-	// check subscription, if there is no subscription objets, then create one
-	sub, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("sub", "-n", so.Namespace, so.PackageName).Output()
-	if err != nil {
-		msg := fmt.Sprint("v%", sub)
-		if strings.Contains(msg, "NotFound") {
-			so.setCatalogSourceObjects(oc)
-			//create subscription object
-			subscriptionFile, err := processTemplate(oc, "-n", so.Namespace, "-f", so.Subscription, "-p", "PACKAGE_NAME="+so.PackageName, "NAMESPACE="+so.Namespace, "CHANNEL="+so.CatalogSource.Channel, "SOURCE="+so.CatalogSource.SourceName, "SOURCE_NAMESPACE="+so.CatalogSource.SourceNamespace)
-			if err != nil {
-				if so.SkipCaseWhenFailed {
-					g.Skip("hit error when processing subscription template: " + err.Error() + ", skip the case")
-				} else {
-					e2e.Failf("hit error when processing subscription template: %v", err)
-				}
-			}
-			defer os.Remove(subscriptionFile)
-			err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 60*time.Second, true, func(context.Context) (done bool, err error) {
-				output, err := oc.AsAdmin().Run("apply").Args("-f", subscriptionFile, "-n", so.Namespace).Output()
-				if err != nil {
-					if strings.Contains(output, "AlreadyExists") {
-						return true, nil
-					}
-					return false, err
-				}
-				return true, nil
-			})
-			if err != nil {
-				if so.SkipCaseWhenFailed {
-					g.Skip("hit error when creating subscription, skip the case")
-				} else {
-					e2e.Failf("hit error when creating subscription")
-				}
-			}
-			exutil.AssertWaitPollNoErr(err, fmt.Sprintf("can't create subscription %s in %s project", so.PackageName, so.Namespace))
-			// check status in subscription
-			err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 120*time.Second, true, func(context.Context) (done bool, err error) {
-				output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("-n", so.Namespace, "sub", so.PackageName, `-ojsonpath={.status.state}`).Output()
-				if err != nil {
-					e2e.Logf("error getting subscription/%s: %v", so.PackageName, err)
-					return false, nil
-				}
-				return strings.Contains(output, "AtLatestKnown"), nil
-			})
-			if err != nil {
-				out, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("-n", so.Namespace, "sub", so.PackageName, `-ojsonpath={.status.conditions}`).Output()
-				e2e.Logf("subscription/%s is not ready, conditions: %v", so.PackageName, out)
-				if so.SkipCaseWhenFailed {
-					g.Skip(fmt.Sprintf("Skip the case for the operator %s is not ready", so.OperatorName))
-				} else {
-					e2e.Failf("can't deploy operator %s", so.OperatorName)
-				}
-			}
-		}
-	}
-
-	// check pod status
-	err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 240*time.Second, true, func(context.Context) (done bool, err error) {
-		pods, err := oc.AdminKubeClient().CoreV1().Pods(so.Namespace).List(context.Background(), metav1.ListOptions{LabelSelector: so.OperatorPodLabel})
-		if err != nil {
-			e2e.Logf("Hit error %v when getting pods", err)
-			return false, nil
-		}
-		if len(pods.Items) == 0 {
-			e2e.Logf("Waiting for pod with label %s to appear\n", so.OperatorPodLabel)
-			return false, nil
-		}
-		ready := true
-		for _, pod := range pods.Items {
-			if pod.Status.Phase != "Running" {
-				ready = false
-				e2e.Logf("Pod %s is not running: %v", pod.Name, pod.Status.Phase)
-				break
-			}
-			for _, containerStatus := range pod.Status.ContainerStatuses {
-				if !containerStatus.Ready {
-					ready = false
-					e2e.Logf("Container %s in pod %s is not ready", containerStatus.Name, pod.Name)
-					break
-				}
-			}
-		}
-		return ready, nil
-	})
-	if err != nil {
-		_ = oc.AsAdmin().WithoutNamespace().Run("get").Args("pod", "-n", so.Namespace, "-l", so.OperatorPodLabel).Execute()
-		podStatus, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("pod", "-n", so.Namespace, "-l", so.OperatorPodLabel, "-ojsonpath={.items[*].status.conditions}").Output()
-		containerStatus, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("pod", "-n", so.Namespace, "-l", so.OperatorPodLabel, "-ojsonpath={.items[*].status.containerStatuses}").Output()
-		e2e.Logf("pod with label %s is not ready:\nconditions: %s\ncontainer status: %s", so.OperatorPodLabel, podStatus, containerStatus)
-		if so.SkipCaseWhenFailed {
-			g.Skip(fmt.Sprintf("Skip the case for the operator %s is not ready", so.OperatorName))
-		} else {
-			e2e.Failf("can't deploy operator %s", so.OperatorName)
-		}
-	}
+	so.operator(oc).Install(oc)
 }
 
 func (so *SubscriptionObjects) uninstallOperator(oc *exutil.CLI) {
-	//csv, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("-n", so.Namespace, "sub/"+so.PackageName, "-ojsonpath={.status.installedCSV}").Output()
-	resource{"subscription", so.PackageName, so.Namespace}.clear(oc)
-	//_ = oc.AsAdmin().WithoutNamespace().Run("delete").Args("-n", so.Namespace, "csv", csv).Execute()
-	_ = oc.AsAdmin().WithoutNamespace().Run("delete").Args("-n", so.Namespace, "csv", "-l", "operators.coreos.com/"+so.PackageName+"."+so.Namespace+"=").Execute()
+	so.operator(oc).Uninstall(oc)
 	// do not remove namespace openshift-logging and openshift-operators-redhat, and preserve the operatorgroup as there may have several operators deployed in one namespace
 	// for example: loki-operator
 	if so.Namespace != "openshift-logging" && so.Namespace != "openshift-operators-redhat" && !strings.HasPrefix(so.Namespace, "e2e-test-") {
@@ -377,7 +200,7 @@ func (so *SubscriptionObjects) uninstallOperator(oc *exutil.CLI) {
 }
 
 func (so *SubscriptionObjects) getInstalledCSV(oc *exutil.CLI) string {
-	installedCSV, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("-n", so.Namespace, "sub", so.PackageName, "-ojsonpath={.status.installedCSV}").Output()
+	installedCSV, err := so.operator(oc).InstalledCSV(oc)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	return installedCSV
 }
@@ -1643,8 +1466,6 @@ func (amqi *amqInstance) deploy(oc *exutil.CLI) {
 			OperatorName:  "amq-streams-cluster-operator",
 			Namespace:     amqi.namespace,
 			PackageName:   "amq-streams",
-			Subscription:  filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml"),
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "singlenamespace-og.yaml"),
 			CatalogSource: catsrc,
 		}
 		amqs.SubscribeOperator(oc)
@@ -2279,10 +2100,11 @@ func waitForOperatorsRunning(oc *exutil.CLI) {
 }
 
 func doHTTPRequest(header http.Header, address, path, query, method string, quiet bool, attempts int, requestBody io.Reader, expectedStatusCode int) ([]byte, error) {
-	us, err := buildURL(address, path, query)
+	u, err := loki.BuildURL(address, path, query)
 	if err != nil {
 		return nil, err
 	}
+	us := u.String()
 	if !quiet {
 		e2e.Logf("the URL is: %s", us)
 	}
@@ -2347,17 +2169,6 @@ func doHTTPRequest(header http.Header, address, path, query, method string, quie
 	return io.ReadAll(resp.Body)
 }
 
-// buildURL concats a url `http://foo/bar` with a path `/buzz`.
-func buildURL(u, p, q string) (string, error) {
-	url, err := url.Parse(u)
-	if err != nil {
-		return "", err
-	}
-	url.Path = path.Join(url.Path, p)
-	url.RawQuery = q
-	return url.String(), nil
-}
-
 // GetIPVersionStackType gets IP-version Stack type of the cluster
 func GetIPVersionStackType(oc *exutil.CLI) (ipvStackType string) {
 	svcNetwork, err := oc.WithoutNamespace().AsAdmin().Run("get").Args("network.operator", "cluster", "-o=jsonpath={.spec.serviceNetwork}").Output()
@@ -20,13 +20,11 @@ var _ = g.Describe("[sig-openshift-logging] LOGGING Logging", func() {
 
 	g.BeforeEach(func() {
 		loggingBaseDir = exutil.FixturePath("testdata", "logging")
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 		CLO = SubscriptionObjects{
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		g.By("deploy CLO")
 		CLO.SubscribeOperator(oc)
@@ -35,8 +35,7 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease", func() {
 				OperatorName:  "cluster-logging-operator",
 				Namespace:     cloNS,
 				PackageName:   "cluster-logging",
-				Subscription:  filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml"),
-				OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+				AllNamespaces: true,
 			}
 			CLO.SubscribeOperator(oc)
 			oc.SetupProject()
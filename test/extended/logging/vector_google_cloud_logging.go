@@ -28,13 +28,11 @@ var _ = g.Describe("[sig-openshift-logging] Logging NonPreRelease Forward to GCL
 		loggingBaseDir = exutil.FixturePath("testdata", "logging")
 
 		g.By("deploy CLO")
-		subTemplate := filepath.Join(loggingBaseDir, "subscription", "sub-template.yaml")
 		CLO := SubscriptionObjects{
 			OperatorName:  "cluster-logging-operator",
 			Namespace:     cloNS,
 			PackageName:   "cluster-logging",
-			Subscription:  subTemplate,
-			OperatorGroup: filepath.Join(loggingBaseDir, "subscription", "allnamespace-og.yaml"),
+			AllNamespaces: true,
 		}
 		CLO.SubscribeOperator(oc)
 		oc.SetupProject()
@@ -1,6 +1,7 @@
 package mco
 
 import (
+	"context"
 	"encoding/base32"
 	"fmt"
 	"hash/fnv"
@@ -15,6 +16,7 @@ import (
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 	container "github.com/openshift/openshift-tests-private/test/extended/util/container"
+	"github.com/openshift/openshift-tests-private/test/extended/util/imageinspect"
 	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
@@ -257,19 +259,14 @@ func (b *OsImageBuilder) digestImage() (string, error) {
 	if b.osImage == "" {
 		return "", fmt.Errorf("There is no image to be digested. Wast the osImage built?")
 	}
-	skopeoCLI := NewSkopeoCLI().SetAuthFile(b.dockerConfig)
-	inspectInfo, err := skopeoCLI.Run("inspect").Args("--tls-verify=false", "docker://"+b.osImage).Output()
+	inspector := imageinspect.NewInspector(imageinspect.NewClient().SetAuthFile(b.dockerConfig).SetInsecure(true))
+	digestedImage, err := inspector.GetDigestedImage(context.Background(), b.osImage)
 	if err != nil {
-		msg := fmt.Sprintf("Skopeo failed inspecting image %s:\n%s\n%s", b.osImage, inspectInfo, err)
+		msg := fmt.Sprintf("Failed inspecting image %s:\n%s", b.osImage, err)
 		logger.Errorf(msg)
 		return "", fmt.Errorf(msg)
 	}
 
-	logger.Debugf(inspectInfo)
-
-	inspectJSON := JSON(inspectInfo)
-	digestedImage := inspectJSON.Get("Name").ToString() + "@" + inspectJSON.Get("Digest").ToString()
-
 	logger.Infof("Image %s was digested as %s", b.osImage, digestedImage)
 
 	return digestedImage, nil
@@ -308,16 +305,22 @@ func prepareDockerfileDirectory(baseDir, dockerFileContent string) (string, erro
 	return directory, nil
 }
 
+// getImageFromReleaseInfo returns the image of a component in the cluster's release, the same as "oc adm release info --image-for"
 func getImageFromReleaseInfo(oc *exutil.CLI, imageName, dockerConfigFile string) (string, error) {
-	stdout, stderr, err := oc.Run("adm").Args("release", "info", "--insecure", "--image-for", imageName,
-		"--registry-config", dockerConfigFile).Outputs()
+	releaseImage, err := GetClusterDesiredReleaseImage(oc)
 	if err != nil {
-		logger.Errorf("STDOUT: %s", stdout)
-		logger.Errorf("STDERR: %s", stderr)
-		return stdout + stderr, err
+		return "", err
+	}
+
+	inspector := imageinspect.NewInspector(imageinspect.NewClient().SetAuthFile(dockerConfigFile).SetInsecure(true)).
+		SetArchitecture(architecture.GetControlPlaneArch(oc).String())
+	image, err := inspector.GetReleaseImageFor(context.Background(), releaseImage, imageName)
+	if err != nil {
+		logger.Errorf("Error getting the image for %s from release %s: %s", imageName, releaseImage, err)
+		return "", err
 	}
 
-	return stdout, nil
+	return image, nil
 }
 
 func getLayeringTestImageRepository(defaultTag string) string {
@@ -3,12 +3,13 @@ package mco
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
-	"time"
 
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/imageinspect"
 	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
 	"github.com/tidwall/sjson"
 )
@@ -414,7 +415,6 @@ func cleanupMOSCSecret(secret Secret) error {
 func CreateInternalRegistrySecretFromSA(oc *exutil.CLI, saName, saNamespace, secretName, secretNamespace string) (*Secret, error) {
 	var (
 		tmpDockerConfigFile = generateTmpFile(oc, "tmp-config.json")
-		masterNode          = NewMachineConfigPool(oc.AsAdmin(), MachineConfigPoolMaster).GetNodesOrFail()[0]
 	)
 	logger.Infof("Create a new secret with the credentials to login to the internal registry using a SA")
 	saToken, err := oc.Run("create").Args("-n", saNamespace, "token", saName, "--duration=72h").Output()
@@ -424,17 +424,11 @@ func CreateInternalRegistrySecretFromSA(oc *exutil.CLI, saName, saNamespace, sec
 	}
 	logger.Debugf("SA TOKEN: %s", saToken)
 
-	logger.Infof("Use a master node to login to the internal registry using the new token")
-	loginOut, loginErr := masterNode.DebugNodeWithChroot("podman", "login", InternalRegistrySvcURL, "-u", saName, "-p", saToken, "--authfile", tmpDockerConfigFile)
-	if loginErr != nil {
-		return nil, fmt.Errorf("Error trying to login to internal registry:\nOutput:%s\nError:%s", loginOut, loginErr)
-	}
-
-	logger.Infof("Copy the docker.json file to local")
-	// Because several MOSCs can be applied at the same time, MCDs can be restarted several times and it can cause a failure in the CopyToLocal method. We retry to mitigate this scenario
-	err = Retry(5, 5*time.Second, func() error { return masterNode.CopyToLocal(tmpDockerConfigFile, tmpDockerConfigFile) })
-	if err != nil {
-		return nil, fmt.Errorf("Error copying the resulting authorization file to local")
+	logger.Infof("Generate the authorization file to login to the internal registry using the new token")
+	defer os.Remove(tmpDockerConfigFile)
+	authFile := (&imageinspect.AuthFile{}).SetCredentials(InternalRegistrySvcURL, imageinspect.Credentials{Username: saName, Password: saToken})
+	if err := authFile.Write(tmpDockerConfigFile); err != nil {
+		return nil, err
 	}
 
 	logger.Infof("OK!")
@@ -4855,6 +4855,61 @@ desiredState:
 		logger.Infof("OK!\n")
 	})
 
+	g.It("Author:sregidor-NonHyperShiftHOST-NonPreRelease-Critical-74608-[P2][OnCLayer] Env file /etc/kubernetes/node.env should not be overwritten after a node power cycle [Disruptive]", func() {
+		// /etc/kubernetes/node.env only exists in AWS. The instance is stopped and started by the platform, the OS doesn't take part in the restart
+		skipTestIfSupportedPlatformNotMatched(oc, AWSPlatform)
+
+		var (
+			node      = GetCompactCompatiblePool(oc.AsAdmin()).GetSortedNodesOrFail()[0]
+			rEnvFile  = NewRemoteFile(node, "/etc/kubernetes/node.env")
+			extraLine = "\nDUMMYKEY=DUMMYVAL"
+		)
+		exutil.By("Get current node.env content")
+		o.Expect(rEnvFile.Fetch()).To(o.Succeed(),
+			"Error getting information about %s", rEnvFile)
+		initialContent := rEnvFile.GetTextContent()
+		initialUser := rEnvFile.GetUIDName()
+		initialGroup := rEnvFile.GetGIDName()
+		initialPermissions := rEnvFile.GetOctalPermissions()
+		logger.Infof("Initial content: %s", initialContent)
+		logger.Infof("OK!\n")
+
+		exutil.By("Modify the content of the node.env file")
+		defer rEnvFile.PushNewTextContent(initialContent)
+		newContent := initialContent + extraLine
+		logger.Infof("New content: %s", newContent)
+		o.Expect(rEnvFile.PushNewTextContent(newContent)).To(o.Succeed(),
+			"Error writing new content in %s", rEnvFile)
+		logger.Infof("OK!\n")
+
+		exutil.By("Power cycle node")
+		o.Expect(node.PowerCycle()).To(o.Succeed(),
+			"Error power cycling %s", node)
+		logger.Infof("OK!\n")
+
+		exutil.By("Check that the content was not changed after the node restart")
+		o.Eventually(rEnvFile.Read, "15m", "20s").Should(o.And(
+			HaveContent(newContent),
+			HaveOwner(initialUser),
+			HaveGroup(initialGroup),
+			HaveOctalPermissions(initialPermissions)),
+			"The information in %s is not the expected one", rEnvFile)
+		logger.Infof("OK!\n")
+
+		exutil.By("Restore initial content")
+		o.Eventually(rEnvFile.PushNewTextContent, "5m", "20s").WithArguments(initialContent).Should(o.Succeed(),
+			"Error writing the initial content in %s", rEnvFile)
+		o.Expect(node.Reboot()).To(o.Succeed(),
+			"Error rebooting %s", node)
+		o.Eventually(rEnvFile.Read, "15m", "20s").Should(o.And(
+			HaveContent(initialContent),
+			HaveOwner(initialUser),
+			HaveGroup(initialGroup),
+			HaveOctalPermissions(initialPermissions)),
+			"The information of %s is not the expected one after restoring the initial content", rEnvFile)
+		logger.Infof("OK!\n")
+	})
+
 	g.It("Author:sregidor-NonHyperShiftHOST-NonPreRelease-Critical-75258-[OnCLayer] No ordering cycle issues should exist [Disruptive]", func() {
 		exutil.By("Check that there are no ordering cycle problems in the nodes")
 		for _, node := range exutil.OrFail[[]Node](NewNodeList(oc.AsAdmin()).GetAllLinux()) {
@@ -1,6 +1,7 @@
 package mco
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 	clusterinfra "github.com/openshift/openshift-tests-private/test/extended/util/clusterinfra"
+	"github.com/openshift/openshift-tests-private/test/extended/util/imageinspect"
 	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
 )
 
@@ -428,22 +430,21 @@ RUN echo "echo 'Hello world! '$(whoami)" > /usr/bin/tc_54159_rpm_and_osimage &&
 		logger.Infof("Base image: %s\n", baseImage)
 
 		exutil.By("Inspect base image information")
-		skopeoCLI := NewSkopeoCLI().SetAuthFile(dockerConfigFile)
-		inspectInfo, err := skopeoCLI.Run("inspect").Args("--tls-verify=false", "--config", "docker://"+baseImage).Output()
+		inspector := imageinspect.NewInspector(imageinspect.NewClient().SetAuthFile(dockerConfigFile).SetInsecure(true))
+		imageConfig, err := inspector.InspectConfig(context.Background(), baseImage)
 		o.Expect(err).NotTo(o.HaveOccurred(),
-			"Error using 'skopeo' to inspect base image %s", baseImage)
+			"Error inspecting base image %s", baseImage)
 
 		logger.Infof("Check if image is bootable")
-		inspectJSON := JSON(inspectInfo)
-		ostreeBootable := inspectJSON.Get("config").Get("Labels").Get("ostree.bootable").ToString()
+		ostreeBootable := imageConfig.Config.Labels["ostree.bootable"]
 		o.Expect(ostreeBootable).To(o.Equal("true"),
-			`The base image %s is expected to be bootable (.config.Labels.ostree\.bootable == "true", but skopeo information says that it is not bootable. %s`,
-			baseImage, inspectInfo)
+			`The base image %s is expected to be bootable (.config.Labels.ostree\.bootable == "true", but the image config says that it is not bootable. %+v`,
+			baseImage, imageConfig.Config.Labels)
 		logger.Infof("OK!\n")
 
 		exutil.By("Verify that old machine config os content is not present in the release info")
-		mcOsIMage, _ := getImageFromReleaseInfo(oc.AsAdmin(), oldMachineConfigOsImage, dockerConfigFile)
-		o.Expect(mcOsIMage).To(o.ContainSubstring(`no image tag "`+oldMachineConfigOsImage+`" exists`),
+		mcOsIMage, mcOsErr := getImageFromReleaseInfo(oc.AsAdmin(), oldMachineConfigOsImage, dockerConfigFile)
+		o.Expect(mcOsErr).To(o.MatchError(o.ContainSubstring(`no image tag "`+oldMachineConfigOsImage+`" exists`)),
 			"%s image should not be present in the release image, but we can find it with value %s", oldMachineConfigOsImage, mcOsIMage)
 		logger.Infof("OK!\n")
 
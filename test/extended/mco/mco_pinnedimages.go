@@ -3,6 +3,7 @@ package mco
 import (
 	"fmt"
 	"math"
+	"os"
 	"strings"
 	"time"
 
@@ -552,9 +553,9 @@ func skipIfDiskSpaceLessThanBytes(node Node, path string, minNumBytes int64) {
 }
 
 // getCurrentReleaseInfoImageSpecOrDefault returns the image spec for the given image in the release. If there is any error, it returns the given default image
-// In disconnected clusters the release image is mirrored. Unfortunately, "oc adm release info" command does not take /etc/containers/registries.conf mirrors into account,
+// In disconnected clusters the release image is mirrored. Unfortunately, the release image is inspected without taking /etc/containers/registries.conf mirrors into account,
 // hence in disconnected clusters we cannot get the release image specs unless we apply the mirror manually.
-// TODO: When the "oc adm release info" command spec fails:
+// TODO: When the release image cannot be inspected:
 // 1. parse the output to get the release image name
 // 2. search in all imagecontentsourcepolicies and all imagedigestimirrorsets if there is any mirror for the release image (it should)
 // 3. use the mirror manually to get the image specs
@@ -568,27 +569,13 @@ func getCurrentReleaseInfoImageSpecOrDefault(oc *exutil.CLI, imageName, defaultI
 
 // getCurrentReleaseInfoImageSpec returns the image spec for the given image in the release
 func getCurrentReleaseInfoImageSpec(oc *exutil.CLI, imageName string) (string, error) {
-	mMcp := NewMachineConfigPool(oc.AsAdmin(), MachineConfigPoolMaster)
-	allNodes, err := mMcp.GetNodes()
-	if err != nil {
-		return "", err
-	}
-	master := allNodes[0]
-
-	remoteAdminKubeConfig := fmt.Sprintf("/root/remoteKubeConfig-%s", exutil.GetRandomString())
-	adminKubeConfig := exutil.KubeConfigPath()
-
-	defer master.RemoveFile(remoteAdminKubeConfig)
-	err = master.CopyFromLocal(adminKubeConfig, remoteAdminKubeConfig)
+	pullSecretFile, err := getPullSecret(oc)
 	if err != nil {
 		return "", err
 	}
+	defer os.Remove(pullSecretFile)
 
-	stdout, _, err := master.DebugNodeWithChrootStd("oc", "adm", "release", "info", "--image-for", imageName, "--registry-config", "/var/lib/kubelet/config.json", "--kubeconfig", remoteAdminKubeConfig)
-	if err != nil {
-		return "", err
-	}
-	return stdout, nil
+	return getImageFromReleaseInfo(oc.AsAdmin(), imageName, pullSecretFile)
 }
 
 // DigestMirrorTest generic instructions for DigestImageMirrorSet tests
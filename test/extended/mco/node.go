@@ -14,6 +14,7 @@ import (
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 	"github.com/openshift/openshift-tests-private/test/extended/util/architecture"
+	compute "github.com/openshift/openshift-tests-private/test/extended/util/compute"
 	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -839,6 +840,35 @@ func (n *Node) Reboot() error {
 	return err
 }
 
+// PowerCycle stops and starts the node's instance using the API of the platform. Unlike Reboot, the OS of the node doesn't take part in the restart
+func (n *Node) PowerCycle() error {
+	pool, err := n.GetPrimaryPool()
+	if err != nil {
+		return err
+	}
+
+	nodes, cleanup := compute.GetNodes(n.oc, pool.GetName())
+	if cleanup != nil {
+		defer cleanup()
+	}
+	instance := nodes.ByName(n.GetName())
+	if instance == nil {
+		return fmt.Errorf("Node %s is not one of the %s nodes reported by the platform", n.GetName(), pool.GetName())
+	}
+
+	logger.Infof("POWER CYCLING NODE %s!!", n.GetName())
+	if err := instance.Stop(); err != nil {
+		return err
+	}
+	if err := compute.WaitForStopped(instance, 10*time.Minute); err != nil {
+		return err
+	}
+	if err := instance.Start(); err != nil {
+		return err
+	}
+	return compute.WaitForRunning(instance, 10*time.Minute)
+}
+
 // IsRpmOsTreeIdle returns true if `rpm-ostree status` reports iddle state
 func (n *Node) IsRpmOsTreeIdle() (bool, error) {
 	status, err := n.GetRpmOstreeStatus(false)
@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/imageinspect"
 	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
@@ -595,7 +596,7 @@ func getFirstHostedCluster(oc *exutil.CLI) string {
 }
 
 // get image url of latest nightly build
-// command is like `oc image info registry.ci.openshift.org/ocp/release:4.12 -a /tmp/config.json -ojson|jq -r '.config.config.Labels."io.openshift.release"'`
+// the release image is inspected like `oc image info registry.ci.openshift.org/ocp/release:4.12 -a /tmp/config.json -ojson|jq -r '.config.config.Labels."io.openshift.release"'`
 // this func does not support negative scenario, if any error occurred, fail the test directly
 // return imageURL and build version
 func getLatestImageURL(oc *exutil.CLI, release string) (string, string) {
@@ -609,14 +610,13 @@ func getLatestImageURL(oc *exutil.CLI, release string) (string, string) {
 	defer os.Remove(registryConfig)
 	o.Expect(extractErr).NotTo(o.HaveOccurred(), "extract registry config from pull secret error")
 
-	imageInfo, getImageInfoErr := oc.AsAdmin().WithoutNamespace().Run("image").Args("info", registryQueryURL, "-a", registryConfig, "-ojson").Output()
+	inspector := imageinspect.NewInspector(imageinspect.NewClient().SetAuthFile(registryConfig))
+	imageInfo, getImageInfoErr := inspector.Inspect(context.Background(), registryQueryURL)
 	o.Expect(getImageInfoErr).NotTo(o.HaveOccurred(), "get image info error")
-	o.Expect(imageInfo).NotTo(o.BeEmpty())
 
-	imageJSON := JSON(imageInfo)
-	buildVersion := imageJSON.Get("config").Get("config").Get("Labels").Get(`io.openshift.release`).ToString()
+	buildVersion := imageInfo.Labels[`io.openshift.release`]
 	o.Expect(buildVersion).NotTo(o.BeEmpty(), "nightly build version is empty")
-	imageDigest := imageJSON.Get("digest").ToString()
+	imageDigest := imageInfo.Digest
 	o.Expect(imageDigest).NotTo(o.BeEmpty(), "image digest is empty")
 
 	imageURL := fmt.Sprintf("%s@%s", registryBaseURL, imageDigest)
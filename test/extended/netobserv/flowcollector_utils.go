@@ -146,8 +146,8 @@ func (lokilabels Lokilabels) getLokiQuery(filterType string, parameters ...strin
 
 func (lokilabels Lokilabels) GetMonolithicLokiFlowLogs(lokiRoute string, startTime time.Time, parameters ...string) ([]FlowRecord, error) {
 	lc := newLokiClient(lokiRoute, startTime).retry(5)
-	lc.quiet = false
-	lc.localhost = true
+	// loki is port-forwarded to localhost
+	lc.Client = lc.Verbose(true).WithoutProxy()
 	lokiQuery := lokilabels.getLokiQuery("REGEX", parameters...)
 	flowRecords := []FlowRecord{}
 	var res *lokiQueryResponse
@@ -2,21 +2,11 @@ package netobserv
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
-	"path"
-	"strconv"
-	"strings"
 	"time"
 
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/loki"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -64,18 +54,10 @@ func validateInfraAndResourcesForLoki(oc *exutil.CLI, reqMemory, reqCPU string,
 	return compareClusterResources(oc, reqCPU, reqMemory)
 }
 
+// lokiClient queries Loki with the shared client from the start time, the responses are decoded into the netobserv types
 type lokiClient struct {
-	username        string    //Username for HTTP basic auth.
-	password        string    //Password for HTTP basic auth
-	address         string    //Server address.
-	orgID           string    //adds X-Scope-OrgID to API requests for representing tenant ID. Useful for requesting tenant data when bypassing an auth gateway.
-	bearerToken     string    //adds the Authorization header to API requests for authentication purposes.
-	bearerTokenFile string    //adds the Authorization header to API requests for authentication purposes.
-	retries         int       //How many times to retry each query when getting an error response from Loki.
-	queryTags       string    //adds X-Query-Tags header to API requests.
-	quiet           bool      //Suppress query metadata.
-	startTime       time.Time //Start time for reading logs
-	localhost       bool      //whether loki is port-forwarded to localhost, useful for monolithic loki
+	*loki.Client
+	startTime time.Time //Start time for reading logs
 }
 
 type lokiQueryResponse struct {
@@ -130,191 +112,17 @@ type lokiQueryResponse struct {
 
 // newLokiClient initializes a lokiClient with server address
 func newLokiClient(routeAddress string, time time.Time) *lokiClient {
-	client := &lokiClient{}
-	client.address = routeAddress
-	client.retries = 5
-	client.quiet = false
-	client.startTime = time
-	client.localhost = false
-	return client
+	return &lokiClient{Client: loki.NewClient(routeAddress), startTime: time}
 }
 
 // retry sets how many times to retry each query
 func (c *lokiClient) retry(retry int) *lokiClient {
-	nc := *c
-	nc.retries = retry
-	return &nc
+	return &lokiClient{Client: c.WithRetries(retry), startTime: c.startTime}
 }
 
 // withToken sets the token used to do query
 func (c *lokiClient) withToken(bearerToken string) *lokiClient {
-	nc := *c
-	nc.bearerToken = bearerToken
-	return &nc
-}
-
-// buildURL concats a url `http://foo/bar` with a path `/buzz`.
-func buildURL(u, p, q string) (string, error) {
-	url, err := url.Parse(u)
-	if err != nil {
-		return "", err
-	}
-	url.Path = path.Join(url.Path, p)
-	url.RawQuery = q
-	return url.String(), nil
-}
-
-type queryStringBuilder struct {
-	values url.Values
-}
-
-func newQueryStringBuilder() *queryStringBuilder {
-	return &queryStringBuilder{
-		values: url.Values{},
-	}
-}
-
-// encode returns the URL-encoded query string based on key-value
-// parameters added to the builder calling Set functions.
-func (b *queryStringBuilder) encode() string {
-	return b.values.Encode()
-}
-
-func (b *queryStringBuilder) setString(name, value string) {
-	b.values.Set(name, value)
-}
-
-func (b *queryStringBuilder) setInt(name string, value int64) {
-	b.setString(name, strconv.FormatInt(value, 10))
-}
-
-func (b *queryStringBuilder) setInt32(name string, value int) {
-	b.setString(name, strconv.Itoa(value))
-}
-
-func (c *lokiClient) getHTTPRequestHeader() (http.Header, error) {
-	h := make(http.Header)
-	if c.username != "" && c.password != "" {
-		h.Set(
-			"Authorization",
-			"Basic "+base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password)),
-		)
-	}
-	h.Set("User-Agent", "loki-logcli")
-
-	if c.orgID != "" {
-		h.Set("X-Scope-OrgID", c.orgID)
-	}
-
-	if c.queryTags != "" {
-		h.Set("X-Query-Tags", c.queryTags)
-	}
-
-	if (c.username != "" || c.password != "") && (len(c.bearerToken) > 0 || len(c.bearerTokenFile) > 0) {
-		return nil, fmt.Errorf("at most one of HTTP basic auth (username/password), bearer-token & bearer-token-file is allowed to be configured")
-	}
-
-	if len(c.bearerToken) > 0 && len(c.bearerTokenFile) > 0 {
-		return nil, fmt.Errorf("at most one of the options bearer-token & bearer-token-file is allowed to be configured")
-	}
-
-	if c.bearerToken != "" {
-		h.Set("Authorization", "Bearer "+c.bearerToken)
-	}
-
-	if c.bearerTokenFile != "" {
-		b, err := os.ReadFile(c.bearerTokenFile)
-		if err != nil {
-			return nil, fmt.Errorf("unable to read authorization credentials file %s: %s", c.bearerTokenFile, err)
-		}
-		bearerToken := strings.TrimSpace(string(b))
-		h.Set("Authorization", "Bearer "+bearerToken)
-	}
-	return h, nil
-}
-
-func (c *lokiClient) doRequest(path, query string, quiet bool, out interface{}) error {
-	us, err := buildURL(c.address, path, query)
-	if err != nil {
-		return err
-	}
-	if !quiet {
-		e2e.Logf(us)
-	}
-
-	req, err := http.NewRequest("GET", us, nil)
-	if err != nil {
-		return err
-	}
-
-	h, err := c.getHTTPRequestHeader()
-	if err != nil {
-		return err
-	}
-	req.Header = h
-
-	var tr *http.Transport
-	proxy := getProxyFromEnv()
-
-	// don't use proxy if svc/loki is port-forwarded to localhost
-	if !c.localhost && len(proxy) > 0 {
-		proxyURL, err := url.Parse(proxy)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		tr = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			Proxy:           http.ProxyURL(proxyURL),
-		}
-	} else {
-		tr = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
-
-	client := &http.Client{Transport: tr}
-
-	var resp *http.Response
-	attempts := c.retries + 1
-	success := false
-
-	for attempts > 0 {
-		attempts--
-		resp, err = client.Do(req)
-		if err != nil {
-			e2e.Logf("error sending request %v", err)
-			continue
-		}
-		if resp.StatusCode/100 != 2 {
-			buf, _ := io.ReadAll(resp.Body) // nolint
-			e2e.Logf("Error response from server: %s (%v) attempts remaining: %d", string(buf), err, attempts)
-			if err := resp.Body.Close(); err != nil {
-				e2e.Logf("error closing body", err)
-			}
-			continue
-		}
-		success = true
-		break
-	}
-	if !success {
-		return fmt.Errorf("run out of attempts while querying the server")
-	}
-
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			e2e.Logf("error closing body", err)
-		}
-	}()
-	return json.NewDecoder(resp.Body).Decode(out)
-}
-
-func (c *lokiClient) doQuery(path string, query string, quiet bool) (*lokiQueryResponse, error) {
-	var err error
-	var r lokiQueryResponse
-
-	if err = c.doRequest(path, query, quiet, &r); err != nil {
-		return nil, err
-	}
-
-	return &r, nil
+	return &lokiClient{Client: c.WithToken(bearerToken), startTime: c.startTime}
 }
 
 // queryRange uses the /api/v1/query_range endpoint to execute a range query
@@ -325,26 +133,11 @@ func (c *lokiClient) doQuery(path string, query string, quiet bool) (*lokiQueryR
 // end: Stop looking for logs at this absolute time (exclusive)
 // forward: true means scan forwards through logs, false means scan backwards through logs
 func (c *lokiClient) queryRange(logType string, queryStr string, limit int, start, end time.Time, forward bool) (*lokiQueryResponse, error) {
-	direction := func() string {
-		if forward {
-			return "FORWARD"
-		}
-		return "BACKWARD"
-	}
-	params := newQueryStringBuilder()
-	params.setString("query", queryStr)
-	params.setInt32("limit", limit)
-	params.setInt("start", start.UnixNano())
-	params.setInt("end", end.UnixNano())
-	params.setString("direction", direction())
-	logPath := ""
-	if len(logType) > 0 {
-		logPath = apiPath + logType + queryRangePath
-	} else {
-		logPath = queryRangePath
+	var r lokiQueryResponse
+	if err := c.Get(logType, loki.QueryRangePath, loki.QueryRangeParams(queryStr, limit, start, end, forward), &r); err != nil {
+		return nil, err
 	}
-
-	return c.doQuery(logPath, params.encode(), c.quiet)
+	return &r, nil
 }
 
 func (c *lokiClient) searchLogsInLoki(logType, query string) (*lokiQueryResponse, error) {
@@ -31,10 +31,8 @@ import (
 )
 
 const (
-	minioNS        = "minio-aosqe"
-	minioSecret    = "minio-creds"
-	apiPath        = "/api/logs/v1/"
-	queryRangePath = "/loki/api/v1/query_range"
+	minioNS     = "minio-aosqe"
+	minioSecret = "minio-creds"
 )
 
 // s3Credential defines the s3 credentials
@@ -884,6 +882,8 @@ func createServiceAccountOnGCP(projectID, name string) (*iam.ServiceAccount, err
 		return nil, fmt.Errorf("failed to create serviceaccount: %w", err)
 	}
 	e2e.Logf("Created service account: %v", account)
+	// the leaked service accounts are deleted by the GCP service account cleaner of the logging tests
+	exutil.RegisterCloudResource(exutil.CloudProviderGCP, exutil.CloudResourceServiceAccount, "projects/"+projectID+"/serviceAccounts/"+account.Email, "")
 	return account, nil
 }
 
@@ -1118,6 +1118,7 @@ func removeServiceAccountFromGCP(name string) error {
 	if err != nil {
 		return fmt.Errorf("can't remove service account: %v", err)
 	}
+	exutil.ReleaseCloudResource(exutil.CloudProviderGCP, exutil.CloudResourceServiceAccount, name)
 	return nil
 }
 
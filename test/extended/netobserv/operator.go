@@ -13,6 +13,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/olm"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -24,11 +25,13 @@ import (
 type SubscriptionObjects struct {
 	OperatorName     string
 	Namespace        string
-	OperatorGroup    string // the file used to create operator group
-	Subscription     string // the file used to create subscription
+	AllNamespaces    bool // the operator group created for the operator targets all the namespaces
 	PackageName      string
 	CatalogSource    *CatalogSourceObjects `json:",omitempty"`
 	OperatorPodLabel string
+
+	// olmOperator is built once so the objects created by the install are removed by the uninstall
+	olmOperator *olm.Operator
 }
 
 // CatalogSourceObjects defines the source used to subscribe an operator
@@ -154,132 +157,37 @@ func (versions *version) gitCheckout(tempdir *string) error {
 	return nil
 }
 
-// waitForPackagemanifestAppear waits for the packagemanifest to appear in the cluster
-// chSource: bool value, true means the packagemanifests' source name must match the so.CatalogSource.SourceName, e.g.: oc get packagemanifests xxxx -l catalog=$source-name
-func (so *SubscriptionObjects) waitForPackagemanifestAppear(oc *exutil.CLI, chSource bool) {
-	args := []string{"-n", so.CatalogSource.SourceNamespace, "packagemanifests"}
-	if chSource {
-		args = append(args, "-l", "catalog="+so.CatalogSource.SourceName)
-	} else {
-		args = append(args, so.PackageName)
+// operator returns the OLM operator of the subscription objects, the channel defaults to stable
+func (so *SubscriptionObjects) operator() *olm.Operator {
+	if so.olmOperator != nil {
+		return so.olmOperator
+	}
+	if so.CatalogSource == nil {
+		so.CatalogSource = &CatalogSourceObjects{}
 	}
-	err := wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 180*time.Second, false, func(context.Context) (done bool, err error) {
-		packages, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(args...).Output()
-		if err != nil {
-			msg := fmt.Sprintf("%v", err)
-			if strings.Contains(msg, "No resources found") || strings.Contains(msg, "NotFound") {
-				return false, nil
-			}
-			return false, err
-		}
-		if strings.Contains(packages, so.PackageName) {
-			return true, nil
-		}
-		e2e.Logf("Waiting for packagemanifest/%s to appear", so.PackageName)
-		return false, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("Packagemanifest %s is not availabile", so.PackageName))
-}
-
-// setCatalogSourceObjects set the default values of channel, source namespace and source name if they're not specified
-func (so *SubscriptionObjects) setCatalogSourceObjects(oc *exutil.CLI) {
-	// set channel
 	if so.CatalogSource.Channel == "" {
 		so.CatalogSource.Channel = "stable"
 	}
-
-	// set source namespace
-	if so.CatalogSource.SourceNamespace == "" {
-		so.CatalogSource.SourceNamespace = "openshift-marketplace"
-	}
-
-	// set source and check if the packagemanifest exists or not
-	if so.CatalogSource.SourceName != "" {
-		so.waitForPackagemanifestAppear(oc, true)
-	} else {
-		catsrc, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("catsrc", "-n", so.CatalogSource.SourceNamespace, "qe-app-registry").Output()
-		if catsrc != "" && !(strings.Contains(catsrc, "NotFound")) {
-			so.CatalogSource.SourceName = "qe-app-registry"
-			so.waitForPackagemanifestAppear(oc, true)
-		} else {
-			so.waitForPackagemanifestAppear(oc, false)
-			source, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("packagemanifests", so.PackageName, "-o", "jsonpath={.status.catalogSource}").Output()
-			if err != nil {
-				e2e.Logf("error getting catalog source name: %v", err)
-			}
-			so.CatalogSource.SourceName = source
-		}
+	so.olmOperator = &olm.Operator{
+		Name:                    so.OperatorName,
+		Package:                 so.PackageName,
+		Namespace:               so.Namespace,
+		AllNamespaces:           so.AllNamespaces,
+		NamespaceLabels:         map[string]string{"openshift.io/cluster-monitoring": "true"},
+		NamespaceAnnotations:    map[string]string{"openshift.io/node-selector": ""},
+		Channel:                 so.CatalogSource.Channel,
+		CatalogSource:           so.CatalogSource.SourceName,
+		CatalogSourceNamespace:  so.CatalogSource.SourceNamespace,
+		PreferredCatalogSources: []string{"qe-app-registry"},
+		PodLabel:                so.OperatorPodLabel,
+		KeepCRDs:                true,
 	}
+	return so.olmOperator
 }
 
-// SubscribeOperator is used to subcribe the CLO and EO
+// SubscribeOperator is used to subcribe the operators
 func (so *SubscriptionObjects) SubscribeOperator(oc *exutil.CLI) {
-	// check if the namespace exists, if it doesn't exist, create the namespace
-	_, err := oc.AdminKubeClient().CoreV1().Namespaces().Get(context.Background(), so.Namespace, metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			e2e.Logf("The project %s is not found, create it now...", so.Namespace)
-			namespaceTemplate := exutil.FixturePath("testdata", "logging", "subscription", "namespace.yaml")
-			namespaceFile, err := processTemplate(oc, "-f", namespaceTemplate, "-p", "NAMESPACE_NAME="+so.Namespace)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 60*time.Second, false, func(context.Context) (done bool, err error) {
-				output, err := oc.AsAdmin().Run("apply").Args("-f", namespaceFile).Output()
-				if err != nil {
-					if strings.Contains(output, "AlreadyExists") {
-						return true, nil
-					}
-					return false, err
-				}
-				return true, nil
-			})
-			exutil.AssertWaitPollNoErr(err, fmt.Sprintf("can't create project %s", so.Namespace))
-		}
-	}
-
-	// check the operator group, if no object found, then create an operator group in the project
-	og, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("-n", so.Namespace, "og").Output()
-	o.Expect(err).NotTo(o.HaveOccurred())
-	msg := fmt.Sprintf("%v", og)
-	if strings.Contains(msg, "No resources found") {
-		// create operator group
-		ogFile, err := processTemplate(oc, "-n", so.Namespace, "-f", so.OperatorGroup, "-p", "OG_NAME="+so.Namespace, "NAMESPACE="+so.Namespace)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 60*time.Second, false, func(context.Context) (done bool, err error) {
-			output, err := oc.AsAdmin().Run("apply").Args("-f", ogFile, "-n", so.Namespace).Output()
-			if err != nil {
-				if strings.Contains(output, "AlreadyExists") {
-					return true, nil
-				}
-				return false, err
-			}
-			return true, nil
-		})
-		exutil.AssertWaitPollNoErr(err, fmt.Sprintf("can't create operatorgroup %s in %s project", so.Namespace, so.Namespace))
-	}
-
-	// check subscription, if there is no subscription objets, then create one
-	sub, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("sub", "-n", so.Namespace, so.PackageName).Output()
-	if err != nil {
-		msg := fmt.Sprint("v%", sub)
-		if strings.Contains(msg, "NotFound") {
-			so.setCatalogSourceObjects(oc)
-			//create subscription object
-			subscriptionFile, err := processTemplate(oc, "-n", so.Namespace, "-f", so.Subscription, "-p", "PACKAGE_NAME="+so.PackageName, "NAMESPACE="+so.Namespace, "CHANNEL="+so.CatalogSource.Channel, "SOURCE="+so.CatalogSource.SourceName, "SOURCE_NAMESPACE="+so.CatalogSource.SourceNamespace)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			err = wait.PollUntilContextTimeout(context.Background(), 5*time.Second, 60*time.Second, false, func(context.Context) (done bool, err error) {
-				output, err := oc.AsAdmin().Run("apply").Args("-f", subscriptionFile, "-n", so.Namespace).Output()
-				if err != nil {
-					if strings.Contains(output, "AlreadyExists") {
-						return true, nil
-					}
-					return false, err
-				}
-				return true, nil
-			})
-			exutil.AssertWaitPollNoErr(err, fmt.Sprintf("can't create subscription %s in %s project", so.PackageName, so.Namespace))
-		}
-	}
-	//WaitForDeploymentPodsToBeReady(oc, so.Namespace, so.OperatorName)
+	so.operator().Install(oc)
 }
 
 func deleteNamespace(oc *exutil.CLI, ns string) {
@@ -304,8 +212,7 @@ func deleteNamespace(oc *exutil.CLI, ns string) {
 }
 
 func (so *SubscriptionObjects) uninstallOperator(oc *exutil.CLI) {
-	Resource{"subscription", so.PackageName, so.Namespace}.clear(oc)
-	_ = oc.AsAdmin().WithoutNamespace().Run("delete").Args("-n", so.Namespace, "csv", "-l", "operators.coreos.com/"+so.PackageName+"."+so.Namespace+"=").Execute()
+	so.operator().Uninstall(oc)
 	// do not remove namespace openshift-logging and openshift-operators-redhat, and preserve the operatorgroup as there may have several operators deployed in one namespace
 	// for example: loki-operator and elasticsearch-operator
 	if so.Namespace != "openshift-logging" && so.Namespace != "openshift-operators-redhat" && so.Namespace != "openshift-operators" && so.Namespace != "openshift-netobserv-operator" && !strings.HasPrefix(so.Namespace, "e2e-test-") {
@@ -41,8 +41,7 @@ var _ = g.Describe("[sig-netobserv] Network_Observability", func() {
 			OperatorName:  "netobserv-operator",
 			Namespace:     netobservNS,
 			PackageName:   NOPackageName,
-			Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
-			OperatorGroup: filePath.Join(subscriptionDir, "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: &NOSource,
 		}
 
@@ -57,8 +56,7 @@ var _ = g.Describe("[sig-netobserv] Network_Observability", func() {
 			OperatorName:  "opentelemetry-operator",
 			Namespace:     OtelNS.Name,
 			PackageName:   "opentelemetry-product",
-			Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
-			OperatorGroup: filePath.Join(subscriptionDir, "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: &OTELSource,
 		}
 	)
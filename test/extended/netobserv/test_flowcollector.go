@@ -44,8 +44,7 @@ var _ = g.Describe("[sig-netobserv] Network_Observability", func() {
 			OperatorName:  "netobserv-operator",
 			Namespace:     netobservNS,
 			PackageName:   NOPackageName,
-			Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
-			OperatorGroup: filePath.Join(subscriptionDir, "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: &NOSource,
 		}
 		imageDigest    = filePath.Join(subscriptionDir, "image-digest-mirror-set.yaml")
@@ -61,8 +60,7 @@ var _ = g.Describe("[sig-netobserv] Network_Observability", func() {
 			OperatorName:  "loki-operator-controller-manager",
 			Namespace:     lokiNS,
 			PackageName:   lokiPackageName,
-			Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
-			OperatorGroup: filePath.Join(subscriptionDir, "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: &lokiSource,
 		}
 	)
@@ -1682,8 +1680,6 @@ var _ = g.Describe("[sig-netobserv] Network_Observability", func() {
 			OperatorName:  "kubevirt-hyperconverged",
 			Namespace:     virtOperatorNS,
 			PackageName:   virtPackageName,
-			Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
-			OperatorGroup: filePath.Join(subscriptionDir, "singlenamespace-og.yaml"),
 			CatalogSource: &virtSource,
 		}
 
@@ -2250,7 +2246,6 @@ var _ = g.Describe("[sig-netobserv] Network_Observability", func() {
 				OperatorName:  "amq-streams-cluster-operator",
 				Namespace:     "openshift-operators",
 				PackageName:   "amq-streams",
-				Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
 				CatalogSource: &kafkaSource,
 			}
 
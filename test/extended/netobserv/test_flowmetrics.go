@@ -39,8 +39,7 @@ var _ = g.Describe("[sig-netobserv] Network_Observability", func() {
 			OperatorName:  "netobserv-operator",
 			Namespace:     netobservNS,
 			PackageName:   NOPackageName,
-			Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
-			OperatorGroup: filePath.Join(subscriptionDir, "allnamespace-og.yaml"),
+			AllNamespaces: true,
 			CatalogSource: &NOSource,
 		}
 		flow Flowcollector
@@ -16,6 +16,7 @@ import (
 
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/loki"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -297,10 +298,11 @@ func getSAToken(oc *exutil.CLI, name, ns string) string {
 }
 
 func doHTTPRequest(header http.Header, address, path, query, method string, quiet bool, attempts int, requestBody io.Reader, expectedStatusCode int) ([]byte, error) {
-	us, err := buildURL(address, path, query)
+	u, err := loki.BuildURL(address, path, query)
 	if err != nil {
 		return nil, err
 	}
+	us := u.String()
 	if !quiet {
 		e2e.Logf(us)
 	}
@@ -192,19 +192,21 @@ var _ = g.Describe("[sig-networking] SDN bgp egressIP", func() {
 		primaryInf, infErr := getSnifPhyInf(oc, egressNodes[0])
 		o.Expect(infErr).NotTo(o.HaveOccurred())
 		externalPublicHost := "www.google.com"
-		var tcpdumpCmd, cmdOnPod string
+		var tcpdumpCmd, cmdOnPod, captureFilter string
 		if ipStackType == "ipv4single" || ipStackType == "dualstack" {
 			tcpdumpCmd = fmt.Sprintf("timeout 60s tcpdump -c 2 -nni %s host %s", primaryInf, externalPublicHost)
 			cmdOnPod = "curl -I -k " + externalPublicHost
+			captureFilter = "host " + externalPublicHost
 		}
 		if ipStackType == "ipv6single" {
 			tcpdumpCmd = fmt.Sprintf("timeout 60s tcpdump -c 2 -nni %s ip6 host %s", primaryInf, externalPublicHost)
 			cmdOnPod = "curl -I -6 -k " + externalPublicHost
+			captureFilter = "ip6 host " + externalPublicHost
 		}
 
 		for i := 0; i < len(EIPPods); i++ {
-			tcpdumOutput := getTcpdumpOnNodeCmdFromPod(oc, egressNodes[0], tcpdumpCmd, ns1, EIPPods[i].name, cmdOnPod)
-			o.Expect(strings.Contains(tcpdumOutput, freeIPs[0])).To(o.BeTrue())
+			packets := capturePacketsOnNodeFromPod(oc, egressNodes[0], primaryInf, captureFilter, ns1, EIPPods[i].name, cmdOnPod)
+			o.Expect(packets.From(freeIPs[0])).NotTo(o.BeEmpty(), "No packets with the egressIP as source captured:\n%s", packets)
 		}
 
 		exutil.By("6.1. Create a 3rd test pod on egress node but do not label it so this pod will not use egressIP")
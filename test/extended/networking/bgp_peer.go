@@ -0,0 +1,313 @@
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+	netutils "k8s.io/utils/net"
+)
+
+// bgpPeer is a BGP router outside the cluster BGP speakers, used by RouteAdvertisement, MetalLB and BGP UDN tests.
+// The router runs FRR, either in a container of an external host or in a pod, and it is inspected with "vtysh -c ... json"
+// so the tests check typed neighbor, route and BFD states instead of parsing the text output.
+type bgpPeer struct {
+	name string
+	// vtysh runs a vtysh command in the router and returns its output
+	vtysh func(cmd string) (string, error)
+}
+
+// bgpNeighbor is the state of a BGP session as reported by "show bgp neighbors json"
+type bgpNeighbor struct {
+	Address         string                           `json:"-"`
+	RemoteAS        int64                            `json:"remoteAs"`
+	LocalAS         int64                            `json:"localAs"`
+	State           string                           `json:"bgpState"`
+	Hostname        string                           `json:"hostname"`
+	AddressFamilies map[string]bgpNeighborFamilyInfo `json:"addressFamilyInfo"`
+	BFD             *bgpNeighborBFDInfo              `json:"peerBfdInfo"`
+}
+
+// bgpNeighborFamilyInfo contains the prefix counters of a neighbor for an address family, i.e. "ipv4Unicast"
+type bgpNeighborFamilyInfo struct {
+	AcceptedPrefixes int `json:"acceptedPrefixCounter"`
+	SentPrefixes     int `json:"sentPrefixCounter"`
+}
+
+// bgpNeighborBFDInfo is the BFD state of a BGP neighbor
+type bgpNeighborBFDInfo struct {
+	Status string `json:"status"`
+}
+
+// bgpRoute is a path in the BGP table
+type bgpRoute struct {
+	Prefix   string
+	Nexthops []string
+	Valid    bool
+	Best     bool
+	// PathFrom is "internal" for iBGP and "external" for eBGP routes
+	PathFrom string
+}
+
+// bfdPeer is a BFD session as reported by "show bfd peers json"
+type bfdPeer struct {
+	Peer      string `json:"peer"`
+	Local     string `json:"local"`
+	VRF       string `json:"vrf"`
+	Interface string `json:"interface"`
+	Status    string `json:"status"`
+	Multihop  bool   `json:"multihop"`
+}
+
+// newExternalFrrContainerPeer returns the FRR container created with createExternalFrrRouter in the external host
+func newExternalFrrContainerPeer(host, frrContainerID string) *bgpPeer {
+	return &bgpPeer{
+		name: fmt.Sprintf("FRR container %s on %s", frrContainerID, host),
+		vtysh: func(cmd string) (string, error) {
+			return sshRunCmdOutPut(host, "root", fmt.Sprintf("sudo podman exec %s vtysh -c '%s'", frrContainerID, cmd))
+		},
+	}
+}
+
+// newFrrPodPeer returns an FRR router running in a pod, i.e. the MetalLB test router pod. Container can be empty if the pod has only one.
+func newFrrPodPeer(oc *exutil.CLI, namespace, podName, container string) *bgpPeer {
+	return &bgpPeer{
+		name: fmt.Sprintf("FRR pod %s/%s", namespace, podName),
+		vtysh: func(cmd string) (string, error) {
+			args := []string{"-n", namespace, podName}
+			if container != "" {
+				args = append(args, "-c", container)
+			}
+			args = append(args, "--", "vtysh", "-c", cmd)
+			return oc.AsAdmin().WithoutNamespace().Run("exec").Args(args...).Output()
+		},
+	}
+}
+
+// newFrrK8sPeerOnNode returns the frr-k8s router of a cluster node, the other end of the sessions with the external peers
+func newFrrK8sPeerOnNode(oc *exutil.CLI, nodeName string) (*bgpPeer, error) {
+	podName, err := exutil.GetPodName(oc, "openshift-frr-k8s", "app=frr-k8s", nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if podName == "" {
+		return nil, fmt.Errorf("No frr-k8s pod found in node %s", nodeName)
+	}
+	return newFrrPodPeer(oc, "openshift-frr-k8s", podName, "frr"), nil
+}
+
+// String implements the Stringer interface
+func (p *bgpPeer) String() string {
+	return p.name
+}
+
+// vtyshJSON runs a vtysh command with the json modifier and decodes its output
+func (p *bgpPeer) vtyshJSON(cmd string, v interface{}) error {
+	output, err := p.vtysh(cmd + " json")
+	if err != nil {
+		return fmt.Errorf("Error running %q in %s: %v", cmd, p, err)
+	}
+	if err := json.Unmarshal([]byte(output), v); err != nil {
+		return fmt.Errorf("Error decoding the output of %q in %s: %v. Output: %s", cmd, p, err, output)
+	}
+	return nil
+}
+
+// bgpVRFArgs returns the vrf arguments of a "show bgp" command, the default VRF is used if vrf is empty
+func bgpVRFArgs(vrf string) string {
+	if vrf == "" {
+		vrf = "default"
+	}
+	return "vrf " + vrf
+}
+
+// bgpAFIArgs returns the address family arguments of a "show bgp" command for the IP family of the given address
+func bgpAFIArgs(ip string) string {
+	if netutils.IsIPv6String(ip) || netutils.IsIPv6CIDRString(ip) {
+		return "ipv6 unicast"
+	}
+	return "ipv4 unicast"
+}
+
+// Neighbors returns the BGP neighbors in the VRF indexed by address
+func (p *bgpPeer) Neighbors(vrf string) (map[string]bgpNeighbor, error) {
+	raw := map[string]json.RawMessage{}
+	if err := p.vtyshJSON(fmt.Sprintf("show bgp %s neighbors", bgpVRFArgs(vrf)), &raw); err != nil {
+		return nil, err
+	}
+	neighbors := map[string]bgpNeighbor{}
+	for address, data := range raw {
+		// the output may contain other keys like vrfId or vrfName
+		if net.ParseIP(address) == nil {
+			continue
+		}
+		neighbor := bgpNeighbor{}
+		if err := json.Unmarshal(data, &neighbor); err != nil {
+			return nil, fmt.Errorf("Error decoding neighbor %s in %s: %v", address, p, err)
+		}
+		neighbor.Address = address
+		neighbors[address] = neighbor
+	}
+	return neighbors, nil
+}
+
+// IsEstablished returns true if the BGP session with the neighbor is established. Unknown neighbors are not established.
+func (p *bgpPeer) IsEstablished(vrf, neighborIP string) (bool, error) {
+	neighbors, err := p.Neighbors(vrf)
+	if err != nil {
+		return false, err
+	}
+	neighbor, ok := neighbors[neighborIP]
+	return ok && neighbor.State == "Established", nil
+}
+
+// frrRoutes is the output of "show bgp ... json" and "show bgp ... neighbors <ip> routes json"
+type frrRoutes struct {
+	Routes map[string][]struct {
+		Valid    bool   `json:"valid"`
+		Best     bool   `json:"bestpath"`
+		PathFrom string `json:"pathFrom"`
+		Nexthops []struct {
+			IP string `json:"ip"`
+		} `json:"nexthops"`
+	} `json:"routes"`
+}
+
+func (r frrRoutes) toRoutes() []bgpRoute {
+	routes := []bgpRoute{}
+	for prefix, paths := range r.Routes {
+		for _, path := range paths {
+			route := bgpRoute{Prefix: prefix, Valid: path.Valid, Best: path.Best, PathFrom: path.PathFrom}
+			for _, nexthop := range path.Nexthops {
+				route.Nexthops = append(route.Nexthops, nexthop.IP)
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// Routes returns the paths in the BGP table of the VRF for the family, "ipv4" or "ipv6"
+func (p *bgpPeer) Routes(vrf, family string) ([]bgpRoute, error) {
+	output := frrRoutes{}
+	if err := p.vtyshJSON(fmt.Sprintf("show bgp %s %s unicast", bgpVRFArgs(vrf), family), &output); err != nil {
+		return nil, err
+	}
+	return output.toRoutes(), nil
+}
+
+// ReceivedRoutes returns the paths received from the neighbor and accepted in the VRF
+func (p *bgpPeer) ReceivedRoutes(vrf, neighborIP string) ([]bgpRoute, error) {
+	output := frrRoutes{}
+	if err := p.vtyshJSON(fmt.Sprintf("show bgp %s %s neighbors %s routes", bgpVRFArgs(vrf), bgpAFIArgs(neighborIP), neighborIP), &output); err != nil {
+		return nil, err
+	}
+	return output.toRoutes(), nil
+}
+
+// AdvertisedRoutes returns the prefixes advertised to the neighbor
+func (p *bgpPeer) AdvertisedRoutes(vrf, neighborIP string) ([]string, error) {
+	output := struct {
+		AdvertisedRoutes map[string]json.RawMessage `json:"advertisedRoutes"`
+	}{}
+	if err := p.vtyshJSON(fmt.Sprintf("show bgp %s %s neighbors %s advertised-routes", bgpVRFArgs(vrf), bgpAFIArgs(neighborIP), neighborIP), &output); err != nil {
+		return nil, err
+	}
+	prefixes := []string{}
+	for prefix := range output.AdvertisedRoutes {
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// HasRoute returns true if the BGP table of the VRF has a valid path for the prefix via the nexthop. Any nexthop is accepted if it is empty.
+func (p *bgpPeer) HasRoute(vrf, prefix, nexthop string) (bool, error) {
+	family := "ipv4"
+	if netutils.IsIPv6CIDRString(prefix) || netutils.IsIPv6String(prefix) {
+		family = "ipv6"
+	}
+	routes, err := p.Routes(vrf, family)
+	if err != nil {
+		return false, err
+	}
+	for _, route := range routes {
+		if route.Prefix != prefix || !route.Valid {
+			continue
+		}
+		if nexthop == "" {
+			return true, nil
+		}
+		for _, hop := range route.Nexthops {
+			if hop == nexthop {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// BFDPeers returns the BFD sessions of the router
+func (p *bgpPeer) BFDPeers() ([]bfdPeer, error) {
+	peers := []bfdPeer{}
+	if err := p.vtyshJSON("show bfd peers", &peers); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// waitForCondition polls the condition in the peer. Errors running vtysh are retried.
+func (p *bgpPeer) waitForCondition(description string, interval, timeout time.Duration, condition func() (bool, error)) error {
+	return wait.PollUntilContextTimeout(context.Background(), interval, timeout, true, func(ctx context.Context) (bool, error) {
+		done, err := condition()
+		if err != nil {
+			e2e.Logf("%v, try again ...", err)
+			return false, nil
+		}
+		if !done {
+			e2e.Logf("Waiting for %s in %s", description, p)
+		}
+		return done, nil
+	})
+}
+
+// WaitForNeighbor waits until the BGP session with the neighbor is established, or not established if expected is false
+func (p *bgpPeer) WaitForNeighbor(vrf, neighborIP string, expected bool) error {
+	return p.waitForCondition(fmt.Sprintf("neighbor %s established=%v", neighborIP, expected), 10*time.Second, 3*time.Minute, func() (bool, error) {
+		established, err := p.IsEstablished(vrf, neighborIP)
+		return established == expected, err
+	})
+}
+
+// WaitForRoute waits until the route is in the BGP table of the VRF, or until it is withdrawn if expected is false
+func (p *bgpPeer) WaitForRoute(vrf, prefix, nexthop string, expected bool) error {
+	return p.waitForCondition(fmt.Sprintf("route %s via %s present=%v", prefix, nexthop, expected), 10*time.Second, 3*time.Minute, func() (bool, error) {
+		found, err := p.HasRoute(vrf, prefix, nexthop)
+		return found == expected, err
+	})
+}
+
+// WaitForBFDPeersUp waits until all the BFD sessions of the router are up
+func (p *bgpPeer) WaitForBFDPeersUp() error {
+	return p.waitForCondition("all BFD sessions up", 10*time.Second, 2*time.Minute, func() (bool, error) {
+		peers, err := p.BFDPeers()
+		if err != nil {
+			return false, err
+		}
+		down := []string{}
+		for _, peer := range peers {
+			if !strings.EqualFold(peer.Status, "up") {
+				down = append(down, peer.Peer+"="+peer.Status)
+			}
+		}
+		if len(down) > 0 {
+			e2e.Logf("BFD sessions not up: %v", down)
+		}
+		return len(peers) > 0 && len(down) == 0, nil
+	})
+}
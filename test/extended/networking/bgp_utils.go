@@ -501,85 +501,40 @@ func verifyRouteAdvertisement(oc *exutil.CLI, host, externalFRRIP2, externalFRRI
 }
 
 func verifyBGPNeighborOnExternalFrr(host, frrContainerID, nodeIP1, nodeIP2 string, expected bool) bool {
-	var externalFrrCmd string
-
-	if netutils.IsIPv6String(nodeIP1) {
-		externalFrrCmd = "sudo podman exec -it " + frrContainerID + " vtysh -c \"show bgp ipv6 neighbor " + nodeIP1 + "\""
-	}
-	if netutils.IsIPv4String(nodeIP1) {
-		externalFrrCmd = "sudo podman exec -it " + frrContainerID + " vtysh -c \"show bgp neighbor " + nodeIP1 + "\""
-	}
-
-	output, err := sshRunCmdOutPut(host, "root", externalFrrCmd)
-	o.Expect(err).NotTo(o.HaveOccurred())
-	if !strings.Contains(string(output), "BGP state = Established") && expected {
-		e2e.Logf("BGP neighborhood is NOT established for the node as expected")
-		return false
-	}
-	if strings.Contains(string(output), "BGP state = Established") && !expected {
-		e2e.Logf("The node should not be selected to establish BGP neighbor with external frr")
-		return false
-	}
-
-	if nodeIP2 != "" {
-		externalFrrCmd = "sudo podman exec -it " + frrContainerID + " vtysh -c \"show bgp ipv6 neighbor " + nodeIP2 + "\""
-		output, err := sshRunCmdOutPut(host, "root", externalFrrCmd)
+	peer := newExternalFrrContainerPeer(host, frrContainerID)
+	for _, nodeIP := range []string{nodeIP1, nodeIP2} {
+		if nodeIP == "" {
+			continue
+		}
+		established, err := peer.IsEstablished("", nodeIP)
 		o.Expect(err).NotTo(o.HaveOccurred())
-		if !strings.Contains(string(output), "BGP state = Established") && expected {
-			e2e.Logf("From IPv6 perspective in dualstack, BGP neighborhood is NOT established for the node as expected")
+		if !established && expected {
+			e2e.Logf("BGP neighborhood is NOT established for the node IP %s as expected", nodeIP)
 			return false
 		}
-		if strings.Contains(string(output), "BGP state = Established") && !expected {
-			e2e.Logf("From IPv6 perspective in dualstack, The node should not be selected to establish BGP neighbor with external frr")
+		if established && !expected {
+			e2e.Logf("The node IP %s should not be selected to establish BGP neighbor with external frr", nodeIP)
 			return false
 		}
 	}
-
 	return true
 }
 
 func verifyBGPRoutesOnExternalFrr(host, frrContainerID string, allNodes []string, podNetwork1Map, podNetwork2Map, nodesIP1Map, nodesIP2Map map[string]string, expected bool) bool {
-	var externalFrrCmd string
-
-	if netutils.IsIPv6String(nodesIP1Map[allNodes[0]]) {
-		externalFrrCmd = "sudo podman exec -it " + frrContainerID + " vtysh -c \"show bgp ipv6\""
-	}
-
-	if netutils.IsIPv4String(nodesIP1Map[allNodes[0]]) {
-		externalFrrCmd = "sudo podman exec -it " + frrContainerID + " vtysh -c \"show ip bgp\""
-	}
-	output, err := sshRunCmdOutPut(host, "root", externalFrrCmd)
-	e2e.Logf("on singlestack, show ip/ipv6 bgp on external frr, output:\n%s ", output)
-	o.Expect(err).NotTo(o.HaveOccurred())
-	for _, eachNode := range allNodes {
-		expectedBGPRoutePattern := fmt.Sprintf(`%s\s+%s`, regexp.QuoteMeta(podNetwork1Map[eachNode]), regexp.QuoteMeta(nodesIP1Map[eachNode]))
-		matched, err := regexp.MatchString(expectedBGPRoutePattern, output)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		if !matched && expected {
-			e2e.Logf("BGP route is not advertised to external frr for node %s", eachNode)
-			return false
-		}
-		if matched && !expected {
-			e2e.Logf("BGP route should not be advertised to external frr for node %s", eachNode)
-			return false
+	peer := newExternalFrrContainerPeer(host, frrContainerID)
+	for _, networks := range []struct{ podNetworkMap, nodesIPMap map[string]string }{{podNetwork1Map, nodesIP1Map}, {podNetwork2Map, nodesIP2Map}} {
+		if networks.nodesIPMap[allNodes[0]] == "" {
+			continue
 		}
-	}
-
-	if nodesIP2Map[allNodes[0]] != "" {
-		externalFrrCmd = "sudo podman exec -it " + frrContainerID + " vtysh -c \"show bgp ipv6\""
-		output, err := sshRunCmdOutPut(host, "root", externalFrrCmd)
-		e2e.Logf("on Dualstack, show bgp ipv6 on external frr, output:\n%s ", output)
-		o.Expect(err).NotTo(o.HaveOccurred())
 		for _, eachNode := range allNodes {
-			expectedBGPRoutePattern := fmt.Sprintf(`%s\s+%s`, regexp.QuoteMeta(podNetwork2Map[eachNode]), regexp.QuoteMeta(nodesIP2Map[eachNode]))
-			matched, err := regexp.MatchString(expectedBGPRoutePattern, output)
+			found, err := peer.HasRoute("", networks.podNetworkMap[eachNode], networks.nodesIPMap[eachNode])
 			o.Expect(err).NotTo(o.HaveOccurred())
-			if !matched && expected {
-				e2e.Logf("IPv6 BGP route is not advertised to external frr for dualstack cluster node %s as expected", eachNode)
+			if !found && expected {
+				e2e.Logf("BGP route %s via %s is not advertised to external frr for node %s", networks.podNetworkMap[eachNode], networks.nodesIPMap[eachNode], eachNode)
 				return false
 			}
-			if matched && !expected {
-				e2e.Logf("IPv6 BGP route should not be advertised to external frr for dualstack cluster node %s", eachNode)
+			if found && !expected {
+				e2e.Logf("BGP route %s via %s should not be advertised to external frr for node %s", networks.podNetworkMap[eachNode], networks.nodesIPMap[eachNode], eachNode)
 				return false
 			}
 		}
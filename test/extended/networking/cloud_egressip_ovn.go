@@ -17,8 +17,9 @@ import (
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 	clusterinfra "github.com/openshift/openshift-tests-private/test/extended/util/clusterinfra"
+	compute "github.com/openshift/openshift-tests-private/test/extended/util/compute"
+	"github.com/openshift/openshift-tests-private/test/extended/util/ovndb"
 	rosacli "github.com/openshift/openshift-tests-private/test/extended/util/rosacli"
-	"github.com/vmware/govmomi"
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
@@ -1204,100 +1205,16 @@ var _ = g.Describe("[sig-networking] SDN OVN EgressIP", func() {
 		e2enode.RemoveLabelOffNode(oc.KubeFramework().ClientSet, nodeToBeShutdown, "tcpdump")
 
 		exutil.By("9. Stop one egress node.\n")
-		var instance []string
-		var zone string
-		var az *exutil.AzureSession
-		var rg string
-		var infraID string
-		var ospObj exutil.Osp
-		var vspObj *exutil.Vmware
-		var vspClient *govmomi.Client
-		var nutanixClient *exutil.NutanixClient
-		var ibmPowerVsSession *exutil.IBMPowerVsSession
-		var ibmPowerVsInstance *ibmPowerVsInstance
-		switch exutil.CheckPlatform(oc) {
-		case "aws":
-			e2e.Logf("\n AWS is detected \n")
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer startInstanceOnAWS(a, nodeToBeShutdown)
-			stopInstanceOnAWS(a, nodeToBeShutdown)
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		case "gcp":
-			// for gcp, remove the postfix "c.openshift-qe.internal" to get its instance name
-			instance = strings.Split(nodeToBeShutdown, ".")
-			e2e.Logf("\n\n\n the worker node to be shutdown is: %v\n\n\n", instance[0])
-			infraID, err = exutil.GetInfraID(oc)
-			zone, err = getZoneOfInstanceFromGcp(oc, infraID, instance[0])
-			o.Expect(err).NotTo(o.HaveOccurred())
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer startInstanceOnGcp(oc, instance[0], zone)
-			err = stopInstanceOnGcp(oc, instance[0], zone)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		case "azure":
-			e2e.Logf("\n Azure is detected \n")
-			err := getAzureCredentialFromCluster(oc)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			rg, err = getAzureResourceGroup(oc)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			az, err = exutil.NewAzureSessionFromEnv()
-			o.Expect(err).NotTo(o.HaveOccurred())
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer startVMOnAzure(az, nodeToBeShutdown, rg)
-			stopVMOnAzure(az, nodeToBeShutdown, rg)
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		case "openstack":
-			e2e.Logf("\n OpenStack is detected, stop the instance %v on OSP now \n", nodeToBeShutdown)
-			ospObj = exutil.Osp{}
-			cred, err1 := exutil.GetOpenStackCredentials(oc)
-			o.Expect(err1).NotTo(o.HaveOccurred())
-			client := exutil.NewOpenStackClient(cred, "compute")
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer ospObj.GetStartOspInstance(client, nodeToBeShutdown)
-			err = ospObj.GetStopOspInstance(client, nodeToBeShutdown)
-
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		case "vsphere":
-			e2e.Logf("\n vSphere is detected, stop the instance %v on vSphere now \n", nodeToBeShutdown)
-			vspObj, vspClient = VsphereCloudClient(oc)
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer vspObj.StartVsphereInstance(vspClient, nodeToBeShutdown)
-			err = vspObj.StopVsphereInstance(vspClient, nodeToBeShutdown)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		case "baremetal":
-			e2e.Logf("\n IPI baremetal is detected \n")
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer startVMOnIPIBM(oc, nodeToBeShutdown)
-			stopErr := stopVMOnIPIBM(oc, nodeToBeShutdown)
-			o.Expect(stopErr).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		case "nutanix":
-			e2e.Logf("\n Nutanix is detected, stop the instance %v on nutanix now \n", nodeToBeShutdown)
-			nutanixClient, err = exutil.InitNutanixClient(oc)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer startInstanceOnNutanix(nutanixClient, nodeToBeShutdown)
-			stopInstanceOnNutanix(nutanixClient, nodeToBeShutdown)
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		case "powervs":
-			e2e.Logf("\n Powervs is detected, stop the instance %v on powervs now \n", nodeToBeShutdown)
-			ibmApiKey, ibmRegion, ibmVpcName, credErr := exutil.GetIBMCredentialFromCluster(oc)
-			o.Expect(credErr).NotTo(o.HaveOccurred())
-			cloudID := exutil.GetIBMPowerVsCloudID(oc, nodeToBeShutdown)
-			ibmPowerVsSession, err = exutil.LoginIBMPowerVsCloud(ibmApiKey, ibmRegion, ibmVpcName, cloudID)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			ibmPowerVsInstance = newIBMPowerInstance(oc, ibmPowerVsSession, ibmRegion, ibmVpcName, nodeToBeShutdown)
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			defer ibmPowerVsInstance.Start()
-			err = ibmPowerVsInstance.Stop()
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
-		default:
-			e2e.Logf("Not support cloud provider for auto egressip cases for now.")
-			g.Skip("Not support cloud provider for auto egressip cases for now.")
+		nodes, cleanup := compute.GetNodes(oc, "worker")
+		if cleanup != nil {
+			defer cleanup()
 		}
+		node := nodes.ByName(nodeToBeShutdown)
+		o.Expect(node).NotTo(o.BeNil(), "the node %s was not found on the platform", nodeToBeShutdown)
+		defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
+		defer node.Start()
+		o.Expect(node.Stop()).NotTo(o.HaveOccurred())
+		checkNodeStatus(oc, nodeToBeShutdown, "NotReady")
 
 		exutil.By("10. Check EgressIP updated in EIP object, sourceIP contains 2 IPs. \n")
 		verifyExpectedEIPNumInEIPObject(oc, egressip1.name, 2)
@@ -1347,51 +1264,8 @@ var _ = g.Describe("[sig-networking] SDN OVN EgressIP", func() {
 		}
 
 		exutil.By("11. Start the stopped egress node \n")
-		switch exutil.CheckPlatform(oc) {
-		case "aws":
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			startInstanceOnAWS(a, nodeToBeShutdown)
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		case "gcp":
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			err = startInstanceOnGcp(oc, instance[0], zone)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		case "azure":
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			startVMOnAzure(az, nodeToBeShutdown, rg)
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		case "openstack":
-			cred, err1 := exutil.GetOpenStackCredentials(oc)
-			o.Expect(err1).NotTo(o.HaveOccurred())
-			client := exutil.NewOpenStackClient(cred, "compute")
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			err = ospObj.GetStartOspInstance(client, nodeToBeShutdown)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		case "vsphere":
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			err = vspObj.StartVsphereInstance(vspClient, nodeToBeShutdown)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		case "baremetal":
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			startErr := startVMOnIPIBM(oc, nodeToBeShutdown)
-			o.Expect(startErr).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		case "nutanix":
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			startInstanceOnNutanix(nutanixClient, nodeToBeShutdown)
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		case "powervs":
-			defer checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-			err = ibmPowerVsInstance.Start()
-			o.Expect(err).NotTo(o.HaveOccurred())
-			checkNodeStatus(oc, nodeToBeShutdown, "Ready")
-		default:
-			e2e.Logf("Not support cloud provider for auto egressip cases for now.")
-			g.Skip("Not support cloud provider for auto egressip cases for now.")
-		}
+		o.Expect(node.Start()).NotTo(o.HaveOccurred())
+		checkNodeStatus(oc, nodeToBeShutdown, "Ready")
 
 		exutil.By("12. Check source IP is randomly one of 3 egress IPs.\n")
 		verifyExpectedEIPNumInEIPObject(oc, egressip1.name, 3)
@@ -2044,11 +1918,12 @@ var _ = g.Describe("[sig-networking] SDN OVN EgressIP", func() {
 		o.Expect(snatIP[0]).To(o.ContainSubstring(helloPod1IP))
 
 		exutil.By("9. Check lr-policy-list in 100 table of northdb on the node where hello-pod1 resides on, there should be an entry that contains hello-pod1's pod IP. \n")
-		lrPolicyList, lrpErr := getlrPolicyList(oc, helloPod1Node, "100 ", true)
+		ovnDB, dbErr := ovndb.NewClientForNode(oc, helloPod1Node)
+		o.Expect(dbErr).NotTo(o.HaveOccurred())
+		lrPolicyList, lrpErr := ovnDB.WaitForLogicalRouterPolicies("ovn_cluster_router", 100, 1)
 		o.Expect(lrpErr).NotTo(o.HaveOccurred())
-		e2e.Logf("\n Before hello-pod1 is deleted, lrPolicyList found: %v\n", lrPolicyList)
-		o.Expect(len(lrPolicyList)).Should(o.Equal(1))
-		o.Expect(lrPolicyList[0]).To(o.ContainSubstring(helloPod1IP))
+		e2e.Logf("\n Before hello-pod1 is deleted, lrPolicyList found: %+v\n", lrPolicyList)
+		o.Expect(lrPolicyList[0].Match).To(o.ContainSubstring(helloPod1IP))
 
 		exutil.By("10 Check the sourceIP of the two test pods, hello-pod1 should use egressip, while hello-pod2 should uses its node IP")
 		var dstHost, primaryInf string
@@ -2097,9 +1972,18 @@ var _ = g.Describe("[sig-networking] SDN OVN EgressIP", func() {
 		}, "300s", "10s").Should(o.BeTrue(), "SNAT for the egressip is not deleted!!")
 
 		o.Eventually(func() bool {
-			lrPolicyList, _ = getlrPolicyList(oc, helloPod1Node, "100 ", false)
-			e2e.Logf("\n After hello-pod1 is deleted, lrPolicyList found: %v\n", lrPolicyList)
-			return !isValueInList(helloPod1IP, lrPolicyList)
+			lrPolicyList, lrpErr = ovnDB.LogicalRouterPoliciesWithPriority("ovn_cluster_router", 100)
+			if lrpErr != nil {
+				e2e.Logf("%v, try again ...", lrpErr)
+				return false
+			}
+			e2e.Logf("\n After hello-pod1 is deleted, lrPolicyList found: %+v\n", lrPolicyList)
+			for _, policy := range lrPolicyList {
+				if strings.Contains(policy.Match, helloPod1IP) {
+					return false
+				}
+			}
+			return true
 		}, "300s", "10s").Should(o.BeTrue(), "lr-policy-list for the egressip is not deleted!!")
 	})
 
@@ -6375,8 +6259,6 @@ var _ = g.Describe("[sig-networking] SDN OVN EgressIP rdu1", func() {
 			testDataMetallbDir = exutil.FixturePath("testdata", "networking/metallb")
 
 			mlNSTemplate            = filepath.Join(testDataMetallbDir, "namespace-template.yaml")
-			mlOperatorGroupTemplate = filepath.Join(testDataMetallbDir, "operatorgroup-template.yaml")
-			mlSubscriptionTemplate  = filepath.Join(testDataMetallbDir, "subscription-template.yaml")
 			mlNs                    = "metallb-system"
 			exteranlHost            = "10.8.1.181"
 			metalLBNodeSelKey       = "node-role.kubernetes.io/worker"
@@ -6408,7 +6290,6 @@ var _ = g.Describe("[sig-networking] SDN OVN EgressIP rdu1", func() {
 			channel:          "stable",
 			catalog:          "qe-app-registry",
 			catalogNamespace: "openshift-marketplace",
-			template:         mlSubscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     mlNs,
@@ -6418,7 +6299,6 @@ var _ = g.Describe("[sig-networking] SDN OVN EgressIP rdu1", func() {
 			name:             "metallb-operator",
 			namespace:        mlNs,
 			targetNamespaces: "metallb-system",
-			template:         mlOperatorGroupTemplate,
 		}
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
 		if catalogSource == "" {
@@ -6,9 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
-	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -26,8 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 	e2eoutput "k8s.io/kubernetes/test/e2e/framework/pod/output"
-
-	"github.com/vmware/govmomi"
 )
 
 type tcpdumpDaemonSet struct {
@@ -41,13 +37,6 @@ type tcpdumpDaemonSet struct {
 	template     string
 }
 
-type ibmPowerVsInstance struct {
-	instance
-	ibmRegion     string
-	ibmVpcName    string
-	clientPowerVs *exutil.IBMPowerVsSession
-}
-
 func (ds *tcpdumpDaemonSet) createTcpdumpDS(oc *exutil.CLI) error {
 	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
 		err1 := applyResourceFromTemplateByAdmin(oc, "--ignore-unknown-parameters=true", "-f", ds.template, "-p", "NAME="+ds.name, "NAMESPACE="+ds.namespace, "NODELABEL="+ds.nodeLabel, "LABELKEY="+ds.labelKey, "INF="+ds.phyInterface, "DSTPORT="+strconv.Itoa(ds.dstPort), "HOST="+ds.dstHost)
@@ -210,15 +199,24 @@ func findUnUsedIPsOnNode(oc *exutil.CLI, nodeName, cidr string, number int) []st
 	podName, err = exutil.GetPodName(oc, "openshift-ovn-kubernetes", "app=ovnkube-node", nodeName)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	ns = "openshift-ovn-kubernetes"
+	runCmd := func(cmd string) (string, error) { return exutil.RemoteShPodWithBash(oc, ns, podName, cmd) }
+	usedIPs := getIPsUsedByCluster(oc)
 
 	for _, ip := range ipRange {
 		if len(ipUnused) < number {
+			if usedIPs.Has(ip) || !reserveIP(oc, ip) {
+				continue
+			}
 			pingCmd := "ping -c4 -t1 " + ip
-			msg, err := exutil.RemoteShPodWithBash(oc, ns, podName, pingCmd)
-			if err != nil && (strings.Contains(msg, "Destination Host Unreachable") || strings.Contains(msg, "100% packet loss")) {
+			msg, err := runCmd(pingCmd)
+			unreachable := err != nil && (strings.Contains(msg, "Destination Host Unreachable") || strings.Contains(msg, "100% packet loss"))
+			if unreachable && !isIPInNeighborTable(ip, runCmd) {
 				e2e.Logf("%s is not used!\n", ip)
 				ipUnused = append(ipUnused, ip)
-			} else if err != nil {
+				continue
+			}
+			releaseIPs(oc, ip)
+			if err != nil && !unreachable {
 				break
 			}
 		} else {
@@ -383,22 +381,6 @@ func uninstallIPEchoServiceOnGCP(oc *exutil.CLI) {
 	}
 }
 
-func getZoneOfInstanceFromGcp(oc *exutil.CLI, infraID string, workerName string) (string, error) {
-	zone, err := getgcloudClient(oc).GetZone(infraID, workerName)
-	e2e.Logf("zone for instance %v is: %s", workerName, zone)
-	return zone, err
-}
-
-func startInstanceOnGcp(oc *exutil.CLI, nodeName string, zone string) error {
-	err := getgcloudClient(oc).StartInstance(nodeName, zone)
-	return err
-}
-
-func stopInstanceOnGcp(oc *exutil.CLI, nodeName string, zone string) error {
-	err := getgcloudClient(oc).StopInstance(nodeName, zone)
-	return err
-}
-
 // Run timeout ssh connection test from GCP int-svc instance
 func accessEgressNodeFromIntSvcInstanceOnGCP(host string, IPaddr string) (string, error) {
 	user := os.Getenv("SSH_CLOUD_PRIV_GCP_USER")
@@ -422,57 +404,6 @@ func accessEgressNodeFromIntSvcInstanceOnGCP(host string, IPaddr string) (string
 	return "0", nil
 }
 
-// start one AWS instance
-func startInstanceOnAWS(a *exutil.AwsClient, hostname string) {
-	instanceID, err := a.GetAwsInstanceIDFromHostname(hostname)
-	o.Expect(err).NotTo(o.HaveOccurred())
-	stateErr := wait.Poll(5*time.Second, 120*time.Second, func() (bool, error) {
-		state, err := a.GetAwsInstanceState(instanceID)
-		if err != nil {
-			e2e.Logf("%v", err)
-			return false, nil
-		}
-		if state == "running" {
-			e2e.Logf("The instance  is running")
-			return true, nil
-		}
-		if state == "stopped" {
-			err = a.StartInstance(instanceID)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			return true, nil
-		}
-		e2e.Logf("The instance  is in %v,not in a state from which it can be started.", state)
-		return false, nil
-
-	})
-	exutil.AssertWaitPollNoErr(stateErr, fmt.Sprintf("The instance  is not in a state from which it can be started."))
-}
-
-func stopInstanceOnAWS(a *exutil.AwsClient, hostname string) {
-	instanceID, err := a.GetAwsInstanceIDFromHostname(hostname)
-	o.Expect(err).NotTo(o.HaveOccurred())
-	stateErr := wait.Poll(5*time.Second, 120*time.Second, func() (bool, error) {
-		state, err := a.GetAwsInstanceState(instanceID)
-		if err != nil {
-			e2e.Logf("%v", err)
-			return false, nil
-		}
-		if state == "stopped" {
-			e2e.Logf("The instance  is already stopped.")
-			return true, nil
-		}
-		if state == "running" {
-			err = a.StopInstance(instanceID)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			return true, nil
-		}
-		e2e.Logf("The instance is in %v,not in a state from which it can be stopped.", state)
-		return false, nil
-
-	})
-	exutil.AssertWaitPollNoErr(stateErr, fmt.Sprintf("The instance  is not in a state from which it can be stopped."))
-}
-
 // Run timeout ssh connection test from AWS int-svc instance
 func accessEgressNodeFromIntSvcInstanceOnAWS(a *exutil.AwsClient, oc *exutil.CLI, IPaddr string) (string, error) {
 	user := os.Getenv("SSH_CLOUD_PRIV_AWS_USER")
@@ -1014,90 +945,6 @@ func OspCredentials(oc *exutil.CLI) {
 	}
 }
 
-// VsphereCloudClient pass env details to login function, and used to login
-func VsphereCloudClient(oc *exutil.CLI) (*exutil.Vmware, *govmomi.Client) {
-	credential, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("secret/vsphere-creds", "-n", "kube-system", "-o", `jsonpath={.data}`).Output()
-	o.Expect(err).NotTo(o.HaveOccurred())
-	output := gjson.Parse(credential).Value().(map[string]interface{})
-	var accessKeyIDBase64 string
-	var secureKeyBase64 string
-	for key, value := range output {
-		if strings.Contains(key, "username") {
-			accessKeyIDBase64 = fmt.Sprint(value)
-		} else if strings.Contains(key, "password") {
-			secureKeyBase64 = fmt.Sprint(value)
-		}
-	}
-	accessKeyID, err1 := base64.StdEncoding.DecodeString(accessKeyIDBase64)
-	o.Expect(err1).NotTo(o.HaveOccurred())
-	secureKey, err2 := base64.StdEncoding.DecodeString(secureKeyBase64)
-	o.Expect(err2).NotTo(o.HaveOccurred())
-	cloudConfig, err3 := oc.AsAdmin().WithoutNamespace().Run("get").Args("cm/cloud-provider-config", "-n", "openshift-config", "-o", `jsonpath={.data.config}`).OutputToFile("vsphere.ini")
-	o.Expect(err3).NotTo(o.HaveOccurred())
-	cmd := fmt.Sprintf(`grep -i server "%v" | awk -F '"' '{print $2}'`, cloudConfig)
-	serverURL, err4 := exec.Command("bash", "-c", cmd).Output()
-	e2e.Logf("\n serverURL: %s \n", string(serverURL))
-	o.Expect(err4).NotTo(o.HaveOccurred())
-	envUsername := string(accessKeyID)
-	envPassword := string(secureKey)
-	envURL := string(serverURL)
-	envURL = strings.TrimSuffix(envURL, "\n")
-	encodedPassword := url.QueryEscape(envPassword)
-	govmomiURL := fmt.Sprintf("https://%s:%s@%s/sdk", envUsername, encodedPassword, envURL)
-	vmware := exutil.Vmware{GovmomiURL: govmomiURL}
-	return vmware.Login()
-}
-
-// startVMOnAzure start one Azure VM
-func startVMOnAzure(az *exutil.AzureSession, nodeName, rg string) {
-	stateErr := wait.Poll(5*time.Second, 120*time.Second, func() (bool, error) {
-		vmState, stateErr := exutil.GetAzureVMInstanceState(az, nodeName, rg)
-		if stateErr != nil {
-			e2e.Logf("%v", stateErr)
-			return false, nil
-		}
-		if strings.EqualFold(vmState, "poweredOn") || strings.EqualFold(vmState, "running") || strings.EqualFold(vmState, "active") || strings.EqualFold(vmState, "ready") {
-			e2e.Logf("The instance  has been started with state:%s !", vmState)
-			return true, nil
-		}
-		if strings.EqualFold(vmState, "poweredOff") || strings.EqualFold(vmState, "stopped") || strings.EqualFold(vmState, "paused") || strings.EqualFold(vmState, "notready") {
-			e2e.Logf("Start instance %s\n", nodeName)
-			_, err := exutil.StartAzureVM(az, nodeName, rg)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			return true, nil
-		}
-		e2e.Logf("The instance  is in %v,not in a state from which it can be started.", vmState)
-		return false, nil
-
-	})
-	exutil.AssertWaitPollNoErr(stateErr, fmt.Sprintf("The instance %s is not in a state from which it can be started.", nodeName))
-}
-
-// stopVMOnAzure stop one Azure VM
-func stopVMOnAzure(az *exutil.AzureSession, nodeName, rg string) {
-	stateErr := wait.Poll(5*time.Second, 120*time.Second, func() (bool, error) {
-		vmState, stateErr := exutil.GetAzureVMInstanceState(az, nodeName, rg)
-		if stateErr != nil {
-			e2e.Logf("%v", stateErr)
-			return false, nil
-		}
-		if strings.EqualFold(vmState, "poweredoff") || strings.EqualFold(vmState, "stopped") || strings.EqualFold(vmState, "stopping") || strings.EqualFold(vmState, "paused") || strings.EqualFold(vmState, "pausing") || strings.EqualFold(vmState, "deallocated") || strings.EqualFold(vmState, "notready") {
-			e2e.Logf("The instance %s has been stopped already, and now is with state:%s !", nodeName, vmState)
-			return true, nil
-		}
-		if strings.EqualFold(vmState, "poweredOn") || strings.EqualFold(vmState, "running") || strings.EqualFold(vmState, "active") || strings.EqualFold(vmState, "ready") {
-			e2e.Logf("Stop instance %s\n", nodeName)
-			_, err := exutil.StopAzureVM(az, nodeName, rg)
-			o.Expect(err).NotTo(o.HaveOccurred())
-			return true, nil
-		}
-		e2e.Logf("The instance  is in %v,not in a state from which it can be stopped.", vmState)
-		return false, nil
-
-	})
-	exutil.AssertWaitPollNoErr(stateErr, fmt.Sprintf("The instance %s is not in a state from which it can be stopped.", nodeName))
-}
-
 func verifyEgressIPWithIPEcho(oc *exutil.CLI, podNS, podName, ipEchoURL string, hit bool, expectedIPs ...string) {
 	timeout := estimateTimeoutForEgressIP(oc)
 	if hit {
@@ -1168,46 +1015,6 @@ func GetBmhNodeMachineConfig(oc *exutil.CLI, nodeName string) (string, error) {
 	return bmh, bmhErr
 }
 
-// stopVMOnIpiBM stop one IPI BM VM
-func stopVMOnIPIBM(oc *exutil.CLI, nodeName string) error {
-	stopErr := wait.Poll(10*time.Second, 100*time.Second, func() (bool, error) {
-		vmInstance, err := GetBmhNodeMachineConfig(oc, nodeName)
-		if err != nil {
-			return false, nil
-		}
-		e2e.Logf("\n\n\n vmInstance for the node is: %v \n\n\n", vmInstance)
-
-		patch := `[{"op": "replace", "path": "/spec/online", "value": false}]`
-		patchErr := oc.AsAdmin().WithoutNamespace().Run("patch").Args("bmh", "-n", "openshift-machine-api", vmInstance, "--type=json", "-p", patch).Execute()
-		if patchErr != nil {
-			return false, nil
-		}
-		return true, nil
-	})
-	e2e.Logf("Not able to stop %s, got error: %v.", nodeName, stopErr)
-	return stopErr
-}
-
-// startVMOnIpiBM starts one IPI BM VM
-func startVMOnIPIBM(oc *exutil.CLI, nodeName string) error {
-	startErr := wait.Poll(10*time.Second, 100*time.Second, func() (bool, error) {
-		vmInstance, err := GetBmhNodeMachineConfig(oc, nodeName)
-		if err != nil {
-			return false, nil
-		}
-		e2e.Logf("\n\n\n vmInstance for the node is: %v \n\n\n", vmInstance)
-
-		patch := `[{"op": "replace", "path": "/spec/online", "value": true}]`
-		patchErr := oc.AsAdmin().WithoutNamespace().Run("patch").Args("bmh", "-n", "openshift-machine-api", vmInstance, "--type=json", "-p", patch).Execute()
-		if patchErr != nil {
-			return false, nil
-		}
-		return true, nil
-	})
-	e2e.Logf("Not able to start %s, got error: %v.", nodeName, startErr)
-	return startErr
-}
-
 func specialPlatformCheck(oc *exutil.CLI) bool {
 	platform := exutil.CheckPlatform(oc)
 	specialPlatform := false
@@ -1279,60 +1086,6 @@ func getClusterNetworkInfo(oc *exutil.CLI) (string, string) {
 	return networkCIDR, hostPrefix
 }
 
-// start one instance on Nutanix
-func startInstanceOnNutanix(nutanix *exutil.NutanixClient, hostname string) {
-	instanceID, err := nutanix.GetNutanixVMUUID(hostname)
-	o.Expect(err).NotTo(o.HaveOccurred())
-	e2e.Logf("The instance %s  UUID is :%s", hostname, instanceID)
-	stateErr := wait.Poll(5*time.Second, 120*time.Second, func() (bool, error) {
-		state, err := nutanix.GetNutanixVMState(instanceID)
-		if err != nil {
-			e2e.Logf("Failed to get instance state %s, Error: %v", hostname, err)
-			return false, nil
-		}
-		if state == "ON" {
-			e2e.Logf("The instance %s is already running", hostname)
-			return true, nil
-		}
-		if state == "OFF" {
-			err = nutanix.ChangeNutanixVMState(instanceID, "ON")
-			o.Expect(err).NotTo(o.HaveOccurred())
-			return true, nil
-		}
-		e2e.Logf("The instance  is in %v,not in a state from which it can be started.", state)
-		return false, nil
-
-	})
-	exutil.AssertWaitPollNoErr(stateErr, fmt.Sprintf("The instance is not in a state from which it can be started."))
-}
-
-// stop one instance on Nutanix
-func stopInstanceOnNutanix(nutanix *exutil.NutanixClient, hostname string) {
-	instanceID, err := nutanix.GetNutanixVMUUID(hostname)
-	o.Expect(err).NotTo(o.HaveOccurred())
-	e2e.Logf("The instance %s  UUID is :%s", hostname, instanceID)
-	stateErr := wait.Poll(5*time.Second, 120*time.Second, func() (bool, error) {
-		state, err := nutanix.GetNutanixVMState(instanceID)
-		if err != nil {
-			e2e.Logf("Failed to get instance state %s, Error: %v", hostname, err)
-			return false, nil
-		}
-		if state == "OFF" {
-			e2e.Logf("The instance  is already stopped.")
-			return true, nil
-		}
-		if state == "ON" {
-			err = nutanix.ChangeNutanixVMState(instanceID, "OFF")
-			o.Expect(err).NotTo(o.HaveOccurred())
-			return true, nil
-		}
-		e2e.Logf("The instance is in %v,not in a state from which it can be stopped.", state)
-		return false, nil
-
-	})
-	exutil.AssertWaitPollNoErr(stateErr, fmt.Sprintf("The instance is not in a state from which it can be stopped."))
-}
-
 func checkDisconnect(oc *exutil.CLI) bool {
 	workNode, err := exutil.GetFirstWorkerNode(oc)
 	o.Expect(err).ShouldNot(o.HaveOccurred())
@@ -1347,43 +1100,6 @@ func checkDisconnect(oc *exutil.CLI) bool {
 	return false
 }
 
-// get ibm powervs instance for an OCP node
-func newIBMPowerInstance(oc *exutil.CLI, clientPowerVs *exutil.IBMPowerVsSession, ibmRegion, ibmVpcName, nodeName string) *ibmPowerVsInstance {
-	return &ibmPowerVsInstance{
-		instance: instance{
-			nodeName: nodeName,
-			oc:       oc,
-		},
-		clientPowerVs: clientPowerVs,
-		ibmRegion:     ibmRegion,
-		ibmVpcName:    ibmVpcName,
-	}
-}
-
-// start the ibm powervs instancce
-func (ibmPws *ibmPowerVsInstance) Start() error {
-	instanceID, status, idErr := exutil.GetIBMPowerVsInstanceInfo(ibmPws.clientPowerVs, ibmPws.nodeName)
-	o.Expect(idErr).NotTo(o.HaveOccurred())
-	e2e.Logf("\n The ibmPowervs instance %s is currently in state: %s \n", ibmPws.nodeName, status)
-	if status == "active" {
-		e2e.Logf("The node is already in active state, no need to start it again\n")
-		return nil
-	}
-	return exutil.PerformInstanceActionOnPowerVs(ibmPws.clientPowerVs, instanceID, "start")
-}
-
-// stop the ibm powervs instance
-func (ibmPws *ibmPowerVsInstance) Stop() error {
-	instanceID, status, idErr := exutil.GetIBMPowerVsInstanceInfo(ibmPws.clientPowerVs, ibmPws.nodeName)
-	o.Expect(idErr).NotTo(o.HaveOccurred())
-	e2e.Logf("\n The ibmPowervs instance %s is currently in state: %s \n", ibmPws.nodeName, status)
-	if status == "shutoff" {
-		e2e.Logf("The node is already in shutoff state, no need to stop it again\n")
-		return nil
-	}
-	return exutil.PerformInstanceActionOnPowerVs(ibmPws.clientPowerVs, instanceID, "stop")
-}
-
 func cfgRouteOnExternalHost(oc *exutil.CLI, host string, user string, pod string, ns string, externalIntf string) bool {
 	nodeName, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("pod", "-n", ns, pod, "-o=jsonpath={.spec.nodeName}").Output()
 	o.Expect(err).NotTo(o.HaveOccurred())
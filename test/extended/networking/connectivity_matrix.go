@@ -0,0 +1,408 @@
+package networking
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	o "github.com/onsi/gomega"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+	e2eoutput "k8s.io/kubernetes/test/e2e/framework/pod/output"
+	netutils "k8s.io/utils/net"
+)
+
+// connectivitySourceKind is the kind of endpoint that runs the probes of a connectivity matrix
+type connectivitySourceKind string
+
+const (
+	podSource            connectivitySourceKind = "pod"
+	hostNetworkPodSource connectivitySourceKind = "hostnetwork-pod"
+	nodeSource           connectivitySourceKind = "node"
+	externalHostSource   connectivitySourceKind = "external"
+)
+
+const (
+	defaultProbeTimeout   = 5
+	defaultProbeParallel  = 8
+	defaultMatrixInterval = 10 * time.Second
+	defaultMatrixTimeout  = 2 * time.Minute
+)
+
+// connectivityTargetKind is the kind of address probed by a connectivity matrix
+type connectivityTargetKind string
+
+const (
+	podIPTarget        connectivityTargetKind = "podIP"
+	clusterIPTarget    connectivityTargetKind = "clusterIP"
+	nodePortTarget     connectivityTargetKind = "nodePort"
+	loadBalancerTarget connectivityTargetKind = "loadBalancer"
+	egressTarget       connectivityTargetKind = "egress"
+	nodeIPTarget       connectivityTargetKind = "nodeIP"
+)
+
+// connectivitySource is a row in the connectivity matrix
+type connectivitySource struct {
+	name      string
+	kind      connectivitySourceKind
+	namespace string
+	podName   string
+	nodeName  string
+	host      string
+	user      string
+}
+
+// connectivityTarget is a column in the connectivity matrix
+type connectivityTarget struct {
+	name     string
+	group    string
+	kind     connectivityTargetKind
+	ip       string
+	port     string
+	protocol string
+}
+
+// address returns the target in host:port format, or just the IP for icmp targets
+func (t connectivityTarget) address() string {
+	if t.protocol == "icmp" || t.port == "" {
+		return t.ip
+	}
+	return net.JoinHostPort(t.ip, t.port)
+}
+
+func (t connectivityTarget) isIPv6() bool {
+	return netutils.IsIPv6String(t.ip)
+}
+
+// connectivityMatrix declares the expected reachability between a set of sources and a set of targets.
+// All the cells are probed concurrently and, on failure, an expected-vs-actual grid is reported.
+type connectivityMatrix struct {
+	sources      []connectivitySource
+	targets      []connectivityTarget
+	expected     map[string]map[string]bool
+	defaultAllow *bool
+	parallel     int
+	probeTimeout int
+}
+
+// connectivityResult contains the result of probing all the cells in a matrix
+type connectivityResult struct {
+	matrix *connectivityMatrix
+	actual map[string]map[string]bool
+	output map[string]map[string]string
+}
+
+func newConnectivityMatrix() *connectivityMatrix {
+	return &connectivityMatrix{
+		expected:     map[string]map[string]bool{},
+		parallel:     defaultProbeParallel,
+		probeTimeout: defaultProbeTimeout,
+	}
+}
+
+// newPodSource returns a source that runs the probes inside a pod using its own network namespace
+func newPodSource(namespace, podName string) connectivitySource {
+	return connectivitySource{name: namespace + "/" + podName, kind: podSource, namespace: namespace, podName: podName}
+}
+
+// newHostNetworkPodSource returns a source that runs the probes inside a pod with hostNetwork: true
+func newHostNetworkPodSource(namespace, podName string) connectivitySource {
+	return connectivitySource{name: namespace + "/" + podName + "(host)", kind: hostNetworkPodSource, namespace: namespace, podName: podName}
+}
+
+// newNodeSource returns a source that runs the probes in a node using a debug pod
+func newNodeSource(nodeName string) connectivitySource {
+	return connectivitySource{name: "node/" + nodeName, kind: nodeSource, nodeName: nodeName}
+}
+
+// newExternalHostSource returns a source that runs the probes in a host outside the cluster using ssh
+func newExternalHostSource(host, user string) connectivitySource {
+	return connectivitySource{name: "external/" + host, kind: externalHostSource, host: host, user: user}
+}
+
+// newPodTargets returns one target for every IP of the pod, so that dual stack clusters check both IPv4 and IPv6
+func newPodTargets(oc *exutil.CLI, namespace, podName, port string) []connectivityTarget {
+	ip1, ip2 := getPodIP(oc, namespace, podName)
+	return newIPTargets(namespace+"/"+podName, podIPTarget, port, ip1, ip2)
+}
+
+// newClusterIPTargets returns one target for every cluster IP of the service
+func newClusterIPTargets(oc *exutil.CLI, namespace, svcName, port string) []connectivityTarget {
+	ip1, ip2 := getSvcIP(oc, namespace, svcName)
+	return newIPTargets("svc/"+namespace+"/"+svcName, clusterIPTarget, port, ip1, ip2)
+}
+
+// newNodePortTargets returns one target for every internal IP of the node using the given node port
+func newNodePortTargets(oc *exutil.CLI, nodeName, nodePort string) []connectivityTarget {
+	ip1, ip2 := getNodeIP(oc, nodeName)
+	return newIPTargets("nodeport/"+nodeName, nodePortTarget, nodePort, ip1, ip2)
+}
+
+// newNodeIPTargets returns one target for every internal IP of the node, for services listening in the host network
+func newNodeIPTargets(oc *exutil.CLI, nodeName, port string) []connectivityTarget {
+	ip1, ip2 := getNodeIP(oc, nodeName)
+	return newIPTargets("node/"+nodeName, nodeIPTarget, port, ip1, ip2)
+}
+
+// newLoadBalancerTarget returns a target for the ingress IP of a LoadBalancer service
+func newLoadBalancerTarget(oc *exutil.CLI, namespace, svcName, port string) connectivityTarget {
+	ip := getLBSVCIP(oc, namespace, svcName)
+	name := "lb/" + namespace + "/" + svcName
+	return connectivityTarget{name: name, group: name, kind: loadBalancerTarget, ip: ip, port: port, protocol: "tcp"}
+}
+
+// newEgressTarget returns a target outside the cluster, used to check egress rules like EgressFirewall or egress ANP rules
+func newEgressTarget(name, ip, port string) connectivityTarget {
+	return connectivityTarget{name: "egress/" + name, group: "egress/" + name, kind: egressTarget, ip: ip, port: port, protocol: "tcp"}
+}
+
+// newIPTargets creates a target for every non empty IP
+func newIPTargets(name string, kind connectivityTargetKind, port string, ips ...string) []connectivityTarget {
+	targets := []connectivityTarget{}
+	for _, ip := range ips {
+		if ip == "" {
+			continue
+		}
+		family := "v4"
+		if netutils.IsIPv6String(ip) {
+			family = "v6"
+		}
+		targets = append(targets, connectivityTarget{name: name + "[" + family + "]", group: name, kind: kind, ip: ip, port: port, protocol: "tcp"})
+	}
+	return targets
+}
+
+// asICMP returns a copy of the targets that will be probed with ping instead of curl
+func asICMP(targets ...connectivityTarget) []connectivityTarget {
+	icmpTargets := []connectivityTarget{}
+	for _, target := range targets {
+		target.protocol = "icmp"
+		target.name = target.name + "(icmp)"
+		target.group = target.group + "(icmp)"
+		icmpTargets = append(icmpTargets, target)
+	}
+	return icmpTargets
+}
+
+func (m *connectivityMatrix) addSources(sources ...connectivitySource) *connectivityMatrix {
+	m.sources = append(m.sources, sources...)
+	return m
+}
+
+func (m *connectivityMatrix) addTargets(targets ...connectivityTarget) *connectivityMatrix {
+	m.targets = append(m.targets, targets...)
+	return m
+}
+
+// setParallel sets how many probes can be executed at the same time
+func (m *connectivityMatrix) setParallel(parallel int) *connectivityMatrix {
+	m.parallel = parallel
+	return m
+}
+
+// expectAll sets the expectation for every cell that has no explicit expectation
+func (m *connectivityMatrix) expectAll(allowed bool) *connectivityMatrix {
+	m.defaultAllow = &allowed
+	return m
+}
+
+// expect sets the expectation for the target when probed from the given source.
+// The target can be the full target name like "ns1/pod1[v6]" or the group name "ns1/pod1" that matches both the IPv4 and the IPv6 targets of the pod.
+func (m *connectivityMatrix) expect(source connectivitySource, targetName string, allowed bool) *connectivityMatrix {
+	found := false
+	for _, target := range m.targets {
+		if target.name == targetName || target.group == targetName {
+			m.setExpected(source.name, target.name, allowed)
+			found = true
+		}
+	}
+	o.Expect(found).To(o.BeTrue(), "No target in the connectivity matrix matches %s", targetName)
+	return m
+}
+
+// expectFrom sets the expectation for all the targets when probed from the given source
+func (m *connectivityMatrix) expectFrom(source connectivitySource, allowed bool) *connectivityMatrix {
+	for _, target := range m.targets {
+		m.setExpected(source.name, target.name, allowed)
+	}
+	return m
+}
+
+// expectTo sets the expectation for the target when probed from any source
+func (m *connectivityMatrix) expectTo(targetName string, allowed bool) *connectivityMatrix {
+	for _, source := range m.sources {
+		m.expect(source, targetName, allowed)
+	}
+	return m
+}
+
+func (m *connectivityMatrix) setExpected(source, target string, allowed bool) {
+	if _, ok := m.expected[source]; !ok {
+		m.expected[source] = map[string]bool{}
+	}
+	m.expected[source][target] = allowed
+}
+
+// expectation returns the expected result for a cell and false if the cell has no expectation and must not be probed
+func (m *connectivityMatrix) expectation(source connectivitySource, target connectivityTarget) (bool, bool) {
+	if allowed, ok := m.expected[source.name][target.name]; ok {
+		return allowed, true
+	}
+	if m.defaultAllow != nil {
+		return *m.defaultAllow, true
+	}
+	return false, false
+}
+
+// probe runs all the probes with an expectation concurrently and returns the actual reachability
+func (m *connectivityMatrix) probe(oc *exutil.CLI) *connectivityResult {
+	result := &connectivityResult{
+		matrix: m,
+		actual: map[string]map[string]bool{},
+		output: map[string]map[string]string{},
+	}
+	for _, source := range m.sources {
+		result.actual[source.name] = map[string]bool{}
+		result.output[source.name] = map[string]string{}
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		semaphore = make(chan struct{}, m.parallel)
+	)
+	for _, source := range m.sources {
+		for _, target := range m.targets {
+			if _, ok := m.expectation(source, target); !ok {
+				continue
+			}
+			wg.Add(1)
+			go func(source connectivitySource, target connectivityTarget) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+
+				output, err := m.runProbe(oc, source, target)
+				mu.Lock()
+				defer mu.Unlock()
+				result.actual[source.name][target.name] = err == nil
+				result.output[source.name][target.name] = output
+			}(source, target)
+		}
+	}
+	wg.Wait()
+	return result
+}
+
+// runProbe executes a single curl or ping from the source to the target
+func (m *connectivityMatrix) runProbe(oc *exutil.CLI, source connectivitySource, target connectivityTarget) (string, error) {
+	var cmd []string
+	if target.protocol == "icmp" {
+		pingCmd := "ping"
+		if target.isIPv6() {
+			pingCmd = "ping6"
+		}
+		cmd = []string{pingCmd, "-c", "2", "-W", fmt.Sprintf("%d", m.probeTimeout), target.ip}
+	} else {
+		cmd = []string{"curl", "-s", "-o", "/dev/null", "--connect-timeout", fmt.Sprintf("%d", m.probeTimeout), target.address()}
+	}
+
+	switch source.kind {
+	case podSource, hostNetworkPodSource:
+		return e2eoutput.RunHostCmd(source.namespace, source.podName, strings.Join(cmd, " "))
+	case nodeSource:
+		return exutil.DebugNode(oc, source.nodeName, cmd...)
+	case externalHostSource:
+		err := sshRunCmd(source.host, source.user, strings.Join(cmd, " "))
+		return "", err
+	}
+	return "", fmt.Errorf("unknown connectivity source kind %s", source.kind)
+}
+
+// mismatches returns the number of cells whose actual result is not the expected one
+func (r *connectivityResult) mismatches() int {
+	count := 0
+	for _, source := range r.matrix.sources {
+		for _, target := range r.matrix.targets {
+			expected, ok := r.matrix.expectation(source, target)
+			if ok && r.actual[source.name][target.name] != expected {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// render returns the matrix as a grid where every cell shows the actual result and, if it does not match, the expected one
+func (r *connectivityResult) render() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	header := []string{"SOURCE \\ TARGET"}
+	for _, target := range r.matrix.targets {
+		header = append(header, target.name)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, source := range r.matrix.sources {
+		row := []string{source.name}
+		for _, target := range r.matrix.targets {
+			expected, ok := r.matrix.expectation(source, target)
+			if !ok {
+				row = append(row, "-")
+				continue
+			}
+			actual := r.actual[source.name][target.name]
+			cell := allowDenyString(actual)
+			if actual != expected {
+				cell = fmt.Sprintf("%s(expected %s)!", cell, allowDenyString(expected))
+			}
+			row = append(row, cell)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+func allowDenyString(allowed bool) string {
+	if allowed {
+		return "allow"
+	}
+	return "deny"
+}
+
+// verify probes the matrix until every cell has the expected result or the timeout expires.
+// Policies take some time to be applied, so the matrix is probed again while there are mismatches.
+func (m *connectivityMatrix) verify(oc *exutil.CLI) {
+	m.verifyWithTimeout(oc, defaultMatrixInterval, defaultMatrixTimeout)
+}
+
+func (m *connectivityMatrix) verifyWithTimeout(oc *exutil.CLI, interval, timeout time.Duration) {
+	var result *connectivityResult
+	err := wait.PollImmediate(interval, timeout, func() (bool, error) {
+		result = m.probe(oc)
+		if mismatches := result.mismatches(); mismatches != 0 {
+			e2e.Logf("Connectivity matrix has %d unexpected results. Trying again...\n%s", mismatches, result.render())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		e2e.Logf("Output of the failed probes:")
+		for _, source := range m.sources {
+			for _, target := range m.targets {
+				expected, ok := m.expectation(source, target)
+				if ok && result.actual[source.name][target.name] != expected {
+					e2e.Logf("%s -> %s: %s", source.name, target.address(), result.output[source.name][target.name])
+				}
+			}
+		}
+	}
+	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("Connectivity matrix does not match the expected results:\n%s", result.render()))
+	e2e.Logf("Connectivity matrix verified:\n%s", result.render())
+}
@@ -40,14 +40,11 @@ var _ = g.Describe("[sig-networking] SDN infw", func() {
 
 		//leveraging few templates and utils from metallb code
 		namespaceTemplate := filepath.Join(testDataDirMetallb, "namespace-template.yaml")
-		operatorGroupTemplate := filepath.Join(testDataDirMetallb, "operatorgroup-template.yaml")
-		subscriptionTemplate := filepath.Join(testDataDirMetallb, "subscription-template.yaml")
 		sub := subscriptionResource{
 			name:         "ingress-node-firewall-sub",
 			namespace:    opNamespace,
 			operatorName: opName,
 			catalog:      "qe-app-registry",
-			template:     subscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     opNamespace,
@@ -57,7 +54,6 @@ var _ = g.Describe("[sig-networking] SDN infw", func() {
 			name:             opName,
 			namespace:        opNamespace,
 			targetNamespaces: opNamespace,
-			template:         operatorGroupTemplate,
 		}
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
 		if catalogSource == "" {
@@ -0,0 +1,164 @@
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	g "github.com/onsi/ginkgo/v2"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// The IPs picked by findUnUsedIPs and friends are reserved in a ConfigMap so tests running in parallel do not use the same "free" IPs.
+// Updates rely on the resourceVersion of the ConfigMap, a conflicting update is retried with the latest content.
+const (
+	ipReservationNamespace = "default"
+	ipReservationConfigMap = "openshift-tests-ip-reservations"
+	// reservations older than this are considered leaked by a killed test run and can be taken over
+	ipReservationTTL = 4 * time.Hour
+)
+
+// ipReservation is the value stored in the ConfigMap for every reserved IP
+type ipReservation struct {
+	Owner    string    `json:"owner"`
+	Reserved time.Time `json:"reserved"`
+}
+
+// ipReservationKey returns the ConfigMap key for the IP. Keys cannot contain ":", so IPv6 addresses are stored with "_".
+func ipReservationKey(ip string) string {
+	return strings.ReplaceAll(ip, ":", "_")
+}
+
+// ipReservationOwner identifies the test reserving the IPs
+func ipReservationOwner() string {
+	return g.CurrentSpecReport().FullText()
+}
+
+// reserveIP reserves the IP for the current test. It returns false if the IP is already reserved by another test.
+// The reservation is released automatically when the test finishes.
+func reserveIP(oc *exutil.CLI, ip string) bool {
+	owner := ipReservationOwner()
+	key := ipReservationKey(ip)
+	reserved := false
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		reserved = false
+		cm, err := getOrCreateIPReservationConfigMap(oc)
+		if err != nil {
+			return err
+		}
+		if value, ok := cm.Data[key]; ok {
+			current := ipReservation{}
+			if err := json.Unmarshal([]byte(value), &current); err == nil && current.Owner != owner && time.Since(current.Reserved) < ipReservationTTL {
+				e2e.Logf("IP %s is reserved by %q since %v", ip, current.Owner, current.Reserved)
+				return nil
+			}
+		}
+		value, err := json.Marshal(ipReservation{Owner: owner, Reserved: time.Now()})
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[key] = string(value)
+		_, err = oc.AdminKubeClient().CoreV1().ConfigMaps(ipReservationNamespace).Update(context.Background(), cm, metav1.UpdateOptions{})
+		if err == nil {
+			reserved = true
+		}
+		return err
+	})
+	if err != nil {
+		e2e.Logf("Cannot reserve IP %s: %v", ip, err)
+		return false
+	}
+	if reserved {
+		e2e.Logf("IP %s reserved for the test", ip)
+		g.DeferCleanup(releaseIPs, oc, ip)
+	}
+	return reserved
+}
+
+// releaseIPs removes the reservations of the current test for the given IPs
+func releaseIPs(oc *exutil.CLI, ips ...string) {
+	owner := ipReservationOwner()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm, err := oc.AdminKubeClient().CoreV1().ConfigMaps(ipReservationNamespace).Get(context.Background(), ipReservationConfigMap, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		changed := false
+		for _, ip := range ips {
+			key := ipReservationKey(ip)
+			current := ipReservation{}
+			if value, ok := cm.Data[key]; ok && json.Unmarshal([]byte(value), &current) == nil && current.Owner == owner {
+				delete(cm.Data, key)
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		_, err = oc.AdminKubeClient().CoreV1().ConfigMaps(ipReservationNamespace).Update(context.Background(), cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		e2e.Logf("Cannot release IPs %v: %v", ips, err)
+		return
+	}
+	e2e.Logf("IPs %v released", ips)
+}
+
+func getOrCreateIPReservationConfigMap(oc *exutil.CLI) (*corev1.ConfigMap, error) {
+	client := oc.AdminKubeClient().CoreV1().ConfigMaps(ipReservationNamespace)
+	cm, err := client.Get(context.Background(), ipReservationConfigMap, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		return cm, err
+	}
+	cm, err = client.Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ipReservationConfigMap, Namespace: ipReservationNamespace},
+	}, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// another test created it at the same time, retry with its content
+		return nil, apierrors.NewConflict(corev1.Resource("configmaps"), ipReservationConfigMap, err)
+	}
+	return cm, err
+}
+
+// getIPsUsedByCluster returns the IPs assigned to EgressIPs and to the external IPs and load balancers of the services.
+// These IPs can be unreachable while the objects are being reconciled, so the ping check alone is not enough.
+func getIPsUsedByCluster(oc *exutil.CLI) sets.Set[string] {
+	used := sets.New[string]()
+	queries := [][]string{
+		{"egressip", "-o=jsonpath={.items[*].spec.egressIPs[*]} {.items[*].status.items[*].egressIP}"},
+		{"service", "-A", "-o=jsonpath={.items[*].spec.externalIPs[*]} {.items[*].status.loadBalancer.ingress[*].ip}"},
+	}
+	for _, args := range queries {
+		output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(args...).Output()
+		if err != nil {
+			e2e.Logf("Cannot get the IPs used by %s: %v", args[0], err)
+			continue
+		}
+		used.Insert(strings.Fields(output)...)
+	}
+	return used
+}
+
+// isIPInNeighborTable checks the ARP/NDP entry of the IP. Hosts dropping ICMP still answer ARP and NDP, so a resolved
+// entry after a failed ping means the IP is used. runCmd must run the command in the pod used for the ping.
+func isIPInNeighborTable(ip string, runCmd func(cmd string) (string, error)) bool {
+	output, err := runCmd("ip neigh show to " + ip)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(output, "lladdr") && !strings.Contains(output, "FAILED") && !strings.Contains(output, "INCOMPLETE")
+}
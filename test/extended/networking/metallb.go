@@ -38,8 +38,6 @@ var _ = g.Describe("[sig-networking] SDN metallb", func() {
 		}
 
 		namespaceTemplate := filepath.Join(testDataDir, "namespace-template.yaml")
-		operatorGroupTemplate := filepath.Join(testDataDir, "operatorgroup-template.yaml")
-		subscriptionTemplate := filepath.Join(testDataDir, "subscription-template.yaml")
 		sub := subscriptionResource{
 			name:             "metallb-operator-sub",
 			namespace:        opNamespace,
@@ -47,7 +45,6 @@ var _ = g.Describe("[sig-networking] SDN metallb", func() {
 			channel:          "stable",
 			catalog:          "qe-app-registry",
 			catalogNamespace: "openshift-marketplace",
-			template:         subscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     opNamespace,
@@ -57,7 +54,6 @@ var _ = g.Describe("[sig-networking] SDN metallb", func() {
 			name:             opName,
 			namespace:        opNamespace,
 			targetNamespaces: opNamespace,
-			template:         operatorGroupTemplate,
 		}
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
 		if catalogSource == "" {
@@ -311,8 +307,6 @@ var _ = g.Describe("[sig-networking] SDN metallb install", func() {
 		}
 
 		namespaceTemplate := filepath.Join(testDataDir, "namespace-template.yaml")
-		operatorGroupTemplate := filepath.Join(testDataDir, "operatorgroup-template.yaml")
-		subscriptionTemplate := filepath.Join(testDataDir, "subscription-template.yaml")
 		sub := subscriptionResource{
 			name:             "metallb-operator-sub",
 			namespace:        opNamespace,
@@ -320,7 +314,6 @@ var _ = g.Describe("[sig-networking] SDN metallb install", func() {
 			channel:          "stable",
 			catalog:          "qe-app-registry",
 			catalogNamespace: "openshift-marketplace",
-			template:         subscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     opNamespace,
@@ -330,7 +323,6 @@ var _ = g.Describe("[sig-networking] SDN metallb install", func() {
 			name:             opName,
 			namespace:        opNamespace,
 			targetNamespaces: opNamespace,
-			template:         operatorGroupTemplate,
 		}
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
 		if catalogSource == "" {
@@ -630,8 +622,6 @@ var _ = g.Describe("[sig-networking] SDN metallb l2", func() {
 			g.Skip("These cases can only be run on networking team's private RDU cluster , skip for other platforms or non-OVN network plugin!!!")
 		}
 		namespaceTemplate := filepath.Join(testDataDir, "namespace-template.yaml")
-		operatorGroupTemplate := filepath.Join(testDataDir, "operatorgroup-template.yaml")
-		subscriptionTemplate := filepath.Join(testDataDir, "subscription-template.yaml")
 		sub := subscriptionResource{
 			name:             "metallb-operator-sub",
 			namespace:        opNamespace,
@@ -639,7 +629,6 @@ var _ = g.Describe("[sig-networking] SDN metallb l2", func() {
 			channel:          "stable",
 			catalog:          "qe-app-registry",
 			catalogNamespace: "openshift-marketplace",
-			template:         subscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     opNamespace,
@@ -649,7 +638,6 @@ var _ = g.Describe("[sig-networking] SDN metallb l2", func() {
 			name:             opName,
 			namespace:        opNamespace,
 			targetNamespaces: opNamespace,
-			template:         operatorGroupTemplate,
 		}
 		exutil.By("Check the catalog source")
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
@@ -2272,8 +2260,6 @@ var _ = g.Describe("[sig-networking] SDN metallb l3", func() {
 		}
 
 		namespaceTemplate := filepath.Join(testDataDir, "namespace-template.yaml")
-		operatorGroupTemplate := filepath.Join(testDataDir, "operatorgroup-template.yaml")
-		subscriptionTemplate := filepath.Join(testDataDir, "subscription-template.yaml")
 		sub := subscriptionResource{
 			name:             "metallb-operator-sub",
 			namespace:        opNamespace,
@@ -2281,7 +2267,6 @@ var _ = g.Describe("[sig-networking] SDN metallb l3", func() {
 			channel:          "stable",
 			catalog:          "qe-app-registry",
 			catalogNamespace: "openshift-marketplace",
-			template:         subscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     opNamespace,
@@ -2291,7 +2276,6 @@ var _ = g.Describe("[sig-networking] SDN metallb l3", func() {
 			name:             opName,
 			namespace:        opNamespace,
 			targetNamespaces: opNamespace,
-			template:         operatorGroupTemplate,
 		}
 		exutil.By("Check the catalog source")
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
@@ -3588,8 +3572,6 @@ var _ = g.Describe("[sig-networking] SDN udn metallb", func() {
 			g.Skip("These cases can only be run on networking team's private RDU cluster, skipping for other platforms or non-OVN network plugin!!!")
 		}
 		namespaceTemplate := filepath.Join(testDataDir, "namespace-template.yaml")
-		operatorGroupTemplate := filepath.Join(testDataDir, "operatorgroup-template.yaml")
-		subscriptionTemplate := filepath.Join(testDataDir, "subscription-template.yaml")
 		sub := subscriptionResource{
 			name:             "metallb-operator-sub",
 			namespace:        opNamespace,
@@ -3597,7 +3579,6 @@ var _ = g.Describe("[sig-networking] SDN udn metallb", func() {
 			channel:          "stable",
 			catalog:          "qe-app-registry",
 			catalogNamespace: "openshift-marketplace",
-			template:         subscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     opNamespace,
@@ -3607,7 +3588,6 @@ var _ = g.Describe("[sig-networking] SDN udn metallb", func() {
 			name:             opName,
 			namespace:        opNamespace,
 			targetNamespaces: opNamespace,
-			template:         operatorGroupTemplate,
 		}
 		exutil.By("Check the catalog source")
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
@@ -14,6 +14,7 @@ import (
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/olm"
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
@@ -26,7 +27,6 @@ type subscriptionResource struct {
 	channel          string
 	catalog          string
 	catalogNamespace string
-	template         string
 }
 type namespaceResource struct {
 	name     string
@@ -36,7 +36,6 @@ type operatorGroupResource struct {
 	name             string
 	namespace        string
 	targetNamespaces string
-	template         string
 }
 
 type metalLBCRResource struct {
@@ -196,63 +195,28 @@ var (
 	bgpRouterNADName                     = "external1"
 )
 
+// operatorInstall creates the namespace from its template and installs the operator with the OLM library
 func operatorInstall(oc *exutil.CLI, sub subscriptionResource, ns namespaceResource, og operatorGroupResource) (status bool) {
-	//Installing Operator
 	g.By(" (1) INSTALLING Operator in the namespace")
-
-	//Applying the config of necessary yaml files from templates to create metallb operator
 	g.By("(1.1) Applying namespace template")
-	err0 := applyResourceFromTemplateByAdmin(oc, "--ignore-unknown-parameters=true", "-f", ns.template, "-p", "NAME="+ns.name)
-	if err0 != nil {
-		e2e.Logf("Error creating namespace %v", err0)
-	}
-
-	g.By("(1.2)  Applying operatorgroup yaml")
-	err0 = applyResourceFromTemplateByAdmin(oc, "--ignore-unknown-parameters=true", "-f", og.template, "-p", "NAME="+og.name, "NAMESPACE="+og.namespace, "TARGETNAMESPACES="+og.targetNamespaces)
-	if err0 != nil {
-		e2e.Logf("Error creating operator group %v", err0)
-	}
-
-	g.By("(1.3) Creating subscription yaml from template")
-	// no need to check for an existing subscription
-	err0 = applyResourceFromTemplateByAdmin(oc, "--ignore-unknown-parameters=true", "-f", sub.template, "-p", "OPERATORNAME="+sub.operatorName, "SUBSCRIPTIONNAME="+sub.name, "NAMESPACE="+sub.namespace, "CHANNEL="+sub.channel,
-		"CATALOGSOURCE="+sub.catalog, "CATALOGSOURCENAMESPACE="+sub.catalogNamespace)
-	if err0 != nil {
-		e2e.Logf("Error creating subscription %v", err0)
-	}
-
-	//confirming operator install
-	g.By("(1.4) Verify the operator finished subscribing")
-	errCheck := wait.Poll(10*time.Second, snooze*time.Second, func() (bool, error) {
-		subState, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("sub", sub.name, "-n", sub.namespace, "-o=jsonpath={.status.state}").Output()
-		o.Expect(err).NotTo(o.HaveOccurred())
-		if strings.Compare(subState, "AtLatestKnown") == 0 {
-			return true, nil
-		}
-		// log full status of sub for installation failure debugging
-		subState, _ = oc.AsAdmin().WithoutNamespace().Run("get").Args("sub", sub.name, "-n", sub.namespace, "-o=jsonpath={.status}").Output()
-		e2e.Logf("Status of subscription: %v", subState)
-		return false, nil
-	})
-	exutil.AssertWaitPollNoErr(errCheck, fmt.Sprintf("Subscription %s in namespace %v does not have expected status", sub.name, sub.namespace))
-
-	g.By("(1.5) Get csvName")
-	csvName, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("sub", sub.name, "-n", sub.namespace, "-o=jsonpath={.status.installedCSV}").Output()
-	o.Expect(err).NotTo(o.HaveOccurred())
-	o.Expect(csvName).NotTo(o.BeEmpty())
-	errCheck = wait.Poll(10*time.Second, snooze*time.Second, func() (bool, error) {
-		csvState, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("csv", csvName, "-n", sub.namespace, "-o=jsonpath={.status.phase}").Output()
-		o.Expect(err).NotTo(o.HaveOccurred())
-		if strings.Compare(csvState, "Succeeded") == 0 {
-			e2e.Logf("CSV check complete!!!")
-			return true, nil
-
-		}
-		return false, nil
-
-	})
-	exutil.AssertWaitPollNoErr(errCheck, fmt.Sprintf("CSV %v in %v namespace does not have expected status", csvName, sub.namespace))
-
+	err := applyResourceFromTemplateByAdmin(oc, "--ignore-unknown-parameters=true", "-f", ns.template, "-p", "NAME="+ns.name)
+	if err != nil {
+		e2e.Logf("Error creating namespace %v", err)
+	}
+
+	g.By("(1.2) Subscribing to the operator and waiting for its CSV to succeed")
+	operator := &olm.Operator{
+		Package:                sub.operatorName,
+		SubscriptionName:       sub.name,
+		Namespace:              sub.namespace,
+		OperatorGroupName:      og.name,
+		AllNamespaces:          og.targetNamespaces == "",
+		Channel:                sub.channel,
+		CatalogSource:          sub.catalog,
+		CatalogSourceNamespace: sub.catalogNamespace,
+		Timeout:                snooze * time.Second,
+	}
+	operator.Install(oc)
 	return true
 }
 
@@ -803,20 +767,8 @@ func createBFDProfileCR(oc *exutil.CLI, bfdProfile bfdProfileResource) (status b
 
 }
 func checkBFDSessions(oc *exutil.CLI, ns string) (status bool) {
-	cmd := []string{"-n", ns, bgpRouterPodName, "--", "vtysh", "-c", "show bfd peers brief"}
-	errCheck := wait.Poll(60*time.Second, 120*time.Second, func() (bool, error) {
-		e2e.Logf("Checking status of BFD session")
-		bfdOutput, err := oc.AsAdmin().WithoutNamespace().Run("exec").Args(cmd...).Output()
-		o.Expect(bfdOutput).NotTo(o.BeEmpty())
-		if err != nil {
-			return false, nil
-		}
-		if strings.Contains(bfdOutput, "down") {
-			e2e.Logf("Failed to establish BFD session between router and speakers, Trying again")
-			return false, nil
-		}
-		return true, nil
-	})
+	e2e.Logf("Checking status of BFD session")
+	errCheck := newFrrPodPeer(oc, ns, bgpRouterPodName, "").WaitForBFDPeersUp()
 	exutil.AssertWaitPollNoErr(errCheck, "Establishing BFD session between router and speakers timed out")
 	e2e.Logf("BFD session established")
 	return true
@@ -0,0 +1,119 @@
+package networking
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	o "github.com/onsi/gomega"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/netpolicy"
+	v1 "k8s.io/api/core/v1"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+	netutils "k8s.io/utils/net"
+)
+
+// newPolicyOracleFromCluster returns a verdict oracle loaded with the NetworkPolicies in the given namespaces and all the (Baseline)AdminNetworkPolicies in the cluster
+func newPolicyOracleFromCluster(oc *exutil.CLI, namespaces ...string) *netpolicy.Oracle {
+	oracle := netpolicy.NewOracle()
+	for _, ns := range namespaces {
+		output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("networkpolicy", "-n", ns, "-o", "json").Output()
+		o.Expect(err).NotTo(o.HaveOccurred())
+		o.Expect(oracle.AddFromJSON([]byte(output))).NotTo(o.HaveOccurred())
+	}
+
+	for _, kind := range []string{"adminnetworkpolicy", "baselineadminnetworkpolicy"} {
+		output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(kind, "-o", "json").Output()
+		if err != nil {
+			e2e.Logf("Cannot get %s, the cluster may not support it: %v", kind, err)
+			continue
+		}
+		o.Expect(oracle.AddFromJSON([]byte(output))).NotTo(o.HaveOccurred())
+	}
+	return oracle
+}
+
+// getPolicyOracleEndpoint returns the pod as an endpoint for the verdict oracle, including its namespace labels and named ports
+func getPolicyOracleEndpoint(oc *exutil.CLI, namespace, podName string) netpolicy.Endpoint {
+	podJSON, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("pod", podName, "-n", namespace, "-o", "json").Output()
+	o.Expect(err).NotTo(o.HaveOccurred())
+	pod := v1.Pod{}
+	o.Expect(json.Unmarshal([]byte(podJSON), &pod)).NotTo(o.HaveOccurred())
+
+	nsJSON, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("namespace", namespace, "-o", "json").Output()
+	o.Expect(err).NotTo(o.HaveOccurred())
+	ns := v1.Namespace{}
+	o.Expect(json.Unmarshal([]byte(nsJSON), &ns)).NotTo(o.HaveOccurred())
+
+	endpoint := netpolicy.Endpoint{
+		Kind:            netpolicy.PodEndpoint,
+		Name:            podName,
+		Namespace:       namespace,
+		Labels:          pod.Labels,
+		NamespaceLabels: ns.Labels,
+	}
+	if pod.Spec.HostNetwork {
+		endpoint.Kind = netpolicy.NodeEndpoint
+	}
+	for _, podIP := range pod.Status.PodIPs {
+		endpoint.IPs = append(endpoint.IPs, podIP.IP)
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name != "" {
+				endpoint.Ports = append(endpoint.Ports, netpolicy.ContainerPort{Name: port.Name, Port: port.ContainerPort, Protocol: string(port.Protocol)})
+			}
+		}
+	}
+	return endpoint
+}
+
+// expectFromPolicyOracle sets the expectation of every pod to pod cell in the matrix using the verdicts calculated by the oracle
+func (m *connectivityMatrix) expectFromPolicyOracle(oc *exutil.CLI, oracle *netpolicy.Oracle) *connectivityMatrix {
+	endpoints := map[string]netpolicy.Endpoint{}
+	endpoint := func(namespace, podName string) netpolicy.Endpoint {
+		key := namespace + "/" + podName
+		if _, ok := endpoints[key]; !ok {
+			endpoints[key] = getPolicyOracleEndpoint(oc, namespace, podName)
+		}
+		return endpoints[key]
+	}
+
+	for _, source := range m.sources {
+		if source.kind != podSource && source.kind != hostNetworkPodSource {
+			continue
+		}
+		for _, target := range m.targets {
+			if target.kind != podIPTarget {
+				continue
+			}
+			port, err := strconv.Atoi(target.port)
+			o.Expect(err).NotTo(o.HaveOccurred(), "Target %s has no numeric port", target.name)
+
+			family := "ipv4"
+			if netutils.IsIPv6String(target.ip) {
+				family = "ipv6"
+			}
+			dst := endpoint(splitNamespacedName(target.group))
+			verdict := oracle.Evaluate(netpolicy.Connection{
+				Source:      endpoint(source.namespace, source.podName),
+				Destination: dst,
+				Port:        int32(port),
+				Protocol:    "TCP",
+				Family:      family,
+			})
+			e2e.Logf("Oracle verdict %s -> %s: allowed=%v egress=%+v ingress=%+v", source.name, target.name, verdict.Allowed(), verdict.Egress, verdict.Ingress)
+			m.setExpected(source.name, target.name, verdict.Allowed())
+		}
+	}
+	return m
+}
+
+// splitNamespacedName splits a namespace/name string
+func splitNamespacedName(namespacedName string) (string, string) {
+	namespace, name, found := strings.Cut(namespacedName, "/")
+	if !found {
+		return "", namespacedName
+	}
+	return namespace, name
+}
@@ -16,6 +16,7 @@ import (
 	o "github.com/onsi/gomega"
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/ovndb"
 
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
@@ -894,14 +895,14 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 			o.Expect(err).NotTo(o.HaveOccurred())
 			o.Expect(output).To(o.ContainSubstring("ipblock-single-cidr-egress-except"))
 		}
-		g.By("Checking connectivity from pod3 to pod1")
-		CurlPod2PodPass(oc, ns1, "hello-pod3", ns1, "hello-pod1")
-
-		g.By("Checking connectivity from pod3 to pod2")
-		CurlPod2PodFail(oc, ns1, "hello-pod3", ns1, "hello-pod2")
-
-		g.By("Checking connectivity from pod3 to pod4")
-		CurlPod2PodFail(oc, ns1, "hello-pod3", ns1, "hello-pod4")
+		g.By("Checking connectivity from pod3 to pod1, pod2 and pod4")
+		pod3 := newPodSource(ns1, "hello-pod3")
+		matrix := newConnectivityMatrix().addSources(pod3)
+		for _, podName := range []string{"hello-pod1", "hello-pod2", "hello-pod4"} {
+			matrix.addTargets(newPodTargets(oc, ns1, podName, "8080")...)
+		}
+		// the policy allows the node subnet of pod1 except pod2, pod4 runs on another node
+		matrix.expectFromPolicyOracle(oc, newPolicyOracleFromCluster(oc, ns1)).verify(oc)
 		if ipStackType == "dualstack" {
 			g.By("Delete networkpolicy from ns1 so no networkpolicy in namespace")
 			err = oc.AsAdmin().WithoutNamespace().Run("delete").Args("networkpolicy", "ipblock-dual-cidrs-egress-except", "-n", ns1).Execute()
@@ -913,15 +914,7 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 		}
 
 		g.By("Check connectivity works fine across all failed ones above to make sure all policy flows are cleared properly")
-
-		g.By("Checking connectivity from pod3 to pod1")
-		CurlPod2PodPass(oc, ns1, "hello-pod3", ns1, "hello-pod1")
-
-		g.By("Checking connectivity from pod3 to pod2")
-		CurlPod2PodPass(oc, ns1, "hello-pod3", ns1, "hello-pod2")
-
-		g.By("Checking connectivity from pod3 to pod4")
-		CurlPod2PodPass(oc, ns1, "hello-pod3", ns1, "hello-pod4")
+		matrix.expectFromPolicyOracle(oc, newPolicyOracleFromCluster(oc, ns1)).verify(oc)
 
 	})
 
@@ -1649,36 +1642,30 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 
 		exutil.By("Get the ACL for the created policy")
 		//list ACLs related to the networkpolicy name
-		aclName := fmt.Sprintf("'NP:%s:%s:Ingres'", ns, policyName)
-		listACLCmd := fmt.Sprintf("ovn-nbctl find acl name='NP\\:%s\\:%s\\:Ingres'", ns, policyName)
-		listAclOutput, listErr := exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKNodePod, "ovnkube-controller", listACLCmd)
+		ovnDB, dbErr := ovndb.NewClientForNode(oc, nodeName)
+		o.Expect(dbErr).NotTo(o.HaveOccurred())
+		aclName := fmt.Sprintf("NP:%s:%s:Ingres", ns, policyName)
+		acls, listErr := ovnDB.ACLsWithName(aclName)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listAclOutput).NotTo(o.BeEmpty())
-		e2e.Logf(listAclOutput)
-		var aclMap map[string]string
-		var listPGCmd string
+		e2e.Logf("ACLs of the policy: %+v", acls)
 		//Dual stack has two ACLs for policy and uuid of both are needed to get port group
+		expectedACLs := 1
 		if ipStackType == "dualstack" {
-			listAcls := strings.Split(listAclOutput, "\n\n")
-			aclMap = nbContructToMap(listAcls[0])
-			o.Expect(len(aclMap)).NotTo(o.Equal(0))
-			aclMap1 := nbContructToMap(listAcls[1])
-			o.Expect(len(aclMap1)).NotTo(o.Equal(0))
-			listPGCmd = fmt.Sprintf("ovn-nbctl find port-group acls='[%s, %s]'", aclMap["_uuid"], aclMap1["_uuid"])
-		} else {
-			aclMap = nbContructToMap(listAclOutput)
-			o.Expect(len(aclMap)).NotTo(o.Equal(0))
-			listPGCmd = fmt.Sprintf("ovn-nbctl find port-group acls='[%s]'", aclMap["_uuid"])
+			expectedACLs = 2
+		}
+		o.Expect(acls).To(o.HaveLen(expectedACLs))
+		aclUUIDs := []string{}
+		for _, acl := range acls {
+			aclUUIDs = append(aclUUIDs, acl.UUID)
 		}
-		aclMap["name"] = aclName
+		acl := acls[0]
 
 		exutil.By("Get the port group for the created policy")
-		listPGOutput, listErr := exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKNodePod, "ovnkube-controller", listPGCmd)
+		portGroups, listErr := ovnDB.PortGroupsWithACLs(aclUUIDs...)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listPGOutput).NotTo(o.BeEmpty())
-		e2e.Logf(listPGOutput)
-		pgMap := nbContructToMap(listPGOutput)
-		o.Expect(len(pgMap)).NotTo(o.Equal(0))
+		o.Expect(portGroups).To(o.HaveLen(1))
+		portGroup := portGroups[0]
+		e2e.Logf("Port group of the policy: %+v", portGroup)
 
 		defer func() {
 			if os.Getenv("DELETE_NAMESPACE") != "false" {
@@ -1687,7 +1674,7 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 		}()
 		o.Expect(err).NotTo(o.HaveOccurred())
 		exutil.By("Create a duplicate ACL")
-		createAclCmd := fmt.Sprintf("ovn-nbctl --id=@copyacl create acl name=copyacl direction=%s action=%s -- add port_group %s acl @copyacl", aclMap["direction"], aclMap["action"], pgMap["_uuid"])
+		createAclCmd := fmt.Sprintf("ovn-nbctl --id=@copyacl create acl name=copyacl direction=%s action=%s -- add port_group %s acl @copyacl", acl.Direction, acl.Action, portGroup.UUID)
 		idOutput, listErr := exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKNodePod, "ovnkube-controller", createAclCmd)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
 		o.Expect(idOutput).NotTo(o.BeEmpty())
@@ -1698,7 +1685,11 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 			}
 		}()
 		exutil.By("Set properties of duplicate ACL")
-		setAclPropertiesCmd := fmt.Sprintf("ovn-nbctl set acl %s  match='%s' priority=%s meter=%s", idOutput, aclMap["match"], aclMap["priority"], aclMap["meter"])
+		meter := "[]"
+		if acl.Meter != "" {
+			meter = acl.Meter
+		}
+		setAclPropertiesCmd := fmt.Sprintf("ovn-nbctl set acl %s  match='%q' priority=%d meter=%s", idOutput, acl.Match, acl.Priority, meter)
 		_, listErr = exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKNodePod, "ovnkube-controller", setAclPropertiesCmd)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
 		defer func() {
@@ -1713,11 +1704,10 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 		o.Expect(listErr).NotTo(o.HaveOccurred())
 
 		exutil.By("Check duplicate ACL is created successfully")
-		listDupACLCmd := fmt.Sprintf("ovn-nbctl find acl name='NP\\:%s\\:%s\\:Ingre0'", ns, policyName)
-		listDupAclOutput, listErr := exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKNodePod, "ovnkube-controller", listDupACLCmd)
+		dupACLs, listErr := ovnDB.ACLsWithName(fmt.Sprintf("NP:%s:%s:Ingre0", ns, policyName))
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listDupAclOutput).NotTo(o.BeEmpty())
-		e2e.Logf(listDupAclOutput)
+		o.Expect(dupACLs).NotTo(o.BeEmpty())
+		e2e.Logf("Duplicate ACLs: %+v", dupACLs)
 
 		exutil.By("Delete the ovnkube node pod on the node")
 		ovnKNodePod, ovnkNodePodErr = exutil.GetPodName(oc, "openshift-ovn-kubernetes", "app=ovnkube-node", nodeName)
@@ -1735,13 +1725,15 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 		o.Expect(ovnKNodePod).ShouldNot(o.Equal(""))
 
 		exutil.By("Check the duplicate ACL is removed")
-		listAclOutput, listErr = exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKNodePod, "ovnkube-controller", listACLCmd)
+		ovnDB, dbErr = ovndb.NewClientForNode(oc, nodeName)
+		o.Expect(dbErr).NotTo(o.HaveOccurred())
+		acls, listErr = ovnDB.ACLsWithName(aclName)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listAclOutput).NotTo(o.BeEmpty(), listAclOutput)
+		o.Expect(acls).NotTo(o.BeEmpty())
 
-		listDupAclOutput, listErr = exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKNodePod, "ovnkube-controller", listDupACLCmd)
+		dupACLs, listErr = ovnDB.ACLsWithName(fmt.Sprintf("NP:%s:%s:Ingre0", ns, policyName))
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listDupAclOutput).To(o.BeEmpty())
+		o.Expect(dupACLs).To(o.BeEmpty())
 	})
 
 	// author: asood@redhat.com
@@ -1918,40 +1910,43 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 		o.Expect(ovnNodePod).NotTo(o.BeEmpty())
 
 		exutil.By("3. Check the acl from the port-group from the OVNK leader ovnkube-node")
-		listPGCmd := fmt.Sprintf("ovn-nbctl find port-group | grep -C 2 '%s\\:allow-same-namespace'", ns)
-		listPGCOutput, listErr := exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", ovnNodePod, listPGCmd)
+		ovnDB, dbErr := ovndb.NewClientForNode(oc, nodeList.Items[0].Name)
+		o.Expect(dbErr).NotTo(o.HaveOccurred())
+		portGroups, listErr := ovnDB.PortGroups()
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listPGCOutput).NotTo(o.BeEmpty())
-		e2e.Logf("Output %s", listPGCOutput)
+		var policyPortGroup *ovndb.PortGroup
+		for i, portGroup := range portGroups {
+			for _, value := range portGroup.ExternalIDs {
+				if strings.Contains(value, ns+":allow-same-namespace") {
+					policyPortGroup = &portGroups[i]
+				}
+			}
+		}
+		o.Expect(policyPortGroup).NotTo(o.BeNil(), "No port group found for the policy %s:allow-same-namespace", ns)
+		e2e.Logf("Port group %+v", *policyPortGroup)
 
 		exutil.By("4. Check the addresses in ACL's address-set is empty")
-		var PGCMap map[string]string
-		PGCMap = nbContructToMap(listPGCOutput)
-		acls := strings.Split(strings.Trim(PGCMap["acls"], "[]"), ", ")
-		o.Expect(len(acls)).To(o.Equal(2))
-
-		listAclCmd := fmt.Sprintf("ovn-nbctl list acl %s", strings.Join(acls, " "))
-		listAclOutput, listErr := exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", ovnNodePod, listAclCmd)
+		o.Expect(policyPortGroup.ACLs).To(o.HaveLen(2))
+		acls, listErr := ovnDB.ACLsByUUID(policyPortGroup.ACLs...)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listAclOutput).NotTo(o.BeEmpty())
+		o.Expect(acls).NotTo(o.BeEmpty())
 
 		regex := `\{\$(\w+)\}`
 		re := regexp.MustCompile(regex)
-		addrSetNames := re.FindAllString(listAclOutput, -1)
-		if len(addrSetNames) == 0 {
+		var addrSetName string
+		for _, acl := range acls {
+			if matches := re.FindStringSubmatch(acl.Match); matches != nil {
+				addrSetName = matches[1]
+				break
+			}
+		}
+		if addrSetName == "" {
 			e2e.Fail("No matched address_set name found")
 		}
-		addrSetName := strings.Trim(addrSetNames[0], "{$}")
-		o.Expect(addrSetName).NotTo(o.BeEmpty())
 
-		listAddressSetCmd := fmt.Sprintf("ovn-nbctl list address_set %s", addrSetName)
-		listAddrOutput, listErr := exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", ovnNodePod, listAddressSetCmd)
+		addrSet, listErr := ovnDB.AddressSet(addrSetName)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listAddrOutput).NotTo(o.BeEmpty())
-		var AddrMap map[string]string
-		AddrMap = nbContructToMap(listAddrOutput)
-		addrs := strings.Trim(AddrMap["addresses"], "[]")
-		o.Expect(addrs).To(o.BeEmpty())
+		o.Expect(addrSet.Addresses).To(o.BeEmpty())
 
 		exutil.By("5. Create a hello pod on non existent node")
 		nonexistNodeName := "doesnotexist-" + getRandomString()
@@ -1964,12 +1959,9 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 		pod1.createPingPodNode(oc)
 
 		exutil.By("6. Verify address is not added to address-set")
-		listAddrOutput, listErr = exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", ovnNodePod, listAddressSetCmd)
+		addrSet, listErr = ovnDB.AddressSet(addrSetName)
 		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listAddrOutput).NotTo(o.BeEmpty())
-		AddrMap = nbContructToMap(listAddrOutput)
-		addrs = strings.Trim(AddrMap["addresses"], "[]")
-		o.Expect(addrs).To(o.BeEmpty())
+		o.Expect(addrSet.Addresses).To(o.BeEmpty())
 
 		exutil.By("7. Delete the pods that did not reach running state and create it with valid node name")
 		err = oc.AsAdmin().WithoutNamespace().Run("delete").Args("pod", pod1.name, "-n", ns).Execute()
@@ -1979,21 +1971,13 @@ var _ = g.Describe("[sig-networking] SDN networkpolicy", func() {
 		waitPodReady(oc, pod1.namespace, pod1.name)
 
 		exutil.By("8. Verify address is added to address-set")
-		listAddrOutput, listErr = exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", ovnNodePod, listAddressSetCmd)
-		o.Expect(listErr).NotTo(o.HaveOccurred())
-		o.Expect(listAddrOutput).NotTo(o.BeEmpty())
-		AddrMap = nbContructToMap(listAddrOutput)
-		addrs = strings.Trim(AddrMap["addresses"], "[\"]")
-		o.Expect(addrs).NotTo(o.BeEmpty())
-
 		ipStack := checkIPStackType(oc)
-		if (ipStack == "ipv6single") || (ipStack == "ipv4single") {
-			Pod1IP, _ := getPodIP(oc, ns, pod1.name)
-			o.Expect(addrs == Pod1IP).To(o.BeTrue())
-		} else {
-			_, Pod1IPv4 := getPodIP(oc, ns, pod1.name)
-			o.Expect(addrs == Pod1IPv4).To(o.BeTrue())
+		podIP, _ := getPodIP(oc, ns, pod1.name)
+		if ipStack == "dualstack" {
+			_, podIP = getPodIP(oc, ns, pod1.name)
 		}
+		_, waitErr := ovnDB.WaitForAddressSetAddresses(addrSetName, podIP)
+		o.Expect(waitErr).NotTo(o.HaveOccurred())
 	})
 })
 
@@ -459,8 +459,6 @@ func installNMstateOperator(oc *exutil.CLI) {
 	e2e.Logf("Check catalogsource and install nmstate operator.")
 
 	namespaceTemplate := generateTemplateAbsolutePath("namespace-template.yaml")
-	operatorGroupTemplate := generateTemplateAbsolutePath("operatorgroup-template.yaml")
-	subscriptionTemplate := generateTemplateAbsolutePath("subscription-template.yaml")
 	sub := subscriptionResource{
 		name:             "nmstate-operator-sub",
 		namespace:        opNamespace,
@@ -468,7 +466,6 @@ func installNMstateOperator(oc *exutil.CLI) {
 		channel:          "stable",
 		catalog:          "qe-app-registry",
 		catalogNamespace: "openshift-marketplace",
-		template:         subscriptionTemplate,
 	}
 	catalogSource := getOperatorSource(oc, "openshift-marketplace")
 	if catalogSource == "" {
@@ -483,7 +480,6 @@ func installNMstateOperator(oc *exutil.CLI) {
 		name:             opName,
 		namespace:        opNamespace,
 		targetNamespaces: opNamespace,
-		template:         operatorGroupTemplate,
 	}
 
 	operatorInstall(oc, sub, ns, og)
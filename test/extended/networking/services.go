@@ -977,13 +977,13 @@ var _ = g.Describe("[sig-networking] SDN service", func() {
 		var epErr error
 		for _, eachNode := range nodeList {
 			if ipStack == "dualstack" || ipStack == "ipv6single" {
-				endpointsv6, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, "\\["+svcIPv6+"\\]:27017")
+				endpointsv6, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, "["+svcIPv6+"]:27017", expectedEndpointsv6)
 				e2e.Logf("\n Got V6 endpoints of service lb for node %s : %v\n", eachNode, expectedEndpointsv6)
 				o.Expect(epErr).NotTo(o.HaveOccurred())
 				o.Expect(unorderedEqual(endpointsv6, expectedEndpointsv6)).Should(o.BeTrue(), fmt.Sprintf("V6 service lb endpoints on node %sdo not match expected endpoints!", eachNode))
 			}
 			if ipStack == "dualstack" || ipStack == "ipv4single" {
-				endpointsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017")
+				endpointsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017", expectedEndpointsv4)
 				e2e.Logf("\n Got V4 endpoints of service lb for node %s : %v\n", eachNode, expectedEndpointsv4)
 				o.Expect(epErr).NotTo(o.HaveOccurred())
 				o.Expect(unorderedEqual(endpointsv4, expectedEndpointsv4)).Should(o.BeTrue(), fmt.Sprintf("V4 service lb endpoints on node %sdo not match expected endpoints!", eachNode))
@@ -1041,13 +1041,13 @@ var _ = g.Describe("[sig-networking] SDN service", func() {
 		exutil.By("8. Check lb-list entries in northdb again in each node's ovnkube-node pod, only Ready pods' endpoints reminded in service lb endpoints \n")
 		for _, eachNode := range nodeList {
 			if ipStack == "dualstack" || ipStack == "ipv6single" {
-				actualFinalEPsv6, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, "\\["+svcIPv6+"\\]:27017")
+				actualFinalEPsv6, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, "["+svcIPv6+"]:27017", expectedRemindedEPsv6)
 				e2e.Logf("\n\n After scale-down to 2, V6 endpoints from lb-list output on node %s northdb: %v\n\n", eachNode, actualFinalEPsv6)
 				o.Expect(epErr).NotTo(o.HaveOccurred())
 				o.Expect(unorderedEqual(actualFinalEPsv6, expectedRemindedEPsv6)).Should(o.BeTrue(), fmt.Sprintf("After scale-down, V6 service lb endpoints on node %s do not match expected endpoints!", eachNode))
 			}
 			if ipStack == "dualstack" || ipStack == "ipv4single" {
-				actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017")
+				actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017", expectedRemindedEPsv4)
 				o.Expect(epErr).NotTo(o.HaveOccurred())
 				e2e.Logf("\n\n After scale-down to 2, V4 endpoints from lb-list output on node %s northdb: %v\n\n", eachNode, actualFinalEPsv4)
 				o.Expect(unorderedEqual(actualFinalEPsv4, expectedRemindedEPsv4)).Should(o.BeTrue(), fmt.Sprintf("After scale-down, V4 service lb endpoints on node %s do not match expected endpoints!", eachNode))
@@ -1088,13 +1088,13 @@ var _ = g.Describe("[sig-networking] SDN service", func() {
 		// that is because these two pods transition from Running state to terminating but serving state and there is no other running pod available
 		for _, eachNode := range nodeList {
 			if ipStack == "dualstack" || ipStack == "ipv6single" {
-				actualFinalEPsv6, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, "\\["+svcIPv6+"\\]:27017")
+				actualFinalEPsv6, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, "["+svcIPv6+"]:27017", expectedRemindedEPsv6)
 				e2e.Logf("\n\n After scale-down to 0, V6 endpoints from lb-list output on node %s northdb: %v\n\n", eachNode, actualFinalEPsv6)
 				o.Expect(epErr).NotTo(o.HaveOccurred())
 				o.Expect(unorderedEqual(actualFinalEPsv6, expectedRemindedEPsv6)).Should(o.BeTrue(), fmt.Sprintf("After scale-down, V6 service lb endpoints on node %s do not match expected endpoints!", eachNode))
 			}
 			if ipStack == "dualstack" || ipStack == "ipv4single" {
-				actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017")
+				actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017", expectedRemindedEPsv4)
 				o.Expect(epErr).NotTo(o.HaveOccurred())
 				e2e.Logf("\n\n After scale-down to 0, V4 endpoints from lb-list output on node %s northdb: %v\n\n", eachNode, actualFinalEPsv4)
 				o.Expect(unorderedEqual(actualFinalEPsv4, expectedRemindedEPsv4)).Should(o.BeTrue(), fmt.Sprintf("After scale-down, V4 service lb endpoints on node %s do not match expected endpoints!", eachNode))
@@ -1529,7 +1529,7 @@ var _ = g.Describe("[sig-networking] SDN service", func() {
 		var endpointsv4 []string
 		var epErr error
 		for _, eachNode := range nodeList {
-			endpointsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017")
+			endpointsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017", expectedEndpointsv4)
 			e2e.Logf("\n Got V4 endpoints of service lb for node %s : %v\n", eachNode, expectedEndpointsv4)
 			o.Expect(epErr).NotTo(o.HaveOccurred())
 			o.Expect(unorderedEqual(endpointsv4, expectedEndpointsv4)).Should(o.BeTrue(), fmt.Sprintf("V4 service lb endpoints on node %sdo not match expected endpoints!", eachNode))
@@ -1597,7 +1597,7 @@ var _ = g.Describe("[sig-networking] SDN service", func() {
 
 		exutil.By("5.1. Check lb-list entries in northdb again in each node's ovnkube-node pod, only Ready pods' endpoints reminded in service lb endpoints \n")
 		for _, eachNode := range nodeList {
-			actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017")
+			actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017", expectedRemindedEPsv4)
 			o.Expect(epErr).NotTo(o.HaveOccurred())
 			e2e.Logf("\n\n After scale-down to 2, V4 endpoints from lb-list output on node %s northdb: %v\n\n", eachNode, actualFinalEPsv4)
 			o.Expect(unorderedEqual(actualFinalEPsv4, expectedRemindedEPsv4)).Should(o.BeTrue(), fmt.Sprintf("After scale-down, V4 service lb endpoints on node %s do not match expected endpoints!", eachNode))
@@ -1656,7 +1656,7 @@ var _ = g.Describe("[sig-networking] SDN service", func() {
 		// expectedRemindedEPv4 are still expected in NBDB for a little while,
 		// that is because the last pod transition from Running state to terminating but serving state and there is no other running pod available
 		for _, eachNode := range nodeList {
-			actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017")
+			actualFinalEPsv4, epErr = getLBListEndpointsbySVCIPPortinNBDB(oc, eachNode, svcIPv4+":27017", expectedRemindedEPsv4)
 			o.Expect(epErr).NotTo(o.HaveOccurred())
 			e2e.Logf("\n\n After scale-down to 0, V4 endpoints from lb-list output on node %s northdb: %v\n\n", eachNode, actualFinalEPsv4)
 			o.Expect(unorderedEqual(actualFinalEPsv4, expectedRemindedEPsv4)).Should(o.BeTrue(), fmt.Sprintf("After scale-down, V4 service lb endpoints on node %s do not match expected endpoints!", eachNode))
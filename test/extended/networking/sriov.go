@@ -1157,8 +1157,7 @@ var _ = g.Describe("[sig-networking] SDN sriov", func() {
 				OperatorName:  "netobserv-operator",
 				Namespace:     netobservNS,
 				PackageName:   NOPackageName,
-				Subscription:  filePath.Join(subscriptionDir, "sub-template.yaml"),
-				OperatorGroup: filePath.Join(subscriptionDir, "allnamespace-og.yaml"),
+				AllNamespaces: true,
 				CatalogSource: &NOSource,
 			}
 			sriovNetworkAttachTmpFile = filepath.Join(sriovBaseDir, "sriovnetwork-netobserv.yaml")
@@ -30,13 +30,11 @@ var _ = g.Describe("[sig-networking] SDN sriov installation", func() {
 	})
 	g.It("LEVEL0-Author:zzhao-High-55957-Sriov operator can be setup ", func() {
 		var (
-			buildPruningBaseDir   = exutil.FixturePath("testdata", "networking/sriov")
-			namespaceTemplate     = filepath.Join(buildPruningBaseDir, "namespace-template.yaml")
-			operatorGroupTemplate = filepath.Join(buildPruningBaseDir, "operatorgroup-template.yaml")
-			subscriptionTemplate  = filepath.Join(buildPruningBaseDir, "subscription-template.yaml")
-			sriovOperatorconfig   = filepath.Join(buildPruningBaseDir, "sriovoperatorconfig.yaml")
-			opNamespace           = "openshift-sriov-network-operator"
-			opName                = "sriov-network-operators"
+			buildPruningBaseDir = exutil.FixturePath("testdata", "networking/sriov")
+			namespaceTemplate   = filepath.Join(buildPruningBaseDir, "namespace-template.yaml")
+			sriovOperatorconfig = filepath.Join(buildPruningBaseDir, "sriovoperatorconfig.yaml")
+			opNamespace         = "openshift-sriov-network-operator"
+			opName              = "sriov-network-operators"
 		)
 		sub := subscriptionResource{
 			name:             "sriov-network-operator-subsription",
@@ -45,7 +43,6 @@ var _ = g.Describe("[sig-networking] SDN sriov installation", func() {
 			channel:          "stable",
 			catalog:          "qe-app-registry",
 			catalogNamespace: "openshift-marketplace",
-			template:         subscriptionTemplate,
 		}
 		ns := namespaceResource{
 			name:     opNamespace,
@@ -55,7 +52,6 @@ var _ = g.Describe("[sig-networking] SDN sriov installation", func() {
 			name:             opName,
 			namespace:        opNamespace,
 			targetNamespaces: opNamespace,
-			template:         operatorGroupTemplate,
 		}
 		catalogSource := getOperatorSource(oc, "openshift-marketplace")
 		if catalogSource == "" {
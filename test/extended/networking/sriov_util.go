@@ -13,6 +13,7 @@ import (
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/olm"
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 	e2eoutput "k8s.io/kubernetes/test/e2e/framework/pod/output"
@@ -965,48 +966,34 @@ func uninstallSriovOperator(oc *exutil.CLI, namespace string) {
 
 func installSriovOperator(oc *exutil.CLI, opNamespace string) {
 	var (
-		buildPruningBaseDir   = exutil.FixturePath("testdata", "networking/sriov")
-		namespaceTemplate     = filepath.Join(buildPruningBaseDir, "namespace-template.yaml")
-		operatorGroupTemplate = filepath.Join(buildPruningBaseDir, "operatorgroup-template.yaml")
-		subscriptionTemplate  = filepath.Join(buildPruningBaseDir, "subscription-template.yaml")
-		sriovOperatorconfig   = filepath.Join(buildPruningBaseDir, "sriovoperatorconfig.yaml")
-		opName                = "sriov-network-operators"
+		buildPruningBaseDir = exutil.FixturePath("testdata", "networking/sriov")
+		sriovOperatorconfig = filepath.Join(buildPruningBaseDir, "sriovoperatorconfig.yaml")
 	)
-	sub := subscriptionResource{
-		name:             "sriov-network-operator-subsription",
-		namespace:        opNamespace,
-		operatorName:     opName,
-		channel:          "stable",
-		catalog:          "qe-app-registry",
-		catalogNamespace: "openshift-marketplace",
-		template:         subscriptionTemplate,
-	}
-	ns := namespaceResource{
-		name:     opNamespace,
-		template: namespaceTemplate,
-	}
-	og := operatorGroupResource{
-		name:             opName,
-		namespace:        opNamespace,
-		targetNamespaces: opNamespace,
-		template:         operatorGroupTemplate,
-	}
 	catalogSource := getOperatorSource(oc, "openshift-marketplace")
 	if catalogSource == "" {
 		g.Skip("Skip testing as auto-release-app-registry/qe-app-registry not found")
 	}
-	sub.catalog = catalogSource
-	operatorInstall(oc, sub, ns, og)
+	sub := &olm.Operator{
+		Package:           "sriov-network-operator",
+		Namespace:         opNamespace,
+		SubscriptionName:  "sriov-network-operator-subsription",
+		OperatorGroupName: "sriov-network-operators",
+		NamespaceLabels:   map[string]string{"name": opNamespace},
+		Channel:           "stable",
+		CatalogSource:     catalogSource,
+		Config:            map[string]interface{}{"nodeSelector": map[string]string{"node-role.kubernetes.io/worker": ""}},
+	}
+	sub.Install(oc)
 	e2e.Logf("Operator install check successfull as part of setup !!!!!")
 	exutil.By("SUCCESS - sriov operator installed")
 	exutil.By("check sriov version if match the ocp version")
-	operatorVersion := getOperatorVersion(oc, sub.name, sub.namespace)
+	operatorVersion := getOperatorVersion(oc, sub.SubscriptionName, sub.Namespace)
 	ocpversion, _, err := exutil.GetClusterVersion(oc)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	o.Expect(operatorVersion).Should(o.MatchRegexp(ocpversion))
 	err = oc.AsAdmin().WithoutNamespace().Run("apply").Args("-f", sriovOperatorconfig, "-n", opNamespace).Execute()
 	o.Expect(err).NotTo(o.HaveOccurred())
 	exutil.By("Check all pods in sriov namespace are running")
-	chkSriovOperatorStatus(oc, sub.namespace)
+	chkSriovOperatorStatus(oc, sub.Namespace)
 
 }
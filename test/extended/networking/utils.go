@@ -18,6 +18,8 @@ import (
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/ovndb"
+	"github.com/openshift/openshift-tests-private/test/extended/util/pktcap"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
@@ -773,13 +775,19 @@ func findUnUsedIPs(oc *exutil.CLI, cidr string, number int) []string {
 	//shuffle the ips slice
 	rand.Seed(time.Now().UnixNano())
 	rand.Shuffle(len(ipRange), func(i, j int) { ipRange[i], ipRange[j] = ipRange[j], ipRange[i] })
+	usedIPs := getIPsUsedByCluster(oc)
 	for _, ip := range ipRange {
 		if len(ipUnused) < number {
+			if usedIPs.Has(ip) || !reserveIP(oc, ip) {
+				continue
+			}
 			pingCmd := "ping -c4 -t1 " + ip
 			_, err := execCommandInNetworkingPod(oc, pingCmd)
-			if err != nil {
+			if err != nil && !isIPInNeighborTable(ip, func(cmd string) (string, error) { return execCommandInNetworkingPod(oc, cmd) }) {
 				e2e.Logf("%s is not used!\n", ip)
 				ipUnused = append(ipUnused, ip)
+			} else {
+				releaseIPs(oc, ip)
 			}
 		} else {
 			break
@@ -825,6 +833,7 @@ func findUnUsedIPv6(oc *exutil.CLI, cidr string, number int) ([]string, error) {
 	number += 2
 	var ips []string
 	var i = 0
+	usedIPs := getIPsUsedByCluster(oc)
 	for ip := ip.Mask(ipnet.Mask); ipnet.Contains(ip); inc(ip) {
 		//Not use the first two IPv6 addresses , such as 2620:52:0:4e::  , 2620:52:0:4e::1
 		if i == 0 || i == 1 {
@@ -833,12 +842,17 @@ func findUnUsedIPv6(oc *exutil.CLI, cidr string, number int) ([]string, error) {
 		}
 		//Start to detect the IPv6 adress is used or not
 		if i < number {
+			if usedIPs.Has(ip.String()) || !reserveIP(oc, ip.String()) {
+				continue
+			}
 			pingCmd := "ping -c4 -t1 -6 " + ip.String()
 			_, err := execCommandInNetworkingPod(oc, pingCmd)
-			if err != nil {
+			if err != nil && !isIPInNeighborTable(ip.String(), func(cmd string) (string, error) { return execCommandInNetworkingPod(oc, cmd) }) {
 				e2e.Logf("%s is not used!\n", ip)
 				ips = append(ips, ip.String())
 				i++
+			} else {
+				releaseIPs(oc, ip.String())
 			}
 		} else {
 			break
@@ -2149,29 +2163,6 @@ func findLogFromPod(oc *exutil.CLI, searchString string, namespace string, podLa
 	return findLog
 }
 
-// searchOVNDBForSpecCmd This is used for lr-policy-list and snat rules check in ovn db.
-func searchOVNDBForSpecCmd(oc *exutil.CLI, cmd, searchKeyword string, times int) error {
-	ovnPod := getOVNKMasterOVNkubeNode(oc)
-	o.Expect(ovnPod).ShouldNot(o.Equal(""))
-	var cmdOutput string
-	checkOVNDbErr := wait.Poll(10*time.Second, 2*time.Minute, func() (bool, error) {
-		output, cmdErr := exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", ovnPod, cmd)
-		if cmdErr != nil {
-			e2e.Logf("%v,Waiting for expected result to be synced, try next ...,", cmdErr)
-			return false, nil
-		}
-		cmdOutput = output
-		if strings.Count(output, searchKeyword) == times {
-			return true, nil
-		}
-		return false, nil
-	})
-	if checkOVNDbErr != nil {
-		e2e.Logf("The command check result in ovndb is not expected ! See below output \n %s ", cmdOutput)
-	}
-	return checkOVNDbErr
-}
-
 // waitEgressFirewallApplied Wait egressfirewall applied
 func waitEgressFirewallApplied(oc *exutil.CLI, efName, ns string) error {
 	checkErr := wait.Poll(10*time.Second, 60*time.Second, func() (bool, error) {
@@ -2699,71 +2690,25 @@ func getOVNAlertMasterRules(oc *exutil.CLI, alertName string) (string, string, s
 	}
 }
 
-// returns all the logical routers and switches on all the nodes
-func getOVNConstructs(oc *exutil.CLI, constructType string, nodeNames []string) []string {
-	var ovnConstructs []string
-	var matchStr string
-	//var cmdOutput string
-
-	getCmd := "ovn-nbctl --no-leader-only " + constructType
-	ovnPod := getOVNKMasterOVNkubeNode(oc)
-	o.Expect(ovnPod).ShouldNot(o.Equal(""))
-	checkOVNDbErr := wait.Poll(10*time.Second, 2*time.Minute, func() (bool, error) {
-		cmdOutput, cmdErr := exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", ovnPod, getCmd)
-		if cmdErr != nil {
-			e2e.Logf("%v,Waiting for expected result to be synced, try again ...,", cmdErr)
-			return false, nil
-		}
-		o.Expect(cmdOutput).ShouldNot(o.Equal(""))
-		for _, index := range strings.Split(cmdOutput, "\n") {
-			for _, node := range nodeNames {
-				if constructType == "ls-list" {
-					matchStr = fmt.Sprintf("\\((%s\\))", node)
-				} else {
-					matchStr = fmt.Sprintf("\\((GR_%s\\))", node)
-				}
-				re := regexp.MustCompile(matchStr)
-				if re.FindString(index) != "" {
-					ovnConstruct := strings.Fields(index)
-					ovnConstructs = append(ovnConstructs, ovnConstruct[0])
-				}
-			}
-		}
-		return true, nil
-	})
-	if checkOVNDbErr != nil {
-		e2e.Logf("The result in ovndb is not expected ! See below output \n %s ", checkOVNDbErr)
-	}
-	return ovnConstructs
-}
-
-// Returns the logical router or logical switch on a node
+// Returns the UUID of the logical router or logical switch on a node
 func (svcEndpontDetails *svcEndpontDetails) getOVNConstruct(oc *exutil.CLI, constructType string) string {
+	ovnDB, err := ovndb.NewClientForNode(oc, svcEndpontDetails.nodeName)
+	o.Expect(err).NotTo(o.HaveOccurred())
 	var ovnConstruct string
-	var matchStr string
-	getCmd := "ovn-nbctl " + constructType
-	checkOVNDbErr := wait.Poll(10*time.Second, 2*time.Minute, func() (bool, error) {
-		cmdOutput, cmdErr := exutil.RemoteShPodWithBash(oc, "openshift-ovn-kubernetes", svcEndpontDetails.ovnKubeNodePod, getCmd)
-		if cmdErr != nil {
-			e2e.Logf("%v,Waiting for expected result to be synced, try again ...,", cmdErr)
-			return false, nil
-		}
-		if cmdOutput == "" {
+	checkOVNDbErr := ovnDB.Wait(fmt.Sprintf("%s of node %s", constructType, svcEndpontDetails.nodeName), func() (bool, error) {
+		if constructType == "ls-list" {
+			ls, err := ovnDB.LogicalSwitch(svcEndpontDetails.nodeName)
+			if err != nil {
+				return false, err
+			}
+			ovnConstruct = ls.UUID
 			return true, nil
 		}
-		for _, index := range strings.Split(cmdOutput, "\n") {
-
-			if constructType == "ls-list" {
-				matchStr = fmt.Sprintf("\\((%s\\))", svcEndpontDetails.nodeName)
-			} else {
-				matchStr = fmt.Sprintf("\\((GR_%s\\))", svcEndpontDetails.nodeName)
-			}
-			re := regexp.MustCompile(matchStr)
-			if re.FindString(index) != "" {
-				matchedStr := strings.Fields(index)
-				ovnConstruct = matchedStr[0]
-			}
+		lr, err := ovnDB.LogicalRouter("GR_" + svcEndpontDetails.nodeName)
+		if err != nil {
+			return false, err
 		}
+		ovnConstruct = lr.UUID
 		return true, nil
 	})
 	if checkOVNDbErr != nil {
@@ -3162,20 +3107,6 @@ func disableIPForwardingOnSpecNodeNIC(oc *exutil.CLI, worker, secNIC string) {
 	e2e.Logf("IP forwarding was disabled for NIC %s on node %s!", secNIC, worker)
 }
 
-func nbContructToMap(nbConstruct string) map[string]string {
-	listKeyValues := strings.Split(nbConstruct, "\n")
-	var tempMap map[string]string
-	tempMap = make(map[string]string)
-	for _, keyValPair := range listKeyValues {
-		keyValItem := strings.SplitN(keyValPair, ":", 2)
-		key := strings.Trim(keyValItem[0], " ")
-		val := strings.TrimLeft(keyValItem[1], " ")
-		tempMap[key] = val
-
-	}
-	return tempMap
-}
-
 // Create live migration job on Kubevirt cluster
 func (migrationjob *migrationDetails) createMigrationJob(oc *exutil.CLI) {
 	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
@@ -3458,42 +3389,6 @@ func disableNodeIdentityWebhook(oc *exutil.CLI, namespace string, cmName string)
 	return "", err
 }
 
-// get lr-policy-list from logical_router_policy table
-func getlrPolicyList(oc *exutil.CLI, nodeName, tableID string, expected bool) ([]string, error) {
-	// get the ovnkube-node pod on the node
-	ovnKubeNodePod, podErr := exutil.GetPodName(oc, "openshift-ovn-kubernetes", "app=ovnkube-node", nodeName)
-	o.Expect(podErr).NotTo(o.HaveOccurred())
-	o.Expect(ovnKubeNodePod).ShouldNot(o.Equal(""))
-	var lspOutput string
-	var lspErr error
-	var lrPolicyList []string
-
-	lspCmd := "ovn-nbctl lr-policy-list ovn_cluster_router | grep '" + tableID + " '"
-	checkLspErr := wait.Poll(10*time.Second, 2*time.Minute, func() (bool, error) {
-		lspOutput, lspErr = exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKubeNodePod, "northd", lspCmd)
-		if lspErr == nil && lspOutput != "" && expected {
-			cmdOutputLines := strings.Split(lspOutput, "\n")
-			for i := 0; i < len(cmdOutputLines); i++ {
-				lrPolicyList = append(lrPolicyList, cmdOutputLines[i])
-			}
-			return true, nil
-		}
-
-		// check lr-policy-list grep with tableID returned empty, usually there is "command terminated with exit code 1" to lspErr returned, so lspErr is not checked here
-		if lspOutput != "ip4.src ==" && !expected {
-			e2e.Logf("lr-policy-list of table %s is cleared up as expected", tableID)
-			return true, nil
-		}
-
-		e2e.Logf("Waiting for expected result to be synced, try again ...")
-		return false, nil
-	})
-	if checkLspErr != nil {
-		e2e.Logf("The command check result in ovndb is not expected ! See below output \n %s ", lspOutput)
-	}
-	return lrPolicyList, checkLspErr
-}
-
 // Create a kubeconfig that impersonates ovnkube-node
 func generateKubeConfigFileForContext(oc *exutil.CLI, nodeName string, ovnKubeNodePod string, kubeConfigFilePath string, userContext string) bool {
 	var (
@@ -3562,39 +3457,13 @@ func findNodesWithSameSubnet(oc *exutil.CLI, nodeList []string) (bool, []string)
 	return false, nil
 }
 
-// Get endpoints for service:port in northdb of the node
-func getLBListEndpointsbySVCIPPortinNBDB(oc *exutil.CLI, nodeName, svcPort string) ([]string, error) {
-	// get the ovnkube-node pod of the node
-	ovnKubePod, podErr := exutil.GetPodName(oc, "openshift-ovn-kubernetes", "app=ovnkube-node", nodeName)
-	o.Expect(podErr).NotTo(o.HaveOccurred())
-	o.Expect(ovnKubePod).ShouldNot(o.Equal(""))
-	var cmdOutput string
-	var cmdErr error
-	var endpoints []string
-
-	lbCmd := "ovn-nbctl lb-list | grep  \"" + svcPort + "\"  | awk '{print $NF}'"
-	checkOVNDbErr := wait.Poll(2*time.Second, 2*time.Minute, func() (bool, error) {
-		cmdOutput, cmdErr = exutil.RemoteShPodWithBashSpecifyContainer(oc, "openshift-ovn-kubernetes", ovnKubePod, "northd", lbCmd)
-		if cmdErr != nil {
-			e2e.Logf("%v,Waiting for expected result to be synced, try again ...,", cmdErr)
-			return false, nil
-		}
-
-		if cmdOutput != "" {
-			cmdOutputLines := strings.Split(cmdOutput, ",")
-			for i := 0; i < len(cmdOutputLines); i++ {
-				endpoints = append(endpoints, cmdOutputLines[i])
-			}
-			return true, nil
-		}
-
-		e2e.Logf("Waiting for expected result to be synced, try again ...")
-		return false, nil
-	})
-	if checkOVNDbErr != nil {
-		e2e.Logf("The command check result in ovndb is not expected ! See below output \n %s ", cmdOutput)
+// Get endpoints for service ip:port in northdb of the node, waiting until they are the expected ones. IPv6 use the "[ip]:port" format.
+func getLBListEndpointsbySVCIPPortinNBDB(oc *exutil.CLI, nodeName, svcIPPort string, expected []string) ([]string, error) {
+	ovnDB, err := ovndb.NewClientForNode(oc, nodeName)
+	if err != nil {
+		return nil, err
 	}
-	return endpoints, checkOVNDbErr
+	return ovnDB.SetPollInterval(2*time.Second, 2*time.Minute).WaitForLoadBalancerBackends(svcIPPort, expected...)
 }
 
 // Get all pods with same label and also are in same state
@@ -4269,6 +4138,24 @@ func getTcpdumpOnNodeCmdFromPod(oc *exutil.CLI, nodeName, tcpdumpCmd, namespace,
 	return cmdOutput.String()
 }
 
+// capturePacketsOnNodeFromPod captures the packets matching the filter in the node interface while the command runs in the pod
+func capturePacketsOnNodeFromPod(oc *exutil.CLI, nodeName, iface, filter, namespace, podName, cmdOnPod string) pktcap.Packets {
+	capture := pktcap.NewCapture(oc, nodeName).SetInterface(iface).SetFilter(filter).SetDuration(90 * time.Second)
+	packets, err := pktcap.CaptureWhile(capture, func() error {
+		return wait.PollUntilContextTimeout(context.Background(), 10*time.Second, 60*time.Second, true, func(ctx context.Context) (bool, error) {
+			_, cmdErr := e2eoutput.RunHostCmd(namespace, podName, cmdOnPod)
+			if cmdErr != nil {
+				e2e.Logf("Getting error at executing command %s on pod %s: %v, try again ...", cmdOnPod, podName, cmdErr)
+				return false, nil
+			}
+			return true, nil
+		})
+	})
+	o.Expect(err).NotTo(o.HaveOccurred(), "Unable to capture packets on node %s when running %s from pod %s/%s", nodeName, cmdOnPod, namespace, podName)
+	e2e.Logf("The captured packets are:\n%s", packets)
+	return packets
+}
+
 func collectMustGather(oc *exutil.CLI, dstDir string, imageStream string, parameters []string) (string, error) {
 	args := []string{"must-gather"}
 	if dstDir != "" {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/url"
@@ -178,132 +179,58 @@ func getAwsVolumeInfoByVolumeID(volumeID string) (string, error) {
 	return interfaceToString(volumeInfo), err
 }
 
-// Get the volume status "in use" or "available" by persistent volume id
-func getAwsVolumeStatusByVolumeID(volumeID string) (string, error) {
-	volumeInfo, err := getAwsVolumeInfoByVolumeID(volumeID)
-	o.Expect(err).NotTo(o.HaveOccurred())
-	volumeStatus := gjson.Get(volumeInfo, `Volumes.0.State`).Str
-	e2e.Logf("The volume %s status is %q on aws backend", volumeID, volumeStatus)
-	return volumeStatus, err
+// getVolumeBackendByVolumeID returns the backend of the volume provisioned by the csi driver,
+// the vsphere file volumes are not on the CNS block volume backend
+func getVolumeBackendByVolumeID(oc *exutil.CLI, provisioner string, volumeID string) (VolumeBackend, error) {
+	if provisioner == "csi.vsphere.vmware.com" && strings.HasPrefix(volumeID, "file:") {
+		return nil, fmt.Errorf("%w: %q file volumes", errBackendNotSupported, provisioner)
+	}
+	return getVolumeBackend(oc, provisioner)
 }
 
 // Delete backend volume
-func deleteBackendVolumeByVolumeID(oc *exutil.CLI, volumeID string) (string, error) {
-	switch cloudProvider {
-	case "aws":
-		if strings.Contains(volumeID, "::") {
-			volumeID = strings.Split(volumeID, "::")[1]
-			mySession := session.Must(session.NewSession())
-			svc := efs.New(mySession)
-			deleteAccessPointID := &efs.DeleteAccessPointInput{
-				AccessPointId: aws.String(volumeID),
-			}
-			req, resp := svc.DeleteAccessPointRequest(deleteAccessPointID)
-			return interfaceToString(resp), req.Send()
-		}
+func deleteBackendVolumeByVolumeID(oc *exutil.CLI, provisioner string, volumeID string) (string, error) {
+	if provisioner == "efs.csi.aws.com" && strings.Contains(volumeID, "::") {
+		volumeID = strings.Split(volumeID, "::")[1]
 		mySession := session.Must(session.NewSession())
-		svc := ec2.New(mySession, aws.NewConfig())
-		deleteVolumeID := &ec2.DeleteVolumeInput{
-			VolumeId: aws.String(volumeID),
+		svc := efs.New(mySession)
+		deleteAccessPointID := &efs.DeleteAccessPointInput{
+			AccessPointId: aws.String(volumeID),
 		}
-		req, resp := svc.DeleteVolumeRequest(deleteVolumeID)
+		req, resp := svc.DeleteAccessPointRequest(deleteAccessPointID)
 		return interfaceToString(resp), req.Send()
-	case "vsphere":
-		e2e.Logf("Delete %s backend volume is under development", cloudProvider)
-		return "under development now", nil
-	case "gcp":
-		e2e.Logf("Delete %s backend volume is under development", cloudProvider)
-		return "under development now", nil
-	case "azure":
-		e2e.Logf("Delete %s backend volume is under development", cloudProvider)
-		return "under development now", nil
-	case "openstack":
-		e2e.Logf("Delete %s backend volume is under development", cloudProvider)
-		return "under development now", nil
-	default:
-		e2e.Logf("unknown cloud provider")
+	}
+	backend, err := getVolumeBackendByVolumeID(oc, provisioner, volumeID)
+	if errors.Is(err, errBackendNotSupported) {
+		e2e.Logf("Delete %s backend volume is under development", provisioner)
 		return "under development now", nil
 	}
-}
-
-// Check the volume status becomes available, status is "available"
-func checkVolumeAvailableOnBackend(volumeID string) (bool, error) {
-	volumeStatus, err := getAwsVolumeStatusByVolumeID(volumeID)
-	availableStatus := []string{"available"}
-	return contains(availableStatus, volumeStatus), err
-}
-
-// Check the volume is deleted
-func checkVolumeDeletedOnBackend(volumeID string) (bool, error) {
-	volumeStatus, err := getAwsVolumeStatusByVolumeID(volumeID)
-	deletedStatus := []string{""}
-	return contains(deletedStatus, volumeStatus), err
+	if err != nil {
+		return "", err
+	}
+	return "", backend.DeleteVolume(volumeID)
 }
 
 // Waiting the volume become available
-func waitVolumeAvailableOnBackend(oc *exutil.CLI, volumeID string) {
-	switch cloudProvider {
-	case "aws":
-		if strings.Contains(volumeID, "::") {
-			e2e.Logf("Get EFS volume: \"%s\" status is under development", volumeID)
-		} else {
-			err := wait.Poll(10*time.Second, 120*time.Second, func() (bool, error) {
-				volumeStatus, errinfo := checkVolumeAvailableOnBackend(volumeID)
-				if errinfo != nil {
-					e2e.Logf("the err:%v, wait for volume %v to become available.", errinfo, volumeID)
-					return volumeStatus, errinfo
-				}
-				if !volumeStatus {
-					return volumeStatus, nil
-				}
-				return volumeStatus, nil
-			})
-			exutil.AssertWaitPollNoErr(err, fmt.Sprintf("The volume:%v, is not available.", volumeID))
-		}
-	case "vsphere":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	case "gcp":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	case "azure":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	case "openstack":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	default:
-		e2e.Logf("unknown cloud provider")
+func waitVolumeAvailableOnBackend(oc *exutil.CLI, provisioner string, volumeID string) {
+	backend, err := getVolumeBackendByVolumeID(oc, provisioner, volumeID)
+	if errors.Is(err, errBackendNotSupported) {
+		e2e.Logf("Get %s backend volume: \"%s\" status is under development", provisioner, volumeID)
+		return
 	}
+	o.Expect(err).NotTo(o.HaveOccurred())
+	waitBackendVolumeAvailable(backend, volumeID)
 }
 
 // Waiting the volume become deleted
-func waitVolumeDeletedOnBackend(oc *exutil.CLI, volumeID string) {
-	switch cloudProvider {
-	case "aws":
-		if strings.Contains(volumeID, "::") {
-			e2e.Logf("Get EFS volume: \"%s\" status is under development", volumeID)
-		} else {
-			err := wait.Poll(10*time.Second, 120*time.Second, func() (bool, error) {
-				volumeStatus, errinfo := checkVolumeDeletedOnBackend(volumeID)
-				if errinfo != nil {
-					e2e.Logf("the err:%v, wait for volume %v to be deleted.", errinfo, volumeID)
-					return volumeStatus, errinfo
-				}
-				if !volumeStatus {
-					return volumeStatus, nil
-				}
-				return volumeStatus, nil
-			})
-			exutil.AssertWaitPollNoErr(err, fmt.Sprintf("The volume:%v, still exist.", volumeID))
-		}
-	case "vsphere":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	case "gcp":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	case "azure":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	case "openstack":
-		e2e.Logf("Get %s backend volume status is under development", cloudProvider)
-	default:
-		e2e.Logf("unknown cloud provider")
+func waitVolumeDeletedOnBackend(oc *exutil.CLI, provisioner string, volumeID string) {
+	backend, err := getVolumeBackendByVolumeID(oc, provisioner, volumeID)
+	if errors.Is(err, errBackendNotSupported) {
+		e2e.Logf("Get %s backend volume: \"%s\" status is under development", provisioner, volumeID)
+		return
 	}
+	o.Expect(err).NotTo(o.HaveOccurred())
+	waitBackendVolumeDeleted(backend, volumeID)
 }
 
 // Get the volume type by volume id
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	o "github.com/onsi/gomega"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+	"sigs.k8s.io/yaml"
+)
+
+// csiDriverCapabilities declares what a CSI driver supports, the general csi scenarios run only for the drivers declaring the capabilities they need
+type csiDriverCapabilities struct {
+	Provisioner string `json:"provisioner"`
+	// Platforms are the cloud providers the driver is installed on, as returned by getCloudProvider
+	Platforms   []string `json:"platforms"`
+	VolumeModes []string `json:"volumeModes"`
+	// FsTypes are the "csi.storage.k8s.io/fstype" values supported besides the default one
+	FsTypes   []string `json:"fsTypes,omitempty"`
+	Expansion struct {
+		Online  bool `json:"online"`
+		Offline bool `json:"offline"`
+	} `json:"expansion"`
+	Cloning      bool     `json:"cloning"`
+	Snapshot     bool     `json:"snapshot"`
+	FsGroup      bool     `json:"fsGroup"`
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
+	// MaxVolumesPerNode is the upper bound of the CSINode allocatable count, 0 if the driver does not limit the attached volumes
+	MaxVolumesPerNode int64 `json:"maxVolumesPerNode,omitempty"`
+	// KnownIssues are the bugs of the driver by the Polarion ID of the case they skip the driver for
+	KnownIssues map[string]string `json:"knownIssues,omitempty"`
+}
+
+// supportsVolumeMode returns whether the driver supports the "Filesystem" or "Block" volume mode
+func (c csiDriverCapabilities) supportsVolumeMode(volumeMode string) bool {
+	return strSliceContains(c.VolumeModes, volumeMode)
+}
+
+// validate checks the manifest is consistent, the errors fail the scenarios loading the manifests
+func (c csiDriverCapabilities) validate() error {
+	if c.Provisioner == "" {
+		return fmt.Errorf("the provisioner is not set")
+	}
+	if len(c.Platforms) == 0 {
+		return fmt.Errorf("%s: no platforms set", c.Provisioner)
+	}
+	if len(c.VolumeModes) == 0 {
+		return fmt.Errorf("%s: no volumeModes set", c.Provisioner)
+	}
+	for _, volumeMode := range c.VolumeModes {
+		if volumeMode != "Filesystem" && volumeMode != "Block" {
+			return fmt.Errorf("%s: unknown volumeMode %q", c.Provisioner, volumeMode)
+		}
+	}
+	if len(c.FsTypes) > 0 && !c.supportsVolumeMode("Filesystem") {
+		return fmt.Errorf("%s: fsTypes set without the Filesystem volumeMode", c.Provisioner)
+	}
+	if c.MaxVolumesPerNode < 0 {
+		return fmt.Errorf("%s: negative maxVolumesPerNode", c.Provisioner)
+	}
+	return nil
+}
+
+// loadCSIDriverCapabilities loads the capability manifests of the directory sorted by provisioner, one file per driver named by its provisioner
+func loadCSIDriverCapabilities(dir string) ([]csiDriverCapabilities, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	drivers := []csiDriverCapabilities{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var driver csiDriverCapabilities
+		if err := yaml.UnmarshalStrict(content, &driver); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %v", file.Name(), err)
+		}
+		if err := driver.validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", file.Name(), err)
+		}
+		if file.Name() != driver.Provisioner+".yaml" {
+			return nil, fmt.Errorf("the manifest of %s must be named %s.yaml, not %s", driver.Provisioner, driver.Provisioner, file.Name())
+		}
+		drivers = append(drivers, driver)
+	}
+	sort.Slice(drivers, func(i, j int) bool { return drivers[i].Provisioner < drivers[j].Provisioner })
+	return drivers, nil
+}
+
+// getCSIDriverProvisionersWith returns the provisioners whose capability manifests declare what the scenario requires, the
+// drivers with a known issue for the current case are left out. The scenarios intersect them with the provisioners of the
+// cloud provider, as they did with their own lists.
+func getCSIDriverProvisionersWith(requires func(c csiDriverCapabilities) bool) []string {
+	drivers, err := loadCSIDriverCapabilities(filepath.Join(exutil.FixturePath("testdata", "storage"), "csi-driver-capabilities"))
+	o.Expect(err).NotTo(o.HaveOccurred(), "Failed to load the csi driver capability manifests")
+	return filterCSIDriverProvisioners(drivers, exutil.GetCurrentTestPolarionIDNumber(), requires)
+}
+
+// filterCSIDriverProvisioners returns the provisioners of the drivers meeting the requirements without a known issue for the case
+func filterCSIDriverProvisioners(drivers []csiDriverCapabilities, caseID string, requires func(c csiDriverCapabilities) bool) []string {
+	provisioners := []string{}
+	for _, driver := range drivers {
+		if !requires(driver) {
+			continue
+		}
+		if issue, ok := driver.KnownIssues[caseID]; ok {
+			e2e.Logf("Skip the csi driver \"%s\" for the known issue %s", driver.Provisioner, issue)
+			continue
+		}
+		provisioners = append(provisioners, driver.Provisioner)
+	}
+	return provisioners
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	o "github.com/onsi/gomega"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// dataIntegrityMissingChunk is the chunk index of the mismatches of the files missing in the volume
+const dataIntegrityMissingChunk = -1
+
+// dataIntegrity writes deterministic pseudo-random data into a volume and records its SHA-256 manifest,
+// so the data could be verified chunk by chunk after the volume is cloned, restored, resized or consumed by another pod
+type dataIntegrity struct {
+	// seed generates the data, the same seed always generates the same data
+	seed      int64
+	fileCount int
+	fileSize  int64
+	// chunkSize is the size of the data each checksum covers, the mismatches are reported by chunk
+	chunkSize int64
+	// blockOffset is where the data starts on the raw block volumes, it keeps the beginning of the device for the
+	// helpers writing their test string there. It must be a multiple of the chunk size.
+	blockOffset int64
+	manifest    dataIntegrityManifest
+}
+
+// dataIntegrityManifest is the SHA-256 manifest of the data written into a volume
+type dataIntegrityManifest struct {
+	Seed       int64  `json:"seed"`
+	VolumeMode string `json:"volumeMode"`
+	ChunkSize  int64  `json:"chunkSize"`
+	// Files are the checksums of the chunks by file name, the raw block volume data is the single "file" named by the device path
+	Files map[string][]string `json:"files"`
+}
+
+// dataIntegrityMismatch is a file or a chunk whose data differs from the manifest
+type dataIntegrityMismatch struct {
+	File     string
+	Chunk    int
+	Expected string
+	Actual   string
+}
+
+// String returns the mismatch as in the failure reports
+func (m dataIntegrityMismatch) String() string {
+	if m.Chunk == dataIntegrityMissingChunk {
+		return fmt.Sprintf("%s: missing", m.File)
+	}
+	return fmt.Sprintf("%s: chunk %d sha256 is %q, expected %q", m.File, m.Chunk, m.Actual, m.Expected)
+}
+
+type dataIntegrityOption func(*dataIntegrity)
+
+// Replace the default value of data integrity seed
+func setDataIntegritySeed(seed int64) dataIntegrityOption {
+	return func(this *dataIntegrity) {
+		this.seed = seed
+	}
+}
+
+// Replace the default value of data integrity file count, the raw block volumes get the data of all the files in a row
+func setDataIntegrityFileCount(fileCount int) dataIntegrityOption {
+	return func(this *dataIntegrity) {
+		this.fileCount = fileCount
+	}
+}
+
+// Replace the default value of data integrity file size in bytes
+func setDataIntegrityFileSize(fileSize int64) dataIntegrityOption {
+	return func(this *dataIntegrity) {
+		this.fileSize = fileSize
+	}
+}
+
+// Replace the default value of data integrity chunk size in bytes
+func setDataIntegrityChunkSize(chunkSize int64) dataIntegrityOption {
+	return func(this *dataIntegrity) {
+		this.chunkSize = chunkSize
+	}
+}
+
+// Replace the default value of data integrity raw block volume offset in bytes
+func setDataIntegrityBlockOffset(blockOffset int64) dataIntegrityOption {
+	return func(this *dataIntegrity) {
+		this.blockOffset = blockOffset
+	}
+}
+
+// Create a new customized dataIntegrity object, by default 4 files of 1MiB with a checksum per 256KiB
+func newDataIntegrity(opts ...dataIntegrityOption) *dataIntegrity {
+	defaultDataIntegrity := dataIntegrity{
+		seed:        time.Now().UnixNano(),
+		fileCount:   4,
+		fileSize:    1024 * 1024,
+		chunkSize:   256 * 1024,
+		blockOffset: 1024 * 1024,
+	}
+
+	for _, o := range opts {
+		o(&defaultDataIntegrity)
+	}
+
+	return &defaultDataIntegrity
+}
+
+// fileName returns the name of the data file with the index
+func (d *dataIntegrity) fileName(index int) string {
+	return fmt.Sprintf("integrity-%03d.bin", index)
+}
+
+// fileData returns the pseudo-random data of the file with the index
+func (d *dataIntegrity) fileData(index int) []byte {
+	data := make([]byte, d.fileSize)
+	rand.New(rand.NewSource(d.seed + int64(index))).Read(data)
+	return data
+}
+
+// chunkChecksums returns the SHA-256 checksums of the data by chunk
+func chunkChecksums(data []byte, chunkSize int64) []string {
+	checksums := []string{}
+	for start := int64(0); start < int64(len(data)); start += chunkSize {
+		end := min(start+chunkSize, int64(len(data)))
+		sum := sha256.Sum256(data[start:end])
+		checksums = append(checksums, hex.EncodeToString(sum[:]))
+	}
+	return checksums
+}
+
+// readChunkChecksumsCommand returns the command printing the checksums of the chunks of the file, or "missing" if it does not exist
+func readChunkChecksumsCommand(path string, skipChunks int64, chunks int, chunkSize int64) string {
+	return fmt.Sprintf(`if [ ! -e %[1]s ]; then echo missing; exit 0; fi; for i in $(seq %[2]d %[3]d); do dd if=%[1]s bs=%[4]d skip=$i count=1 2>/dev/null | sha256sum; done`,
+		path, skipChunks, skipChunks+int64(chunks)-1, chunkSize)
+}
+
+// parseChunkChecksums parses the output of the readChunkChecksumsCommand, the checksums are nil if the file is missing
+func parseChunkChecksums(output string) []string {
+	checksums := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "missing" {
+			return nil
+		}
+		checksums = append(checksums, fields[0])
+	}
+	return checksums
+}
+
+// compareChunkChecksums returns the chunks of the file whose checksums differ from the expected ones
+func compareChunkChecksums(file string, expected, actual []string) []dataIntegrityMismatch {
+	if actual == nil {
+		return []dataIntegrityMismatch{{File: file, Chunk: dataIntegrityMissingChunk}}
+	}
+	mismatches := []dataIntegrityMismatch{}
+	for i, checksum := range expected {
+		if i >= len(actual) {
+			mismatches = append(mismatches, dataIntegrityMismatch{File: file, Chunk: i, Expected: checksum, Actual: ""})
+		} else if actual[i] != checksum {
+			mismatches = append(mismatches, dataIntegrityMismatch{File: file, Chunk: i, Expected: checksum, Actual: actual[i]})
+		}
+	}
+	return mismatches
+}
+
+// writeIntoPod writes the data into the volume of the pod mounted on the path, or into the raw block device with the path,
+// and records the manifest
+func (d *dataIntegrity) writeIntoPod(oc *exutil.CLI, namespace string, podName string, volumeMode string, path string) {
+	o.Expect(d.blockOffset%d.chunkSize).To(o.BeZero(), "The data integrity block offset must be a multiple of the chunk size")
+	d.manifest = dataIntegrityManifest{Seed: d.seed, VolumeMode: volumeMode, ChunkSize: d.chunkSize, Files: map[string][]string{}}
+	e2e.Logf("Writing %d files of %d bytes generated with seed %d into the %s volume of pod %s/%s", d.fileCount, d.fileSize, d.seed, volumeMode, namespace, podName)
+
+	if volumeMode == "Block" {
+		data := []byte{}
+		for i := 0; i < d.fileCount; i++ {
+			data = append(data, d.fileData(i)...)
+		}
+		command := fmt.Sprintf("dd of=%s bs=%d seek=%d conv=notrunc 2>/dev/null && sync", path, d.chunkSize, d.blockOffset/d.chunkSize)
+		_, err := oc.WithoutNamespace().Run("exec").Args("-n", namespace, "-i", podName, "--", "/bin/sh", "-c", command).InputString(string(data)).Output()
+		o.Expect(err).NotTo(o.HaveOccurred(), "Failed to write the data integrity data into the raw block volume")
+		d.manifest.Files[path] = chunkChecksums(data, d.chunkSize)
+		return
+	}
+
+	for i := 0; i < d.fileCount; i++ {
+		file := path + "/" + d.fileName(i)
+		data := d.fileData(i)
+		_, err := oc.WithoutNamespace().Run("exec").Args("-n", namespace, "-i", podName, "--", "/bin/sh", "-c", "cat > "+file+" && sync -f "+file).InputString(string(data)).Output()
+		o.Expect(err).NotTo(o.HaveOccurred(), fmt.Sprintf("Failed to write the data integrity file %s", file))
+		d.manifest.Files[d.fileName(i)] = chunkChecksums(data, d.chunkSize)
+	}
+}
+
+// verifyInPod returns the files and the chunks of the volume of the pod which differ from the manifest
+func (d *dataIntegrity) verifyInPod(oc *exutil.CLI, namespace string, podName string, path string) []dataIntegrityMismatch {
+	o.Expect(d.manifest.Files).NotTo(o.BeEmpty(), "No data integrity data was written")
+	files := []string{}
+	for file := range d.manifest.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	mismatches := []dataIntegrityMismatch{}
+	for _, file := range files {
+		expected := d.manifest.Files[file]
+		target, skipChunks := path+"/"+file, int64(0)
+		if d.manifest.VolumeMode == "Block" {
+			target, skipChunks = path, d.blockOffset/d.chunkSize
+		}
+		output, err := execCommandInSpecificPod(oc, namespace, podName, readChunkChecksumsCommand(target, skipChunks, len(expected), d.chunkSize))
+		o.Expect(err).NotTo(o.HaveOccurred(), fmt.Sprintf("Failed to read the data integrity checksums of %s", target))
+		mismatches = append(mismatches, compareChunkChecksums(file, expected, parseChunkChecksums(output))...)
+	}
+	return mismatches
+}
+
+// checkInPod checks the data of the volume of the pod is the same as the manifest, the failure reports every file and
+// chunk which differs
+func (d *dataIntegrity) checkInPod(oc *exutil.CLI, namespace string, podName string, path string) {
+	mismatches := d.verifyInPod(oc, namespace, podName, path)
+	report := []string{}
+	for _, mismatch := range mismatches {
+		report = append(report, mismatch.String())
+	}
+	o.Expect(mismatches).To(o.BeEmpty(), fmt.Sprintf("The data of the volume of pod %s/%s differs from the data integrity manifest of seed %d:\n%s",
+		namespace, podName, d.seed, strings.Join(report, "\n")))
+	e2e.Logf("The data of the volume of pod %s/%s is the same as the data integrity manifest of seed %d", namespace, podName, d.seed)
+}
+
+// Write the data integrity data into the pod volume
+func (po *pod) writeDataIntegrity(oc *exutil.CLI, d *dataIntegrity) {
+	volumeMode := "Filesystem"
+	if po.volumeType == "volumeDevices" {
+		volumeMode = "Block"
+	}
+	d.writeIntoPod(oc, po.namespace, po.name, volumeMode, po.mountPath)
+}
+
+// Check the data integrity of the pod volume
+func (po *pod) checkDataIntegrity(oc *exutil.CLI, d *dataIntegrity) {
+	d.checkInPod(oc, po.namespace, po.name, po.mountPath)
+}
+
+// Write the data integrity data into the volume of the deployment first pod
+func (dep *deployment) writeDataIntegrity(oc *exutil.CLI, d *dataIntegrity) {
+	volumeMode := "Filesystem"
+	if dep.volumetype == "volumeDevices" {
+		volumeMode = "Block"
+	}
+	d.writeIntoPod(oc, dep.namespace, dep.getPodList(oc)[0], volumeMode, dep.mpath)
+}
+
+// Check the data integrity of the volume of the deployment first pod
+func (dep *deployment) checkDataIntegrity(oc *exutil.CLI, d *dataIntegrity) {
+	d.checkInPod(oc, dep.namespace, dep.getPodList(oc)[0], dep.mpath)
+}
@@ -521,7 +521,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-44905 - [CSI-Driver] [Dynamic PV] [block volume] volumes should store data
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-LEVEL0-Critical-44905-[CSI-Driver] [Dynamic PV] [block volume] volumes should store data", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io", "diskplugin.csi.alibabacloud.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.supportsVolumeMode("Block") })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -780,7 +780,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-46358 - [CSI-Driver] [CSI Clone] Clone a pvc with filesystem VolumeMode
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-LEVEL0-Critical-46358-[CSI-Driver] [CSI Clone] Clone a pvc with filesystem VolumeMode", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"disk.csi.azure.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io", "file.csi.azure.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Cloning && c.supportsVolumeMode("Filesystem") })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -929,7 +929,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-46813 - [CSI-Driver] [CSI Clone] Clone a pvc with Raw Block VolumeMode
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-LEVEL0-Critical-46813-[CSI-Driver][CSI Clone] Clone a pvc with Raw Block VolumeMode", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"disk.csi.azure.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Cloning && c.supportsVolumeMode("Block") })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -1188,7 +1188,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 			exutil.By("# Get the volumename, volumeID")
 			volumeName := pvc.getVolumeName(oc)
 			volumeID := pvc.getVolumeID(oc)
-			defer deleteBackendVolumeByVolumeID(oc, volumeID)
+			defer deleteBackendVolumeByVolumeID(oc, provisioner, volumeID)
 
 			exutil.By("# Delete the pvc and check the pv is deleted accordingly")
 			pvc.delete(oc)
@@ -1196,7 +1196,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 
 			exutil.By("# Check the volume on backend is deleted")
 			getCredentialFromCluster(oc)
-			waitVolumeDeletedOnBackend(oc, volumeID)
+			waitVolumeDeletedOnBackend(oc, provisioner, volumeID)
 
 			exutil.By("******" + cloudProvider + " csi driver: \"" + provisioner + "\" test phase finished" + "******")
 		}
@@ -1268,7 +1268,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 				volumeName := pvc.getVolumeName(oc)
 				defer oc.AsAdmin().WithoutNamespace().Run("delete").Args("pv", volumeName).Execute()
 				volumeID := pvc.getVolumeID(oc)
-				defer deleteBackendVolumeByVolumeID(oc, volumeID)
+				defer deleteBackendVolumeByVolumeID(oc, provisioner, volumeID)
 				originNodeName := getNodeNameByPod(oc, pod.namespace, pod.name)
 
 				exutil.By("# Check the pod volume can be read and write")
@@ -1295,7 +1295,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 
 				exutil.By("# Check the volume still exists in backend by volumeID")
 				getCredentialFromCluster(oc)
-				waitVolumeAvailableOnBackend(oc, volumeID)
+				waitVolumeAvailableOnBackend(oc, provisioner, volumeID)
 
 				exutil.By("# Use the retained volume create new pv,pvc,pod and wait for the pod running")
 				newPvName := "newpv-" + getRandomString()
@@ -1316,7 +1316,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 				deleteSpecifiedResource(oc, "pod", newpod.name, newpod.namespace)
 				deleteSpecifiedResource(oc, "pvc", newpvc.name, newpvc.namespace)
 				waitForPersistentVolumeStatusAsExpected(oc, newPvName, "deleted")
-				waitVolumeDeletedOnBackend(oc, volumeID)
+				waitVolumeDeletedOnBackend(oc, provisioner, volumeID)
 
 				exutil.By("******" + cloudProvider + " csi driver: \"" + provisioner + "\" test phase finished" + "******")
 			}()
@@ -1327,7 +1327,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// [CSI-Driver] [Dynamic PV] [Filesystem default] volumes resize on-line
 	g.It("ROSA-OSD_CCS-ARO-Author:ropatil-Critical-45984-[CSI-Driver] [Dynamic PV] [Filesystem default] volumes resize on-line", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "file.csi.azure.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io", "diskplugin.csi.alibabacloud.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Expansion.Online && c.supportsVolumeMode("Filesystem") })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -1343,6 +1343,11 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 		oc.SetupProject() //create new project
 		for _, provisioner = range supportProvisioners {
 			exutil.By("******" + cloudProvider + " csi driver: \"" + provisioner + "\" test phase start" + "******")
+			// Record the volume lifecycle latencies of the provisioner, the thresholds are only checked if STORAGE_VOLUME_LATENCY_CHECK is set
+			latency := newVolumeLatencyRecorder(oc, oc.Namespace(), provisioner)
+			latency.start()
+			defer latency.checkThresholds()
+			defer latency.stop()
 			// Set the resource definition for the scenario
 			pvc := newPersistentVolumeClaim(setPersistentVolumeClaimTemplate(pvcTemplate), setPersistentVolumeClaimStorageClassName(getPresetStorageClassNameByProvisioner(oc, cloudProvider, provisioner)))
 			dep := newDeployment(setDeploymentTemplate(deploymentTemplate), setDeploymentPVCName(pvc.name))
@@ -1454,7 +1459,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// [CSI-Driver] [Dynamic PV] [Raw Block] volumes resize on-line
 	g.It("ROSA-OSD_CCS-ARO-Author:ropatil-LEVEL0-Critical-45985-[CSI-Driver] [Dynamic PV] [Raw block] volumes resize on-line", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io", "diskplugin.csi.alibabacloud.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Expansion.Online && c.supportsVolumeMode("Block") })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -1487,7 +1492,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// [CSI-Driver] [Dynamic PV] [Filesystem default] volumes resize off-line
 	g.It("ROSA-OSD_CCS-ARO-Author:ropatil-Critical-41452-[CSI-Driver] [Dynamic PV] [Filesystem default] volumes resize off-line", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"disk.csi.azure.com", "csi.vsphere.vmware.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Expansion.Offline && c.supportsVolumeMode("Filesystem") })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -1614,7 +1619,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// [CSI-Driver] [Dynamic PV] [Raw block] volumes resize off-line
 	g.It("ROSA-OSD_CCS-ARO-Author:ropatil-Critical-44902-[CSI-Driver] [Dynamic PV] [Raw block] volumes resize off-line", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"disk.csi.azure.com", "csi.vsphere.vmware.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Expansion.Offline && c.supportsVolumeMode("Block") })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -1860,7 +1865,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-48911 - [CSI-Driver] [fsgroup] should be updated with new defined value when volume attach to another pod
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-Critical-48911-[CSI-Driver] [fsgroup] should be updated with new defined value when volume attach to another pod", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "file.csi.azure.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io", "diskplugin.csi.alibabacloud.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.FsGroup })
 		supportProvisioners := sliceIntersect(scenarioSupportProvisioners, cloudProviderSupportProvisioners)
 		if len(supportProvisioners) == 0 {
 			g.Skip("Skip for scenario non-supported provisioner!!!")
@@ -1933,7 +1938,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-47879 - [CSI-Driver] [Snapshot] [Filesystem default] provisioning should provision storage with snapshot data source and restore it successfully
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-Critical-47879-[CSI-Driver] [Snapshot] [Filesystem default] provisioning should provision storage with snapshot data source and restore it successfully", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "file.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io", "filestore.csi.storage.gke.io"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Snapshot && c.supportsVolumeMode("Filesystem") })
 		supportProvisioners := sliceIntersect(scenarioSupportProvisioners, cloudProviderSupportProvisioners)
 		if len(supportProvisioners) == 0 {
 			g.Skip("Skip for scenario non-supported provisioner!!!")
@@ -1972,6 +1977,11 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 		oc.SetupProject() //create new project
 		for _, provisioner = range supportProvisioners {
 			exutil.By("******" + cloudProvider + " csi driver: \"" + provisioner + "\" test phase start" + "******")
+			// Record the volume lifecycle latencies of the provisioner, the thresholds are only checked if STORAGE_VOLUME_LATENCY_CHECK is set
+			latency := newVolumeLatencyRecorder(oc, oc.Namespace(), provisioner)
+			latency.start()
+			defer latency.checkThresholds()
+			defer latency.stop()
 			// Set the resource definition for the original
 			pvcOri := newPersistentVolumeClaim(setPersistentVolumeClaimTemplate(pvcTemplate))
 			podOri := newPod(setPodTemplate(podTemplate), setPodPersistentVolumeClaim(pvcOri.name))
@@ -2168,10 +2178,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-47931 - [CSI-Driver] [Snapshot] [Filesystem xfs] provisioning should provision storage with snapshot data source and restore it successfully
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-LEVEL0-Critical-47931-[CSI-Driver] [Snapshot] [Filesystem xfs] provisioning should provision storage with snapshot data source and restore it successfully", func() {
 		// Define the test scenario support provisioners
-		// Temporarily remove "vpc.block.csi.ibm.io" as known issue OCPBUGS-16920 [ibm-vpc-block-csi-driver] xfs volume snapshot volume mount failed of "Filesystem has duplicate UUID" to dismiss CI noise
-		// TODO: Revert the commit when the known issue solved
-		// scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io"}
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "csi.vsphere.vmware.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Snapshot && strSliceContains(c.FsTypes, "xfs") })
 		supportProvisioners := sliceIntersect(scenarioSupportProvisioners, cloudProviderSupportProvisioners)
 		if len(supportProvisioners) == 0 {
 			g.Skip("Skip for scenario non-supported provisioner!!!")
@@ -2296,7 +2303,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-48723 - [CSI-Driver] [Snapshot] [Block] provisioning should provision storage with snapshot data source and restore it successfully
 	g.It("ROSA-OSD_CCS-ARO-Author:chaoyang-LEVEL0-Critical-48723-[CSI-Driver] [Snapshot] [block] provisioning should provision storage with snapshot data source and restore it successfully", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.Snapshot && c.supportsVolumeMode("Block") })
 		supportProvisioners := sliceIntersect(scenarioSupportProvisioners, cloudProviderSupportProvisioners)
 		if len(supportProvisioners) == 0 {
 			g.Skip("Skip for scenario non-supported provisioner!!!")
@@ -2964,7 +2971,9 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// Known issue(BZ2073617) for ibm CSI Driver
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-Critical-37570-[CSI-Driver][Dynamic PV][FileSystem] topology should provision a volume and schedule a pod with AllowedTopologies", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "filestore.csi.storage.gke.io", "csi.vsphere.vmware.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool {
+			return len(c.TopologyKeys) > 0 && c.supportsVolumeMode("Filesystem")
+		})
 		supportProvisioners := sliceIntersect(scenarioSupportProvisioners, cloudProviderSupportProvisioners)
 		if len(supportProvisioners) == 0 {
 			g.Skip("Skip for scenario non-supported provisioner!!!")
@@ -3056,7 +3065,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// Known issue(BZ2073617) for ibm CSI Driver
 	g.It("ROSA-OSD_CCS-ARO-Author:wduan-LEVEL0-Critical-50202-[CSI-Driver][Dynamic PV][Block] topology should provision a volume and schedule a pod with AllowedTopologies", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "vpc.block.csi.ibm.io", "csi.vsphere.vmware.com"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return len(c.TopologyKeys) > 0 && c.supportsVolumeMode("Block") })
 		supportProvisioners := sliceIntersect(scenarioSupportProvisioners, cloudProviderSupportProvisioners)
 		if len(supportProvisioners) == 0 {
 			g.Skip("Skip for scenario non-supported provisioner!!!")
@@ -3927,7 +3936,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 				checkVolumeDetachedFromNode(oc, pvName, originNodeName)
 				getCredentialFromCluster(oc)
 				// Temp enchancement for the retain volume clean up
-				defer deleteBackendVolumeByVolumeID(oc, volumeID)
+				defer deleteBackendVolumeByVolumeID(oc, provisioner, volumeID)
 				// The reclaimPolicy:Retain is used for pv object(accually is real backend volume)
 				// PVC should be also deleted by Kubernetes garbage collector
 				checkResourcesNotExist(oc, "pvc", pvcName, dep.namespace)
@@ -3943,7 +3952,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 				checkVolumeNotMountOnNode(oc, pvName, originNodeName)
 
 				exutil.By("# Check the volume still exists in backend by volumeID")
-				waitVolumeAvailableOnBackend(oc, volumeID)
+				waitVolumeAvailableOnBackend(oc, provisioner, volumeID)
 
 				exutil.By("# Use the retained volume create new pv,pvc,pod and wait for the pod running")
 				newPvName := "newpv-" + getRandomString()
@@ -3964,8 +3973,8 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 				newpod.delete(oc)
 				newpvc.delete(oc)
 				deleteSpecifiedResource(oc.AsAdmin(), "pv", newPvName, "")
-				deleteBackendVolumeByVolumeID(oc, volumeID)
-				waitVolumeDeletedOnBackend(oc, volumeID)
+				deleteBackendVolumeByVolumeID(oc, provisioner, volumeID)
+				waitVolumeDeletedOnBackend(oc, provisioner, volumeID)
 
 				exutil.By("******" + cloudProvider + " csi driver: \"" + provisioner + "\" test phase finished" + "******")
 			}()
@@ -4618,7 +4627,7 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 	// OCP-57148 [CSI Driver] Attachable volume number on each node should obey CSINode allocatable count for different instance types
 	g.It("NonHyperShiftHOST-ROSA-OSD_CCS-ARO-Longduration-NonPreRelease-Author:jiasun-High-57148-[CSI Driver] Attachable volume number on each node should obey CSINode allocatable count for different instance types [Serial]", func() {
 		// Define the test scenario support provisioners
-		scenarioSupportProvisioners := []string{"ebs.csi.aws.com", "disk.csi.azure.com", "vpc.block.csi.ibm.io", "csi.vsphere.vmware.com", "pd.csi.storage.gke.io"}
+		scenarioSupportProvisioners := getCSIDriverProvisionersWith(func(c csiDriverCapabilities) bool { return c.MaxVolumesPerNode > 0 })
 		// Set the resource template for the scenario
 		var (
 			storageTeamBaseDir  = exutil.FixturePath("testdata", "storage")
@@ -6082,8 +6091,8 @@ var _ = g.Describe("[sig-storage] STORAGE", func() {
 			pvName := pvc1.getVolumeName(oc)
 			volumeID := pvc1.getVolumeID(oc)
 			defer func() {
-				deleteBackendVolumeByVolumeID(oc, volumeID)
-				waitVolumeDeletedOnBackend(oc, volumeID)
+				deleteBackendVolumeByVolumeID(oc, provisioner, volumeID)
+				waitVolumeDeletedOnBackend(oc, provisioner, volumeID)
 			}()
 			defer deleteSpecifiedResource(oc.AsAdmin(), "pv", pvName, "")
 
@@ -6293,6 +6302,8 @@ func resizeOnlineCommonTestSteps(oc *exutil.CLI, pvc persistentVolumeClaim, dep
 	} else {
 		dep.writeDataBlockType(oc)
 	}
+	integrity := newDataIntegrity()
+	dep.writeDataIntegrity(oc, integrity)
 
 	exutil.By("#. Apply the patch to Resize the pvc volume")
 	capacityInt64, err := strconv.ParseInt(strings.TrimRight(pvc.capacity, "Gi"), 10, 64)
@@ -6307,6 +6318,7 @@ func resizeOnlineCommonTestSteps(oc *exutil.CLI, pvc persistentVolumeClaim, dep
 	pvc.waitResizeSuccess(oc, pvc.capacity)
 
 	exutil.By("#. Check origin data intact and write new data in pod")
+	dep.checkDataIntegrity(oc, integrity)
 	if dep.typepath == "mountPath" {
 		dep.checkPodMountedVolumeDataExist(oc, true)
 		// After volume expand write data more than the old capacity should succeed
@@ -6346,6 +6358,8 @@ func resizeOfflineCommonTestSteps(oc *exutil.CLI, pvc persistentVolumeClaim, dep
 	} else {
 		dep.writeDataBlockType(oc)
 	}
+	integrity := newDataIntegrity()
+	dep.writeDataIntegrity(oc, integrity)
 
 	exutil.By("#. Get the volume mounted on the pod located node and Scale down the replicas number to 0")
 	volName := pvc.getVolumeName(oc)
@@ -6383,6 +6397,7 @@ func resizeOfflineCommonTestSteps(oc *exutil.CLI, pvc persistentVolumeClaim, dep
 	pvc.waitResizeSuccess(oc, pvc.capacity)
 
 	exutil.By("#. Check origin data intact and write new data in pod")
+	dep.checkDataIntegrity(oc, integrity)
 	if dep.typepath == "mountPath" {
 		dep.checkPodMountedVolumeDataExist(oc, true)
 		// After volume expand write data more than the old capacity should succeed
@@ -1,12 +1,18 @@
 package storage
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
 
@@ -88,3 +94,232 @@ func TestDebugNodeUtils(t *testing.T) {
 	as.Nil(outputError)
 	e2e.Logf("************************************************")
 }
+
+// TestFakeVolumeBackend tests the generic backend volume helpers with the fake volume backend
+func TestFakeVolumeBackend(t *testing.T) {
+	o.RegisterFailHandler(g.Fail)
+	var (
+		as      = assert.New(t)
+		backend = newFakeVolumeBackend()
+	)
+
+	backend.addVolume(backendVolume{ID: "vol-1", SizeGiB: 10, Type: "gp3", Iops: 3000, Throughput: 125, Encrypted: true, Zone: "us-east-1a", AttachedTo: []string{"i-1"}})
+	backend.addSnapshot("snap-1")
+
+	volume := getBackendVolume(backend, "vol-1")
+	as.Equal(int64(10), volume.SizeGiB)
+	as.Equal("gp3", volume.Type)
+	as.True(volume.isAttached())
+
+	missing, err := backend.GetVolume("vol-2")
+	as.Nil(err)
+	as.Nil(missing)
+
+	exists, err := backend.SnapshotExists("snap-1")
+	as.Nil(err)
+	as.True(exists)
+
+	// Detach the volume while waiting for it
+	go func() {
+		detached := *volume
+		detached.AttachedTo = nil
+		detached.Available = true
+		backend.addVolume(detached)
+	}()
+	waitBackendVolumeAttachState(backend, "vol-1", false)
+	waitBackendVolumeAvailable(backend, "vol-1")
+
+	as.Nil(backend.DeleteVolume("vol-1"))
+	as.NotNil(backend.DeleteVolume("vol-1"))
+	waitBackendVolumeDeleted(backend, "vol-1")
+}
+
+// TestPdVolumeLocation tests parsing the gcp pd volume handles
+func TestPdVolumeLocation(t *testing.T) {
+	var as = assert.New(t)
+
+	name, scope, location := pdVolumeLocation("projects/my-project/zones/us-central1-a/disks/pvc-1")
+	as.Equal([]string{"pvc-1", "zone", "us-central1-a"}, []string{name, scope, location})
+	name, scope, location = pdVolumeLocation("projects/my-project/regions/us-central1/disks/pvc-2")
+	as.Equal([]string{"pvc-2", "region", "us-central1"}, []string{name, scope, location})
+	name, scope, _ = pdVolumeLocation("pvc-3")
+	as.Equal([]string{"pvc-3", ""}, []string{name, scope})
+}
+
+// TestCSIDriverCapabilities tests the csi driver capability manifests select the provisioners the general csi cases support
+func TestCSIDriverCapabilities(t *testing.T) {
+	var as = assert.New(t)
+
+	drivers, err := loadCSIDriverCapabilities(filepath.Join("..", "testdata", "storage", "csi-driver-capabilities"))
+	as.Nil(err)
+	as.NotEmpty(drivers)
+
+	// The provisioners of a case are the drivers declaring the capabilities it needs, without a known issue for the case
+	as.ElementsMatch([]string{"ebs.csi.aws.com", "file.csi.azure.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io", "diskplugin.csi.alibabacloud.com"},
+		filterCSIDriverProvisioners(drivers, "45984", func(c csiDriverCapabilities) bool { return c.Expansion.Online && c.supportsVolumeMode("Filesystem") }))
+	as.ElementsMatch([]string{"disk.csi.azure.com", "csi.vsphere.vmware.com"},
+		filterCSIDriverProvisioners(drivers, "44902", func(c csiDriverCapabilities) bool { return c.Expansion.Offline && c.supportsVolumeMode("Block") }))
+	as.ElementsMatch([]string{"disk.csi.azure.com", "cinder.csi.openstack.org", "pd.csi.storage.gke.io"},
+		filterCSIDriverProvisioners(drivers, "46813", func(c csiDriverCapabilities) bool { return c.Cloning && c.supportsVolumeMode("Block") }))
+	as.ElementsMatch([]string{"ebs.csi.aws.com", "disk.csi.azure.com", "file.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "csi.vsphere.vmware.com", "vpc.block.csi.ibm.io", "filestore.csi.storage.gke.io"},
+		filterCSIDriverProvisioners(drivers, "47879", func(c csiDriverCapabilities) bool { return c.Snapshot && c.supportsVolumeMode("Filesystem") }))
+	as.ElementsMatch([]string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "csi.vsphere.vmware.com"},
+		filterCSIDriverProvisioners(drivers, "47931", func(c csiDriverCapabilities) bool { return c.Snapshot && strSliceContains(c.FsTypes, "xfs") }))
+	as.ElementsMatch([]string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "filestore.csi.storage.gke.io", "csi.vsphere.vmware.com"},
+		filterCSIDriverProvisioners(drivers, "37570", func(c csiDriverCapabilities) bool {
+			return len(c.TopologyKeys) > 0 && c.supportsVolumeMode("Filesystem")
+		}))
+	as.ElementsMatch([]string{"ebs.csi.aws.com", "disk.csi.azure.com", "pd.csi.storage.gke.io", "diskplugin.csi.alibabacloud.com", "vpc.block.csi.ibm.io", "csi.vsphere.vmware.com"},
+		filterCSIDriverProvisioners(drivers, "50202", func(c csiDriverCapabilities) bool { return len(c.TopologyKeys) > 0 && c.supportsVolumeMode("Block") }))
+	as.ElementsMatch([]string{"ebs.csi.aws.com", "disk.csi.azure.com", "vpc.block.csi.ibm.io", "csi.vsphere.vmware.com", "pd.csi.storage.gke.io"},
+		filterCSIDriverProvisioners(drivers, "57148", func(c csiDriverCapabilities) bool { return c.MaxVolumesPerNode > 0 }))
+
+	invalid := csiDriverCapabilities{Provisioner: "fake.csi.io", Platforms: []string{"aws"}, VolumeModes: []string{"Block"}, FsTypes: []string{"ext4"}}
+	as.NotNil(invalid.validate())
+}
+
+// TestDataIntegrity tests the data integrity data generation and checksum comparison
+func TestDataIntegrity(t *testing.T) {
+	var (
+		as        = assert.New(t)
+		integrity = newDataIntegrity(setDataIntegritySeed(42), setDataIntegrityFileSize(1000), setDataIntegrityChunkSize(256))
+	)
+
+	// The same seed always generates the same data, and every file gets different data
+	as.Equal(integrity.fileData(1), newDataIntegrity(setDataIntegritySeed(42), setDataIntegrityFileSize(1000)).fileData(1))
+	as.NotEqual(integrity.fileData(0), integrity.fileData(1))
+
+	checksums := chunkChecksums(integrity.fileData(0), integrity.chunkSize)
+	as.Len(checksums, 4)
+	as.Equal(checksums, chunkChecksums(integrity.fileData(0), integrity.chunkSize))
+	as.Equal([]string{"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}, chunkChecksums([]byte("hello"), 256))
+
+	output := checksums[0] + "  -\n" + checksums[1] + "  -\n" + "0000  -\n"
+	as.Equal([]dataIntegrityMismatch{
+		{File: "integrity-000.bin", Chunk: 2, Expected: checksums[2], Actual: "0000"},
+		{File: "integrity-000.bin", Chunk: 3, Expected: checksums[3], Actual: ""},
+	}, compareChunkChecksums("integrity-000.bin", checksums, parseChunkChecksums(output)))
+	as.Empty(compareChunkChecksums("integrity-000.bin", checksums, checksums))
+
+	missing := compareChunkChecksums("integrity-001.bin", checksums, parseChunkChecksums("missing\n"))
+	as.Equal([]dataIntegrityMismatch{{File: "integrity-001.bin", Chunk: dataIntegrityMissingChunk}}, missing)
+	as.Equal("integrity-001.bin: missing", missing[0].String())
+
+	as.Equal(`if [ ! -e /dev/dblock ]; then echo missing; exit 0; fi; for i in $(seq 4 7); do dd if=/dev/dblock bs=256 skip=$i count=1 2>/dev/null | sha256sum; done`,
+		readChunkChecksumsCommand("/dev/dblock", 4, 4, 256))
+}
+
+// TestVolumeLatencyRecorder tests measuring the volume operation latencies from the observed objects
+func TestVolumeLatencyRecorder(t *testing.T) {
+	o.RegisterFailHandler(g.Fail)
+	var (
+		as           = assert.New(t)
+		start        = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		now          = start
+		storageClass = "gp3-csi"
+		recorder     = newVolumeLatencyRecorder(nil, "e2e", ebsCsiDriverProvisioner)
+	)
+	recorder.now = func() time.Time { return now }
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "mypvc", Namespace: "e2e", CreationTimestamp: metav1.NewTime(start)},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	recorder.observePersistentVolumeClaim(pvc.DeepCopy(), false)
+
+	// The WaitForFirstConsumer volume provisioning starts when the pod is scheduled
+	now = start.Add(10 * time.Second)
+	pvc.Annotations = map[string]string{"volume.kubernetes.io/selected-node": "worker-0"}
+	recorder.observePersistentVolumeClaim(pvc.DeepCopy(), false)
+	now = start.Add(15 * time.Second)
+	pvc.Spec.VolumeName = "pv-1"
+	pvc.Status = corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound, Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}}
+	recorder.observePersistentVolumeClaim(pvc.DeepCopy(), false)
+
+	pvName := "pv-1"
+	va := &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-1", CreationTimestamp: metav1.NewTime(start.Add(16 * time.Second))},
+		Spec:       storagev1.VolumeAttachmentSpec{NodeName: "worker-0", Source: storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName}},
+	}
+	recorder.observeVolumeAttachment(va.DeepCopy(), false)
+	now = start.Add(20 * time.Second)
+	va.Status.Attached = true
+	recorder.observeVolumeAttachment(va.DeepCopy(), false)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "mypod", Namespace: "e2e"},
+		Spec:       corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "mypvc"}}}}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(start.Add(10 * time.Second))},
+			{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+		}},
+	}
+	now = start.Add(30 * time.Second)
+	recorder.observePod(pod)
+
+	// Resize the volume
+	now = start.Add(40 * time.Second)
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("2Gi")
+	recorder.observePersistentVolumeClaim(pvc.DeepCopy(), false)
+	now = start.Add(400 * time.Second)
+	pvc.Status.Capacity[corev1.ResourceStorage] = resource.MustParse("2Gi")
+	recorder.observePersistentVolumeClaim(pvc.DeepCopy(), false)
+
+	// Detach and delete the volume
+	now = start.Add(410 * time.Second)
+	va.DeletionTimestamp = &metav1.Time{Time: now}
+	recorder.observeVolumeAttachment(va.DeepCopy(), false)
+	now = start.Add(415 * time.Second)
+	recorder.observeVolumeAttachment(va.DeepCopy(), true)
+	recorder.observePersistentVolumeClaim(pvc.DeepCopy(), true)
+	now = start.Add(425 * time.Second)
+	recorder.observePersistentVolume(&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}}, true)
+
+	latencies := map[volumeLatencyOperation]time.Duration{}
+	for _, latency := range recorder.latencies {
+		as.Equal(storageClass, latency.storageClass)
+		latencies[latency.operation] = latency.duration()
+	}
+	as.Equal(map[volumeLatencyOperation]time.Duration{
+		volumeLatencyProvision: 5 * time.Second,
+		volumeLatencyAttach:    4 * time.Second,
+		volumeLatencyMount:     20 * time.Second,
+		volumeLatencyResize:    360 * time.Second,
+		volumeLatencyDetach:    5 * time.Second,
+		volumeLatencyDelete:    10 * time.Second,
+	}, latencies)
+
+	// The resize is above the default threshold
+	exceeded := recorder.exceededThresholds()
+	as.Len(exceeded, 1)
+	as.Equal(volumeLatencyResize, exceeded[0].operation)
+	intervals := recorder.intervals()
+	as.Len(intervals, 6)
+	as.Equal("provisioner/ebs.csi.aws.com storageclass/gp3-csi operation/provision", intervals[0].Locator)
+	// The exceeded thresholds don't fail the case unless the check is enabled
+	t.Setenv(volumeLatencyCheckEnv, "")
+	recorder.checkThresholds()
+
+	// The pvcs of the other provisioners are measured by their own recorder
+	otherStorageClass := "azurefile-csi"
+	recorder.observePersistentVolumeClaim(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "otherpvc", Namespace: "e2e", CreationTimestamp: metav1.NewTime(start),
+			Annotations: map[string]string{"volume.kubernetes.io/storage-provisioner": azureFileCsiDriverProvisioner}},
+		Spec:   corev1.PersistentVolumeClaimSpec{StorageClassName: &otherStorageClass, VolumeName: "pv-2"},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}, false)
+	as.Len(recorder.latencies, 6)
+
+	t.Setenv(volumeLatencyThresholdsEnv, `{"ebs.csi.aws.com": {"resize": "10m"}}`)
+	thresholds, err := getVolumeLatencyThresholds(ebsCsiDriverProvisioner)
+	as.Nil(err)
+	as.Equal(10*time.Minute, thresholds[volumeLatencyResize])
+	as.Equal(2*time.Minute, thresholds[volumeLatencyProvision])
+	t.Setenv(volumeLatencyThresholdsEnv, `{"ebs.csi.aws.com": {"resizing": "10m"}}`)
+	_, err = getVolumeLatencyThresholds(ebsCsiDriverProvisioner)
+	as.NotNil(err)
+}
@@ -0,0 +1,575 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/vmware/govmomi/cns"
+	cnstypes "github.com/vmware/govmomi/cns/types"
+
+	g "github.com/onsi/ginkgo/v2"
+	o "github.com/onsi/gomega"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// errBackendNotSupported is returned for the csi drivers without a volume backend
+var errBackendNotSupported = errors.New("the volume backend is not supported for the csi driver")
+
+// backendVolume is the provider independent view of a volume on the storage backend
+type backendVolume struct {
+	ID      string
+	SizeGiB int64
+	// Type is the provider volume type, i.e. "gp3" on aws, "pd-ssd" on gcp or "Premium_LRS" on azure
+	Type string
+	// Iops and Throughput(MiB/s) are the provisioned performance, 0 if the volume type has no provisioned performance
+	Iops       int64
+	Throughput int64
+	Encrypted  bool
+	// KmsKeyID is the customer managed key of the volume, empty if it uses the provider managed key
+	KmsKeyID string
+	Zone     string
+	// Available is whether the volume is ready to be attached, i.e. in "available" state on aws
+	Available bool
+	// AttachedTo are the instances the volume is attached to
+	AttachedTo []string
+}
+
+// isAttached returns whether the volume is attached to any instance
+func (v *backendVolume) isAttached() bool {
+	return len(v.AttachedTo) > 0
+}
+
+// VolumeBackend verifies the state of the volumes and snapshots on the storage backend of the cloud provider,
+// so the csi tests could check the backend generically whatever the provider is
+type VolumeBackend interface {
+	// GetVolume returns the volume by the csi volume handle, nil if the volume does not exist
+	GetVolume(volumeID string) (*backendVolume, error)
+	// SnapshotExists returns whether the snapshot exists by the csi snapshot handle
+	SnapshotExists(snapshotID string) (bool, error)
+	// DeleteVolume deletes the volume by the csi volume handle
+	DeleteVolume(volumeID string) error
+	String() string
+}
+
+// getVolumeBackend returns the volume backend of the block volumes provisioned by the csi driver,
+// the file based csi drivers have no volume backend
+func getVolumeBackend(oc *exutil.CLI, provisioner string) (VolumeBackend, error) {
+	switch provisioner {
+	case "ebs.csi.aws.com":
+		getCredentialFromCluster(oc)
+		return &awsVolumeBackend{ec2Client: newAwsClient()}, nil
+	case "pd.csi.storage.gke.io":
+		return &gcpVolumeBackend{gcloud: getgcloudClient(oc)}, nil
+	case "disk.csi.azure.com":
+		return &azureVolumeBackend{clientSet: exutil.NewAzureClientSetWithRootCreds(oc)}, nil
+	case "csi.vsphere.vmware.com":
+		return &vsphereVolumeBackend{oc: oc}, nil
+	case "vpc.block.csi.ibm.io":
+		apiKey, _, region, err := exutil.GetIBMCredentialFromCluster(oc)
+		if err != nil {
+			return nil, err
+		}
+		session, err := exutil.NewIBMSessionFromEnv(apiKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := exutil.SetVPCServiceURLForRegion(session, region); err != nil {
+			return nil, err
+		}
+		return &ibmVolumeBackend{session: session}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errBackendNotSupported, provisioner)
+	}
+}
+
+// newVolumeBackend returns the volume backend of the csi driver, skips the test if it is not supported
+func newVolumeBackend(oc *exutil.CLI, provisioner string) VolumeBackend {
+	backend, err := getVolumeBackend(oc, provisioner)
+	if errors.Is(err, errBackendNotSupported) {
+		g.Skip("Skip for the volume backend is not supported for " + provisioner)
+	}
+	o.Expect(err).NotTo(o.HaveOccurred(), "Failed to init the volume backend")
+	return backend
+}
+
+// getBackendVolume gets the volume from the backend and expects it exists
+func getBackendVolume(backend VolumeBackend, volumeID string) *backendVolume {
+	volume, err := backend.GetVolume(volumeID)
+	o.Expect(err).NotTo(o.HaveOccurred(), fmt.Sprintf("Failed to get the volume %s on %s backend", volumeID, backend))
+	o.Expect(volume).NotTo(o.BeNil(), fmt.Sprintf("The volume %s does not exist on %s backend", volumeID, backend))
+	debugLogf("The volume %s on %s backend is %+v", volumeID, backend, *volume)
+	return volume
+}
+
+// waitBackendVolumeAttachState waits until the volume is attached to or detached from all the instances on the backend
+func waitBackendVolumeAttachState(backend VolumeBackend, volumeID string, attached bool) {
+	err := wait.Poll(defaultIntervalTime, defaultMaxWaitingTime, func() (bool, error) {
+		volume, err := backend.GetVolume(volumeID)
+		if err != nil {
+			e2e.Logf("Failed to get the volume %s on %s backend: %v, try again", volumeID, backend, err)
+			return false, nil
+		}
+		if volume == nil {
+			return false, fmt.Errorf("the volume %s does not exist on %s backend", volumeID, backend)
+		}
+		e2e.Logf("The volume %s is attached to %v on %s backend", volumeID, volume.AttachedTo, backend)
+		return volume.isAttached() == attached, nil
+	})
+	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("The volume %s attached state is not %t on %s backend", volumeID, attached, backend))
+}
+
+// waitBackendVolumeAvailable waits until the volume is available on the backend
+func waitBackendVolumeAvailable(backend VolumeBackend, volumeID string) {
+	err := wait.Poll(defaultIntervalTime, defaultMaxWaitingTime, func() (bool, error) {
+		volume, err := backend.GetVolume(volumeID)
+		if err != nil {
+			e2e.Logf("Failed to get the volume %s on %s backend: %v, try again", volumeID, backend, err)
+			return false, nil
+		}
+		if volume == nil {
+			return false, fmt.Errorf("the volume %s does not exist on %s backend", volumeID, backend)
+		}
+		e2e.Logf("The volume %s available state is %t on %s backend", volumeID, volume.Available, backend)
+		return volume.Available, nil
+	})
+	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("The volume %s is not available on %s backend", volumeID, backend))
+}
+
+// waitBackendVolumeDeleted waits until the volume does not exist on the backend
+func waitBackendVolumeDeleted(backend VolumeBackend, volumeID string) {
+	err := wait.Poll(defaultIntervalTime, defaultMaxWaitingTime, func() (bool, error) {
+		volume, err := backend.GetVolume(volumeID)
+		if err != nil {
+			e2e.Logf("Failed to get the volume %s on %s backend: %v, try again", volumeID, backend, err)
+			return false, nil
+		}
+		return volume == nil, nil
+	})
+	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("The volume %s still exists on %s backend", volumeID, backend))
+}
+
+// awsVolumeBackend is the EBS volume backend
+type awsVolumeBackend struct {
+	ec2Client *ec2.EC2
+}
+
+func (b *awsVolumeBackend) String() string {
+	return "aws"
+}
+
+func (b *awsVolumeBackend) GetVolume(volumeID string) (*backendVolume, error) {
+	output, err := b.ec2Client.DescribeVolumes(&ec2.DescribeVolumesInput{
+		Filters: []*ec2.Filter{{Name: aws.String("volume-id"), Values: []*string{aws.String(volumeID)}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the deleted volumes are still described in "deleted" state for a while
+	if len(output.Volumes) == 0 || aws.StringValue(output.Volumes[0].State) == ec2.VolumeStateDeleted {
+		return nil, nil
+	}
+	ebs := output.Volumes[0]
+	volume := &backendVolume{
+		ID:         aws.StringValue(ebs.VolumeId),
+		SizeGiB:    aws.Int64Value(ebs.Size),
+		Type:       aws.StringValue(ebs.VolumeType),
+		Iops:       aws.Int64Value(ebs.Iops),
+		Throughput: aws.Int64Value(ebs.Throughput),
+		Encrypted:  aws.BoolValue(ebs.Encrypted),
+		KmsKeyID:   aws.StringValue(ebs.KmsKeyId),
+		Zone:       aws.StringValue(ebs.AvailabilityZone),
+		Available:  aws.StringValue(ebs.State) == ec2.VolumeStateAvailable,
+	}
+	for _, attachment := range ebs.Attachments {
+		if aws.StringValue(attachment.State) == ec2.VolumeAttachmentStateAttached {
+			volume.AttachedTo = append(volume.AttachedTo, aws.StringValue(attachment.InstanceId))
+		}
+	}
+	return volume, nil
+}
+
+func (b *awsVolumeBackend) SnapshotExists(snapshotID string) (bool, error) {
+	output, err := b.ec2Client.DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+		Filters: []*ec2.Filter{{Name: aws.String("snapshot-id"), Values: []*string{aws.String(snapshotID)}}},
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(output.Snapshots) > 0, nil
+}
+
+func (b *awsVolumeBackend) DeleteVolume(volumeID string) error {
+	_, err := b.ec2Client.DeleteVolume(&ec2.DeleteVolumeInput{VolumeId: aws.String(volumeID)})
+	return err
+}
+
+// gcpVolumeBackend is the persistent disk volume backend, the volume handles are
+// "projects/<project>/zones/<zone>/disks/<name>" or "projects/<project>/regions/<region>/disks/<name>"
+type gcpVolumeBackend struct {
+	gcloud *exutil.Gcloud
+}
+
+type gcpPdVolume struct {
+	Name                  string   `json:"name"`
+	SizeGb                string   `json:"sizeGb"`
+	Type                  string   `json:"type"`
+	ProvisionedIops       string   `json:"provisionedIops"`
+	ProvisionedThroughput string   `json:"provisionedThroughput"`
+	Zone                  string   `json:"zone"`
+	Region                string   `json:"region"`
+	Status                string   `json:"status"`
+	Users                 []string `json:"users"`
+	DiskEncryptionKey     struct {
+		KmsKeyName string `json:"kmsKeyName"`
+	} `json:"diskEncryptionKey"`
+}
+
+func (b *gcpVolumeBackend) String() string {
+	return "gcp"
+}
+
+// pdVolumeLocation returns the disk name, its location scope "zone" or "region" and its location from the volume handle
+func pdVolumeLocation(volumeID string) (name, scope, location string) {
+	parts := strings.Split(volumeID, "/")
+	if len(parts) != 6 {
+		return volumeID, "", ""
+	}
+	return parts[5], strings.TrimSuffix(parts[2], "s"), parts[3]
+}
+
+func (b *gcpVolumeBackend) GetVolume(volumeID string) (*backendVolume, error) {
+	name, scope, location := pdVolumeLocation(volumeID)
+	filterArgs := []string{"--filter=name=" + name}
+	if scope != "" {
+		filterArgs = append(filterArgs, fmt.Sprintf("--%ss=%s", scope, location))
+	}
+	output, err := b.gcloud.ListPdVolumes(filterArgs...)
+	if err != nil {
+		return nil, err
+	}
+	var disks []gcpPdVolume
+	if err := json.Unmarshal(output, &disks); err != nil {
+		return nil, err
+	}
+	if len(disks) == 0 {
+		return nil, nil
+	}
+	disk := disks[0]
+	volume := &backendVolume{
+		ID:         volumeID,
+		SizeGiB:    parseInt64OrZero(disk.SizeGb),
+		Type:       path.Base(disk.Type),
+		Iops:       parseInt64OrZero(disk.ProvisionedIops),
+		Throughput: parseInt64OrZero(disk.ProvisionedThroughput),
+		// the persistent disks are always encrypted, by the google managed key if no kms key is set
+		Encrypted: true,
+		KmsKeyID:  disk.DiskEncryptionKey.KmsKeyName,
+		Zone:      path.Base(disk.Zone),
+	}
+	if disk.Zone == "" {
+		volume.Zone = path.Base(disk.Region)
+	}
+	for _, user := range disk.Users {
+		volume.AttachedTo = append(volume.AttachedTo, path.Base(user))
+	}
+	volume.Available = disk.Status == "READY" && !volume.isAttached()
+	return volume, nil
+}
+
+func (b *gcpVolumeBackend) SnapshotExists(snapshotID string) (bool, error) {
+	output, err := b.gcloud.ListPdSnapshots("--filter=name=" + path.Base(snapshotID))
+	if err != nil {
+		return false, err
+	}
+	var snapshots []interface{}
+	if err := json.Unmarshal(output, &snapshots); err != nil {
+		return false, err
+	}
+	return len(snapshots) > 0, nil
+}
+
+func (b *gcpVolumeBackend) DeleteVolume(volumeID string) error {
+	name, scope, location := pdVolumeLocation(volumeID)
+	if scope == "" {
+		return fmt.Errorf("invalid pd volume handle %q", volumeID)
+	}
+	return b.gcloud.DeletePdVolume(name, fmt.Sprintf("--%s=%s", scope, location))
+}
+
+// azureVolumeBackend is the managed disk volume backend, the volume handles are the disk resource IDs
+type azureVolumeBackend struct {
+	clientSet *exutil.AzureClientSet
+}
+
+func (b *azureVolumeBackend) String() string {
+	return "azure"
+}
+
+// isAzureNotFound returns whether the error is the 404 response of the azure api
+func isAzureNotFound(err error) bool {
+	var responseErr *azcore.ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound
+}
+
+func (b *azureVolumeBackend) GetVolume(volumeID string) (*backendVolume, error) {
+	resourceID, err := arm.ParseResourceID(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	response, err := b.clientSet.GetDisksClient(nil).Get(context.Background(), resourceID.ResourceGroupName, resourceID.Name, nil)
+	if isAzureNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	disk := response.Disk
+	volume := &backendVolume{ID: volumeID, Encrypted: true}
+	if disk.SKU != nil && disk.SKU.Name != nil {
+		volume.Type = string(*disk.SKU.Name)
+	}
+	if len(disk.Zones) > 0 && disk.Zones[0] != nil {
+		volume.Zone = *disk.Zones[0]
+	}
+	if disk.ManagedBy != nil {
+		volume.AttachedTo = []string{path.Base(*disk.ManagedBy)}
+	}
+	if properties := disk.Properties; properties != nil {
+		volume.Available = properties.DiskState != nil && *properties.DiskState == armcompute.DiskStateUnattached
+		if properties.DiskSizeGB != nil {
+			volume.SizeGiB = int64(*properties.DiskSizeGB)
+		}
+		if properties.DiskIOPSReadWrite != nil {
+			volume.Iops = *properties.DiskIOPSReadWrite
+		}
+		if properties.DiskMBpsReadWrite != nil {
+			volume.Throughput = *properties.DiskMBpsReadWrite
+		}
+		// the managed disks are always encrypted, by the platform managed key if no disk encryption set is used
+		if properties.Encryption != nil && properties.Encryption.DiskEncryptionSetID != nil {
+			volume.KmsKeyID = *properties.Encryption.DiskEncryptionSetID
+		}
+	}
+	return volume, nil
+}
+
+func (b *azureVolumeBackend) SnapshotExists(snapshotID string) (bool, error) {
+	resourceID, err := arm.ParseResourceID(snapshotID)
+	if err != nil {
+		return false, err
+	}
+	_, err = b.clientSet.GetSnapshotsClient(nil).Get(context.Background(), resourceID.ResourceGroupName, resourceID.Name, nil)
+	if isAzureNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *azureVolumeBackend) DeleteVolume(volumeID string) error {
+	resourceID, err := arm.ParseResourceID(volumeID)
+	if err != nil {
+		return err
+	}
+	poller, err := b.clientSet.GetDisksClient(nil).BeginDelete(context.Background(), resourceID.ResourceGroupName, resourceID.Name, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(context.Background(), nil)
+	return err
+}
+
+// vsphereVolumeBackend is the CNS volume backend, the volume handles are the CNS volume IDs.
+// CNS reports neither the zone nor the performance of the volumes, the volume is attached to the pods using it
+// in the CNS metadata, and the snapshots are not supported.
+type vsphereVolumeBackend struct {
+	oc *exutil.CLI
+}
+
+func (b *vsphereVolumeBackend) String() string {
+	return "vsphere"
+}
+
+func (b *vsphereVolumeBackend) cnsClient(ctx context.Context) (*cns.Client, error) {
+	return cns.NewClient(ctx, NewVim25Client(ctx, b.oc))
+}
+
+func (b *vsphereVolumeBackend) GetVolume(volumeID string) (*backendVolume, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cnsClient, err := b.cnsClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := cnsClient.QueryVolume(ctx, cnstypes.CnsQueryFilter{VolumeIds: []cnstypes.CnsVolumeId{{Id: volumeID}}})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Volumes) == 0 {
+		return nil, nil
+	}
+	cnsVolume := result.Volumes[0]
+	volume := &backendVolume{ID: volumeID, Type: cnsVolume.VolumeType}
+	if cnsVolume.BackingObjectDetails != nil {
+		volume.SizeGiB = cnsVolume.BackingObjectDetails.GetCnsBackingObjectDetails().CapacityInMb / 1024
+	}
+	for _, entity := range cnsVolume.Metadata.EntityMetadata {
+		if metadata, ok := entity.(*cnstypes.CnsKubernetesEntityMetadata); ok && metadata.EntityType == string(cnstypes.CnsKubernetesEntityTypePOD) {
+			volume.AttachedTo = append(volume.AttachedTo, metadata.EntityName)
+		}
+	}
+	// CNS has no volume state, the volume is available once no pod uses it
+	volume.Available = !volume.isAttached()
+	return volume, nil
+}
+
+func (b *vsphereVolumeBackend) SnapshotExists(snapshotID string) (bool, error) {
+	return false, fmt.Errorf("%w: checking the snapshots on vsphere", errBackendNotSupported)
+}
+
+func (b *vsphereVolumeBackend) DeleteVolume(volumeID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	cnsClient, err := b.cnsClient(ctx)
+	if err != nil {
+		return err
+	}
+	task, err := cnsClient.DeleteVolume(ctx, []cnstypes.CnsVolumeId{{Id: volumeID}}, true)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// ibmVolumeBackend is the VPC block storage volume backend, the volume handles are the volume IDs
+type ibmVolumeBackend struct {
+	session *exutil.IBMSession
+}
+
+func (b *ibmVolumeBackend) String() string {
+	return "ibmcloud"
+}
+
+func (b *ibmVolumeBackend) GetVolume(volumeID string) (*backendVolume, error) {
+	ibmVolume, err := exutil.GetIBMVolume(b.session, volumeID)
+	if err != nil || ibmVolume == nil {
+		return nil, err
+	}
+	volume := &backendVolume{
+		ID:         volumeID,
+		SizeGiB:    derefInt64(ibmVolume.Capacity),
+		Iops:       derefInt64(ibmVolume.Iops),
+		Throughput: derefInt64(ibmVolume.Bandwidth),
+		// the volumes are always encrypted, by the provider managed key if no encryption key is set
+		Encrypted: true,
+	}
+	if ibmVolume.Profile != nil && ibmVolume.Profile.Name != nil {
+		volume.Type = *ibmVolume.Profile.Name
+	}
+	if ibmVolume.EncryptionKey != nil && ibmVolume.EncryptionKey.CRN != nil {
+		volume.KmsKeyID = *ibmVolume.EncryptionKey.CRN
+	}
+	if ibmVolume.Zone != nil && ibmVolume.Zone.Name != nil {
+		volume.Zone = *ibmVolume.Zone.Name
+	}
+	for _, attachment := range ibmVolume.VolumeAttachments {
+		if attachment.Instance != nil && attachment.Instance.ID != nil {
+			volume.AttachedTo = append(volume.AttachedTo, *attachment.Instance.ID)
+		}
+	}
+	volume.Available = ibmVolume.Status != nil && *ibmVolume.Status == vpcv1.VolumeStatusAvailableConst && !volume.isAttached()
+	return volume, nil
+}
+
+func (b *ibmVolumeBackend) SnapshotExists(snapshotID string) (bool, error) {
+	snapshot, err := exutil.GetIBMSnapshot(b.session, snapshotID)
+	return snapshot != nil, err
+}
+
+func (b *ibmVolumeBackend) DeleteVolume(volumeID string) error {
+	return exutil.DeleteIBMVolume(b.session, volumeID)
+}
+
+// fakeVolumeBackend is the in memory volume backend for the self tests of the backend helpers
+type fakeVolumeBackend struct {
+	mu        sync.Mutex
+	volumes   map[string]backendVolume
+	snapshots map[string]bool
+}
+
+func newFakeVolumeBackend() *fakeVolumeBackend {
+	return &fakeVolumeBackend{volumes: map[string]backendVolume{}, snapshots: map[string]bool{}}
+}
+
+func (b *fakeVolumeBackend) String() string {
+	return "fake"
+}
+
+// addVolume adds or replaces the volume
+func (b *fakeVolumeBackend) addVolume(volume backendVolume) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.volumes[volume.ID] = volume
+}
+
+// addSnapshot adds the snapshot
+func (b *fakeVolumeBackend) addSnapshot(snapshotID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.snapshots[snapshotID] = true
+}
+
+func (b *fakeVolumeBackend) GetVolume(volumeID string) (*backendVolume, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	volume, ok := b.volumes[volumeID]
+	if !ok {
+		return nil, nil
+	}
+	volume.AttachedTo = append([]string(nil), volume.AttachedTo...)
+	return &volume, nil
+}
+
+func (b *fakeVolumeBackend) SnapshotExists(snapshotID string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshots[snapshotID], nil
+}
+
+func (b *fakeVolumeBackend) DeleteVolume(volumeID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.volumes[volumeID]; !ok {
+		return fmt.Errorf("volume %s not found", volumeID)
+	}
+	delete(b.volumes, volumeID)
+	return nil
+}
+
+func derefInt64(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// parseInt64OrZero parses the int64 encoded as string in the gcloud json output, 0 if not set
+func parseInt64OrZero(s string) int64 {
+	i, _ := strconv.ParseInt(s, 10, 64)
+	return i
+}
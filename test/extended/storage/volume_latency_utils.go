@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	o "github.com/onsi/gomega"
+	pkgmonitor "github.com/openshift/openshift-tests-private/pkg/monitor"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// volumeLatencyOperation is a volume lifecycle operation whose latency is measured
+type volumeLatencyOperation string
+
+const (
+	volumeLatencyProvision     volumeLatencyOperation = "provision"
+	volumeLatencyAttach        volumeLatencyOperation = "attach"
+	volumeLatencyMount         volumeLatencyOperation = "mount"
+	volumeLatencyResize        volumeLatencyOperation = "resize"
+	volumeLatencySnapshotReady volumeLatencyOperation = "snapshot-ready"
+	volumeLatencyDetach        volumeLatencyOperation = "detach"
+	volumeLatencyDelete        volumeLatencyOperation = "delete"
+)
+
+// volumeLatencyCheckEnv enables the threshold check, the latencies above the thresholds only fail the cases if it is set
+// to "true". Otherwise they are reported as Warning intervals, so the functional cases don't fail on a slow cloud.
+const volumeLatencyCheckEnv = "STORAGE_VOLUME_LATENCY_CHECK"
+
+// volumeLatencyThresholdsEnv overrides the latency thresholds with a json of the thresholds by provisioner and operation,
+// i.e. {"ebs.csi.aws.com": {"provision": "90s", "attach": "1m"}}
+const volumeLatencyThresholdsEnv = "STORAGE_VOLUME_LATENCY_THRESHOLDS"
+
+// defaultVolumeLatencyThresholds are the latency thresholds of the operations, the slower provisioners replace some of them
+var defaultVolumeLatencyThresholds = map[volumeLatencyOperation]time.Duration{
+	volumeLatencyProvision:     2 * time.Minute,
+	volumeLatencyAttach:        2 * time.Minute,
+	volumeLatencyMount:         3 * time.Minute,
+	volumeLatencyResize:        3 * time.Minute,
+	volumeLatencySnapshotReady: 5 * time.Minute,
+	volumeLatencyDetach:        2 * time.Minute,
+	volumeLatencyDelete:        3 * time.Minute,
+}
+
+// provisionerVolumeLatencyThresholds are the thresholds replacing the default ones by provisioner
+var provisionerVolumeLatencyThresholds = map[string]map[volumeLatencyOperation]time.Duration{
+	// Azure file shares and filestore instances take minutes to be created
+	azureFileCsiDriverProvisioner:    {volumeLatencyProvision: 5 * time.Minute, volumeLatencyDelete: 5 * time.Minute},
+	gcpFilestoreCsiDriverProvisioner: {volumeLatencyProvision: 15 * time.Minute, volumeLatencyDelete: 15 * time.Minute, volumeLatencyResize: 10 * time.Minute},
+	// The snapshots of the gcp pd and vSphere volumes are slow to be ready
+	gcpPdCsiDriverProvisioner:  {volumeLatencySnapshotReady: 10 * time.Minute},
+	vmwareCsiDriverProvisioner: {volumeLatencySnapshotReady: 10 * time.Minute},
+}
+
+// getVolumeLatencyThresholds returns the latency thresholds of the provisioner, including the overrides of the environment
+func getVolumeLatencyThresholds(provisioner string) (map[volumeLatencyOperation]time.Duration, error) {
+	thresholds := map[volumeLatencyOperation]time.Duration{}
+	for operation, threshold := range defaultVolumeLatencyThresholds {
+		thresholds[operation] = threshold
+	}
+	for operation, threshold := range provisionerVolumeLatencyThresholds[provisioner] {
+		thresholds[operation] = threshold
+	}
+	overridesJSON := os.Getenv(volumeLatencyThresholdsEnv)
+	if overridesJSON == "" {
+		return thresholds, nil
+	}
+	overrides := map[string]map[volumeLatencyOperation]string{}
+	if err := json.Unmarshal([]byte(overridesJSON), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", volumeLatencyThresholdsEnv, err)
+	}
+	for operation, value := range overrides[provisioner] {
+		if _, ok := defaultVolumeLatencyThresholds[operation]; !ok {
+			return nil, fmt.Errorf("invalid %s: unknown operation %q", volumeLatencyThresholdsEnv, operation)
+		}
+		threshold, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", volumeLatencyThresholdsEnv, err)
+		}
+		thresholds[operation] = threshold
+	}
+	return thresholds, nil
+}
+
+// volumeLatency is the measured latency of an operation of a volume object
+type volumeLatency struct {
+	operation    volumeLatencyOperation
+	storageClass string
+	// object is the pvc, pod or volumesnapshot of the operation, i.e. "pvc/e2e-test/mypvc"
+	object string
+	from   time.Time
+	to     time.Time
+}
+
+func (l volumeLatency) duration() time.Duration {
+	return l.to.Sub(l.from)
+}
+
+// volumeLatencyRecorder watches the volume objects of a namespace and measures the latencies of their lifecycle operations
+type volumeLatencyRecorder struct {
+	oc          *exutil.CLI
+	namespace   string
+	provisioner string
+	thresholds  map[volumeLatencyOperation]time.Duration
+	now         func() time.Time
+
+	lock      sync.Mutex
+	latencies []volumeLatency
+	// pending are the start times of the operations in progress by operation and object
+	pending map[string]time.Time
+	// recorded are the operations measured once per object
+	recorded map[string]bool
+	// storageClasses are the storageclasses of the pvcs, volumes are the pvc objects of the pvs
+	storageClasses map[string]string
+	volumes        map[string]string
+	cancel         context.CancelFunc
+	stopped        bool
+}
+
+// newVolumeLatencyRecorder creates the latency recorder of the volumes of the provisioner in the namespace
+func newVolumeLatencyRecorder(oc *exutil.CLI, namespace string, provisioner string) *volumeLatencyRecorder {
+	thresholds, err := getVolumeLatencyThresholds(provisioner)
+	o.Expect(err).NotTo(o.HaveOccurred())
+	return &volumeLatencyRecorder{
+		oc:             oc,
+		namespace:      namespace,
+		provisioner:    provisioner,
+		thresholds:     thresholds,
+		now:            time.Now,
+		pending:        map[string]time.Time{},
+		recorded:       map[string]bool{},
+		storageClasses: map[string]string{},
+		volumes:        map[string]string{},
+	}
+}
+
+// start starts watching the pvcs, pvs, volumeattachments, pods and volumesnapshots until the recorder is stopped
+func (r *volumeLatencyRecorder) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	client := r.oc.AdminKubeClient()
+
+	r.watch(ctx, &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().PersistentVolumeClaims(r.namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().PersistentVolumeClaims(r.namespace).Watch(ctx, options)
+		},
+	}, &corev1.PersistentVolumeClaim{}, func(obj interface{}, deleted bool) {
+		r.observePersistentVolumeClaim(obj.(*corev1.PersistentVolumeClaim), deleted)
+	})
+	r.watch(ctx, &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().PersistentVolumes().List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().PersistentVolumes().Watch(ctx, options)
+		},
+	}, &corev1.PersistentVolume{}, func(obj interface{}, deleted bool) {
+		r.observePersistentVolume(obj.(*corev1.PersistentVolume), deleted)
+	})
+	r.watch(ctx, &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.StorageV1().VolumeAttachments().List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.StorageV1().VolumeAttachments().Watch(ctx, options)
+		},
+	}, &storagev1.VolumeAttachment{}, func(obj interface{}, deleted bool) {
+		r.observeVolumeAttachment(obj.(*storagev1.VolumeAttachment), deleted)
+	})
+	r.watch(ctx, &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Pods(r.namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Pods(r.namespace).Watch(ctx, options)
+		},
+	}, &corev1.Pod{}, func(obj interface{}, deleted bool) {
+		r.observePod(obj.(*corev1.Pod))
+	})
+
+	// The volumesnapshots are only watched if the CSISnapshot capability is enabled
+	snapshots := r.oc.AdminDynamicClient().Resource(schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}).Namespace(r.namespace)
+	if _, err := snapshots.List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		e2e.Logf("Not measuring the volumesnapshot latencies: %v", err)
+		return
+	}
+	r.watch(ctx, &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return snapshots.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return snapshots.Watch(ctx, options)
+		},
+	}, &unstructured.Unstructured{}, func(obj interface{}, deleted bool) {
+		r.observeVolumeSnapshot(obj.(*unstructured.Unstructured))
+	})
+}
+
+// watch runs an informer calling observe on every change of the objects until the context is done
+func (r *volumeLatencyRecorder) watch(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object, observe func(obj interface{}, deleted bool)) {
+	_, informer := cache.NewInformer(lw, objType, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { observe(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { observe(obj, false) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			observe(obj, true)
+		},
+	})
+	go informer.Run(ctx.Done())
+}
+
+// begin records the start of the operation of the object if it is not in progress
+func (r *volumeLatencyRecorder) begin(operation volumeLatencyOperation, object string, from time.Time) {
+	key := string(operation) + " " + object
+	if _, ok := r.pending[key]; !ok && !r.recorded[key] {
+		r.pending[key] = from
+	}
+}
+
+// finish records the latency of the operation of the object in progress, once marks the operations measured once per object
+func (r *volumeLatencyRecorder) finish(operation volumeLatencyOperation, object string, storageClass string, once bool) {
+	key := string(operation) + " " + object
+	from, ok := r.pending[key]
+	if !ok {
+		return
+	}
+	delete(r.pending, key)
+	r.recorded[key] = once
+	r.latencies = append(r.latencies, volumeLatency{operation: operation, storageClass: storageClass, object: object, from: from, to: r.now()})
+}
+
+func (r *volumeLatencyRecorder) observePersistentVolumeClaim(pvc *corev1.PersistentVolumeClaim, deleted bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	object := "pvc/" + pvc.Namespace + "/" + pvc.Name
+	// The pvcs of the other provisioners tested in the namespace are measured by their own recorder
+	if provisioner, ok := pvc.Annotations["volume.kubernetes.io/storage-provisioner"]; ok && provisioner != r.provisioner {
+		delete(r.storageClasses, object)
+		delete(r.pending, string(volumeLatencyProvision)+" "+object)
+		return
+	}
+	storageClass := ""
+	if pvc.Spec.StorageClassName != nil {
+		storageClass = *pvc.Spec.StorageClassName
+	}
+	r.storageClasses[object] = storageClass
+
+	// The provisioning of the WaitForFirstConsumer volumes starts when the consumer pod is scheduled
+	r.begin(volumeLatencyProvision, object, pvc.CreationTimestamp.Time)
+	if _, ok := pvc.Annotations["volume.kubernetes.io/selected-node"]; ok && pvc.Status.Phase == corev1.ClaimPending && !r.recorded["selected-node "+object] {
+		r.recorded["selected-node "+object] = true
+		r.pending[string(volumeLatencyProvision)+" "+object] = r.now()
+	}
+	if pvc.Status.Phase == corev1.ClaimBound {
+		r.finish(volumeLatencyProvision, object, storageClass, true)
+		r.volumes[pvc.Spec.VolumeName] = object
+	}
+
+	requested, capacity := pvc.Spec.Resources.Requests[corev1.ResourceStorage], pvc.Status.Capacity[corev1.ResourceStorage]
+	if pvc.Status.Phase == corev1.ClaimBound && !capacity.IsZero() {
+		if requested.Cmp(capacity) > 0 {
+			r.begin(volumeLatencyResize, object, r.now())
+		} else {
+			r.finish(volumeLatencyResize, object, storageClass, false)
+		}
+	}
+
+	// The deletion is measured until the pv is deleted
+	if (deleted || pvc.DeletionTimestamp != nil) && pvc.Spec.VolumeName != "" {
+		from := r.now()
+		if pvc.DeletionTimestamp != nil {
+			from = pvc.DeletionTimestamp.Time
+		}
+		r.begin(volumeLatencyDelete, object, from)
+	}
+}
+
+func (r *volumeLatencyRecorder) observePersistentVolume(pv *corev1.PersistentVolume, deleted bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	object, ok := r.volumes[pv.Name]
+	if !ok || !deleted {
+		return
+	}
+	r.finish(volumeLatencyDelete, object, r.storageClasses[object], true)
+}
+
+func (r *volumeLatencyRecorder) observeVolumeAttachment(va *storagev1.VolumeAttachment, deleted bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if va.Spec.Source.PersistentVolumeName == nil {
+		return
+	}
+	pvcObject, ok := r.volumes[*va.Spec.Source.PersistentVolumeName]
+	if !ok {
+		return
+	}
+	// The volume attachments of the pvc to the nodes are measured separately
+	object := pvcObject + " node/" + va.Spec.NodeName
+	storageClass := r.storageClasses[pvcObject]
+
+	r.begin(volumeLatencyAttach, object, va.CreationTimestamp.Time)
+	if va.Status.Attached {
+		r.finish(volumeLatencyAttach, object, storageClass, true)
+	}
+	if va.DeletionTimestamp != nil {
+		r.begin(volumeLatencyDetach, object, va.DeletionTimestamp.Time)
+	}
+	if deleted {
+		r.finish(volumeLatencyDetach, object, storageClass, false)
+		// The volume could be attached to the node again
+		delete(r.recorded, string(volumeLatencyAttach)+" "+object)
+	}
+}
+
+func (r *volumeLatencyRecorder) observePod(pod *corev1.Pod) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	storageClass, consumesVolume := "", false
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			storageClass, consumesVolume = r.storageClasses["pvc/"+pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName]
+			if consumesVolume {
+				break
+			}
+		}
+	}
+	if !consumesVolume {
+		return
+	}
+	// The volumes are mounted from the pod scheduling until the containers are ready
+	object := "pod/" + pod.Namespace + "/" + pod.Name
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+			r.begin(volumeLatencyMount, object, condition.LastTransitionTime.Time)
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.ContainersReady && condition.Status == corev1.ConditionTrue {
+			r.finish(volumeLatencyMount, object, storageClass, true)
+		}
+	}
+}
+
+func (r *volumeLatencyRecorder) observeVolumeSnapshot(snapshot *unstructured.Unstructured) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	storageClass, ok := r.storageClasses["pvc/"+snapshot.GetNamespace()+"/"+source]
+	if !ok {
+		return
+	}
+	object := "volumesnapshot/" + snapshot.GetNamespace() + "/" + snapshot.GetName()
+	r.begin(volumeLatencySnapshotReady, object, snapshot.GetCreationTimestamp().Time)
+	if readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); readyToUse {
+		r.finish(volumeLatencySnapshotReady, object, storageClass, true)
+	}
+}
+
+// intervals returns the measured latencies as monitor intervals, the ones above the thresholds are warnings
+func (r *volumeLatencyRecorder) intervals() pkgmonitor.EventIntervals {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	intervals := pkgmonitor.EventIntervals{}
+	for _, latency := range r.latencies {
+		level, message := pkgmonitor.Info, fmt.Sprintf("%s took %s within the threshold %s", latency.object, latency.duration().Round(time.Millisecond), r.thresholds[latency.operation])
+		if latency.duration() > r.thresholds[latency.operation] {
+			level, message = pkgmonitor.Warning, fmt.Sprintf("%s took %s above the threshold %s", latency.object, latency.duration().Round(time.Millisecond), r.thresholds[latency.operation])
+		}
+		intervals = append(intervals, &pkgmonitor.EventInterval{
+			Condition: &pkgmonitor.Condition{
+				Level:   level,
+				Locator: fmt.Sprintf("provisioner/%s storageclass/%s operation/%s", r.provisioner, latency.storageClass, latency.operation),
+				Message: message,
+			},
+			From: latency.from,
+			To:   latency.to,
+		})
+	}
+	sort.Sort(intervals)
+	return intervals
+}
+
+// stop stops watching and writes the latencies to the test output, so they are reported in the monitor timeline and
+// in the jUnit properties of the test
+func (r *volumeLatencyRecorder) stop() {
+	r.lock.Lock()
+	if r.stopped {
+		r.lock.Unlock()
+		return
+	}
+	r.stopped = true
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.lock.Unlock()
+
+	intervals := r.intervals()
+	for _, interval := range intervals {
+		e2e.Logf("Volume latency: %s %s", interval.Locator, interval.Message)
+	}
+	if err := pkgmonitor.WriteIntervals(os.Stdout, intervals...); err != nil {
+		e2e.Logf("Failed to write the volume latencies: %v", err)
+	}
+}
+
+// exceededThresholds returns the latencies above the thresholds of the provisioner
+func (r *volumeLatencyRecorder) exceededThresholds() []volumeLatency {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	exceeded := []volumeLatency{}
+	for _, latency := range r.latencies {
+		if latency.duration() > r.thresholds[latency.operation] {
+			exceeded = append(exceeded, latency)
+		}
+	}
+	return exceeded
+}
+
+// checkThresholds checks none of the latencies is above the thresholds of the provisioner, if the check is enabled by
+// volumeLatencyCheckEnv
+func (r *volumeLatencyRecorder) checkThresholds() {
+	report := []string{}
+	for _, latency := range r.exceededThresholds() {
+		report = append(report, fmt.Sprintf("%s of %s took %s, the threshold is %s", latency.operation, latency.object, latency.duration().Round(time.Millisecond), r.thresholds[latency.operation]))
+	}
+	if os.Getenv(volumeLatencyCheckEnv) != "true" {
+		if len(report) > 0 {
+			e2e.Logf("The %s volume operations are slower than the thresholds, not failing as %s is not set: %v", r.provisioner, volumeLatencyCheckEnv, report)
+		}
+		return
+	}
+	o.Expect(report).To(o.BeEmpty(), fmt.Sprintf("The %s volume operations are slower than the thresholds", r.provisioner))
+}
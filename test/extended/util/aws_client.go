@@ -2,6 +2,7 @@ package util
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/url"
@@ -306,6 +307,57 @@ func (a *AwsClient) StopInstance(instanceID string) error {
 	return err
 }
 
+// ForceStopInstance Stop an instance without waiting for the guest to shut down
+func (a *AwsClient) ForceStopInstance(instanceID string) error {
+	if instanceID == "" {
+		return fmt.Errorf("You must supply an instance ID (-i INSTANCE-ID")
+	}
+	input := &ec2.StopInstancesInput{
+		InstanceIds: []*string{
+			&instanceID,
+		},
+		Force: aws.Bool(true),
+	}
+	result, err := a.svc.StopInstances(input)
+	if err != nil {
+		return err
+	}
+	e2e.Logf("%v", result.StoppingInstances)
+	return nil
+}
+
+// RebootInstance Reboot an instance
+func (a *AwsClient) RebootInstance(instanceID string) error {
+	if instanceID == "" {
+		return fmt.Errorf("You must supply an instance ID (-i INSTANCE-ID")
+	}
+	_, err := a.svc.RebootInstances(&ec2.RebootInstancesInput{
+		InstanceIds: []*string{
+			&instanceID,
+		},
+	})
+	return err
+}
+
+// GetAwsInstanceConsoleOutput gives the latest console output of the instance
+func (a *AwsClient) GetAwsInstanceConsoleOutput(instanceID string) (string, error) {
+	result, err := a.svc.GetConsoleOutput(&ec2.GetConsoleOutputInput{
+		InstanceId: aws.String(instanceID),
+		Latest:     aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Output == nil {
+		return "", nil
+	}
+	output, err := base64.StdEncoding.DecodeString(*result.Output)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode the console output of %s: %v", instanceID, err)
+	}
+	return string(output), nil
+}
+
 // GetAwsInstanceState gives the instance state
 func (a *AwsClient) GetAwsInstanceState(instanceID string) (string, error) {
 	filters := []*ec2.Filter{
@@ -342,6 +394,7 @@ func (a *AwsClient) CreateDhcpOptions() (string, error) {
 			},
 		},
 	}
+	input.TagSpecifications = ec2TagSpecifications(ec2.ResourceTypeDhcpOptions)
 	result, err := a.svc.CreateDhcpOptions(input)
 	if err != nil {
 		e2e.Logf("err: %v", err)
@@ -349,6 +402,7 @@ func (a *AwsClient) CreateDhcpOptions() (string, error) {
 	}
 	dhcpOptionsID := result.DhcpOptions.DhcpOptionsId
 	e2e.Logf("The created dhcpOptionsId is %s", *dhcpOptionsID)
+	RegisterCloudResource(CloudProviderAWS, CloudResourceDhcpOptions, *dhcpOptionsID, aws.StringValue(a.svc.Config.Region))
 	return *dhcpOptionsID, err
 }
 
@@ -370,6 +424,7 @@ func (a *AwsClient) CreateDhcpOptionsWithDomainName(domainName string) (string,
 			},
 		},
 	}
+	input.TagSpecifications = ec2TagSpecifications(ec2.ResourceTypeDhcpOptions)
 	result, err := a.svc.CreateDhcpOptions(input)
 	if err != nil {
 		e2e.Logf("err: %v", err)
@@ -377,6 +432,7 @@ func (a *AwsClient) CreateDhcpOptionsWithDomainName(domainName string) (string,
 	}
 	dhcpOptionsID := result.DhcpOptions.DhcpOptionsId
 	e2e.Logf("The created dhcpOptionsId is %s", *dhcpOptionsID)
+	RegisterCloudResource(CloudProviderAWS, CloudResourceDhcpOptions, *dhcpOptionsID, aws.StringValue(a.svc.Config.Region))
 	return *dhcpOptionsID, err
 }
 
@@ -386,9 +442,25 @@ func (a *AwsClient) DeleteDhcpOptions(dhcpOptionsID string) error {
 		DhcpOptionsId: aws.String(dhcpOptionsID),
 	}
 	_, err := a.svc.DeleteDhcpOptions(input)
+	if err == nil {
+		ReleaseCloudResource(CloudProviderAWS, CloudResourceDhcpOptions, dhcpOptionsID)
+	}
 	return err
 }
 
+// ec2TagSpecifications returns the tags of the run for the ec2 resources of the type, none if the run has no ID
+func ec2TagSpecifications(resourceType string) []*ec2.TagSpecification {
+	tags := CloudResourceTags()
+	if len(tags) == 0 {
+		return nil
+	}
+	spec := &ec2.TagSpecification{ResourceType: aws.String(resourceType)}
+	for key, value := range tags {
+		spec.Tags = append(spec.Tags, &ec2.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	return []*ec2.TagSpecification{spec}
+}
+
 // GetPlacementGroupByName Get placement group by group-name
 func (a *AwsClient) GetPlacementGroupByName(groupName string) (string, error) {
 	input := &ec2.DescribePlacementGroupsInput{
@@ -483,14 +555,16 @@ func (a *AwsClient) AssociateDhcpOptions(vpcID, dhcpOptionsID string) error {
 
 func (a *AwsClient) CreateSecurityGroup(groupName, vpcID, description string) (string, error) {
 	createRes, err := a.svc.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{
-		GroupName:   aws.String(groupName),
-		Description: aws.String(description),
-		VpcId:       aws.String(vpcID),
+		GroupName:         aws.String(groupName),
+		Description:       aws.String(description),
+		VpcId:             aws.String(vpcID),
+		TagSpecifications: ec2TagSpecifications(ec2.ResourceTypeSecurityGroup),
 	})
 	if err != nil {
 		return "", err
 	}
 
+	RegisterCloudResource(CloudProviderAWS, CloudResourceSecurityGroup, *createRes.GroupId, aws.StringValue(a.svc.Config.Region))
 	return *createRes.GroupId, nil
 }
 
@@ -498,6 +572,9 @@ func (a *AwsClient) DeleteSecurityGroup(groupID string) error {
 	_, err := a.svc.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{
 		GroupId: aws.String(groupID),
 	})
+	if err == nil {
+		ReleaseCloudResource(CloudProviderAWS, CloudResourceSecurityGroup, groupID)
+	}
 	return err
 }
 
@@ -723,6 +800,16 @@ func (sc *S3Client) CreateBucket(name string) error {
 	}
 
 	e2e.Logf("bucket %s is created successfully %v", name, cbo)
+	RegisterCloudResource(CloudProviderAWS, CloudResourceS3Bucket, name, aws.StringValue(sc.svc.Config.Region))
+	if tags := CloudResourceTags(); len(tags) > 0 {
+		tagging := &s3.Tagging{}
+		for key, value := range tags {
+			tagging.TagSet = append(tagging.TagSet, &s3.Tag{Key: aws.String(key), Value: aws.String(value)})
+		}
+		if _, err := sc.svc.PutBucketTagging(&s3.PutBucketTaggingInput{Bucket: aws.String(name), Tagging: tagging}); err != nil {
+			e2e.Logf("failed to tag bucket %s: %v", name, err)
+		}
+	}
 
 	_, doe := sc.svc.DeletePublicAccessBlock(&s3.DeletePublicAccessBlockInput{
 		Bucket: aws.String(name),
@@ -798,6 +885,7 @@ func (sc *S3Client) DeleteBucket(name string) error {
 	}
 
 	e2e.Logf("bucket %s is successfully deleted", name)
+	ReleaseCloudResource(CloudProviderAWS, CloudResourceS3Bucket, name)
 
 	return nil
 }
@@ -904,11 +992,20 @@ func NewIAMClientFromCredFile(filename, region string) *IAMClient {
 }
 
 func (iamClient *IAMClient) CreateRoleWithContext(ctx aws.Context, input *iam.CreateRoleInput, opts ...request.Option) (*iam.CreateRoleOutput, error) {
-	return iamClient.svc.CreateRoleWithContext(ctx, input, opts...)
+	input.Tags = append(input.Tags, getTags(CloudResourceTags())...)
+	output, err := iamClient.svc.CreateRoleWithContext(ctx, input, opts...)
+	if err == nil {
+		RegisterCloudResource(CloudProviderAWS, CloudResourceIAMRole, aws.StringValue(input.RoleName), "")
+	}
+	return output, err
 }
 
 func (iamClient *IAMClient) DeleteRoleWithContext(ctx aws.Context, input *iam.DeleteRoleInput, opts ...request.Option) (*iam.DeleteRoleOutput, error) {
-	return iamClient.svc.DeleteRoleWithContext(ctx, input, opts...)
+	output, err := iamClient.svc.DeleteRoleWithContext(ctx, input, opts...)
+	if err == nil {
+		ReleaseCloudResource(CloudProviderAWS, CloudResourceIAMRole, aws.StringValue(input.RoleName))
+	}
+	return output, err
 }
 
 func (iamClient *IAMClient) DeleteOpenIDConnectProviderByProviderName(providerName string) error {
@@ -348,7 +348,16 @@ func CreateAzureStorageBlobContainer(container azblob.ContainerURL) error {
 	_, err := container.GetProperties(ctx, azblob.LeaseAccessConditions{})
 	message := fmt.Sprintf("%v", err)
 	if strings.Contains(message, "ContainerNotFound") {
-		_, err = container.Create(ctx, azblob.Metadata{}, azblob.PublicAccessNone)
+		// the metadata names must be C# identifiers
+		metadata := azblob.Metadata{}
+		for key, value := range CloudResourceTags() {
+			metadata[strings.ReplaceAll(key, "-", "_")] = value
+		}
+		_, err = container.Create(ctx, metadata, azblob.PublicAccessNone)
+		if err == nil {
+			// the cleanup can't delete the container without the key of the storage account, a leak is only reported
+			RegisterCloudResource(CloudProviderAzure, CloudResourceBlobContainer, container.String(), "")
+		}
 		return err
 	}
 	return EmptyAzureBlobContainer(container)
@@ -367,6 +376,7 @@ func DeleteAzureStorageBlobContainer(container azblob.ContainerURL) error {
 		return fmt.Errorf("error deleting container: %v", err)
 	}
 	e2e.Logf("Azure storage container is deleted")
+	ReleaseCloudResource(CloudProviderAzure, CloudResourceBlobContainer, container.String())
 	return nil
 }
 
@@ -471,6 +481,16 @@ func StopAzureStackVM(resourceGroupName, vmName string) error {
 	return nil
 }
 
+// ForceStopAzureStackVM powers off the virtual machine without shutting down the guest using Azure CLI
+func ForceStopAzureStackVM(resourceGroupName, vmName string) error {
+	cmd := fmt.Sprintf(`az vm stop --name %s --resource-group %s --skip-shutdown --no-wait`, vmName, resourceGroupName)
+	err := exec.Command("bash", "-c", cmd).Run()
+	if err != nil {
+		return fmt.Errorf("error force stopping VM: %v", err)
+	}
+	return nil
+}
+
 // StartAzureStackVM starts the virtual machine with the given name in the specified resource group using Azure CLI
 func StartAzureStackVM(resourceGroupName, vmName string) error {
 	cmd := fmt.Sprintf(`az vm start --name %s --resource-group %s`, vmName, resourceGroupName)
@@ -30,9 +30,11 @@ type AzureClientSet struct {
 	// Clients
 	capacityReservationGroupClient *armcompute.CapacityReservationGroupsClient
 	capacityReservationsClient     *armcompute.CapacityReservationsClient
+	disksClient                    *armcompute.DisksClient
 	graphServiceClient             *msgraphsdkgo.GraphServiceClient
 	keysClient                     *armkeyvault.KeysClient
 	resourceGroupsClient           *armresources.ResourceGroupsClient
+	snapshotsClient                *armcompute.SnapshotsClient
 	vaultsClient                   *armkeyvault.VaultsClient
 	virtualMachinesClient          *armcompute.VirtualMachinesClient
 }
@@ -102,6 +104,26 @@ func (cs *AzureClientSet) GetCapacityReservationsClient(options *arm.ClientOptio
 	return cs.capacityReservationsClient
 }
 
+// GetDisksClient gets the managed disks client from AzureClientSet, constructs it if necessary.
+func (cs *AzureClientSet) GetDisksClient(options *arm.ClientOptions) *armcompute.DisksClient {
+	if cs.disksClient == nil {
+		disksClient, err := armcompute.NewDisksClient(cs.SubscriptionID, cs.tokenCredential, options)
+		o.Expect(err).NotTo(o.HaveOccurred())
+		cs.disksClient = disksClient
+	}
+	return cs.disksClient
+}
+
+// GetSnapshotsClient gets the disk snapshots client from AzureClientSet, constructs it if necessary.
+func (cs *AzureClientSet) GetSnapshotsClient(options *arm.ClientOptions) *armcompute.SnapshotsClient {
+	if cs.snapshotsClient == nil {
+		snapshotsClient, err := armcompute.NewSnapshotsClient(cs.SubscriptionID, cs.tokenCredential, options)
+		o.Expect(err).NotTo(o.HaveOccurred())
+		cs.snapshotsClient = snapshotsClient
+	}
+	return cs.snapshotsClient
+}
+
 // GetVaultsClient gets the vaults client from AzureClientSet, constructs it if necessary.
 func (cs *AzureClientSet) GetVaultsClient(options *arm.ClientOptions) *armkeyvault.VaultsClient {
 	if cs.vaultsClient == nil {
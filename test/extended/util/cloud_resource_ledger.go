@@ -0,0 +1,499 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	g "github.com/onsi/ginkgo/v2"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// Tests create resources on the cloud provider, i.e. S3 buckets or GCP service accounts, and delete them in a defer. The
+// defers don't run when the test process is killed on timeout, and a failed deletion is only logged. The provider helpers
+// register the resources they create and delete in the cloud resource ledger: each registration is written to the test
+// output, the suite runner collects them from the output of every test, deletes what is left when the suite ends and
+// reports the resources it couldn't delete. A PreChkUpgrade test hands off the resources its PstChkUpgrade test uses
+// after the upgrade, those are kept and reported as handed off.
+const (
+	// EnvCloudResourceRunID is the ID of the suite run, set by the suite runner. The helpers tag the resources they
+	// create with it where the provider supports tags, so that the leaked resources of a run can be found.
+	EnvCloudResourceRunID = "OPENSHIFT_TESTS_RUN_ID"
+	// CloudResourceRunIDTag is the tag key of the run ID
+	CloudResourceRunIDTag = "openshift-tests-run-id"
+
+	CloudProviderAWS   = "aws"
+	CloudProviderAzure = "azure"
+	CloudProviderGCP   = "gcp"
+
+	CloudResourceS3Bucket       = "s3-bucket"
+	CloudResourceIAMRole        = "iam-role"
+	CloudResourceIAMPolicy      = "iam-policy"
+	CloudResourceDhcpOptions    = "dhcp-options"
+	CloudResourceSecurityGroup  = "security-group"
+	CloudResourceServiceAccount = "service-account"
+	CloudResourceBlobContainer  = "blob-container"
+
+	cloudResourceOutputPrefix = "cloud-resource: "
+	cloudResourceReportFile   = "cloud-resource-leaks.json"
+	cloudResourceCleanTimeout = 5 * time.Minute
+)
+
+// cloudResourceOutput is where the registrations are written, the output of the test read by the suite runner
+var cloudResourceOutput io.Writer = os.Stdout
+
+// CloudResource is a resource created on the cloud provider by a test
+type CloudResource struct {
+	Provider string `json:"provider"`
+	Type     string `json:"type"`
+	// ID is what the cleaner of the resource type needs to delete it, i.e. the bucket name or the ARN
+	ID        string            `json:"id"`
+	Region    string            `json:"region,omitempty"`
+	Test      string            `json:"test,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+func (r CloudResource) key() string {
+	return r.Provider + "/" + r.Type + "/" + r.ID
+}
+
+func (r CloudResource) String() string {
+	s := fmt.Sprintf("%s %s %s", r.Provider, r.Type, r.ID)
+	if r.Region != "" {
+		s += " in " + r.Region
+	}
+	if r.Test != "" {
+		s += fmt.Sprintf(" created by %q", r.Test)
+	}
+	return s
+}
+
+// cloudResourceEvent is a line of the test output, the creation or the deletion of a resource
+type cloudResourceEvent struct {
+	Action   string        `json:"action"`
+	Resource CloudResource `json:"resource"`
+}
+
+// CloudResourceRunID returns the ID of the suite run, empty if the test isn't run by the suite runner
+func CloudResourceRunID() string {
+	return os.Getenv(EnvCloudResourceRunID)
+}
+
+// CloudResourceTags returns the tags of the resources created in this run, none if the run has no ID
+func CloudResourceTags() map[string]string {
+	if id := CloudResourceRunID(); id != "" {
+		return map[string]string{CloudResourceRunIDTag: id}
+	}
+	return nil
+}
+
+// RegisterCloudResource registers a resource created by the current test, it is deleted when the suite ends unless
+// ReleaseCloudResource is called once the test deletes it
+func RegisterCloudResource(provider, resourceType, id, region string) {
+	writeCloudResourceEvent("created", CloudResource{
+		Provider:  provider,
+		Type:      resourceType,
+		ID:        id,
+		Region:    region,
+		Test:      g.CurrentSpecReport().FullText(),
+		Tags:      CloudResourceTags(),
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// ReleaseCloudResource unregisters a resource deleted by the current test
+func ReleaseCloudResource(provider, resourceType, id string) {
+	writeCloudResourceEvent("deleted", CloudResource{Provider: provider, Type: resourceType, ID: id})
+}
+
+// HandOffCloudResource keeps a resource registered by the current test for a test of a later run, i.e. the
+// PstChkUpgrade test of a PreChkUpgrade test, the suite doesn't delete it and the later test deletes it
+func HandOffCloudResource(provider, resourceType, id string) {
+	writeCloudResourceEvent("handed-off", CloudResource{Provider: provider, Type: resourceType, ID: id})
+}
+
+func writeCloudResourceEvent(action string, r CloudResource) {
+	data, err := json.Marshal(cloudResourceEvent{Action: action, Resource: r})
+	if err != nil {
+		e2e.Logf("failed to register the %s cloud resource %s: %v", action, r, err)
+		return
+	}
+	fmt.Fprintf(cloudResourceOutput, "%s%s\n", cloudResourceOutputPrefix, data)
+}
+
+// CloudResourceCleaner deletes a leaked resource, it returns no error if the resource is already gone
+type CloudResourceCleaner func(ctx context.Context, r CloudResource) error
+
+var (
+	cloudResourceCleanersLock sync.Mutex
+	cloudResourceCleaners     = map[string]CloudResourceCleaner{}
+)
+
+// RegisterCloudResourceCleaner sets the cleaner of the resources of the type, the test packages creating resources
+// without the helpers of util register theirs in an init function. The cleaners run in the suite runner, not in a
+// test: the AWS cleaners of util resolve the credentials of the cluster as the test helpers do, see
+// awsCleanerSessionOf, the other cleaners must get theirs from the environment of the suite runner, i.e.
+// GOOGLE_APPLICATION_CREDENTIALS for GCP.
+func RegisterCloudResourceCleaner(provider, resourceType string, cleaner CloudResourceCleaner) {
+	cloudResourceCleanersLock.Lock()
+	defer cloudResourceCleanersLock.Unlock()
+	cloudResourceCleaners[provider+"/"+resourceType] = cleaner
+}
+
+func cloudResourceCleaner(provider, resourceType string) CloudResourceCleaner {
+	cloudResourceCleanersLock.Lock()
+	defer cloudResourceCleanersLock.Unlock()
+	return cloudResourceCleaners[provider+"/"+resourceType]
+}
+
+// CloudResourceLedger is the list of the resources created by the tests of a suite and not deleted yet
+type CloudResourceLedger struct {
+	lock      sync.Mutex
+	created   int
+	resources map[string]CloudResource
+	order     []string
+	// handedOff are the resources kept for a later run, in hand-off order
+	handedOff []CloudResource
+}
+
+// NewCloudResourceLedger returns an empty ledger
+func NewCloudResourceLedger() *CloudResourceLedger {
+	return &CloudResourceLedger{resources: map[string]CloudResource{}}
+}
+
+// Record updates the ledger with the registrations written to the output of a test, the malformed lines are ignored
+func (l *CloudResourceLedger) Record(output []byte) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, cloudResourceOutputPrefix)
+		if i < 0 {
+			continue
+		}
+		var event cloudResourceEvent
+		if err := json.Unmarshal([]byte(line[i+len(cloudResourceOutputPrefix):]), &event); err != nil {
+			continue
+		}
+		key := event.Resource.key()
+		switch event.Action {
+		case "created":
+			if _, ok := l.resources[key]; !ok {
+				l.order = append(l.order, key)
+			}
+			l.resources[key] = event.Resource
+			l.created++
+		case "deleted":
+			delete(l.resources, key)
+			for i, r := range l.handedOff {
+				if r.key() == key {
+					l.handedOff = append(l.handedOff[:i], l.handedOff[i+1:]...)
+					break
+				}
+			}
+		case "handed-off":
+			if r, ok := l.resources[key]; ok {
+				l.handedOff = append(l.handedOff, r)
+				delete(l.resources, key)
+			}
+		}
+	}
+}
+
+// Outstanding returns the resources not deleted by the tests, in creation order
+func (l *CloudResourceLedger) Outstanding() []CloudResource {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var resources []CloudResource
+	var order []string
+	for _, key := range l.order {
+		if r, ok := l.resources[key]; ok {
+			resources = append(resources, r)
+			order = append(order, key)
+		}
+	}
+	l.order = order
+	return resources
+}
+
+// CloudResourceLeak is a resource the cleanup couldn't delete
+type CloudResourceLeak struct {
+	CloudResource
+	Error string `json:"error"`
+}
+
+// CloudResourceReport is the result of the cleanup at the end of the suite
+type CloudResourceReport struct {
+	RunID string `json:"runID,omitempty"`
+	// Created is the number of resources registered by the tests
+	Created int `json:"created"`
+	// CleanedUp are the resources the tests didn't delete, deleted by the cleanup or already gone
+	CleanedUp []CloudResource `json:"cleanedUp"`
+	// Leaked are the resources the cleanup couldn't delete
+	Leaked []CloudResourceLeak `json:"leaked"`
+	// HandedOff are the resources kept for a test of a later run
+	HandedOff []CloudResource `json:"handedOff"`
+}
+
+// Cleanup deletes the outstanding resources with their cleaners, best effort, and returns what was left behind. The
+// handed off resources are not deleted.
+func (l *CloudResourceLedger) Cleanup(ctx context.Context) *CloudResourceReport {
+	resources := l.Outstanding()
+	l.lock.Lock()
+	report := &CloudResourceReport{RunID: CloudResourceRunID(), Created: l.created, CleanedUp: []CloudResource{}, Leaked: []CloudResourceLeak{},
+		HandedOff: append([]CloudResource{}, l.handedOff...)}
+	l.lock.Unlock()
+
+	for _, r := range resources {
+		cleaner := cloudResourceCleaner(r.Provider, r.Type)
+		if cleaner == nil {
+			report.Leaked = append(report.Leaked, CloudResourceLeak{CloudResource: r, Error: fmt.Sprintf("no cleaner for the %s %s resources", r.Provider, r.Type)})
+			continue
+		}
+		cleanCtx, cancel := context.WithTimeout(ctx, cloudResourceCleanTimeout)
+		err := cleaner(cleanCtx, r)
+		cancel()
+		if err != nil {
+			report.Leaked = append(report.Leaked, CloudResourceLeak{CloudResource: r, Error: err.Error()})
+			continue
+		}
+		report.CleanedUp = append(report.CleanedUp, r)
+		l.lock.Lock()
+		delete(l.resources, r.key())
+		l.lock.Unlock()
+	}
+	return report
+}
+
+func (r *CloudResourceReport) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%d cloud resources created by the tests, %d deleted at the end of the suite, %d leaked", r.Created, len(r.CleanedUp), len(r.Leaked))
+	if len(r.Leaked) > 0 {
+		fmt.Fprintf(buf, ":\n")
+		for _, leak := range r.Leaked {
+			fmt.Fprintf(buf, "  %s: %s\n", leak.CloudResource, leak.Error)
+		}
+	}
+	if len(r.HandedOff) > 0 {
+		fmt.Fprintf(buf, "\n%d handed off to a later run:\n", len(r.HandedOff))
+		for _, resource := range r.HandedOff {
+			fmt.Fprintf(buf, "  %s\n", resource)
+		}
+	}
+	return buf.String()
+}
+
+// WriteFile writes the report in the directory as json
+func (r *CloudResourceReport) WriteFile(dir string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, cloudResourceReportFile), data, 0644)
+}
+
+// isAWSNotFound returns true if the error is one of the not found codes of the AWS API
+func isAWSNotFound(err error, codes ...string) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		for _, code := range codes {
+			if aerr.Code() == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func awsConfigOf(r CloudResource) *aws.Config {
+	config := aws.NewConfig()
+	if r.Region != "" {
+		config = config.WithRegion(r.Region)
+	}
+	return config
+}
+
+var (
+	awsCleanerOnce    sync.Once
+	awsCleanerSession *session.Session
+	awsCleanerErr     error
+)
+
+// awsCleanerSessionOf returns the session of the AWS cleaners. Like the test helpers, see
+// clusterinfra.GetAwsCredentialFromCluster, it uses the kube-system/aws-creds secret and the region of the cluster of
+// KUBECONFIG. The cluster has no such secret with STS, the credentials of the environment of the suite runner are used then.
+func awsCleanerSessionOf(ctx context.Context) (*session.Session, error) {
+	awsCleanerOnce.Do(func() {
+		config := aws.NewConfig()
+		creds, region, err := awsClusterCredentials(ctx)
+		if err != nil {
+			e2e.Logf("deleting the leaked AWS resources with the credentials of the environment: %v", err)
+		} else {
+			config = config.WithCredentials(creds).WithRegion(region)
+		}
+		awsCleanerSession, awsCleanerErr = session.NewSession(config)
+	})
+	return awsCleanerSession, awsCleanerErr
+}
+
+// awsClusterCredentials returns the credentials of the kube-system/aws-creds secret and the region of the cluster
+func awsClusterCredentials(ctx context.Context) (*credentials.Credentials, string, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", KubeConfigPath())
+	if err != nil {
+		return nil, "", err
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := kubeClient.CoreV1().Secrets("kube-system").Get(ctx, "aws-creds", metav1.GetOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	configClient, err := configv1client.NewForConfig(restConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	infra, err := configClient.ConfigV1().Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	var region string
+	if infra.Status.PlatformStatus != nil && infra.Status.PlatformStatus.AWS != nil {
+		region = infra.Status.PlatformStatus.AWS.Region
+	}
+	creds := credentials.NewStaticCredentials(string(secret.Data["aws_access_key_id"]), string(secret.Data["aws_secret_access_key"]), "")
+	return creds, region, nil
+}
+
+func init() {
+	RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceS3Bucket, func(ctx context.Context, r CloudResource) error {
+		sess, err := awsCleanerSessionOf(ctx)
+		if err != nil {
+			return err
+		}
+		sc := &S3Client{svc: s3.New(sess, awsConfigOf(r))}
+		if err := sc.EmptyBucketWithContextAndCheck(ctx, r.ID); err != nil {
+			if isAWSNotFound(err, s3.ErrCodeNoSuchBucket) || strings.Contains(err.Error(), s3.ErrCodeNoSuchBucket) {
+				return nil
+			}
+			return err
+		}
+		_, err = sc.svc.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{Bucket: aws.String(r.ID)})
+		if isAWSNotFound(err, s3.ErrCodeNoSuchBucket) {
+			return nil
+		}
+		return err
+	})
+	RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceDhcpOptions, func(ctx context.Context, r CloudResource) error {
+		sess, err := awsCleanerSessionOf(ctx)
+		if err != nil {
+			return err
+		}
+		svc := ec2.New(sess, awsConfigOf(r))
+		_, err = svc.DeleteDhcpOptionsWithContext(ctx, &ec2.DeleteDhcpOptionsInput{DhcpOptionsId: aws.String(r.ID)})
+		if isAWSNotFound(err, "InvalidDhcpOptionID.NotFound") {
+			return nil
+		}
+		return err
+	})
+	RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceSecurityGroup, func(ctx context.Context, r CloudResource) error {
+		sess, err := awsCleanerSessionOf(ctx)
+		if err != nil {
+			return err
+		}
+		svc := ec2.New(sess, awsConfigOf(r))
+		_, err = svc.DeleteSecurityGroupWithContext(ctx, &ec2.DeleteSecurityGroupInput{GroupId: aws.String(r.ID)})
+		if isAWSNotFound(err, "InvalidGroup.NotFound") {
+			return nil
+		}
+		return err
+	})
+	RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceIAMRole, deleteLeakedIAMRole)
+	RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceIAMPolicy, deleteLeakedIAMPolicy)
+}
+
+// deleteLeakedIAMRole deletes the role after detaching its policies, IAM refuses to delete a role with policies
+func deleteLeakedIAMRole(ctx context.Context, r CloudResource) error {
+	sess, err := awsCleanerSessionOf(ctx)
+	if err != nil {
+		return err
+	}
+	svc := iam.New(sess, awsConfigOf(r))
+	role := aws.String(r.ID)
+	attached, err := svc.ListAttachedRolePoliciesWithContext(ctx, &iam.ListAttachedRolePoliciesInput{RoleName: role})
+	if isAWSNotFound(err, iam.ErrCodeNoSuchEntityException) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, policy := range attached.AttachedPolicies {
+		if _, err := svc.DetachRolePolicyWithContext(ctx, &iam.DetachRolePolicyInput{RoleName: role, PolicyArn: policy.PolicyArn}); err != nil {
+			return err
+		}
+	}
+	inline, err := svc.ListRolePoliciesWithContext(ctx, &iam.ListRolePoliciesInput{RoleName: role})
+	if err != nil {
+		return err
+	}
+	for _, name := range inline.PolicyNames {
+		if _, err := svc.DeleteRolePolicyWithContext(ctx, &iam.DeleteRolePolicyInput{RoleName: role, PolicyName: name}); err != nil {
+			return err
+		}
+	}
+	_, err = svc.DeleteRoleWithContext(ctx, &iam.DeleteRoleInput{RoleName: role})
+	if isAWSNotFound(err, iam.ErrCodeNoSuchEntityException) {
+		return nil
+	}
+	return err
+}
+
+// deleteLeakedIAMPolicy deletes the policy, the ID is its ARN, after detaching it from the roles, IAM refuses to delete
+// an attached policy
+func deleteLeakedIAMPolicy(ctx context.Context, r CloudResource) error {
+	sess, err := awsCleanerSessionOf(ctx)
+	if err != nil {
+		return err
+	}
+	svc := iam.New(sess, awsConfigOf(r))
+	policy := aws.String(r.ID)
+	entities, err := svc.ListEntitiesForPolicyWithContext(ctx, &iam.ListEntitiesForPolicyInput{PolicyArn: policy})
+	if isAWSNotFound(err, iam.ErrCodeNoSuchEntityException) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, role := range entities.PolicyRoles {
+		if _, err := svc.DetachRolePolicyWithContext(ctx, &iam.DetachRolePolicyInput{RoleName: role.RoleName, PolicyArn: policy}); err != nil {
+			return err
+		}
+	}
+	_, err = svc.DeletePolicyWithContext(ctx, &iam.DeletePolicyInput{PolicyArn: policy})
+	if isAWSNotFound(err, iam.ErrCodeNoSuchEntityException) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloudResourceLedger(t *testing.T) {
+	out := &bytes.Buffer{}
+	cloudResourceOutput = out
+	defer func() { cloudResourceOutput = os.Stdout }()
+	t.Setenv(EnvCloudResourceRunID, "20261019-101500-abcd1234")
+
+	// the output of a test: the logs and the registrations of its helpers
+	fmt.Fprintln(out, "STEP: create the buckets")
+	RegisterCloudResource(CloudProviderAWS, CloudResourceS3Bucket, "logging-bucket", "us-east-2")
+	RegisterCloudResource(CloudProviderAWS, CloudResourceIAMRole, "logging-role", "")
+	RegisterCloudResource(CloudProviderGCP, CloudResourceServiceAccount, "projects/p/serviceAccounts/sa@p.iam.gserviceaccount.com", "")
+	RegisterCloudResource(CloudProviderAzure, CloudResourceBlobContainer, "https://account.blob.core.windows.net/loki", "")
+	ReleaseCloudResource(CloudProviderAWS, CloudResourceIAMRole, "logging-role")
+	fmt.Fprintln(out, cloudResourceOutputPrefix+"{malformed")
+
+	ledger := NewCloudResourceLedger()
+	ledger.Record(out.Bytes())
+	ledger.Record([]byte("no registration\n"))
+
+	outstanding := ledger.Outstanding()
+	if len(outstanding) != 3 || outstanding[0].ID != "logging-bucket" || outstanding[0].Region != "us-east-2" {
+		t.Fatalf("unexpected outstanding resources %v", outstanding)
+	}
+	if outstanding[0].Tags[CloudResourceRunIDTag] != "20261019-101500-abcd1234" || outstanding[0].CreatedAt.IsZero() {
+		t.Fatalf("expected the run ID tag and the creation time, got %+v", outstanding[0])
+	}
+
+	// the fake cleaners replace the registered ones for the test only
+	s3Cleaner := cloudResourceCleaner(CloudProviderAWS, CloudResourceS3Bucket)
+	serviceAccountCleaner := cloudResourceCleaner(CloudProviderGCP, CloudResourceServiceAccount)
+	defer RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceS3Bucket, s3Cleaner)
+	defer RegisterCloudResourceCleaner(CloudProviderGCP, CloudResourceServiceAccount, serviceAccountCleaner)
+	if s3Cleaner == nil {
+		t.Fatalf("expected the S3 bucket cleaner of util to be registered")
+	}
+
+	var cleaned []string
+	RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceS3Bucket, func(ctx context.Context, r CloudResource) error {
+		cleaned = append(cleaned, r.ID)
+		return nil
+	})
+	RegisterCloudResourceCleaner(CloudProviderGCP, CloudResourceServiceAccount, func(ctx context.Context, r CloudResource) error {
+		return fmt.Errorf("permission denied")
+	})
+
+	report := ledger.Cleanup(context.Background())
+	if strings.Join(cleaned, ",") != "logging-bucket" || report.Created != 4 || len(report.CleanedUp) != 1 {
+		t.Fatalf("unexpected cleanup of %v: %s", cleaned, report)
+	}
+	if len(report.Leaked) != 2 || report.Leaked[0].Error != "permission denied" || !strings.Contains(report.Leaked[1].Error, "no cleaner") {
+		t.Fatalf("unexpected leaks %+v", report.Leaked)
+	}
+	if left := ledger.Outstanding(); len(left) != 2 {
+		t.Fatalf("expected the leaked resources to stay in the ledger, got %v", left)
+	}
+
+	dir := t.TempDir()
+	if err := report.WriteFile(dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, cloudResourceReportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written CloudResourceReport
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.RunID != "20261019-101500-abcd1234" || len(written.Leaked) != 2 || written.Leaked[1].Provider != CloudProviderAzure {
+		t.Fatalf("unexpected report %s", data)
+	}
+}
+
+func TestCloudResourceLedgerHandOff(t *testing.T) {
+	out := &bytes.Buffer{}
+	cloudResourceOutput = out
+	defer func() { cloudResourceOutput = os.Stdout }()
+
+	// a PreChkUpgrade test hands off the dhcp options its PstChkUpgrade test deletes, the security group is deleted
+	// in the same run after the hand-off
+	RegisterCloudResource(CloudProviderAWS, CloudResourceDhcpOptions, "dopt-0123", "us-east-2")
+	RegisterCloudResource(CloudProviderAWS, CloudResourceSecurityGroup, "sg-0123", "us-east-2")
+	HandOffCloudResource(CloudProviderAWS, CloudResourceDhcpOptions, "dopt-0123")
+	HandOffCloudResource(CloudProviderAWS, CloudResourceSecurityGroup, "sg-0123")
+	ReleaseCloudResource(CloudProviderAWS, CloudResourceSecurityGroup, "sg-0123")
+	// a hand-off of a resource not registered in this run is ignored
+	HandOffCloudResource(CloudProviderAWS, CloudResourceS3Bucket, "unknown-bucket")
+
+	ledger := NewCloudResourceLedger()
+	ledger.Record(out.Bytes())
+	if outstanding := ledger.Outstanding(); len(outstanding) != 0 {
+		t.Fatalf("expected the handed off resources not to be outstanding, got %v", outstanding)
+	}
+
+	cleaner := cloudResourceCleaner(CloudProviderAWS, CloudResourceDhcpOptions)
+	defer RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceDhcpOptions, cleaner)
+	RegisterCloudResourceCleaner(CloudProviderAWS, CloudResourceDhcpOptions, func(ctx context.Context, r CloudResource) error {
+		t.Errorf("unexpected cleanup of %s", r)
+		return nil
+	})
+
+	report := ledger.Cleanup(context.Background())
+	if len(report.CleanedUp) != 0 || len(report.Leaked) != 0 || len(report.HandedOff) != 1 || report.HandedOff[0].ID != "dopt-0123" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if !strings.Contains(report.String(), "1 handed off to a later run:\n  aws dhcp-options dopt-0123 in us-east-2") {
+		t.Fatalf("unexpected report %q", report)
+	}
+}
@@ -0,0 +1,490 @@
+package clusterinfra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	g "github.com/onsi/ginkgo/v2"
+	o "github.com/onsi/gomega"
+	pkgmonitor "github.com/openshift/openshift-tests-private/pkg/monitor"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// The tests creating or scaling MachineSets run in parallel processes. The capacity broker keeps the machines they add
+// within the budget of the run, so that they don't exhaust the cloud quota or the max nodes of the cluster together: a
+// test leases the machines it needs, waits while the budget is used by the leases of the other tests, and releases
+// them once its machines are deleted. The leases are stored in a ConfigMap of the cluster shared by the processes of the
+// run, and renewed while their test runs.
+const (
+	// EnvCapacityBudget is the budget of the run, the maximum number of machines the leases can hold together, as a
+	// comma separated list of <platform>=<machines> or <platform>/<instance type>=<machines>. The "*" platform is the
+	// budget of the platforms not in the list, i.e. "aws=6,aws/m6i.metal=1,*=4". The platforms without budget are
+	// unbounded, only the maxNodesTotal of the ClusterAutoscaler limits the leases then.
+	EnvCapacityBudget = "CAPACITY_BUDGET"
+
+	capacityLeaseNamespace = "default"
+	// capacityLeaseConfigMap is the name of the ConfigMap of the leases, suffixed with the ID of the run if it has one
+	capacityLeaseConfigMap = "openshift-tests-capacity-leases"
+
+	// capacityLeaseTTL is how long a lease is held once its test stops renewing it, i.e. if the test process dies
+	// without releasing it
+	capacityLeaseTTL           = 10 * time.Minute
+	capacityLeaseRenewInterval = 2 * time.Minute
+	capacityWaitTimeout        = 30 * time.Minute
+	capacityPollInterval       = 30 * time.Second
+	capacityAutoscalerConfig   = "clusterautoscaler.autoscaling.openshift.io"
+)
+
+var (
+	// ErrCapacityExceedsBudget is returned when a request can never be satisfied by the budget of the run
+	ErrCapacityExceedsBudget = errors.New("the request exceeds the capacity budget of the run")
+	// ErrCapacityUnavailable is returned when the budget is still used by the other tests after the wait timeout
+	ErrCapacityUnavailable = errors.New("no capacity available")
+)
+
+// CapacityBudget is the maximum number of machines the leases can hold
+type CapacityBudget struct {
+	// Machines is -1 if the budget is unbounded
+	Machines int
+	// InstanceTypes are the budgets of the instance types with their own quota, within Machines
+	InstanceTypes map[string]int
+}
+
+// ParseCapacityBudget returns the budget of the platform in the budget of the run, see EnvCapacityBudget
+func ParseCapacityBudget(spec, platform string) (CapacityBudget, error) {
+	budget := CapacityBudget{Machines: -1, InstanceTypes: map[string]int{}}
+	wildcard := -1
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, "=")
+		machines, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || err != nil || machines < 0 {
+			return budget, fmt.Errorf("invalid capacity budget %q, expected <platform>[/<instance type>]=<machines>", entry)
+		}
+		entryPlatform, instanceType, _ := strings.Cut(strings.TrimSpace(key), "/")
+		switch {
+		case entryPlatform == "*":
+			wildcard = machines
+		case entryPlatform != platform:
+		case instanceType != "":
+			budget.InstanceTypes[instanceType] = machines
+		default:
+			budget.Machines = machines
+		}
+	}
+	if budget.Machines < 0 {
+		budget.Machines = wildcard
+	}
+	return budget, nil
+}
+
+// CapacityLease is a number of machines held by a test
+type CapacityLease struct {
+	ID           string    `json:"-"`
+	Test         string    `json:"test"`
+	InstanceType string    `json:"instanceType,omitempty"`
+	Machines     int       `json:"machines"`
+	AcquiredAt   time.Time `json:"acquiredAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+
+	broker   *CapacityBroker
+	released bool
+	// stop stops the renewal of the lease, nil while it isn't held
+	stop chan struct{}
+}
+
+func (l *CapacityLease) String() string {
+	shape := "machines"
+	if l.InstanceType != "" {
+		shape = l.InstanceType + " machines"
+	}
+	return fmt.Sprintf("lease %s of %d %s for %q", l.ID, l.Machines, shape, l.Test)
+}
+
+// capacityLeaseStore stores the leases of the run
+type capacityLeaseStore interface {
+	// update passes the current leases to fn and saves them if fn returns true
+	update(fn func(leases map[string]*CapacityLease) bool) error
+}
+
+// CapacityBroker leases the machines of the budget of the run to the tests
+type CapacityBroker struct {
+	budget CapacityBudget
+	store  capacityLeaseStore
+	// room returns how many machines the leases can hold together on the cluster besides the budget, -1 if unbounded
+	room func(leased int) (int, error)
+
+	// WaitTimeout is how long Acquire waits for the budget to be available
+	WaitTimeout   time.Duration
+	pollInterval  time.Duration
+	ttl           time.Duration
+	renewInterval time.Duration
+	out           io.Writer
+	lock          sync.Mutex
+}
+
+// NewCapacityBroker returns the broker of the cluster, with the budget of the platform in EnvCapacityBudget. The leases
+// are those of the run, see exutil.EnvCloudResourceRunID, the runs on the same cluster don't share their budgets.
+func NewCapacityBroker(oc *exutil.CLI) (*CapacityBroker, error) {
+	budget, err := ParseCapacityBudget(os.Getenv(EnvCapacityBudget), CheckPlatform(oc).String())
+	if err != nil {
+		return nil, err
+	}
+	name := capacityLeaseConfigMap
+	if runID := exutil.CloudResourceRunID(); runID != "" {
+		name += "-" + strings.ToLower(runID)
+	}
+	b := newCapacityBroker(budget, &configMapLeaseStore{oc: oc, name: name})
+	b.room = func(leased int) (int, error) { return clusterRoom(oc, leased) }
+	return b, nil
+}
+
+func newCapacityBroker(budget CapacityBudget, store capacityLeaseStore) *CapacityBroker {
+	return &CapacityBroker{
+		budget:        budget,
+		store:         store,
+		room:          func(int) (int, error) { return -1, nil },
+		WaitTimeout:   capacityWaitTimeout,
+		pollInterval:  capacityPollInterval,
+		ttl:           capacityLeaseTTL,
+		renewInterval: capacityLeaseRenewInterval,
+		out:           os.Stdout,
+	}
+}
+
+// clusterRoom returns how many machines the leases can hold together within the maxNodesTotal of the ClusterAutoscaler,
+// the nodes of the leases already provisioned are counted once
+func clusterRoom(oc *exutil.CLI, leased int) (int, error) {
+	maxNodes, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(capacityAutoscalerConfig, "default", "-o=jsonpath={.spec.resourceLimits.maxNodesTotal}").Output()
+	if err != nil || maxNodes == "" {
+		// no ClusterAutoscaler or no limit
+		return -1, nil
+	}
+	maxNodesTotal, err := strconv.Atoi(maxNodes)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maxNodesTotal %q: %v", maxNodes, err)
+	}
+	nodes, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("node", "-o=name").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the nodes: %v", err)
+	}
+	baseline := len(strings.Fields(nodes)) - leased
+	if baseline < 0 {
+		baseline = 0
+	}
+	return maxNodesTotal - baseline, nil
+}
+
+// fits returns true if the leases leave room for the request, and ErrCapacityExceedsBudget if it can never fit
+func (b *CapacityBroker) fits(leases map[string]*CapacityLease, instanceType string, machines int) (bool, error) {
+	bounded := b.budget.Machines >= 0
+	typeBudget, hasTypeBudget := b.budget.InstanceTypes[instanceType]
+	if (bounded && machines > b.budget.Machines) || (hasTypeBudget && machines > typeBudget) {
+		return false, fmt.Errorf("%w: %d %s machines, budget %+v", ErrCapacityExceedsBudget, machines, instanceType, b.budget)
+	}
+	leased, typeLeased := 0, 0
+	for _, l := range leases {
+		leased += l.Machines
+		if l.InstanceType == instanceType {
+			typeLeased += l.Machines
+		}
+	}
+	if (bounded && leased+machines > b.budget.Machines) || (hasTypeBudget && typeLeased+machines > typeBudget) {
+		return false, nil
+	}
+	room, err := b.room(leased)
+	if err != nil {
+		return false, err
+	}
+	return room < 0 || leased+machines <= room, nil
+}
+
+// Acquire leases the machines of the instance type, the instance type is empty if it doesn't matter, and waits up to
+// WaitTimeout while the leases of the other tests use the budget
+func (b *CapacityBroker) Acquire(instanceType string, machines int) (*CapacityLease, error) {
+	lease := &CapacityLease{
+		ID:           fmt.Sprintf("%s-%s", time.Now().UTC().Format("150405"), exutil.GetRandomString()),
+		Test:         g.CurrentSpecReport().FullText(),
+		InstanceType: instanceType,
+		Machines:     machines,
+		broker:       b,
+	}
+	e2e.Logf("Acquiring the capacity %s", lease)
+	if err := b.hold(lease, machines); err != nil {
+		return nil, err
+	}
+	e2e.Logf("Acquired the capacity %s", lease)
+	return lease, nil
+}
+
+// Resize changes the number of machines of the lease, it waits like Acquire if the lease grows. A released lease is
+// acquired again.
+func (l *CapacityLease) Resize(machines int) error {
+	if machines == l.Machines && !l.released {
+		return nil
+	}
+	e2e.Logf("Resizing the capacity %s to %d machines", l, machines)
+	if err := l.broker.hold(l, machines); err != nil {
+		return err
+	}
+	e2e.Logf("Resized the capacity %s", l)
+	return nil
+}
+
+// hold stores the lease with the machines once they fit besides the other leases, the machines the lease already holds
+// are kept without waiting. The lease is renewed until it's released.
+func (b *CapacityBroker) hold(lease *CapacityLease, machines int) error {
+	start := time.Now().UTC()
+	deadline := start.Add(b.WaitTimeout)
+	for {
+		var acquired bool
+		var fitErr error
+		var updated CapacityLease
+		b.lock.Lock()
+		err := b.store.update(func(leases map[string]*CapacityLease) bool {
+			now := time.Now().UTC()
+			expired := false
+			others := map[string]*CapacityLease{}
+			for id, l := range leases {
+				switch {
+				case id == lease.ID:
+				case now.After(l.ExpiresAt):
+					e2e.Logf("The capacity %s expired", l)
+					delete(leases, id)
+					expired = true
+				default:
+					others[id] = l
+				}
+			}
+			if held, ok := leases[lease.ID]; ok && machines <= held.Machines {
+				acquired = true
+			} else {
+				acquired, fitErr = b.fits(others, lease.InstanceType, machines)
+			}
+			if acquired {
+				updated = *lease
+				if updated.AcquiredAt.IsZero() || updated.released {
+					updated.AcquiredAt = now
+				}
+				updated.Machines, updated.ExpiresAt, updated.released = machines, now.Add(b.ttl), false
+				leases[lease.ID] = &updated
+			}
+			return acquired || expired
+		})
+		if err == nil && acquired {
+			lease.Machines, lease.AcquiredAt, lease.ExpiresAt, lease.released = updated.Machines, updated.AcquiredAt, updated.ExpiresAt, false
+			if lease.stop == nil {
+				lease.stop = make(chan struct{})
+				go b.renew(lease, lease.stop)
+			}
+		}
+		b.lock.Unlock()
+		if err == nil {
+			err = fitErr
+		}
+		if err != nil {
+			b.writeInterval(lease, pkgmonitor.Error, start, time.Now().UTC(), fmt.Sprintf("failed to acquire %d machines: %v", machines, err))
+			return err
+		}
+		if acquired {
+			if waited := time.Now().UTC().Sub(start); waited >= b.pollInterval {
+				b.writeInterval(lease, pkgmonitor.Warning, start, time.Now().UTC(), fmt.Sprintf("waited %s for the capacity", waited.Round(time.Second)))
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			err := fmt.Errorf("%w for %d %s machines after %s", ErrCapacityUnavailable, machines, lease.InstanceType, b.WaitTimeout)
+			b.writeInterval(lease, pkgmonitor.Error, start, time.Now().UTC(), err.Error())
+			return err
+		}
+		time.Sleep(b.pollInterval)
+	}
+}
+
+// renew extends the expiration of the lease every renewInterval until stop is closed
+func (b *CapacityBroker) renew(lease *CapacityLease, stop <-chan struct{}) {
+	ticker := time.NewTicker(b.renewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		b.lock.Lock()
+		var expiresAt time.Time
+		err := b.store.update(func(leases map[string]*CapacityLease) bool {
+			held, ok := leases[lease.ID]
+			if !ok {
+				// released, or expired while the test was stuck
+				return false
+			}
+			held.ExpiresAt = time.Now().UTC().Add(b.ttl)
+			expiresAt = held.ExpiresAt
+			return true
+		})
+		if err == nil && !expiresAt.IsZero() {
+			lease.ExpiresAt = expiresAt
+		}
+		b.lock.Unlock()
+		if err != nil {
+			e2e.Logf("failed to renew the capacity %s: %v", lease, err)
+		}
+	}
+}
+
+// Leases returns the current leases, ordered by acquisition
+func (b *CapacityBroker) Leases() ([]*CapacityLease, error) {
+	var current []*CapacityLease
+	err := b.store.update(func(leases map[string]*CapacityLease) bool {
+		for _, l := range leases {
+			current = append(current, l)
+		}
+		return false
+	})
+	sort.Slice(current, func(i, j int) bool { return current[i].AcquiredAt.Before(current[j].AcquiredAt) })
+	return current, err
+}
+
+// Release returns the machines of the lease to the budget, once they are deleted. Releasing twice is a no-op.
+func (l *CapacityLease) Release() error {
+	b := l.broker
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if l.released {
+		return nil
+	}
+	// the lease expires if it can't be deleted
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+	}
+	err := b.store.update(func(leases map[string]*CapacityLease) bool {
+		if _, ok := leases[l.ID]; !ok {
+			return false
+		}
+		delete(leases, l.ID)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release the capacity %s: %v", l, err)
+	}
+	l.released = true
+	b.writeInterval(l, pkgmonitor.Info, l.AcquiredAt, time.Now().UTC(), "held")
+	e2e.Logf("Released the capacity %s", l)
+	return nil
+}
+
+// writeInterval writes the lease to the monitor timeline of the test
+func (b *CapacityBroker) writeInterval(l *CapacityLease, level pkgmonitor.EventLevel, from, to time.Time, message string) {
+	locator := fmt.Sprintf("capacity-lease/%s machines/%d", l.ID, l.Machines)
+	if l.InstanceType != "" {
+		locator += " instance-type/" + l.InstanceType
+	}
+	interval := &pkgmonitor.EventInterval{
+		Condition: &pkgmonitor.Condition{Level: level, Locator: locator, Message: message},
+		From:      from,
+		To:        to,
+	}
+	if err := pkgmonitor.WriteIntervals(b.out, interval); err != nil {
+		e2e.Logf("failed to write the interval of the capacity %s: %v", l, err)
+	}
+}
+
+// configMapLeaseStore stores the leases in a ConfigMap, one key per lease
+type configMapLeaseStore struct {
+	oc   *exutil.CLI
+	name string
+}
+
+func (s *configMapLeaseStore) update(fn func(leases map[string]*CapacityLease) bool) error {
+	client := s.oc.AdminKubeClient().CoreV1().ConfigMaps(capacityLeaseNamespace)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm, err := client.Get(context.Background(), s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm, err = client.Create(context.Background(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: capacityLeaseNamespace}}, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// created by another process, retried as a conflict
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		leases := map[string]*CapacityLease{}
+		for id, value := range cm.Data {
+			l := &CapacityLease{}
+			if err := json.Unmarshal([]byte(value), l); err != nil {
+				e2e.Logf("Dropping the invalid capacity lease %s: %v", id, err)
+				continue
+			}
+			l.ID = id
+			leases[id] = l
+		}
+		if !fn(leases) {
+			return nil
+		}
+		cm.Data = map[string]string{}
+		for id, l := range leases {
+			data, err := json.Marshal(l)
+			if err != nil {
+				return err
+			}
+			cm.Data[id] = string(data)
+		}
+		_, err = client.Update(context.Background(), cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// AcquireCapacity leases the machines of the instance type for the current test and releases them when it ends, the
+// test is skipped if the budget of the run is too small for it and fails if the budget stays in use by the other tests
+func AcquireCapacity(oc *exutil.CLI, instanceType string, machines int) *CapacityLease {
+	broker, err := NewCapacityBroker(oc)
+	o.Expect(err).NotTo(o.HaveOccurred())
+	lease, err := broker.Acquire(instanceType, machines)
+	if err != nil {
+		skipOnCapacityExceedsBudget(err)
+		o.Expect(err).NotTo(o.HaveOccurred())
+	}
+	g.DeferCleanup(func() {
+		if err := lease.Release(); err != nil {
+			e2e.Logf("%v", err)
+		}
+	})
+	return lease
+}
+
+// skipOnCapacityExceedsBudget skips the test if the budget of the run is too small for it. The test isn't skipped when
+// the budget stays in use by the other tests, ErrCapacityUnavailable fails it.
+func skipOnCapacityExceedsBudget(err error) {
+	if errors.Is(err, ErrCapacityExceedsBudget) {
+		g.Skip(fmt.Sprintf("Skip this test scenario because of the capacity budget: %v", err))
+	}
+}
+
+// GetMachineSetInstanceType returns the instance type of the machines of the MachineSet, empty on the platforms
+// without instance types
+func GetMachineSetInstanceType(oc *exutil.CLI, machineSetName string) string {
+	instanceType, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(MapiMachineset, machineSetName, "-n", MachineAPINamespace,
+		"-o=jsonpath={.spec.template.spec.providerSpec.value.instanceType}{.spec.template.spec.providerSpec.value.machineType}{.spec.template.spec.providerSpec.value.vmSize}").Output()
+	o.Expect(err).NotTo(o.HaveOccurred())
+	return instanceType
+}
@@ -0,0 +1,273 @@
+package clusterinfra
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pkgmonitor "github.com/openshift/openshift-tests-private/pkg/monitor"
+)
+
+// memoryLeaseStore is a capacityLeaseStore shared by the brokers of a test, as the ConfigMap by the test processes
+type memoryLeaseStore struct {
+	lock   sync.Mutex
+	leases map[string]CapacityLease
+}
+
+func (s *memoryLeaseStore) update(fn func(leases map[string]*CapacityLease) bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	leases := map[string]*CapacityLease{}
+	for id, l := range s.leases {
+		copied := l
+		leases[id] = &copied
+	}
+	if fn(leases) {
+		s.leases = map[string]CapacityLease{}
+		for id, l := range leases {
+			s.leases[id] = *l
+		}
+	}
+	return nil
+}
+
+func TestParseCapacityBudget(t *testing.T) {
+	for _, tc := range []struct {
+		spec     string
+		platform string
+		machines int
+		types    map[string]int
+	}{
+		{"", "aws", -1, nil},
+		{"aws=4, aws/m6i.metal=1, gcp=2", "aws", 4, map[string]int{"m6i.metal": 1}},
+		{"aws=4,*=3", "azure", 3, nil},
+		{"aws/m6i.metal=1", "aws", -1, map[string]int{"m6i.metal": 1}},
+	} {
+		budget, err := ParseCapacityBudget(tc.spec, tc.platform)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.spec, err)
+		}
+		if budget.Machines != tc.machines || len(budget.InstanceTypes) != len(tc.types) {
+			t.Errorf("%q on %s: unexpected budget %+v", tc.spec, tc.platform, budget)
+		}
+		for instanceType, machines := range tc.types {
+			if budget.InstanceTypes[instanceType] != machines {
+				t.Errorf("%q on %s: unexpected budget %+v", tc.spec, tc.platform, budget)
+			}
+		}
+	}
+	for _, spec := range []string{"aws", "aws=x", "aws=-1"} {
+		if _, err := ParseCapacityBudget(spec, "aws"); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
+
+func TestCapacityBroker(t *testing.T) {
+	store := &memoryLeaseStore{}
+	newBroker := func() (*CapacityBroker, *bytes.Buffer) {
+		b := newCapacityBroker(CapacityBudget{Machines: 3, InstanceTypes: map[string]int{"m6i.metal": 1}}, store)
+		out := &bytes.Buffer{}
+		b.out, b.pollInterval, b.WaitTimeout = out, 10*time.Millisecond, time.Second
+		return b, out
+	}
+	first, firstOut := newBroker()
+	second, secondOut := newBroker()
+
+	if _, err := first.Acquire("", 4); !errors.Is(err, ErrCapacityExceedsBudget) {
+		t.Fatalf("expected the request to exceed the budget, got %v", err)
+	}
+	if _, err := first.Acquire("m6i.metal", 2); !errors.Is(err, ErrCapacityExceedsBudget) {
+		t.Fatalf("expected the request to exceed the instance type budget, got %v", err)
+	}
+
+	held, err := first.Acquire("m6i.metal", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := first.Acquire("m6i.xlarge", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the metal budget is used by the first lease, the second broker waits until it is released
+	acquired := make(chan *CapacityLease)
+	go func() {
+		lease, err := second.Acquire("m6i.metal", 1)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- lease
+	}()
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-acquired:
+		t.Fatalf("expected to wait for the metal lease")
+	default:
+	}
+	if err := held.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := held.Release(); err != nil {
+		t.Fatalf("expected releasing twice to be a no-op, got %v", err)
+	}
+	waited := <-acquired
+	if waited == nil {
+		t.Fatalf("expected the metal lease once released")
+	}
+
+	leases, err := second.Leases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leases) != 2 || leases[0].ID != other.ID || leases[1].ID != waited.ID {
+		t.Fatalf("unexpected leases %v", leases)
+	}
+
+	// the budget stays used until the timeout, unless the leases of dead tests expire
+	second.WaitTimeout = 30 * time.Millisecond
+	if _, err := second.Acquire("", 2); !errors.Is(err, ErrCapacityUnavailable) {
+		t.Fatalf("expected no capacity, got %v", err)
+	}
+	store.lock.Lock()
+	expired := store.leases[other.ID]
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	store.leases[other.ID] = expired
+	store.lock.Unlock()
+	if _, err := second.Acquire("", 2); err != nil {
+		t.Fatalf("expected the expired lease to be dropped, got %v", err)
+	}
+
+	intervals := pkgmonitor.ParseIntervals(firstOut.Bytes())
+	if len(intervals) != 3 || intervals[2].Level != pkgmonitor.Info || intervals[2].Locator != "capacity-lease/"+held.ID+" machines/1 instance-type/m6i.metal" {
+		t.Fatalf("unexpected intervals of the first broker %v", intervals)
+	}
+	intervals = pkgmonitor.ParseIntervals(secondOut.Bytes())
+	if len(intervals) != 2 || intervals[0].Level != pkgmonitor.Warning || intervals[1].Level != pkgmonitor.Error {
+		t.Fatalf("unexpected intervals of the second broker %v", intervals)
+	}
+}
+
+func TestCapacityBrokerClusterRoom(t *testing.T) {
+	b := newCapacityBroker(CapacityBudget{Machines: 6}, &memoryLeaseStore{})
+	b.out, b.pollInterval, b.WaitTimeout = &bytes.Buffer{}, time.Millisecond, 10*time.Millisecond
+	b.room = func(leased int) (int, error) { return 2, nil }
+	if _, err := b.Acquire("", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Acquire("", 1); !errors.Is(err, ErrCapacityUnavailable) {
+		t.Fatalf("expected the max nodes of the cluster to be reached, got %v", err)
+	}
+}
+
+func TestCapacityBrokerUnbounded(t *testing.T) {
+	b := newCapacityBroker(CapacityBudget{Machines: -1, InstanceTypes: map[string]int{"m6i.metal": 1}}, &memoryLeaseStore{})
+	b.out, b.pollInterval, b.WaitTimeout = &bytes.Buffer{}, time.Millisecond, 10*time.Millisecond
+	// the autoscaler tests lease up to the 10 max replicas of their machineset
+	if _, err := b.Acquire("", 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Acquire("m6i.xlarge", 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Acquire("m6i.metal", 2); !errors.Is(err, ErrCapacityExceedsBudget) {
+		t.Fatalf("expected the request to exceed the instance type budget, got %v", err)
+	}
+}
+
+func TestCapacityLeaseRenew(t *testing.T) {
+	store := &memoryLeaseStore{}
+	newBroker := func() *CapacityBroker {
+		b := newCapacityBroker(CapacityBudget{Machines: 1}, store)
+		b.out, b.pollInterval, b.WaitTimeout = &bytes.Buffer{}, time.Millisecond, 10*time.Millisecond
+		b.ttl, b.renewInterval = 50*time.Millisecond, 10*time.Millisecond
+		return b
+	}
+	first, second := newBroker(), newBroker()
+
+	lease, err := first.Acquire("", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the lease is renewed past its ttl while it's held
+	time.Sleep(150 * time.Millisecond)
+	if _, err := second.Acquire("", 1); !errors.Is(err, ErrCapacityUnavailable) {
+		t.Fatalf("expected the renewed lease to hold the budget, got %v", err)
+	}
+
+	// the renewal stops once the lease is released and doesn't store it again
+	if err := lease.Release(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if leases, err := second.Leases(); err != nil || len(leases) != 0 {
+		t.Fatalf("expected no leases, got %v: %v", leases, err)
+	}
+
+	// the lease of a test which stopped renewing it expires
+	held, err := second.Acquire("", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(held.stop)
+	held.stop = nil
+	time.Sleep(100 * time.Millisecond)
+	if _, err := first.Acquire("", 1); err != nil {
+		t.Fatalf("expected the lease without renewal to expire, got %v", err)
+	}
+}
+
+func TestCapacityLeaseResize(t *testing.T) {
+	store := &memoryLeaseStore{}
+	b := newCapacityBroker(CapacityBudget{Machines: 3}, store)
+	b.out, b.pollInterval, b.WaitTimeout = &bytes.Buffer{}, time.Millisecond, 10*time.Millisecond
+
+	lease, err := b.Acquire("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := b.Acquire("", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Resize(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Resize(3); !errors.Is(err, ErrCapacityUnavailable) {
+		t.Fatalf("expected the budget to be used by the other lease, got %v", err)
+	}
+	if err := lease.Resize(4); !errors.Is(err, ErrCapacityExceedsBudget) {
+		t.Fatalf("expected the request to exceed the budget, got %v", err)
+	}
+	if lease.Machines != 2 || store.leases[lease.ID].Machines != 2 {
+		t.Fatalf("expected the lease to keep 2 machines, got %d stored %d", lease.Machines, store.leases[lease.ID].Machines)
+	}
+
+	// shrinking never waits, even once the other leases use the budget
+	if err := lease.Resize(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Resize(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Resize(0); err != nil {
+		t.Fatal(err)
+	}
+
+	// a released lease is acquired again
+	if err := other.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Resize(1); err != nil {
+		t.Fatal(err)
+	}
+	leases, err := b.Leases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leases) != 2 || leases[0].ID != lease.ID || leases[1].ID != other.ID || leases[1].Machines != 1 {
+		t.Fatalf("unexpected leases %v", leases)
+	}
+}
@@ -18,6 +18,7 @@ import (
 func (ms *MachineSetDescription) CreateMachineSetByArch(oc *exutil.CLI, arch architecture.Architecture) {
 	e2e.Logf("Creating a new MachineSets ...")
 	machinesetName := GetRandomMachineSetNameByArch(oc, arch)
+	registerMachineSetLease(ms.Name, &machineSetLease{lease: AcquireCapacity(oc, GetMachineSetInstanceType(oc, machinesetName), ms.Replicas)})
 	machineSetJSON, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(MapiMachineset, machinesetName, "-n", MachineAPINamespace, "-o=json").OutputToFile("machineset.json")
 	o.Expect(err).NotTo(o.HaveOccurred())
 
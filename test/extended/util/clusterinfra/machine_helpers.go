@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	g "github.com/onsi/ginkgo/v2"
@@ -32,10 +33,85 @@ type MachineSetDescription struct {
 	Replicas int
 }
 
+// machineSetLease is the lease of the machines a test adds to a machineset, base is the number of replicas of the
+// machineset before the test, they are not leased
+type machineSetLease struct {
+	lease *CapacityLease
+	base  int
+}
+
+var (
+	machineSetLeasesLock sync.Mutex
+	// machineSetLeases are the leases of the machinesets created or scaled by the current test, by name
+	machineSetLeases = map[string]*machineSetLease{}
+)
+
+// LeaseMachineSetReplicas resizes the lease of the machineset to the replicas, i.e. before scaling it or before letting
+// the autoscaler scale it up to its max replicas. The machinesets which existed before the test are leased the
+// replicas above their current replicas, the lease is held until the end of the test or DeleteMachineSet.
+func LeaseMachineSetReplicas(oc *exutil.CLI, machineSetName string, replicas int) {
+	machineSetLeasesLock.Lock()
+	msl, ok := machineSetLeases[machineSetName]
+	machineSetLeasesLock.Unlock()
+	if !ok {
+		base := GetMachineSetReplicas(oc, machineSetName)
+		registerMachineSetLease(machineSetName, &machineSetLease{lease: AcquireCapacity(oc, GetMachineSetInstanceType(oc, machineSetName), max(replicas-base, 0)), base: base})
+		return
+	}
+	if err := msl.lease.Resize(max(replicas-msl.base, 0)); err != nil {
+		skipOnCapacityExceedsBudget(err)
+		o.Expect(err).NotTo(o.HaveOccurred())
+	}
+}
+
+// registerMachineSetLease keeps the lease of the machineset until the end of the test
+func registerMachineSetLease(machineSetName string, msl *machineSetLease) {
+	machineSetLeasesLock.Lock()
+	machineSetLeases[machineSetName] = msl
+	machineSetLeasesLock.Unlock()
+	g.DeferCleanup(func() {
+		machineSetLeasesLock.Lock()
+		defer machineSetLeasesLock.Unlock()
+		delete(machineSetLeases, machineSetName)
+	})
+}
+
+// isMachineSetLeased returns true if the current test holds a lease of the machineset
+func isMachineSetLeased(machineSetName string) bool {
+	machineSetLeasesLock.Lock()
+	defer machineSetLeasesLock.Unlock()
+	_, ok := machineSetLeases[machineSetName]
+	return ok
+}
+
+// waitForMachineCount waits until the machineset has at most count machines, the deleted machines included
+func waitForMachineCount(oc *exutil.CLI, machineSetName string, count int) error {
+	return wait.Poll(30*time.Second, 1200*time.Second, func() (bool, error) {
+		machineNames, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args(MapiMachine, "-o=jsonpath={.items[*].metadata.name}", "-l", "machine.openshift.io/cluster-api-machineset="+machineSetName, "-n", MachineAPINamespace).Output()
+		return len(strings.Fields(machineNames)) <= count, nil
+	})
+}
+
+// releaseMachineSetLease releases the lease of the machineset, once its machines are deleted
+func releaseMachineSetLease(machineSetName string) {
+	machineSetLeasesLock.Lock()
+	msl, ok := machineSetLeases[machineSetName]
+	delete(machineSetLeases, machineSetName)
+	machineSetLeasesLock.Unlock()
+	if !ok {
+		return
+	}
+	if err := msl.lease.Release(); err != nil {
+		e2e.Logf("%v", err)
+	}
+}
+
 // CreateMachineSet create a new machineset
 func (ms *MachineSetDescription) CreateMachineSet(oc *exutil.CLI) {
 	e2e.Logf("Creating a new MachineSets ...")
 	machinesetName := GetRandomMachineSetName(oc)
+	// the machineset is leased even without replicas, so that ScaleMachineSet and LeaseMachineSetReplicas resize its lease
+	registerMachineSetLease(ms.Name, &machineSetLease{lease: AcquireCapacity(oc, GetMachineSetInstanceType(oc, machinesetName), ms.Replicas)})
 	machineSetJSON, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(MapiMachineset, machinesetName, "-n", MachineAPINamespace, "-o=json").OutputToFile("machineset.json")
 	o.Expect(err).NotTo(o.HaveOccurred())
 
@@ -60,7 +136,17 @@ func (ms *MachineSetDescription) CreateMachineSet(oc *exutil.CLI) {
 // DeleteMachineSet delete a machineset
 func (ms *MachineSetDescription) DeleteMachineSet(oc *exutil.CLI) error {
 	e2e.Logf("Deleting a MachineSets ...")
-	return oc.AsAdmin().WithoutNamespace().Run("delete").Args(MapiMachineset, ms.Name, "-n", MachineAPINamespace).Execute()
+	err := oc.AsAdmin().WithoutNamespace().Run("delete").Args(MapiMachineset, ms.Name, "-n", MachineAPINamespace).Execute()
+	if err != nil || !isMachineSetLeased(ms.Name) {
+		return err
+	}
+	// the capacity is returned once the machines are gone, the lease is released at the end of the test otherwise
+	if waitErr := waitForMachineCount(oc, ms.Name, 0); waitErr != nil {
+		e2e.Logf("The machines of the machineset %s are not deleted yet, keeping its capacity lease", ms.Name)
+		return nil
+	}
+	releaseMachineSetLease(ms.Name)
+	return nil
 }
 
 // ListAllMachineNames list all machines
@@ -170,7 +256,7 @@ func GetLatestMachineFromMachineSet(oc *exutil.CLI, machineSet string) string {
 		o.Expect(err).NotTo(o.HaveOccurred())
 		parsedMachineCreationTime, err := time.Parse(time.RFC3339, machineCreationTime)
 		if err != nil {
-			e2e.Logf("Error parsing time:", err)
+			e2e.Logf("Error parsing time: %v", err)
 			return ""
 		}
 		if parsedMachineCreationTime.After(newest) {
@@ -218,9 +304,21 @@ func GetMachineSetReplicas(oc *exutil.CLI, machineSetName string) int {
 // ScaleMachineSet scale a MachineSet by replicas
 func ScaleMachineSet(oc *exutil.CLI, machineSetName string, replicas int) {
 	e2e.Logf("Scaling MachineSets ...")
+	// the lease grows before the machines are created and shrinks once they are deleted
+	current := GetMachineSetReplicas(oc, machineSetName)
+	if replicas > current {
+		LeaseMachineSetReplicas(oc, machineSetName, replicas)
+	}
 	_, err := oc.AsAdmin().WithoutNamespace().Run("scale").Args("--replicas="+strconv.Itoa(replicas), MapiMachineset, machineSetName, "-n", MachineAPINamespace).Output()
 	o.Expect(err).NotTo(o.HaveOccurred())
 	WaitForMachinesRunning(oc, replicas, machineSetName)
+	if replicas < current && isMachineSetLeased(machineSetName) {
+		if err := waitForMachineCount(oc, machineSetName, replicas); err != nil {
+			e2e.Logf("The machines of the machineset %s are not deleted yet, keeping its capacity lease", machineSetName)
+			return
+		}
+		LeaseMachineSetReplicas(oc, machineSetName, replicas)
+	}
 }
 
 // DeleteMachine delete a machine
@@ -0,0 +1,264 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// ClusterRole is the role of a cluster in a ClusterSet
+type ClusterRole string
+
+const (
+	// ClusterRoleManagement is the cluster the test runs against, it hosts the control planes of the hosted clusters
+	ClusterRoleManagement ClusterRole = "management"
+	// ClusterRoleHosted is the guest cluster of a HostedCluster
+	ClusterRoleHosted ClusterRole = "hosted"
+	// ClusterRoleHub is the ACM hub managing the management cluster
+	ClusterRoleHub ClusterRole = "hub"
+)
+
+// Cluster is one cluster of a ClusterSet, the commands and the clients are those of the cluster admin and target the
+// namespace of the cluster, so that the namespace of a cluster is never used on another one
+type Cluster struct {
+	role       ClusterRole
+	name       string
+	kubeconfig string
+	cli        *CLI
+
+	lock               sync.Mutex
+	namespacesToDelete []string
+}
+
+// newCluster returns the cluster of the kubeconfig, it has its own framework so that its namespace is independent
+// of the one of the test
+func newCluster(oc *CLI, role ClusterRole, name, kubeconfig string) *Cluster {
+	cli := &CLI{
+		execPath:        oc.execPath,
+		configPath:      kubeconfig,
+		adminConfigPath: kubeconfig,
+		username:        "admin",
+		showInfo:        oc.showInfo,
+		kubeFramework:   &e2e.Framework{BaseName: oc.KubeFramework().BaseName},
+	}
+	return &Cluster{role: role, name: name, kubeconfig: kubeconfig, cli: cli}
+}
+
+// Role returns the role of the cluster
+func (c *Cluster) Role() ClusterRole {
+	return c.role
+}
+
+// Name returns the name of the cluster, i.e. the name of the HostedCluster
+func (c *Cluster) Name() string {
+	return c.name
+}
+
+// Kubeconfig returns the path of the admin kubeconfig of the cluster
+func (c *Cluster) Kubeconfig() string {
+	return c.kubeconfig
+}
+
+// Namespace returns the namespace of the cluster, the namespace of the test for the management cluster
+func (c *Cluster) Namespace() string {
+	return c.cli.Namespace()
+}
+
+// SetNamespace sets the namespace of the commands run on the cluster, it's not allowed on the management cluster
+// whose namespace is the one of the test
+func (c *Cluster) SetNamespace(ns string) error {
+	if c.role == ClusterRoleManagement {
+		return fmt.Errorf("the namespace of the management cluster is the namespace of the test, use oc.SetNamespace instead")
+	}
+	c.cli.SetNamespace(ns)
+	return nil
+}
+
+// CLI returns the admin CLI of the cluster
+func (c *Cluster) CLI() *CLI {
+	return c.cli.AsAdmin()
+}
+
+// Run runs the oc command in the namespace of the cluster, or without namespace if the cluster has none
+func (c *Cluster) Run(commands ...string) *CLI {
+	if c.Namespace() == "" {
+		return c.WithoutNamespace().Run(commands...)
+	}
+	return c.CLI().Run(commands...)
+}
+
+// WithoutNamespace returns the admin CLI of the cluster which doesn't add the namespace to the commands
+func (c *Cluster) WithoutNamespace() *CLI {
+	return c.CLI().WithoutNamespace()
+}
+
+// Config returns the admin client config of the cluster
+func (c *Cluster) Config() *rest.Config {
+	return c.cli.AdminConfig()
+}
+
+// KubeClient returns the admin Kubernetes client of the cluster
+func (c *Cluster) KubeClient() kubernetes.Interface {
+	return c.cli.AdminKubeClient()
+}
+
+// DynamicClient returns the admin dynamic client of the cluster
+func (c *Cluster) DynamicClient() dynamic.Interface {
+	return c.cli.AdminDynamicClient()
+}
+
+// ConfigClient returns the admin client of the config.openshift.io API of the cluster
+func (c *Cluster) ConfigClient() configv1client.Interface {
+	return c.cli.AdminConfigClient()
+}
+
+// CreateNamespace creates the namespace on the cluster, sets it as the namespace of the cluster and deletes it on
+// ClusterSet.Cleanup
+func (c *Cluster) CreateNamespace(ns string) error {
+	_, err := c.KubeClient().CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s on %s cluster %s: %v", ns, c.role, c.name, err)
+	}
+	c.lock.Lock()
+	c.namespacesToDelete = append(c.namespacesToDelete, ns)
+	c.lock.Unlock()
+	if c.role != ClusterRoleManagement {
+		c.cli.SetNamespace(ns)
+	}
+	return nil
+}
+
+// deleteNamespaces deletes the namespaces created by CreateNamespace
+func (c *Cluster) deleteNamespaces() error {
+	c.lock.Lock()
+	namespaces := c.namespacesToDelete
+	c.namespacesToDelete = nil
+	c.lock.Unlock()
+
+	var errs []error
+	for _, ns := range namespaces {
+		e2e.Logf("Deleting namespace %s of %s cluster %s", ns, c.role, c.name)
+		if err := c.KubeClient().CoreV1().Namespaces().Delete(context.Background(), ns, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete namespace %s on %s cluster %s: %v", ns, c.role, c.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// ClusterSet is the management cluster of a test and the hosted and hub clusters related to it, so that the tests
+// pick the cluster of every command and client instead of switching the kubeconfig of a CLI
+type ClusterSet struct {
+	management *Cluster
+	hosted     *Cluster
+	hub        *Cluster
+
+	// HostedClusterNamespace is the namespace of the HostedCluster on the management cluster
+	HostedClusterNamespace string
+}
+
+// NewClusterSet returns the set of the management cluster of the CLI, and of the hosted cluster if the guest
+// kubeconfig of the CLI is set
+func NewClusterSet(oc *CLI) *ClusterSet {
+	s := &ClusterSet{management: &Cluster{role: ClusterRoleManagement, name: "management", kubeconfig: oc.adminConfigPath, cli: oc}}
+	if oc.GetGuestKubeconf() != "" {
+		s.hosted = newCluster(oc, ClusterRoleHosted, "hosted", oc.GetGuestKubeconf())
+	}
+	return s
+}
+
+// NewClusterSetFromKubeconfigs returns the set of the management cluster of the kubeconfig, and of the hosted cluster
+// if hostedKubeconfig is set, it's meant for the test runner which has no CLI of a test, i.e. to monitor the clusters
+func NewClusterSetFromKubeconfigs(kubeconfig, hostedKubeconfig string) *ClusterSet {
+	oc := &CLI{
+		execPath:        "oc",
+		configPath:      kubeconfig,
+		adminConfigPath: kubeconfig,
+		username:        "admin",
+		kubeFramework:   &e2e.Framework{BaseName: "clusterset"},
+	}
+	oc.SetGuestKubeconf(hostedKubeconfig)
+	return NewClusterSet(oc)
+}
+
+// NewHypershiftClusterSet returns the set of the management cluster and of its first hosted cluster, the test is
+// skipped if there is no hosted cluster. The guest kubeconfig of the CLI is set as well for the helpers which still
+// use AsGuestKubeconf.
+func NewHypershiftClusterSet(oc *CLI) *ClusterSet {
+	name, kubeconfig, namespace := ValidHypershiftAndGetGuestKubeConf(oc)
+	oc.SetGuestKubeconf(kubeconfig)
+	return NewClusterSet(oc).WithHosted(name, namespace, kubeconfig)
+}
+
+// WithHosted sets the hosted cluster, hostedClusterNS is the namespace of the HostedCluster on the management cluster
+func (s *ClusterSet) WithHosted(name, hostedClusterNS, kubeconfig string) *ClusterSet {
+	s.hosted = newCluster(s.management.cli, ClusterRoleHosted, name, kubeconfig)
+	s.HostedClusterNamespace = hostedClusterNS
+	return s
+}
+
+// WithHub sets the hub cluster
+func (s *ClusterSet) WithHub(name, kubeconfig string) *ClusterSet {
+	s.hub = newCluster(s.management.cli, ClusterRoleHub, name, kubeconfig)
+	return s
+}
+
+// Management returns the management cluster
+func (s *ClusterSet) Management() *Cluster {
+	return s.management
+}
+
+// Hosted returns the hosted cluster and whether the set has one, it's set by NewHypershiftClusterSet or WithHosted
+func (s *ClusterSet) Hosted() (*Cluster, bool) {
+	return s.hosted, s.hosted != nil
+}
+
+// Hub returns the hub cluster and whether the set has one, it's set by WithHub
+func (s *ClusterSet) Hub() (*Cluster, bool) {
+	return s.hub, s.hub != nil
+}
+
+// HostedControlPlaneNamespace returns the namespace of the control plane of the hosted cluster on the management
+// cluster, it's empty if the set has no hosted cluster
+func (s *ClusterSet) HostedControlPlaneNamespace() string {
+	if s.hosted == nil {
+		return ""
+	}
+	return s.HostedClusterNamespace + "-" + s.hosted.Name()
+}
+
+// Clusters returns the clusters of the set, the management cluster first
+func (s *ClusterSet) Clusters() []*Cluster {
+	clusters := []*Cluster{s.management}
+	for _, c := range []*Cluster{s.hosted, s.hub} {
+		if c != nil {
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters
+}
+
+// Cleanup deletes the namespaces created by CreateNamespace on every cluster
+func (s *ClusterSet) Cleanup() error {
+	var errs []error
+	for _, c := range s.Clusters() {
+		if err := c.deleteNamespaces(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+func TestClusterSet(t *testing.T) {
+	oc := &CLI{execPath: "oc", configPath: "/mgmt-user", adminConfigPath: "/mgmt", kubeFramework: &e2e.Framework{BaseName: "clusterset"}}
+	oc.SetNamespace("e2e-test")
+
+	set := NewClusterSet(oc)
+	_, hasHosted := set.Hosted()
+	_, hasHub := set.Hub()
+	if hasHosted || hasHub || len(set.Clusters()) != 1 || set.HostedControlPlaneNamespace() != "" {
+		t.Fatalf("expected only the management cluster, got %d clusters", len(set.Clusters()))
+	}
+	set.WithHosted("hc1", "clusters", "/guest").WithHub("hub", "/hub")
+	if len(set.Clusters()) != 3 || set.HostedControlPlaneNamespace() != "clusters-hc1" {
+		t.Fatalf("got %d clusters and control plane namespace %s", len(set.Clusters()), set.HostedControlPlaneNamespace())
+	}
+	hosted, _ := set.Hosted()
+	hub, _ := set.Hub()
+	if err := set.Management().SetNamespace("guest-app"); err == nil {
+		t.Errorf("expected an error setting the namespace of the management cluster")
+	}
+	hostedRun := hosted.Run("get", "pods")
+	if err := hosted.SetNamespace("guest-app"); err != nil {
+		t.Fatalf("SetNamespace() = %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		cli      *CLI
+		expected []string
+	}{
+		{
+			name:     "management in the namespace of the test",
+			cli:      set.Management().Run("get", "pods"),
+			expected: []string{"--namespace=e2e-test", "--kubeconfig=/mgmt", "get", "pods"},
+		},
+		{
+			name:     "hosted without namespace",
+			cli:      hostedRun,
+			expected: []string{"--kubeconfig=/guest", "get", "pods"},
+		},
+		{
+			name:     "hosted in its own namespace",
+			cli:      hosted.Run("get", "pods"),
+			expected: []string{"--namespace=guest-app", "--kubeconfig=/guest", "get", "pods"},
+		},
+		{
+			name:     "hub without namespace",
+			cli:      hub.WithoutNamespace().Run("get", "managedclusters"),
+			expected: []string{"--kubeconfig=/hub", "get", "managedclusters"},
+		},
+	} {
+		if !reflect.DeepEqual(tc.cli.globalArgs, tc.expected) {
+			t.Errorf("%s: got args %v, expected %v", tc.name, tc.cli.globalArgs, tc.expected)
+		}
+	}
+
+	// the namespace of the hosted cluster never leaks to the management cluster
+	if oc.Namespace() != "e2e-test" || set.Management().Namespace() != "e2e-test" || hub.Namespace() != "" {
+		t.Errorf("got namespaces %q, %q and %q", oc.Namespace(), set.Management().Namespace(), hub.Namespace())
+	}
+
+	set = NewClusterSetFromKubeconfigs("/mgmt", "/guest")
+	if hosted, ok := set.Hosted(); !ok || hosted.Kubeconfig() != "/guest" || set.Management().Kubeconfig() != "/mgmt" {
+		t.Errorf("expected the management and the hosted clusters of the kubeconfigs")
+	}
+	if _, ok := NewClusterSetFromKubeconfigs("/mgmt", "").Hosted(); ok {
+		t.Errorf("expected no hosted cluster without its kubeconfig")
+	}
+}
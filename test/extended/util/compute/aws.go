@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"fmt"
@@ -16,11 +16,11 @@ type awsInstance struct {
 }
 
 // GetAwsNodes get nodes and load clouds cred with the specified label.
-func GetAwsNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetAwsNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	clusterinfra.GetAwsCredentialFromCluster(oc)
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newAwsInstance(oc, exutil.InitAwsSession(), nodeName))
 	}
@@ -51,7 +51,7 @@ func (a *awsInstance) Start() error {
 	o.Expect(err).NotTo(o.HaveOccurred())
 	instanceState, err := a.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := stopStates[instanceState]; ok {
+	if _, ok := StoppedStates[instanceState]; ok {
 		err = a.client.StartInstance(instanceID)
 		if err != nil {
 			return fmt.Errorf("start instance failed with error :: %v", err)
@@ -67,7 +67,7 @@ func (a *awsInstance) Stop() error {
 	o.Expect(err).NotTo(o.HaveOccurred())
 	instanceState, err := a.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := startStates[instanceState]; ok {
+	if _, ok := RunningStates[instanceState]; ok {
 		err = a.client.StopInstance(instanceID)
 		if err != nil {
 			return fmt.Errorf("stop instance failed with error :: %v", err)
@@ -88,3 +88,27 @@ func (a *awsInstance) State() (string, error) {
 	}
 	return strings.ToLower(instanceState), nil
 }
+
+func (a *awsInstance) ForceStop() error {
+	instanceID, err := a.client.GetAwsInstanceIDFromHostname(a.nodeName)
+	if err != nil {
+		return err
+	}
+	return a.client.ForceStopInstance(instanceID)
+}
+
+func (a *awsInstance) Reboot() error {
+	instanceID, err := a.client.GetAwsInstanceIDFromHostname(a.nodeName)
+	if err != nil {
+		return err
+	}
+	return a.client.RebootInstance(instanceID)
+}
+
+func (a *awsInstance) GetConsoleLog() (string, error) {
+	instanceID, err := a.client.GetAwsInstanceIDFromHostname(a.nodeName)
+	if err != nil {
+		return "", err
+	}
+	return a.client.GetAwsInstanceConsoleOutput(instanceID)
+}
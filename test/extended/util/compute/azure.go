@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"fmt"
@@ -21,14 +21,14 @@ type azureInstance struct {
 }
 
 // GetAzureNodes get nodes and load clouds cred with the specified label.
-func GetAzureNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetAzureNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	azureRGname, rgerr := exutil.GetAzureCredentialFromCluster(oc)
 	o.Expect(rgerr).NotTo(o.HaveOccurred())
 	azureSession, sessErr := exutil.NewAzureSessionFromEnv()
 	o.Expect(sessErr).NotTo(o.HaveOccurred())
-	isAzureStack, cloudName := isAzureStackCluster(oc)
+	isAzureStack, cloudName := IsAzureStackCluster(oc)
 	if isAzureStack {
 		var filePath string
 		filePath = os.Getenv("SHARED_DIR") + "/azurestack-login-script.sh"
@@ -53,7 +53,7 @@ func GetAzureNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
 			e2e.Failf("Not able to run azure cli successfully :: %s :: %s", string(vmOutput), azcmdErr)
 		}
 	}
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newAzureInstance(oc, azureSession, azureRGname, nodeName, strings.ToLower(cloudName)))
 	}
@@ -113,3 +113,28 @@ func (az *azureInstance) State() (string, error) {
 	o.Expect(err).NotTo(o.HaveOccurred())
 	return instanceState, err
 }
+
+// ForceStop powers off the VM without shutting down the guest
+func (az *azureInstance) ForceStop() error {
+	if az.azureCloudType == "azurestackcloud" {
+		return exutil.ForceStopAzureStackVM(az.azureRGname, az.nodeName)
+	}
+	// StopAzureVM skips the shutdown of the guest already
+	_, err := exutil.StopAzureVM(az.client, az.nodeName, az.azureRGname)
+	return err
+}
+
+func (az *azureInstance) Reboot() error {
+	return powerCycle(az, az.Stop)
+}
+
+// IsAzureStackCluster returns true and the cloud name if the cluster is on Azure Stack Hub
+func IsAzureStackCluster(oc *exutil.CLI) (bool, string) {
+	cloudName, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("infrastructure", "cluster", "-o=jsonpath={.status.platformStatus.azure.cloudName}").Output()
+	o.Expect(err).NotTo(o.HaveOccurred())
+	if strings.ToLower(cloudName) == "azurestackcloud" {
+		e2e.Logf("This is Azure Stack cluster.")
+		return true, cloudName
+	}
+	return false, ""
+}
@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"fmt"
@@ -26,10 +26,10 @@ func newBaremetalIPIInstance(oc *exutil.CLI, nodeName string) *baremetalIPIInsta
 }
 
 // GetBaremetalNodes get nodes and load clouds cred with the specified label.
-func GetBaremetalNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetBaremetalNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newBaremetalIPIInstance(oc, nodeName))
 	}
@@ -102,3 +102,12 @@ func GetBmhNodeMachineConfig(oc *exutil.CLI, vmInstance string) (string, error)
 	}
 	return masterNodeMachineConfig, bmhErr
 }
+
+// ForceStop isn't supported, the baremetal operator powers off the host after trying a soft power off
+func (ipi *baremetalIPIInstance) ForceStop() error {
+	return ErrNotSupported
+}
+
+func (ipi *baremetalIPIInstance) Reboot() error {
+	return powerCycle(ipi, ipi.Stop)
+}
@@ -0,0 +1,218 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	g "github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+)
+
+// ErrNotSupported is returned by the operations the platform of the node can't do, e.g. GetConsoleLog on vSphere
+var ErrNotSupported = errors.New("not supported on this platform")
+
+var (
+	// RunningStates are the lower case states of a powered on instance on every platform
+	RunningStates = map[string]bool{
+		exutil.BMPoweredOn: true,
+		"running":          true,
+		"active":           true,
+		"ready":            true,
+	}
+	// StoppedStates are the lower case states of a powered off instance on every platform
+	StoppedStates = map[string]bool{
+		exutil.BMPoweredOff: true,
+		"stopped":           true,
+		"shutoff":           true,
+		"terminated":        true,
+		"paused":            true,
+		"deallocated":       true,
+		"notready":          true,
+	}
+
+	// pollInterval is the interval of WaitForState
+	pollInterval = 10 * time.Second
+)
+
+// Metadata is the placement of the instance of a node
+type Metadata struct {
+	Platform     string
+	Region       string
+	Zone         string
+	InstanceType string
+}
+
+// Node is the instance of a cluster node which can be power cycled the same way on every platform
+type Node interface {
+	GetName() string
+	GetInstanceID() (string, error)
+	Start() error
+	Stop() error
+	// ForceStop powers off the instance without a graceful shutdown of the guest, ErrNotSupported if the platform
+	// always shuts down the guest first
+	ForceStop() error
+	// Reboot restarts the instance, it returns once the restart is requested
+	Reboot() error
+	// State returns the lower case state of the instance, see RunningStates and StoppedStates
+	State() (string, error)
+	// GetConsoleLog returns the serial console output of the instance, ErrNotSupported if the platform has none
+	GetConsoleLog() (string, error)
+	Metadata() (Metadata, error)
+}
+
+// Nodes is a list of Node
+type Nodes []Node
+
+// Names returns the names of the nodes
+func (n Nodes) Names() []string {
+	names := make([]string, 0, len(n))
+	for _, node := range n {
+		names = append(names, node.GetName())
+	}
+	return names
+}
+
+// ByName returns the node with the name, nil if there is none
+func (n Nodes) ByName(name string) Node {
+	for _, node := range n {
+		if node.GetName() == name {
+			return node
+		}
+	}
+	return nil
+}
+
+// IsRunning returns true if the state is one of RunningStates
+func IsRunning(state string) bool {
+	return RunningStates[strings.ToLower(state)]
+}
+
+// IsStopped returns true if the state is one of StoppedStates
+func IsStopped(state string) bool {
+	return StoppedStates[strings.ToLower(state)]
+}
+
+// WaitForState waits until the state of the node is one of states, e.g. RunningStates, the errors getting the
+// state are retried until the timeout
+func WaitForState(n Node, states map[string]bool, timeout time.Duration) error {
+	var state string
+	var stateErr error
+	err := wait.PollUntilContextTimeout(context.Background(), pollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		state, stateErr = n.State()
+		if stateErr != nil {
+			e2e.Logf("Failed to get the state of %s :: %v", n.GetName(), stateErr)
+			return false, nil
+		}
+		return states[strings.ToLower(state)], nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s didn't reach the expected state in %v, last state %q, last error %v", n.GetName(), timeout, state, stateErr)
+	}
+	e2e.Logf("%s is %s", n.GetName(), state)
+	return nil
+}
+
+// WaitForRunning waits until the node is in one of RunningStates
+func WaitForRunning(n Node, timeout time.Duration) error {
+	return WaitForState(n, RunningStates, timeout)
+}
+
+// WaitForStopped waits until the node is in one of StoppedStates
+func WaitForStopped(n Node, timeout time.Duration) error {
+	return WaitForState(n, StoppedStates, timeout)
+}
+
+// GetNodes gets the nodes with the specified label and the client of the platform of the cluster, the test is
+// skipped on the platforms without power control. The returned function, if not nil, releases the client.
+func GetNodes(oc *exutil.CLI, label string) (Nodes, func()) {
+	platform := exutil.CheckPlatform(oc)
+	switch platform {
+	case "aws":
+		e2e.Logf("\n AWS is detected, running the case on AWS\n")
+		return GetAwsNodes(oc, label)
+	case "gcp":
+		e2e.Logf("\n GCP is detected, running the case on gcp\n")
+		return GetGcpNodes(oc, label)
+	case "vsphere":
+		e2e.Logf("\n vsphere is detected, running the case on vsphere\n")
+		return GetVsphereNodes(oc, label)
+	case "openstack":
+		e2e.Logf("\n OSP is detected, running the case on osp\n")
+		return GetOspNodes(oc, label)
+	case "azure":
+		e2e.Logf("\n Azure is detected, running the case on azure\n")
+		return GetAzureNodes(oc, label)
+	case "baremetal":
+		e2e.Logf("\n IPI Baremetal is detected, running the case on baremetal\n")
+		return GetBaremetalNodes(oc, label)
+	case "none":
+		e2e.Logf("\n UPI Baremetal is detected, running the case on baremetal\n")
+		return GetUPIBaremetalNodes(oc, label)
+	case "ibmcloud":
+		e2e.Logf("\n IBM is detected, running the case on IBM\n")
+		return GetIbmNodes(oc, label)
+	case "nutanix":
+		e2e.Logf("\n Nutanix is detected, running the case on nutanix\n")
+		return GetNutanixNodes(oc, label)
+	case "powervs":
+		e2e.Logf("\n IBM Powervs is detected, running the case on PowerVs\n")
+		return GetIBMPowerNodes(oc, label)
+	default:
+		g.Skip("Not support cloud provider for power control of nodes for now. Test cases should be run on IBM or vsphere or aws or gcp or openstack or azure or baremetal or nutanix or powervs, skip for other platforms!!")
+	}
+	return nil, nil
+}
+
+// instance is the part of a Node common to all the platforms
+type instance struct {
+	nodeName string
+	oc       *exutil.CLI
+}
+
+func (i *instance) GetName() string {
+	return i.nodeName
+}
+
+// GetConsoleLog is not supported unless the platform overrides it
+func (i *instance) GetConsoleLog() (string, error) {
+	return "", ErrNotSupported
+}
+
+// Metadata returns the placement of the node from its well-known labels
+func (i *instance) Metadata() (Metadata, error) {
+	labels, err := i.oc.AsAdmin().WithoutNamespace().Run("get").Args("node", i.nodeName,
+		`-o=jsonpath={.metadata.labels.topology\.kubernetes\.io/region}{"\n"}{.metadata.labels.topology\.kubernetes\.io/zone}{"\n"}{.metadata.labels.node\.kubernetes\.io/instance-type}`).Output()
+	if err != nil {
+		return Metadata{}, fmt.Errorf("failed to get the labels of node %s: %v", i.nodeName, err)
+	}
+	fields := strings.Split(labels, "\n")
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+	return Metadata{
+		Platform:     exutil.CheckPlatform(i.oc),
+		Region:       fields[0],
+		Zone:         fields[1],
+		InstanceType: fields[2],
+	}, nil
+}
+
+// powerCycle reboots the node by stopping and starting it, for the platforms which can't restart an instance
+func powerCycle(n Node, stop func() error) error {
+	if err := stop(); err != nil {
+		return fmt.Errorf("failed to stop %s for the reboot: %v", n.GetName(), err)
+	}
+	if err := WaitForStopped(n, 10*time.Minute); err != nil {
+		return err
+	}
+	if err := n.Start(); err != nil {
+		return fmt.Errorf("failed to start %s for the reboot: %v", n.GetName(), err)
+	}
+	return nil
+}
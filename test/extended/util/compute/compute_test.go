@@ -0,0 +1,96 @@
+package compute
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFakeNodePowerCycle(t *testing.T) {
+	pollInterval = time.Millisecond
+	provider := NewFakeProvider()
+	node := provider.AddNode("master-0", Metadata{Platform: "fake", Zone: "zone-a", InstanceType: "m5.xlarge"}).SetTransitionSteps(2)
+	provider.AddNode("master-1", Metadata{Platform: "fake", Zone: "zone-b"})
+
+	if err := node.Start(); err == nil {
+		t.Fatalf("expected an error starting a running node")
+	}
+	if err := node.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	if state, _ := node.State(); state != FakeStateStopping {
+		t.Fatalf("expected %s, got %s", FakeStateStopping, state)
+	}
+	if err := WaitForStopped(node, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	if err := WaitForRunning(node, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := node.Reboot(); err != nil {
+		t.Fatalf("failed to reboot: %v", err)
+	}
+	if err := WaitForRunning(node, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := node.ForceStop(); err != nil {
+		t.Fatalf("failed to force stop: %v", err)
+	}
+
+	expected := []string{"Start master-0", "Stop master-0", "Start master-0", "Reboot master-0", "ForceStop master-0"}
+	if calls := provider.Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	console, _ := node.GetConsoleLog()
+	if console != "master-0: shutdown\nmaster-0: boot\nmaster-0: reboot\nmaster-0: power off" {
+		t.Fatalf("unexpected console log %q", console)
+	}
+	if metadata, _ := provider.Nodes().ByName("master-1").Metadata(); metadata.Zone != "zone-b" {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+	if names := provider.Nodes().Names(); !reflect.DeepEqual(names, []string{"master-0", "master-1"}) {
+		t.Fatalf("unexpected names %v", names)
+	}
+}
+
+func TestFakeNodeErrors(t *testing.T) {
+	pollInterval = time.Millisecond
+	node := NewFakeProvider().AddNode("worker-0", Metadata{})
+
+	stopErr := errors.New("quota exceeded")
+	node.SetError("Stop", stopErr)
+	if err := node.Stop(); err != stopErr {
+		t.Fatalf("expected %v, got %v", stopErr, err)
+	}
+	node.SetError("Stop", nil)
+	if err := node.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+
+	node.SetError("State", errors.New("unreachable"))
+	if err := WaitForStopped(node, 20*time.Millisecond); err == nil {
+		t.Fatalf("expected a timeout while the state can't be read")
+	}
+	node.SetError("State", nil)
+	if err := WaitForStopped(node, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := WaitForRunning(node.SetState("paused"), 20*time.Millisecond); err == nil {
+		t.Fatalf("expected a timeout waiting for a paused node to run")
+	}
+}
+
+func TestStates(t *testing.T) {
+	for state, running := range map[string]bool{"running": true, "RUNNING": true, "poweredOn": true, "active": true, "stopped": false, "deallocated": false} {
+		if IsRunning(state) != running || IsStopped(state) == running {
+			t.Errorf("unexpected state %s, running %v", state, IsRunning(state))
+		}
+	}
+	if IsRunning(FakeStatePending) || IsStopped(FakeStatePending) {
+		t.Errorf("%s is a transitional state", FakeStatePending)
+	}
+}
@@ -0,0 +1,216 @@
+package compute
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Transitional states of the fake nodes
+const (
+	FakeStatePending   = "pending"
+	FakeStateStopping  = "stopping"
+	FakeStateRebooting = "rebooting"
+)
+
+// FakeProvider is an in-memory platform whose nodes follow the power actions, so that the orchestration of the power
+// cycles, e.g. the disaster recovery of a control plane node, can be unit tested without a cluster
+type FakeProvider struct {
+	lock  sync.Mutex
+	nodes []*FakeNode
+	calls []string
+}
+
+// NewFakeProvider returns a provider without nodes
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{}
+}
+
+// AddNode adds a running node
+func (p *FakeProvider) AddNode(name string, metadata Metadata) *FakeNode {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	n := &FakeNode{
+		provider:   p,
+		name:       name,
+		instanceID: fmt.Sprintf("fake-%d", len(p.nodes)),
+		metadata:   metadata,
+		state:      "running",
+		errors:     map[string]error{},
+	}
+	p.nodes = append(p.nodes, n)
+	return n
+}
+
+// Nodes returns the nodes in the order they were added
+func (p *FakeProvider) Nodes() Nodes {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	nodes := make(Nodes, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// Calls returns the power actions done on the nodes, e.g. "Stop master-0"
+func (p *FakeProvider) Calls() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]string{}, p.calls...)
+}
+
+// FakeNode is a node of a FakeProvider
+type FakeNode struct {
+	provider   *FakeProvider
+	name       string
+	instanceID string
+	metadata   Metadata
+
+	state     string
+	target    string
+	remaining int
+	steps     int
+	errors    map[string]error
+	console   []string
+}
+
+// SetTransitionSteps sets the number of State calls which return the transitional state, e.g. stopping, before the
+// node reaches the state of the action, 0 by default so that the actions are immediate
+func (n *FakeNode) SetTransitionSteps(steps int) *FakeNode {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	n.steps = steps
+	return n
+}
+
+// SetState sets the state of the node, any transition in progress is dropped
+func (n *FakeNode) SetState(state string) *FakeNode {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	n.state = state
+	n.remaining = 0
+	return n
+}
+
+// SetError makes the method of the node return err until it's set to nil, method is the name of the method of
+// Node, e.g. Stop or State
+func (n *FakeNode) SetError(method string, err error) *FakeNode {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	n.errors[method] = err
+	return n
+}
+
+func (n *FakeNode) GetName() string {
+	return n.name
+}
+
+func (n *FakeNode) GetInstanceID() (string, error) {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	if err := n.errors["GetInstanceID"]; err != nil {
+		return "", err
+	}
+	return n.instanceID, nil
+}
+
+func (n *FakeNode) Start() error {
+	return n.act("Start", func() error {
+		if !IsStopped(n.state) {
+			return fmt.Errorf("unable to start instance %s from status %s", n.name, n.state)
+		}
+		n.transition(FakeStatePending, "running", "boot")
+		return nil
+	})
+}
+
+func (n *FakeNode) Stop() error {
+	return n.act("Stop", func() error {
+		if !IsRunning(n.state) {
+			return fmt.Errorf("unable to stop instance %s from status %s", n.name, n.state)
+		}
+		n.transition(FakeStateStopping, "stopped", "shutdown")
+		return nil
+	})
+}
+
+// ForceStop stops the node from any state but stopped
+func (n *FakeNode) ForceStop() error {
+	return n.act("ForceStop", func() error {
+		if IsStopped(n.state) {
+			return fmt.Errorf("unable to force stop instance %s from status %s", n.name, n.state)
+		}
+		n.transition(FakeStateStopping, "stopped", "power off")
+		return nil
+	})
+}
+
+func (n *FakeNode) Reboot() error {
+	return n.act("Reboot", func() error {
+		if !IsRunning(n.state) {
+			return fmt.Errorf("unable to reboot instance %s from status %s", n.name, n.state)
+		}
+		n.transition(FakeStateRebooting, "running", "reboot")
+		return nil
+	})
+}
+
+func (n *FakeNode) State() (string, error) {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	if err := n.errors["State"]; err != nil {
+		return "", err
+	}
+	state := n.state
+	if n.remaining > 0 {
+		n.remaining--
+		if n.remaining == 0 {
+			n.state = n.target
+		}
+	}
+	return state, nil
+}
+
+// GetConsoleLog returns a line per power action done on the node
+func (n *FakeNode) GetConsoleLog() (string, error) {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	if err := n.errors["GetConsoleLog"]; err != nil {
+		return "", err
+	}
+	return strings.Join(n.console, "\n"), nil
+}
+
+func (n *FakeNode) Metadata() (Metadata, error) {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	if err := n.errors["Metadata"]; err != nil {
+		return Metadata{}, err
+	}
+	return n.metadata, nil
+}
+
+// act records the power action and runs it unless an error is set for it
+func (n *FakeNode) act(method string, action func() error) error {
+	n.provider.lock.Lock()
+	defer n.provider.lock.Unlock()
+	n.provider.calls = append(n.provider.calls, method+" "+n.name)
+	if err := n.errors[method]; err != nil {
+		return err
+	}
+	return action()
+}
+
+// transition moves the node to the state through the transitional state, the lock must be held
+func (n *FakeNode) transition(through, to, console string) {
+	n.console = append(n.console, fmt.Sprintf("%s: %s", n.name, console))
+	if n.steps == 0 {
+		n.state = to
+		n.remaining = 0
+		return
+	}
+	n.state = through
+	n.target = to
+	n.remaining = n.steps
+}
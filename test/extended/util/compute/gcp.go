@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"fmt"
@@ -17,13 +17,13 @@ type gcpInstance struct {
 }
 
 // GetGcpNodes get nodes and load clouds cred with the specified label.
-func GetGcpNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetGcpNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	projectID, err := exutil.GetGcpProjectID(oc)
 	o.Expect(err).ToNot(o.HaveOccurred())
 	client := client(projectID)
-	var results []ComputeNode
+	var results Nodes
 	for _, node := range nodeNames {
 		results = append(results, newGcpInstance(oc, client, projectID, strings.Split(node, ".")[0]))
 	}
@@ -62,7 +62,7 @@ func client(projectID string) *exutil.Gcloud {
 func (g *gcpInstance) Start() error {
 	instanceState, err := g.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := stopStates[instanceState]; ok {
+	if _, ok := StoppedStates[instanceState]; ok {
 		nodeInstance := strings.Split(g.nodeName, ".")
 		zoneName, err := g.client.GetZone(g.nodeName, nodeInstance[0])
 		o.Expect(err).NotTo(o.HaveOccurred())
@@ -79,7 +79,7 @@ func (g *gcpInstance) Start() error {
 func (g *gcpInstance) Stop() error {
 	instanceState, err := g.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := startStates[instanceState]; ok {
+	if _, ok := RunningStates[instanceState]; ok {
 		nodeInstance := strings.Split(g.nodeName, ".")
 		zoneName, err := g.client.GetZone(g.nodeName, nodeInstance[0])
 		o.Expect(err).NotTo(o.HaveOccurred())
@@ -101,3 +101,26 @@ func (g *gcpInstance) State() (string, error) {
 	}
 	return "", err
 }
+
+// ForceStop isn't supported, GCP stops the instance after the guest shut down or a timeout
+func (g *gcpInstance) ForceStop() error {
+	return ErrNotSupported
+}
+
+func (g *gcpInstance) Reboot() error {
+	nodeInstance := strings.Split(g.nodeName, ".")
+	zoneName, err := g.client.GetZone(g.nodeName, nodeInstance[0])
+	if err != nil {
+		return err
+	}
+	return g.client.ResetInstance(nodeInstance[0], strings.TrimSpace(zoneName))
+}
+
+func (g *gcpInstance) GetConsoleLog() (string, error) {
+	nodeInstance := strings.Split(g.nodeName, ".")
+	zoneName, err := g.client.GetZone(g.nodeName, nodeInstance[0])
+	if err != nil {
+		return "", err
+	}
+	return g.client.GetSerialPortOutput(nodeInstance[0], strings.TrimSpace(zoneName))
+}
@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	o "github.com/onsi/gomega"
@@ -15,7 +15,7 @@ type ibmInstance struct {
 }
 
 // Get nodes and load clouds cred with the specified label.
-func GetIbmNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetIbmNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	ibmApiKey, ibmRegion, ibmVpcName, credErr := exutil.GetIBMCredentialFromCluster(oc)
@@ -24,7 +24,7 @@ func GetIbmNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
 	o.Expect(sessErr).NotTo(o.HaveOccurred())
 	baseDomain, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("dns", "cluster", "-o=jsonpath={.spec.baseDomain}").Output()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newIbmInstance(oc, ibmSession, ibmRegion, ibmVpcName, nodeName, baseDomain))
 	}
@@ -71,3 +71,14 @@ func (ibm *ibmInstance) State() (string, error) {
 	o.Expect(idErr).NotTo(o.HaveOccurred())
 	return exutil.GetIBMInstanceStatus(ibm.client, instanceID)
 }
+
+// ForceStop stops the instance without waiting for the guest to shut down
+func (ibm *ibmInstance) ForceStop() error {
+	instanceID, idErr := exutil.GetIBMInstanceID(ibm.client, ibm.oc, ibm.ibmRegion, ibm.ibmVpcName, ibm.nodeName, ibm.baseDomain)
+	o.Expect(idErr).NotTo(o.HaveOccurred())
+	return exutil.ForceStopIBMInstance(ibm.client, instanceID)
+}
+
+func (ibm *ibmInstance) Reboot() error {
+	return powerCycle(ibm, ibm.Stop)
+}
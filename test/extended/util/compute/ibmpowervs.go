@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	o "github.com/onsi/gomega"
@@ -14,7 +14,7 @@ type ibmPowerVsInstance struct {
 }
 
 // Get nodes and load clouds cred with the specified label.
-func GetIBMPowerNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetIBMPowerNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	ibmApiKey, ibmRegion, ibmVpcName, credErr := exutil.GetIBMCredentialFromCluster(oc)
@@ -22,7 +22,7 @@ func GetIBMPowerNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
 	cloudID := exutil.GetIBMPowerVsCloudID(oc, nodeNames[0])
 	ibmSession, sessErr := exutil.LoginIBMPowerVsCloud(ibmApiKey, ibmRegion, ibmVpcName, cloudID)
 	o.Expect(sessErr).NotTo(o.HaveOccurred())
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newIBMPowerInstance(oc, ibmSession, ibmRegion, ibmVpcName, nodeName))
 	}
@@ -67,3 +67,14 @@ func (ibmPws *ibmPowerVsInstance) State() (string, error) {
 	o.Expect(idErr).NotTo(o.HaveOccurred())
 	return status, idErr
 }
+
+// ForceStop shuts down the instance immediately
+func (ibmPws *ibmPowerVsInstance) ForceStop() error {
+	instanceID, _, idErr := exutil.GetIBMPowerVsInstanceInfo(ibmPws.clientPowerVs, ibmPws.nodeName)
+	o.Expect(idErr).NotTo(o.HaveOccurred())
+	return exutil.PerformInstanceActionOnPowerVs(ibmPws.clientPowerVs, instanceID, "immediate-shutdown")
+}
+
+func (ibmPws *ibmPowerVsInstance) Reboot() error {
+	return powerCycle(ibmPws, ibmPws.Stop)
+}
@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"fmt"
@@ -15,14 +15,14 @@ type nutanixInstance struct {
 }
 
 // Get nodes and load clouds cred with the specified label.
-func GetNutanixNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetNutanixNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	nutanixUsername, nutanixPassword, nutanixEndpointURL, credErr := exutil.GetNutanixCredentialFromCluster(oc)
 	o.Expect(credErr).NotTo(o.HaveOccurred())
 	nutanixSession, sessErr := exutil.NewNutanixSession(nutanixUsername, nutanixPassword, nutanixEndpointURL)
 	o.Expect(sessErr).NotTo(o.HaveOccurred())
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newNutanixInstance(oc, nutanixSession, nodeName))
 	}
@@ -53,7 +53,7 @@ func (nux *nutanixInstance) Start() error {
 	o.Expect(idErr).NotTo(o.HaveOccurred())
 	instanceState, err := nux.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := stopStates[strings.ToLower(instanceState)]; ok {
+	if _, ok := StoppedStates[strings.ToLower(instanceState)]; ok {
 		err = nux.client.SetNutanixInstanceState("ON", instanceID)
 		if err != nil {
 			return fmt.Errorf("start instance failed with error :: %v", err)
@@ -69,7 +69,7 @@ func (nux *nutanixInstance) Stop() error {
 	o.Expect(idErr).NotTo(o.HaveOccurred())
 	instanceState, err := nux.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := startStates[strings.ToLower(instanceState)]; ok {
+	if _, ok := RunningStates[strings.ToLower(instanceState)]; ok {
 		err = nux.client.SetNutanixInstanceState("OFF", instanceID)
 		if err != nil {
 			return fmt.Errorf("stop instance failed with error :: %v", err)
@@ -83,3 +83,14 @@ func (nux *nutanixInstance) Stop() error {
 func (nux *nutanixInstance) State() (string, error) {
 	return nux.client.GetNutanixInstanceState(nux.nodeName)
 }
+
+// ForceStop sets the power state of the VM to OFF, which is a hard power off unlike the ACPI shutdown of the guest
+func (nux *nutanixInstance) ForceStop() error {
+	instanceID, idErr := nux.client.GetNutanixInstanceID(nux.nodeName)
+	o.Expect(idErr).NotTo(o.HaveOccurred())
+	return nux.client.SetNutanixInstanceState("OFF", instanceID)
+}
+
+func (nux *nutanixInstance) Reboot() error {
+	return powerCycle(nux, nux.Stop)
+}
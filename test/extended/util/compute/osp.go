@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"encoding/base64"
@@ -21,14 +21,14 @@ type ospInstance struct {
 }
 
 // Get nodes and load clouds cred with the specified label.
-func GetOspNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetOspNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	cred, err1 := exutil.GetOpenStackCredentials(oc)
 	o.Expect(err1).NotTo(o.HaveOccurred())
 	client := exutil.NewOpenStackClient(cred, "compute")
 
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newOspInstance(oc, client, nodeName))
 	}
@@ -109,7 +109,7 @@ func (osp *ospInstance) GetInstanceID() (string, error) {
 func (osp *ospInstance) Start() error {
 	instanceState, err := osp.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := stopStates[instanceState]; ok {
+	if _, ok := StoppedStates[instanceState]; ok {
 		err = osp.ospObj.GetStartOspInstance(osp.client, osp.nodeName)
 		if err != nil {
 			return fmt.Errorf("start instance failed with error :: %v", err)
@@ -123,7 +123,7 @@ func (osp *ospInstance) Start() error {
 func (osp *ospInstance) Stop() error {
 	instanceState, err := osp.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := startStates[instanceState]; ok {
+	if _, ok := RunningStates[instanceState]; ok {
 		err = osp.ospObj.GetStopOspInstance(osp.client, osp.nodeName)
 		if err != nil {
 			return fmt.Errorf("stop instance failed with error :: %v", err)
@@ -142,3 +142,12 @@ func (osp *ospInstance) State() (string, error) {
 	}
 	return "", err
 }
+
+// ForceStop isn't supported, Nova stops the server after the guest shut down or a timeout
+func (osp *ospInstance) ForceStop() error {
+	return ErrNotSupported
+}
+
+func (osp *ospInstance) Reboot() error {
+	return powerCycle(osp, osp.Stop)
+}
@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"fmt"
@@ -27,7 +27,7 @@ const RDU2BaseDomain = "arm.eng.rdu2.redhat.com"
 
 // GetUPIBaremetalNodes get nodes by label and returns a list of ComputeNode objects with the required information to
 // control the nodes.
-func GetUPIBaremetalNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetUPIBaremetalNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	if !strings.Contains(nodeNames[0], RDU2BaseDomain) {
@@ -59,7 +59,7 @@ func GetUPIBaremetalNodes(oc *exutil.CLI, label string) ([]ComputeNode, func())
 	}
 
 	// Create the UPIInstance objects and the results slice
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		o.Expect(err).NotTo(o.HaveOccurred())
 		results = append(results, newUPIbaremetalInstance(oc, nodeName,
@@ -89,7 +89,7 @@ func (upi *UPIInstance) GetInstanceID() (string, error) {
 func (upi *UPIInstance) Start() error {
 	instanceState, err := upi.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := stopStates[instanceState]; ok {
+	if _, ok := StoppedStates[instanceState]; ok {
 		err = upi.upiObj.StartUPIbaremetalInstance()
 		if err != nil {
 			return fmt.Errorf("start instance failed with error :: %v", err)
@@ -104,7 +104,7 @@ func (upi *UPIInstance) Start() error {
 func (upi *UPIInstance) Stop() error {
 	instanceState, err := upi.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := startStates[instanceState]; ok {
+	if _, ok := RunningStates[instanceState]; ok {
 		err = upi.upiObj.StopUPIbaremetalInstance()
 		if err != nil {
 			return fmt.Errorf("stop instance failed with error :: %v", err)
@@ -132,3 +132,12 @@ func (upi *UPIInstance) State() (string, error) {
 		fmt.Sprintf("Failed to get power status for master node: %s, error: %s", upi.nodeName, statusErr))
 	return strings.ToLower(instanceState), statusErr
 }
+
+// ForceStop powers off the chassis through IPMI, which doesn't shut down the guest
+func (upi *UPIInstance) ForceStop() error {
+	return upi.upiObj.StopUPIbaremetalInstance()
+}
+
+func (upi *UPIInstance) Reboot() error {
+	return powerCycle(upi, upi.Stop)
+}
@@ -1,4 +1,4 @@
-package disasterrecovery
+package compute
 
 import (
 	"encoding/base64"
@@ -29,11 +29,11 @@ type vsphereInstance struct {
 }
 
 // Get nodes and load clouds cred with the specified label.
-func GetVsphereNodes(oc *exutil.CLI, label string) ([]ComputeNode, func()) {
+func GetVsphereNodes(oc *exutil.CLI, label string) (Nodes, func()) {
 	nodeNames, err := exutil.GetClusterNodesBy(oc, label)
 	o.Expect(err).NotTo(o.HaveOccurred())
 	vspObj, vspClient, vmRelativePath := VsphereCloudClient(oc)
-	var results []ComputeNode
+	var results Nodes
 	for _, nodeName := range nodeNames {
 		results = append(results, newVsphereInstance(oc, vspObj, vspClient, nodeName, vmRelativePath))
 	}
@@ -105,7 +105,7 @@ func (vs *vsphereInstance) GetInstanceID() (string, error) {
 func (vs *vsphereInstance) Start() error {
 	instanceState, err := vs.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := stopStates[instanceState]; ok {
+	if _, ok := StoppedStates[instanceState]; ok {
 		err = vs.vspObj.StartVsphereInstance(vs.vspClient, vs.vmRelativePath+vs.nodeName)
 		if err != nil {
 			return fmt.Errorf("start instance failed with error :: %v", err)
@@ -119,7 +119,7 @@ func (vs *vsphereInstance) Start() error {
 func (vs *vsphereInstance) Stop() error {
 	instanceState, err := vs.State()
 	o.Expect(err).NotTo(o.HaveOccurred())
-	if _, ok := startStates[instanceState]; ok {
+	if _, ok := RunningStates[instanceState]; ok {
 		err = vs.vspObj.StopVsphereInstance(vs.vspClient, vs.vmRelativePath+vs.nodeName)
 		if err != nil {
 			return fmt.Errorf("stop instance failed with error :: %v", err)
@@ -182,3 +182,12 @@ func getvSphereServerConfig(oc *exutil.CLI, vSphereConfigFile string) (string, s
 
 	return "", "", fmt.Errorf("no valid configuration found")
 }
+
+// ForceStop powers off the VM, which doesn't shut down the guest unlike ShutdownGuest
+func (vs *vsphereInstance) ForceStop() error {
+	return vs.vspObj.StopVsphereInstance(vs.vspClient, vs.vmRelativePath+vs.nodeName)
+}
+
+func (vs *vsphereInstance) Reboot() error {
+	return powerCycle(vs, vs.Stop)
+}
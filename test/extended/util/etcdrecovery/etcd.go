@@ -0,0 +1,87 @@
+package etcdrecovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Member is a member of the etcd cluster, as listed by etcdctl member list
+type Member struct {
+	ID         uint64   `json:"ID"`
+	Name       string   `json:"name"`
+	PeerURLs   []string `json:"peerURLs"`
+	ClientURLs []string `json:"clientURLs"`
+	IsLearner  bool     `json:"isLearner"`
+}
+
+// Started returns true if the member joined the cluster, the members added but never started have no name
+func (m Member) Started() bool {
+	return m.Name != "" && len(m.ClientURLs) > 0
+}
+
+// ParseMemberList returns the members of the json output of etcdctl member list
+func ParseMemberList(output string) ([]Member, error) {
+	var list struct {
+		Members []Member `json:"members"`
+	}
+	if err := json.Unmarshal([]byte(jsonOf(output)), &list); err != nil {
+		return nil, fmt.Errorf("invalid member list %q: %v", output, err)
+	}
+	return list.Members, nil
+}
+
+// EndpointStatus is the status of a member, as reported by etcdctl endpoint status
+type EndpointStatus struct {
+	Endpoint string
+	Revision int64
+	Leader   uint64
+	Version  string
+	DBSize   int64
+}
+
+// ParseEndpointStatus returns the statuses of the json output of etcdctl endpoint status
+func ParseEndpointStatus(output string) ([]EndpointStatus, error) {
+	var endpoints []struct {
+		Endpoint string `json:"Endpoint"`
+		Status   struct {
+			Header struct {
+				Revision int64 `json:"revision"`
+			} `json:"header"`
+			Version string `json:"version"`
+			DBSize  int64  `json:"dbSize"`
+			Leader  uint64 `json:"leader"`
+		} `json:"Status"`
+	}
+	if err := json.Unmarshal([]byte(jsonOf(output)), &endpoints); err != nil {
+		return nil, fmt.Errorf("invalid endpoint status %q: %v", output, err)
+	}
+	statuses := make([]EndpointStatus, 0, len(endpoints))
+	for _, e := range endpoints {
+		statuses = append(statuses, EndpointStatus{Endpoint: e.Endpoint, Revision: e.Status.Header.Revision, Leader: e.Status.Leader, Version: e.Status.Version, DBSize: e.Status.DBSize})
+	}
+	return statuses, nil
+}
+
+// maxRevision returns the highest revision of the endpoints, the followers can lag behind the leader
+func maxRevision(statuses []EndpointStatus) int64 {
+	var revision int64
+	for _, s := range statuses {
+		if s.Revision > revision {
+			revision = s.Revision
+		}
+	}
+	return revision
+}
+
+// jsonOf returns the last json line of the output, etcdctl logs its warnings as json lines before the result
+func jsonOf(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "{") {
+			return line
+		}
+	}
+	return output
+}
@@ -0,0 +1,268 @@
+package etcdrecovery
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pkgmonitor "github.com/openshift/openshift-tests-private/pkg/monitor"
+	"github.com/openshift/openshift-tests-private/test/extended/util/compute"
+)
+
+const backupOutput = `Certificate /etc/kubernetes/static-pod-certs/configmaps/etcd-all-bundles/server-ca-bundle.crt is missing. Checking in different directory
+etcdctl is already installed
+{"level":"info","ts":"2024-09-12T08:41:25.186Z","caller":"snapshot/v3_snapshot.go:65","msg":"created temporary db file","path":"/home/core/assets/backup/snapshot_2024-09-12_084124.db.part"}
+Snapshot saved at /home/core/assets/backup/snapshot_2024-09-12_084124.db
+Deprecated: Use ` + "`etcdutl snapshot status`" + ` instead.
+
+{"hash":3931349276,"revision":120437,"totalKey":9817,"totalSize":118411264}
+snapshot db and kube resources are successfully saved to /home/core/assets/backup
+`
+
+// fakeExecutor returns the output of the command prefix matching the command, after failing as many times as the
+// failures of the prefix, the nodes stopped in the provider are unreachable
+type fakeExecutor struct {
+	outputs  map[string]string
+	failures map[string]int
+	commands []string
+	provider *compute.FakeProvider
+}
+
+func (f *fakeExecutor) Exec(node string, command string) (string, error) {
+	if f.provider != nil {
+		if state, _ := f.provider.Nodes().ByName(node).State(); compute.IsStopped(state) {
+			return "", fmt.Errorf("%s is unreachable", node)
+		}
+	}
+	f.commands = append(f.commands, node+": "+command)
+	for prefix, n := range f.failures {
+		if strings.HasPrefix(command, prefix) && n > 0 {
+			f.failures[prefix]--
+			return "", fmt.Errorf("%s failed", prefix)
+		}
+	}
+	for prefix, out := range f.outputs {
+		if strings.HasPrefix(command, prefix) {
+			return out, nil
+		}
+	}
+	return "", nil
+}
+
+// fakeEtcd answers etcdctl with the members and the revision
+type fakeEtcd struct {
+	members  []string
+	revision int64
+}
+
+func (f *fakeEtcd) etcdctl(args ...string) (string, error) {
+	switch strings.Join(args[:2], " ") {
+	case "member list":
+		var members []string
+		for i, name := range f.members {
+			members = append(members, fmt.Sprintf(`{"ID":%d,"name":%q,"peerURLs":["https://10.0.0.%d:2380"],"clientURLs":["https://10.0.0.%d:2379"]}`, 1000+i, name, i, i))
+		}
+		return `{"header":{"cluster_id":17237436991929493444,"member_id":9372538179322589801,"raft_term":2},"members":[` + strings.Join(members, ",") + `]}`, nil
+	case "endpoint status":
+		return fmt.Sprintf(`[{"Endpoint":"https://10.0.0.0:2379","Status":{"header":{"revision":%d},"version":"3.5.14","dbSize":118411264,"leader":1000}},`+
+			`{"Endpoint":"https://10.0.0.1:2379","Status":{"header":{"revision":%d},"version":"3.5.14","dbSize":118411264,"leader":1000}}]`, f.revision, f.revision-3), nil
+	}
+	return "", fmt.Errorf("unexpected etcdctl %v", args)
+}
+
+func newTestRecovery(t *testing.T, executor NodeExecutor, etcd *fakeEtcd) (*Recovery, *compute.FakeProvider, *bytes.Buffer) {
+	provider := compute.NewFakeProvider()
+	for _, name := range []string{"master-0", "master-1", "master-2"} {
+		provider.AddNode(name, compute.Metadata{Platform: "fake"})
+	}
+	r, err := New(nil, executor, provider.Nodes().Names(), "master-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	r.etcdctl, r.out, r.pollInterval = etcd.etcdctl, out, time.Millisecond
+	return r, provider, out
+}
+
+func TestParseBackupOutput(t *testing.T) {
+	s, err := ParseBackupOutput("master-0", DefaultBackupDir, backupOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Path != "/home/core/assets/backup/snapshot_2024-09-12_084124.db" || s.Hash != 3931349276 || s.Revision != 120437 || s.TotalKey != 9817 || s.TotalSize != 118411264 {
+		t.Fatalf("unexpected snapshot %s", s)
+	}
+	if _, err := ParseBackupOutput("master-0", DefaultBackupDir, "Error: context deadline exceeded"); err == nil {
+		t.Fatalf("expected an error without snapshot")
+	}
+
+	for _, tc := range []struct {
+		name        string
+		size        int64
+		minRevision int64
+		valid       bool
+	}{
+		{"consistent", 118411264, 120000, true},
+		{"with the appended hash", 118411264 + 32, 120000, true},
+		{"within the tolerance", 118411264 - 1024*1024, 120000, true},
+		{"above the tolerance", 118411264 + 2*1024*1024, 120000, false},
+		{"truncated", 126 * 1024, 120000, false},
+		{"older than the cluster", 118411264, 130000, false},
+	} {
+		if err := s.Verify(tc.size, tc.minRevision); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid %v, got %v", tc.name, tc.valid, err)
+		}
+	}
+}
+
+func TestParseEtcdctl(t *testing.T) {
+	etcd := &fakeEtcd{members: []string{"master-0", ""}, revision: 42}
+	out, _ := etcd.etcdctl("member", "list")
+	members, err := ParseMemberList(`{"level":"warn","ts":"2024-09-12T08:41:25.186Z","msg":"ignored"}` + "\n" + out + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || !members[0].Started() || members[1].Started() || members[0].ID != 1000 {
+		t.Fatalf("unexpected members %+v", members)
+	}
+	if err := checkMembers(members, 2); err == nil {
+		t.Fatalf("expected an error with a member not started")
+	}
+
+	out, _ = etcd.etcdctl("endpoint", "status")
+	statuses, err := ParseEndpointStatus(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || maxRevision(statuses) != 42 || statuses[1].Revision != 39 || statuses[0].Version != "3.5.14" {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+}
+
+func TestQuorumLossAndRecovery(t *testing.T) {
+	executor := &fakeExecutor{outputs: map[string]string{
+		"/usr/local/bin/cluster-backup.sh": backupOutput,
+		"stat -c %s":                       "118411264\n",
+		"/usr/local/bin/quorum-restore.sh": "starting restore-etcd static pod\n",
+	}}
+	etcd := &fakeEtcd{members: []string{"master-0", "master-1", "master-2"}, revision: 120400}
+	r, provider, out := newTestRecovery(t, executor, etcd)
+	executor.provider = provider
+	r.ExpectRevisionBump = true
+
+	snapshot, err := r.Backup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.VerifySnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	etcd.revision = 120500
+	if err := r.LoseMembers(provider.Nodes()); err == nil {
+		t.Fatalf("expected an error losing the recovery node")
+	}
+	if err := r.LoseMembers(provider.Nodes()[1:]); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"master-1", "master-2"} {
+		if state, _ := provider.Nodes().ByName(name).State(); !compute.IsStopped(state) {
+			t.Fatalf("%s is %s after the loss", name, state)
+		}
+	}
+	if err := r.QuorumRestore(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RecoverMembers(); err != nil {
+		t.Fatal(err)
+	}
+
+	etcd.revision = 120450
+	if err := r.VerifyRecovered(snapshot); err == nil || !strings.Contains(err.Error(), "wasn't bumped") {
+		t.Fatalf("expected an error as the revision went back, got %v", err)
+	}
+	etcd.revision = 1000120500
+	if err := r.VerifyRecovered(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCalls := "ForceStop master-1,ForceStop master-2,Start master-1,Start master-2"
+	if calls := strings.Join(provider.Calls(), ","); calls != expectedCalls {
+		t.Fatalf("expected calls %s, got %s", expectedCalls, calls)
+	}
+	// the etcd data is wiped while the nodes are reachable, before they are powered off
+	wiped := 0
+	for _, c := range executor.commands {
+		if c == "master-1: /usr/local/bin/disable-etcd.sh && rm -rf /var/lib/etcd" || c == "master-2: /usr/local/bin/disable-etcd.sh && rm -rf /var/lib/etcd" {
+			wiped++
+		}
+	}
+	if wiped != 2 {
+		t.Fatalf("expected the etcd data of the 2 lost nodes wiped, got %v", executor.commands)
+	}
+	if last := executor.commands[len(executor.commands)-1]; last != "master-0: /usr/local/bin/quorum-restore.sh" {
+		t.Fatalf("unexpected last command %s", last)
+	}
+
+	var names []string
+	for _, s := range r.Steps() {
+		names = append(names, s.Name)
+	}
+	expectedSteps := "backup,verify-snapshot,lose-members,lose-members,quorum-restore,recover-members,verify-recovered,verify-recovered"
+	if strings.Join(names, ",") != expectedSteps {
+		t.Fatalf("expected steps %s, got %v", expectedSteps, names)
+	}
+	intervals := pkgmonitor.ParseIntervals(out.Bytes())
+	if len(intervals) != len(names) {
+		t.Fatalf("expected %d intervals, got %d", len(names), len(intervals))
+	}
+	if intervals[2].Level != pkgmonitor.Error || intervals[3].Locator != "etcd-recovery/lose-members node/master-0" {
+		t.Fatalf("unexpected intervals %v %v", intervals[2], intervals[3])
+	}
+}
+
+func TestPointInTimeRestoreFailures(t *testing.T) {
+	executor := &fakeExecutor{
+		outputs: map[string]string{
+			"/usr/local/bin/cluster-backup.sh":  backupOutput,
+			"stat -c %s":                        "129024\n",
+			"/usr/local/bin/cluster-restore.sh": "Backup appears corrupted. Aborting!\n",
+		},
+	}
+	etcd := &fakeEtcd{members: []string{"master-0", "master-1", "master-2"}, revision: 120400}
+	r, _, _ := newTestRecovery(t, executor, etcd)
+
+	snapshot, err := r.Backup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.VerifySnapshot(snapshot); err == nil {
+		t.Fatalf("expected an error verifying a truncated snapshot")
+	}
+	if err := r.DisableMembers(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Restore(snapshot); err == nil {
+		t.Fatalf("expected an error as the restore pod wasn't started")
+	}
+	disabled := 0
+	for _, c := range executor.commands {
+		if strings.HasSuffix(c, "disable-etcd.sh") {
+			disabled++
+		}
+	}
+	if disabled != 3 {
+		t.Fatalf("expected etcd disabled on the 3 nodes, got %v", executor.commands)
+	}
+
+	// the backup is retried while it fails
+	executor.failures = map[string]int{"/usr/local/bin/cluster-backup.sh": 2}
+	if _, err := r.Backup(); err != nil {
+		t.Fatal(err)
+	}
+	if executor.failures["/usr/local/bin/cluster-backup.sh"] != 0 {
+		t.Fatalf("expected the backup to be retried")
+	}
+}
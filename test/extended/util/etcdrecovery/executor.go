@@ -0,0 +1,135 @@
+package etcdrecovery
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// NodeExecutor runs a shell command as root on the host of a control plane node
+type NodeExecutor interface {
+	Exec(node string, command string) (string, error)
+}
+
+// controlPlaneHosts returns the internal IP of every control plane node by node name
+func controlPlaneHosts(oc *exutil.CLI) (map[string]string, error) {
+	out, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("node", "-l", "node-role.kubernetes.io/master=",
+		`-o=jsonpath={range .items[*]}{.metadata.name}{" "}{.status.addresses[?(@.type=="InternalIP")].address}{"\n"}{end}`).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the control plane nodes: %v", err)
+	}
+	hosts := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			hosts[fields[0]] = fields[1]
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no control plane node found")
+	}
+	return hosts, nil
+}
+
+// resolveNode returns the full name of the node, the platforms like GCP name the instances after the first label of
+// the node name only
+func resolveNode(hosts map[string]string, node string) (string, bool) {
+	if _, ok := hosts[node]; ok {
+		return node, true
+	}
+	for name := range hosts {
+		if strings.HasPrefix(name, node+".") {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// DebugExecutor runs the commands in a debug pod of the node, the API must be available
+type DebugExecutor struct {
+	oc    *exutil.CLI
+	hosts map[string]string
+}
+
+// NewDebugExecutor returns an executor using oc debug
+func NewDebugExecutor(oc *exutil.CLI) (*DebugExecutor, error) {
+	hosts, err := controlPlaneHosts(oc)
+	if err != nil {
+		return nil, err
+	}
+	return &DebugExecutor{oc: oc, hosts: hosts}, nil
+}
+
+func (d *DebugExecutor) Exec(node string, command string) (string, error) {
+	name, ok := resolveNode(d.hosts, node)
+	if !ok {
+		return "", fmt.Errorf("%s is not a control plane node", node)
+	}
+	return exutil.DebugNodeWithOptionsAndChroot(d.oc, name, []string{"-q"}, "bash", "-c", command)
+}
+
+// SSHExecutor runs the commands over ssh as the core user, through the bastion host when there is one, so that it
+// works while the API is down
+type SSHExecutor struct {
+	privateKey  string
+	bastionHost string
+	bastionUser string
+	hosts       map[string]string
+}
+
+// NewSSHExecutor returns an executor using the ssh key of SSH_CLOUD_PRIV_KEY and, on the platforms with a bastion,
+// the bastion of QE_BASTION_PUBLIC_ADDRESS, the addresses of the nodes are resolved while the API is available
+func NewSSHExecutor(oc *exutil.CLI, platform string) (*SSHExecutor, error) {
+	s := &SSHExecutor{privateKey: os.Getenv("SSH_CLOUD_PRIV_KEY")}
+	if s.privateKey == "" {
+		return nil, fmt.Errorf("SSH_CLOUD_PRIV_KEY is not set")
+	}
+	switch platform {
+	case "vsphere", "nutanix":
+	default:
+		s.bastionHost = os.Getenv("QE_BASTION_PUBLIC_ADDRESS")
+		if s.bastionHost == "" {
+			return nil, fmt.Errorf("QE_BASTION_PUBLIC_ADDRESS is not set")
+		}
+		s.bastionUser = os.Getenv("SSH_CLOUD_PRIV_" + strings.ToUpper(platform) + "_USER")
+		if s.bastionUser == "" {
+			return nil, fmt.Errorf("SSH_CLOUD_PRIV_%s_USER is not set", strings.ToUpper(platform))
+		}
+	}
+	if err := os.Chmod(s.privateKey, 0600); err != nil {
+		return nil, fmt.Errorf("failed to restrict the permissions of %s: %v", s.privateKey, err)
+	}
+	hosts, err := controlPlaneHosts(oc)
+	if err != nil {
+		return nil, err
+	}
+	s.hosts = hosts
+	return s, nil
+}
+
+func (s *SSHExecutor) Exec(node string, command string) (string, error) {
+	name, ok := resolveNode(s.hosts, node)
+	if !ok {
+		return "", fmt.Errorf("%s is not a control plane node", node)
+	}
+	args := []string{"-i", s.privateKey, "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null"}
+	if s.bastionHost != "" {
+		args = append(args, "-o", fmt.Sprintf("ProxyCommand=ssh -i %s -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -W %%h:%%p %s@%s", s.privateKey, s.bastionUser, s.bastionHost))
+	}
+	args = append(args, "core@"+s.hosts[name], "sudo -E bash -c "+shellQuote(command))
+	out, err := exec.Command("ssh", args...).CombinedOutput()
+	if err != nil {
+		e2e.Logf("Command %q on %s failed :: %s", command, name, string(out))
+		return string(out), fmt.Errorf("failed to run %q on %s: %v", command, name, err)
+	}
+	return string(out), nil
+}
+
+// shellQuote quotes s for the remote shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
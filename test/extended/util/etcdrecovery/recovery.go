@@ -0,0 +1,562 @@
+package etcdrecovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+
+	pkgmonitor "github.com/openshift/openshift-tests-private/pkg/monitor"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/compute"
+)
+
+const (
+	// DefaultBackupDir is the backup directory of the documented backup procedure
+	DefaultBackupDir = "/home/core/assets/backup"
+
+	etcdNamespace = "openshift-etcd"
+	// restoreStarted is printed by cluster-restore.sh and quorum-restore.sh once the restore pod is started
+	restoreStarted = "starting restore-etcd static pod"
+
+	backupTimeout = 5 * time.Minute
+	powerTimeout  = 10 * time.Minute
+)
+
+// Phase is when marker objects are created, relative to the backup
+type Phase string
+
+const (
+	// PhasePreBackup markers are in the snapshot, they must be present after the restore
+	PhasePreBackup Phase = "pre-backup"
+	// PhasePostBackup markers are not in the snapshot, they must be absent after the restore
+	PhasePostBackup Phase = "post-backup"
+)
+
+// Step is a step of the recovery, each step is written to the monitor timeline of the test as an interval
+type Step struct {
+	Name    string
+	From    time.Time
+	To      time.Time
+	Message string
+	Err     error
+}
+
+func (s Step) String() string {
+	if s.Err != nil {
+		return fmt.Sprintf("%s took %s and failed: %v", s.Name, s.To.Sub(s.From).Round(time.Second), s.Err)
+	}
+	return fmt.Sprintf("%s took %s: %s", s.Name, s.To.Sub(s.From).Round(time.Second), s.Message)
+}
+
+// Recovery orchestrates the backup of etcd on a control plane node, the loss of the other members and the restore
+// from the backup or from the remaining member, then verifies the state of the recovered cluster
+type Recovery struct {
+	oc           *exutil.CLI
+	executor     NodeExecutor
+	nodes        []string
+	recoveryNode string
+
+	// BackupDir is the backup directory on the recovery node, DefaultBackupDir by default
+	BackupDir string
+	// ExpectRevisionBump requires the revision after the restore to be above the revision before the loss, the
+	// restore bumps the revision so that the watch caches of the clients never go back in time
+	ExpectRevisionBump bool
+
+	// etcdctl runs etcdctl in the etcd pod of a running member
+	etcdctl      func(args ...string) (string, error)
+	out          io.Writer
+	pollInterval time.Duration
+
+	lock            sync.Mutex
+	steps           []Step
+	markerNamespace string
+	markers         map[Phase][]string
+	// backupRevision is the revision of the cluster before the backup, lossRevision the one before the loss of
+	// the members
+	backupRevision int64
+	lossRevision   int64
+	lost           compute.Nodes
+}
+
+// New returns the recovery of the control plane nodes from the recovery node, the commands run on the hosts with
+// executor, an SSHExecutor for the steps done while the API is down
+func New(oc *exutil.CLI, executor NodeExecutor, nodes []string, recoveryNode string) (*Recovery, error) {
+	found := false
+	for _, node := range nodes {
+		found = found || sameNode(node, recoveryNode)
+	}
+	if !found {
+		return nil, fmt.Errorf("the recovery node %s is not one of %v", recoveryNode, nodes)
+	}
+	r := &Recovery{
+		oc:           oc,
+		executor:     executor,
+		nodes:        nodes,
+		recoveryNode: recoveryNode,
+		BackupDir:    DefaultBackupDir,
+		out:          os.Stdout,
+		pollInterval: 20 * time.Second,
+		markers:      map[Phase][]string{},
+	}
+	r.etcdctl = r.runEtcdctl
+	return r, nil
+}
+
+// sameNode returns true if the names are the names of the same node, the power interface names the GCP nodes after
+// the first label of the node name only
+func sameNode(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+// isLost returns true if the node was powered off by LoseMembers
+func (r *Recovery) isLost(node string) bool {
+	for _, n := range r.lost {
+		if sameNode(n.GetName(), node) {
+			return true
+		}
+	}
+	return false
+}
+
+// Steps returns the steps done so far
+func (r *Recovery) Steps() []Step {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Step{}, r.steps...)
+}
+
+// step runs the step and records it, the message of fn describes the result of the step
+func (r *Recovery) step(name string, fn func() (string, error)) error {
+	s := Step{Name: name, From: time.Now().UTC()}
+	e2e.Logf("etcd recovery: %s started", name)
+	s.Message, s.Err = fn()
+	s.To = time.Now().UTC()
+	e2e.Logf("etcd recovery: %s", s)
+
+	r.lock.Lock()
+	r.steps = append(r.steps, s)
+	r.lock.Unlock()
+
+	condition := &pkgmonitor.Condition{Level: pkgmonitor.Info, Locator: fmt.Sprintf("etcd-recovery/%s node/%s", name, r.recoveryNode), Message: s.Message}
+	if s.Err != nil {
+		condition.Level, condition.Message = pkgmonitor.Error, s.Err.Error()
+	}
+	if err := pkgmonitor.WriteIntervals(r.out, &pkgmonitor.EventInterval{Condition: condition, From: s.From, To: s.To}); err != nil {
+		e2e.Logf("failed to write the interval of %s: %v", name, err)
+	}
+	return s.Err
+}
+
+// runEtcdctl runs etcdctl in the etcd pod of the recovery node, or of another running member if it has none
+func (r *Recovery) runEtcdctl(args ...string) (string, error) {
+	out, err := r.oc.AsAdmin().WithoutNamespace().Run("get").Args("pod", "-n", etcdNamespace, "-l", "app=etcd", "--field-selector=status.phase=Running",
+		`-o=jsonpath={range .items[*]}{.metadata.name}{" "}{.spec.nodeName}{"\n"}{end}`).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the etcd pods: %v", err)
+	}
+	var pod string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if pod == "" || sameNode(fields[1], r.recoveryNode) {
+			pod = fields[0]
+		}
+	}
+	if pod == "" {
+		return "", fmt.Errorf("no etcd pod is running")
+	}
+	return r.oc.AsAdmin().WithoutNamespace().Run("exec").Args(append([]string{"-n", etcdNamespace, "-c", "etcdctl", pod, "--", "etcdctl"}, args...)...).Output()
+}
+
+// Members returns the members of the etcd cluster
+func (r *Recovery) Members() ([]Member, error) {
+	out, err := r.etcdctl("member", "list", "-w", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the etcd members: %v", err)
+	}
+	return ParseMemberList(out)
+}
+
+// Revision returns the current revision of the etcd cluster
+func (r *Recovery) Revision() (int64, error) {
+	out, err := r.etcdctl("endpoint", "status", "-w", "json")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the etcd endpoint status: %v", err)
+	}
+	statuses, err := ParseEndpointStatus(out)
+	if err != nil {
+		return 0, err
+	}
+	return maxRevision(statuses), nil
+}
+
+// CreateMarkers creates count ConfigMaps in a namespace of the recovery, the PhasePreBackup ones must be present and
+// the PhasePostBackup ones absent after the restore
+func (r *Recovery) CreateMarkers(phase Phase, count int) error {
+	return r.step(fmt.Sprintf("create-%s-markers", phase), func() (string, error) {
+		if r.markerNamespace == "" {
+			ns := "e2e-etcd-recovery-" + exutil.GetRandomString()
+			if err := r.oc.AsAdmin().WithoutNamespace().Run("create").Args("namespace", ns).Execute(); err != nil {
+				return "", fmt.Errorf("failed to create namespace %s: %v", ns, err)
+			}
+			r.markerNamespace = ns
+		}
+		for i := 0; i < count; i++ {
+			name := fmt.Sprintf("%s-%d", phase, len(r.markers[phase]))
+			err := r.oc.AsAdmin().WithoutNamespace().Run("create").Args("configmap", name, "-n", r.markerNamespace, "--from-literal=phase="+string(phase)).Execute()
+			if err != nil {
+				return "", fmt.Errorf("failed to create ConfigMap %s/%s: %v", r.markerNamespace, name, err)
+			}
+			r.markers[phase] = append(r.markers[phase], name)
+		}
+		return fmt.Sprintf("created %d ConfigMaps in %s", count, r.markerNamespace), nil
+	})
+}
+
+// Backup runs cluster-backup.sh on the recovery node, it's retried until backupTimeout as the backup fails while
+// a revision of etcd is rolled out
+func (r *Recovery) Backup() (*Snapshot, error) {
+	var snapshot *Snapshot
+	err := r.step("backup", func() (string, error) {
+		revision, err := r.Revision()
+		if err != nil {
+			return "", err
+		}
+		node := r.recoveryNode
+		var backupErr error
+		err = wait.PollUntilContextTimeout(context.Background(), r.pollInterval, backupTimeout, true, func(ctx context.Context) (bool, error) {
+			var out string
+			out, backupErr = r.executor.Exec(node, "/usr/local/bin/cluster-backup.sh "+r.BackupDir)
+			if backupErr != nil {
+				e2e.Logf("backup on %s failed, trying again :: %v", node, backupErr)
+				return false, nil
+			}
+			snapshot, backupErr = ParseBackupOutput(node, r.BackupDir, out)
+			return backupErr == nil, nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to back up etcd on %s: %v", node, backupErr)
+		}
+		r.backupRevision = revision
+		return snapshot.String(), nil
+	})
+	return snapshot, err
+}
+
+// VerifySnapshot verifies the hash, the revision and the size of the snapshot on the node
+func (r *Recovery) VerifySnapshot(s *Snapshot) error {
+	return r.step("verify-snapshot", func() (string, error) {
+		out, err := r.executor.Exec(s.Node, "stat -c %s "+s.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to get the size of %s: %v", s.Path, err)
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid size of %s %q: %v", s.Path, out, err)
+		}
+		if err := s.Verify(size, r.backupRevision); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s is consistent, revision %d not older than %d", s.Path, s.Revision, r.backupRevision), nil
+	})
+}
+
+// recordLossRevision keeps the revision before the first disruption of the members
+func (r *Recovery) recordLossRevision() error {
+	if r.lossRevision != 0 {
+		return nil
+	}
+	revision, err := r.Revision()
+	if err != nil {
+		return err
+	}
+	r.lossRevision = revision
+	return nil
+}
+
+// LoseMembers powers off the members without a graceful shutdown, or with one if the platform has no forced power
+// off, the nodes are those of compute.GetNodes and can't include the recovery node. The etcd data of the members is
+// wiped right before, so that the stale members don't start again when the nodes boot before the restore is done.
+func (r *Recovery) LoseMembers(lost compute.Nodes) error {
+	return r.step("lose-members", func() (string, error) {
+		for _, n := range lost {
+			if sameNode(n.GetName(), r.recoveryNode) {
+				return "", fmt.Errorf("can't lose the recovery node %s", r.recoveryNode)
+			}
+		}
+		if err := r.recordLossRevision(); err != nil {
+			return "", err
+		}
+		for _, n := range lost {
+			if out, err := r.executor.Exec(n.GetName(), "/usr/local/bin/disable-etcd.sh && rm -rf /var/lib/etcd"); err != nil {
+				return "", fmt.Errorf("failed to wipe the etcd data of %s: %v\n%s", n.GetName(), err, out)
+			}
+		}
+		for _, n := range lost {
+			err := n.ForceStop()
+			if errors.Is(err, compute.ErrNotSupported) {
+				err = n.Stop()
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to power off %s: %v", n.GetName(), err)
+			}
+			r.lost = append(r.lost, n)
+		}
+		for _, n := range lost {
+			if err := compute.WaitForStopped(n, powerTimeout); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("powered off %v at revision %d", lost.Names(), r.lossRevision), nil
+	})
+}
+
+// DisableMembers runs disable-etcd.sh on every running control plane node, it stops etcd and moves its data away
+// as required before a point-in-time restore
+func (r *Recovery) DisableMembers() error {
+	return r.step("disable-members", func() (string, error) {
+		if err := r.recordLossRevision(); err != nil {
+			return "", err
+		}
+		var disabled []string
+		for _, node := range r.nodes {
+			if r.isLost(node) {
+				continue
+			}
+			if out, err := r.executor.Exec(node, "/usr/local/bin/disable-etcd.sh"); err != nil {
+				return "", fmt.Errorf("failed to disable etcd on %s: %v\n%s", node, err, out)
+			}
+			disabled = append(disabled, node)
+		}
+		return fmt.Sprintf("disabled etcd on %v at revision %d", disabled, r.lossRevision), nil
+	})
+}
+
+// Restore runs cluster-restore.sh with the snapshot on the recovery node
+func (r *Recovery) Restore(s *Snapshot) error {
+	return r.step("restore", func() (string, error) {
+		return r.runRestore("/usr/local/bin/cluster-restore.sh " + s.Dir)
+	})
+}
+
+// QuorumRestore runs quorum-restore.sh on the recovery node, which restores etcd from its own data
+func (r *Recovery) QuorumRestore() error {
+	return r.step("quorum-restore", func() (string, error) {
+		return r.runRestore("/usr/local/bin/quorum-restore.sh")
+	})
+}
+
+func (r *Recovery) runRestore(command string) (string, error) {
+	node := r.recoveryNode
+	out, err := r.executor.Exec(node, command)
+	if err != nil {
+		return "", fmt.Errorf("%s failed on %s: %v\n%s", command, node, err, out)
+	}
+	if !strings.Contains(out, restoreStarted) {
+		return "", fmt.Errorf("%s on %s didn't start the restore pod:\n%s", command, node, out)
+	}
+	return fmt.Sprintf("%s started the restore pod on %s", command, node), nil
+}
+
+// RecoverMembers powers on the lost members, their etcd data was wiped by LoseMembers so that they join the restored
+// cluster as new members
+func (r *Recovery) RecoverMembers() error {
+	return r.step("recover-members", func() (string, error) {
+		lost := r.lost
+		for _, n := range lost {
+			if err := n.Start(); err != nil {
+				return "", fmt.Errorf("failed to power on %s: %v", n.GetName(), err)
+			}
+		}
+		for _, n := range lost {
+			if err := compute.WaitForRunning(n, powerTimeout); err != nil {
+				return "", err
+			}
+		}
+		r.lost = nil
+		return fmt.Sprintf("powered on %v", lost.Names()), nil
+	})
+}
+
+// WaitForRecovery waits, step by step, until the API is available, the control plane nodes are Ready, etcd and
+// kube-apiserver are rolled out and every member is started. The quorum guard is disabled during the rollout.
+func (r *Recovery) WaitForRecovery(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	poll := func(condition func() (bool, error)) error {
+		return wait.PollUntilContextTimeout(context.Background(), r.pollInterval, time.Until(deadline), true, func(ctx context.Context) (bool, error) {
+			return condition()
+		})
+	}
+
+	err := r.step("api-available", func() (string, error) {
+		err := poll(func() (bool, error) {
+			return r.oc.AsAdmin().WithoutNamespace().Run("get").Args("nodes").Execute() == nil, nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("the API isn't available after the restore: %v", err)
+		}
+		return "the API is available", nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.step("nodes-ready", func() (string, error) {
+		var statuses string
+		err := poll(func() (bool, error) {
+			statuses, _ = r.oc.AsAdmin().WithoutNamespace().Run("get").Args("node", "-l", "node-role.kubernetes.io/master=",
+				`-o=jsonpath={.items[*].status.conditions[?(@.type=="Ready")].status}`).Output()
+			ready := strings.Fields(statuses)
+			return len(ready) == len(r.nodes) && !sets.NewString(ready...).HasAny("False", "Unknown"), nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("the control plane nodes are not Ready, Ready conditions %q", statuses)
+		}
+		return fmt.Sprintf("%d control plane nodes are Ready", len(r.nodes)), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.step("disable-quorum-guard", func() (string, error) {
+		err := poll(func() (bool, error) {
+			return r.oc.AsAdmin().WithoutNamespace().Run("patch").Args("etcd", "cluster", "--type=merge", "-p",
+				`{"spec": {"unsupportedConfigOverrides": {"useUnsupportedUnsafeNonHANonProductionUnstableEtcd": true}}}`).Execute() == nil, nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to disable the quorum guard: %v", err)
+		}
+		return "disabled the quorum guard to roll out the static pods", nil
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.oc.AsAdmin().WithoutNamespace().Run("patch").Args("etcd", "cluster", "--type=merge", "-p", `{"spec": {"unsupportedConfigOverrides": null}}`).Execute(); err != nil {
+			e2e.Logf("failed to enable the quorum guard again: %v", err)
+		}
+	}()
+
+	for _, operator := range []string{"etcd", "kube-apiserver"} {
+		operator := operator
+		err = r.step(operator+"-rollout", func() (string, error) {
+			var status string
+			err := poll(func() (bool, error) {
+				status, _ = r.oc.AsAdmin().WithoutNamespace().Run("get").Args("co", operator,
+					`-o=jsonpath={.status.conditions[?(@.type=="Available")].status}{.status.conditions[?(@.type=="Progressing")].status}{.status.conditions[?(@.type=="Degraded")].status}`).Output()
+				return status == "TrueFalseFalse", nil
+			})
+			if err != nil {
+				return "", fmt.Errorf("clusteroperator %s is not rolled out, Available, Progressing and Degraded are %q", operator, status)
+			}
+			return fmt.Sprintf("clusteroperator %s is rolled out", operator), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.step("members-started", func() (string, error) {
+		var members []Member
+		var membersErr error
+		err := poll(func() (bool, error) {
+			members, membersErr = r.Members()
+			return membersErr == nil && checkMembers(members, len(r.nodes)) == nil, nil
+		})
+		if err != nil {
+			if membersErr != nil {
+				return "", membersErr
+			}
+			return "", checkMembers(members, len(r.nodes))
+		}
+		return fmt.Sprintf("%d members are started", len(members)), nil
+	})
+}
+
+// checkMembers returns an error unless there are count started voting members
+func checkMembers(members []Member, count int) error {
+	var notStarted []string
+	for _, m := range members {
+		if !m.Started() || m.IsLearner {
+			notStarted = append(notStarted, fmt.Sprintf("%x", m.ID))
+		}
+	}
+	if len(members) != count || len(notStarted) > 0 {
+		return fmt.Errorf("expected %d started members, got %d members, not started or learners %v", count, len(members), notStarted)
+	}
+	return nil
+}
+
+// VerifyRecovered verifies the recovered cluster: every member is started, the revision didn't go back and the
+// markers created before the snapshot are present while the ones created after it are absent
+func (r *Recovery) VerifyRecovered(s *Snapshot) error {
+	return r.step("verify-recovered", func() (string, error) {
+		var errs []string
+		members, err := r.Members()
+		if err == nil {
+			err = checkMembers(members, len(r.nodes))
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		revision, err := r.Revision()
+		switch {
+		case err != nil:
+			errs = append(errs, err.Error())
+		case s != nil && revision < s.Revision:
+			errs = append(errs, fmt.Sprintf("revision %d is older than the revision %d of the snapshot", revision, s.Revision))
+		case r.ExpectRevisionBump && revision <= r.lossRevision:
+			errs = append(errs, fmt.Sprintf("revision %d is not above the revision %d before the loss, the revision wasn't bumped", revision, r.lossRevision))
+		}
+
+		if r.markerNamespace != "" {
+			if err := r.checkMarkers(); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) > 0 {
+			return "", fmt.Errorf("%s", strings.Join(errs, "; "))
+		}
+		return fmt.Sprintf("%d members started, revision %d, markers restored", len(members), revision), nil
+	})
+}
+
+// checkMarkers returns an error unless the pre-backup markers are present and the post-backup ones absent
+func (r *Recovery) checkMarkers() error {
+	out, err := r.oc.AsAdmin().WithoutNamespace().Run("get").Args("configmap", "-n", r.markerNamespace, "-o=jsonpath={.items[*].metadata.name}").Output()
+	if err != nil {
+		return fmt.Errorf("failed to get the markers in %s: %v", r.markerNamespace, err)
+	}
+	found := sets.NewString(strings.Fields(out)...)
+	missing := sets.NewString(r.markers[PhasePreBackup]...).Difference(found)
+	unexpected := sets.NewString(r.markers[PhasePostBackup]...).Intersection(found)
+	if missing.Len() > 0 || unexpected.Len() > 0 {
+		return fmt.Errorf("markers in %s: missing pre-backup %v, present post-backup %v", r.markerNamespace, missing.List(), unexpected.List())
+	}
+	return nil
+}
+
+// Cleanup deletes the marker namespace and the backup, it's meant to be deferred once the cluster is recovered
+func (r *Recovery) Cleanup() {
+	if r.markerNamespace != "" {
+		if err := r.oc.AsAdmin().WithoutNamespace().Run("delete").Args("namespace", r.markerNamespace, "--ignore-not-found").Execute(); err != nil {
+			e2e.Logf("failed to delete namespace %s: %v", r.markerNamespace, err)
+		}
+	}
+	if _, err := r.executor.Exec(r.recoveryNode, "rm -rf "+r.BackupDir); err != nil {
+		e2e.Logf("failed to delete the backup %s on %s: %v", r.BackupDir, r.recoveryNode, err)
+	}
+}
@@ -0,0 +1,79 @@
+package etcdrecovery
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// snapshotSizeTolerance is the fraction of the size of its status the size of a snapshot on disk can differ by,
+// besides the sha256 appended to the snapshot file, as the status reports the size of the bolt db only
+const snapshotSizeTolerance = 0.01
+
+var (
+	// snapshotSaved matches the line of cluster-backup.sh with the path of the snapshot
+	snapshotSaved = regexp.MustCompile(`Snapshot saved at (\S+\.db)`)
+	// snapshotStatus matches the status of the snapshot printed by cluster-backup.sh
+	snapshotStatus = regexp.MustCompile(`\{"hash":\d+[^}]*\}`)
+)
+
+// Snapshot is an etcd backup taken by cluster-backup.sh
+type Snapshot struct {
+	// Node is the control plane node the backup was taken on
+	Node string `json:"node"`
+	// Path is the path of the snapshot db on the node
+	Path string `json:"path"`
+	// Dir is the backup directory, with the snapshot and the static pod resources
+	Dir string `json:"dir"`
+
+	Hash      uint64 `json:"hash"`
+	Revision  int64  `json:"revision"`
+	TotalKey  int64  `json:"totalKey"`
+	TotalSize int64  `json:"totalSize"`
+
+	TakenAt time.Time `json:"takenAt"`
+}
+
+func (s *Snapshot) String() string {
+	return fmt.Sprintf("%s on %s: hash %d, revision %d, %d keys, %d bytes", s.Path, s.Node, s.Hash, s.Revision, s.TotalKey, s.TotalSize)
+}
+
+// ParseBackupOutput returns the snapshot of the output of cluster-backup.sh
+func ParseBackupOutput(node, dir, output string) (*Snapshot, error) {
+	saved := snapshotSaved.FindStringSubmatch(output)
+	if saved == nil {
+		return nil, fmt.Errorf("no snapshot saved in the backup output:\n%s", output)
+	}
+	status := snapshotStatus.FindString(output)
+	if status == "" {
+		return nil, fmt.Errorf("no snapshot status in the backup output:\n%s", output)
+	}
+	s := &Snapshot{Node: node, Path: saved[1], Dir: dir, TakenAt: time.Now().UTC()}
+	if err := json.Unmarshal([]byte(status), s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot status %s: %v", status, err)
+	}
+	return s, nil
+}
+
+// Verify returns an error unless the snapshot is consistent: it has a hash and keys, its revision isn't older than
+// minRevision, the revision of the cluster before the backup, and the size on disk is the size of its status within
+// snapshotSizeTolerance
+func (s *Snapshot) Verify(sizeOnDisk int64, minRevision int64) error {
+	sizeDiff := sizeOnDisk - s.TotalSize
+	if sizeDiff < 0 {
+		sizeDiff = -sizeDiff
+	}
+	switch {
+	case s.Hash == 0:
+		return fmt.Errorf("snapshot %s has no hash", s.Path)
+	case s.TotalKey == 0:
+		return fmt.Errorf("snapshot %s has no key", s.Path)
+	case s.Revision < minRevision:
+		return fmt.Errorf("snapshot %s has revision %d, older than the revision %d of the cluster before the backup", s.Path, s.Revision, minRevision)
+	case sizeDiff > int64(float64(s.TotalSize)*snapshotSizeTolerance)+sha256.Size:
+		return fmt.Errorf("snapshot %s is %d bytes on disk, its status reports %d bytes", s.Path, sizeOnDisk, s.TotalSize)
+	}
+	return nil
+}
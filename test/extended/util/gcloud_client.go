@@ -104,6 +104,17 @@ func (gcloud *Gcloud) StopInstanceAsync(nodeName string, zoneName string) error
 	return exec.Command("bash", "-c", fmt.Sprintf(`gcloud compute instances stop %s --async --zone=%s`, nodeName, zoneName)).Run()
 }
 
+// ResetInstance Hard reset GCP node/instance
+func (gcloud *Gcloud) ResetInstance(nodeName string, zoneName string) error {
+	return exec.Command("bash", "-c", fmt.Sprintf(`gcloud compute instances reset %s --zone=%s`, nodeName, zoneName)).Run()
+}
+
+// GetSerialPortOutput returns the output of the serial console of GCP node/instance
+func (gcloud *Gcloud) GetSerialPortOutput(nodeName string, zoneName string) (string, error) {
+	output, err := exec.Command("bash", "-c", fmt.Sprintf(`gcloud compute instances get-serial-port-output %s --zone=%s`, nodeName, zoneName)).Output()
+	return string(output), err
+}
+
 // CreateGCSBucket creates a GCS bucket in a project
 func CreateGCSBucket(projectID, bucketName string) error {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -224,6 +235,21 @@ func (gcloud *Gcloud) GetPdVolumeInfo(pvName string, filterArgs ...string) ([]by
 	return pdVolumeInfo, err
 }
 
+// DeletePdVolume deletes the pd volume, the filter args set its location, i.e. "--zone=<zone>" or "--region=<region>"
+func (gcloud *Gcloud) DeletePdVolume(pvName string, filterArgs ...string) error {
+	return exec.Command("bash", "-c", fmt.Sprintf(`gcloud compute disks delete %s %s --quiet`, pvName, strings.Join(filterArgs, " "))).Run()
+}
+
+// ListPdVolumes returns the pd volumes matching the filter args, i.e. "--filter=name=<name>", as a json list
+func (gcloud *Gcloud) ListPdVolumes(filterArgs ...string) ([]byte, error) {
+	return exec.Command("bash", "-c", fmt.Sprintf(`gcloud compute disks list %s --format=json`, strings.Join(filterArgs, " "))).Output()
+}
+
+// ListPdSnapshots returns the pd snapshots matching the filter args, i.e. "--filter=name=<name>", as a json list
+func (gcloud *Gcloud) ListPdSnapshots(filterArgs ...string) ([]byte, error) {
+	return exec.Command("bash", "-c", fmt.Sprintf(`gcloud compute snapshots list %s --format=json`, strings.Join(filterArgs, " "))).Output()
+}
+
 func (gcloud *Gcloud) GetResourceTags(bucketName string, zone string) ([]byte, error) {
 	ResourceTags, err := exec.Command("bash", "-c", fmt.Sprintf(`gcloud resource-manager tags bindings list --parent=//storage.googleapis.com/projects/_/buckets/%s --location=%s`, bucketName, zone)).Output()
 	if len(ResourceTags) == 0 {
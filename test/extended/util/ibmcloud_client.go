@@ -135,6 +135,16 @@ func StopIBMInstance(session *IBMSession, instanceID string) error {
 	return nil
 }
 
+// ForceStopIBMInstance stops the IBM instance immediately, without waiting for the guest to shut down
+func ForceStopIBMInstance(session *IBMSession, instanceID string) error {
+	stopInstanceOptions := session.vpcv1.NewCreateInstanceActionOptions(instanceID, "stop").SetForce(true)
+	_, _, err := session.vpcv1.CreateInstanceAction(stopInstanceOptions)
+	if err != nil {
+		return fmt.Errorf("Unable to force stop IBM instance: %v", err)
+	}
+	return nil
+}
+
 // StartIBMInstance start the IBM instance
 func StartIBMInstance(session *IBMSession, instanceID string) error {
 	startInstanceOptions := session.vpcv1.NewCreateInstanceActionOptions(instanceID, "start")
@@ -206,6 +216,30 @@ func GetIBMInstanceStatus(session *IBMSession, instanceID string) (string, error
 	return *instance.Status, nil
 }
 
+// GetIBMVolume gets the IBM block storage volume by ID, it returns nil if the volume does not exist
+func GetIBMVolume(session *IBMSession, volumeID string) (*vpcv1.Volume, error) {
+	volume, response, err := session.vpcv1.GetVolume(session.vpcv1.NewGetVolumeOptions(volumeID))
+	if response != nil && response.StatusCode == 404 {
+		return nil, nil
+	}
+	return volume, err
+}
+
+// DeleteIBMVolume deletes the IBM block storage volume by ID
+func DeleteIBMVolume(session *IBMSession, volumeID string) error {
+	_, err := session.vpcv1.DeleteVolume(session.vpcv1.NewDeleteVolumeOptions(volumeID))
+	return err
+}
+
+// GetIBMSnapshot gets the IBM block storage snapshot by ID, it returns nil if the snapshot does not exist
+func GetIBMSnapshot(session *IBMSession, snapshotID string) (*vpcv1.Snapshot, error) {
+	snapshot, response, err := session.vpcv1.GetSnapshot(session.vpcv1.NewGetSnapshotOptions(snapshotID))
+	if response != nil && response.StatusCode == 404 {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
 // SetVPCServiceURLForRegion will set the VPC Service URL to a specific IBM Cloud Region, in order to access Region scoped resources
 func SetVPCServiceURLForRegion(session *IBMSession, region string) error {
 	regionOptions := session.vpcv1.NewGetRegionOptions(region)
@@ -0,0 +1,125 @@
+package imageinspect
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Credentials contains the user and password used to authenticate to a registry
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// AuthFile is a decoded docker config file, the file format used by pull-secrets, podman and skopeo's --authfile flag
+type AuthFile struct {
+	Auths map[string]AuthEntry `json:"auths"`
+}
+
+// AuthEntry is an entry in the "auths" section of a docker config file
+type AuthEntry struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// LoadAuthFile reads and decodes a docker config file
+func LoadAuthFile(path string) (*AuthFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading auth file %s: %s", path, err)
+	}
+	return ParseAuthFile(content)
+}
+
+// ParseAuthFile decodes the content of a docker config file
+func ParseAuthFile(content []byte) (*AuthFile, error) {
+	authFile := &AuthFile{}
+	if err := json.Unmarshal(content, authFile); err != nil {
+		return nil, fmt.Errorf("Error decoding auth file: %s", err)
+	}
+	return authFile, nil
+}
+
+// SetCredentials adds or replaces the credentials of a registry in the auth file, the same as "podman login --authfile"
+func (a *AuthFile) SetCredentials(registry string, creds Credentials) *AuthFile {
+	if a.Auths == nil {
+		a.Auths = map[string]AuthEntry{}
+	}
+	a.Auths[registry] = AuthEntry{Auth: base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))}
+	return a
+}
+
+// Marshal encodes the auth file in docker config format
+func (a *AuthFile) Marshal() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// Write stores the auth file in the given path
+func (a *AuthFile) Write(path string) error {
+	content, err := a.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("Error writing auth file %s: %s", path, err)
+	}
+	return nil
+}
+
+// CredentialsFor returns the credentials that should be used to access the given image.
+// As podman does, the most specific entry is used, so an entry for "quay.io/org/repo" takes precedence over "quay.io".
+func (a *AuthFile) CredentialsFor(ref Reference) (*Credentials, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	var (
+		bestKey   string
+		bestEntry AuthEntry
+		found     bool
+	)
+	fullName := ref.Name()
+	for key, entry := range a.Auths {
+		normalized := normalizeAuthKey(key)
+		if normalized == "index.docker.io" || normalized == dockerHubAPIHost {
+			normalized = DefaultRegistry
+		}
+		if normalized != fullName && normalized != ref.Registry && !strings.HasPrefix(fullName, normalized+"/") {
+			continue
+		}
+		if !found || len(normalized) > len(bestKey) {
+			bestKey, bestEntry, found = normalized, entry, true
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	if bestEntry.Username != "" {
+		return &Credentials{Username: bestEntry.Username, Password: bestEntry.Password}, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(bestEntry.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding the auth for %s: %s", bestKey, err)
+	}
+	userPass := strings.SplitN(string(decoded), ":", 2)
+	if len(userPass) != 2 {
+		return nil, fmt.Errorf("The auth for %s is not in user:password format", bestKey)
+	}
+	return &Credentials{Username: userPass[0], Password: userPass[1]}, nil
+}
+
+// normalizeAuthKey removes the scheme and the legacy /v1/ or /v2/ suffixes from the keys in the auth file
+func normalizeAuthKey(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	key = strings.TrimSuffix(key, "/v1/")
+	key = strings.TrimSuffix(key, "/v2/")
+	return strings.TrimSuffix(key, "/")
+}
@@ -0,0 +1,487 @@
+package imageinspect
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
+)
+
+// manifestAcceptHeader lists all the manifest media types that the client can decode
+var manifestAcceptHeader = strings.Join([]string{
+	MediaTypeOCIIndex,
+	MediaTypeDockerManifestList,
+	MediaTypeOCIManifest,
+	MediaTypeDockerManifest,
+}, ", ")
+
+// Client is a minimal registry v2 API client that can be used instead of skopeo to inspect, list and copy images
+type Client struct {
+	httpClient *http.Client
+	authFile   string
+	auth       *AuthFile
+	insecure   bool
+	plainHTTP  map[string]bool
+	tokens     map[string]string
+	mu         sync.Mutex
+}
+
+// NewClient returns a new registry client. By default TLS is verified and no credentials are used.
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 5 * time.Minute, Transport: http.DefaultTransport.(*http.Transport).Clone()},
+		plainHTTP:  map[string]bool{},
+		tokens:     map[string]string{},
+	}
+}
+
+// SetAuthFile sets the docker config file used to authenticate to the registries, the same as skopeo's --authfile flag
+func (c *Client) SetAuthFile(authFile string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authFile = authFile
+	c.auth = nil
+	return c
+}
+
+// SetAuth sets an already decoded docker config to authenticate to the registries
+func (c *Client) SetAuth(auth *AuthFile) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authFile = ""
+	c.auth = auth
+	return c
+}
+
+// SetInsecure disables TLS verification, the same as skopeo's --tls-verify=false flag
+func (c *Client) SetInsecure(insecure bool) *Client {
+	c.insecure = insecure
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		// #nosec G402 -- test registries usually use self-signed certificates
+		transport.TLSClientConfig.InsecureSkipVerify = insecure
+	}
+	return c
+}
+
+// SetPlainHTTP makes the client use http instead of https to talk to the given registry host
+func (c *Client) SetPlainHTTP(registry string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plainHTTP[registry] = true
+	return c
+}
+
+// SetTransport overrides the http transport used by the client
+func (c *Client) SetTransport(transport http.RoundTripper) *Client {
+	c.httpClient.Transport = transport
+	return c
+}
+
+// GetManifest returns the manifest that the reference points to, without resolving manifest lists
+func (c *Client) GetManifest(ctx context.Context, ref Reference) (*RawManifest, error) {
+	resp, err := c.do(ctx, ref, http.MethodGet, "/manifests/"+ref.Identifier(), map[string]string{"Accept": manifestAcceptHeader}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading manifest for %s: %s", ref, err)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = ComputeDigest(content)
+	}
+	if ref.Digest != "" && ComputeDigest(content) != ref.Digest {
+		return nil, fmt.Errorf("The manifest returned for %s does not match its digest", ref)
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if mediaType == "" || mediaType == "application/json" || mediaType == "text/plain" {
+		mediaType = detectManifestMediaType(content)
+	}
+
+	return &RawManifest{MediaType: mediaType, Digest: digest, Content: content}, nil
+}
+
+// ResolveDigest returns the digest of the manifest that the reference points to. If the image is a manifest list, the digest of the list is returned.
+func (c *Client) ResolveDigest(ctx context.Context, ref Reference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+
+	resp, err := c.do(ctx, ref, http.MethodHead, "/manifests/"+ref.Tag, map[string]string{"Accept": manifestAcceptHeader}, nil)
+	if err == nil {
+		resp.Body.Close()
+		if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+			return digest, nil
+		}
+	}
+
+	// Some registries do not return the digest in HEAD requests, in that case we calculate it
+	manifest, err := c.GetManifest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	return manifest.Digest, nil
+}
+
+// GetPlatformManifest returns the single-architecture manifest for the given platform.
+// If the reference does not point to a manifest list, the platform is ignored and the manifest is returned as is.
+func (c *Client) GetPlatformManifest(ctx context.Context, ref Reference, platform Platform) (*RawManifest, *Manifest, error) {
+	raw, err := c.GetManifest(ctx, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if raw.IsIndex() {
+		index, err := raw.Index()
+		if err != nil {
+			return nil, nil, err
+		}
+		desc, err := SelectPlatform(index, platform)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error selecting platform in image %s: %s", ref, err)
+		}
+		logger.Debugf("Image %s is a manifest list. Using manifest %s for platform %s", ref, desc.Digest, platform)
+		raw, err = c.GetManifest(ctx, ref.WithDigest(desc.Digest))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	manifest, err := raw.Manifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, manifest, nil
+}
+
+// GetBlob returns the content of a blob. The content is verified against the digest.
+func (c *Client) GetBlob(ctx context.Context, ref Reference, digest string) ([]byte, error) {
+	resp, err := c.do(ctx, ref, http.MethodGet, "/blobs/"+digest, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading blob %s from %s: %s", digest, ref.Name(), err)
+	}
+	if ComputeDigest(content) != digest {
+		return nil, fmt.Errorf("The content of blob %s in %s does not match its digest", digest, ref.Name())
+	}
+	return content, nil
+}
+
+// BlobExists returns true if the blob exists in the repository
+func (c *Client) BlobExists(ctx context.Context, ref Reference, digest string) (bool, error) {
+	resp, err := c.do(ctx, ref, http.MethodHead, "/blobs/"+digest, nil, nil)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
+// PutBlob uploads a blob to the repository using a monolithic upload
+func (c *Client) PutBlob(ctx context.Context, ref Reference, content []byte) (string, error) {
+	digest := ComputeDigest(content)
+	exists, err := c.BlobExists(ctx, ref, digest)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		logger.Debugf("Blob %s already exists in %s. Skip upload", digest, ref.Name())
+		return digest, nil
+	}
+
+	resp, err := c.do(ctx, ref, http.MethodPost, "/blobs/uploads/", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("The registry did not return the upload location for %s: %s", ref.Name(), err)
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = c.doURL(ctx, ref, http.MethodPut, location.String(), map[string]string{"Content-Type": "application/octet-stream"}, content)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return digest, nil
+}
+
+// PutManifest uploads a manifest to the repository using the reference's tag, or its digest if it has no tag
+func (c *Client) PutManifest(ctx context.Context, ref Reference, mediaType string, content []byte) (string, error) {
+	identifier := ref.Tag
+	if identifier == "" {
+		identifier = ComputeDigest(content)
+	}
+	resp, err := c.do(ctx, ref, http.MethodPut, "/manifests/"+identifier, map[string]string{"Content-Type": mediaType}, content)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return ComputeDigest(content), nil
+}
+
+// ListTags returns the tags of the repository
+func (c *Client) ListTags(ctx context.Context, ref Reference) ([]string, error) {
+	resp, err := c.do(ctx, ref, http.MethodGet, "/tags/list", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	tags := struct {
+		Tags []string `json:"tags"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("Error decoding tags list for %s: %s", ref.Name(), err)
+	}
+	return tags.Tags, nil
+}
+
+// HTTPError is returned when the registry answers with an unexpected status code
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// IsNotFound returns true if the error is a registry 404 answer
+func IsNotFound(err error) bool {
+	if httpErr, ok := err.(*HTTPError); ok {
+		return httpErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+func (c *Client) baseURL(ref Reference) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	scheme := "https"
+	if c.plainHTTP[ref.Registry] {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s", scheme, ref.apiHost(), ref.Repository)
+}
+
+func (c *Client) do(ctx context.Context, ref Reference, method, path string, headers map[string]string, body []byte) (*http.Response, error) {
+	return c.doURL(ctx, ref, method, c.baseURL(ref)+path, headers, body)
+}
+
+// doURL executes the request and handles the registry's authentication challenges
+func (c *Client) doURL(ctx context.Context, ref Reference, method, rawURL string, headers map[string]string, body []byte) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
+	if method != http.MethodGet && method != http.MethodHead {
+		scope = fmt.Sprintf("repository:%s:pull,push", ref.Repository)
+	}
+
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return c.httpClient.Do(req)
+	}
+
+	resp, err := send(c.cachedToken(ref, scope))
+	if err != nil {
+		return nil, fmt.Errorf("Error executing %s %s: %s", method, rawURL, err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		authorization, err := c.authorize(ctx, ref, scope, challenge)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = send(authorization)
+		if err != nil {
+			return nil, fmt.Errorf("Error executing %s %s: %s", method, rawURL, err)
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &HTTPError{Method: method, URL: rawURL, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(errBody))}
+	}
+
+	return resp, nil
+}
+
+func (c *Client) cachedToken(ref Reference, scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[ref.Registry+"/"+scope]
+}
+
+func (c *Client) credentials(ref Reference) (*Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.auth == nil && c.authFile != "" {
+		auth, err := LoadAuthFile(c.authFile)
+		if err != nil {
+			return nil, err
+		}
+		c.auth = auth
+	}
+	return c.auth.CredentialsFor(ref)
+}
+
+// authorize answers a WWW-Authenticate challenge and returns the value for the Authorization header
+func (c *Client) authorize(ctx context.Context, ref Reference, scope, challenge string) (string, error) {
+	creds, err := c.credentials(ref)
+	if err != nil {
+		return "", err
+	}
+
+	authType, params := parseChallenge(challenge)
+	var authorization string
+	switch strings.ToLower(authType) {
+	case "basic":
+		if creds == nil {
+			return "", fmt.Errorf("Registry %s requires basic authentication but there are no credentials for %s", ref.Registry, ref.Name())
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(creds.Username, creds.Password)
+		authorization = req.Header.Get("Authorization")
+	case "bearer":
+		token, err := c.fetchToken(ctx, params, scope, creds)
+		if err != nil {
+			return "", err
+		}
+		authorization = "Bearer " + token
+	default:
+		return "", fmt.Errorf("Unsupported authentication challenge from registry %s: %q", ref.Registry, challenge)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[ref.Registry+"/"+scope] = authorization
+	return authorization, nil
+}
+
+func (c *Client) fetchToken(ctx context.Context, params map[string]string, scope string, creds *Credentials) (string, error) {
+	realm, ok := params["realm"]
+	if !ok {
+		return "", fmt.Errorf("The bearer challenge has no realm")
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("Wrong realm %s in bearer challenge: %s", realm, err)
+	}
+	query := tokenURL.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if creds != nil {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Error requesting token to %s: %s", realm, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", &HTTPError{Method: http.MethodGet, URL: realm, StatusCode: resp.StatusCode, Body: string(errBody)}
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("Error decoding token returned by %s: %s", realm, err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header like: Bearer realm="https://auth",service="registry",scope="repository:a:pull"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimSpace(rest[eq+1:])
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if comma := strings.Index(rest, ","); comma >= 0 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = value
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+	return parts[0], params
+}
@@ -0,0 +1,264 @@
+package imageinspect
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		image    string
+		expected Reference
+	}{
+		{"quay.io/openshift/img:v1", Reference{Registry: "quay.io", Repository: "openshift/img", Tag: "v1"}},
+		{"docker://quay.io/openshift/img", Reference{Registry: "quay.io", Repository: "openshift/img", Tag: "latest"}},
+		{"localhost:5000/img@" + digest, Reference{Registry: "localhost:5000", Repository: "img", Digest: digest}},
+		{"registry:5000/ns/img:tag@" + digest, Reference{Registry: "registry:5000", Repository: "ns/img", Tag: "tag", Digest: digest}},
+		{"busybox", Reference{Registry: "docker.io", Repository: "library/busybox", Tag: "latest"}},
+	}
+
+	for _, test := range tests {
+		ref, err := ParseReference(test.image)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %s: %s", test.image, err)
+		}
+		if ref != test.expected {
+			t.Errorf("Wrong reference parsing %s. Expected %+v, got %+v", test.image, test.expected, ref)
+		}
+	}
+
+	for _, image := range []string{"", "quay.io/img@sha256:1234", "quay.io/Upper/img"} {
+		if _, err := ParseReference(image); err == nil {
+			t.Errorf("Expected error parsing %q", image)
+		}
+	}
+}
+
+func TestCredentialsFor(t *testing.T) {
+	auth := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	authFile, err := ParseAuthFile([]byte(`{"auths": {
+		"quay.io": {"auth": "` + auth("generic:pass") + `"},
+		"quay.io/org/repo": {"auth": "` + auth("specific:pass") + `"},
+		"https://index.docker.io/v1/": {"auth": "` + auth("hub:pass") + `"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"quay.io/org/repo:tag": "specific",
+		"quay.io/org/other":    "generic",
+		"busybox":              "hub",
+		"registry.io/img":      "",
+	}
+	for image, user := range expected {
+		ref, _ := ParseReference(image)
+		creds, err := authFile.CredentialsFor(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if creds != nil {
+			got = creds.Username
+		}
+		if got != user {
+			t.Errorf("Wrong credentials for %s. Expected user %q, got %q", image, user, got)
+		}
+	}
+}
+
+func TestSetCredentials(t *testing.T) {
+	authFile, err := ParseAuthFile([]byte(`{"auths": {"quay.io": {"username": "quay", "password": "pass"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := authFile.SetCredentials("image-registry.openshift-image-registry.svc:5000", Credentials{Username: "builder", Password: "token"}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := ParseAuthFile(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for image, user := range map[string]string{"quay.io/org/repo": "quay", "image-registry.openshift-image-registry.svc:5000/ns/img": "builder"} {
+		ref, _ := ParseReference(image)
+		creds, err := written.CredentialsFor(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds == nil || creds.Username != user {
+			t.Errorf("Wrong credentials for %s. Expected user %q, got %+v", image, user, creds)
+		}
+	}
+}
+
+func TestInspectAndCopy(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(NewMemoryStore()).SetBasicAuth("user", "pass").Start()
+	defer registry.Close()
+
+	amdDigest, err := registry.PushImage("mco/layering", "", ImageConfig{
+		OS: "linux", Architecture: "amd64",
+		Config: ContainerConfig{Labels: map[string]string{"ostree.bootable": "true"}},
+	}, []byte("layer1"), []byte("layer2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	armDigest, err := registry.PushImage("mco/layering", "", ImageConfig{OS: "linux", Architecture: "arm64"}, []byte("layer3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexDigest, err := registry.PushIndex("mco/layering", "latest", map[Platform]string{
+		NewPlatform("amd64"): amdDigest,
+		NewPlatform("arm64"): armDigest,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := registry.Host() + "/mco/layering:latest"
+	client := registry.NewClient().SetAuth(&AuthFile{Auths: map[string]AuthEntry{registry.Host(): {Username: "user", Password: "pass"}}})
+	inspector := NewInspector(client).SetArchitecture("amd64")
+
+	info, err := inspector.Inspect(ctx, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Digest != indexDigest || info.ManifestDigest != amdDigest {
+		t.Errorf("Wrong digests. Expected %s and %s, got %s and %s", indexDigest, amdDigest, info.Digest, info.ManifestDigest)
+	}
+	if info.Labels["ostree.bootable"] != "true" || len(info.Layers) != 2 {
+		t.Errorf("Wrong image info %+v", info)
+	}
+	if info.DigestedName() != registry.Host()+"/mco/layering@"+indexDigest {
+		t.Errorf("Wrong digested name %s", info.DigestedName())
+	}
+
+	layers, err := inspector.SetArchitecture("arm64").ListLayers(ctx, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 1 || layers[0].Digest != ComputeDigest([]byte("layer3")) {
+		t.Errorf("Wrong arm64 layers %+v", layers)
+	}
+
+	if _, err := inspector.SetArchitecture("s390x").InspectConfig(ctx, image); err == nil {
+		t.Errorf("Expected error selecting a platform that is not in the manifest list")
+	}
+
+	copied, err := inspector.Copy(ctx, image, registry.Host()+"/mco/copy:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied != indexDigest {
+		t.Errorf("Copied image should keep the digest %s, got %s", indexDigest, copied)
+	}
+	tags, err := client.ListTags(ctx, Reference{Registry: registry.Host(), Repository: "mco/copy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "v1" {
+		t.Errorf("Wrong tags in copied repository %v", tags)
+	}
+
+	noAuth := NewInspector(registry.NewClient())
+	if _, err := noAuth.Inspect(ctx, image); err == nil {
+		t.Errorf("Expected error inspecting without credentials")
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	dir, err := os.MkdirTemp("", "imageinspect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewDirStore(filepath.Join(dir, "registry"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := NewRegistry(store).PushImage("ns/img", "v1", ImageConfig{OS: "linux", Architecture: "amd64"}, []byte("layer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new registry on the same directory must serve the stored image
+	registry := NewRegistry(store).Start()
+	defer registry.Close()
+	resolved, err := registry.NewClient().ResolveDigest(context.Background(), Reference{Registry: registry.Host(), Repository: "ns/img", Tag: "v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != digest {
+		t.Errorf("Expected digest %s, got %s", digest, resolved)
+	}
+}
+
+func TestGetReleaseImageFor(t *testing.T) {
+	layer := func(compress bool, files map[string]string) []byte {
+		buf := &bytes.Buffer{}
+		var writer io.Writer = buf
+		var gzipWriter *gzip.Writer
+		if compress {
+			gzipWriter = gzip.NewWriter(buf)
+			writer = gzipWriter
+		}
+		tarWriter := tar.NewWriter(writer)
+		for name, content := range files {
+			if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tarWriter.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tarWriter.Close()
+		if gzipWriter != nil {
+			gzipWriter.Close()
+		}
+		return buf.Bytes()
+	}
+
+	registry := NewRegistry(NewMemoryStore()).Start()
+	defer registry.Close()
+	_, err := registry.PushImage("ocp/release", "4.18", ImageConfig{OS: "linux", Architecture: "amd64"},
+		layer(true, map[string]string{"usr/bin/cluster-version-operator": "binary"}),
+		layer(false, map[string]string{"release-manifests/image-references": `{"kind": "ImageStream", "spec": {"tags": [
+			{"name": "rhel-coreos", "from": {"kind": "DockerImage", "name": "quay.io/ocp/release@sha256:rhcos"}},
+			{"name": "tools", "from": {"kind": "DockerImage", "name": "quay.io/ocp/release@sha256:tools"}}]}}`}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	inspector := NewInspector(registry.NewClient())
+	release := registry.Host() + "/ocp/release:4.18"
+	image, err := inspector.GetReleaseImageFor(ctx, release, "tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image != "quay.io/ocp/release@sha256:tools" {
+		t.Errorf("Wrong image for tools %s", image)
+	}
+	if _, err := inspector.GetReleaseImageFor(ctx, release, "unknown"); err == nil {
+		t.Errorf("Expected error getting a component that is not in the release")
+	}
+
+	content, err := inspector.ReadFile(ctx, release, "/usr/bin/cluster-version-operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "binary" {
+		t.Errorf("Wrong content read from the compressed layer %q", content)
+	}
+}
@@ -0,0 +1,283 @@
+package imageinspect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+
+	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
+)
+
+// DefaultPlatform returns the platform selected in manifest lists when no other platform is requested. As skopeo does, it is the platform running the test.
+func DefaultPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+}
+
+// NewPlatform returns a linux platform for the given architecture. The architecture uses the golang names: amd64, arm64, ppc64le, s390x
+func NewPlatform(arch string) Platform {
+	return Platform{OS: "linux", Architecture: arch}
+}
+
+// SelectPlatform returns the descriptor of the manifest for the given platform in a manifest list.
+// If the platform has no variant, the first manifest matching os and architecture is returned.
+func SelectPlatform(index *Index, platform Platform) (*Descriptor, error) {
+	var candidate *Descriptor
+	for i := range index.Manifests {
+		desc := &index.Manifests[i]
+		if desc.Platform == nil || desc.Platform.OS != platform.OS || desc.Platform.Architecture != platform.Architecture {
+			continue
+		}
+		if desc.Platform.Variant == platform.Variant {
+			return desc, nil
+		}
+		if platform.Variant == "" && candidate == nil {
+			candidate = desc
+		}
+	}
+	if candidate != nil {
+		return candidate, nil
+	}
+
+	available := []string{}
+	for _, desc := range index.Manifests {
+		if desc.Platform != nil {
+			available = append(available, desc.Platform.String())
+		}
+	}
+	return nil, fmt.Errorf("No manifest for platform %s. Available platforms: %v", platform, available)
+}
+
+// Inspector resolves images using a registry Client and a platform to select images in manifest lists
+type Inspector struct {
+	client   *Client
+	platform Platform
+}
+
+// NewInspector returns an inspector that uses the given client. The default platform is used to resolve manifest lists.
+func NewInspector(client *Client) *Inspector {
+	return &Inspector{client: client, platform: DefaultPlatform()}
+}
+
+// SetPlatform sets the platform used to resolve manifest lists, the same as skopeo's --override-os and --override-arch flags
+func (i *Inspector) SetPlatform(platform Platform) *Inspector {
+	i.platform = platform
+	return i
+}
+
+// SetArchitecture sets the architecture used to resolve manifest lists in linux images
+func (i *Inspector) SetArchitecture(arch string) *Inspector {
+	return i.SetPlatform(NewPlatform(arch))
+}
+
+// Client returns the registry client used by the inspector
+func (i *Inspector) Client() *Client {
+	return i.client
+}
+
+// Inspect returns the same information as "skopeo inspect docker://image"
+func (i *Inspector) Inspect(ctx context.Context, image string) (*ImageInfo, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	top, err := i.client.GetManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, manifest, err := i.client.GetPlatformManifest(ctx, ref.WithDigest(top.Digest), i.platform)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := i.getConfig(ctx, ref, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ImageInfo{
+		Name:           ref.Name(),
+		Tag:            ref.Tag,
+		Digest:         top.Digest,
+		ManifestDigest: raw.Digest,
+		Created:        config.Created,
+		Labels:         config.Config.Labels,
+		Architecture:   config.Architecture,
+		Os:             config.OS,
+		Variant:        config.Variant,
+		Env:            config.Config.Env,
+		LayersData:     manifest.Layers,
+		RepoTags:       []string{},
+	}
+	for _, layer := range manifest.Layers {
+		info.Layers = append(info.Layers, layer.Digest)
+	}
+
+	logger.Debugf("Image %s inspected. Digest: %s", image, info.Digest)
+	return info, nil
+}
+
+// InspectConfig returns the image configuration, the same as "skopeo inspect --config docker://image"
+func (i *Inspector) InspectConfig(ctx context.Context, image string) (*ImageConfig, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	_, manifest, err := i.client.GetPlatformManifest(ctx, ref, i.platform)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.getConfig(ctx, ref, manifest)
+}
+
+// ListLayers returns the layers of the image for the configured platform
+func (i *Inspector) ListLayers(ctx context.Context, image string) ([]Descriptor, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	_, manifest, err := i.client.GetPlatformManifest(ctx, ref, i.platform)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Layers, nil
+}
+
+// ListPlatforms returns the platforms available in the image. Single-architecture images return the platform in their config.
+func (i *Inspector) ListPlatforms(ctx context.Context, image string) ([]Platform, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := i.client.GetManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if !raw.IsIndex() {
+		manifest, err := raw.Manifest()
+		if err != nil {
+			return nil, err
+		}
+		config, err := i.getConfig(ctx, ref, manifest)
+		if err != nil {
+			return nil, err
+		}
+		return []Platform{{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}}, nil
+	}
+
+	index, err := raw.Index()
+	if err != nil {
+		return nil, err
+	}
+	platforms := []Platform{}
+	for _, desc := range index.Manifests {
+		if desc.Platform != nil {
+			platforms = append(platforms, *desc.Platform)
+		}
+	}
+	return platforms, nil
+}
+
+// GetDigestedImage returns the image in name@digest format
+func (i *Inspector) GetDigestedImage(ctx context.Context, image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	digest, err := i.client.ResolveDigest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	return ref.WithDigest(digest).DigestedString(), nil
+}
+
+// Copy copies an image between repositories, the same as "skopeo copy --all docker://src docker://dst".
+// Manifest lists are copied with all their platforms. It returns the digest of the copied image.
+func (i *Inspector) Copy(ctx context.Context, srcImage, dstImage string) (string, error) {
+	src, err := ParseReference(srcImage)
+	if err != nil {
+		return "", err
+	}
+	dst, err := ParseReference(dstImage)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := i.client.GetManifest(ctx, src)
+	if err != nil {
+		return "", err
+	}
+
+	if raw.IsIndex() {
+		index, err := raw.Index()
+		if err != nil {
+			return "", err
+		}
+		for _, desc := range index.Manifests {
+			child, err := i.client.GetManifest(ctx, src.WithDigest(desc.Digest))
+			if err != nil {
+				return "", err
+			}
+			if err := i.copyManifestBlobs(ctx, src, dst, child); err != nil {
+				return "", err
+			}
+			untagged := dst
+			untagged.Tag = ""
+			if _, err := i.client.PutManifest(ctx, untagged, child.MediaType, child.Content); err != nil {
+				return "", err
+			}
+		}
+	} else if err := i.copyManifestBlobs(ctx, src, dst, raw); err != nil {
+		return "", err
+	}
+
+	digest, err := i.client.PutManifest(ctx, dst, raw.MediaType, raw.Content)
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("Image %s copied to %s with digest %s", srcImage, dstImage, digest)
+	return digest, nil
+}
+
+func (i *Inspector) copyManifestBlobs(ctx context.Context, src, dst Reference, raw *RawManifest) error {
+	manifest, err := raw.Manifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range append([]Descriptor{manifest.Config}, manifest.Layers...) {
+		exists, err := i.client.BlobExists(ctx, dst, desc.Digest)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		content, err := i.client.GetBlob(ctx, src, desc.Digest)
+		if err != nil {
+			return err
+		}
+		if _, err := i.client.PutBlob(ctx, dst, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Inspector) getConfig(ctx context.Context, ref Reference, manifest *Manifest) (*ImageConfig, error) {
+	content, err := i.client.GetBlob(ctx, ref, manifest.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	config := &ImageConfig{}
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("Error decoding config %s of image %s: %s", manifest.Config.Digest, ref, err)
+	}
+	return config, nil
+}
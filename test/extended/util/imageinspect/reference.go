@@ -0,0 +1,133 @@
+package imageinspect
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultRegistry is the registry used when an image name does not include one
+	DefaultRegistry = "docker.io"
+	// dockerHubAPIHost is the host serving the registry API for docker.io
+	dockerHubAPIHost = "registry-1.docker.io"
+	// transportPrefix is the skopeo transport prefix that can be found in image names copied from skopeo commands
+	transportPrefix = "docker://"
+)
+
+// Reference is a parsed image pull spec like quay.io/openshift/image:tag or quay.io/openshift/image@sha256:xxx
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image pull spec. The "docker://" skopeo transport prefix is accepted and ignored.
+// If the image has no tag and no digest the "latest" tag is used.
+func ParseReference(image string) (Reference, error) {
+	ref := Reference{}
+	name := strings.TrimPrefix(strings.TrimSpace(image), transportPrefix)
+	if name == "" {
+		return ref, fmt.Errorf("Empty image reference")
+	}
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if err := validateDigest(ref.Digest); err != nil {
+			return ref, fmt.Errorf("Wrong digest in image %s: %s", image, err)
+		}
+	}
+
+	// The tag is whatever is after the last ':' as long as it is not part of the registry's host:port
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		ref.Repository = parts[1]
+	} else {
+		ref.Registry = DefaultRegistry
+		ref.Repository = name
+	}
+
+	if ref.Registry == DefaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	if ref.Repository == "" || strings.HasSuffix(ref.Repository, "/") {
+		return ref, fmt.Errorf("Wrong repository in image %s", image)
+	}
+	if ref.Repository != strings.ToLower(ref.Repository) {
+		return ref, fmt.Errorf("Repository names must be lowercase. Image: %s", image)
+	}
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	return ref, nil
+}
+
+// Name returns the image name without tag and without digest
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// Identifier returns the value used to query the manifest in the registry. The digest if there is one, or the tag if there is no digest.
+func (r Reference) Identifier() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// WithDigest returns a copy of the reference pointing to the given digest
+func (r Reference) WithDigest(digest string) Reference {
+	r.Digest = digest
+	return r
+}
+
+// String returns the full pull spec of the reference
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// DigestedString returns the pull spec in the name@digest format. The reference must contain a digest.
+func (r Reference) DigestedString() string {
+	return r.Name() + "@" + r.Digest
+}
+
+// apiHost returns the host that serves the registry v2 API for this reference
+func (r Reference) apiHost() string {
+	if r.Registry == DefaultRegistry {
+		return dockerHubAPIHost
+	}
+	return r.Registry
+}
+
+func validateDigest(digest string) error {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("digest %s is not in algorithm:hex format", digest)
+	}
+	if parts[0] == "sha256" && len(parts[1]) != 64 {
+		return fmt.Errorf("sha256 digest %s must have 64 hex characters", digest)
+	}
+	for _, c := range parts[1] {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return fmt.Errorf("digest %s contains non hex characters", digest)
+		}
+	}
+	return nil
+}
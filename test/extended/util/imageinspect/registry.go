@@ -0,0 +1,452 @@
+package imageinspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned by the registry stores when a blob, a manifest or a tag does not exist
+var ErrNotFound = fmt.Errorf("not found")
+
+// Store is the storage used by the Registry stand-in
+type Store interface {
+	// GetBlob returns the content of a blob
+	GetBlob(repo, digest string) ([]byte, error)
+	// PutBlob stores a blob. The digest is calculated by the caller.
+	PutBlob(repo, digest string, content []byte) error
+	// GetManifest returns the content of a manifest by tag or by digest
+	GetManifest(repo, reference string) ([]byte, error)
+	// PutManifest stores a manifest and, if the reference is not a digest, tags it
+	PutManifest(repo, reference string, content []byte) error
+	// Tags returns the tags in the repository
+	Tags(repo string) ([]string, error)
+}
+
+// MemoryStore is a Store that keeps everything in memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	tags      map[string]map[string]string
+}
+
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+		tags:      map[string]map[string]string{},
+	}
+}
+
+// GetBlob implements Store
+func (s *MemoryStore) GetBlob(repo, digest string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.blobs[repo+"@"+digest]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return content, nil
+}
+
+// PutBlob implements Store
+func (s *MemoryStore) PutBlob(repo, digest string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[repo+"@"+digest] = content
+	return nil
+}
+
+// GetManifest implements Store
+func (s *MemoryStore) GetManifest(repo, reference string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if digest, ok := s.tags[repo][reference]; ok {
+		reference = digest
+	}
+	content, ok := s.manifests[repo+"@"+reference]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return content, nil
+}
+
+// PutManifest implements Store
+func (s *MemoryStore) PutManifest(repo, reference string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	digest := ComputeDigest(content)
+	s.manifests[repo+"@"+digest] = content
+	if reference != digest {
+		if _, ok := s.tags[repo]; !ok {
+			s.tags[repo] = map[string]string{}
+		}
+		s.tags[repo][reference] = digest
+	}
+	return nil
+}
+
+// Tags implements Store
+func (s *MemoryStore) Tags(repo string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tags := []string{}
+	for tag := range s.tags[repo] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// DirStore is a Store that keeps the images in a local directory with the layout <dir>/<repo>/{blobs,manifests,tags}
+type DirStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewDirStore returns a store that saves the images in the given directory. Existing content in the directory is reused.
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("Error creating registry directory %s: %s", dir, err)
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (s *DirStore) path(repo, kind, name string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(repo), kind, strings.ReplaceAll(name, ":", "_"))
+}
+
+func (s *DirStore) read(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return content, err
+}
+
+func (s *DirStore) write(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// GetBlob implements Store
+func (s *DirStore) GetBlob(repo, digest string) ([]byte, error) {
+	return s.read(s.path(repo, "blobs", digest))
+}
+
+// PutBlob implements Store
+func (s *DirStore) PutBlob(repo, digest string, content []byte) error {
+	return s.write(s.path(repo, "blobs", digest), content)
+}
+
+// GetManifest implements Store
+func (s *DirStore) GetManifest(repo, reference string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if validateDigest(reference) != nil {
+		digest, err := s.read(s.path(repo, "tags", reference))
+		if err != nil {
+			return nil, err
+		}
+		reference = string(digest)
+	}
+	return s.read(s.path(repo, "manifests", reference))
+}
+
+// PutManifest implements Store
+func (s *DirStore) PutManifest(repo, reference string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	digest := ComputeDigest(content)
+	if err := s.write(s.path(repo, "manifests", digest), content); err != nil {
+		return err
+	}
+	if reference != digest {
+		return s.write(s.path(repo, "tags", reference), []byte(digest))
+	}
+	return nil
+}
+
+// Tags implements Store
+func (s *DirStore) Tags(repo string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, filepath.FromSlash(repo), "tags"))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tags := []string{}
+	for _, entry := range entries {
+		tags = append(tags, entry.Name())
+	}
+	return tags, nil
+}
+
+var registryPathRegexp = regexp.MustCompile(`^/v2/(.+)/(manifests|blobs|tags)/(.*)$`)
+
+// Registry is a registry v2 API stand-in backed by a Store. It can be used to test the image helpers without a real registry.
+type Registry struct {
+	store    Store
+	server   *httptest.Server
+	username string
+	password string
+	uploads  map[string]bool
+	mu       sync.Mutex
+}
+
+// NewRegistry returns a registry stand-in serving the images in the given store. Use Start to listen in a local port or use it as an http.Handler.
+func NewRegistry(store Store) *Registry {
+	return &Registry{store: store, uploads: map[string]bool{}}
+}
+
+// SetBasicAuth makes the registry require the given credentials in every request
+func (r *Registry) SetBasicAuth(username, password string) *Registry {
+	r.username = username
+	r.password = password
+	return r
+}
+
+// Start starts serving plain http in a random local port
+func (r *Registry) Start() *Registry {
+	r.server = httptest.NewServer(r)
+	return r
+}
+
+// StartTLS starts serving https in a random local port using a self-signed certificate
+func (r *Registry) StartTLS() *Registry {
+	r.server = httptest.NewTLSServer(r)
+	return r
+}
+
+// Host returns the host:port where the registry is listening
+func (r *Registry) Host() string {
+	if r.server == nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(r.server.URL, "http://"), "https://")
+}
+
+// Close stops the registry server
+func (r *Registry) Close() {
+	if r.server != nil {
+		r.server.Close()
+	}
+}
+
+// NewClient returns a client configured to talk to this registry
+func (r *Registry) NewClient() *Client {
+	client := NewClient()
+	if r.server != nil {
+		client.SetTransport(r.server.Client().Transport)
+		if strings.HasPrefix(r.server.URL, "http://") {
+			client.SetPlainHTTP(r.Host())
+		}
+	}
+	return client
+}
+
+// PushImage stores a single-architecture image built with the given config and layers. It returns the manifest digest.
+func (r *Registry) PushImage(repo, tag string, config ImageConfig, layers ...[]byte) (string, error) {
+	configContent, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeOCIManifest,
+		Config:        Descriptor{MediaType: MediaTypeOCIConfig, Digest: ComputeDigest(configContent), Size: int64(len(configContent))},
+	}
+	if err := r.store.PutBlob(repo, manifest.Config.Digest, configContent); err != nil {
+		return "", err
+	}
+	for _, layer := range layers {
+		desc := Descriptor{MediaType: MediaTypeOCILayer, Digest: ComputeDigest(layer), Size: int64(len(layer))}
+		if err := r.store.PutBlob(repo, desc.Digest, layer); err != nil {
+			return "", err
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+	return r.pushManifest(repo, tag, manifest)
+}
+
+// PushIndex stores a manifest list with the given platform manifests, that must already exist in the repository. It returns the manifest list digest.
+func (r *Registry) PushIndex(repo, tag string, manifests map[Platform]string) (string, error) {
+	index := Index{SchemaVersion: 2, MediaType: MediaTypeOCIIndex}
+	platforms := []Platform{}
+	for platform := range manifests {
+		platforms = append(platforms, platform)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i].String() < platforms[j].String() })
+
+	for _, platform := range platforms {
+		content, err := r.store.GetManifest(repo, manifests[platform])
+		if err != nil {
+			return "", fmt.Errorf("Error getting manifest %s for platform %s: %s", manifests[platform], platform, err)
+		}
+		p := platform
+		index.Manifests = append(index.Manifests, Descriptor{
+			MediaType: detectManifestMediaType(content),
+			Digest:    ComputeDigest(content),
+			Size:      int64(len(content)),
+			Platform:  &p,
+		})
+	}
+	return r.pushManifest(repo, tag, index)
+}
+
+func (r *Registry) pushManifest(repo, tag string, manifest interface{}) (string, error) {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	digest := ComputeDigest(content)
+	if tag == "" {
+		tag = digest
+	}
+	return digest, r.store.PutManifest(repo, tag, content)
+}
+
+// ServeHTTP implements the subset of the registry v2 API used by the Client
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.username != "" {
+		user, pass, ok := req.BasicAuth()
+		if !ok || user != r.username || pass != r.password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="imageinspect"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	if req.URL.Path == "/v2/" || req.URL.Path == "/v2" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	matches := registryPathRegexp.FindStringSubmatch(req.URL.Path)
+	if matches == nil {
+		http.NotFound(w, req)
+		return
+	}
+	repo, kind, reference := matches[1], matches[2], matches[3]
+
+	switch {
+	case kind == "tags" && reference == "list" && req.Method == http.MethodGet:
+		r.serveTags(w, repo)
+	case kind == "manifests" && (req.Method == http.MethodGet || req.Method == http.MethodHead):
+		r.serveManifest(w, req, repo, reference)
+	case kind == "manifests" && req.Method == http.MethodPut:
+		r.receiveManifest(w, req, repo, reference)
+	case kind == "blobs" && strings.HasPrefix(reference, "uploads/"):
+		r.receiveBlob(w, req, repo, strings.TrimPrefix(reference, "uploads/"))
+	case kind == "blobs" && (req.Method == http.MethodGet || req.Method == http.MethodHead):
+		r.serveBlob(w, req, repo, reference)
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *Registry) serveTags(w http.ResponseWriter, repo string) {
+	tags, err := r.store.Tags(repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repo, "tags": tags})
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, repo, reference string) {
+	content, err := r.store.GetManifest(repo, reference)
+	if err != nil {
+		http.Error(w, "manifest unknown", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", detectManifestMediaType(content))
+	w.Header().Set("Docker-Content-Digest", ComputeDigest(content))
+	http.ServeContent(w, req, "", time.Time{}, strings.NewReader(string(content)))
+}
+
+func (r *Registry) receiveManifest(w http.ResponseWriter, req *http.Request, repo, reference string) {
+	content, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if validateDigest(reference) == nil && ComputeDigest(content) != reference {
+		http.Error(w, "digest invalid", http.StatusBadRequest)
+		return
+	}
+	if err := r.store.PutManifest(repo, reference, content); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", ComputeDigest(content))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (r *Registry) serveBlob(w http.ResponseWriter, req *http.Request, repo, digest string) {
+	content, err := r.store.GetBlob(repo, digest)
+	if err != nil {
+		http.Error(w, "blob unknown", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", digest)
+	http.ServeContent(w, req, "", time.Time{}, strings.NewReader(string(content)))
+}
+
+// receiveBlob implements monolithic uploads: POST to start the upload and PUT with the digest and the whole content
+func (r *Registry) receiveBlob(w http.ResponseWriter, req *http.Request, repo, uploadID string) {
+	switch req.Method {
+	case http.MethodPost:
+		id := uuid.NewString()
+		r.mu.Lock()
+		r.uploads[id] = true
+		r.mu.Unlock()
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", repo, id))
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		r.mu.Lock()
+		_, ok := r.uploads[uploadID]
+		delete(r.uploads, uploadID)
+		r.mu.Unlock()
+		if !ok {
+			http.Error(w, "blob upload unknown", http.StatusNotFound)
+			return
+		}
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		digest := req.URL.Query().Get("digest")
+		if ComputeDigest(content) != digest {
+			http.Error(w, "digest invalid", http.StatusBadRequest)
+			return
+		}
+		if err := r.store.PutBlob(repo, digest, content); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
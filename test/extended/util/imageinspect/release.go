@@ -0,0 +1,109 @@
+package imageinspect
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	logger "github.com/openshift/openshift-tests-private/test/extended/util/logext"
+)
+
+// releaseImageReferencesPath is the file in the release payload that maps the release components to their images
+const releaseImageReferencesPath = "release-manifests/image-references"
+
+// imageReferences is the ImageStream stored in the image-references file of the release payload
+type imageReferences struct {
+	Spec struct {
+		Tags []struct {
+			Name string `json:"name"`
+			From struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			} `json:"from"`
+		} `json:"tags"`
+	} `json:"spec"`
+}
+
+// GetReleaseImageFor returns the image of a component in a release payload, the same as "oc adm release info --image-for component releaseImage"
+func (i *Inspector) GetReleaseImageFor(ctx context.Context, releaseImage, component string) (string, error) {
+	content, err := i.ReadFile(ctx, releaseImage, releaseImageReferencesPath)
+	if err != nil {
+		return "", err
+	}
+
+	references := imageReferences{}
+	if err := json.Unmarshal(content, &references); err != nil {
+		return "", fmt.Errorf("Error decoding %s in release %s: %s", releaseImageReferencesPath, releaseImage, err)
+	}
+	for _, tag := range references.Spec.Tags {
+		if tag.Name == component {
+			logger.Debugf("Release %s uses image %s for %s", releaseImage, tag.From.Name, component)
+			return tag.From.Name, nil
+		}
+	}
+	// same message as "oc adm release info", so that the tests can check the missing images in the same way
+	return "", fmt.Errorf("no image tag %q exists in the release image %s", component, releaseImage)
+}
+
+// ReadFile returns the content of a file in the image filesystem. The layers are read from the top one, so that the content of the last layer adding the file is returned.
+func (i *Inspector) ReadFile(ctx context.Context, image, filePath string) ([]byte, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	_, manifest, err := i.client.GetPlatformManifest(ctx, ref, i.platform)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath = path.Clean("/" + filePath)
+	for idx := len(manifest.Layers) - 1; idx >= 0; idx-- {
+		layer, err := i.client.GetBlob(ctx, ref, manifest.Layers[idx].Digest)
+		if err != nil {
+			return nil, err
+		}
+		content, found, err := readFileFromLayer(layer, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading layer %s of image %s: %s", manifest.Layers[idx].Digest, image, err)
+		}
+		if found {
+			return content, nil
+		}
+	}
+	return nil, fmt.Errorf("File %s does not exist in image %s", filePath, image)
+}
+
+// readFileFromLayer looks for a file in a layer tarball, compressed with gzip or not
+func readFileFromLayer(layer []byte, filePath string) ([]byte, bool, error) {
+	var reader io.Reader = bytes.NewReader(layer)
+	if bytes.HasPrefix(layer, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, false, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if path.Clean("/"+header.Name) != filePath || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		return content, true, err
+	}
+}
@@ -0,0 +1,176 @@
+package imageinspect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Media types supported by the client
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeDockerConfig       = "application/vnd.docker.container.image.v1+json"
+	MediaTypeDockerLayer        = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	MediaTypeOCIConfig          = "application/vnd.oci.image.config.v1+json"
+	MediaTypeOCILayer           = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// Descriptor describes a blob or a manifest stored in a registry
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *Platform         `json:"platform,omitempty"`
+}
+
+// Platform describes the os and architecture of an image in a manifest list
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// String returns the platform in os/arch[/variant] format
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Manifest is a single-architecture image manifest (docker v2 schema 2 or OCI)
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Index is a multi-architecture manifest list (docker manifest list or OCI index)
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// ImageConfig is the image configuration blob. Only the fields used by the tests are decoded.
+type ImageConfig struct {
+	Architecture string          `json:"architecture"`
+	OS           string          `json:"os"`
+	Variant      string          `json:"variant,omitempty"`
+	Created      *time.Time      `json:"created,omitempty"`
+	Config       ContainerConfig `json:"config"`
+	RootFS       RootFS          `json:"rootfs"`
+}
+
+// ContainerConfig is the execution configuration stored in the image config
+type ContainerConfig struct {
+	Labels     map[string]string `json:"Labels,omitempty"`
+	Env        []string          `json:"Env,omitempty"`
+	Cmd        []string          `json:"Cmd,omitempty"`
+	Entrypoint []string          `json:"Entrypoint,omitempty"`
+	User       string            `json:"User,omitempty"`
+	WorkingDir string            `json:"WorkingDir,omitempty"`
+}
+
+// RootFS lists the uncompressed layer digests of the image
+type RootFS struct {
+	Type    string   `json:"type"`
+	DiffIDs []string `json:"diff_ids"`
+}
+
+// RawManifest is a manifest as returned by the registry, before being decoded
+type RawManifest struct {
+	MediaType string
+	Digest    string
+	Content   []byte
+}
+
+// IsIndex returns true if the manifest is a manifest list or an OCI index
+func (m RawManifest) IsIndex() bool {
+	return isIndexMediaType(m.MediaType)
+}
+
+// Index decodes the raw manifest as a manifest list
+func (m RawManifest) Index() (*Index, error) {
+	if !m.IsIndex() {
+		return nil, fmt.Errorf("Manifest %s with media type %s is not a manifest list", m.Digest, m.MediaType)
+	}
+	index := &Index{}
+	if err := json.Unmarshal(m.Content, index); err != nil {
+		return nil, fmt.Errorf("Error decoding manifest list %s: %s", m.Digest, err)
+	}
+	return index, nil
+}
+
+// Manifest decodes the raw manifest as a single-architecture image manifest
+func (m RawManifest) Manifest() (*Manifest, error) {
+	if m.IsIndex() {
+		return nil, fmt.Errorf("Manifest %s is a manifest list, a platform must be selected first", m.Digest)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(m.Content, manifest); err != nil {
+		return nil, fmt.Errorf("Error decoding manifest %s: %s", m.Digest, err)
+	}
+	return manifest, nil
+}
+
+// ImageInfo contains the same information reported by "skopeo inspect docker://image"
+type ImageInfo struct {
+	Name         string            `json:"Name"`
+	Tag          string            `json:"Tag,omitempty"`
+	Digest       string            `json:"Digest"`
+	RepoTags     []string          `json:"RepoTags"`
+	Created      *time.Time        `json:"Created,omitempty"`
+	Labels       map[string]string `json:"Labels"`
+	Architecture string            `json:"Architecture"`
+	Os           string            `json:"Os"`
+	Variant      string            `json:"Variant,omitempty"`
+	Layers       []string          `json:"Layers"`
+	LayersData   []Descriptor      `json:"LayersData"`
+	Env          []string          `json:"Env"`
+	// ManifestDigest is the digest of the platform specific manifest. It is the same as Digest if the image is not a manifest list.
+	ManifestDigest string `json:"-"`
+}
+
+// DigestedName returns the image in name@digest format, the value that we need to configure digested osImages
+func (i ImageInfo) DigestedName() string {
+	return i.Name + "@" + i.Digest
+}
+
+// ComputeDigest returns the sha256 digest of the given content in algorithm:hex format
+func ComputeDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func isIndexMediaType(mediaType string) bool {
+	return mediaType == MediaTypeDockerManifestList || mediaType == MediaTypeOCIIndex
+}
+
+// detectManifestMediaType returns the media type declared inside a manifest, guessing it from the content if it is not declared
+func detectManifestMediaType(content []byte) string {
+	probe := struct {
+		MediaType string            `json:"mediaType"`
+		Manifests []json.RawMessage `json:"manifests"`
+	}{}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return ""
+	}
+	if probe.MediaType != "" {
+		return probe.MediaType
+	}
+	if probe.Manifests != nil {
+		return MediaTypeOCIIndex
+	}
+	return MediaTypeOCIManifest
+}
@@ -0,0 +1,120 @@
+package loggen
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formats of the generated lines
+const (
+	// FormatUnstructured lines are plain text: loggen stream=<stream> seq=<seq> <payload>
+	FormatUnstructured = "unstructured"
+	// FormatStructured lines are JSON objects: {"stream":"<stream>","seq":<seq>,"level":"info","message":"<payload>"}
+	FormatStructured = "structured"
+	// FormatMultiline lines are java stack traces, the first line is the unstructured line of the sequence
+	FormatMultiline = "multiline"
+)
+
+const payloadAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Config is the configuration of a generator
+type Config struct {
+	// Stream identifies the generator in the lines, i.e. the pod name
+	Stream string
+	// Format is FormatUnstructured, FormatStructured or FormatMultiline
+	Format string
+	// Rate is the number of lines per second, 0 means as fast as possible
+	Rate float64
+	// Size is the length of the payload of every line
+	Size int
+	// Count is the number of lines, 0 means unlimited
+	Count int64
+}
+
+// Validate returns an error if the configuration can't be generated
+func (c Config) Validate() error {
+	if c.Stream == "" || strings.ContainsAny(c.Stream, " \t\r\n\"\\") {
+		return fmt.Errorf("invalid stream %q", c.Stream)
+	}
+	switch c.Format {
+	case FormatUnstructured, FormatStructured, FormatMultiline:
+	default:
+		return fmt.Errorf("unknown format %q", c.Format)
+	}
+	if c.Rate < 0 || c.Size < 0 || c.Count < 0 {
+		return fmt.Errorf("rate, size and count can't be negative")
+	}
+	return nil
+}
+
+// Payload returns the deterministic payload of the sequence, the alphabet is rotated by the sequence so that the
+// payloads of consecutive lines differ
+func Payload(seq int64, size int) string {
+	b := make([]byte, size)
+	offset := int(seq % int64(len(payloadAlphabet)))
+	for i := range b {
+		b[i] = payloadAlphabet[(offset+i)%len(payloadAlphabet)]
+	}
+	return string(b)
+}
+
+// Line returns the line of the sequence, sequences start from 1, multiline lines contain new lines
+func Line(format, stream string, seq int64, size int) string {
+	payload := Payload(seq, size)
+	switch format {
+	case FormatStructured:
+		b, _ := json.Marshal(struct {
+			Stream  string `json:"stream"`
+			Seq     int64  `json:"seq"`
+			Level   string `json:"level"`
+			Message string `json:"message"`
+		}{stream, seq, "info", payload})
+		return string(b)
+	case FormatMultiline:
+		return fmt.Sprintf("loggen stream=%s seq=%d java.lang.RuntimeException: %s\n"+
+			"\tat com.openshift.loggen.Generator.emit(Generator.java:%d)\n"+
+			"\tat com.openshift.loggen.Generator.run(Generator.java:42)\n"+
+			"\tat java.base/java.lang.Thread.run(Thread.java:833)", stream, seq, payload, seq%1000)
+	default:
+		return fmt.Sprintf("loggen stream=%s seq=%d %s", stream, seq, payload)
+	}
+}
+
+// Run writes the lines to w at the rate of the configuration until Count lines are written or the context is done,
+// it returns the number of lines written
+func Run(ctx context.Context, config Config, w io.Writer) (int64, error) {
+	if err := config.Validate(); err != nil {
+		return 0, err
+	}
+	out := bufio.NewWriter(w)
+	start := time.Now()
+	var seq int64
+	for config.Count == 0 || seq < config.Count {
+		if config.Rate > 0 {
+			// pace by the start time instead of a ticker, so that slow writes don't lower the rate
+			next := start.Add(time.Duration(float64(seq) / config.Rate * float64(time.Second)))
+			if d := time.Until(next); d > 0 {
+				if err := out.Flush(); err != nil {
+					return seq, err
+				}
+				select {
+				case <-ctx.Done():
+					return seq, nil
+				case <-time.After(d):
+				}
+			}
+		} else if ctx.Err() != nil {
+			break
+		}
+		seq++
+		if _, err := out.WriteString(Line(config.Format, config.Stream, seq, config.Size) + "\n"); err != nil {
+			return seq - 1, err
+		}
+	}
+	return seq, out.Flush()
+}
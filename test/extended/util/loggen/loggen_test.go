@@ -0,0 +1,133 @@
+package loggen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLine(t *testing.T) {
+	for _, format := range []string{FormatUnstructured, FormatStructured, FormatMultiline} {
+		line := Line(format, "pod-a", 42, 16)
+		stream, seq, ok := ParseLine(line)
+		if !ok || stream != "pod-a" || seq != 42 {
+			t.Errorf("%s: ParseLine(%q) = %q, %d, %v", format, line, stream, seq, ok)
+		}
+		if !strings.Contains(line, Payload(42, 16)) {
+			t.Errorf("%s: %q doesn't contain the payload", format, line)
+		}
+		if Line(format, "pod-a", 42, 16) != line {
+			t.Errorf("%s: the line isn't deterministic", format)
+		}
+	}
+	if lines := strings.Split(Line(FormatMultiline, "pod-a", 1, 8), "\n"); len(lines) != 4 {
+		t.Errorf("expected a 4 lines stack trace, got %q", lines)
+	}
+}
+
+func TestParseLineForwarded(t *testing.T) {
+	// the collector either parses the structured line or keeps it as a string in its own record
+	structured := Line(FormatStructured, "pod-b", 7, 8)
+	// the collector reserializes the parsed line with the keys sorted, seq comes before stream
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(structured), &fields); err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := json.Marshal(map[string]interface{}{"structured": fields, "kubernetes": map[string]string{"pod_name": "pod-b"}, "stream": "stdout"})
+	if !strings.Contains(string(parsed), `"seq":7,"stream":"pod-b"`) {
+		t.Fatalf("expected the keys to be sorted in %s", parsed)
+	}
+	escaped, _ := json.Marshal(map[string]string{"message": structured})
+	for _, line := range []string{structured, string(parsed), string(escaped)} {
+		stream, seq, ok := ParseLine(line)
+		if !ok || stream != "pod-b" || seq != 7 {
+			t.Errorf("ParseLine(%s) = %q, %d, %v", line, stream, seq, ok)
+		}
+	}
+	if _, _, ok := ParseLine("some other log"); ok {
+		t.Errorf("expected no match")
+	}
+}
+
+func TestRun(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+	n, err := Run(context.Background(), Config{Stream: "pod-a", Format: FormatStructured, Rate: 100, Size: 8, Count: 20}, &buf)
+	if err != nil || n != 20 {
+		t.Fatalf("Run() = %d, %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("20 lines at 100/s took %v", elapsed)
+	}
+	report := Verify(strings.Split(strings.TrimSpace(buf.String()), "\n"), 20)
+	if !report.OK() || report.Unmatched != 0 || len(report.Streams) != 1 || report.Streams[0].Received != 20 {
+		t.Errorf("unexpected report %s", report)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if n, err := Run(ctx, Config{Stream: "pod-a", Format: FormatUnstructured, Rate: 1}, &buf); err != nil || n > 1 {
+		t.Errorf("Run() with a done context = %d, %v", n, err)
+	}
+	if _, err := Run(context.Background(), Config{Stream: "pod a", Format: FormatUnstructured}, &buf); err == nil {
+		t.Errorf("expected an error for an invalid stream")
+	}
+}
+
+func TestLastSequence(t *testing.T) {
+	for _, format := range []string{FormatUnstructured, FormatStructured, FormatMultiline} {
+		var buf bytes.Buffer
+		if n, err := Run(context.Background(), Config{Stream: "pod-a", Format: format, Size: 8, Count: 20}, &buf); err != nil || n != 20 {
+			t.Fatalf("%s: Run() = %d, %v", format, n, err)
+		}
+		lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+		tail := strings.Join(lines[len(lines)-TailLines:], "\n")
+		if seq, ok := LastSequence(tail); !ok || seq != 20 {
+			t.Errorf("%s: LastSequence(%q) = %d, %v", format, tail, seq, ok)
+		}
+	}
+	if _, ok := LastSequence("some other log\n"); ok {
+		t.Errorf("expected no sequence")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var lines []string
+	for _, seq := range []int64{1, 2, 4, 3, 4, 7, 8} {
+		lines = append(lines, Line(FormatUnstructured, "pod-a", seq, 4))
+	}
+	lines = append(lines, "unrelated", Line(FormatStructured, "pod-b", 1, 4), Line(FormatStructured, "pod-b", 2, 4))
+
+	report := Verify(lines, 10)
+	if report.OK() || report.Unmatched != 1 || len(report.Streams) != 2 {
+		t.Fatalf("unexpected report %s", report)
+	}
+	a := report.Stream("pod-a")
+	expected := StreamReport{
+		Stream:     "pod-a",
+		Received:   7,
+		Expected:   10,
+		Missing:    []Range{{5, 6}, {9, 10}},
+		Duplicated: []int64{4},
+		OutOfOrder: []int64{3},
+	}
+	if !reflect.DeepEqual(*a, expected) {
+		t.Errorf("got %+v, expected %+v", *a, expected)
+	}
+	if a.MissingCount() != 4 || a.LossRatio() != 0.4 {
+		t.Errorf("got %d missing, loss ratio %v", a.MissingCount(), a.LossRatio())
+	}
+
+	// without the expected count, the stream is expected up to its highest sequence
+	report = Verify(lines, 0)
+	if b := report.Stream("pod-b"); b == nil || !b.OK() || b.Expected != 2 {
+		t.Errorf("unexpected report %s", report)
+	}
+	if a := report.Stream("pod-a"); a.Expected != 8 || a.MissingCount() != 2 {
+		t.Errorf("unexpected report %s", report)
+	}
+}
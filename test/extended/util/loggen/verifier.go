@@ -0,0 +1,251 @@
+package loggen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// unstructuredSeq matches the unstructured and multiline lines
+var unstructuredSeq = regexp.MustCompile(`loggen stream=([^\s"\\]+) seq=(\d+)`)
+
+// ParseLine returns the stream and the sequence of a generated line, the line can be the raw line, the message of the
+// record or the whole record serialized by the collector
+func ParseLine(line string) (stream string, seq int64, ok bool) {
+	if m := unstructuredSeq.FindStringSubmatch(line); m != nil {
+		seq, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil {
+			return "", 0, false
+		}
+		return m[1], seq, true
+	}
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", 0, false
+	}
+	return structuredSeq(value)
+}
+
+// structuredSeq looks for the object of a structured line in the decoded JSON value, the collector either parses the
+// line into a field of its record, in any key order, or keeps it as a string
+func structuredSeq(value interface{}) (string, int64, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		stream, isString := v["stream"].(string)
+		number, isNumber := v["seq"].(json.Number)
+		if isString && isNumber {
+			if seq, err := number.Int64(); err == nil {
+				return stream, seq, true
+			}
+		}
+		for _, field := range v {
+			if stream, seq, ok := structuredSeq(field); ok {
+				return stream, seq, true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if stream, seq, ok := structuredSeq(item); ok {
+				return stream, seq, true
+			}
+		}
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "{") {
+			return ParseLine(v)
+		}
+	}
+	return "", 0, false
+}
+
+// TailLines is the number of lines of the log tail that is enough to contain a sequence, a multiline sequence spans
+// 4 lines
+const TailLines = 8
+
+// LastSequence returns the last sequence of the log output, the lines are scanned backwards since the last lines of
+// a multiline sequence are stack trace lines without sequence
+func LastSequence(output string) (int64, bool) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if _, seq, ok := ParseLine(lines[i]); ok {
+			return seq, true
+		}
+	}
+	return 0, false
+}
+
+// Range is a closed range of sequences
+type Range struct {
+	First int64 `json:"first"`
+	Last  int64 `json:"last"`
+}
+
+func (r Range) String() string {
+	if r.First == r.Last {
+		return strconv.FormatInt(r.First, 10)
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+// StreamReport is the verification of the lines of one stream
+type StreamReport struct {
+	Stream string `json:"stream"`
+	// Received is the number of lines received, duplicates included
+	Received int `json:"received"`
+	// Expected is the number of lines the stream should have, the highest sequence received if it isn't known
+	Expected int64 `json:"expected"`
+	// Missing are the sequences between 1 and Expected never received
+	Missing []Range `json:"missing,omitempty"`
+	// Duplicated are the sequences received more than once
+	Duplicated []int64 `json:"duplicated,omitempty"`
+	// OutOfOrder are the sequences received after a higher sequence
+	OutOfOrder []int64 `json:"outOfOrder,omitempty"`
+}
+
+// MissingCount returns the number of missing sequences
+func (s StreamReport) MissingCount() int64 {
+	var n int64
+	for _, r := range s.Missing {
+		n += r.Last - r.First + 1
+	}
+	return n
+}
+
+// LossRatio returns the ratio of the missing sequences to the expected ones
+func (s StreamReport) LossRatio() float64 {
+	if s.Expected == 0 {
+		return 0
+	}
+	return float64(s.MissingCount()) / float64(s.Expected)
+}
+
+// OK returns true if every expected sequence was received once and in order
+func (s StreamReport) OK() bool {
+	return len(s.Missing) == 0 && len(s.Duplicated) == 0 && len(s.OutOfOrder) == 0
+}
+
+func (s StreamReport) String() string {
+	return fmt.Sprintf("stream %s: received %d of %d, missing %d %v, duplicated %d %v, out of order %d %v", s.Stream, s.Received, s.Expected,
+		s.MissingCount(), truncate(s.Missing), len(s.Duplicated), truncate(s.Duplicated), len(s.OutOfOrder), truncate(s.OutOfOrder))
+}
+
+// truncate keeps the first elements of the slice so that the report of a heavy loss stays readable
+func truncate[T any](s []T) []T {
+	if len(s) > 10 {
+		return s[:10]
+	}
+	return s
+}
+
+// Report is the verification of the lines of all the streams
+type Report struct {
+	Streams []StreamReport `json:"streams"`
+	// Unmatched is the number of lines which aren't generated lines
+	Unmatched int `json:"unmatched"`
+}
+
+// Stream returns the report of the stream, nil if no line of the stream was received
+func (r Report) Stream(stream string) *StreamReport {
+	for i := range r.Streams {
+		if r.Streams[i].Stream == stream {
+			return &r.Streams[i]
+		}
+	}
+	return nil
+}
+
+// OK returns true if the lines of every stream were received once and in order
+func (r Report) OK() bool {
+	for _, s := range r.Streams {
+		if !s.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// LossRatio returns the ratio of the missing sequences to the expected ones of all the streams
+func (r Report) LossRatio() float64 {
+	var missing, expected int64
+	for _, s := range r.Streams {
+		missing += s.MissingCount()
+		expected += s.Expected
+	}
+	if expected == 0 {
+		return 0
+	}
+	return float64(missing) / float64(expected)
+}
+
+func (r Report) String() string {
+	lines := make([]string, 0, len(r.Streams)+1)
+	for _, s := range r.Streams {
+		lines = append(lines, s.String())
+	}
+	if r.Unmatched > 0 {
+		lines = append(lines, fmt.Sprintf("%d lines are not generated lines", r.Unmatched))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Verify reports the missing, duplicated and out of order sequences per stream, the lines must be in the order they
+// were received, expected is the number of lines each stream generated, 0 if it isn't known
+func Verify(lines []string, expected int64) Report {
+	type state struct {
+		report StreamReport
+		seen   map[int64]bool
+		max    int64
+	}
+	streams := map[string]*state{}
+	report := Report{}
+	for _, line := range lines {
+		stream, seq, ok := ParseLine(line)
+		if !ok {
+			report.Unmatched++
+			continue
+		}
+		s := streams[stream]
+		if s == nil {
+			s = &state{report: StreamReport{Stream: stream}, seen: map[int64]bool{}}
+			streams[stream] = s
+		}
+		s.report.Received++
+		switch {
+		case s.seen[seq]:
+			s.report.Duplicated = append(s.report.Duplicated, seq)
+		case seq < s.max:
+			s.report.OutOfOrder = append(s.report.OutOfOrder, seq)
+		}
+		s.seen[seq] = true
+		if seq > s.max {
+			s.max = seq
+		}
+	}
+
+	for _, s := range streams {
+		s.report.Expected = expected
+		if expected == 0 {
+			s.report.Expected = s.max
+		}
+		var gap *Range
+		for seq := int64(1); seq <= s.report.Expected; seq++ {
+			if s.seen[seq] {
+				gap = nil
+				continue
+			}
+			if gap == nil {
+				s.report.Missing = append(s.report.Missing, Range{First: seq, Last: seq})
+				gap = &s.report.Missing[len(s.report.Missing)-1]
+			} else {
+				gap.Last = seq
+			}
+		}
+		report.Streams = append(report.Streams, s.report)
+	}
+	sort.Slice(report.Streams, func(i, j int) bool { return report.Streams[i].Stream < report.Streams[j].Stream })
+	return report
+}
@@ -0,0 +1,119 @@
+package logreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Transport sends the requests of the client to the API of the receiver, i.e. through the service proxy of the API server
+type Transport interface {
+	// Get returns the body of the response to GET the path, the path includes the query
+	Get(path string) ([]byte, error)
+	// Delete sends DELETE to the path
+	Delete(path string) error
+}
+
+// Client queries the records of a receiver
+type Client struct {
+	transport    Transport
+	pollInterval time.Duration
+}
+
+// NewClient returns the client of the transport
+func NewClient(transport Transport) *Client {
+	return &Client{transport: transport, pollInterval: 10 * time.Second}
+}
+
+// Records returns the records selected by the query
+func (c *Client) Records(q Query) ([]Record, error) {
+	path := RecordsPath
+	if values := q.Values(); len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	body, err := c.transport.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	records := []Record{}
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, fmt.Errorf("error decoding the records: %v", err)
+	}
+	return records, nil
+}
+
+// Reset removes all the records of the receiver
+func (c *Client) Reset() error {
+	return c.transport.Delete(RecordsPath)
+}
+
+// LastID returns the ID of the last record, the records received later are selected by Query{After: id}
+func (c *Client) LastID() (int64, error) {
+	records, err := c.Records(Query{})
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+	return records[len(records)-1].ID, nil
+}
+
+// WaitForRecords waits for the receiver to have at least count records selected by the query and returns them
+func (c *Client) WaitForRecords(q Query, count int, timeout time.Duration) ([]Record, error) {
+	var records []Record
+	err := wait.PollUntilContextTimeout(context.Background(), c.pollInterval, timeout, true, func(context.Context) (bool, error) {
+		var err error
+		if records, err = c.Records(q); err != nil {
+			return false, nil
+		}
+		return len(records) >= count, nil
+	})
+	if err != nil {
+		return records, fmt.Errorf("got %d of %d records of %v: %v", len(records), count, q.Values().Encode(), err)
+	}
+	return records, nil
+}
+
+// HTTPTransport sends the requests to the address of the API
+type HTTPTransport struct {
+	Address string
+	Client  *http.Client
+}
+
+func (t HTTPTransport) do(method, path string) ([]byte, error) {
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest(method, t.Address+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: %s %s", method, path, resp.Status, body)
+	}
+	return body, nil
+}
+
+// Get implements Transport
+func (t HTTPTransport) Get(path string) ([]byte, error) {
+	return t.do(http.MethodGet, path)
+}
+
+// Delete implements Transport
+func (t HTTPTransport) Delete(path string) error {
+	_, err := t.do(http.MethodDelete, path)
+	return err
+}
@@ -0,0 +1,413 @@
+package logreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// isProtobuf returns whether the content type is protobuf, JSON is the default
+func isProtobuf(contentType string) bool {
+	return strings.Contains(contentType, "protobuf")
+}
+
+// decodeLines returns the records of a generic HTTP body, a JSON array or newline delimited documents
+func decodeLines(body []byte) ([]Record, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	if trimmed[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("error decoding the JSON array: %v", err)
+		}
+		records := []Record{}
+		for _, item := range items {
+			records = append(records, Record{Message: string(item)})
+		}
+		return records, nil
+	}
+	records := []Record{}
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			records = append(records, Record{Message: line})
+		}
+	}
+	return records, scanner.Err()
+}
+
+// splunkEvent is an event of the Splunk HTTP Event Collector
+type splunkEvent struct {
+	Time       json.RawMessage        `json:"time,omitempty"`
+	Host       string                 `json:"host,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	SourceType string                 `json:"sourcetype,omitempty"`
+	Index      string                 `json:"index,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Event      json.RawMessage        `json:"event"`
+}
+
+// decodeSplunkEvents returns the records of the concatenated HEC events
+func decodeSplunkEvents(body []byte) ([]Record, error) {
+	records := []Record{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for decoder.More() {
+		var event splunkEvent
+		if err := decoder.Decode(&event); err != nil {
+			return nil, fmt.Errorf("error decoding the HEC event: %v", err)
+		}
+		metadata := map[string]string{}
+		for key, value := range map[string]string{"host": event.Host, "source": event.Source, "sourcetype": event.SourceType, "index": event.Index, "time": string(event.Time)} {
+			if value != "" {
+				metadata[key] = value
+			}
+		}
+		for key, value := range event.Fields {
+			metadata["fields."+key] = stringValue(value)
+		}
+		records = append(records, Record{Metadata: metadata, Message: rawString(event.Event)})
+	}
+	return records, nil
+}
+
+// rawString returns the JSON string unquoted, or the JSON document
+func rawString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// stringValue returns the string of a decoded JSON value
+func stringValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	out, _ := json.Marshal(value)
+	return string(out)
+}
+
+// bulkResponse is the response of the Elasticsearch bulk API
+type bulkResponse struct {
+	Took   int                            `json:"took"`
+	Errors bool                           `json:"errors"`
+	Items  []map[string]map[string]string `json:"items"`
+}
+
+// decodeBulk returns the records of the Elasticsearch bulk request and the response of the request
+func decodeBulk(body []byte) ([]Record, bulkResponse, error) {
+	records, err := decodeLines(body)
+	response := bulkResponse{Items: []map[string]map[string]string{}}
+	if err != nil {
+		return nil, response, err
+	}
+	documents := []Record{}
+	for i := 0; i < len(records); i++ {
+		var action map[string]map[string]interface{}
+		if err := json.Unmarshal([]byte(records[i].Message), &action); err != nil || len(action) != 1 {
+			return nil, response, fmt.Errorf("invalid bulk action %q", records[i].Message)
+		}
+		for name, meta := range action {
+			metadata := map[string]string{"action": name}
+			for key, value := range meta {
+				metadata[strings.TrimPrefix(key, "_")] = stringValue(value)
+			}
+			response.Items = append(response.Items, map[string]map[string]string{name: {"_index": metadata["index"], "result": "created", "status": "201"}})
+			if name == "delete" {
+				continue
+			}
+			if i++; i >= len(records) {
+				return nil, response, fmt.Errorf("missing the document of bulk action %q", name)
+			}
+			documents = append(documents, Record{Metadata: metadata, Message: records[i].Message})
+		}
+	}
+	return documents, response, nil
+}
+
+// lokiPushRequest is the JSON push request of Loki
+type lokiPushRequest struct {
+	Streams []struct {
+		Stream map[string]string   `json:"stream"`
+		Values [][]json.RawMessage `json:"values"`
+	} `json:"streams"`
+}
+
+// decodeLokiPush returns the records of the Loki push request, protobuf requests are snappy compressed
+func decodeLokiPush(body []byte, contentType string) ([]Record, error) {
+	if isProtobuf(contentType) {
+		decoded, err := snappy.Decode(nil, body)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing the push request: %v", err)
+		}
+		return decodeLokiPushProto(decoded)
+	}
+	var request lokiPushRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("error decoding the push request: %v", err)
+	}
+	records := []Record{}
+	for _, stream := range request.Streams {
+		for _, value := range stream.Values {
+			if len(value) < 2 {
+				return nil, fmt.Errorf("invalid entry of stream %v", stream.Stream)
+			}
+			metadata := map[string]string{"timestamp": rawString(value[0])}
+			for key, label := range stream.Stream {
+				metadata[key] = label
+			}
+			// the third value is the structured metadata
+			if len(value) > 2 {
+				var structured map[string]string
+				if err := json.Unmarshal(value[2], &structured); err == nil {
+					for key, label := range structured {
+						metadata[key] = label
+					}
+				}
+			}
+			records = append(records, Record{Metadata: metadata, Message: rawString(value[1])})
+		}
+	}
+	return records, nil
+}
+
+// decodeLokiPushProto decodes the logproto.PushRequest:
+// PushRequest{repeated Stream streams = 1}, Stream{string labels = 1; repeated Entry entries = 2},
+// Entry{Timestamp timestamp = 1; string line = 2; repeated LabelPair structuredMetadata = 3},
+// Timestamp{int64 seconds = 1; int32 nanos = 2}, LabelPair{string name = 1; string value = 2}
+func decodeLokiPushProto(b []byte) ([]Record, error) {
+	records := []Record{}
+	err := protoFields(b, func(num protowire.Number, stream []byte) error {
+		if num != 1 {
+			return nil
+		}
+		labels := map[string]string{}
+		return protoFields(stream, func(num protowire.Number, value []byte) error {
+			switch num {
+			case 1:
+				var err error
+				if labels, err = parseLabels(string(value)); err != nil {
+					return err
+				}
+			case 2:
+				metadata := map[string]string{}
+				for key, label := range labels {
+					metadata[key] = label
+				}
+				var line string
+				err := protoFields(value, func(num protowire.Number, value []byte) error {
+					switch num {
+					case 1:
+						var seconds, nanos uint64
+						err := protoVarints(value, func(num protowire.Number, v uint64) {
+							if num == 1 {
+								seconds = v
+							} else if num == 2 {
+								nanos = v
+							}
+						})
+						metadata["timestamp"] = strconv.FormatInt(int64(seconds)*1e9+int64(nanos), 10)
+						return err
+					case 2:
+						line = string(value)
+					case 3:
+						var name, labelValue string
+						err := protoFields(value, func(num protowire.Number, value []byte) error {
+							if num == 1 {
+								name = string(value)
+							} else if num == 2 {
+								labelValue = string(value)
+							}
+							return nil
+						})
+						metadata[name] = labelValue
+						return err
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				records = append(records, Record{Metadata: metadata, Message: line})
+			}
+			return nil
+		})
+	})
+	return records, err
+}
+
+// protoFields calls the handler with the length delimited fields of the message, the other fields are skipped
+func protoFields(b []byte, handler func(protowire.Number, []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			if err := handler(num, value); err != nil {
+				return err
+			}
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// protoVarints calls the handler with the varint fields of the message, the other fields are skipped
+func protoVarints(b []byte, handler func(protowire.Number, uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			handler(num, v)
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// parseLabels parses the labels of a Loki stream, i.e. {app="foo", namespace="bar"}
+func parseLabels(s string) (map[string]string, error) {
+	labels := map[string]string{}
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, fmt.Errorf("invalid labels %q", s)
+	}
+	s = s[1 : len(s)-1]
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(s, ", ") {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid labels %q", s)
+		}
+		value, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of label %s: %v", name, err)
+		}
+		labels[strings.TrimSpace(name)], _ = strconv.Unquote(value)
+		s = rest[len(value):]
+	}
+	return labels, nil
+}
+
+// decodeOTLPLogs returns the records of the OTLP logs export request, the messages are the bodies of the log records
+func decodeOTLPLogs(body []byte, contentType string) ([]Record, error) {
+	request := &collogspb.ExportLogsServiceRequest{}
+	var err error
+	if isProtobuf(contentType) {
+		err = proto.Unmarshal(body, request)
+	} else {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, request)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding the logs export request: %v", err)
+	}
+	records := []Record{}
+	for _, resourceLogs := range request.GetResourceLogs() {
+		resource := map[string]string{}
+		addAttributes(resource, "resource.", resourceLogs.GetResource().GetAttributes())
+		for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+			for _, log := range scopeLogs.GetLogRecords() {
+				metadata := map[string]string{}
+				for key, value := range resource {
+					metadata[key] = value
+				}
+				if scope := scopeLogs.GetScope().GetName(); scope != "" {
+					metadata["scope"] = scope
+				}
+				if log.GetSeverityText() != "" {
+					metadata["severityText"] = log.GetSeverityText()
+				}
+				if log.GetTimeUnixNano() > 0 {
+					metadata["timeUnixNano"] = strconv.FormatUint(log.GetTimeUnixNano(), 10)
+				}
+				addAttributes(metadata, "", log.GetAttributes())
+				records = append(records, Record{Metadata: metadata, Message: anyValueString(log.GetBody())})
+			}
+		}
+	}
+	return records, nil
+}
+
+func addAttributes(metadata map[string]string, prefix string, attributes []*commonpb.KeyValue) {
+	for _, attribute := range attributes {
+		metadata[prefix+attribute.GetKey()] = anyValueString(attribute.GetValue())
+	}
+}
+
+// anyValueString returns the string of an OTLP value, the arrays and maps are encoded in JSON
+func anyValueString(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case nil:
+		return ""
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return string(v.BytesValue)
+	default:
+		out, _ := json.Marshal(anyValueInterface(value))
+		return string(out)
+	}
+}
+
+func anyValueInterface(value *commonpb.AnyValue) interface{} {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_ArrayValue:
+		items := []interface{}{}
+		for _, item := range v.ArrayValue.GetValues() {
+			items = append(items, anyValueInterface(item))
+		}
+		return items
+	case *commonpb.AnyValue_KvlistValue:
+		items := map[string]interface{}{}
+		for _, item := range v.KvlistValue.GetValues() {
+			items[item.GetKey()] = anyValueInterface(item.GetValue())
+		}
+		return items
+	default:
+		return anyValueString(value)
+	}
+}
@@ -0,0 +1,365 @@
+package logreceiver
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestParseSyslog(t *testing.T) {
+	for _, tc := range []struct {
+		line     string
+		expected SyslogMessage
+	}{
+		{
+			line: `<14>1 2024-05-10T08:00:00.123Z worker-0 vector 1234 ID47 [exampleSDID@32473 iut="3" eventSource="App\]lication"] {"message":"hello"}`,
+			expected: SyslogMessage{Format: SyslogRFC5424, Facility: 1, Severity: 6, Timestamp: "2024-05-10T08:00:00.123Z", Hostname: "worker-0", AppName: "vector", ProcID: "1234",
+				MsgID: "ID47", StructuredData: `[exampleSDID@32473 iut="3" eventSource="App\]lication"]`, Message: `{"message":"hello"}`},
+		},
+		{
+			line:     "<165>1 2024-05-10T08:00:00Z - - - - - \ufeffno header",
+			expected: SyslogMessage{Format: SyslogRFC5424, Facility: 20, Severity: 5, Timestamp: "2024-05-10T08:00:00Z", Message: "no header"},
+		},
+		{
+			line:     `<13>May 10 08:00:00 worker-0 app[42]: {"message":"hello"}`,
+			expected: SyslogMessage{Format: SyslogRFC3164, Facility: 1, Severity: 5, Timestamp: "May 10 08:00:00", Hostname: "worker-0", AppName: "app", ProcID: "42", Message: `{"message":"hello"}`},
+		},
+		{
+			line:     `<13>2024-05-10T08:00:00+00:00 worker-0 app: plain`,
+			expected: SyslogMessage{Format: SyslogRFC3164, Facility: 1, Severity: 5, Timestamp: "2024-05-10T08:00:00+00:00", Hostname: "worker-0", AppName: "app", Message: "plain"},
+		},
+	} {
+		m, err := ParseSyslog(tc.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*m, tc.expected) {
+			t.Errorf("%s:\nexpected %+v\ngot      %+v", tc.line, tc.expected, *m)
+		}
+	}
+	for _, line := range []string{"no priority", "<999>1 - - - - - -", "<14>1 2024-05-10T08:00:00Z host app - - [unterminated"} {
+		if _, err := ParseSyslog(line); err == nil {
+			t.Errorf("Expected an error parsing %q", line)
+		}
+	}
+}
+
+func TestServeSyslog(t *testing.T) {
+	server, err := NewServer(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go server.ServeSyslog(ln, ListenerSyslogTCP)
+	packets, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer packets.Close()
+	go server.ServeSyslogPackets(packets, ListenerSyslogUDP)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// octet counting and new line framing on the same connection
+	first := "<14>1 - host app - - - octet counted"
+	fmt.Fprintf(conn, "%d %s<14>1 - host app - - - new line\n", len(first), first)
+	conn.Close()
+	udp, err := net.Dial("udp", packets.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	fmt.Fprint(udp, "<14>May 10 08:00:00 host app: datagram")
+
+	client := NewClient(HTTPTransport{Address: httptest.NewServer(server.APIHandler()).URL})
+	client.pollInterval = 10 * time.Millisecond
+	records, err := client.WaitForRecords(Query{Protocol: ProtocolSyslog}, 3, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := map[string]string{}
+	for _, r := range records {
+		messages[r.Syslog.Message] = r.Listener
+	}
+	if !reflect.DeepEqual(messages, map[string]string{"octet counted": ListenerSyslogTCP, "new line": ListenerSyslogTCP, "datagram": ListenerSyslogUDP}) {
+		t.Errorf("Unexpected records %v", messages)
+	}
+}
+
+func post(t *testing.T, url, path, contentType string, body []byte, header http.Header) int {
+	req, err := http.NewRequest(http.MethodPost, url+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// lokiPushProto encodes a push request of one stream with the entry lines
+func lokiPushProto(labels string, lines ...string) []byte {
+	var stream []byte
+	stream = protowire.AppendTag(stream, 1, protowire.BytesType)
+	stream = protowire.AppendString(stream, labels)
+	for i, line := range lines {
+		var ts, entry []byte
+		ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, 1700000000)
+		ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(i))
+		entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, ts)
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendString(entry, line)
+		stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+		stream = protowire.AppendBytes(stream, entry)
+	}
+	var request []byte
+	request = protowire.AppendTag(request, 1, protowire.BytesType)
+	request = protowire.AppendBytes(request, stream)
+	return snappy.Encode(nil, request)
+}
+
+func TestIngestHandler(t *testing.T) {
+	server, err := NewServer(Config{SplunkToken: "hec-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingest := httptest.NewServer(server.IngestHandler(ListenerHTTP))
+	defer ingest.Close()
+	client := NewClient(HTTPTransport{Address: httptest.NewServer(server.APIHandler()).URL})
+
+	for _, tc := range []struct {
+		path        string
+		contentType string
+		body        []byte
+		header      http.Header
+		status      int
+		protocol    string
+		messages    []string
+		metadata    map[string]string
+	}{
+		{
+			path: "/logs", contentType: "application/json", body: []byte(`[{"message":"a"},{"message":"b"}]`), header: http.Header{"Authorization": {"Bearer token"}},
+			status: http.StatusOK, protocol: ProtocolHTTP, messages: []string{`{"message":"a"}`, `{"message":"b"}`},
+		},
+		{
+			path: "/", contentType: "application/x-ndjson", body: []byte("{\"message\":\"c\"}\n{\"message\":\"d\"}\n"),
+			status: http.StatusOK, protocol: ProtocolHTTP, messages: []string{`{"message":"c"}`, `{"message":"d"}`},
+		},
+		{
+			path: SplunkEventPath, contentType: "application/json", body: []byte(`{"event":{"message":"e"},"sourcetype":"_json","index":"main","fields":{"log_type":"application"}}{"event":"f"}`),
+			header: http.Header{"Authorization": {"Splunk hec-token"}}, status: http.StatusOK, protocol: ProtocolSplunk, messages: []string{`{"message":"e"}`, "f"},
+			metadata: map[string]string{"sourcetype": "_json", "index": "main", "fields.log_type": "application"},
+		},
+		{
+			path: SplunkEventPath, contentType: "application/json", body: []byte(`{"event":"g"}`), header: http.Header{"Authorization": {"Splunk wrong"}}, status: http.StatusForbidden,
+		},
+		{
+			path: ElasticsearchBulk, contentType: "application/x-ndjson", body: []byte("{\"create\":{\"_index\":\"app-write\"}}\n{\"message\":\"h\"}\n{\"index\":{}}\n{\"message\":\"i\"}\n"),
+			status: http.StatusOK, protocol: ProtocolElasticsearch, messages: []string{`{"message":"h"}`, `{"message":"i"}`}, metadata: map[string]string{"action": "create", "index": "app-write"},
+		},
+		{
+			path: LokiPushPath, contentType: "application/json", body: []byte(`{"streams":[{"stream":{"log_type":"application"},"values":[["1700000000000000000","j"]]}]}`),
+			status: http.StatusNoContent, protocol: ProtocolLoki, messages: []string{"j"}, metadata: map[string]string{"log_type": "application", "timestamp": "1700000000000000000"},
+		},
+		{
+			path: LokiPushPath, contentType: "application/x-protobuf", body: lokiPushProto(`{log_type="audit", kubernetes_host="worker-0"}`, "k", "l"),
+			status: http.StatusNoContent, protocol: ProtocolLoki, messages: []string{"k", "l"}, metadata: map[string]string{"log_type": "audit", "kubernetes_host": "worker-0", "timestamp": "1700000000000000000"},
+		},
+		{
+			path: OTLPLogsPath, contentType: "application/json",
+			body: []byte(`{"resourceLogs":[{"resource":{"attributes":[{"key":"k8s.namespace.name","value":{"stringValue":"test"}}]},"scopeLogs":[{"logRecords":[` +
+				`{"timeUnixNano":"1700000000000000000","severityText":"info","body":{"stringValue":"m"},"attributes":[{"key":"log_type","value":{"stringValue":"application"}}]}]}]}]}`),
+			status: http.StatusOK, protocol: ProtocolOTLP, messages: []string{"m"},
+			metadata: map[string]string{"resource.k8s.namespace.name": "test", "severityText": "info", "timeUnixNano": "1700000000000000000", "log_type": "application"},
+		},
+		{
+			path: ElasticsearchBulk, contentType: "application/x-ndjson", body: []byte("{\"index\":{}}\n"), status: http.StatusBadRequest,
+		},
+	} {
+		last, err := client.LastID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := post(t, ingest.URL, tc.path, tc.contentType, tc.body, tc.header); status != tc.status {
+			t.Errorf("POST %s: expected status %d, got %d", tc.path, tc.status, status)
+			continue
+		}
+		records, err := client.Records(Query{After: last})
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages := []string{}
+		for _, r := range records {
+			messages = append(messages, r.Message)
+			if r.Protocol != tc.protocol || r.Listener != ListenerHTTP || r.Path != tc.path {
+				t.Errorf("POST %s: unexpected record %+v", tc.path, r)
+			}
+		}
+		// the metadata is the one of the first record
+		for key, value := range tc.metadata {
+			if records[0].Metadata[key] != value {
+				t.Errorf("POST %s: expected metadata %s=%s, got %v", tc.path, key, value, records[0].Metadata)
+				break
+			}
+		}
+		if len(tc.messages) == 0 {
+			tc.messages = []string{}
+		}
+		if !reflect.DeepEqual(messages, tc.messages) {
+			t.Errorf("POST %s: expected messages %v, got %v", tc.path, tc.messages, messages)
+		}
+		if len(records) > 0 && tc.header != nil && records[0].Headers["Authorization"][0] != tc.header.Get("Authorization") {
+			t.Errorf("POST %s: unexpected headers %v", tc.path, records[0].Headers)
+		}
+	}
+
+	if records, err := client.Records(Query{Protocol: ProtocolHTTP, Contains: `"c"`}); err != nil || len(records) != 1 {
+		t.Errorf("Unexpected records %v: %v", records, err)
+	}
+	if err := client.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if records, err := client.Records(Query{}); err != nil || len(records) != 0 {
+		t.Errorf("Expected no records after reset, got %v: %v", records, err)
+	}
+}
+
+// writeCertificates writes the CA, the server certificate and a client certificate signed by the CA
+func writeCertificates(t *testing.T, dir string) tls.Certificate {
+	newCert := func(template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+	notAfter := time.Now().Add(time.Hour)
+	ca, caKey, caPEM, _ := newCert(&x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test-ca"}, NotAfter: notAfter, IsCA: true,
+		BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil, nil)
+	_, _, serverPEM, serverKeyPEM := newCert(&x509.Certificate{SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "log-receiver"}, NotAfter: notAfter,
+		DNSNames: []string{"log-receiver"}, IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, ca, caKey)
+	_, _, clientPEM, clientKeyPEM := newCert(&x509.Certificate{SerialNumber: big.NewInt(3), Subject: pkix.Name{CommonName: "collector"}, NotAfter: notAfter,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}, ca, caKey)
+	for name, content := range map[string][]byte{"ca.crt": caPEM, "tls.crt": serverPEM, "tls.key": serverKeyPEM} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientCert
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert := writeCertificates(t, dir)
+	server, err := NewServer(Config{HTTPSAddr: "unused", TLSDir: dir, ClientAuth: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingest := httptest.NewUnstartedServer(server.IngestHandler(ListenerHTTPS))
+	ingest.TLS = server.tlsConfig
+	ingest.StartTLS()
+	defer ingest.Close()
+
+	ca, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca)
+	send := func(certs []tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs: roots, Certificates: certs, ServerName: "log-receiver", MaxVersion: tls.VersionTLS12,
+			CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		}}}
+		resp, err := client.Post(ingest.URL+"/logs", "application/json", bytes.NewReader([]byte(`{"message":"tls"}`)))
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	if err := send(nil); err == nil {
+		t.Errorf("Expected the client without certificate to be rejected")
+	}
+	if err := send([]tls.Certificate{clientCert}); err != nil {
+		t.Fatal(err)
+	}
+
+	records := server.Store.List(Query{Listener: ListenerHTTPS})
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %v", records)
+	}
+	expected := &TLSInfo{Version: "TLS 1.2", CipherSuite: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", ServerName: "log-receiver", ClientCertificates: []string{"CN=collector"}}
+	if !reflect.DeepEqual(records[0].TLS, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, records[0].TLS)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(2)
+	store.Add(Record{Message: "a"}, Record{Message: "b"}, Record{Message: "c"})
+	records := store.List(Query{})
+	if len(records) != 2 || records[0].Message != "b" || records[1].ID != 3 {
+		t.Errorf("Unexpected records %v", records)
+	}
+	store.Reset()
+	store.Add(Record{Message: "d"})
+	if records := store.List(Query{After: 3}); len(records) != 1 || records[0].ID != 4 {
+		t.Errorf("Unexpected records %v", records)
+	}
+	q, err := ParseQuery(Query{Protocol: ProtocolLoki, Contains: "x", After: 7}.Values())
+	if err != nil || !reflect.DeepEqual(q, Query{Protocol: ProtocolLoki, Contains: "x", After: 7}) {
+		t.Errorf("Unexpected query %v: %v", q, err)
+	}
+}
@@ -0,0 +1,146 @@
+package logreceiver
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Protocols of the received records
+const (
+	ProtocolHTTP          = "http"
+	ProtocolSyslog        = "syslog"
+	ProtocolSplunk        = "splunk"
+	ProtocolElasticsearch = "elasticsearch"
+	ProtocolLoki          = "loki"
+	ProtocolOTLP          = "otlp"
+)
+
+// TLSInfo is the TLS connection a record was received on
+type TLSInfo struct {
+	Version     string `json:"version"`
+	CipherSuite string `json:"cipherSuite"`
+	ServerName  string `json:"serverName,omitempty"`
+	// ClientCertificates are the subjects of the verified client certificate chain
+	ClientCertificates []string `json:"clientCertificates,omitempty"`
+}
+
+// Record is a log record as received by the receiver
+type Record struct {
+	ID       int64     `json:"id"`
+	Received time.Time `json:"received"`
+	Protocol string    `json:"protocol"`
+	// Listener is the name of the listener the record was received on, i.e. http, https, syslog-udp or syslog-tls
+	Listener string   `json:"listener"`
+	Remote   string   `json:"remote"`
+	TLS      *TLSInfo `json:"tls,omitempty"`
+	// Path and Headers are the request of the HTTP based protocols
+	Path    string              `json:"path,omitempty"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	// Metadata is the protocol specific metadata of the record, i.e. the labels of a Loki stream, the index of an
+	// Elasticsearch document, the source type of a Splunk event or the attributes of an OTLP log record
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Syslog is the parsed syslog message
+	Syslog *SyslogMessage `json:"syslog,omitempty"`
+	// Message is the forwarded payload, i.e. the JSON document of the log record or the syslog message
+	Message string `json:"message"`
+}
+
+// Query selects the records, the empty fields match all the records
+type Query struct {
+	Protocol string
+	Listener string
+	// Contains matches the records whose message contains the string
+	Contains string
+	// After matches the records received after the record with the ID
+	After int64
+}
+
+// Values returns the URL parameters of the query
+func (q Query) Values() url.Values {
+	values := url.Values{}
+	if q.Protocol != "" {
+		values.Set("protocol", q.Protocol)
+	}
+	if q.Listener != "" {
+		values.Set("listener", q.Listener)
+	}
+	if q.Contains != "" {
+		values.Set("contains", q.Contains)
+	}
+	if q.After > 0 {
+		values.Set("after", strconv.FormatInt(q.After, 10))
+	}
+	return values
+}
+
+// ParseQuery returns the query of the URL parameters
+func ParseQuery(values url.Values) (Query, error) {
+	q := Query{Protocol: values.Get("protocol"), Listener: values.Get("listener"), Contains: values.Get("contains")}
+	if after := values.Get("after"); after != "" {
+		var err error
+		if q.After, err = strconv.ParseInt(after, 10, 64); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+// Match returns whether the query selects the record
+func (q Query) Match(r Record) bool {
+	return (q.Protocol == "" || r.Protocol == q.Protocol) &&
+		(q.Listener == "" || r.Listener == q.Listener) &&
+		(q.Contains == "" || strings.Contains(r.Message, q.Contains)) &&
+		r.ID > q.After
+}
+
+// Store keeps the last received records in memory
+type Store struct {
+	mu      sync.Mutex
+	records []Record
+	lastID  int64
+	max     int
+}
+
+// NewStore returns a store keeping at most max records
+func NewStore(max int) *Store {
+	return &Store{max: max}
+}
+
+// Add stores the records, the IDs and the receive time are set by the store
+func (s *Store) Add(records ...Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, r := range records {
+		s.lastID++
+		r.ID = s.lastID
+		r.Received = now
+		s.records = append(s.records, r)
+	}
+	if s.max > 0 && len(s.records) > s.max {
+		s.records = append([]Record(nil), s.records[len(s.records)-s.max:]...)
+	}
+}
+
+// List returns the records selected by the query in the receive order
+func (s *Store) List(q Query) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := []Record{}
+	for _, r := range s.records {
+		if q.Match(r) {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Reset removes all the records, the IDs keep increasing so the queries after a record stay valid
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = nil
+}
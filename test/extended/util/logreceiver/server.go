@@ -0,0 +1,388 @@
+package logreceiver
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Names of the listeners
+const (
+	ListenerHTTP      = "http"
+	ListenerHTTPS     = "https"
+	ListenerSyslogTCP = "syslog-tcp"
+	ListenerSyslogUDP = "syslog-udp"
+	ListenerSyslogTLS = "syslog-tls"
+)
+
+// Paths of the HTTP based protocols, the other paths receive generic HTTP records
+const (
+	SplunkEventPath      = "/services/collector/event"
+	SplunkRawPath        = "/services/collector/raw"
+	SplunkHealthPath     = "/services/collector/health"
+	ElasticsearchBulk    = "/_bulk"
+	LokiPushPath         = "/loki/api/v1/push"
+	OTLPLogsPath         = "/v1/logs"
+	RecordsPath          = "/records"
+	HealthPath           = "/healthz"
+	maxRequestBodyLength = 64 * 1024 * 1024
+)
+
+// Config is the configuration of the receiver, the listeners with an empty address are not started
+type Config struct {
+	APIAddr       string
+	HTTPAddr      string
+	HTTPSAddr     string
+	SyslogTCPAddr string
+	SyslogUDPAddr string
+	SyslogTLSAddr string
+	// TLSDir contains tls.crt and tls.key of the TLS listeners, and ca.crt to verify the client certificates
+	TLSDir string
+	// ClientAuth requires the clients of the TLS listeners to present a certificate signed by ca.crt
+	ClientAuth bool
+	// SplunkToken is the HEC token required by the Splunk endpoints if it is not empty
+	SplunkToken string
+	// MaxRecords is the number of records kept in memory
+	MaxRecords int
+}
+
+// Server receives the logs of all the protocols into its store
+type Server struct {
+	config    Config
+	Store     *Store
+	tlsConfig *tls.Config
+}
+
+// NewServer returns the receiver of the configuration, the TLS files are loaded when there is a TLS listener
+func NewServer(config Config) (*Server, error) {
+	if config.MaxRecords == 0 {
+		config.MaxRecords = 100000
+	}
+	s := &Server{config: config, Store: NewStore(config.MaxRecords)}
+	if config.HTTPSAddr != "" || config.SyslogTLSAddr != "" {
+		tlsConfig, err := LoadTLSConfig(config.TLSDir, config.ClientAuth)
+		if err != nil {
+			return nil, err
+		}
+		s.tlsConfig = tlsConfig
+	}
+	return s, nil
+}
+
+// LoadTLSConfig returns the server TLS config of the directory. All the TLS versions and cipher suites are enabled so
+// the tests assert the parameters negotiated by the client.
+func LoadTLSConfig(dir string, clientAuth bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+	if err != nil {
+		return nil, fmt.Errorf("error loading the server certificate: %v", err)
+	}
+	var suites []uint16
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites = append(suites, suite.ID)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS10,
+		CipherSuites: suites,
+	}
+	ca, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil && clientAuth {
+		return nil, fmt.Errorf("error loading the client CA: %v", err)
+	}
+	if err == nil {
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates in %s", filepath.Join(dir, "ca.crt"))
+		}
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if clientAuth {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return config, nil
+}
+
+// tlsInfo returns the parameters of the TLS connection
+func tlsInfo(state *tls.ConnectionState) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+	info := &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	for _, cert := range state.PeerCertificates {
+		info.ClientCertificates = append(info.ClientCertificates, cert.Subject.String())
+	}
+	return info
+}
+
+// Run starts the listeners of the configuration and serves them until the context is done or a listener fails
+func (s *Server) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 6)
+	serve := func(name, addr string, start func(net.Listener) error) {
+		if addr == "" {
+			return
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			errs <- fmt.Errorf("error listening %s on %s: %v", name, addr, err)
+			return
+		}
+		go func() {
+			<-ctx.Done()
+			ln.Close()
+		}()
+		log.Printf("serving %s on %s", name, addr)
+		go func() { errs <- start(ln) }()
+	}
+	serveHTTP := func(handler http.Handler) func(net.Listener) error {
+		return func(ln net.Listener) error {
+			return (&http.Server{Handler: handler, ReadHeaderTimeout: time.Minute}).Serve(ln)
+		}
+	}
+
+	serve("api", s.config.APIAddr, serveHTTP(s.APIHandler()))
+	serve(ListenerHTTP, s.config.HTTPAddr, serveHTTP(s.IngestHandler(ListenerHTTP)))
+	serve(ListenerHTTPS, s.config.HTTPSAddr, func(ln net.Listener) error {
+		return serveHTTP(s.IngestHandler(ListenerHTTPS))(tls.NewListener(ln, s.tlsConfig))
+	})
+	serve(ListenerSyslogTCP, s.config.SyslogTCPAddr, func(ln net.Listener) error { return s.ServeSyslog(ln, ListenerSyslogTCP) })
+	serve(ListenerSyslogTLS, s.config.SyslogTLSAddr, func(ln net.Listener) error {
+		return s.ServeSyslog(tls.NewListener(ln, s.tlsConfig), ListenerSyslogTLS)
+	})
+	if s.config.SyslogUDPAddr != "" {
+		conn, err := net.ListenPacket("udp", s.config.SyslogUDPAddr)
+		if err != nil {
+			return fmt.Errorf("error listening %s on %s: %v", ListenerSyslogUDP, s.config.SyslogUDPAddr, err)
+		}
+		go func() {
+			<-ctx.Done()
+			conn.Close()
+		}()
+		log.Printf("serving %s on %s", ListenerSyslogUDP, s.config.SyslogUDPAddr)
+		go func() { errs <- s.ServeSyslogPackets(conn, ListenerSyslogUDP) }()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+}
+
+// syslogRecord returns the record of the syslog message, the unparsable messages are kept without the parsed fields
+func syslogRecord(listener, remote string, info *TLSInfo, line string) Record {
+	r := Record{Protocol: ProtocolSyslog, Listener: listener, Remote: remote, TLS: info, Message: line}
+	if m, err := ParseSyslog(line); err == nil {
+		r.Syslog = m
+	} else {
+		log.Printf("error parsing the syslog message from %s: %v", remote, err)
+	}
+	return r
+}
+
+// ServeSyslog receives the syslog messages of the stream connections, the TLS connections are handshaked first to
+// record their parameters
+func (s *Server) ServeSyslog(ln net.Listener, listener string) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			var info *TLSInfo
+			if tlsConn, ok := conn.(*tls.Conn); ok {
+				if err := tlsConn.Handshake(); err != nil {
+					log.Printf("TLS handshake error from %s: %v", conn.RemoteAddr(), err)
+					return
+				}
+				state := tlsConn.ConnectionState()
+				info = tlsInfo(&state)
+			}
+			err := readSyslogFrames(bufio.NewReader(conn), func(line string) {
+				s.Store.Add(syslogRecord(listener, conn.RemoteAddr().String(), info, line))
+			})
+			if err != nil {
+				log.Printf("error reading syslog messages from %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// ServeSyslogPackets receives the syslog messages of the datagrams, one message each
+func (s *Server) ServeSyslogPackets(conn net.PacketConn, listener string) error {
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		if line := strings.TrimRight(string(buf[:n]), "\r\n\x00"); line != "" {
+			s.Store.Add(syslogRecord(listener, addr.String(), nil, line))
+		}
+	}
+}
+
+// readBody returns the request body decompressed by its content encoding
+func readBody(r *http.Request) ([]byte, error) {
+	var reader io.Reader = http.MaxBytesReader(nil, r.Body, maxRequestBodyLength)
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case "zstd":
+		zr, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = zr
+	}
+	return io.ReadAll(reader)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// IngestHandler receives the records of the HTTP based protocols by path
+func (s *Server) IngestHandler(listener string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimSuffix(r.URL.Path, "/")
+		// the health checks of the collector sinks
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			switch path {
+			case SplunkHealthPath:
+				writeJSON(w, http.StatusOK, map[string]interface{}{"text": "HEC is healthy", "code": 17})
+			case "/_cluster/health":
+				writeJSON(w, http.StatusOK, map[string]interface{}{"status": "green"})
+			case "":
+				writeJSON(w, http.StatusOK, map[string]interface{}{"name": "log-receiver", "version": map[string]string{"number": "8.17.0", "distribution": "elasticsearch"}, "tagline": "You Know, for Search"})
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+			return
+		}
+
+		body, err := readBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		contentType := r.Header.Get("Content-Type")
+		var records []Record
+		protocol := ProtocolHTTP
+		var response interface{} = map[string]interface{}{}
+		switch {
+		case strings.HasPrefix(path, "/services/collector"):
+			protocol = ProtocolSplunk
+			if s.config.SplunkToken != "" && r.Header.Get("Authorization") != "Splunk "+s.config.SplunkToken {
+				writeJSON(w, http.StatusForbidden, map[string]interface{}{"text": "Invalid token", "code": 4})
+				return
+			}
+			if path == SplunkRawPath {
+				records, err = decodeLines(body)
+			} else {
+				records, err = decodeSplunkEvents(body)
+			}
+			response = map[string]interface{}{"text": "Success", "code": 0}
+		case strings.HasSuffix(path, ElasticsearchBulk):
+			protocol = ProtocolElasticsearch
+			var bulk bulkResponse
+			records, bulk, err = decodeBulk(body)
+			if index := strings.Trim(strings.TrimSuffix(path, ElasticsearchBulk), "/"); index != "" {
+				for i := range records {
+					if records[i].Metadata["index"] == "" {
+						records[i].Metadata["index"] = index
+					}
+				}
+			}
+			response = bulk
+		case path == LokiPushPath:
+			protocol = ProtocolLoki
+			records, err = decodeLokiPush(body, contentType)
+		case path == OTLPLogsPath:
+			protocol = ProtocolOTLP
+			records, err = decodeOTLPLogs(body, contentType)
+		default:
+			records, err = decodeLines(body)
+		}
+		if err != nil {
+			log.Printf("error decoding the %s request from %s: %v", protocol, r.RemoteAddr, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		info := tlsInfo(r.TLS)
+		for i := range records {
+			records[i].Protocol = protocol
+			records[i].Listener = listener
+			records[i].Remote = r.RemoteAddr
+			records[i].TLS = info
+			records[i].Path = r.URL.Path
+			records[i].Headers = r.Header
+		}
+		s.Store.Add(records...)
+
+		if protocol == ProtocolLoki {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+// APIHandler serves the records of the store, GET /records lists them by the query parameters and DELETE /records
+// removes them
+func (s *Server) APIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc(RecordsPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			q, err := ParseQuery(r.URL.Query())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, http.StatusOK, s.Store.List(q))
+		case http.MethodDelete:
+			s.Store.Reset()
+			writeJSON(w, http.StatusOK, map[string]interface{}{})
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return mux
+}
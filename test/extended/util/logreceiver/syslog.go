@@ -0,0 +1,191 @@
+package logreceiver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the syslog messages
+const (
+	SyslogRFC3164 = "rfc3164"
+	SyslogRFC5424 = "rfc5424"
+)
+
+// SyslogMessage is a syslog message parsed by its format, the nil values of RFC5424 are empty
+type SyslogMessage struct {
+	Format         string `json:"format"`
+	Facility       int    `json:"facility"`
+	Severity       int    `json:"severity"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	Hostname       string `json:"hostname,omitempty"`
+	AppName        string `json:"appName,omitempty"`
+	ProcID         string `json:"procID,omitempty"`
+	MsgID          string `json:"msgID,omitempty"`
+	StructuredData string `json:"structuredData,omitempty"`
+	Message        string `json:"message"`
+}
+
+// ParseSyslog parses a RFC5424 or RFC3164 message
+func ParseSyslog(line string) (*SyslogMessage, error) {
+	line = strings.TrimRight(line, "\r\n\x00")
+	if !strings.HasPrefix(line, "<") {
+		return nil, fmt.Errorf("missing the priority of syslog message %q", line)
+	}
+	end := strings.Index(line, ">")
+	if end < 2 || end > 4 {
+		return nil, fmt.Errorf("invalid priority of syslog message %q", line)
+	}
+	pri, err := strconv.Atoi(line[1:end])
+	if err != nil || pri > 191 {
+		return nil, fmt.Errorf("invalid priority of syslog message %q", line)
+	}
+	m := &SyslogMessage{Facility: pri / 8, Severity: pri % 8}
+	rest := line[end+1:]
+	if strings.HasPrefix(rest, "1 ") {
+		return m, m.parseRFC5424(rest[2:])
+	}
+	m.parseRFC3164(rest)
+	return m, nil
+}
+
+// nilValue returns the empty string for the RFC5424 nil value
+func nilValue(field string) string {
+	if field == "-" {
+		return ""
+	}
+	return field
+}
+
+// parseRFC5424 parses TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func (m *SyslogMessage) parseRFC5424(rest string) error {
+	m.Format = SyslogRFC5424
+	fields := strings.SplitN(rest, " ", 6)
+	if len(fields) < 6 {
+		return fmt.Errorf("invalid RFC5424 header %q", rest)
+	}
+	m.Timestamp = nilValue(fields[0])
+	m.Hostname = nilValue(fields[1])
+	m.AppName = nilValue(fields[2])
+	m.ProcID = nilValue(fields[3])
+	m.MsgID = nilValue(fields[4])
+
+	rest = fields[5]
+	if strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	} else {
+		n, err := structuredDataLength(rest)
+		if err != nil {
+			return err
+		}
+		m.StructuredData = rest[:n]
+		rest = rest[n:]
+	}
+	m.Message = strings.TrimPrefix(strings.TrimPrefix(rest, " "), "\ufeff")
+	return nil
+}
+
+// structuredDataLength returns the length of the structured data elements at the beginning of the string, the param
+// values are quoted and may contain escaped '"', '\' and ']'
+func structuredDataLength(s string) (int, error) {
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		quoted := false
+		for i++; i < len(s); i++ {
+			if quoted && s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				quoted = !quoted
+			} else if s[i] == ']' && !quoted {
+				break
+			}
+		}
+		if i >= len(s) {
+			return 0, fmt.Errorf("unterminated structured data %q", s)
+		}
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid structured data %q", s)
+	}
+	return i, nil
+}
+
+// parseRFC3164 parses TIMESTAMP HOSTNAME TAG[PID]: MSG, the senders use either the BSD timestamp or RFC3339
+func (m *SyslogMessage) parseRFC3164(rest string) {
+	m.Format = SyslogRFC3164
+	if len(rest) >= 15 {
+		if _, err := time.Parse(time.Stamp, rest[:15]); err == nil {
+			m.Timestamp = rest[:15]
+			rest = strings.TrimPrefix(rest[15:], " ")
+		}
+	}
+	if m.Timestamp == "" {
+		if field, after, ok := strings.Cut(rest, " "); ok {
+			if _, err := time.Parse(time.RFC3339Nano, field); err == nil {
+				m.Timestamp = field
+				rest = after
+			}
+		}
+	}
+	if m.Timestamp != "" {
+		if field, after, ok := strings.Cut(rest, " "); ok && !strings.HasSuffix(field, ":") {
+			m.Hostname = field
+			rest = after
+		}
+	}
+	if tag, msg, ok := strings.Cut(rest, ": "); ok && !strings.ContainsAny(tag, " {\"") {
+		if name, pid, ok := strings.Cut(tag, "["); ok && strings.HasSuffix(pid, "]") {
+			m.AppName, m.ProcID = name, strings.TrimSuffix(pid, "]")
+		} else {
+			m.AppName = tag
+		}
+		rest = msg
+	}
+	m.Message = rest
+}
+
+// readSyslogFrames calls the handler with the messages of the stream, framed by octet counting or by new lines
+func readSyslogFrames(r *bufio.Reader, handler func(string)) error {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		var frame string
+		if b[0] >= '1' && b[0] <= '9' {
+			count, err := r.ReadString(' ')
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(count))
+			if err != nil {
+				return fmt.Errorf("invalid octet count %q", count)
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return err
+			}
+			frame = string(buf)
+		} else {
+			frame, err = r.ReadString('\n')
+			if err != nil && (err != io.EOF || frame == "") {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+		if frame = strings.TrimRight(frame, "\r\n\x00"); frame != "" {
+			handler(frame)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package loki
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// Paths of the Loki HTTP API
+const (
+	// GatewayPath prefixes the API paths with the tenant when querying through the LokiStack gateway
+	GatewayPath     = "/api/logs/v1/"
+	QueryPath       = "/loki/api/v1/query"
+	QueryRangePath  = "/loki/api/v1/query_range"
+	LabelsPath      = "/loki/api/v1/labels"
+	LabelValuesPath = "/loki/api/v1/label/%s/values"
+	SeriesPath      = "/loki/api/v1/series"
+	TailPath        = "/loki/api/v1/tail"
+	RulesPath       = "/loki/api/v1/rules"
+)
+
+// Client queries Loki, directly or through the LokiStack gateway. The With* methods return a copy of the client so a
+// base client can be shared by the cases.
+type Client struct {
+	address         string // Server address
+	username        string // Username for HTTP basic auth
+	password        string // Password for HTTP basic auth
+	bearerToken     string // Token of the Authorization header
+	bearerTokenFile string // File of the token of the Authorization header, read on every request
+	orgID           string // X-Scope-OrgID header representing the tenant ID, useful when bypassing an auth gateway
+	queryTags       string // X-Query-Tags header
+	retries         int    // How many times to retry each request when getting an error response from Loki
+	retryInterval   time.Duration
+	pollInterval    time.Duration
+	quiet           bool // Suppress the request URLs in the logs
+	noProxy         bool // Do not use the proxy of the environment
+}
+
+// NewClient initializes a client with the server address, i.e. the route of the LokiStack gateway
+func NewClient(address string) *Client {
+	return &Client{
+		address:       address,
+		retries:       5,
+		retryInterval: 5 * time.Second,
+		pollInterval:  10 * time.Second,
+		quiet:         true,
+	}
+}
+
+// Address returns the server address
+func (c *Client) Address() string {
+	return c.address
+}
+
+// WithRetries sets how many times to retry each request
+func (c *Client) WithRetries(retries int) *Client {
+	nc := *c
+	nc.retries = retries
+	return &nc
+}
+
+// WithToken sets the bearer token used to authenticate
+func (c *Client) WithToken(bearerToken string) *Client {
+	nc := *c
+	nc.bearerToken = bearerToken
+	return &nc
+}
+
+// WithTokenFile sets the file of the bearer token used to authenticate, i.e. a projected service account token
+func (c *Client) WithTokenFile(bearerTokenFile string) *Client {
+	nc := *c
+	nc.bearerTokenFile = bearerTokenFile
+	return &nc
+}
+
+// WithBasicAuth sets the username and password used to authenticate
+func (c *Client) WithBasicAuth(username string, password string) *Client {
+	nc := *c
+	nc.username = username
+	nc.password = password
+	return &nc
+}
+
+// WithOrgID sets the X-Scope-OrgID header, used to query a tenant of Loki without the gateway
+func (c *Client) WithOrgID(orgID string) *Client {
+	nc := *c
+	nc.orgID = orgID
+	return &nc
+}
+
+// WithQueryTags sets the X-Query-Tags header
+func (c *Client) WithQueryTags(queryTags string) *Client {
+	nc := *c
+	nc.queryTags = queryTags
+	return &nc
+}
+
+// Verbose logs the URL of every request
+func (c *Client) Verbose(verbose bool) *Client {
+	nc := *c
+	nc.quiet = !verbose
+	return &nc
+}
+
+// WithoutProxy does not use the proxy of the environment, i.e. when Loki is port-forwarded to the test host.
+// The proxy is never used for localhost.
+func (c *Client) WithoutProxy() *Client {
+	nc := *c
+	nc.noProxy = true
+	return &nc
+}
+
+// Header returns the headers of the requests, at most one of basic auth, bearer token and bearer token file is allowed
+func (c *Client) Header() (http.Header, error) {
+	if (c.username != "" || c.password != "") && (c.bearerToken != "" || c.bearerTokenFile != "") {
+		return nil, fmt.Errorf("at most one of HTTP basic auth (username/password), bearer-token & bearer-token-file is allowed to be configured")
+	}
+	if c.bearerToken != "" && c.bearerTokenFile != "" {
+		return nil, fmt.Errorf("at most one of the options bearer-token & bearer-token-file is allowed to be configured")
+	}
+
+	h := make(http.Header)
+	h.Set("User-Agent", "loki-logcli")
+	if c.username != "" && c.password != "" {
+		h.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password)))
+	}
+	if c.orgID != "" {
+		h.Set("X-Scope-OrgID", c.orgID)
+	}
+	if c.queryTags != "" {
+		h.Set("X-Query-Tags", c.queryTags)
+	}
+	if c.bearerToken != "" {
+		h.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	if c.bearerTokenFile != "" {
+		b, err := os.ReadFile(c.bearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read authorization credentials file %s: %s", c.bearerTokenFile, err)
+		}
+		h.Set("Authorization", "Bearer "+strings.TrimSpace(string(b)))
+	}
+	return h, nil
+}
+
+// TenantPath returns the path of the API for the tenant, the tenant is only set when querying through the LokiStack
+// gateway, i.e. application, infrastructure, audit or network
+func TenantPath(tenant string, apiPath string) string {
+	if tenant == "" {
+		return apiPath
+	}
+	return GatewayPath + tenant + apiPath
+}
+
+// BuildURL concats a url `http://foo/bar` with a path `/buzz` and the query
+func BuildURL(address, p, query string) (*url.URL, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, p)
+	u.RawQuery = query
+	return u, nil
+}
+
+// proxy returns the proxy of the environment for the URL, nil if the proxy is not used
+func (c *Client) proxy(u *url.URL) (*url.URL, error) {
+	if c.noProxy {
+		return nil, nil
+	}
+	if host := u.Hostname(); host == "localhost" {
+		return nil, nil
+	} else if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil, nil
+	}
+	proxy := os.Getenv("http_proxy")
+	if proxy == "" {
+		proxy = os.Getenv("https_proxy")
+	}
+	if proxy == "" {
+		return nil, nil
+	}
+	return url.Parse(proxy)
+}
+
+func (c *Client) httpClient(u *url.URL) (*http.Client, error) {
+	proxy, err := c.proxy(u)
+	if err != nil {
+		return nil, err
+	}
+	tr := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	if proxy != nil {
+		tr.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: tr, Timeout: time.Minute}, nil
+}
+
+// Do sends a GET request to the path and returns the response body, the request is retried until it gets a 2xx response
+func (c *Client) Do(p string, params url.Values) ([]byte, error) {
+	u, err := BuildURL(c.address, p, params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if !c.quiet {
+		e2e.Logf("the URL is: %s", u.String())
+	}
+	h, err := c.Header()
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.httpClient(u)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(c.retryInterval)
+		}
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header = h.Clone()
+		resp, err := client.Do(req)
+		if err != nil {
+			e2e.Logf("error sending request %v, attempts remaining: %d", err, c.retries-attempt)
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			e2e.Logf("Error response from server: %s %s, attempts remaining: %d", resp.Status, strings.TrimSpace(string(body)), c.retries-attempt)
+			continue
+		}
+		if err != nil {
+			// some endpoints, i.e. the rules of the application tenant, close the connection before the end of the body
+			if len(body) > 0 {
+				e2e.Logf("got error %v when reading the response, but ignore it", err)
+				return body, nil
+			}
+			e2e.Logf("error reading the response %v, attempts remaining: %d", err, c.retries-attempt)
+			continue
+		}
+		return body, nil
+	}
+	return nil, fmt.Errorf("run out of attempts while querying the server")
+}
+
+// Get sends a GET request to the path of the tenant and decodes the JSON response into out
+func (c *Client) Get(tenant, apiPath string, params url.Values, out interface{}) error {
+	body, err := c.Do(TenantPath(tenant, apiPath), params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
+
+func direction(forward bool) string {
+	if forward {
+		return "FORWARD"
+	}
+	return "BACKWARD"
+}
+
+// QueryParams returns the parameters of the instant query at the time
+func QueryParams(query string, limit int, forward bool, t time.Time) url.Values {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("time", strconv.FormatInt(t.UnixNano(), 10))
+	params.Set("direction", direction(forward))
+	return params
+}
+
+// QueryRangeParams returns the parameters of the range query, start is inclusive and end is exclusive
+func QueryRangeParams(query string, limit int, start, end time.Time, forward bool) url.Values {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("direction", direction(forward))
+	return params
+}
+
+func timeRangeParams(start, end time.Time) url.Values {
+	params := url.Values{}
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	return params
+}
+
+// Query executes an instant LogQL query, i.e. a metric query
+// c.Query("application", "sum by(kubernetes_namespace_name)(count_over_time({kubernetes_namespace_name=\"test\"}[5m]))", 30, false, time.Now())
+func (c *Client) Query(tenant, query string, limit int, forward bool, t time.Time) (*QueryResponse, error) {
+	var r QueryResponse
+	if err := c.Get(tenant, QueryPath, QueryParams(query, limit, forward, t), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// QueryRange executes a LogQL query over the time range
+// c.QueryRange("application", "{kubernetes_namespace_name=\"test\"}", 100, time.Now().Add(-time.Hour), time.Now(), false)
+func (c *Client) QueryRange(tenant, query string, limit int, start, end time.Time, forward bool) (*QueryResponse, error) {
+	var r QueryResponse
+	if err := c.Get(tenant, QueryRangePath, QueryRangeParams(query, limit, start, end, forward), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Labels returns the label names of the time range
+func (c *Client) Labels(tenant string, start, end time.Time) ([]string, error) {
+	var r LabelResponse
+	if err := c.Get(tenant, LabelsPath, timeRangeParams(start, end), &r); err != nil {
+		return nil, err
+	}
+	return r.Data, nil
+}
+
+// LabelValues returns the values of the label in the time range
+func (c *Client) LabelValues(tenant, name string, start, end time.Time) ([]string, error) {
+	var r LabelResponse
+	if err := c.Get(tenant, fmt.Sprintf(LabelValuesPath, url.PathEscape(name)), timeRangeParams(start, end), &r); err != nil {
+		return nil, err
+	}
+	return r.Data, nil
+}
+
+// Series returns the label sets of the streams matching any of the selectors in the time range
+func (c *Client) Series(tenant string, selectors []string, start, end time.Time) ([]map[string]string, error) {
+	params := timeRangeParams(start, end)
+	for _, selector := range selectors {
+		params.Add("match[]", selector)
+	}
+	var r SeriesResponse
+	if err := c.Get(tenant, SeriesPath, params, &r); err != nil {
+		return nil, err
+	}
+	return r.Data, nil
+}
+
+// Rules returns the rules of the tenant in YAML, the namespace filters the rules of the application tenant
+func (c *Client) Rules(tenant, namespace string) ([]byte, error) {
+	params := url.Values{}
+	if namespace != "" {
+		params.Set("kubernetes_namespace_name", namespace)
+	}
+	return c.Do(TenantPath(tenant, RulesPath), params)
+}
+
+// WaitForLines waits for the query to return at least count log lines since the time, matching the pattern if it is not
+// empty. It returns the matching entries.
+func (c *Client) WaitForLines(tenant, query string, since time.Time, count int, pattern string, timeout time.Duration) ([]Entry, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+	limit := 5000
+	if count > limit {
+		limit = count
+	}
+	var matched []Entry
+	err := wait.PollUntilContextTimeout(context.Background(), c.pollInterval, timeout, true, func(context.Context) (bool, error) {
+		r, err := c.QueryRange(tenant, query, limit, since, time.Now(), true)
+		if err != nil {
+			e2e.Logf("got err when searching logs: %v, retrying...", err)
+			return false, nil
+		}
+		entries, err := r.Entries()
+		if err != nil {
+			return false, err
+		}
+		matched = matched[:0]
+		for _, entry := range entries {
+			if re == nil || re.MatchString(entry.Line) {
+				matched = append(matched, entry)
+			}
+		}
+		if len(matched) < count {
+			e2e.Logf("found %d of %d log lines matching %q by %s, retrying...", len(matched), count, pattern, query)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return matched, fmt.Errorf("found %d of %d log lines matching %q by %s: %v", len(matched), count, pattern, query, err)
+	}
+	return matched, nil
+}
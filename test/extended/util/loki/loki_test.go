@@ -0,0 +1,262 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeLoki serves the Loki API of a LokiStack gateway with the application tenant
+type fakeLoki struct {
+	mu       sync.Mutex
+	token    string
+	lines    []string
+	requests []*http.Request
+	failures int
+}
+
+func (f *fakeLoki) push(lines ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lines = append(f.lines, lines...)
+}
+
+func (f *fakeLoki) streams() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := [][]string{}
+	for i, line := range f.lines {
+		values = append(values, []string{fmt.Sprintf("%d", int64(1700000000000000000)+int64(i)), line})
+	}
+	return []map[string]interface{}{{"stream": map[string]string{"kubernetes_namespace_name": "test"}, "values": values}}
+}
+
+func (f *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	fail := f.failures > 0
+	if fail {
+		f.failures--
+	}
+	f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+f.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if fail {
+		http.Error(w, "too many outstanding requests", http.StatusTooManyRequests)
+		return
+	}
+	prefix := GatewayPath + "application"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var data interface{}
+	switch p := strings.TrimPrefix(r.URL.Path, prefix); p {
+	case QueryRangePath:
+		data = map[string]interface{}{"resultType": ResultTypeStreams, "result": f.streams()}
+	case QueryPath:
+		data = map[string]interface{}{"resultType": ResultTypeVector, "result": []interface{}{
+			map[string]interface{}{"metric": map[string]string{"kubernetes_namespace_name": "test"}, "value": []interface{}{1700000000.5, "3"}},
+		}}
+	case LabelsPath:
+		data = []string{"kubernetes_namespace_name", "log_type"}
+	case fmt.Sprintf(LabelValuesPath, "log_type"):
+		data = []string{"application"}
+	case SeriesPath:
+		if r.URL.Query()["match[]"][0] != `{log_type="application"}` {
+			http.Error(w, "unexpected matcher", http.StatusBadRequest)
+			return
+		}
+		data = []map[string]string{{"kubernetes_namespace_name": "test", "log_type": "application"}}
+	case TailPath:
+		f.tail(w, r)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "data": data})
+}
+
+func (f *fakeLoki) tail(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for _, stream := range f.streams() {
+		if err := conn.WriteJSON(map[string]interface{}{"streams": []interface{}{stream}}); err != nil {
+			return
+		}
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+func newFakeLoki(t *testing.T) (*fakeLoki, *Client) {
+	fake := &fakeLoki{token: "sha256~token"}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client := NewClient(server.URL).WithToken(fake.token).WithRetries(2)
+	client.retryInterval = time.Millisecond
+	client.pollInterval = 10 * time.Millisecond
+	return fake, client
+}
+
+func TestClientQuery(t *testing.T) {
+	fake, client := newFakeLoki(t)
+	fake.push(`{"message":"line 1"}`, `{"message":"line 2"}`)
+	fake.failures = 2
+
+	r, err := client.QueryRange("application", `{kubernetes_namespace_name="test"}`, 10, time.Now().Add(-time.Hour), time.Now(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streams, err := r.Streams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streams) != 1 || streams[0].Labels["kubernetes_namespace_name"] != "test" || len(streams[0].Entries) != 2 {
+		t.Fatalf("Unexpected streams %v", streams)
+	}
+	if entry := streams[0].Entries[1]; entry.Line != `{"message":"line 2"}` || entry.Timestamp.UnixNano() != 1700000000000000001 {
+		t.Errorf("Unexpected entry %v", entry)
+	}
+	if _, err := r.Matrix(); err == nil {
+		t.Errorf("Expected an error decoding streams as matrix")
+	}
+	if query := fake.requests[len(fake.requests)-1].URL.Query(); query.Get("direction") != "FORWARD" || query.Get("limit") != "10" {
+		t.Errorf("Unexpected query %v", query)
+	}
+
+	r, err = client.Query("application", `count_over_time({kubernetes_namespace_name="test"}[5m])`, 10, false, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector, err := r.Vector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vector) != 1 || vector[0].Point.Value != 3 || vector[0].Point.Timestamp.UnixMilli() != 1700000000500 {
+		t.Errorf("Unexpected vector %v", vector)
+	}
+
+	fake.failures = 3
+	if _, err := client.Query("application", "{}", 10, false, time.Now()); err == nil {
+		t.Errorf("Expected an error when running out of attempts")
+	}
+	if _, err := client.WithToken("").Query("application", "{}", 10, false, time.Now()); err == nil {
+		t.Errorf("Expected an error without authentication")
+	}
+}
+
+func TestClientLabels(t *testing.T) {
+	_, client := newFakeLoki(t)
+	start, end := time.Now().Add(-time.Hour), time.Now()
+
+	labels, err := client.Labels("application", start, end)
+	if err != nil || !reflect.DeepEqual(labels, []string{"kubernetes_namespace_name", "log_type"}) {
+		t.Errorf("Unexpected labels %v: %v", labels, err)
+	}
+	values, err := client.LabelValues("application", "log_type", start, end)
+	if err != nil || !reflect.DeepEqual(values, []string{"application"}) {
+		t.Errorf("Unexpected label values %v: %v", values, err)
+	}
+	series, err := client.Series("application", []string{`{log_type="application"}`}, start, end)
+	if err != nil || len(series) != 1 || series[0]["kubernetes_namespace_name"] != "test" {
+		t.Errorf("Unexpected series %v: %v", series, err)
+	}
+}
+
+func TestClientHeader(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		client   *Client
+		expected http.Header
+		err      bool
+	}{
+		{client: NewClient("").WithToken("token"), expected: http.Header{"Authorization": {"Bearer token"}}},
+		{client: NewClient("").WithTokenFile(tokenFile), expected: http.Header{"Authorization": {"Bearer file-token"}}},
+		{client: NewClient("").WithBasicAuth("user", "pass"), expected: http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}}},
+		{client: NewClient("").WithOrgID("application").WithQueryTags("source=e2e"), expected: http.Header{"X-Scope-Orgid": {"application"}, "X-Query-Tags": {"source=e2e"}}},
+		{client: NewClient("").WithToken("token").WithBasicAuth("user", "pass"), err: true},
+		{client: NewClient("").WithToken("token").WithTokenFile(tokenFile), err: true},
+	} {
+		h, err := tc.client.Header()
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected an error, got %v", h)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Del("User-Agent")
+		if !reflect.DeepEqual(h, tc.expected) {
+			t.Errorf("Expected %v, got %v", tc.expected, h)
+		}
+	}
+}
+
+func TestClientWaitForLines(t *testing.T) {
+	fake, client := newFakeLoki(t)
+	go func() {
+		for i := 0; i < 5; i++ {
+			time.Sleep(20 * time.Millisecond)
+			fake.push(fmt.Sprintf(`{"message":"seq %d"}`, i), `{"message":"noise"}`)
+		}
+	}()
+
+	entries, err := client.WaitForLines("application", `{kubernetes_namespace_name="test"}`, time.Now().Add(-time.Minute), 5, `seq \d`, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 || entries[0].Line != `{"message":"seq 0"}` {
+		t.Errorf("Unexpected entries %v", entries)
+	}
+	if _, err := client.WaitForLines("application", `{kubernetes_namespace_name="test"}`, time.Now().Add(-time.Minute), 1, "missing", 100*time.Millisecond); err == nil {
+		t.Errorf("Expected an error when the lines do not appear")
+	}
+}
+
+func TestClientTail(t *testing.T) {
+	fake, client := newFakeLoki(t)
+	fake.push("line 1", "line 2")
+
+	var lines []string
+	err := client.Tail(context.Background(), "application", `{kubernetes_namespace_name="test"}`, time.Now(), 100, func(r TailResponse) bool {
+		for _, stream := range r.Streams {
+			for _, entry := range stream.Entries {
+				lines = append(lines, entry.Line)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lines, []string{"line 1", "line 2"}) {
+		t.Errorf("Unexpected lines %v", lines)
+	}
+	if err := client.WithToken("").Tail(context.Background(), "application", "{}", time.Now(), 100, func(TailResponse) bool { return true }); err == nil {
+		t.Errorf("Expected an error without authentication")
+	}
+}
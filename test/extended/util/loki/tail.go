@@ -0,0 +1,80 @@
+package loki
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Tail streams the log lines of the query from the start time over WebSocket, the handler is called for every message
+// until it returns false, the context is done or the connection is closed
+func (c *Client) Tail(ctx context.Context, tenant, query string, start time.Time, limit int, handler func(TailResponse) bool) error {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	u, err := BuildURL(c.address, TenantPath(tenant, TailPath), params.Encode())
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	h, err := c.Header()
+	if err != nil {
+		return err
+	}
+	proxy, err := c.proxy(u)
+	if err != nil {
+		return err
+	}
+	dialer := websocket.Dialer{
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: true},
+		HandshakeTimeout: time.Minute,
+	}
+	if proxy != nil {
+		dialer.Proxy = http.ProxyURL(proxy)
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, u.String(), h)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("error connecting to %s: %s: %v", TailPath, resp.Status, err)
+		}
+		return fmt.Errorf("error connecting to %s: %v", TailPath, err)
+	}
+	defer conn.Close()
+
+	// unblock the reader when the context is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var r TailResponse
+		if err := conn.ReadJSON(&r); err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return fmt.Errorf("error reading from %s: %v", TailPath, err)
+		}
+		if !handler(r) {
+			return nil
+		}
+	}
+}
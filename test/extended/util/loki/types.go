@@ -0,0 +1,160 @@
+package loki
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Result types of the query responses
+const (
+	ResultTypeStreams = "streams"
+	ResultTypeMatrix  = "matrix"
+	ResultTypeVector  = "vector"
+)
+
+// QueryResponse is the response of the query APIs, the result is decoded by its type with Streams, Matrix or Vector
+type QueryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+		Stats      json.RawMessage `json:"stats,omitempty"`
+	} `json:"data"`
+}
+
+// Entry is a log line of a stream
+type Entry struct {
+	Timestamp time.Time
+	Line      string
+}
+
+// UnmarshalJSON decodes the entry from ["<unix epoch in nanoseconds>", "<log line>"]
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	var value []string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if len(value) != 2 {
+		return fmt.Errorf("unexpected log entry %s", data)
+	}
+	ns, err := strconv.ParseInt(value[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected timestamp of log entry %s: %v", data, err)
+	}
+	e.Timestamp = time.Unix(0, ns)
+	e.Line = value[1]
+	return nil
+}
+
+// Stream is the log lines with the same labels
+type Stream struct {
+	Labels  map[string]string `json:"stream"`
+	Entries []Entry           `json:"values"`
+}
+
+// Point is a sample of a metric
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// UnmarshalJSON decodes the point from [<unix epoch in seconds>, "<value>"]
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var value []interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if len(value) != 2 {
+		return fmt.Errorf("unexpected sample %s", data)
+	}
+	ts, ok := value[0].(float64)
+	if !ok {
+		return fmt.Errorf("unexpected timestamp of sample %s", data)
+	}
+	s, ok := value[1].(string)
+	if !ok {
+		return fmt.Errorf("unexpected value of sample %s", data)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected value of sample %s: %v", data, err)
+	}
+	p.Timestamp = time.Unix(0, int64(ts*float64(time.Second)))
+	p.Value = v
+	return nil
+}
+
+// Sample is the value of a metric at the time of an instant query
+type Sample struct {
+	Metric map[string]string `json:"metric"`
+	Point  Point             `json:"value"`
+}
+
+// Series is the values of a metric over the time range of a range query
+type Series struct {
+	Metric map[string]string `json:"metric"`
+	Points []Point           `json:"values"`
+}
+
+func (r *QueryResponse) decode(resultType string, out interface{}) error {
+	if r.Data.ResultType != resultType {
+		return fmt.Errorf("expected result type %s, got %s", resultType, r.Data.ResultType)
+	}
+	return json.Unmarshal(r.Data.Result, out)
+}
+
+// Streams returns the result of a log query
+func (r *QueryResponse) Streams() ([]Stream, error) {
+	var streams []Stream
+	return streams, r.decode(ResultTypeStreams, &streams)
+}
+
+// Matrix returns the result of a metric range query
+func (r *QueryResponse) Matrix() ([]Series, error) {
+	var matrix []Series
+	return matrix, r.decode(ResultTypeMatrix, &matrix)
+}
+
+// Vector returns the result of a metric instant query
+func (r *QueryResponse) Vector() ([]Sample, error) {
+	var vector []Sample
+	return vector, r.decode(ResultTypeVector, &vector)
+}
+
+// Entries returns the log lines of all the streams ordered by time
+func (r *QueryResponse) Entries() ([]Entry, error) {
+	streams, err := r.Streams()
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, stream := range streams {
+		entries = append(entries, stream.Entries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+	return entries, nil
+}
+
+// LabelResponse is the response of the label APIs
+type LabelResponse struct {
+	Status string   `json:"status"`
+	Data   []string `json:"data"`
+}
+
+// SeriesResponse is the response of the series API
+type SeriesResponse struct {
+	Status string              `json:"status"`
+	Data   []map[string]string `json:"data"`
+}
+
+// TailResponse is a message of the tail API
+type TailResponse struct {
+	Streams        []Stream `json:"streams"`
+	DroppedEntries []struct {
+		Labels    map[string]string `json:"labels"`
+		Timestamp string            `json:"timestamp"`
+	} `json:"dropped_entries,omitempty"`
+}
@@ -0,0 +1,491 @@
+package netpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	netutils "k8s.io/utils/net"
+)
+
+const (
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+	defaultProtocol    = "TCP"
+	defaultBANPName    = "default"
+)
+
+// Oracle calculates the expected verdict of connections from the NetworkPolicy, AdminNetworkPolicy and BaselineAdminNetworkPolicy objects.
+// It does not need a cluster, so it can be used to calculate the expectations before the policies are applied.
+type Oracle struct {
+	networkPolicies []networkingv1.NetworkPolicy
+	adminPolicies   []AdminNetworkPolicy
+	baselinePolicy  *BaselineAdminNetworkPolicy
+}
+
+// NewOracle returns an oracle without policies, where every connection is allowed
+func NewOracle() *Oracle {
+	return &Oracle{}
+}
+
+// AddNetworkPolicies adds NetworkPolicy objects to the oracle
+func (o *Oracle) AddNetworkPolicies(policies ...networkingv1.NetworkPolicy) *Oracle {
+	o.networkPolicies = append(o.networkPolicies, policies...)
+	return o
+}
+
+// AddAdminNetworkPolicies adds AdminNetworkPolicy objects to the oracle
+func (o *Oracle) AddAdminNetworkPolicies(policies ...AdminNetworkPolicy) *Oracle {
+	o.adminPolicies = append(o.adminPolicies, policies...)
+	// Lower priority values are evaluated first. Policies with the same priority are not supported by the API, we sort them by name to be deterministic.
+	sort.SliceStable(o.adminPolicies, func(i, j int) bool {
+		if o.adminPolicies[i].Spec.Priority != o.adminPolicies[j].Spec.Priority {
+			return o.adminPolicies[i].Spec.Priority < o.adminPolicies[j].Spec.Priority
+		}
+		return o.adminPolicies[i].Name < o.adminPolicies[j].Name
+	})
+	return o
+}
+
+// SetBaselineAdminNetworkPolicy sets the BaselineAdminNetworkPolicy. Only the policy named "default" is honored, as in the cluster.
+func (o *Oracle) SetBaselineAdminNetworkPolicy(policy *BaselineAdminNetworkPolicy) *Oracle {
+	if policy != nil && policy.Name != "" && policy.Name != defaultBANPName {
+		o.baselinePolicy = nil
+		return o
+	}
+	o.baselinePolicy = policy
+	return o
+}
+
+// AddFromJSON adds the policies in the output of "oc get <kind> -o json". Both single objects and lists are accepted.
+func (o *Oracle) AddFromJSON(content []byte) error {
+	probe := struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}{}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return fmt.Errorf("Error decoding policies: %s", err)
+	}
+
+	if probe.Kind == "List" || strings.HasSuffix(probe.Kind, "List") {
+		for _, item := range probe.Items {
+			if err := o.AddFromJSON(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	switch probe.Kind {
+	case "NetworkPolicy":
+		policy := networkingv1.NetworkPolicy{}
+		if err = json.Unmarshal(content, &policy); err == nil {
+			o.AddNetworkPolicies(policy)
+		}
+	case "AdminNetworkPolicy":
+		policy := AdminNetworkPolicy{}
+		if err = json.Unmarshal(content, &policy); err == nil {
+			o.AddAdminNetworkPolicies(policy)
+		}
+	case "BaselineAdminNetworkPolicy":
+		policy := &BaselineAdminNetworkPolicy{}
+		if err = json.Unmarshal(content, policy); err == nil {
+			o.SetBaselineAdminNetworkPolicy(policy)
+		}
+	default:
+		return fmt.Errorf("Unsupported policy kind %q", probe.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("Error decoding %s: %s", probe.Kind, err)
+	}
+	return nil
+}
+
+// Evaluate returns the expected verdict of a connection
+func (o *Oracle) Evaluate(conn Connection) Verdict {
+	if conn.Protocol == "" {
+		conn.Protocol = defaultProtocol
+	}
+	return Verdict{
+		Connection: conn,
+		Egress:     o.evaluateDirection(conn, true),
+		Ingress:    o.evaluateDirection(conn, false),
+	}
+}
+
+// EvaluateAll returns the verdicts of the connections between every pair of different endpoints for the given port and protocol
+func (o *Oracle) EvaluateAll(endpoints []Endpoint, port int32, protocol string) []Verdict {
+	verdicts := []Verdict{}
+	for _, src := range endpoints {
+		for _, dst := range endpoints {
+			if src.String() == dst.String() || dst.Kind != PodEndpoint && src.Kind != PodEndpoint {
+				continue
+			}
+			verdicts = append(verdicts, o.Evaluate(Connection{Source: src, Destination: dst, Port: port, Protocol: protocol}))
+		}
+	}
+	return verdicts
+}
+
+// evaluateDirection evaluates the egress of the source (egress=true) or the ingress of the destination (egress=false)
+func (o *Oracle) evaluateDirection(conn Connection, egress bool) Decision {
+	subject, peer := conn.Destination, conn.Source
+	if egress {
+		subject, peer = conn.Source, conn.Destination
+	}
+
+	// Policies only apply to pods that are not in the host network
+	if subject.Kind != PodEndpoint {
+		return Decision{Allowed: true, Tier: TierDefault}
+	}
+
+	decision := Decision{}
+	for _, anp := range o.adminPolicies {
+		if !subjectMatches(anp.Spec.Subject, subject) {
+			continue
+		}
+		action, rule, matched := o.matchAdminRules(anp.Spec.Ingress, anp.Spec.Egress, conn, subject, peer, egress)
+		if !matched {
+			continue
+		}
+		ruleID := anp.Name + "/" + rule
+		if action == ActionPass {
+			decision.Passed = append(decision.Passed, ruleID)
+			break
+		}
+		decision.Allowed = action == ActionAllow
+		decision.Tier, decision.Policy, decision.Rule = TierAdmin, anp.Name, rule
+		return decision
+	}
+
+	if isolated, allowed, policy := o.evaluateNetworkPolicies(conn, subject, peer, egress); isolated {
+		decision.Allowed = allowed
+		decision.Tier, decision.Policy = TierNetwork, policy
+		return decision
+	}
+
+	if o.baselinePolicy != nil && subjectMatches(o.baselinePolicy.Spec.Subject, subject) {
+		action, rule, matched := o.matchAdminRules(o.baselinePolicy.Spec.Ingress, o.baselinePolicy.Spec.Egress, conn, subject, peer, egress)
+		if matched {
+			decision.Allowed = action == ActionAllow
+			decision.Tier, decision.Policy, decision.Rule = TierBaseline, o.baselinePolicy.Name, rule
+			return decision
+		}
+	}
+
+	decision.Allowed = true
+	decision.Tier = TierDefault
+	return decision
+}
+
+// matchAdminRules returns the action of the first rule matching the connection
+func (o *Oracle) matchAdminRules(ingress []AdminNetworkPolicyIngressRule, egress []AdminNetworkPolicyEgressRule, conn Connection, subject, peer Endpoint, isEgress bool) (RuleAction, string, bool) {
+	if isEgress {
+		for _, rule := range egress {
+			if !adminPortsMatch(rule.Ports, conn, conn.Destination) {
+				continue
+			}
+			for _, to := range rule.To {
+				if anyFamilyMatches(peer, conn.Family, func(family string) bool { return adminEgressPeerMatches(to, peer, family) }) {
+					return rule.Action, rule.Name, true
+				}
+			}
+		}
+		return "", "", false
+	}
+
+	for _, rule := range ingress {
+		if !adminPortsMatch(rule.Ports, conn, subject) {
+			continue
+		}
+		for _, from := range rule.From {
+			if adminIngressPeerMatches(from, peer) {
+				return rule.Action, rule.Name, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// evaluateNetworkPolicies returns whether the subject is isolated for the direction and, if it is, whether any policy allows the connection
+func (o *Oracle) evaluateNetworkPolicies(conn Connection, subject, peer Endpoint, egress bool) (bool, bool, string) {
+	isolated := false
+	for _, np := range o.networkPolicies {
+		if np.Namespace != subject.Namespace || !selectorMatches(&np.Spec.PodSelector, subject.Labels) {
+			continue
+		}
+		if !policyHasType(np, egress) {
+			continue
+		}
+		isolated = true
+
+		if egress {
+			for _, rule := range np.Spec.Egress {
+				if npPortsMatch(rule.Ports, conn, conn.Destination) && anyFamilyMatches(peer, conn.Family, func(family string) bool { return npPeersMatch(rule.To, np.Namespace, peer, family) }) {
+					return true, true, np.Namespace + "/" + np.Name
+				}
+			}
+		} else {
+			for _, rule := range np.Spec.Ingress {
+				if npPortsMatch(rule.Ports, conn, subject) && anyFamilyMatches(peer, conn.Family, func(family string) bool { return npPeersMatch(rule.From, np.Namespace, peer, family) }) {
+					return true, true, np.Namespace + "/" + np.Name
+				}
+			}
+		}
+	}
+	return isolated, false, ""
+}
+
+// policyHasType returns whether the NetworkPolicy isolates pods for ingress or egress, using the API defaults when policyTypes is empty
+func policyHasType(np networkingv1.NetworkPolicy, egress bool) bool {
+	if len(np.Spec.PolicyTypes) == 0 {
+		if egress {
+			return len(np.Spec.Egress) > 0
+		}
+		return true
+	}
+	want := networkingv1.PolicyTypeIngress
+	if egress {
+		want = networkingv1.PolicyTypeEgress
+	}
+	for _, policyType := range np.Spec.PolicyTypes {
+		if policyType == want {
+			return true
+		}
+	}
+	return false
+}
+
+func npPeersMatch(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, peer Endpoint, family string) bool {
+	// An empty list of peers matches every peer
+	if len(peers) == 0 {
+		return true
+	}
+	for _, p := range peers {
+		if p.IPBlock != nil {
+			if ipBlockMatches(p.IPBlock, peer, family) {
+				return true
+			}
+			continue
+		}
+		if peer.Kind != PodEndpoint {
+			continue
+		}
+		if p.NamespaceSelector == nil && peer.Namespace != policyNamespace {
+			continue
+		}
+		if p.NamespaceSelector != nil && !selectorMatches(p.NamespaceSelector, namespaceLabels(peer)) {
+			continue
+		}
+		if p.PodSelector != nil && !selectorMatches(p.PodSelector, peer.Labels) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func npPortsMatch(ports []networkingv1.NetworkPolicyPort, conn Connection, destination Endpoint) bool {
+	// An empty list of ports matches every port
+	if len(ports) == 0 {
+		return true
+	}
+	for _, port := range ports {
+		protocol := defaultProtocol
+		if port.Protocol != nil {
+			protocol = string(*port.Protocol)
+		}
+		if !strings.EqualFold(protocol, conn.Protocol) {
+			continue
+		}
+		if port.Port == nil {
+			return true
+		}
+		if port.Port.StrVal != "" {
+			if namedPortMatches(port.Port.StrVal, conn, destination) {
+				return true
+			}
+			continue
+		}
+		end := port.Port.IntVal
+		if port.EndPort != nil {
+			end = *port.EndPort
+		}
+		if conn.Port >= port.Port.IntVal && conn.Port <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// anyFamilyMatches evaluates a rule on every family of the peer when the connection has no family, so that a rule
+// with separate IPv4 and IPv6 peers matches a dual-stack peer, as the cluster does. The rule matches if any family matches.
+func anyFamilyMatches(peer Endpoint, family string, matches func(family string) bool) bool {
+	if family != "" {
+		return matches(family)
+	}
+	evaluated := map[string]bool{}
+	for _, ip := range peer.IPs {
+		f := ipFamily(ip)
+		if evaluated[f] {
+			continue
+		}
+		evaluated[f] = true
+		if matches(f) {
+			return true
+		}
+	}
+	// peers without IPs are only matched by selectors
+	return len(evaluated) == 0 && matches(family)
+}
+
+func ipBlockMatches(block *networkingv1.IPBlock, peer Endpoint, family string) bool {
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil {
+		return false
+	}
+	for _, ip := range familyIPs(peer, family) {
+		parsed := net.ParseIP(ip)
+		if parsed == nil || !cidr.Contains(parsed) {
+			continue
+		}
+		excluded := false
+		for _, except := range block.Except {
+			if _, exceptCIDR, err := net.ParseCIDR(except); err == nil && exceptCIDR.Contains(parsed) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			return true
+		}
+	}
+	return false
+}
+
+func subjectMatches(subject AdminNetworkPolicySubject, pod Endpoint) bool {
+	if subject.Namespaces != nil {
+		return selectorMatches(subject.Namespaces, namespaceLabels(pod))
+	}
+	if subject.Pods != nil {
+		return namespacedPodMatches(subject.Pods, pod)
+	}
+	return false
+}
+
+func adminIngressPeerMatches(peer AdminNetworkPolicyIngressPeer, endpoint Endpoint) bool {
+	if endpoint.Kind != PodEndpoint {
+		return false
+	}
+	if peer.Namespaces != nil {
+		return selectorMatches(peer.Namespaces, namespaceLabels(endpoint))
+	}
+	if peer.Pods != nil {
+		return namespacedPodMatches(peer.Pods, endpoint)
+	}
+	return false
+}
+
+func adminEgressPeerMatches(peer AdminNetworkPolicyEgressPeer, endpoint Endpoint, family string) bool {
+	switch {
+	case peer.Namespaces != nil:
+		return endpoint.Kind == PodEndpoint && selectorMatches(peer.Namespaces, namespaceLabels(endpoint))
+	case peer.Pods != nil:
+		return endpoint.Kind == PodEndpoint && namespacedPodMatches(peer.Pods, endpoint)
+	case peer.Nodes != nil:
+		return endpoint.Kind == NodeEndpoint && selectorMatches(peer.Nodes, endpoint.Labels)
+	case len(peer.Networks) > 0:
+		for _, network := range peer.Networks {
+			if ipBlockMatches(&networkingv1.IPBlock{CIDR: network}, endpoint, family) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func adminPortsMatch(ports *[]AdminNetworkPolicyPort, conn Connection, destination Endpoint) bool {
+	if ports == nil || len(*ports) == 0 {
+		return true
+	}
+	for _, port := range *ports {
+		switch {
+		case port.PortNumber != nil:
+			if strings.EqualFold(protocolOrDefault(port.PortNumber.Protocol), conn.Protocol) && port.PortNumber.Port == conn.Port {
+				return true
+			}
+		case port.PortRange != nil:
+			if strings.EqualFold(protocolOrDefault(port.PortRange.Protocol), conn.Protocol) && conn.Port >= port.PortRange.Start && conn.Port <= port.PortRange.End {
+				return true
+			}
+		case port.NamedPort != nil:
+			if namedPortMatches(*port.NamedPort, conn, destination) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namedPortMatches resolves a named port in the destination pod
+func namedPortMatches(name string, conn Connection, destination Endpoint) bool {
+	for _, port := range destination.Ports {
+		if port.Name == name && port.Port == conn.Port && strings.EqualFold(protocolOrDefault(port.Protocol), conn.Protocol) {
+			return true
+		}
+	}
+	return false
+}
+
+func namespacedPodMatches(selector *NamespacedPod, pod Endpoint) bool {
+	return selectorMatches(&selector.NamespaceSelector, namespaceLabels(pod)) && selectorMatches(&selector.PodSelector, pod.Labels)
+}
+
+// selectorMatches returns whether the labels match the selector. An empty selector matches everything.
+func selectorMatches(selector *metav1.LabelSelector, objLabels map[string]string) bool {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(objLabels))
+}
+
+func namespaceLabels(pod Endpoint) map[string]string {
+	nsLabels := map[string]string{namespaceNameLabel: pod.Namespace}
+	for k, v := range pod.NamespaceLabels {
+		nsLabels[k] = v
+	}
+	return nsLabels
+}
+
+func familyIPs(endpoint Endpoint, family string) []string {
+	if family == "" {
+		return endpoint.IPs
+	}
+	ips := []string{}
+	for _, ip := range endpoint.IPs {
+		if ipFamily(ip) == family {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+func ipFamily(ip string) string {
+	if netutils.IsIPv6String(ip) {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+func protocolOrDefault(protocol string) string {
+	if protocol == "" {
+		return defaultProtocol
+	}
+	return protocol
+}
@@ -0,0 +1,201 @@
+package netpolicy
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var (
+	clientNS1 = Endpoint{Kind: PodEndpoint, Name: "client", Namespace: "ns1", Labels: map[string]string{"app": "client"},
+		NamespaceLabels: map[string]string{"team": "red"}, IPs: []string{"10.128.0.10", "fd01:0:0:1::10"}}
+	serverNS1 = Endpoint{Kind: PodEndpoint, Name: "server", Namespace: "ns1", Labels: map[string]string{"app": "server"},
+		NamespaceLabels: map[string]string{"team": "red"}, IPs: []string{"10.128.0.11", "fd01:0:0:1::11"},
+		Ports: []ContainerPort{{Name: "http", Port: 8080}}}
+	clientNS2 = Endpoint{Kind: PodEndpoint, Name: "client", Namespace: "ns2", Labels: map[string]string{"app": "client"},
+		NamespaceLabels: map[string]string{"team": "blue"}, IPs: []string{"10.129.0.10", "fd01:0:0:2::10"}}
+	worker = Endpoint{Kind: NodeEndpoint, Name: "worker-0", Labels: map[string]string{"node-role.kubernetes.io/worker": ""},
+		IPs: []string{"192.168.1.10", "fd00::10"}}
+)
+
+func expectVerdict(t *testing.T, oracle *Oracle, src, dst Endpoint, port int32, allowed bool, tier Tier) {
+	t.Helper()
+	verdict := oracle.Evaluate(Connection{Source: src, Destination: dst, Port: port})
+	if verdict.Allowed() != allowed {
+		t.Errorf("%s -> %s:%d expected allowed=%v, got egress %+v ingress %+v", src, dst, port, allowed, verdict.Egress, verdict.Ingress)
+	}
+	decidingTier := verdict.Ingress.Tier
+	if !verdict.Egress.Allowed || verdict.Egress.Tier != TierDefault && verdict.Ingress.Tier == TierDefault {
+		decidingTier = verdict.Egress.Tier
+	}
+	if decidingTier != tier {
+		t.Errorf("%s -> %s:%d expected to be decided by %s, got egress %+v ingress %+v", src, dst, port, tier, verdict.Egress, verdict.Ingress)
+	}
+}
+
+func TestNoPoliciesAllowEverything(t *testing.T) {
+	oracle := NewOracle()
+	expectVerdict(t, oracle, clientNS1, serverNS1, 8080, true, TierDefault)
+	expectVerdict(t, oracle, worker, serverNS1, 8080, true, TierDefault)
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	tcp := corev1.ProtocolTCP
+	httpPort := intstr.FromString("http")
+	oracle := NewOracle().AddNetworkPolicies(networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-same-ns-http", Namespace: "ns1"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "server"}},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+				Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &httpPort}},
+			}},
+		},
+	}, networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "egress-ipblock", Namespace: "ns2"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.128.0.0/16", Except: []string{"10.128.0.11/32"}}}},
+			}},
+		},
+	})
+
+	expectVerdict(t, oracle, clientNS1, serverNS1, 8080, true, TierNetwork)
+	expectVerdict(t, oracle, clientNS1, serverNS1, 9090, false, TierNetwork)
+	// ns2 egress only allows the CIDR without the server IP, and the server ingress does not allow other namespaces
+	expectVerdict(t, oracle, clientNS2, serverNS1, 8080, false, TierNetwork)
+	// the dual-stack client is only in the ipBlock by its IPv4 address, that is enough to connect on IPv4
+	expectVerdict(t, oracle, clientNS2, clientNS1, 8080, true, TierNetwork)
+	for _, tc := range []struct {
+		family  string
+		allowed bool
+	}{
+		{"ipv4", true},
+		{"ipv6", false},
+	} {
+		verdict := oracle.Evaluate(Connection{Source: clientNS2, Destination: clientNS1, Port: 8080, Family: tc.family})
+		if verdict.Allowed() != tc.allowed {
+			t.Errorf("%s connection to the IPv4 ipBlock expected allowed=%v, got %+v", tc.family, tc.allowed, verdict)
+		}
+	}
+
+	// a single-stack client matches the ipBlock on its only family
+	singleStack := clientNS1
+	singleStack.IPs = []string{"10.128.0.10"}
+	expectVerdict(t, oracle, clientNS2, singleStack, 8080, true, TierNetwork)
+	singleStack.IPs = []string{"fd01:0:0:1::10"}
+	expectVerdict(t, oracle, clientNS2, singleStack, 8080, false, TierNetwork)
+}
+
+func TestNetworkPolicyDualStackIPBlocks(t *testing.T) {
+	oracle := NewOracle().AddNetworkPolicies(networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-ns2-cidrs", Namespace: "ns1"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.129.0.0/16"}},
+					{IPBlock: &networkingv1.IPBlock{CIDR: "fd01:0:0:2::/64"}},
+				},
+			}},
+		},
+	})
+
+	// the separate IPv4 and IPv6 peers of the rule allow the dual-stack client on any family, as the cluster does
+	expectVerdict(t, oracle, clientNS2, serverNS1, 8080, true, TierNetwork)
+	for _, verdict := range oracle.EvaluateAll([]Endpoint{clientNS2, serverNS1}, 8080, "") {
+		if verdict.Connection.Destination.String() == serverNS1.String() && !verdict.Allowed() {
+			t.Errorf("EvaluateAll expected %s -> %s to be allowed, got %+v", verdict.Connection.Source, verdict.Connection.Destination, verdict)
+		}
+	}
+	for _, family := range []string{"ipv4", "ipv6"} {
+		if verdict := oracle.Evaluate(Connection{Source: clientNS2, Destination: serverNS1, Port: 8080, Family: family}); !verdict.Allowed() {
+			t.Errorf("%s connection expected to be allowed, got %+v", family, verdict)
+		}
+	}
+	expectVerdict(t, oracle, clientNS1, serverNS1, 8080, false, TierNetwork)
+}
+
+func TestAdminNetworkPolicyTiers(t *testing.T) {
+	oracle := NewOracle().AddAdminNetworkPolicies(AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "pass-red"},
+		Spec: AdminNetworkPolicySpec{
+			Priority: 10,
+			Subject:  AdminNetworkPolicySubject{Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "red"}}},
+			Ingress: []AdminNetworkPolicyIngressRule{{
+				Name: "pass-from-blue", Action: ActionPass,
+				From: []AdminNetworkPolicyIngressPeer{{Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "blue"}}}},
+			}},
+		},
+	}, AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-all-to-red"},
+		Spec: AdminNetworkPolicySpec{
+			Priority: 20,
+			Subject:  AdminNetworkPolicySubject{Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "red"}}},
+			Ingress: []AdminNetworkPolicyIngressRule{{
+				Name: "deny-all", Action: ActionDeny,
+				From: []AdminNetworkPolicyIngressPeer{{Namespaces: &metav1.LabelSelector{}}},
+			}},
+			Egress: []AdminNetworkPolicyEgressRule{{
+				Name: "deny-nodes", Action: ActionDeny,
+				To: []AdminNetworkPolicyEgressPeer{{Nodes: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "node-role.kubernetes.io/worker", Operator: metav1.LabelSelectorOpExists}}}}},
+			}},
+		},
+	}).SetBaselineAdminNetworkPolicy(&BaselineAdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: BaselineAdminNetworkPolicySpec{
+			Subject: AdminNetworkPolicySubject{Namespaces: &metav1.LabelSelector{}},
+			Ingress: []AdminNetworkPolicyIngressRule{{
+				Name: "deny-blue", Action: ActionDeny,
+				From: []AdminNetworkPolicyIngressPeer{{Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "blue"}}}},
+			}},
+		},
+	})
+
+	// Same namespace traffic is denied by the second ANP
+	expectVerdict(t, oracle, clientNS1, serverNS1, 8080, false, TierAdmin)
+	// Blue traffic is passed by the first ANP, so the second ANP is skipped and the BANP denies it
+	expectVerdict(t, oracle, clientNS2, serverNS1, 8080, false, TierBaseline)
+	// Red pods cannot reach worker nodes
+	expectVerdict(t, oracle, clientNS1, worker, 22, false, TierAdmin)
+
+	// A NetworkPolicy allowing blue traffic takes precedence over the BANP after the Pass
+	oracle.AddNetworkPolicies(networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-blue", Namespace: "ns1"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "blue"}}}},
+			}},
+		},
+	})
+	expectVerdict(t, oracle, clientNS2, serverNS1, 8080, true, TierNetwork)
+	verdict := oracle.Evaluate(Connection{Source: clientNS2, Destination: serverNS1, Port: 8080})
+	if len(verdict.Ingress.Passed) != 1 || verdict.Ingress.Passed[0] != "pass-red/pass-from-blue" {
+		t.Errorf("The pass rule should be reported in the verdict: %+v", verdict.Ingress)
+	}
+}
+
+func TestAddFromJSON(t *testing.T) {
+	oracle := NewOracle()
+	err := oracle.AddFromJSON([]byte(`{"kind": "List", "items": [{
+		"kind": "AdminNetworkPolicy", "apiVersion": "policy.networking.k8s.io/v1alpha1",
+		"metadata": {"name": "deny-port"},
+		"spec": {"priority": 5,
+			"subject": {"pods": {"namespaceSelector": {}, "podSelector": {"matchLabels": {"app": "server"}}}},
+			"ingress": [{"name": "deny-8080", "action": "Deny", "from": [{"namespaces": {}}],
+				"ports": [{"portRange": {"protocol": "TCP", "start": 8000, "end": 8100}}]}]}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVerdict(t, oracle, clientNS1, serverNS1, 8080, false, TierAdmin)
+	expectVerdict(t, oracle, clientNS1, serverNS1, 9090, true, TierDefault)
+
+	if err := oracle.AddFromJSON([]byte(`{"kind": "Pod"}`)); err == nil {
+		t.Errorf("Expected error adding a non policy object")
+	}
+}
@@ -0,0 +1,201 @@
+package netpolicy
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The AdminNetworkPolicy and BaselineAdminNetworkPolicy types below mirror policy.networking.k8s.io/v1alpha1.
+// Only the fields needed to calculate verdicts are decoded, so the objects can be read with "oc get -o json".
+
+// AdminNetworkPolicy is a cluster scoped policy evaluated before NetworkPolicies
+type AdminNetworkPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              AdminNetworkPolicySpec `json:"spec"`
+}
+
+// AdminNetworkPolicyList is a list of AdminNetworkPolicy objects
+type AdminNetworkPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AdminNetworkPolicy `json:"items"`
+}
+
+// AdminNetworkPolicySpec is the spec of an AdminNetworkPolicy
+type AdminNetworkPolicySpec struct {
+	Priority int32                           `json:"priority"`
+	Subject  AdminNetworkPolicySubject       `json:"subject"`
+	Ingress  []AdminNetworkPolicyIngressRule `json:"ingress,omitempty"`
+	Egress   []AdminNetworkPolicyEgressRule  `json:"egress,omitempty"`
+}
+
+// BaselineAdminNetworkPolicy is the cluster scoped policy evaluated after NetworkPolicies. Only the one named "default" is honored.
+type BaselineAdminNetworkPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BaselineAdminNetworkPolicySpec `json:"spec"`
+}
+
+// BaselineAdminNetworkPolicySpec is the spec of a BaselineAdminNetworkPolicy. Rules can only Allow or Deny.
+type BaselineAdminNetworkPolicySpec struct {
+	Subject AdminNetworkPolicySubject       `json:"subject"`
+	Ingress []AdminNetworkPolicyIngressRule `json:"ingress,omitempty"`
+	Egress  []AdminNetworkPolicyEgressRule  `json:"egress,omitempty"`
+}
+
+// AdminNetworkPolicySubject selects the pods the policy applies to. Only one of the fields can be set.
+type AdminNetworkPolicySubject struct {
+	Namespaces *metav1.LabelSelector `json:"namespaces,omitempty"`
+	Pods       *NamespacedPod        `json:"pods,omitempty"`
+}
+
+// NamespacedPod selects pods by namespace and pod labels
+type NamespacedPod struct {
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+	PodSelector       metav1.LabelSelector `json:"podSelector"`
+}
+
+// AdminNetworkPolicyIngressRule is an ingress rule of an (Baseline)AdminNetworkPolicy
+type AdminNetworkPolicyIngressRule struct {
+	Name   string                          `json:"name,omitempty"`
+	Action RuleAction                      `json:"action"`
+	From   []AdminNetworkPolicyIngressPeer `json:"from"`
+	Ports  *[]AdminNetworkPolicyPort       `json:"ports,omitempty"`
+}
+
+// AdminNetworkPolicyEgressRule is an egress rule of an (Baseline)AdminNetworkPolicy
+type AdminNetworkPolicyEgressRule struct {
+	Name   string                         `json:"name,omitempty"`
+	Action RuleAction                     `json:"action"`
+	To     []AdminNetworkPolicyEgressPeer `json:"to"`
+	Ports  *[]AdminNetworkPolicyPort      `json:"ports,omitempty"`
+}
+
+// AdminNetworkPolicyIngressPeer selects the sources of an ingress rule
+type AdminNetworkPolicyIngressPeer struct {
+	Namespaces *metav1.LabelSelector `json:"namespaces,omitempty"`
+	Pods       *NamespacedPod        `json:"pods,omitempty"`
+}
+
+// AdminNetworkPolicyEgressPeer selects the destinations of an egress rule
+type AdminNetworkPolicyEgressPeer struct {
+	Namespaces *metav1.LabelSelector `json:"namespaces,omitempty"`
+	Pods       *NamespacedPod        `json:"pods,omitempty"`
+	Nodes      *metav1.LabelSelector `json:"nodes,omitempty"`
+	Networks   []string              `json:"networks,omitempty"`
+}
+
+// AdminNetworkPolicyPort selects the destination ports of a rule. Only one of the fields can be set.
+type AdminNetworkPolicyPort struct {
+	PortNumber *PortNumber `json:"portNumber,omitempty"`
+	NamedPort  *string     `json:"namedPort,omitempty"`
+	PortRange  *PortRange  `json:"portRange,omitempty"`
+}
+
+// PortNumber is a single port and protocol
+type PortNumber struct {
+	Protocol string `json:"protocol"`
+	Port     int32  `json:"port"`
+}
+
+// PortRange is a range of ports, both ends included
+type PortRange struct {
+	Protocol string `json:"protocol,omitempty"`
+	Start    int32  `json:"start"`
+	End      int32  `json:"end"`
+}
+
+// RuleAction is the action of an (Baseline)AdminNetworkPolicy rule
+type RuleAction string
+
+// Actions supported in (Baseline)AdminNetworkPolicy rules
+const (
+	ActionAllow RuleAction = "Allow"
+	ActionDeny  RuleAction = "Deny"
+	ActionPass  RuleAction = "Pass"
+)
+
+// EndpointKind is the kind of the endpoints used to evaluate the policies
+type EndpointKind string
+
+// Kinds of endpoints. Host network pods must be modeled as nodes, policies do not apply to them.
+const (
+	PodEndpoint      EndpointKind = "pod"
+	NodeEndpoint     EndpointKind = "node"
+	ExternalEndpoint EndpointKind = "external"
+)
+
+// Endpoint is the source or the destination of a connection
+type Endpoint struct {
+	Kind EndpointKind
+	Name string
+	// Namespace and NamespaceLabels are only used by pods. The kubernetes.io/metadata.name label is added automatically.
+	Namespace       string
+	NamespaceLabels map[string]string
+	// Labels are the pod labels or the node labels
+	Labels map[string]string
+	IPs    []string
+	// Ports are the named container ports of a pod, used to resolve named ports in the policies
+	Ports []ContainerPort
+}
+
+// ContainerPort is a named port exposed by a pod
+type ContainerPort struct {
+	Name     string
+	Port     int32
+	Protocol string
+}
+
+// String returns a human readable identifier for the endpoint
+func (e Endpoint) String() string {
+	if e.Kind == PodEndpoint {
+		return e.Namespace + "/" + e.Name
+	}
+	return string(e.Kind) + "/" + e.Name
+}
+
+// Connection is a connection whose verdict is evaluated
+type Connection struct {
+	Source      Endpoint
+	Destination Endpoint
+	Port        int32
+	// Protocol is TCP if empty
+	Protocol string
+	// Family selects the IPs used to match CIDRs: "ipv4", "ipv6" or empty for a connection on any family of the
+	// endpoints, every rule is then evaluated per family and matches a dual-stack endpoint if it matches any of them
+	Family string
+}
+
+// Tier is the policy tier that decided a verdict
+type Tier string
+
+// Tiers in evaluation order
+const (
+	TierAdmin    Tier = "AdminNetworkPolicy"
+	TierNetwork  Tier = "NetworkPolicy"
+	TierBaseline Tier = "BaselineAdminNetworkPolicy"
+	TierDefault  Tier = "Default"
+)
+
+// Decision is the verdict for one direction (egress in the source, or ingress in the destination) of a connection
+type Decision struct {
+	Allowed bool
+	Tier    Tier
+	// Policy and Rule identify the policy and rule that decided. They are empty for default decisions.
+	Policy string
+	Rule   string
+	// Passed contains the AdminNetworkPolicy rules that delegated the decision to the next tiers
+	Passed []string
+}
+
+// Verdict is the expected result of a connection. It is only allowed if both the source egress and the destination ingress allow it.
+type Verdict struct {
+	Connection Connection
+	Egress     Decision
+	Ingress    Decision
+}
+
+// Allowed returns true if the connection is expected to succeed
+func (v Verdict) Allowed() bool {
+	return v.Egress.Allowed && v.Ingress.Allowed
+}
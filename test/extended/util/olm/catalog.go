@@ -0,0 +1,231 @@
+package olm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// MarketplaceNamespace is the namespace of the default catalog sources
+const MarketplaceNamespace = "openshift-marketplace"
+
+// DefaultCatalogSources are the catalog sources preferred in order when the operator does not set one. If none of
+// them provides the package, i.e. in the disconnected clusters where the default catalog sources are disabled and the
+// mirrored ones are named by oc-mirror, the first catalog source providing the package is used.
+var DefaultCatalogSources = []string{"qe-app-registry", "redhat-operators", "certified-operators", "community-operators"}
+
+// PackageChannel is a channel of a package
+type PackageChannel struct {
+	Name       string `json:"name"`
+	CurrentCSV string `json:"currentCSV"`
+	Entries    []struct {
+		Name string `json:"name"`
+	} `json:"entries"`
+}
+
+// HasCSV returns whether the CSV is in the channel
+func (c PackageChannel) HasCSV(csv string) bool {
+	if c.CurrentCSV == csv {
+		return true
+	}
+	for _, entry := range c.Entries {
+		if entry.Name == csv {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageManifest is the package as provided by a catalog source
+type PackageManifest struct {
+	Name                   string
+	CatalogSource          string
+	CatalogSourceNamespace string
+	DefaultChannel         string
+	Channels               []PackageChannel
+}
+
+// Channel returns the channel with the name, false if the package does not have it
+func (p PackageManifest) Channel(name string) (PackageChannel, bool) {
+	for _, channel := range p.Channels {
+		if channel.Name == name {
+			return channel, true
+		}
+	}
+	return PackageChannel{}, false
+}
+
+// packageManifestList is the part of the packagemanifest list the library reads
+type packageManifestList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			CatalogSource          string           `json:"catalogSource"`
+			CatalogSourceNamespace string           `json:"catalogSourceNamespace"`
+			DefaultChannel         string           `json:"defaultChannel"`
+			Channels               []PackageChannel `json:"channels"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// decodePackageManifests decodes the packagemanifest list and returns the manifests of the package by catalog source
+func decodePackageManifests(output []byte, packageName string) ([]PackageManifest, error) {
+	list := packageManifestList{}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("error decoding the packagemanifests: %v", err)
+	}
+	manifests := []PackageManifest{}
+	for _, item := range list.Items {
+		if item.Metadata.Name != packageName {
+			continue
+		}
+		manifests = append(manifests, PackageManifest{
+			Name:                   item.Metadata.Name,
+			CatalogSource:          item.Status.CatalogSource,
+			CatalogSourceNamespace: item.Status.CatalogSourceNamespace,
+			DefaultChannel:         item.Status.DefaultChannel,
+			Channels:               item.Status.Channels,
+		})
+	}
+	return manifests, nil
+}
+
+// GetPackageManifests returns the manifests of the package provided by the catalog sources of the namespace
+func GetPackageManifests(oc *exutil.CLI, namespace string, packageName string) ([]PackageManifest, error) {
+	// The packagemanifests of a package have the same name in all the catalog sources, they are told apart by their labels
+	output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("packagemanifests", "-n", namespace, "-l", "catalog-namespace="+namespace, "-o", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing the packagemanifests of %s: %v", namespace, err)
+	}
+	return decodePackageManifests([]byte(output), packageName)
+}
+
+// selectPackageManifest returns the manifest of the catalog source, or of the first preferred catalog source providing
+// the package if the catalog source is not set
+func selectPackageManifest(manifests []PackageManifest, catalogSource string, preferred []string) (PackageManifest, bool) {
+	if catalogSource != "" {
+		for _, manifest := range manifests {
+			if manifest.CatalogSource == catalogSource {
+				return manifest, true
+			}
+		}
+		return PackageManifest{}, false
+	}
+	for _, source := range preferred {
+		for _, manifest := range manifests {
+			if manifest.CatalogSource == source {
+				return manifest, true
+			}
+		}
+	}
+	if len(manifests) > 0 {
+		return manifests[0], true
+	}
+	return PackageManifest{}, false
+}
+
+// WaitForPackageManifest waits for the catalog source to provide the package, or any catalog source if it is empty
+func WaitForPackageManifest(oc *exutil.CLI, namespace string, packageName string, catalogSource string, preferred []string, timeout time.Duration) (PackageManifest, error) {
+	var manifest PackageManifest
+	err := wait.Poll(10*time.Second, timeout, func() (bool, error) {
+		manifests, err := GetPackageManifests(oc, namespace, packageName)
+		if err != nil {
+			e2e.Logf("%v, try next round", err)
+			return false, nil
+		}
+		found := false
+		manifest, found = selectPackageManifest(manifests, catalogSource, preferred)
+		if !found {
+			e2e.Logf("Waiting for packagemanifest/%s to appear in catalog source %q", packageName, catalogSource)
+		}
+		return found, nil
+	})
+	if err != nil {
+		return manifest, fmt.Errorf("packagemanifest/%s is not provided by catalog source %q in %s", packageName, catalogSource, namespace)
+	}
+	return manifest, nil
+}
+
+// CatalogSource is a grpc catalog source served from an index image, i.e. the mirrored index of a disconnected cluster
+type CatalogSource struct {
+	Name      string
+	Namespace string
+	Image     string
+	// DisplayName defaults to the name
+	DisplayName string
+}
+
+// manifest returns the catalog source object
+func (c CatalogSource) manifest() map[string]interface{} {
+	displayName := c.DisplayName
+	if displayName == "" {
+		displayName = c.Name
+	}
+	return map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "CatalogSource",
+		"metadata":   map[string]interface{}{"name": c.Name, "namespace": c.Namespace},
+		"spec": map[string]interface{}{
+			"sourceType":  "grpc",
+			"image":       c.Image,
+			"displayName": displayName,
+			"publisher":   "OpenShift QE",
+			"updateStrategy": map[string]interface{}{
+				"registryPoll": map[string]interface{}{"interval": "15m"},
+			},
+		},
+	}
+}
+
+// Create creates the catalog source and waits for its connection to be ready
+func (c CatalogSource) Create(oc *exutil.CLI) error {
+	if err := applyManifest(oc, c.manifest()); err != nil {
+		return fmt.Errorf("error creating catalogsource/%s: %v", c.Name, err)
+	}
+	err := wait.Poll(10*time.Second, 5*time.Minute, func() (bool, error) {
+		state, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("catalogsource", c.Name, "-n", c.Namespace, "-o=jsonpath={.status.connectionState.lastObservedState}").Output()
+		if err != nil {
+			e2e.Logf("Error getting catalogsource/%s state: %v, try next round", c.Name, err)
+			return false, nil
+		}
+		return state == "READY", nil
+	})
+	if err != nil {
+		status, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("catalogsource", c.Name, "-n", c.Namespace, "-o=jsonpath={.status}").Output()
+		return fmt.Errorf("catalogsource/%s is not ready: %s", c.Name, status)
+	}
+	return nil
+}
+
+// Delete deletes the catalog source
+func (c CatalogSource) Delete(oc *exutil.CLI) error {
+	return oc.AsAdmin().WithoutNamespace().Run("delete").Args("catalogsource", c.Name, "-n", c.Namespace, "--ignore-not-found").Execute()
+}
+
+// applyManifest applies the object with "oc apply"
+func applyManifest(oc *exutil.CLI, manifest map[string]interface{}) error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	var output string
+	err = wait.Poll(5*time.Second, time.Minute, func() (bool, error) {
+		output, err = oc.AsAdmin().WithoutNamespace().Run("apply").Args("-f", "-").InputString(string(content)).Output()
+		if err != nil {
+			e2e.Logf("Error applying %s: %v, try next round", strings.TrimSpace(output), err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package olm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const (
+	packageManifestsOutput = `{"items":[
+		{"metadata":{"name":"cluster-logging"},"status":{"catalogSource":"cs-redhat-operator-index","catalogSourceNamespace":"openshift-marketplace","defaultChannel":"stable-6.2",
+			"channels":[{"name":"stable-6.1","currentCSV":"cluster-logging.v6.1.3","entries":[{"name":"cluster-logging.v6.1.3"},{"name":"cluster-logging.v6.1.2"}]},{"name":"stable-6.2","currentCSV":"cluster-logging.v6.2.0"}]}},
+		{"metadata":{"name":"loki-operator"},"status":{"catalogSource":"qe-app-registry","catalogSourceNamespace":"openshift-marketplace","defaultChannel":"stable-6.2"}},
+		{"metadata":{"name":"cluster-logging"},"status":{"catalogSource":"redhat-operators","catalogSourceNamespace":"openshift-marketplace","defaultChannel":"stable-6.2"}}]}`
+	installPlansOutput = `{"items":[
+		{"metadata":{"name":"install-aaaaa"},"spec":{"approved":true,"clusterServiceVersionNames":["cluster-logging.v6.1.2"]}},
+		{"metadata":{"name":"install-bbbbb"},"spec":{"approved":false,"clusterServiceVersionNames":["cluster-logging.v6.1.3"]}}]}`
+)
+
+func TestSelectPackageManifest(t *testing.T) {
+	manifests, err := decodePackageManifests([]byte(packageManifestsOutput), "cluster-logging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("Expected the manifests of 2 catalog sources, got %v", manifests)
+	}
+
+	for _, tc := range []struct {
+		catalogSource string
+		preferred     []string
+		expected      string
+		found         bool
+	}{
+		{catalogSource: "cs-redhat-operator-index", preferred: DefaultCatalogSources, expected: "cs-redhat-operator-index", found: true},
+		{catalogSource: "qe-app-registry", preferred: DefaultCatalogSources, found: false},
+		{preferred: DefaultCatalogSources, expected: "redhat-operators", found: true},
+		// The disconnected clusters only have the mirrored catalog sources
+		{preferred: []string{"qe-app-registry"}, expected: "cs-redhat-operator-index", found: true},
+	} {
+		manifest, found := selectPackageManifest(manifests, tc.catalogSource, tc.preferred)
+		if found != tc.found || manifest.CatalogSource != tc.expected {
+			t.Errorf("Catalog source %q preferring %v: expected %q %v, got %q %v", tc.catalogSource, tc.preferred, tc.expected, tc.found, manifest.CatalogSource, found)
+		}
+	}
+
+	channel, ok := manifests[0].Channel("stable-6.1")
+	if !ok || !channel.HasCSV("cluster-logging.v6.1.2") || channel.HasCSV("cluster-logging.v6.2.0") {
+		t.Errorf("Unexpected channel %v", channel)
+	}
+	if _, ok := manifests[0].Channel("stable-5.9"); ok {
+		t.Errorf("Unexpected channel stable-5.9")
+	}
+}
+
+func TestPendingInstallPlan(t *testing.T) {
+	for _, tc := range []struct {
+		csv       string
+		installed string
+		expected  string
+	}{
+		{csv: "cluster-logging.v6.1.3", expected: "install-bbbbb"},
+		{csv: "cluster-logging.v6.1.2", expected: ""},
+		{installed: "cluster-logging.v6.1.2", expected: "install-bbbbb"},
+		{installed: "cluster-logging.v6.1.3", expected: ""},
+	} {
+		plan, err := pendingInstallPlan([]byte(installPlansOutput), tc.csv, tc.installed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan != tc.expected {
+			t.Errorf("CSV %q installed %q: expected installplan %q, got %q", tc.csv, tc.installed, tc.expected, plan)
+		}
+	}
+}
+
+func TestManifests(t *testing.T) {
+	op := &Operator{Package: "sriov-network-operator", Namespace: "openshift-sriov-network-operator", Channel: "stable", CatalogSource: "qe-app-registry",
+		StartingCSV: "sriov-network-operator.v4.18.0", ManualApproval: true, Config: map[string]interface{}{"nodeSelector": map[string]string{"node-role.kubernetes.io/worker": ""}}}
+	op.setDefaults()
+
+	for _, tc := range []struct {
+		manifest map[string]interface{}
+		expected string
+	}{
+		{
+			manifest: op.subscriptionManifest(),
+			expected: `{"apiVersion":"operators.coreos.com/v1alpha1","kind":"Subscription","metadata":{"name":"sriov-network-operator","namespace":"openshift-sriov-network-operator"},` +
+				`"spec":{"channel":"stable","config":{"nodeSelector":{"node-role.kubernetes.io/worker":""}},"installPlanApproval":"Manual","name":"sriov-network-operator",` +
+				`"source":"qe-app-registry","sourceNamespace":"openshift-marketplace","startingCSV":"sriov-network-operator.v4.18.0"}}`,
+		},
+		{
+			manifest: op.operatorGroupManifest(),
+			expected: `{"apiVersion":"operators.coreos.com/v1","kind":"OperatorGroup","metadata":{"name":"openshift-sriov-network-operator","namespace":"openshift-sriov-network-operator"},` +
+				`"spec":{"targetNamespaces":["openshift-sriov-network-operator"]}}`,
+		},
+		{
+			manifest: CatalogSource{Name: "cs-redhat-operator-index", Namespace: MarketplaceNamespace, Image: "mirror.registry:5000/redhat/redhat-operator-index:v4.18"}.manifest(),
+			expected: `{"apiVersion":"operators.coreos.com/v1alpha1","kind":"CatalogSource","metadata":{"name":"cs-redhat-operator-index","namespace":"openshift-marketplace"},` +
+				`"spec":{"displayName":"cs-redhat-operator-index","image":"mirror.registry:5000/redhat/redhat-operator-index:v4.18","publisher":"OpenShift QE","sourceType":"grpc",` +
+				`"updateStrategy":{"registryPoll":{"interval":"15m"}}}}`,
+		},
+	} {
+		out, err := json.Marshal(tc.manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != tc.expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, out)
+		}
+	}
+
+	op.AllNamespaces = true
+	if spec := op.operatorGroupManifest()["spec"]; !reflect.DeepEqual(spec, map[string]interface{}{}) {
+		t.Errorf("Expected the operatorgroup to target all the namespaces, got %v", spec)
+	}
+	if fields := uniqueFields("b.example.com a.example.com b.example.com"); !reflect.DeepEqual(fields, []string{"a.example.com", "b.example.com"}) {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+}
@@ -0,0 +1,493 @@
+package olm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	g "github.com/onsi/ginkgo/v2"
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+const defaultTimeout = 5 * time.Minute
+
+// Operator is an operator installed by OLM with a Subscription. Only the package and the namespace are required,
+// the other fields default to the values of the package in the catalog source.
+type Operator struct {
+	Package   string
+	Namespace string
+	// Name is the operator name in the messages, defaults to the package
+	Name string
+	// SubscriptionName defaults to the package
+	SubscriptionName string
+	// OperatorGroupName defaults to the namespace, the existing operator group of the namespace is reused
+	OperatorGroupName string
+	// AllNamespaces makes the created operator group target all the namespaces instead of the operator namespace
+	AllNamespaces bool
+	// NamespaceLabels and NamespaceAnnotations are set on the namespace if it is created
+	NamespaceLabels      map[string]string
+	NamespaceAnnotations map[string]string
+	// Channel defaults to the default channel of the package
+	Channel string
+	// CatalogSource defaults to the first of PreferredCatalogSources providing the package
+	CatalogSource          string
+	CatalogSourceNamespace string
+	// PreferredCatalogSources defaults to DefaultCatalogSources
+	PreferredCatalogSources []string
+	// CatalogSourceImage creates the catalog source from the index image, i.e. the mirrored index of a disconnected cluster
+	CatalogSourceImage string
+	// StartingCSV pins the installed CSV, set ManualApproval too so OLM does not upgrade it to the channel head
+	StartingCSV    string
+	ManualApproval bool
+	// Config is the spec.config of the subscription, i.e. the nodeSelector of the operator pods
+	Config map[string]interface{}
+	// PodLabel selects the operator pods waited to be ready, no pods are waited if it is empty
+	PodLabel string
+	// SkipWhenUnavailable skips the case instead of failing it if the operator can't be installed
+	SkipWhenUnavailable bool
+	// Timeout is the timeout of every install step, defaults to 5 minutes
+	Timeout time.Duration
+	// KeepCRDs keeps the CRDs of the operator on uninstall, i.e. when their custom resources are shared with other cases
+	KeepCRDs bool
+
+	// created are the objects created by Install and removed by Uninstall
+	createdNamespace     bool
+	createdOperatorGroup bool
+	createdCatalogSource bool
+}
+
+func (op *Operator) setDefaults() {
+	if op.Name == "" {
+		op.Name = op.Package
+	}
+	if op.SubscriptionName == "" {
+		op.SubscriptionName = op.Package
+	}
+	if op.OperatorGroupName == "" {
+		op.OperatorGroupName = op.Namespace
+	}
+	if op.CatalogSourceNamespace == "" {
+		op.CatalogSourceNamespace = MarketplaceNamespace
+	}
+	if op.PreferredCatalogSources == nil {
+		op.PreferredCatalogSources = DefaultCatalogSources
+	}
+	if op.CatalogSourceImage != "" && op.CatalogSource == "" {
+		op.CatalogSource = op.Package + "-catalog"
+	}
+	if op.Timeout == 0 {
+		op.Timeout = defaultTimeout
+	}
+}
+
+// namespaceManifest returns the operator namespace object
+func (op *Operator) namespaceManifest() map[string]interface{} {
+	labels, annotations := map[string]interface{}{}, map[string]interface{}{}
+	for key, value := range op.NamespaceLabels {
+		labels[key] = value
+	}
+	for key, value := range op.NamespaceAnnotations {
+		annotations[key] = value
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": op.Namespace, "labels": labels, "annotations": annotations},
+	}
+}
+
+// operatorGroupManifest returns the operator group object
+func (op *Operator) operatorGroupManifest() map[string]interface{} {
+	spec := map[string]interface{}{}
+	if !op.AllNamespaces {
+		spec["targetNamespaces"] = []string{op.Namespace}
+	}
+	return map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1",
+		"kind":       "OperatorGroup",
+		"metadata":   map[string]interface{}{"name": op.OperatorGroupName, "namespace": op.Namespace},
+		"spec":       spec,
+	}
+}
+
+// subscriptionManifest returns the subscription object
+func (op *Operator) subscriptionManifest() map[string]interface{} {
+	approval := "Automatic"
+	if op.ManualApproval {
+		approval = "Manual"
+	}
+	spec := map[string]interface{}{
+		"name":                op.Package,
+		"channel":             op.Channel,
+		"source":              op.CatalogSource,
+		"sourceNamespace":     op.CatalogSourceNamespace,
+		"installPlanApproval": approval,
+	}
+	if op.StartingCSV != "" {
+		spec["startingCSV"] = op.StartingCSV
+	}
+	if len(op.Config) > 0 {
+		spec["config"] = op.Config
+	}
+	return map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "Subscription",
+		"metadata":   map[string]interface{}{"name": op.SubscriptionName, "namespace": op.Namespace},
+		"spec":       spec,
+	}
+}
+
+// csvLabel selects the CSVs of the operator
+func (op *Operator) csvLabel() string {
+	return "operators.coreos.com/" + op.Package + "." + op.Namespace + "="
+}
+
+// Install installs the operator and waits for its CSV to succeed and its pods to be ready. The case is skipped or
+// failed with the diagnostics of the stalled install if the operator can't be installed.
+func (op *Operator) Install(oc *exutil.CLI) {
+	op.setDefaults()
+	if err := op.install(oc); err != nil {
+		op.fail(oc, err)
+	}
+}
+
+func (op *Operator) install(oc *exutil.CLI) error {
+	e2e.Logf("Installing operator %s from package %s in %s", op.Name, op.Package, op.Namespace)
+	if err := op.ensureNamespace(oc); err != nil {
+		return err
+	}
+	if err := op.ensureOperatorGroup(oc); err != nil {
+		return err
+	}
+	if op.CatalogSourceImage != "" {
+		catalogSource := CatalogSource{Name: op.CatalogSource, Namespace: op.CatalogSourceNamespace, Image: op.CatalogSourceImage}
+		op.createdCatalogSource = true
+		if err := catalogSource.Create(oc); err != nil {
+			return err
+		}
+	}
+
+	exists, err := resourceExists(oc, "subscription", op.SubscriptionName, op.Namespace)
+	if err != nil {
+		return err
+	}
+	if exists {
+		e2e.Logf("subscription/%s already exists in %s", op.SubscriptionName, op.Namespace)
+	} else {
+		if err := op.resolvePackage(oc); err != nil {
+			return err
+		}
+		e2e.Logf("Subscribing to channel %s of package %s from catalogsource %s/%s", op.Channel, op.Package, op.CatalogSourceNamespace, op.CatalogSource)
+		if err := applyManifest(oc, op.subscriptionManifest()); err != nil {
+			return fmt.Errorf("error creating subscription/%s: %v", op.SubscriptionName, err)
+		}
+		if op.ManualApproval {
+			if err := op.approveInstallPlan(oc, op.StartingCSV, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	csv, err := op.waitForInstalledCSV(oc, op.StartingCSV, "")
+	if err != nil {
+		return err
+	}
+	e2e.Logf("Operator %s installed CSV %s", op.Name, csv)
+	if op.PodLabel != "" {
+		return op.waitForPodsReady(oc)
+	}
+	return nil
+}
+
+// resolvePackage sets the catalog source and the channel of the package, and checks the pinned CSV is in the channel
+func (op *Operator) resolvePackage(oc *exutil.CLI) error {
+	manifest, err := WaitForPackageManifest(oc, op.CatalogSourceNamespace, op.Package, op.CatalogSource, op.PreferredCatalogSources, op.Timeout)
+	if err != nil {
+		return err
+	}
+	op.CatalogSource = manifest.CatalogSource
+	if op.Channel == "" {
+		op.Channel = manifest.DefaultChannel
+	}
+	channel, ok := manifest.Channel(op.Channel)
+	if !ok {
+		channels := []string{}
+		for _, c := range manifest.Channels {
+			channels = append(channels, c.Name)
+		}
+		return fmt.Errorf("packagemanifest/%s of catalogsource %s doesn't have channel %s, channels: %v", op.Package, op.CatalogSource, op.Channel, channels)
+	}
+	if op.StartingCSV != "" && !channel.HasCSV(op.StartingCSV) {
+		return fmt.Errorf("channel %s of packagemanifest/%s doesn't have CSV %s", op.Channel, op.Package, op.StartingCSV)
+	}
+	return nil
+}
+
+func (op *Operator) ensureNamespace(oc *exutil.CLI) error {
+	exists, err := resourceExists(oc, "namespace", op.Namespace, "")
+	if err != nil || exists {
+		return err
+	}
+	e2e.Logf("The project %s is not found, create it now...", op.Namespace)
+	op.createdNamespace = true
+	if err := applyManifest(oc, op.namespaceManifest()); err != nil {
+		return fmt.Errorf("error creating namespace %s: %v", op.Namespace, err)
+	}
+	return nil
+}
+
+func (op *Operator) ensureOperatorGroup(oc *exutil.CLI) error {
+	output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("operatorgroup", "-n", op.Namespace, "-o=jsonpath={.items[*].metadata.name}").Output()
+	if err != nil {
+		return fmt.Errorf("error getting the operatorgroups of %s: %v", op.Namespace, err)
+	}
+	if output != "" {
+		e2e.Logf("Using operatorgroup %s of %s", output, op.Namespace)
+		return nil
+	}
+	op.createdOperatorGroup = true
+	if err := applyManifest(oc, op.operatorGroupManifest()); err != nil {
+		return fmt.Errorf("error creating operatorgroup/%s: %v", op.OperatorGroupName, err)
+	}
+	return nil
+}
+
+// installPlanList is the part of the installplan list the library reads
+type installPlanList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Approved                   bool     `json:"approved"`
+			ClusterServiceVersionNames []string `json:"clusterServiceVersionNames"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// pendingInstallPlan returns the unapproved install plan of the CSV, or of any CSV other than the installed one if
+// the CSV is not set. The name is empty if there is no such install plan.
+func pendingInstallPlan(output []byte, csv string, installed string) (string, error) {
+	list := installPlanList{}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return "", fmt.Errorf("error decoding the installplans: %v", err)
+	}
+	for _, plan := range list.Items {
+		if plan.Spec.Approved {
+			continue
+		}
+		for _, name := range plan.Spec.ClusterServiceVersionNames {
+			if (csv != "" && name == csv) || (csv == "" && name != installed) {
+				return plan.Metadata.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// approveInstallPlan waits for the install plan of the CSV, or of any CSV other than the installed one, and approves it
+func (op *Operator) approveInstallPlan(oc *exutil.CLI, csv string, installed string) error {
+	var planName string
+	err := wait.Poll(10*time.Second, op.Timeout, func() (bool, error) {
+		output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("installplan", "-n", op.Namespace, "-o", "json").Output()
+		if err != nil {
+			e2e.Logf("Error getting the installplans of %s: %v, try next round", op.Namespace, err)
+			return false, nil
+		}
+		planName, err = pendingInstallPlan([]byte(output), csv, installed)
+		if err != nil {
+			return false, err
+		}
+		return planName != "", nil
+	})
+	if err != nil {
+		return fmt.Errorf("no installplan to approve for the CSV %q of subscription/%s: %v", csv, op.SubscriptionName, err)
+	}
+	e2e.Logf("Approving installplan/%s of subscription/%s", planName, op.SubscriptionName)
+	return oc.AsAdmin().WithoutNamespace().Run("patch").Args("installplan", planName, "-n", op.Namespace, "--type=merge", "-p", `{"spec":{"approved":true}}`).Execute()
+}
+
+// waitForInstalledCSV waits for the subscription to install the CSV, or any CSV other than the previous one if the CSV
+// is not set, and for the CSV to succeed
+func (op *Operator) waitForInstalledCSV(oc *exutil.CLI, csv string, previous string) (string, error) {
+	var installed, phase string
+	err := wait.Poll(10*time.Second, op.Timeout, func() (bool, error) {
+		var err error
+		installed, err = oc.AsAdmin().WithoutNamespace().Run("get").Args("subscription", op.SubscriptionName, "-n", op.Namespace, "-o=jsonpath={.status.installedCSV}").Output()
+		if err != nil {
+			e2e.Logf("Error getting subscription/%s: %v, try next round", op.SubscriptionName, err)
+			return false, nil
+		}
+		if installed == "" || installed == previous || (csv != "" && installed != csv) {
+			return false, nil
+		}
+		phase, _ = oc.AsAdmin().WithoutNamespace().Run("get").Args("csv", installed, "-n", op.Namespace, "-o=jsonpath={.status.phase}").Output()
+		return phase == "Succeeded", nil
+	})
+	if err != nil {
+		return installed, fmt.Errorf("operator %s is not installed: subscription/%s installed CSV %q in phase %q", op.Name, op.SubscriptionName, installed, phase)
+	}
+	return installed, nil
+}
+
+func (op *Operator) waitForPodsReady(oc *exutil.CLI) error {
+	err := wait.Poll(5*time.Second, op.Timeout, func() (bool, error) {
+		output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args("pod", "-n", op.Namespace, "-l", op.PodLabel,
+			`-o=jsonpath={range .items[*]}{.status.phase}{" "}{.status.containerStatuses[*].ready}{"\n"}{end}`).Output()
+		if err != nil || strings.TrimSpace(output) == "" {
+			e2e.Logf("Waiting for the pods with label %s to appear", op.PodLabel)
+			return false, nil
+		}
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			if !strings.HasPrefix(line, "Running ") || strings.Contains(line, "false") {
+				e2e.Logf("The pods with label %s are not ready: %s", op.PodLabel, output)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("the pods of operator %s with label %s are not ready", op.Name, op.PodLabel)
+	}
+	return nil
+}
+
+// InstalledCSV returns the CSV installed by the subscription
+func (op *Operator) InstalledCSV(oc *exutil.CLI) (string, error) {
+	op.setDefaults()
+	return oc.AsAdmin().WithoutNamespace().Run("get").Args("subscription", op.SubscriptionName, "-n", op.Namespace, "-o=jsonpath={.status.installedCSV}").Output()
+}
+
+// Upgrade upgrades the operator to the next CSV of the channel, switching the subscription to the channel first if it is
+// set, and returns the new CSV. The install plan of the next CSV is approved for the manual approval subscriptions.
+func (op *Operator) Upgrade(oc *exutil.CLI, channel string) string {
+	op.setDefaults()
+	previous, err := op.InstalledCSV(oc)
+	if err != nil {
+		op.fail(oc, fmt.Errorf("error getting the installed CSV of subscription/%s: %v", op.SubscriptionName, err))
+	}
+	if channel != "" && channel != op.Channel {
+		e2e.Logf("Switching subscription/%s to channel %s", op.SubscriptionName, channel)
+		err := oc.AsAdmin().WithoutNamespace().Run("patch").Args("subscription", op.SubscriptionName, "-n", op.Namespace, "--type=merge", "-p", `{"spec":{"channel":"`+channel+`"}}`).Execute()
+		if err != nil {
+			op.fail(oc, fmt.Errorf("error switching subscription/%s to channel %s: %v", op.SubscriptionName, channel, err))
+		}
+		op.Channel = channel
+	}
+	if op.ManualApproval {
+		if err := op.approveInstallPlan(oc, "", previous); err != nil {
+			op.fail(oc, err)
+		}
+	}
+	csv, err := op.waitForInstalledCSV(oc, "", previous)
+	if err != nil {
+		op.fail(oc, err)
+	}
+	e2e.Logf("Operator %s upgraded from %s to %s", op.Name, previous, csv)
+	if op.PodLabel != "" {
+		if err := op.waitForPodsReady(oc); err != nil {
+			op.fail(oc, err)
+		}
+	}
+	return csv
+}
+
+// Uninstall removes the subscription, the CSVs and the CRDs owned by the CSVs of the operator unless KeepCRDs is set,
+// and the namespace, operator group and catalog source created by Install
+func (op *Operator) Uninstall(oc *exutil.CLI) {
+	op.setDefaults()
+	output, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args("csv", "-n", op.Namespace, "-l", op.csvLabel(), "-o=jsonpath={.items[*].spec.customresourcedefinitions.owned[*].name}").Output()
+	crds := uniqueFields(output)
+
+	e2e.Logf("Uninstalling operator %s from %s", op.Name, op.Namespace)
+	deleteResources(oc, "subscription", op.SubscriptionName, "-n", op.Namespace)
+	deleteResources(oc, "csv", "-n", op.Namespace, "-l", op.csvLabel())
+	if len(crds) > 0 && !op.KeepCRDs {
+		deleteResources(oc, append([]string{"crd"}, crds...)...)
+		err := wait.Poll(5*time.Second, op.Timeout, func() (bool, error) {
+			output, _ := oc.AsAdmin().WithoutNamespace().Run("get").Args(append([]string{"crd", "--ignore-not-found", "-o=name"}, crds...)...).Output()
+			return strings.TrimSpace(output) == "", nil
+		})
+		exutil.AssertWaitPollNoErr(err, fmt.Sprintf("the CRDs of operator %s are not deleted: %v", op.Name, crds))
+	}
+	if op.createdOperatorGroup {
+		deleteResources(oc, "operatorgroup", op.OperatorGroupName, "-n", op.Namespace)
+	}
+	if op.createdCatalogSource {
+		deleteResources(oc, "catalogsource", op.CatalogSource, "-n", op.CatalogSourceNamespace)
+	}
+	if op.createdNamespace {
+		deleteResources(oc, "namespace", op.Namespace, "--wait=true")
+	}
+}
+
+// Diagnose returns the status of the objects of the operator install, to tell why the install stalled
+func (op *Operator) Diagnose(oc *exutil.CLI) string {
+	op.setDefaults()
+	get := func(args ...string) string {
+		output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(args...).Output()
+		if err != nil {
+			return fmt.Sprintf("error: %v", err)
+		}
+		return strings.TrimSpace(output)
+	}
+	sections := []string{
+		"subscription state: " + get("subscription", op.SubscriptionName, "-n", op.Namespace, "-o=jsonpath={.status.state}"),
+		"subscription conditions: " + get("subscription", op.SubscriptionName, "-n", op.Namespace, "-o=jsonpath={.status.conditions}"),
+		"installplans:\n" + get("installplan", "-n", op.Namespace, `-o=jsonpath={range .items[*]}{.metadata.name} approved={.spec.approved} phase={.status.phase} csvs={.spec.clusterServiceVersionNames} conditions={.status.conditions}{"\n"}{end}`),
+		"csvs:\n" + get("csv", "-n", op.Namespace, `-o=jsonpath={range .items[*]}{.metadata.name} phase={.status.phase} reason={.status.reason} message={.status.message}{"\n"}{end}`),
+		"catalogsource: " + get("catalogsource", op.CatalogSource, "-n", op.CatalogSourceNamespace, "-o=jsonpath={.status.connectionState}"),
+	}
+	if op.PodLabel != "" {
+		sections = append(sections, "pods:\n"+get("pod", "-n", op.Namespace, "-l", op.PodLabel, `-o=jsonpath={range .items[*]}{.metadata.name} phase={.status.phase} conditions={.status.conditions} containers={.status.containerStatuses}{"\n"}{end}`))
+	}
+	sections = append(sections, "warning events:\n"+get("events", "-n", op.Namespace, "--field-selector=type=Warning", "--sort-by=.lastTimestamp"))
+	return fmt.Sprintf("Diagnostics of operator %s in %s:\n%s", op.Name, op.Namespace, strings.Join(sections, "\n"))
+}
+
+// fail skips or fails the case with the diagnostics of the operator install
+func (op *Operator) fail(oc *exutil.CLI, err error) {
+	e2e.Logf("%s", op.Diagnose(oc))
+	if op.SkipWhenUnavailable {
+		g.Skip(fmt.Sprintf("Skip the case for the operator %s is not available: %v", op.Name, err))
+	}
+	e2e.Failf("can't deploy operator %s: %v", op.Name, err)
+}
+
+// resourceExists returns whether the object exists, the namespace is empty for the cluster scoped objects
+func resourceExists(oc *exutil.CLI, kind string, name string, namespace string) (bool, error) {
+	args := []string{kind, name, "--ignore-not-found", "-o=name"}
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	output, err := oc.AsAdmin().WithoutNamespace().Run("get").Args(args...).Output()
+	if err != nil {
+		return false, fmt.Errorf("error getting %s/%s: %v", kind, name, err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+// deleteResources deletes the objects, the errors are logged since the uninstall removes as much as it can
+func deleteResources(oc *exutil.CLI, args ...string) {
+	if err := oc.AsAdmin().WithoutNamespace().Run("delete").Args(append(args, "--ignore-not-found")...).Execute(); err != nil {
+		e2e.Logf("Error deleting %v: %v", args, err)
+	}
+}
+
+// uniqueFields returns the sorted unique fields of the output
+func uniqueFields(output string) []string {
+	seen := map[string]bool{}
+	fields := []string{}
+	for _, field := range strings.Fields(output) {
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
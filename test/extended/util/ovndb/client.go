@@ -0,0 +1,449 @@
+package ovndb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	ovnNamespace         = "openshift-ovn-kubernetes"
+	ovnkubeNodeLabel     = "app=ovnkube-node"
+	ovnkubeNodeContainer = "northd"
+)
+
+// Database is the OVN database queried by the client
+type Database string
+
+// OVN databases
+const (
+	Northbound Database = "nb"
+	Southbound Database = "sb"
+)
+
+// ctl returns the command line tool used to query the database
+func (d Database) ctl() string {
+	if d == Southbound {
+		return "ovn-sbctl"
+	}
+	return "ovn-nbctl"
+}
+
+// Executor runs a shell command in the pod hosting the OVN databases and returns its stdout
+type Executor func(cmd string) (string, error)
+
+// Client queries the OVN databases of one node. With OVN interconnect every node has its own databases,
+// so the client must be created for the node hosting the resources under test.
+type Client struct {
+	nodeName string
+	podName  string
+	exec     Executor
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// NewClientForNode returns a client for the OVN databases running in the ovnkube-node pod of the given node
+func NewClientForNode(oc *exutil.CLI, nodeName string) (*Client, error) {
+	podName, err := exutil.GetPodName(oc, ovnNamespace, ovnkubeNodeLabel, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if podName == "" {
+		return nil, fmt.Errorf("No ovnkube-node pod found in node %s", nodeName)
+	}
+	client := NewClientWithExecutor(func(cmd string) (string, error) {
+		return exutil.RemoteShPodWithBashSpecifyContainer(oc, ovnNamespace, podName, ovnkubeNodeContainer, cmd)
+	})
+	client.nodeName = nodeName
+	client.podName = podName
+	return client, nil
+}
+
+// NewClientWithExecutor returns a client that runs the database commands using the given executor
+func NewClientWithExecutor(exec Executor) *Client {
+	return &Client{exec: exec, interval: 10 * time.Second, timeout: 2 * time.Minute}
+}
+
+// SetPollInterval sets the interval and the timeout used by the Wait* functions
+func (c *Client) SetPollInterval(interval, timeout time.Duration) *Client {
+	c.interval = interval
+	c.timeout = timeout
+	return c
+}
+
+// String implements the Stringer interface
+func (c *Client) String() string {
+	if c.podName == "" {
+		return "ovndb client"
+	}
+	return fmt.Sprintf("ovndb client for node %s (pod %s)", c.nodeName, c.podName)
+}
+
+// List returns all the rows in the given table
+func (c *Client) List(db Database, table string) ([]Row, error) {
+	return c.run(db, "list", table)
+}
+
+// Find returns the rows in the given table matching all the conditions. Conditions use the ovn-nbctl syntax, i.e. "name=node1" or "external_ids:k8s.ovn.org/owner-type=EgressIP"
+func (c *Client) Find(db Database, table string, conditions ...string) ([]Row, error) {
+	return c.run(db, "find", table, conditions...)
+}
+
+// Get returns the given records of the table, by UUID or by name
+func (c *Client) Get(db Database, table string, records ...string) ([]Row, error) {
+	if len(records) == 0 {
+		return []Row{}, nil
+	}
+	return c.run(db, "list", table, records...)
+}
+
+func (c *Client) run(db Database, command, table string, args ...string) ([]Row, error) {
+	cmd := []string{db.ctl(), "--no-leader-only", "--format=json", command, table}
+	for _, arg := range args {
+		cmd = append(cmd, shellQuote(arg))
+	}
+	output, err := c.exec(strings.Join(cmd, " "))
+	if err != nil {
+		return nil, fmt.Errorf("Error running %s in %s: %s", strings.Join(cmd, " "), c, err)
+	}
+	return DecodeRows([]byte(output))
+}
+
+// shellQuote quotes an argument so it can be passed to bash
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+// LogicalSwitches returns the logical switches in the northbound database
+func (c *Client) LogicalSwitches() ([]LogicalSwitch, error) {
+	rows, err := c.List(Northbound, TableLogicalSwitch)
+	if err != nil {
+		return nil, err
+	}
+	switches := []LogicalSwitch{}
+	for _, row := range rows {
+		switches = append(switches, toLogicalSwitch(row))
+	}
+	return switches, nil
+}
+
+// LogicalSwitch returns the logical switch with the given name, i.e. the node name for the node switches
+func (c *Client) LogicalSwitch(name string) (*LogicalSwitch, error) {
+	rows, err := c.Find(Northbound, TableLogicalSwitch, "name="+name)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("Logical switch %s not found in %s", name, c)
+	}
+	ls := toLogicalSwitch(rows[0])
+	return &ls, nil
+}
+
+// LogicalRouter returns the logical router with the given name, i.e. "ovn_cluster_router"
+func (c *Client) LogicalRouter(name string) (*LogicalRouter, error) {
+	rows, err := c.Find(Northbound, TableLogicalRouter, "name="+name)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("Logical router %s not found in %s", name, c)
+	}
+	router := toLogicalRouter(rows[0])
+	return &router, nil
+}
+
+// LogicalRouterPolicies returns the policies of the given logical router
+func (c *Client) LogicalRouterPolicies(routerName string) ([]LogicalRouterPolicy, error) {
+	router, err := c.LogicalRouter(routerName)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.List(Northbound, TableLogicalRouterPolicy)
+	if err != nil {
+		return nil, err
+	}
+	policies := []LogicalRouterPolicy{}
+	for _, row := range rows {
+		if containsString(router.Policies, row.UUID()) {
+			policies = append(policies, toLogicalRouterPolicy(row))
+		}
+	}
+	return policies, nil
+}
+
+// LogicalRouterPoliciesWithPriority returns the policies of the given logical router with the given priority
+func (c *Client) LogicalRouterPoliciesWithPriority(routerName string, priority int) ([]LogicalRouterPolicy, error) {
+	policies, err := c.LogicalRouterPolicies(routerName)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []LogicalRouterPolicy{}
+	for _, policy := range policies {
+		if policy.Priority == priority {
+			filtered = append(filtered, policy)
+		}
+	}
+	return filtered, nil
+}
+
+// LoadBalancers returns the load balancers in the northbound database
+func (c *Client) LoadBalancers() ([]LoadBalancer, error) {
+	rows, err := c.List(Northbound, TableLoadBalancer)
+	if err != nil {
+		return nil, err
+	}
+	lbs := []LoadBalancer{}
+	for _, row := range rows {
+		lbs = append(lbs, toLoadBalancer(row))
+	}
+	return lbs, nil
+}
+
+// LoadBalancerBackends returns the backends of the given "vip:port" in all the load balancers, without duplicates.
+// IPv6 VIPs use the "[ip]:port" format.
+func (c *Client) LoadBalancerBackends(vip string) ([]string, error) {
+	lbs, err := c.LoadBalancers()
+	if err != nil {
+		return nil, err
+	}
+	backends := []string{}
+	for _, lb := range lbs {
+		for _, backend := range lb.Backends(vip) {
+			if !containsString(backends, backend) {
+				backends = append(backends, backend)
+			}
+		}
+	}
+	return backends, nil
+}
+
+// ACLs returns the ACLs in the northbound database matching the given conditions
+func (c *Client) ACLs(conditions ...string) ([]ACL, error) {
+	rows, err := c.Find(Northbound, TableACL, conditions...)
+	if err != nil {
+		return nil, err
+	}
+	acls := []ACL{}
+	for _, row := range rows {
+		acls = append(acls, toACL(row))
+	}
+	return acls, nil
+}
+
+// ACLsWithName returns the ACLs with the given name, i.e. "NP:namespace:policy:Ingres"
+func (c *Client) ACLsWithName(name string) ([]ACL, error) {
+	return c.ACLs(fmt.Sprintf("name=%q", name))
+}
+
+// ACLsByUUID returns the ACLs with the given UUIDs, i.e. the ACLs of a port group
+func (c *Client) ACLsByUUID(uuids ...string) ([]ACL, error) {
+	rows, err := c.Get(Northbound, TableACL, uuids...)
+	if err != nil {
+		return nil, err
+	}
+	acls := []ACL{}
+	for _, row := range rows {
+		acls = append(acls, toACL(row))
+	}
+	return acls, nil
+}
+
+// ACLsWithExternalID returns the ACLs having the given external id, i.e. "k8s.ovn.org/owner-type" and "NetworkPolicy"
+func (c *Client) ACLsWithExternalID(key, value string) ([]ACL, error) {
+	return c.ACLs(fmt.Sprintf("external_ids:%s=%s", quoteOVSDBKey(key), value))
+}
+
+// NATs returns the NAT rules in the northbound database matching the given conditions
+func (c *Client) NATs(conditions ...string) ([]NAT, error) {
+	rows, err := c.Find(Northbound, TableNAT, conditions...)
+	if err != nil {
+		return nil, err
+	}
+	nats := []NAT{}
+	for _, row := range rows {
+		nats = append(nats, toNAT(row))
+	}
+	return nats, nil
+}
+
+// NATsWithExternalIP returns the NAT rules using the given external IP, i.e. the SNAT rules of an egress IP
+func (c *Client) NATsWithExternalIP(externalIP string) ([]NAT, error) {
+	return c.NATs(fmt.Sprintf("external_ip=%q", externalIP))
+}
+
+// PortGroups returns the port groups in the northbound database matching the given conditions
+func (c *Client) PortGroups(conditions ...string) ([]PortGroup, error) {
+	rows, err := c.Find(Northbound, TablePortGroup, conditions...)
+	if err != nil {
+		return nil, err
+	}
+	groups := []PortGroup{}
+	for _, row := range rows {
+		groups = append(groups, toPortGroup(row))
+	}
+	return groups, nil
+}
+
+// PortGroupsWithACLs returns the port groups containing all the given ACLs
+func (c *Client) PortGroupsWithACLs(aclUUIDs ...string) ([]PortGroup, error) {
+	groups, err := c.PortGroups()
+	if err != nil {
+		return nil, err
+	}
+	filtered := []PortGroup{}
+	for _, group := range groups {
+		containsAll := true
+		for _, uuid := range aclUUIDs {
+			containsAll = containsAll && containsString(group.ACLs, uuid)
+		}
+		if containsAll {
+			filtered = append(filtered, group)
+		}
+	}
+	return filtered, nil
+}
+
+// AddressSets returns the address sets in the northbound database matching the given conditions
+func (c *Client) AddressSets(conditions ...string) ([]AddressSet, error) {
+	rows, err := c.Find(Northbound, TableAddressSet, conditions...)
+	if err != nil {
+		return nil, err
+	}
+	sets := []AddressSet{}
+	for _, row := range rows {
+		sets = append(sets, toAddressSet(row))
+	}
+	return sets, nil
+}
+
+// AddressSet returns the address set with the given name
+func (c *Client) AddressSet(name string) (*AddressSet, error) {
+	sets, err := c.AddressSets("name=" + name)
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("Address set %s not found in %s", name, c)
+	}
+	return &sets[0], nil
+}
+
+// Wait polls the given condition until it returns true or the timeout expires.
+// Errors querying the database are logged and retried, OVN is eventually consistent and the databases may be busy.
+func (c *Client) Wait(description string, condition func() (bool, error)) error {
+	var lastErr error
+	err := wait.Poll(c.interval, c.timeout, func() (bool, error) {
+		done, err := condition()
+		if err != nil {
+			lastErr = err
+			e2e.Logf("%v, waiting for %s to be synced, try next ...", err, description)
+			return false, nil
+		}
+		return done, nil
+	})
+	if err != nil && lastErr != nil {
+		return fmt.Errorf("%s: %s. Last error: %s", description, err, lastErr)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %s", description, err)
+	}
+	return nil
+}
+
+// WaitForLogicalRouterPolicies waits until the number of policies with the given priority in the router is the expected one
+func (c *Client) WaitForLogicalRouterPolicies(routerName string, priority, expected int) ([]LogicalRouterPolicy, error) {
+	var policies []LogicalRouterPolicy
+	err := c.Wait(fmt.Sprintf("%d policies with priority %d in router %s", expected, priority, routerName), func() (bool, error) {
+		var err error
+		policies, err = c.LogicalRouterPoliciesWithPriority(routerName, priority)
+		if err != nil {
+			return false, err
+		}
+		e2e.Logf("Found %d policies with priority %d in router %s", len(policies), priority, routerName)
+		return len(policies) == expected, nil
+	})
+	return policies, err
+}
+
+// WaitForLoadBalancerBackends waits until the backends of the given "vip:port" are exactly the expected ones, in any order
+func (c *Client) WaitForLoadBalancerBackends(vip string, expected ...string) ([]string, error) {
+	var backends []string
+	err := c.Wait(fmt.Sprintf("backends %v for load balancer VIP %s", expected, vip), func() (bool, error) {
+		var err error
+		backends, err = c.LoadBalancerBackends(vip)
+		if err != nil {
+			return false, err
+		}
+		e2e.Logf("Backends for VIP %s: %v", vip, backends)
+		return sameStrings(backends, expected), nil
+	})
+	return backends, err
+}
+
+// WaitForNATs waits until the number of NAT rules with the given external IP is the expected one
+func (c *Client) WaitForNATs(externalIP string, expected int) ([]NAT, error) {
+	var nats []NAT
+	err := c.Wait(fmt.Sprintf("%d NAT rules with external IP %s", expected, externalIP), func() (bool, error) {
+		var err error
+		nats, err = c.NATsWithExternalIP(externalIP)
+		if err != nil {
+			return false, err
+		}
+		return len(nats) == expected, nil
+	})
+	return nats, err
+}
+
+// WaitForAddressSetAddresses waits until the address set contains exactly the expected addresses, in any order
+func (c *Client) WaitForAddressSetAddresses(name string, expected ...string) (*AddressSet, error) {
+	var set *AddressSet
+	err := c.Wait(fmt.Sprintf("addresses %v in address set %s", expected, name), func() (bool, error) {
+		var err error
+		set, err = c.AddressSet(name)
+		if err != nil {
+			return false, err
+		}
+		return sameStrings(set.Addresses, expected), nil
+	})
+	return set, err
+}
+
+// quoteOVSDBKey quotes map keys containing characters that ovn-nbctl would parse as operators, i.e. "k8s.ovn.org/name"
+func quoteOVSDBKey(key string) string {
+	if strings.ContainsAny(key, "./:=") {
+		return fmt.Sprintf("%q", key)
+	}
+	return key
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sameStrings returns true if both lists contain the same elements, ignoring the order
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[string]int{}
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		counts[s]--
+		if counts[s] < 0 {
+			return false
+		}
+	}
+	return true
+}
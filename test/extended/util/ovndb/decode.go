@@ -0,0 +1,191 @@
+package ovndb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Row is a row of an OVN database table as returned by "ovn-nbctl --format=json list <table>".
+// Values are decoded from the OVSDB JSON notation: uuids are strings, sets are []interface{} and maps are map[string]string.
+type Row map[string]interface{}
+
+// tableOutput is the json document returned by ovn-nbctl and ovn-sbctl when using --format=json
+type tableOutput struct {
+	Headings []string            `json:"headings"`
+	Data     [][]json.RawMessage `json:"data"`
+}
+
+// DecodeRows decodes the output of "ovn-nbctl --format=json list|find <table>" into rows indexed by column name
+func DecodeRows(output []byte) ([]Row, error) {
+	table := tableOutput{}
+	if err := json.Unmarshal(output, &table); err != nil {
+		return nil, fmt.Errorf("Error decoding OVN database output: %s. Output: %s", err, output)
+	}
+
+	rows := []Row{}
+	for _, data := range table.Data {
+		if len(data) != len(table.Headings) {
+			return nil, fmt.Errorf("OVN database row has %d values but there are %d headings", len(data), len(table.Headings))
+		}
+		row := Row{}
+		for i, heading := range table.Headings {
+			value, err := decodeValue(data[i])
+			if err != nil {
+				return nil, fmt.Errorf("Error decoding column %s: %s", heading, err)
+			}
+			row[heading] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// decodeValue decodes an OVSDB JSON value: an atom, ["uuid", u], ["named-uuid", u], ["set", [...]] or ["map", [[k, v], ...]]
+func decodeValue(raw json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return convertValue(value)
+}
+
+func convertValue(value interface{}) (interface{}, error) {
+	pair, ok := value.([]interface{})
+	if !ok {
+		return value, nil
+	}
+	if len(pair) != 2 {
+		return nil, fmt.Errorf("unexpected OVSDB value %v", value)
+	}
+	kind, _ := pair[0].(string)
+	switch kind {
+	case "uuid", "named-uuid":
+		return pair[1], nil
+	case "set":
+		elements, ok := pair[1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("wrong OVSDB set %v", value)
+		}
+		set := []interface{}{}
+		for _, element := range elements {
+			converted, err := convertValue(element)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, converted)
+		}
+		return set, nil
+	case "map":
+		entries, ok := pair[1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("wrong OVSDB map %v", value)
+		}
+		m := map[string]string{}
+		for _, entry := range entries {
+			kv, ok := entry.([]interface{})
+			if !ok || len(kv) != 2 {
+				return nil, fmt.Errorf("wrong OVSDB map entry %v", entry)
+			}
+			k, err := convertValue(kv[0])
+			if err != nil {
+				return nil, err
+			}
+			v, err := convertValue(kv[1])
+			if err != nil {
+				return nil, err
+			}
+			m[atomToString(k)] = atomToString(v)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown OVSDB value type %q", kind)
+}
+
+// String returns the column as a string. Empty optional columns return an empty string.
+func (r Row) String(column string) string {
+	switch v := r[column].(type) {
+	case []interface{}:
+		if len(v) == 1 {
+			return atomToString(v[0])
+		}
+		return ""
+	case nil:
+		return ""
+	default:
+		return atomToString(v)
+	}
+}
+
+// Int returns the column as an integer. Empty optional columns return 0.
+func (r Row) Int(column string) int {
+	switch v := r[column].(type) {
+	case float64:
+		return int(v)
+	case []interface{}:
+		if len(v) == 1 {
+			if f, ok := v[0].(float64); ok {
+				return int(f)
+			}
+		}
+	}
+	return 0
+}
+
+// Bool returns the column as a boolean. Empty optional columns return false.
+func (r Row) Bool(column string) bool {
+	switch v := r[column].(type) {
+	case bool:
+		return v
+	case []interface{}:
+		if len(v) == 1 {
+			b, _ := v[0].(bool)
+			return b
+		}
+	}
+	return false
+}
+
+// Strings returns a set column as a sorted list of strings. Sets with a single element are returned by OVSDB as the element itself.
+func (r Row) Strings(column string) []string {
+	values := []string{}
+	switch v := r[column].(type) {
+	case []interface{}:
+		for _, element := range v {
+			values = append(values, atomToString(element))
+		}
+	case nil:
+	default:
+		values = append(values, atomToString(v))
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Map returns a map column. It never returns nil.
+func (r Row) Map(column string) map[string]string {
+	if m, ok := r[column].(map[string]string); ok {
+		return m
+	}
+	return map[string]string{}
+}
+
+// UUID returns the _uuid column of the row
+func (r Row) UUID() string {
+	return r.String("_uuid")
+}
+
+func atomToString(atom interface{}) string {
+	switch v := atom.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", atom)
+}
@@ -0,0 +1,177 @@
+package ovndb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	routerOutput = `{"data":[[["uuid","6f2ed8a6-5ef1-4a4b-9e59-3d3a4a4f2b10"],["set",[]],"ovn_cluster_router",["map",[["always_learn_from_arp_request","false"]]],["set",[["uuid","11111111-0000-0000-0000-000000000001"],["uuid","11111111-0000-0000-0000-000000000002"]]],["uuid","11111111-0000-0000-0000-000000000003"]]],"headings":["_uuid","nat","name","options","policies","ports"]}`
+	policyOutput = `{"data":[
+		[["uuid","11111111-0000-0000-0000-000000000001"],"reroute",["map",[["k8s.ovn.org/name","egressip1"]]],"ip4.src == 10.128.2.5",["set",["100.64.0.2","100.64.0.3"]],102],
+		[["uuid","11111111-0000-0000-0000-000000000002"],"allow",["map",[]],"ip4.src == 10.128.0.0/14 && ip4.dst == 10.128.0.0/14",["set",[]],101],
+		[["uuid","11111111-0000-0000-0000-000000000009"],"reroute",["map",[]],"ip4.src == 10.131.0.9","100.64.0.4",102]],
+		"headings":["_uuid","action","external_ids","match","nexthops","priority"]}`
+	lbOutput = `{"data":[
+		[["uuid","22222222-0000-0000-0000-000000000001"],"Service_ns1/svc1_TCP_cluster","tcp",["map",[["172.30.10.10:27017","10.128.2.10:8080,10.131.0.11:8080"],["[fd02::a]:27017","[fd01:0:0:5::a]:8080"]]]],
+		[["uuid","22222222-0000-0000-0000-000000000002"],"Service_ns1/svc1_TCP_node_router","tcp",["map",[["172.30.10.10:27017","10.128.2.10:8080"]]]]],
+		"headings":["_uuid","name","protocol","vips"]}`
+	portGroupOutput = `{"data":[
+		[["uuid","33333333-0000-0000-0000-000000000001"],["set",[["uuid","44444444-0000-0000-0000-000000000001"],["uuid","44444444-0000-0000-0000-000000000002"]]],["map",[["name","ns1_allow-same-namespace"]]],"a13600453473441457911"],
+		[["uuid","33333333-0000-0000-0000-000000000002"],["uuid","44444444-0000-0000-0000-000000000003"],["map",[]],"clusterPortGroup"]],
+		"headings":["_uuid","acls","external_ids","name"]}`
+	aclOutput = `{"data":[
+		[["uuid","44444444-0000-0000-0000-000000000001"],"allow-related","to-lport","acl-meter","NP:ns1:allow-same-namespace:Ingress:0","ip4.src == {$a10148211500778908391}",1001]],
+		"headings":["_uuid","action","direction","meter","name","match","priority"]}`
+)
+
+// fakeExecutor returns the given output for commands on the given table
+func fakeExecutor(t *testing.T, outputs map[string]string, commands *[]string) Executor {
+	return func(cmd string) (string, error) {
+		*commands = append(*commands, cmd)
+		fields := strings.Fields(cmd)
+		if len(fields) < 5 {
+			t.Fatalf("Unexpected command %s", cmd)
+		}
+		output, ok := outputs[fields[4]]
+		if !ok {
+			return "", fmt.Errorf("table %s not found", fields[4])
+		}
+		return output, nil
+	}
+}
+
+func TestDecodeRows(t *testing.T) {
+	rows, err := DecodeRows([]byte(routerOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(rows))
+	}
+	router := toLogicalRouter(rows[0])
+	expected := LogicalRouter{
+		UUID:          "6f2ed8a6-5ef1-4a4b-9e59-3d3a4a4f2b10",
+		Name:          "ovn_cluster_router",
+		Ports:         []string{"11111111-0000-0000-0000-000000000003"},
+		Policies:      []string{"11111111-0000-0000-0000-000000000001", "11111111-0000-0000-0000-000000000002"},
+		NAT:           []string{},
+		LoadBalancers: []string{},
+		Options:       map[string]string{"always_learn_from_arp_request": "false"},
+		ExternalIDs:   map[string]string{},
+	}
+	if !reflect.DeepEqual(router, expected) {
+		t.Errorf("Wrong router decoded.\nExpected: %+v\nGot:      %+v", expected, router)
+	}
+
+	if _, err := DecodeRows([]byte(`{"data":[["a"]],"headings":["a","b"]}`)); err == nil {
+		t.Errorf("Expected error decoding a row with missing columns")
+	}
+	if _, err := DecodeRows([]byte(`{"data":[[["unknown","a"]]],"headings":["a"]}`)); err == nil {
+		t.Errorf("Expected error decoding an unknown value type")
+	}
+}
+
+func TestLogicalRouterPolicies(t *testing.T) {
+	commands := []string{}
+	client := NewClientWithExecutor(fakeExecutor(t, map[string]string{
+		TableLogicalRouter:       routerOutput,
+		TableLogicalRouterPolicy: policyOutput,
+	}, &commands))
+
+	policies, err := client.LogicalRouterPoliciesWithPriority("ovn_cluster_router", 102)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The third policy has priority 102 but it belongs to another router
+	if len(policies) != 1 {
+		t.Fatalf("Expected 1 policy, got %+v", policies)
+	}
+	policy := policies[0]
+	if policy.Action != "reroute" || policy.Match != "ip4.src == 10.128.2.5" || !reflect.DeepEqual(policy.Nexthops, []string{"100.64.0.2", "100.64.0.3"}) {
+		t.Errorf("Wrong policy decoded: %+v", policy)
+	}
+	if policy.ExternalIDs["k8s.ovn.org/name"] != "egressip1" {
+		t.Errorf("Wrong external ids decoded: %+v", policy.ExternalIDs)
+	}
+	if commands[0] != "ovn-nbctl --no-leader-only --format=json find Logical_Router 'name=ovn_cluster_router'" {
+		t.Errorf("Unexpected command: %s", commands[0])
+	}
+}
+
+func TestLoadBalancerBackends(t *testing.T) {
+	commands := []string{}
+	client := NewClientWithExecutor(fakeExecutor(t, map[string]string{TableLoadBalancer: lbOutput}, &commands))
+
+	backends, err := client.LoadBalancerBackends("172.30.10.10:27017")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(backends, []string{"10.128.2.10:8080", "10.131.0.11:8080"}) {
+		t.Errorf("Wrong IPv4 backends: %v", backends)
+	}
+	backends, err = client.LoadBalancerBackends("[fd02::a]:27017")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(backends, []string{"[fd01:0:0:5::a]:8080"}) {
+		t.Errorf("Wrong IPv6 backends: %v", backends)
+	}
+
+	client.SetPollInterval(10*time.Millisecond, 50*time.Millisecond)
+	if _, err := client.WaitForLoadBalancerBackends("172.30.10.10:27017", "10.131.0.11:8080", "10.128.2.10:8080"); err != nil {
+		t.Errorf("Backends in any order should match: %v", err)
+	}
+	if _, err := client.WaitForLoadBalancerBackends("172.30.10.10:27017", "10.128.2.10:8080"); err == nil {
+		t.Errorf("Expected timeout waiting for the wrong backends")
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	commands := []string{}
+	client := NewClientWithExecutor(fakeExecutor(t, map[string]string{}, &commands)).SetPollInterval(10*time.Millisecond, 30*time.Millisecond)
+
+	if _, err := client.AddressSet("a1234"); err == nil {
+		t.Errorf("Expected error querying a failing database")
+	}
+	err := client.Wait("address set", func() (bool, error) {
+		_, err := client.AddressSet("a1234")
+		return err == nil, err
+	})
+	if err == nil || !strings.Contains(err.Error(), "table Address_Set not found") {
+		t.Errorf("The last query error should be reported: %v", err)
+	}
+	if commands[0] != "ovn-nbctl --no-leader-only --format=json find Address_Set 'name=a1234'" {
+		t.Errorf("Unexpected command: %s", commands[0])
+	}
+}
+
+func TestPortGroupACLs(t *testing.T) {
+	commands := []string{}
+	client := NewClientWithExecutor(fakeExecutor(t, map[string]string{TablePortGroup: portGroupOutput, TableACL: aclOutput}, &commands))
+
+	groups, err := client.PortGroupsWithACLs("44444444-0000-0000-0000-000000000001", "44444444-0000-0000-0000-000000000002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Name != "a13600453473441457911" || groups[0].ExternalIDs["name"] != "ns1_allow-same-namespace" {
+		t.Fatalf("Wrong port groups with both ACLs: %+v", groups)
+	}
+
+	acls, err := client.ACLsByUUID(groups[0].ACLs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acls) != 1 || acls[0].Meter != "acl-meter" || acls[0].Priority != 1001 || acls[0].Direction != "to-lport" {
+		t.Errorf("Wrong ACLs decoded: %+v", acls)
+	}
+	if commands[len(commands)-1] != "ovn-nbctl --no-leader-only --format=json list ACL '44444444-0000-0000-0000-000000000001'" {
+		t.Errorf("Unexpected command: %s", commands[len(commands)-1])
+	}
+	if acls, err := client.ACLsByUUID(); err != nil || len(acls) != 0 {
+		t.Errorf("No ACLs expected without UUIDs, got %+v, %v", acls, err)
+	}
+}
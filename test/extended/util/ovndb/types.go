@@ -0,0 +1,217 @@
+package ovndb
+
+import (
+	"strings"
+)
+
+// Tables decoded by the client
+const (
+	TableLogicalSwitch       = "Logical_Switch"
+	TableLogicalRouter       = "Logical_Router"
+	TableLogicalRouterPolicy = "Logical_Router_Policy"
+	TableLoadBalancer        = "Load_Balancer"
+	TableACL                 = "ACL"
+	TableNAT                 = "NAT"
+	TableAddressSet          = "Address_Set"
+	TablePortGroup           = "Port_Group"
+)
+
+// LogicalSwitch is a row of the Logical_Switch table
+type LogicalSwitch struct {
+	UUID          string
+	Name          string
+	Ports         []string
+	ACLs          []string
+	LoadBalancers []string
+	OtherConfig   map[string]string
+	ExternalIDs   map[string]string
+}
+
+// LogicalRouter is a row of the Logical_Router table
+type LogicalRouter struct {
+	UUID          string
+	Name          string
+	Ports         []string
+	Policies      []string
+	NAT           []string
+	LoadBalancers []string
+	Options       map[string]string
+	ExternalIDs   map[string]string
+}
+
+// LogicalRouterPolicy is a row of the Logical_Router_Policy table
+type LogicalRouterPolicy struct {
+	UUID        string
+	Priority    int
+	Match       string
+	Action      string
+	Nexthops    []string
+	Options     map[string]string
+	ExternalIDs map[string]string
+}
+
+// LoadBalancer is a row of the Load_Balancer table
+type LoadBalancer struct {
+	UUID     string
+	Name     string
+	Protocol string
+	// VIPs maps "vip:port" to the comma separated list of backends
+	VIPs        map[string]string
+	Options     map[string]string
+	ExternalIDs map[string]string
+}
+
+// Backends returns the backends configured for the given "vip:port". IPv6 VIPs use the "[ip]:port" format.
+func (lb LoadBalancer) Backends(vip string) []string {
+	backends := []string{}
+	value, ok := lb.VIPs[vip]
+	if !ok || value == "" {
+		return backends
+	}
+	for _, backend := range strings.Split(value, ",") {
+		if backend = strings.TrimSpace(backend); backend != "" {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
+
+// ACL is a row of the ACL table
+type ACL struct {
+	UUID        string
+	Name        string
+	Priority    int
+	Direction   string
+	Match       string
+	Action      string
+	Log         bool
+	Severity    string
+	Tier        int
+	Meter       string
+	Options     map[string]string
+	ExternalIDs map[string]string
+}
+
+// NAT is a row of the NAT table
+type NAT struct {
+	UUID        string
+	Type        string
+	ExternalIP  string
+	LogicalIP   string
+	LogicalPort string
+	Options     map[string]string
+	ExternalIDs map[string]string
+}
+
+// PortGroup is a row of the Port_Group table
+type PortGroup struct {
+	UUID        string
+	Name        string
+	Ports       []string
+	ACLs        []string
+	ExternalIDs map[string]string
+}
+
+// AddressSet is a row of the Address_Set table
+type AddressSet struct {
+	UUID        string
+	Name        string
+	Addresses   []string
+	ExternalIDs map[string]string
+}
+
+func toLogicalSwitch(row Row) LogicalSwitch {
+	return LogicalSwitch{
+		UUID:          row.UUID(),
+		Name:          row.String("name"),
+		Ports:         row.Strings("ports"),
+		ACLs:          row.Strings("acls"),
+		LoadBalancers: row.Strings("load_balancer"),
+		OtherConfig:   row.Map("other_config"),
+		ExternalIDs:   row.Map("external_ids"),
+	}
+}
+
+func toLogicalRouter(row Row) LogicalRouter {
+	return LogicalRouter{
+		UUID:          row.UUID(),
+		Name:          row.String("name"),
+		Ports:         row.Strings("ports"),
+		Policies:      row.Strings("policies"),
+		NAT:           row.Strings("nat"),
+		LoadBalancers: row.Strings("load_balancer"),
+		Options:       row.Map("options"),
+		ExternalIDs:   row.Map("external_ids"),
+	}
+}
+
+func toLogicalRouterPolicy(row Row) LogicalRouterPolicy {
+	return LogicalRouterPolicy{
+		UUID:        row.UUID(),
+		Priority:    row.Int("priority"),
+		Match:       row.String("match"),
+		Action:      row.String("action"),
+		Nexthops:    row.Strings("nexthops"),
+		Options:     row.Map("options"),
+		ExternalIDs: row.Map("external_ids"),
+	}
+}
+
+func toLoadBalancer(row Row) LoadBalancer {
+	return LoadBalancer{
+		UUID:        row.UUID(),
+		Name:        row.String("name"),
+		Protocol:    row.String("protocol"),
+		VIPs:        row.Map("vips"),
+		Options:     row.Map("options"),
+		ExternalIDs: row.Map("external_ids"),
+	}
+}
+
+func toACL(row Row) ACL {
+	return ACL{
+		UUID:        row.UUID(),
+		Name:        row.String("name"),
+		Priority:    row.Int("priority"),
+		Direction:   row.String("direction"),
+		Match:       row.String("match"),
+		Action:      row.String("action"),
+		Log:         row.Bool("log"),
+		Severity:    row.String("severity"),
+		Tier:        row.Int("tier"),
+		Meter:       row.String("meter"),
+		Options:     row.Map("options"),
+		ExternalIDs: row.Map("external_ids"),
+	}
+}
+
+func toNAT(row Row) NAT {
+	return NAT{
+		UUID:        row.UUID(),
+		Type:        row.String("type"),
+		ExternalIP:  row.String("external_ip"),
+		LogicalIP:   row.String("logical_ip"),
+		LogicalPort: row.String("logical_port"),
+		Options:     row.Map("options"),
+		ExternalIDs: row.Map("external_ids"),
+	}
+}
+
+func toPortGroup(row Row) PortGroup {
+	return PortGroup{
+		UUID:        row.UUID(),
+		Name:        row.String("name"),
+		Ports:       row.Strings("ports"),
+		ACLs:        row.Strings("acls"),
+		ExternalIDs: row.Map("external_ids"),
+	}
+}
+
+func toAddressSet(row Row) AddressSet {
+	return AddressSet{
+		UUID:        row.UUID(),
+		Name:        row.String("name"),
+		Addresses:   row.Strings("addresses"),
+		ExternalIDs: row.Map("external_ids"),
+	}
+}
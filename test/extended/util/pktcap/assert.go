@@ -0,0 +1,133 @@
+package pktcap
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Packets is a list of captured packets with filter and assertion helpers
+type Packets []Packet
+
+// Filter returns the packets matching the condition
+func (ps Packets) Filter(match func(p Packet) bool) Packets {
+	filtered := Packets{}
+	for _, p := range ps {
+		if match(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// To returns the packets sent to the given IP. Inner packets of tunnels are checked too.
+func (ps Packets) To(ip string) Packets {
+	return ps.Filter(func(p Packet) bool { return p.anyLevel(func(l Packet) bool { return l.DstIP == ip }) })
+}
+
+// From returns the packets sent from the given IP. Inner packets of tunnels are checked too.
+func (ps Packets) From(ip string) Packets {
+	return ps.Filter(func(p Packet) bool { return p.anyLevel(func(l Packet) bool { return l.SrcIP == ip }) })
+}
+
+// Between returns the packets sent in any direction between the given IPs, i.e. the node IPs of an IPsec tunnel
+func (ps Packets) Between(ip1, ip2 string) Packets {
+	return ps.Filter(func(p Packet) bool {
+		return p.SrcIP == ip1 && p.DstIP == ip2 || p.SrcIP == ip2 && p.DstIP == ip1
+	})
+}
+
+// WithProtocol returns the packets whose outer protocol is the given one
+func (ps Packets) WithProtocol(protocol string) Packets {
+	return ps.Filter(func(p Packet) bool { return p.Protocol == protocol })
+}
+
+// WithPort returns the packets using the given source or destination port in the innermost packet
+func (ps Packets) WithPort(port int) Packets {
+	return ps.Filter(func(p Packet) bool {
+		inner := p.Innermost()
+		return inner.SrcPort == port || inner.DstPort == port
+	})
+}
+
+// SourceIPs returns the different source IPs of the innermost packets
+func (ps Packets) SourceIPs() []string {
+	ips := sets.New[string]()
+	for _, p := range ps {
+		ips.Insert(p.Innermost().SrcIP)
+	}
+	return sets.List(ips)
+}
+
+// Groups returns the multicast groups joined or left in the IGMP and MLD messages
+func (ps Packets) Groups() []string {
+	groups := sets.New[string]()
+	for _, p := range ps {
+		groups.Insert(p.Innermost().Groups...)
+	}
+	return sets.List(groups)
+}
+
+// String returns a packet per line, like the text output of tcpdump
+func (ps Packets) String() string {
+	lines := []string{}
+	for _, p := range ps {
+		lines = append(lines, p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AllEncrypted returns an error if there are no packets between the two IPs, or if any of them is not ESP
+func (ps Packets) AllEncrypted(ip1, ip2 string) error {
+	between := ps.Between(ip1, ip2)
+	if len(between) == 0 {
+		return fmt.Errorf("No packets captured between %s and %s", ip1, ip2)
+	}
+	plain := between.Filter(func(p Packet) bool { return p.Protocol != ProtocolESP })
+	if len(plain) > 0 {
+		return fmt.Errorf("%d of %d packets between %s and %s are not ESP encrypted:\n%s", len(plain), len(between), ip1, ip2, plain)
+	}
+	return nil
+}
+
+// NoneEncrypted returns an error if any packet between the two IPs is ESP encrypted
+func (ps Packets) NoneEncrypted(ip1, ip2 string) error {
+	encrypted := ps.Between(ip1, ip2).WithProtocol(ProtocolESP)
+	if len(encrypted) > 0 {
+		return fmt.Errorf("%d packets between %s and %s are ESP encrypted:\n%s", len(encrypted), ip1, ip2, encrypted)
+	}
+	return nil
+}
+
+// SourceIPIs returns an error if there are no packets to the destination IP, or if any of them does not use the expected
+// source IP, i.e. the EgressIP assigned to the pod
+func (ps Packets) SourceIPIs(dstIP, expectedSrcIP string) error {
+	to := ps.To(dstIP)
+	if len(to) == 0 {
+		return fmt.Errorf("No packets captured to %s", dstIP)
+	}
+	wrong := to.Filter(func(p Packet) bool { return p.Innermost().SrcIP != expectedSrcIP })
+	if len(wrong) > 0 {
+		return fmt.Errorf("%d of %d packets to %s do not use source IP %s:\n%s", len(wrong), len(to), dstIP, expectedSrcIP, wrong)
+	}
+	return nil
+}
+
+// JoinedGroup returns an error if no IGMP or MLD message for the multicast group was captured
+func (ps Packets) JoinedGroup(group string) error {
+	if !sets.New(ps.Groups()...).Has(group) {
+		return fmt.Errorf("No IGMP or MLD messages captured for group %s. Groups found: %v", group, ps.Groups())
+	}
+	return nil
+}
+
+// anyLevel returns true if the condition is true for the packet or any of its inner packets
+func (p Packet) anyLevel(condition func(Packet) bool) bool {
+	for level := &p; level != nil; level = level.Inner {
+		if condition(*level) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package pktcap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	defaultInterface  = "any"
+	defaultDuration   = 60 * time.Second
+	defaultMaxPackets = 1000
+	// headers of tunneled packets fit in 256 bytes, the payload is not needed
+	defaultSnapLen = 256
+	// the pcap file is written in the host so it survives the debug pod running tcpdump
+	captureDir = "/var/tmp"
+)
+
+// Capture is a bounded tcpdump capture running in a node. It stops after the duration or the number of packets,
+// whatever happens first, so a failed test never leaves tcpdump running.
+type Capture struct {
+	oc         *exutil.CLI
+	nodeName   string
+	iface      string
+	filter     string
+	duration   time.Duration
+	maxPackets int
+	snapLen    int
+	file       string
+	cmd        *exec.Cmd
+	output     *exutil.SynchronizedBuffer
+	done       chan struct{}
+}
+
+// NewCapture returns a capture of all the interfaces of the node. Use the setters to configure it before calling Start.
+func NewCapture(oc *exutil.CLI, nodeName string) *Capture {
+	return &Capture{
+		oc:         oc,
+		nodeName:   nodeName,
+		iface:      defaultInterface,
+		duration:   defaultDuration,
+		maxPackets: defaultMaxPackets,
+		snapLen:    defaultSnapLen,
+		file:       fmt.Sprintf("%s/pktcap-%s.pcap", captureDir, exutil.GetRandomString()),
+	}
+}
+
+// SetInterface sets the interface to capture, i.e. "br-ex" or "genev_sys_6081"
+func (c *Capture) SetInterface(iface string) *Capture {
+	c.iface = iface
+	return c
+}
+
+// SetFilter sets the BPF filter, i.e. "esp or udp port 4500"
+func (c *Capture) SetFilter(filter string) *Capture {
+	c.filter = filter
+	return c
+}
+
+// SetDuration sets the maximum duration of the capture
+func (c *Capture) SetDuration(duration time.Duration) *Capture {
+	c.duration = duration
+	return c
+}
+
+// SetMaxPackets sets the maximum number of packets to capture
+func (c *Capture) SetMaxPackets(maxPackets int) *Capture {
+	c.maxPackets = maxPackets
+	return c
+}
+
+// SetSnapLen sets the number of bytes captured per packet
+func (c *Capture) SetSnapLen(snapLen int) *Capture {
+	c.snapLen = snapLen
+	return c
+}
+
+// String implements the Stringer interface
+func (c *Capture) String() string {
+	return fmt.Sprintf("capture %s on node %s interface %s filter %q", c.file, c.nodeName, c.iface, c.filter)
+}
+
+// tcpdumpCommand returns the tcpdump command line. -U flushes every packet so the file can be read while tcpdump runs.
+func (c *Capture) tcpdumpCommand() string {
+	cmd := fmt.Sprintf("timeout %d tcpdump -nn -U -i %s -s %d -c %d -w /host%s",
+		int(c.duration.Seconds()), c.iface, c.snapLen, c.maxPackets, c.file)
+	if c.filter != "" {
+		cmd += " '" + strings.ReplaceAll(c.filter, "'", `'"'"'`) + "'"
+	}
+	return cmd
+}
+
+// Start starts tcpdump in the node and waits until it is capturing
+func (c *Capture) Start() error {
+	if c.cmd != nil {
+		return fmt.Errorf("The %s is already started", c)
+	}
+	e2e.Logf("Starting %s", c)
+	// the tcpdump messages are redirected to stdout, which is read by a goroutine while the debug session runs
+	cmd, stdout, err := c.oc.AsAdmin().WithoutNamespace().Run("debug").Args("-n", "default", "node/"+c.nodeName, "--", "bash", "-c", c.tcpdumpCommand()+" 2>&1").BackgroundRC()
+	if err != nil {
+		return err
+	}
+	c.cmd = cmd
+	c.output = exutil.NewSynchronizedBuffer()
+	c.done = make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			fmt.Fprintln(c.output, scanner.Text())
+		}
+		// the pipe must be read to the end before waiting for the command
+		cmd.Wait()
+		close(c.done)
+	}()
+
+	// tcpdump prints "listening on <iface>" once the capture is ready
+	err = wait.Poll(2*time.Second, 2*time.Minute, func() (bool, error) {
+		if strings.Contains(c.output.String(), "listening on") {
+			return true, nil
+		}
+		if c.finished() {
+			return false, fmt.Errorf("tcpdump exited before starting the capture: %s", c.output.String())
+		}
+		return false, nil
+	})
+	if err != nil {
+		// the capture may still start later, so tcpdump is killed instead of letting it run until its timeout
+		c.kill()
+	}
+	return err
+}
+
+// kill interrupts tcpdump in the node and ends the debug session running it. Killing only the local "oc debug" process
+// does not delete the debug pod, so tcpdump is interrupted in the node first, which also flushes the pcap file.
+func (c *Capture) kill() {
+	if c.finished() {
+		return
+	}
+	// the pcap file name is unique, pkill exits with 1 if tcpdump already exited
+	_, _, err := exutil.DebugNodeWithOptionsAndChrootWithoutRecoverNsLabel(c.oc, c.nodeName, []string{"--to-namespace=default"}, "pkill", "-INT", "-f", c.file)
+	if err != nil {
+		e2e.Logf("Error interrupting tcpdump of %s: %v", c, err)
+	}
+	select {
+	case <-c.done:
+	case <-time.After(30 * time.Second):
+		c.cmd.Process.Kill()
+		<-c.done
+	}
+}
+
+// finished returns true if the debug session running tcpdump has exited
+func (c *Capture) finished() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop stops tcpdump, if it is still running, and returns the captured packets. The pcap file is removed from the node.
+func (c *Capture) Stop() (Packets, error) {
+	if c.cmd == nil {
+		return nil, fmt.Errorf("The %s is not started", c)
+	}
+	// give tcpdump a chance to write the last packets before killing it
+	if !c.finished() {
+		time.Sleep(2 * time.Second)
+	}
+	c.kill()
+	e2e.Logf("Stopped %s: %s", c, c.output.String())
+	defer c.cleanup()
+	return c.Packets()
+}
+
+// Packets retrieves the pcap file from the node and parses it. It can be called while the capture is running.
+func (c *Capture) Packets() (Packets, error) {
+	stdout, stderr, err := exutil.DebugNodeWithOptionsAndChrootWithoutRecoverNsLabel(c.oc, c.nodeName, []string{"--to-namespace=default"}, "bash", "-c", "base64 -w0 "+c.file+"; echo")
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving %s: %s %s", c, err, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding %s: %s", c, err)
+	}
+	packets, err := ParsePcap(bytes.NewReader(data))
+	if err != nil {
+		return packets, err
+	}
+	e2e.Logf("Captured %d packets in %s", len(packets), c)
+	return packets, nil
+}
+
+func (c *Capture) cleanup() {
+	_, _, err := exutil.DebugNodeWithOptionsAndChrootWithoutRecoverNsLabel(c.oc, c.nodeName, []string{"--to-namespace=default"}, "rm", "-f", c.file)
+	if err != nil {
+		e2e.Logf("Error removing %s: %v", c, err)
+	}
+}
+
+// CaptureWhile captures packets while the action runs. The capture is always stopped, and the action error is returned first.
+func CaptureWhile(capture *Capture, action func() error) (Packets, error) {
+	if err := capture.Start(); err != nil {
+		// stop anyway so the pcap file is removed from the node
+		if capture.cmd != nil {
+			if _, stopErr := capture.Stop(); stopErr != nil {
+				e2e.Logf("Error stopping %s: %v", capture, stopErr)
+			}
+		}
+		return nil, err
+	}
+	actionErr := action()
+	packets, err := capture.Stop()
+	if actionErr != nil {
+		return packets, actionErr
+	}
+	return packets, err
+}
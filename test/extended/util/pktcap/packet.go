@@ -0,0 +1,196 @@
+package pktcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// Protocols reported in the packet records
+const (
+	ProtocolTCP    = "TCP"
+	ProtocolUDP    = "UDP"
+	ProtocolSCTP   = "SCTP"
+	ProtocolICMP   = "ICMP"
+	ProtocolICMPv6 = "ICMPv6"
+	ProtocolESP    = "ESP"
+	ProtocolIGMP   = "IGMP"
+	ProtocolMLD    = "MLD"
+)
+
+// Encapsulations reported in the packet records
+const (
+	EncapGeneve = "GENEVE"
+	EncapVXLAN  = "VXLAN"
+)
+
+// ipsecNATTPort is the UDP port used by IPsec when NAT traversal is enabled, ESP packets are sent inside UDP
+const ipsecNATTPort = 4500
+
+// Packet is a decoded packet. Tunneled packets have the decoded inner packet in Inner.
+type Packet struct {
+	Timestamp time.Time
+	// Length is the original length of the packet, the capture may be truncated by the snap length
+	Length  int
+	Family  string
+	SrcIP   string
+	DstIP   string
+	SrcPort int
+	DstPort int
+	// Protocol is the transport protocol, or ESP when the payload is encrypted
+	Protocol string
+	// SPI is the security parameter index of ESP packets
+	SPI uint32
+	// Groups contains the multicast groups of IGMP and MLD messages
+	Groups []string
+	// Encapsulation is GENEVE or VXLAN for tunnel packets, VNI is the tunnel network identifier
+	Encapsulation string
+	VNI           uint32
+	Inner         *Packet
+}
+
+// String returns a one line summary of the packet similar to tcpdump's
+func (p Packet) String() string {
+	s := fmt.Sprintf("%s %s", p.Timestamp.Format("15:04:05.000000"), p.Protocol)
+	if p.SrcPort != 0 || p.DstPort != 0 {
+		s += fmt.Sprintf(" %s:%d > %s:%d", p.SrcIP, p.SrcPort, p.DstIP, p.DstPort)
+	} else {
+		s += fmt.Sprintf(" %s > %s", p.SrcIP, p.DstIP)
+	}
+	if p.Protocol == ProtocolESP {
+		s += fmt.Sprintf(" spi=0x%x", p.SPI)
+	}
+	if len(p.Groups) > 0 {
+		s += fmt.Sprintf(" groups=%v", p.Groups)
+	}
+	if p.Inner != nil {
+		s += fmt.Sprintf(" %s vni=%d [%s]", p.Encapsulation, p.VNI, p.Inner.String())
+	}
+	return s
+}
+
+// Innermost returns the packet carried by all the tunnels
+func (p Packet) Innermost() Packet {
+	for p.Inner != nil {
+		p = *p.Inner
+	}
+	return p
+}
+
+// ParsePcap decodes all the IP packets in a pcap file. Packets without an IP layer, i.e. ARP, are skipped.
+func ParsePcap(r io.Reader) (Packets, error) {
+	reader, err := pcapgo.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading pcap file: %s", err)
+	}
+	packets := Packets{}
+	for {
+		data, ci, err := reader.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// tcpdump may be killed in the middle of a packet when the capture is stopped
+			if err == io.ErrUnexpectedEOF {
+				break
+			}
+			return packets, fmt.Errorf("Error reading packet %d: %s", len(packets)+1, err)
+		}
+		packet := gopacket.NewPacket(data, reader.LinkType(), gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+		record, ok := decodePacket(packet)
+		if !ok {
+			continue
+		}
+		record.Timestamp = ci.Timestamp
+		record.Length = ci.Length
+		packets = append(packets, record)
+	}
+	return packets, nil
+}
+
+// decodePacket walks the layers of the packet. A tunnel layer starts a new inner packet.
+func decodePacket(packet gopacket.Packet) (Packet, bool) {
+	record := Packet{}
+	current := &record
+	for _, layer := range packet.Layers() {
+		switch l := layer.(type) {
+		case *layers.IPv4:
+			current = nextIPPacket(current)
+			current.Family = "ipv4"
+			current.SrcIP = l.SrcIP.String()
+			current.DstIP = l.DstIP.String()
+		case *layers.IPv6:
+			current = nextIPPacket(current)
+			current.Family = "ipv6"
+			current.SrcIP = l.SrcIP.String()
+			current.DstIP = l.DstIP.String()
+		case *layers.TCP:
+			current.Protocol = ProtocolTCP
+			current.SrcPort = int(l.SrcPort)
+			current.DstPort = int(l.DstPort)
+		case *layers.UDP:
+			current.Protocol = ProtocolUDP
+			current.SrcPort = int(l.SrcPort)
+			current.DstPort = int(l.DstPort)
+			// ESP in UDP starts with the SPI, a zero SPI is the non-ESP marker used by IKE
+			if (l.SrcPort == ipsecNATTPort || l.DstPort == ipsecNATTPort) && len(l.Payload) >= 8 {
+				if spi := binary.BigEndian.Uint32(l.Payload[:4]); spi != 0 {
+					current.Protocol = ProtocolESP
+					current.SPI = spi
+				}
+			}
+		case *layers.SCTP:
+			current.Protocol = ProtocolSCTP
+			current.SrcPort = int(l.SrcPort)
+			current.DstPort = int(l.DstPort)
+		case *layers.ICMPv4:
+			current.Protocol = ProtocolICMP
+		case *layers.ICMPv6:
+			current.Protocol = ProtocolICMPv6
+		case *layers.IPSecESP:
+			current.Protocol = ProtocolESP
+			current.SPI = l.SPI
+		case *layers.IGMPv1or2:
+			current.Protocol = ProtocolIGMP
+			current.Groups = append(current.Groups, l.GroupAddress.String())
+		case *layers.IGMP:
+			current.Protocol = ProtocolIGMP
+			for _, record := range l.GroupRecords {
+				current.Groups = append(current.Groups, record.MulticastAddress.String())
+			}
+		case *layers.MLDv1MulticastListenerReportMessage:
+			current.Protocol = ProtocolMLD
+			current.Groups = append(current.Groups, l.MulticastAddress.String())
+		case *layers.MLDv1MulticastListenerDoneMessage:
+			current.Protocol = ProtocolMLD
+			current.Groups = append(current.Groups, l.MulticastAddress.String())
+		case *layers.MLDv2MulticastListenerReportMessage:
+			current.Protocol = ProtocolMLD
+			for _, record := range l.MulticastAddressRecords {
+				current.Groups = append(current.Groups, record.MulticastAddress.String())
+			}
+		case *layers.Geneve:
+			current.Encapsulation = EncapGeneve
+			current.VNI = l.VNI
+		case *layers.VXLAN:
+			current.Encapsulation = EncapVXLAN
+			current.VNI = l.VNI
+		}
+	}
+	return record, record.SrcIP != ""
+}
+
+// nextIPPacket returns the packet that an IP layer belongs to. The first IP layer fills the outer packet,
+// IP layers after a tunnel header or inside another IP layer (IP in IP) fill a new inner packet.
+func nextIPPacket(current *Packet) *Packet {
+	if current.SrcIP == "" {
+		return current
+	}
+	current.Inner = &Packet{}
+	return current.Inner
+}
@@ -0,0 +1,155 @@
+package pktcap
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+var (
+	node1 = net.ParseIP("192.168.1.10")
+	node2 = net.ParseIP("192.168.1.11")
+	pod1  = net.ParseIP("10.128.2.5")
+	ext   = net.ParseIP("1.1.1.1")
+	eip   = net.ParseIP("192.168.1.100")
+)
+
+// serialize builds an ethernet frame with the given layers, the last one can be a raw payload
+func serialize(t *testing.T, ls ...gopacket.SerializableLayer) []byte {
+	t.Helper()
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	for _, l := range ls {
+		if ip, ok := l.(*layers.IPv4); ok {
+			ip.Version = 4
+			ip.TTL = 64
+		}
+	}
+	if err := gopacket.SerializeLayers(buf, opts, ls...); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func ethernet(ethType layers.EthernetType) *layers.Ethernet {
+	return &layers.Ethernet{SrcMAC: net.HardwareAddr{0, 1, 2, 3, 4, 5}, DstMAC: net.HardwareAddr{0, 1, 2, 3, 4, 6}, EthernetType: ethType}
+}
+
+func writePcap(t *testing.T, frames ...[]byte) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := pcapgo.NewWriter(buf)
+	if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+	for i, frame := range frames {
+		ci := gopacket.CaptureInfo{Timestamp: time.Unix(int64(1700000000+i), 0), CaptureLength: len(frame), Length: len(frame)}
+		if err := w.WritePacket(ci, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf
+}
+
+func TestParsePcap(t *testing.T) {
+	esp := serialize(t, ethernet(layers.EthernetTypeIPv4),
+		&layers.IPv4{SrcIP: node1, DstIP: node2, Protocol: layers.IPProtocolESP},
+		gopacket.Payload{0x00, 0x00, 0x10, 0x01, 0, 0, 0, 1, 0xde, 0xad, 0xbe, 0xef})
+	nattUDP := &layers.UDP{SrcPort: 4500, DstPort: 4500}
+	nattIP := &layers.IPv4{SrcIP: node2, DstIP: node1, Protocol: layers.IPProtocolUDP}
+	nattUDP.SetNetworkLayerForChecksum(nattIP)
+	natt := serialize(t, ethernet(layers.EthernetTypeIPv4), nattIP, nattUDP,
+		gopacket.Payload{0x00, 0x00, 0x20, 0x02, 0, 0, 0, 1, 0xde, 0xad, 0xbe, 0xef})
+
+	innerTCP := &layers.TCP{SrcPort: 40000, DstPort: 8080, SYN: true}
+	innerIP := &layers.IPv4{SrcIP: pod1, DstIP: ext, Protocol: layers.IPProtocolTCP}
+	innerTCP.SetNetworkLayerForChecksum(innerIP)
+	outerUDP := &layers.UDP{SrcPort: 50000, DstPort: 4789}
+	outerIP := &layers.IPv4{SrcIP: node1, DstIP: node2, Protocol: layers.IPProtocolUDP}
+	outerUDP.SetNetworkLayerForChecksum(outerIP)
+	vxlan := serialize(t, ethernet(layers.EthernetTypeIPv4), outerIP, outerUDP,
+		&layers.VXLAN{ValidIDFlag: true, VNI: 42}, ethernet(layers.EthernetTypeIPv4), innerIP, innerTCP)
+
+	snatTCP := &layers.TCP{SrcPort: 40000, DstPort: 8080, SYN: true}
+	snatIP := &layers.IPv4{SrcIP: eip, DstIP: ext, Protocol: layers.IPProtocolTCP}
+	snatTCP.SetNetworkLayerForChecksum(snatIP)
+	snat := serialize(t, ethernet(layers.EthernetTypeIPv4), snatIP, snatTCP)
+
+	// IGMPv2 membership report for 232.43.211.234
+	igmp := serialize(t, ethernet(layers.EthernetTypeIPv4),
+		&layers.IPv4{SrcIP: pod1, DstIP: net.ParseIP("232.43.211.234"), Protocol: layers.IPProtocolIGMP},
+		gopacket.Payload{0x16, 0x00, 0x00, 0x00, 232, 43, 211, 234})
+
+	arp := serialize(t, ethernet(layers.EthernetTypeARP), &layers.ARP{AddrType: layers.LinkTypeEthernet, Protocol: layers.EthernetTypeIPv4,
+		HwAddressSize: 6, ProtAddressSize: 4, Operation: layers.ARPRequest,
+		SourceHwAddress: []byte{0, 1, 2, 3, 4, 5}, SourceProtAddress: node1.To4(), DstHwAddress: []byte{0, 0, 0, 0, 0, 0}, DstProtAddress: node2.To4()})
+
+	packets, err := ParsePcap(writePcap(t, esp, natt, vxlan, snat, igmp, arp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 5 {
+		t.Fatalf("Expected 5 IP packets, got %d:\n%s", len(packets), packets)
+	}
+	if packets[0].Protocol != ProtocolESP || packets[0].SPI != 0x1001 {
+		t.Errorf("Wrong ESP packet: %s", packets[0])
+	}
+	if packets[1].Protocol != ProtocolESP || packets[1].SPI != 0x2002 {
+		t.Errorf("Wrong ESP in UDP packet: %s", packets[1])
+	}
+	if packets[2].Encapsulation != EncapVXLAN || packets[2].VNI != 42 || packets[2].Inner == nil {
+		t.Fatalf("Wrong VXLAN packet: %s", packets[2])
+	}
+	inner := packets[2].Innermost()
+	if inner.SrcIP != pod1.String() || inner.DstIP != ext.String() || inner.Protocol != ProtocolTCP || inner.DstPort != 8080 {
+		t.Errorf("Wrong VXLAN inner packet: %s", inner)
+	}
+	if !reflect.DeepEqual(packets[4].Groups, []string{"232.43.211.234"}) || packets[4].Protocol != ProtocolIGMP {
+		t.Errorf("Wrong IGMP packet: %s", packets[4])
+	}
+	if !packets[0].Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Wrong timestamp: %v", packets[0].Timestamp)
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	packets := Packets{
+		{Family: "ipv4", SrcIP: node1.String(), DstIP: node2.String(), Protocol: ProtocolESP, SPI: 1},
+		{Family: "ipv4", SrcIP: node2.String(), DstIP: node1.String(), Protocol: ProtocolESP, SPI: 2},
+		{Family: "ipv4", SrcIP: eip.String(), DstIP: ext.String(), Protocol: ProtocolTCP, SrcPort: 40000, DstPort: 8080},
+		{Family: "ipv4", SrcIP: pod1.String(), DstIP: "232.43.211.234", Protocol: ProtocolIGMP, Groups: []string{"232.43.211.234"}},
+	}
+	if err := packets.AllEncrypted(node1.String(), node2.String()); err != nil {
+		t.Errorf("Packets between nodes are encrypted: %v", err)
+	}
+	if err := packets.AllEncrypted(node1.String(), ext.String()); err == nil {
+		t.Errorf("Expected error when there are no packets")
+	}
+	if err := packets.SourceIPIs(ext.String(), eip.String()); err != nil {
+		t.Errorf("Source IP should be the egress IP: %v", err)
+	}
+
+	packets = append(packets, Packet{Family: "ipv4", SrcIP: node1.String(), DstIP: node2.String(), Protocol: ProtocolUDP, Encapsulation: EncapGeneve,
+		Inner: &Packet{Family: "ipv4", SrcIP: pod1.String(), DstIP: ext.String(), Protocol: ProtocolTCP, DstPort: 8080}})
+	if err := packets.AllEncrypted(node1.String(), node2.String()); err == nil {
+		t.Errorf("Expected error for the plain GENEVE packet")
+	}
+	if err := packets.SourceIPIs(ext.String(), eip.String()); err == nil {
+		t.Errorf("Expected error for the packet using the pod IP")
+	}
+	if len(packets.WithPort(8080)) != 2 {
+		t.Errorf("Expected 2 packets to port 8080:\n%s", packets.WithPort(8080))
+	}
+	if err := packets.JoinedGroup("232.43.211.234"); err != nil {
+		t.Error(err)
+	}
+	if err := packets.JoinedGroup("232.43.211.235"); err == nil {
+		t.Errorf("Expected error for a group that was not joined")
+	}
+}
@@ -0,0 +1,211 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	e2e "k8s.io/kubernetes/test/e2e/framework"
+)
+
+// The PreChkUpgrade and PstChkUpgrade tests of a case run before and after the cluster upgrade, in different processes and
+// usually in different CI steps. The handoff store keeps the data the post-check needs from the pre-check, i.e. the names
+// of the objects it created, indexed by the Polarion ID both tests share.
+const (
+	// EnvUpgradeHandoffDir is the directory of the handoff files, set by "run --upgrade --upgrade-handoff-dir".
+	// The data is stored in a ConfigMap of the cluster if it is not set.
+	EnvUpgradeHandoffDir = "UPGRADE_HANDOFF_DIR"
+
+	upgradeHandoffNamespace = "default"
+	upgradeHandoffConfigMap = "openshift-tests-upgrade-handoff"
+)
+
+var upgradeHandoffKey = regexp.MustCompile(`^[-_a-zA-Z0-9]+$`)
+
+// UpgradeHandoff is the key/value store shared by the pre and post upgrade checks of a case
+type UpgradeHandoff struct {
+	oc  *CLI
+	id  string
+	dir string
+}
+
+// NewUpgradeHandoff returns the handoff store of the current test, identified by its Polarion ID
+func NewUpgradeHandoff(oc *CLI) *UpgradeHandoff {
+	return NewUpgradeHandoffForID(oc, GetCurrentTestPolarionIDNumber())
+}
+
+// NewUpgradeHandoffForID returns the handoff store of the case with the given Polarion ID
+func NewUpgradeHandoffForID(oc *CLI, id string) *UpgradeHandoff {
+	return &UpgradeHandoff{oc: oc, id: id, dir: os.Getenv(EnvUpgradeHandoffDir)}
+}
+
+// Set stores the value of the key. Keys can contain alphanumeric characters, "-" and "_".
+func (h *UpgradeHandoff) Set(key, value string) error {
+	if h.id == "" {
+		return fmt.Errorf("the upgrade handoff store needs the Polarion ID of the test")
+	}
+	if !upgradeHandoffKey.MatchString(key) {
+		return fmt.Errorf("invalid upgrade handoff key %q", key)
+	}
+	e2e.Logf("Saving upgrade handoff %s/%s=%s", h.id, key, value)
+	if h.dir != "" {
+		return h.updateFile(func(data map[string]string) { data[key] = value })
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm, err := h.getOrCreateConfigMap()
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[h.configMapKey(key)] = value
+		_, err = h.oc.AdminKubeClient().CoreV1().ConfigMaps(upgradeHandoffNamespace).Update(context.Background(), cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Get returns the value of the key, and an error if it was not saved by the pre-check
+func (h *UpgradeHandoff) Get(key string) (string, error) {
+	data, err := h.All()
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in the upgrade handoff of case %s, did the pre-upgrade check run?", key, h.id)
+	}
+	return value, nil
+}
+
+// SetObject stores the value encoded as JSON
+func (h *UpgradeHandoff) SetObject(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return h.Set(key, string(value))
+}
+
+// GetObject decodes the JSON value of the key in v
+func (h *UpgradeHandoff) GetObject(key string, v interface{}) error {
+	value, err := h.Get(key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(value), v)
+}
+
+// All returns all the keys and values of the case
+func (h *UpgradeHandoff) All() (map[string]string, error) {
+	if h.dir != "" {
+		return h.readFile()
+	}
+	cm, err := h.oc.AdminKubeClient().CoreV1().ConfigMaps(upgradeHandoffNamespace).Get(context.Background(), upgradeHandoffConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	prefix := h.id + "."
+	for k, v := range cm.Data {
+		if strings.HasPrefix(k, prefix) {
+			data[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+	return data, nil
+}
+
+// configMapKey returns the key in the ConfigMap shared by all the cases
+func (h *UpgradeHandoff) configMapKey(key string) string {
+	return h.id + "." + key
+}
+
+func (h *UpgradeHandoff) getOrCreateConfigMap() (*corev1.ConfigMap, error) {
+	client := h.oc.AdminKubeClient().CoreV1().ConfigMaps(upgradeHandoffNamespace)
+	cm, err := client.Get(context.Background(), upgradeHandoffConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm, err = client.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: upgradeHandoffConfigMap, Namespace: upgradeHandoffNamespace},
+		}, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return client.Get(context.Background(), upgradeHandoffConfigMap, metav1.GetOptions{})
+		}
+	}
+	return cm, err
+}
+
+// file returns the handoff file of the case, a JSON object with the keys and values
+func (h *UpgradeHandoff) file() string {
+	return filepath.Join(h.dir, fmt.Sprintf("handoff-%s.json", h.id))
+}
+
+func (h *UpgradeHandoff) readFile() (map[string]string, error) {
+	data := map[string]string{}
+	content, err := os.ReadFile(h.file())
+	if os.IsNotExist(err) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %v", h.file(), err)
+	}
+	return data, nil
+}
+
+// updateFile does a read-modify-write of the handoff file under an exclusive lock, the checks of a case can run in
+// parallel processes
+func (h *UpgradeHandoff) updateFile(update func(data map[string]string)) error {
+	if err := os.MkdirAll(h.dir, 0755); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(h.file()+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("error locking %s: %v", lock.Name(), err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	data, err := h.readFile()
+	if err != nil {
+		return err
+	}
+	update(data)
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file is replaced atomically so a reader never sees a partial write, the temporary file is unique per writer
+	tmp, err := os.CreateTemp(h.dir, filepath.Base(h.file())+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), h.file())
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUpgradeHandoffFileConcurrentSet(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvUpgradeHandoffDir, dir)
+
+	// the writers share the handoff file of the case, none of the keys must be lost
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := NewUpgradeHandoffForID(nil, "72031").Set(fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)); err != nil {
+				t.Errorf("Set failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := NewUpgradeHandoffForID(nil, "72031").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 20 {
+		t.Errorf("expected 20 keys, got %d: %v", len(data), data)
+	}
+	value, err := NewUpgradeHandoffForID(nil, "72031").Get("key-7")
+	if err != nil || value != "value-7" {
+		t.Errorf("expected value-7, got %q %v", value, err)
+	}
+	if _, err := NewUpgradeHandoffForID(nil, "72031").Get("missing"); err == nil {
+		t.Errorf("expected an error for a missing key")
+	}
+
+	// no temporary file is left behind
+	tmp, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmp) > 0 {
+		t.Errorf("temporary files left: %v", tmp)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "handoff-72031.json")); err != nil {
+		t.Errorf("the handoff file is missing: %v", err)
+	}
+}
@@ -24,8 +24,6 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		oc                  = exutil.NewCLI("default-"+getRandomString(), exutil.KubeConfigPath())
 		kubeNamespace       = "openshift-cli-manager-operator"
 		buildPruningBaseDir string
-		cmoOperatorGroupT   string
-		cmoSubscriptionT    string
 		sub                 cmoSubscription
 		og                  cmoOperatorgroup
 		plugin              pluginDetails
@@ -36,13 +34,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 			g.Skip("it is jenkins without supporting krew, so skip. currently only support it in prow")
 		}
 		buildPruningBaseDir = exutil.FixturePath("testdata", "workloads")
-		cmoOperatorGroupT = filepath.Join(buildPruningBaseDir, "cmo_operatorgroup.yaml")
-		cmoSubscriptionT = filepath.Join(buildPruningBaseDir, "cmo_subscription.yaml")
 
 		og = cmoOperatorgroup{
 			name:      "openshift-cli-manager-operator",
 			namespace: kubeNamespace,
-			template:  cmoOperatorGroupT,
 		}
 
 		// Skip the test if no qe-app-registry catalog is present
@@ -102,7 +97,6 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 				opsrcName:   "cs-iib-bd6382120df0",
 				sourceName:  "openshift-marketplace",
 				startingCSV: "cli-manager-operator.v0.1.0",
-				template:    cmoSubscriptionT,
 			}
 
 			// Retrieve the certificate from the ConfigMap
@@ -166,7 +160,6 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 				opsrcName:   "custom-cli-app-registry",
 				sourceName:  "openshift-marketplace",
 				startingCSV: "cli-manager-operator.v0.1.0",
-				template:    cmoSubscriptionT,
 			}
 
 			plugin = pluginDetails{
@@ -183,15 +176,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("create").Args("ns", kubeNamespace).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the cli manager operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the cli manager operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "openshift-cli-manager-operator", kubeNamespace, "1"); ok {
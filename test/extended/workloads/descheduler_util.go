@@ -12,6 +12,7 @@ import (
 	g "github.com/onsi/ginkgo/v2"
 	o "github.com/onsi/gomega"
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/olm"
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
@@ -19,7 +20,6 @@ import (
 type operatorgroup struct {
 	name      string
 	namespace string
-	template  string
 }
 
 type subscription struct {
@@ -28,7 +28,6 @@ type subscription struct {
 	channelName string
 	opsrcName   string
 	sourceName  string
-	template    string
 }
 
 type kubedescheduler struct {
@@ -92,56 +91,21 @@ type customsub struct {
 	opsrcName   string
 	sourceName  string
 	startingCSV string
-	template    string
-}
 
-func (sub *subscription) createSubscription(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := applyResourceFromTemplate(oc, "--ignore-unknown-parameters=true", "-f", sub.template, "-p", "NAME="+sub.name, "NAMESPACE="+sub.namespace,
-			"CHANNELNAME="+sub.channelName, "OPSRCNAME="+sub.opsrcName, "SOURCENAME="+sub.sourceName)
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("sub %s is not created successfully", sub.name))
-}
-
-func (sub *subscription) deleteSubscription(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := oc.AsAdmin().WithoutNamespace().Run("delete").Args("subscription", sub.name, "-n", sub.namespace).Execute()
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("sub %s is not deleted successfully", sub.name))
+	// olmOperator is built once so the objects created by the install are removed by the uninstall
+	olmOperator *olm.Operator
 }
 
-func (og *operatorgroup) createOperatorGroup(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := applyResourceFromTemplate(oc, "--ignore-unknown-parameters=true", "-f", og.template, "-p", "NAME="+og.name, "NAMESPACE="+og.namespace)
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("og %s is not created successfully", og.name))
-}
-
-func (og *operatorgroup) deleteOperatorGroup(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := oc.AsAdmin().WithoutNamespace().Run("delete").Args("operatorgroup", og.name, "-n", og.namespace).Execute()
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("og %s is not deleted successfully", og.name))
+// operator returns the OLM operator of the subscription in the namespace of the operator group
+func (sub *subscription) operator(og *operatorgroup) *olm.Operator {
+	return &olm.Operator{
+		Package:                sub.name,
+		Namespace:              sub.namespace,
+		OperatorGroupName:      og.name,
+		Channel:                sub.channelName,
+		CatalogSource:          sub.opsrcName,
+		CatalogSourceNamespace: sub.sourceName,
+	}
 }
 
 func (dsch *kubedescheduler) createKubeDescheduler(oc *exutil.CLI) {
@@ -371,27 +335,18 @@ func skipMissingCatalogsource(oc *exutil.CLI) {
 	}
 }
 
-func (sub *customsub) createCustomSub(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := applyResourceFromTemplate(oc, "--ignore-unknown-parameters=true", "-f", sub.template, "-p", "NAME="+sub.name, "NAMESPACE="+sub.namespace,
-			"CHANNELNAME="+sub.channelName, "OPSRCNAME="+sub.opsrcName, "SOURCENAME="+sub.sourceName, "CSVNAME="+sub.startingCSV)
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
+// operator returns the OLM operator of the subscription in the namespace of the operator group
+func (sub *customsub) operator(og *operatorgroup) *olm.Operator {
+	if sub.olmOperator == nil {
+		sub.olmOperator = &olm.Operator{
+			Package:                sub.name,
+			Namespace:              sub.namespace,
+			OperatorGroupName:      og.name,
+			Channel:                sub.channelName,
+			CatalogSource:          sub.opsrcName,
+			CatalogSourceNamespace: sub.sourceName,
+			StartingCSV:            sub.startingCSV,
 		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("sub %s is not created successfully", sub.name))
-}
-
-func (sub *customsub) deleteCustomSubscription(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := oc.AsAdmin().WithoutNamespace().Run("delete").Args("subscription", sub.name, "-n", sub.namespace).Execute()
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("sub %s is not deleted successfully", sub.name))
+	}
+	return sub.olmOperator
 }
@@ -29,8 +29,6 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 	)
 
 	buildPruningBaseDir := exutil.FixturePath("testdata", "workloads")
-	operatorGroupT := filepath.Join(buildPruningBaseDir, "operatorgroup.yaml")
-	subscriptionT := filepath.Join(buildPruningBaseDir, "subscription.yaml")
 	deschedulerT := filepath.Join(buildPruningBaseDir, "kubedescheduler.yaml")
 
 	sub := subscription{
@@ -39,13 +37,11 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		channelName: "stable",
 		opsrcName:   "qe-app-registry",
 		sourceName:  "openshift-marketplace",
-		template:    subscriptionT,
 	}
 
 	og := operatorgroup{
 		name:      "openshift-kube-descheduler-operator",
 		namespace: kubeNamespace,
-		template:  operatorGroupT,
 	}
 
 	deschu := kubedescheduler{
@@ -90,15 +86,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer og.deleteOperatorGroup(oc)
-
-		g.By("Create the subscription")
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer sub.deleteSubscription(oc)
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -449,15 +440,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer og.deleteOperatorGroup(oc)
-
-		g.By("Create the subscription")
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer sub.deleteSubscription(oc)
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -887,15 +873,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = getOCPerKubeConf(oc, guestClusterKubeconfig).AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		og.createOperatorGroup(getOCPerKubeConf(oc, guestClusterKubeconfig))
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer og.deleteOperatorGroup(getOCPerKubeConf(oc, guestClusterKubeconfig))
-
-		g.By("Create the subscription")
-		sub.createSubscription(getOCPerKubeConf(oc, guestClusterKubeconfig))
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer sub.deleteSubscription(getOCPerKubeConf(oc, guestClusterKubeconfig))
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(getOCPerKubeConf(oc, guestClusterKubeconfig))
+		operator.Install(getOCPerKubeConf(oc, guestClusterKubeconfig))
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(getOCPerKubeConf(oc, guestClusterKubeconfig), "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -1195,15 +1176,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer og.deleteOperatorGroup(oc)
-
-		g.By("Create the subscription")
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer sub.deleteSubscription(oc)
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -1416,15 +1392,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -1584,15 +1555,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -1766,15 +1732,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -2015,15 +1976,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -2171,15 +2127,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err := oc.AsAdmin().WithoutNamespace().Run("create").Args("ns", kubeNamespace).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -2251,15 +2202,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err := oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -2421,15 +2367,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("ns", kubeNamespace, "--type=json", "-p", patch).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer og.deleteOperatorGroup(oc)
-
-		g.By("Create the subscription")
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-		defer sub.deleteSubscription(oc)
+		g.By("Install the descheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the descheduler operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "descheduler-operator", kubeNamespace, "1"); ok {
@@ -23,8 +23,6 @@ var _ = g.Describe("[sig-scheduling] Workloads Set activeDeadLineseconds using t
 		oc                       = exutil.NewCLI("default-"+getRandomString(), exutil.KubeConfigPath())
 		kubeNamespace            = "openshift-run-once-duration-override-operator"
 		buildPruningBaseDir      string
-		rodoOperatorGroupT       string
-		rodoSubscriptionT        string
 		runOnceDurationOverrideT string
 		sub                      rodoSubscription
 		og                       rodoOperatorgroup
@@ -34,8 +32,6 @@ var _ = g.Describe("[sig-scheduling] Workloads Set activeDeadLineseconds using t
 
 	g.BeforeEach(func() {
 		buildPruningBaseDir = exutil.FixturePath("testdata", "workloads")
-		rodoOperatorGroupT = filepath.Join(buildPruningBaseDir, "rodo_operatorgroup.yaml")
-		rodoSubscriptionT = filepath.Join(buildPruningBaseDir, "rodo_subscription.yaml")
 		runOnceDurationOverrideT = filepath.Join(buildPruningBaseDir, "rodo_ds.yaml")
 
 		sub = rodoSubscription{
@@ -44,13 +40,11 @@ var _ = g.Describe("[sig-scheduling] Workloads Set activeDeadLineseconds using t
 			channelName: "stable",
 			opsrcName:   "qe-app-registry",
 			sourceName:  "openshift-marketplace",
-			template:    rodoSubscriptionT,
 		}
 
 		og = rodoOperatorgroup{
 			name:      "openshift-run-once-duration-override-operator",
 			namespace: kubeNamespace,
-			template:  rodoOperatorGroupT,
 		}
 
 		rodods = runOnceDurationOverride{
@@ -95,15 +89,10 @@ var _ = g.Describe("[sig-scheduling] Workloads Set activeDeadLineseconds using t
 		err := oc.AsAdmin().WithoutNamespace().Run("create").Args("ns", kubeNamespace).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the run once duration override operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the runOnceDurationOverride operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "run-once-duration-override-operator", kubeNamespace, "1"); ok {
@@ -265,15 +254,10 @@ var _ = g.Describe("[sig-scheduling] Workloads Set activeDeadLineseconds using t
 		err := oc.AsAdmin().WithoutNamespace().Run("create").Args("ns", kubeNamespace).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(oc)
-		og.createOperatorGroup(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(oc)
-		sub.createSubscription(oc)
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the run once duration override operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(oc)
+		operator.Install(oc)
 
 		g.By("Wait for the runOnceDurationOverride operator pod running")
 		if ok := waitForAvailableRsRunning(oc, "deploy", "run-once-duration-override-operator", kubeNamespace, "1"); ok {
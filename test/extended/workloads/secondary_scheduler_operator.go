@@ -19,8 +19,6 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		oc                                                          = exutil.NewCLI("default-"+getRandomString(), exutil.KubeConfigPath())
 		kubeNamespace                                               = "openshift-secondary-scheduler-operator"
 		buildPruningBaseDir                                         string
-		ssoOperatorGroupT                                           string
-		ssoSubscriptionT                                            string
 		secondarySchedulerT                                         string
 		secondarySchedulerConfig                                    string
 		sub                                                         ssoSubscription
@@ -32,8 +30,6 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 
 	g.BeforeEach(func() {
 		buildPruningBaseDir = exutil.FixturePath("testdata", "workloads")
-		ssoOperatorGroupT = filepath.Join(buildPruningBaseDir, "sso_operatorgroup.yaml")
-		ssoSubscriptionT = filepath.Join(buildPruningBaseDir, "sso_subscription.yaml")
 		secondarySchedulerT = filepath.Join(buildPruningBaseDir, "secondaryScheduler.yaml")
 		secondarySchedulerConfig = filepath.Join(buildPruningBaseDir, "SecondarySchedulerConfig.yaml")
 
@@ -44,13 +40,11 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 			opsrcName:   "redhat-operators",
 			sourceName:  "openshift-marketplace",
 			startingCSV: "secondaryscheduleroperator.v1.3.1",
-			template:    ssoSubscriptionT,
 		}
 
 		og = ssoOperatorgroup{
 			name:      "openshift-secondary-scheduler-operator",
 			namespace: kubeNamespace,
-			template:  ssoOperatorGroupT,
 		}
 
 		// Get secheduler Image
@@ -97,15 +91,10 @@ var _ = g.Describe("[sig-scheduling] Workloads The Descheduler Operator automate
 		err := getOCPerKubeConf(oc, guestClusterKubeconfig).AsAdmin().WithoutNamespace().Run("create").Args("ns", kubeNamespace).Execute()
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		g.By("Create the operatorgroup")
-		defer og.deleteOperatorGroup(getOCPerKubeConf(oc, guestClusterKubeconfig))
-		og.createOperatorGroup(getOCPerKubeConf(oc, guestClusterKubeconfig))
-		o.Expect(err).NotTo(o.HaveOccurred())
-
-		g.By("Create the subscription")
-		defer sub.deleteSubscription(getOCPerKubeConf(oc, guestClusterKubeconfig))
-		sub.createSubscription(getOCPerKubeConf(oc, guestClusterKubeconfig))
-		o.Expect(err).NotTo(o.HaveOccurred())
+		g.By("Install the secondary scheduler operator")
+		operator := sub.operator(&og)
+		defer operator.Uninstall(getOCPerKubeConf(oc, guestClusterKubeconfig))
+		operator.Install(getOCPerKubeConf(oc, guestClusterKubeconfig))
 
 		g.By("Wait for the secondary scheduler operator pod running")
 		if ok := waitForAvailableRsRunning(getOCPerKubeConf(oc, guestClusterKubeconfig), "deploy", "secondary-scheduler-operator", kubeNamespace, "1"); ok {
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	exutil "github.com/openshift/openshift-tests-private/test/extended/util"
+	"github.com/openshift/openshift-tests-private/test/extended/util/olm"
 	"k8s.io/apimachinery/pkg/util/wait"
 	e2e "k8s.io/kubernetes/test/e2e/framework"
 )
@@ -15,7 +16,6 @@ import (
 type ssoOperatorgroup struct {
 	name      string
 	namespace string
-	template  string
 }
 
 type ssoSubscription struct {
@@ -25,7 +25,6 @@ type ssoSubscription struct {
 	opsrcName   string
 	sourceName  string
 	startingCSV string
-	template    string
 }
 
 type secondaryScheduler struct {
@@ -50,53 +49,19 @@ type deployPodWithOutScheduler struct {
 	template  string
 }
 
-func (sub *ssoSubscription) createSubscription(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := applyResourceFromTemplate(oc, "--ignore-unknown-parameters=true", "-f", sub.template, "-p", "NAME="+sub.name, "NAMESPACE="+sub.namespace,
-			"CHANNELNAME="+sub.channelName, "OPSRCNAME="+sub.opsrcName, "SOURCENAME="+sub.sourceName, "STARTINGCSV="+sub.startingCSV)
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("sub %s is not created successfully", sub.name))
-}
-
-func (sub *ssoSubscription) deleteSubscription(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := oc.AsAdmin().WithoutNamespace().Run("delete").Args("subscription", sub.name, "-n", sub.namespace).Execute()
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("sub %s is not deleted successfully", sub.name))
-}
-
-func (og *ssoOperatorgroup) createOperatorGroup(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := applyResourceFromTemplate(oc, "--ignore-unknown-parameters=true", "-f", og.template, "-p", "NAME="+og.name, "NAMESPACE="+og.namespace)
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("og %s is not created successfully", og.name))
-}
-
-func (og *ssoOperatorgroup) deleteOperatorGroup(oc *exutil.CLI) {
-	err := wait.Poll(5*time.Second, 20*time.Second, func() (bool, error) {
-		err1 := oc.AsAdmin().WithoutNamespace().Run("delete").Args("operatorgroup", og.name, "-n", og.namespace).Execute()
-		if err1 != nil {
-			e2e.Logf("the err:%v, and try next round", err1)
-			return false, nil
-		}
-		return true, nil
-	})
-	exutil.AssertWaitPollNoErr(err, fmt.Sprintf("og %s is not deleted successfully", og.name))
+// operator returns the OLM operator of the subscription in the namespace of the operator group, the starting CSV
+// is approved manually so OLM does not upgrade it
+func (sub *ssoSubscription) operator(og *ssoOperatorgroup) *olm.Operator {
+	return &olm.Operator{
+		Package:                sub.name,
+		Namespace:              sub.namespace,
+		OperatorGroupName:      og.name,
+		Channel:                sub.channelName,
+		CatalogSource:          sub.opsrcName,
+		CatalogSourceNamespace: sub.sourceName,
+		StartingCSV:            sub.startingCSV,
+		ManualApproval:         sub.startingCSV != "",
+	}
 }
 
 func (secschu *secondaryScheduler) createSecondaryScheduler(oc *exutil.CLI) {
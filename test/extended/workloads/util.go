@@ -1460,10 +1460,6 @@ func getOperatorInfo(oc *exutil.CLI, operatorName string, operatorNamespace stri
 	startingCsvName := strings.ReplaceAll(string(startingCsv), "\n", "")
 	e2e.Logf("The csv name%v", startingCsvName)
 
-	buildPruningBaseDir := exutil.FixturePath("testdata", "workloads")
-	subscriptionT := filepath.Join(buildPruningBaseDir, "customsub.yaml")
-	operatorGroupT := filepath.Join(buildPruningBaseDir, "operatorgroup.yaml")
-
 	sub := customsub{
 		name:        operatorName,
 		namespace:   operatorNamespace,
@@ -1471,13 +1467,11 @@ func getOperatorInfo(oc *exutil.CLI, operatorName string, operatorNamespace stri
 		opsrcName:   catalogSourceName,
 		sourceName:  "openshift-marketplace",
 		startingCSV: startingCsvName,
-		template:    subscriptionT,
 	}
 
 	og := operatorgroup{
 		name:      operatorName,
 		namespace: operatorNamespace,
-		template:  operatorGroupT,
 	}
 
 	return &sub, &og
@@ -1488,16 +1482,9 @@ func installOperatorFromCustomCS(oc *exutil.CLI, operatorSub *customsub, operato
 	installCustomOperator(oc, operatorSub, operatorOG, operatorNamespace, operatorDeoloy, "1")
 }
 
+// removeOperatorFromCustomCS removes the subscription, the CSV, and the operator group and namespace created by the install
 func removeOperatorFromCustomCS(oc *exutil.CLI, operatorSub *customsub, operatorOG *operatorgroup, operatorNamespace string) {
-	e2e.Logf("Remove the subscription")
-	operatorSub.deleteCustomSubscription(oc)
-
-	e2e.Logf("Remove the operatorgroup")
-	operatorOG.deleteOperatorGroup(oc)
-
-	e2e.Logf("Remove the operatornamespace")
-	err := oc.AsAdmin().WithoutNamespace().Run("delete").Args("ns", operatorNamespace).Execute()
-	o.Expect(err).NotTo(o.HaveOccurred())
+	operatorSub.operator(operatorOG).Uninstall(oc)
 }
 
 func removeCSAndISCP(oc *exutil.CLI) {
@@ -2257,57 +2244,26 @@ func (registry *registry) deleteregistrySpecifyName(oc *exutil.CLI, registryName
 	_ = oc.Run("delete").Args("is", registryName, "-n", registry.namespace).Execute()
 }
 
+// installAllNSOperatorFromCustomCS installs the operator watching all the namespaces from the custom catalog source
 func installAllNSOperatorFromCustomCS(oc *exutil.CLI, operatorSub *customsub, operatorOG *operatorgroup, operatorNamespace string, operatorDeoloy string, ogName string, operatorunningNum string) {
-	e2e.Logf("Create the operator namespace")
-	err := oc.AsAdmin().WithoutNamespace().Run("create").Args("ns", operatorNamespace).Execute()
-	o.Expect(err).NotTo(o.HaveOccurred())
-
-	e2e.Logf("Create the subscription")
-	operatorSub.createCustomSub(oc)
-
-	e2e.Logf("Create the operatorgroup")
-	operatorOG.createOperatorGroup(oc)
-
-	e2e.Logf("Remove the targetNamespaces")
-	pathYaml := `[{"op": "remove", "path": "/spec/targetNamespaces"}]`
-	err = oc.AsAdmin().WithoutNamespace().Run("patch").Args("og", ogName, "--type=json", "-p", pathYaml, "-n", operatorNamespace).Execute()
-	o.Expect(err).NotTo(o.HaveOccurred())
-
-	e2e.Logf("Wait for the operator pod running")
-	if ok := waitForAvailableRsRunning(oc, "deploy", operatorDeoloy, operatorNamespace, operatorunningNum); ok {
-		e2e.Logf("installed operator runnnig now\n")
-	} else {
-		e2e.Failf("All pods related to deployment are not running")
-	}
-
+	operator := operatorSub.operator(operatorOG)
+	operator.OperatorGroupName = ogName
+	operator.AllNamespaces = true
+	installCustomOperator(oc, operatorSub, operatorOG, operatorNamespace, operatorDeoloy, operatorunningNum)
 }
 
-// install the operator from custom catalog source and check the operator running pods numbers as expected
+// installCustomOperator installs the operator from the custom catalog source and waits for the replicas of the operator deployment
 func installCustomOperator(oc *exutil.CLI, operatorSub *customsub, operatorOG *operatorgroup, operatorNamespace string, operatorDeoloy string, operatorunningNum string) {
-	e2e.Logf("Create the operator namespace")
-	err := oc.AsAdmin().WithoutNamespace().Run("create").Args("ns", operatorNamespace).Execute()
-	o.Expect(err).NotTo(o.HaveOccurred())
-
-	e2e.Logf("Create the subscription")
-	operatorSub.createCustomSub(oc)
-
-	e2e.Logf("Create the operatorgroup")
-	operatorOG.createOperatorGroup(oc)
+	operator := operatorSub.operator(operatorOG)
+	operator.Install(oc)
 
 	e2e.Logf("Wait for the operator pod running")
 	if ok := waitForAvailableRsRunning(oc, "deploy", operatorDeoloy, operatorNamespace, operatorunningNum); ok {
 		e2e.Logf("installed operator runnnig now\n")
 	} else {
-		err = oc.AsAdmin().WithoutNamespace().Run("get").Args("-n", operatorNamespace, "sub").Execute()
-		if err != nil {
-			e2e.Logf("Could not retreive subscription")
-		} else {
-			err = oc.AsAdmin().WithoutNamespace().Run("describe").Args("-n", operatorNamespace, "sub").Execute()
-			o.Expect(err).NotTo(o.HaveOccurred())
-		}
+		e2e.Logf("%s", operator.Diagnose(oc))
 		e2e.Failf("All pods related to deployment are not running")
 	}
-
 }
 
 func checkImageRegistryPodNum(oc *exutil.CLI) bool {